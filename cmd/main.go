@@ -1,9 +1,10 @@
 package main
 
-import "cakestore/internal/bootstrap"
+import (
+	"cakestore/internal/cli"
+	"os"
+)
 
 func main() {
-	app := bootstrap.NewApplication()
-	app.Bootstrap()
-	app.Start()
+	os.Exit(cli.Run(os.Args[1:]))
 }
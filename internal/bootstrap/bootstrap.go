@@ -3,22 +3,31 @@ package bootstrap
 
 import (
 	configs "cakestore/internal/config"
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	controller "cakestore/internal/delivery/http"
 	"cakestore/internal/delivery/http/route"
 	"cakestore/internal/health"
+	"cakestore/internal/lifecycle"
+	"cakestore/internal/notification"
+	"cakestore/internal/ratelimit"
 	"cakestore/internal/repository"
 	"cakestore/internal/seeder"
+	"cakestore/internal/token"
 	"cakestore/internal/usecase"
+	"cakestore/internal/worker"
 	"cakestore/utils"
 	"context"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	fiberprometheus "github.com/ansrivas/fiberprometheus/v2"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Application struct {
@@ -26,66 +35,125 @@ type Application struct {
 	Config *configs.Config
 	DB     *gorm.DB
 	Logger *logrus.Logger
-	Cache  *database.RedisCacheService
+	Cache  database.RedisCache
+	// SecurityCache holds revoked tokens, token versions, two-factor
+	// challenges, role permissions and POS devices. It is Redis whatever
+	// CACHE_BACKEND says, every replica has to see a revocation at once.
+	SecurityCache database.RedisCache
+	Worker        *worker.Runner
+	// Redis backs the security cache, and the cache and the rate limiter
+	// unless they are configured otherwise
+	Redis       *redis.Client
+	RateLimiter *ratelimit.Limiter
+	// Lifecycle shuts the server down and reports readiness meanwhile
+	Lifecycle *lifecycle.Manager
 }
 
 type Dependencies struct {
 	// Repositories
-	MenuRepository        repository.MenuRepository
-	CustomerRepository    repository.CustomerRepository
-	CartRepository        repository.CartRepository
-	OrderRepository       repository.OrderRepository
-	PaymentRepository     repository.PaymentRepository
-	WishlistRepository    repository.WishListRepository
-	ReservationRepository repository.ReservationRepository
-	InventoryRepository   repository.InventoryRepository
-	TableRepository       repository.TableRepository
+	MenuRepository         repository.MenuRepository
+	CustomerRepository     repository.CustomerRepository
+	CartRepository         repository.CartRepository
+	OrderRepository        repository.OrderRepository
+	PaymentRepository      repository.PaymentRepository
+	WishlistRepository     repository.WishListRepository
+	ReservationRepository  repository.ReservationRepository
+	InventoryRepository    repository.InventoryRepository
+	TableRepository        repository.TableRepository
+	BillRepository         repository.BillRepository
+	FloorRepository        repository.FloorRepository
+	DepositRepository      repository.DepositRepository
+	NotificationRepository repository.NotificationRepository
+	RefreshTokenRepository repository.RefreshTokenRepository
+	AccountTokenRepository repository.AccountTokenRepository
+	RoleRepository         repository.RoleRepository
+	PosRepository          repository.PosRepository
+	LoginRepository        repository.LoginRepository
+	TwoFactorRepository    repository.TwoFactorRepository
+	AuditRepository        repository.AuditRepository
+	EmployeeRepository     repository.EmployeeRepository
+	TimeClockRepository    repository.TimeClockRepository
+	SearchRepository       repository.SearchRepository
 
 	// Use Cases
-	MenuUseCase        usecase.MenuUseCase
-	CustomerUseCase    usecase.CustomerUseCase
-	CartUseCase        usecase.CartUseCase
-	OrderUseCase       usecase.OrderUseCase
-	PaymentUseCase     usecase.PaymentUseCase
-	WishlistUseCase    usecase.WishListUseCase
-	ReservationUseCase usecase.ReservationUseCase
-	InventoryUseCase   usecase.InventoryUseCase
-	TableUseCase       usecase.TableUseCase
+	MenuUseCase         usecase.MenuUseCase
+	CustomerUseCase     usecase.CustomerUseCase
+	CartUseCase         usecase.CartUseCase
+	OrderUseCase        usecase.OrderUseCase
+	PaymentUseCase      usecase.PaymentUseCase
+	WishlistUseCase     usecase.WishListUseCase
+	ReservationUseCase  usecase.ReservationUseCase
+	InventoryUseCase    usecase.InventoryUseCase
+	TableUseCase        usecase.TableUseCase
+	BillUseCase         usecase.BillUseCase
+	FloorUseCase        usecase.FloorUseCase
+	DepositUseCase      usecase.DepositUseCase
+	NotificationUseCase usecase.NotificationUseCase
+	AuthUseCase         usecase.AuthUseCase
+	AccountUseCase      usecase.AccountUseCase
+	RoleUseCase         usecase.RoleUseCase
+	PosUseCase          usecase.PosUseCase
+	TwoFactorUseCase    usecase.TwoFactorUseCase
+	AuditUseCase        usecase.AuditUseCase
+	EmployeeUseCase     usecase.EmployeeUseCase
+	ShiftUseCase        usecase.ShiftUseCase
+	TimeClockUseCase    usecase.TimeClockUseCase
+	RateLimitUseCase    usecase.RateLimitUseCase
+	MaintenanceUseCase  usecase.MaintenanceUseCase
+	// PaymentReconcileUseCase is only run from the command line
+	PaymentReconcileUseCase usecase.PaymentReconcileUseCase
 
 	// Controllers
-	MenuController        *controller.MenuController
-	CustomerController    *controller.CustomerController
-	OrderController       *controller.OrderController
-	CartController        *controller.CartController
-	PaymentController     controller.PaymentController
-	WishlistController    *controller.WishListController
-	ReservationController *controller.ReservationController
-	InventoryController   *controller.InventoryController
-	TableController       *controller.TableController
+	MenuController         *controller.MenuController
+	CustomerController     *controller.CustomerController
+	OrderController        *controller.OrderController
+	CartController         *controller.CartController
+	PaymentController      controller.PaymentController
+	WishlistController     *controller.WishListController
+	ReservationController  *controller.ReservationController
+	InventoryController    *controller.InventoryController
+	TableController        *controller.TableController
+	BillController         *controller.BillController
+	FloorController        *controller.FloorController
+	DepositController      *controller.DepositController
+	NotificationController *controller.NotificationController
+	JWKSController         *controller.JWKSController
+	RoleController         *controller.RoleController
+	PosController          *controller.PosController
+	TwoFactorController    *controller.TwoFactorController
+	AuditController        *controller.AuditController
+	EmployeeController     *controller.EmployeeController
+	ShiftController        *controller.ShiftController
+	TimeClockController    *controller.TimeClockController
+	RateLimitController    *controller.RateLimitController
 
 	// Cache
-	Cache *database.RedisCacheService
+	Cache database.RedisCache
+
+	// Tokens signs and verifies access tokens
+	Tokens *token.Service
 }
 
 func NewApplication() *Application {
 	logger := utils.NewLogger()
 	cfg := configs.LoadConfig()
 	db := database.ConnectPostgres(cfg)
-	cache := database.NewRedisCacheService(context.Background(), "redis://dragonfly:6379/0")
-
-	if err := database.RunMigrations(db); err != nil {
-		log.Fatalf("❌ Failed to run database migrations: %v", err)
-	}
+	warnPendingMigrations(db)
 
 	app := fiber.New()
 
-	return &Application{
+	application := &Application{
 		App:    app,
 		Config: cfg,
 		DB:     db,
 		Logger: logger,
-		Cache:  cache,
+		Worker: worker.NewRunner(logger),
 	}
+	application.Cache = application.cache()
+	application.SecurityCache = application.securityCache()
+	application.RateLimiter = application.rateLimiter()
+	application.Lifecycle = lifecycle.NewManager(logger, application.shutdownDrain(), application.shutdownTimeout())
+	return application
 }
 
 func (a *Application) initializeRepositories() Dependencies {
@@ -101,53 +169,462 @@ func (a *Application) initializeRepositories() Dependencies {
 	deps.ReservationRepository = repository.NewReservationRepository(a.DB, a.Logger)
 	deps.InventoryRepository = repository.NewInventoryRepository(a.DB, a.Logger)
 	deps.TableRepository = repository.NewTableRepository(a.DB, a.Logger)
+	deps.BillRepository = repository.NewBillRepository(a.DB, a.Logger)
+	deps.FloorRepository = repository.NewFloorRepository(a.DB, a.Logger)
+	deps.DepositRepository = repository.NewDepositRepository(a.DB, a.Logger)
+	deps.NotificationRepository = repository.NewNotificationRepository(a.DB, a.Logger)
+	deps.RefreshTokenRepository = repository.NewRefreshTokenRepository(a.DB, a.Logger)
+	deps.AccountTokenRepository = repository.NewAccountTokenRepository(a.DB, a.Logger)
+	deps.RoleRepository = repository.NewRoleRepository(a.DB, a.Logger)
+	deps.PosRepository = repository.NewPosRepository(a.DB, a.Logger)
+	deps.LoginRepository = repository.NewLoginRepository(a.DB, a.Logger)
+	deps.TwoFactorRepository = repository.NewTwoFactorRepository(a.DB, a.Logger)
+	deps.AuditRepository = repository.NewAuditRepository(a.DB, a.Logger)
+	deps.EmployeeRepository = repository.NewEmployeeRepository(a.DB, a.Logger)
+	deps.TimeClockRepository = repository.NewTimeClockRepository(a.DB, a.Logger)
+	deps.SearchRepository = repository.NewSearchRepository(a.DB, a.Logger)
 
 	return deps
 }
 
 func (a *Application) initializeUseCases(deps *Dependencies) {
+	deps.Tokens = a.tokenService()
+	channel := a.notificationChannel()
+
 	// Initialize use cases
-	deps.MenuUseCase = usecase.NewMenuUseCase(deps.MenuRepository, a.Logger, a.Cache)
-	deps.CustomerUseCase = usecase.NewCustomerUseCase(deps.CustomerRepository, a.Logger, a.Config.JWT_SECRET, a.Cache)
+	deps.AuditUseCase = usecase.NewAuditUseCase(deps.AuditRepository, a.Logger)
+	deps.NotificationUseCase = usecase.NewNotificationUseCase(deps.NotificationRepository, deps.CustomerRepository, deps.ReservationRepository, channel, a.Logger)
+	deps.RoleUseCase = usecase.NewRoleUseCase(deps.RoleRepository, a.Logger, a.SecurityCache, deps.AuditUseCase)
+	deps.MenuUseCase = usecase.NewMenuUseCase(deps.MenuRepository, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.TwoFactorUseCase = usecase.NewTwoFactorUseCase(deps.TwoFactorRepository, deps.CustomerRepository, a.Logger, a.SecurityCache, a.twoFactorRoles(), deps.AuditUseCase)
+	deps.AuthUseCase = usecase.NewAuthUseCase(deps.CustomerRepository, deps.RefreshTokenRepository, deps.LoginRepository, deps.TwoFactorUseCase, a.Logger, a.SecurityCache, deps.Tokens, a.accessTokenTTL(), a.refreshTokenTTL(), deps.AuditUseCase)
+	deps.AccountUseCase = usecase.NewAccountUseCase(deps.CustomerRepository, deps.AccountTokenRepository, deps.AuthUseCase, channel, a.Logger, a.Cache, a.baseURL())
+	deps.PosUseCase = usecase.NewPosUseCase(deps.PosRepository, deps.CustomerRepository, deps.AuthUseCase, a.Logger, a.SecurityCache, a.posSessionTTL(), deps.AuditUseCase)
+	deps.EmployeeUseCase = usecase.NewEmployeeUseCase(deps.EmployeeRepository, deps.CustomerRepository, deps.RoleRepository, deps.AccountUseCase, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.ShiftUseCase = usecase.NewShiftUseCase(deps.EmployeeRepository, deps.CustomerRepository, deps.RoleRepository, a.Logger, deps.AuditUseCase)
+	deps.TimeClockUseCase = usecase.NewTimeClockUseCase(deps.TimeClockRepository, deps.EmployeeRepository, deps.CustomerRepository, a.Logger, a.payPolicy(), deps.AuditUseCase)
+	deps.RateLimitUseCase = usecase.NewRateLimitUseCase(a.RateLimiter, deps.CustomerRepository, a.Logger, deps.AuditUseCase)
+	deps.CustomerUseCase = usecase.NewCustomerUseCase(deps.CustomerRepository, deps.AccountTokenRepository, a.Logger, a.Cache, a.SecurityCache, deps.AuditUseCase)
 	deps.CartUseCase = usecase.NewCartUseCase(deps.CartRepository, deps.MenuRepository, a.Logger, a.Cache)
-	deps.OrderUseCase = usecase.NewOrderUseCase(deps.OrderRepository, deps.MenuRepository, deps.CustomerRepository, a.Logger, a.Config.SERVER_ENV, a.Cache)
+	deps.OrderUseCase = usecase.NewOrderUseCase(deps.OrderRepository, deps.MenuRepository, deps.CustomerRepository, deps.NotificationUseCase, a.Logger, a.Config.SERVER_ENV, a.Cache, deps.AuditUseCase)
 	deps.PaymentUseCase = usecase.NewPaymentUseCase(a.Config.MIDTRANS_ENDPOINT, deps.PaymentRepository, a.Logger, a.Config.SERVER_ENV, a.Cache)
 	deps.WishlistUseCase = usecase.NewWishListUseCase(deps.WishlistRepository, deps.MenuRepository, a.Logger, a.Cache)
-	deps.ReservationUseCase = usecase.NewReservationUseCase(deps.ReservationRepository, a.Logger, deps.TableRepository, a.Cache)
-	deps.InventoryUseCase = usecase.NewInventoryUseCase(deps.InventoryRepository, a.Logger, a.Cache)
-	deps.TableUseCase = usecase.NewTableUseCase(deps.TableRepository, a.Logger, a.Cache)
+	deps.InventoryUseCase = usecase.NewInventoryUseCase(deps.InventoryRepository, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.TableUseCase = usecase.NewTableUseCase(deps.TableRepository, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.BillUseCase = usecase.NewBillUseCase(deps.BillRepository, deps.OrderRepository, deps.PaymentRepository, deps.PaymentUseCase, deps.NotificationUseCase, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.FloorUseCase = usecase.NewFloorUseCase(deps.FloorRepository, deps.TableRepository, deps.ReservationRepository, deps.CustomerRepository, a.Logger, a.Cache, deps.ShiftUseCase, deps.AuditUseCase)
+	deps.DepositUseCase = usecase.NewDepositUseCase(deps.DepositRepository, a.Logger, a.Cache, deps.AuditUseCase)
+	deps.ReservationUseCase = usecase.NewReservationUseCase(deps.ReservationRepository, a.Logger, deps.TableRepository, a.Cache,
+		deps.DepositRepository, deps.CustomerRepository, deps.PaymentUseCase, deps.BillUseCase, a.depositExpiry(), a.changeCutoff(), deps.NotificationUseCase, deps.AuditUseCase)
+	deps.MaintenanceUseCase = usecase.NewMaintenanceUseCase(deps.SearchRepository, a.Logger, a.Cache, a.SecurityCache)
+	deps.PaymentReconcileUseCase = usecase.NewPaymentReconcileUseCase(deps.PaymentRepository, deps.PaymentUseCase, deps.BillUseCase, deps.ReservationUseCase, a.Logger)
 }
 
 func (a *Application) initializeControllers(deps *Dependencies) {
 	// Initialize controllers
 	deps.MenuController = controller.NewMenuController(deps.MenuUseCase, a.Logger)
-	deps.CustomerController = controller.NewCustomerController(deps.CustomerUseCase, a.Logger)
+	deps.CustomerController = controller.NewCustomerController(deps.CustomerUseCase, deps.AuthUseCase, deps.AccountUseCase, a.Logger)
 	deps.OrderController = controller.NewOrderController(deps.OrderUseCase, deps.PaymentUseCase, a.Logger)
 	deps.CartController = controller.NewCartController(deps.CartUseCase, a.Logger)
-	deps.PaymentController = controller.NewPaymentController(a.Logger, a.Config.MIDTRANS_SERVER_KEY, deps.OrderUseCase, deps.PaymentUseCase)
+	deps.PaymentController = controller.NewPaymentController(a.Logger, a.Config.MIDTRANS_SERVER_KEY, deps.OrderUseCase, deps.PaymentUseCase, deps.BillUseCase, deps.ReservationUseCase)
 	deps.WishlistController = controller.NewWishListController(deps.WishlistUseCase, a.Logger)
-	deps.ReservationController = controller.NewReservationController(deps.ReservationUseCase, a.Logger)
+	deps.ReservationController = controller.NewReservationController(deps.ReservationUseCase, deps.RoleUseCase, a.Logger)
 	deps.InventoryController = controller.NewInventoryController(deps.InventoryUseCase, a.Logger)
 	deps.TableController = controller.NewTableController(deps.TableUseCase, a.Logger)
+	deps.BillController = controller.NewBillController(deps.BillUseCase, a.Logger)
+	deps.FloorController = controller.NewFloorController(deps.FloorUseCase, a.Logger)
+	deps.DepositController = controller.NewDepositController(deps.DepositUseCase, a.Logger)
+	deps.NotificationController = controller.NewNotificationController(deps.NotificationUseCase, a.Logger)
+	deps.JWKSController = controller.NewJWKSController(deps.Tokens)
+	deps.RoleController = controller.NewRoleController(deps.RoleUseCase, a.Logger)
+	deps.PosController = controller.NewPosController(deps.PosUseCase, a.Logger)
+	deps.TwoFactorController = controller.NewTwoFactorController(deps.TwoFactorUseCase, deps.AuthUseCase, a.Logger)
+	deps.AuditController = controller.NewAuditController(deps.AuditUseCase, a.Logger)
+	deps.EmployeeController = controller.NewEmployeeController(deps.EmployeeUseCase, a.Logger)
+	deps.ShiftController = controller.NewShiftController(deps.ShiftUseCase, a.Logger)
+	deps.TimeClockController = controller.NewTimeClockController(deps.TimeClockUseCase, a.Logger)
+	deps.RateLimitController = controller.NewRateLimitController(deps.RateLimitUseCase, a.Logger)
 }
 
-func (a *Application) seedDatabase(deps *Dependencies) {
-	// Initialize and run seeder
-	dbSeeder := seeder.NewSeeder(deps.CustomerRepository, deps.MenuRepository, a.Logger, deps.InventoryRepository, deps.TableRepository)
-	if err := dbSeeder.SeedAll(); err != nil {
-		log.Printf("⚠️ Warning: Failed to seed database: %v", err)
+// warnPendingMigrations logs migrations that weren't applied yet, the schema
+// is only changed by the migrate command
+func warnPendingMigrations(db *gorm.DB) {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to load database migrations: %v", err)
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		log.Printf("⚠️ Warning: Failed to check database migrations: %v", err)
+		return
+	}
+	if pending > 0 {
+		log.Printf("⚠️ Warning: %d database migrations are pending, run `migrate up`", pending)
 	}
 }
 
-func (a *Application) setupHealthCheck() {
-	healthChecker := health.NewHealthChecker(a.DB)
-	a.App.Get("/health", func(c *fiber.Ctx) error {
-		health := healthChecker.Check()
-		if health.Status != "healthy" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(health)
+// Seed fills the database with the built-in roles, staff accounts and demo
+// data, skipping what already exists. names picks seeds from seeder.Seeds,
+// all of them run when there are none.
+func (a *Application) Seed(ctx context.Context, names ...string) error {
+	deps := a.initializeRepositories()
+	dbSeeder := seeder.NewSeeder(deps.CustomerRepository, deps.MenuRepository, a.Logger, deps.InventoryRepository, deps.TableRepository, deps.RoleRepository)
+	if len(names) == 0 {
+		return dbSeeder.SeedAll(ctx)
+	}
+	return dbSeeder.Seed(ctx, names...)
+}
+
+// UseCases wires the repositories and use cases the server runs on, without
+// controllers, routes or workers, for commands run outside the server
+func (a *Application) UseCases() *Dependencies {
+	deps := a.initializeRepositories()
+	a.initializeUseCases(&deps)
+	return &deps
+}
+
+// depositExpiry is how long a reservation deposit can stay unpaid, 30 minutes by default
+func (a *Application) depositExpiry() time.Duration {
+	if a.Config.DEPOSIT_EXPIRY_MINUTES <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(a.Config.DEPOSIT_EXPIRY_MINUTES) * time.Minute
+}
+
+// changeCutoff is how long before a reservation customers can still change it, 2 hours by default
+func (a *Application) changeCutoff() time.Duration {
+	if a.Config.RESERVATION_CHANGE_CUTOFF_MINUTES <= 0 {
+		return 2 * time.Hour
+	}
+	return time.Duration(a.Config.RESERVATION_CHANGE_CUTOFF_MINUTES) * time.Minute
+}
+
+// tokenService loads the JWT signing and verification keys
+func (a *Application) tokenService() *token.Service {
+	issuer := a.Config.JWT_ISSUER
+	if issuer == "" {
+		issuer = "cakestore"
+	}
+	audience := a.Config.JWT_AUDIENCE
+	if audience == "" {
+		audience = "cakestore-api"
+	}
+
+	tokens, err := token.NewService(token.Config{
+		Algorithm:              a.Config.JWT_ALGORITHM,
+		Secret:                 a.Config.JWT_SECRET,
+		PrivateKeyFile:         a.Config.JWT_PRIVATE_KEY_FILE,
+		KeyID:                  a.Config.JWT_KEY_ID,
+		PreviousPublicKeyFiles: a.Config.JWT_PREVIOUS_PUBLIC_KEYS,
+		Issuer:                 issuer,
+		Audience:               audience,
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to load JWT keys: %v", err)
+	}
+	return tokens
+}
+
+// accessTokenTTL is how long an access token is valid, 15 minutes by default
+func (a *Application) accessTokenTTL() time.Duration {
+	if a.Config.ACCESS_TOKEN_TTL_MINUTES <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(a.Config.ACCESS_TOKEN_TTL_MINUTES) * time.Minute
+}
+
+// refreshTokenTTL is how long a session can go without refreshing, 30 days by default
+func (a *Application) refreshTokenTTL() time.Duration {
+	if a.Config.REFRESH_TOKEN_TTL_DAYS <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(a.Config.REFRESH_TOKEN_TTL_DAYS) * 24 * time.Hour
+}
+
+// posSessionTTL is how long a PIN session on a POS terminal lasts, 30 minutes by default
+func (a *Application) posSessionTTL() time.Duration {
+	if a.Config.POS_SESSION_TTL_MINUTES <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(a.Config.POS_SESSION_TTL_MINUTES) * time.Minute
+}
+
+// twoFactorRoles are the roles that must log in with two-factor
+// authentication, admins and cashiers by default
+func (a *Application) twoFactorRoles() []string {
+	if a.Config.TWO_FACTOR_REQUIRED_ROLES == nil {
+		return []string{constants.RoleAdmin, constants.RoleCashier}
+	}
+	return a.Config.TWO_FACTOR_REQUIRED_ROLES
+}
+
+// payPolicy configures pay periods and overtime, by default two week periods
+// starting on Monday 5 January 2026 with overtime past 40 hours a week paid
+// at 1.5 times the hourly rate
+func (a *Application) payPolicy() usecase.PayPolicy {
+	policy := usecase.PayPolicy{
+		PeriodStart:        time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local),
+		PeriodDays:         14,
+		WeeklyOvertime:     40 * time.Hour,
+		OvertimeMultiplier: 1.5,
+	}
+	if a.Config.PAY_PERIOD_START != "" {
+		start, err := time.ParseInLocation("2006-01-02", a.Config.PAY_PERIOD_START, time.Local)
+		if err != nil {
+			log.Fatalf("❌ Invalid PAY_PERIOD_START, expected YYYY-MM-DD: %v", err)
+		}
+		policy.PeriodStart = start
+	}
+	if a.Config.PAY_PERIOD_DAYS > 0 {
+		policy.PeriodDays = a.Config.PAY_PERIOD_DAYS
+	}
+	if a.Config.OVERTIME_WEEKLY_HOURS > 0 {
+		policy.WeeklyOvertime = time.Duration(a.Config.OVERTIME_WEEKLY_HOURS * float64(time.Hour))
+	}
+	if a.Config.OVERTIME_MULTIPLIER > 0 {
+		policy.OvertimeMultiplier = a.Config.OVERTIME_MULTIPLIER
+	}
+	return policy
+}
+
+// redisClient connects to Redis at REDIS_URL once, the caches and the rate
+// limiter share the client
+func (a *Application) redisClient() *redis.Client {
+	if a.Redis == nil {
+		redisURL := a.Config.REDIS_ADDR
+		if redisURL == "" {
+			redisURL = "redis://dragonfly:6379/0"
+		}
+		a.Redis = database.ConnectRedis(context.Background(), redisURL)
+	}
+	return a.Redis
+}
+
+// cache builds the backend picked by CACHE_BACKEND, Redis by default. The
+// in-process cache holds 10000 keys and the tiered cache keeps local copies
+// for 30 seconds unless configured otherwise.
+func (a *Application) cache() database.RedisCache {
+	maxEntries := a.Config.CACHE_MAX_ENTRIES
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	localTTL := 30 * time.Second
+	if a.Config.CACHE_LOCAL_TTL_SECONDS > 0 {
+		localTTL = time.Duration(a.Config.CACHE_LOCAL_TTL_SECONDS) * time.Second
+	}
+
+	switch a.Config.CACHE_BACKEND {
+	case "", "redis":
+		return database.NewRedisCacheServiceWithClient(a.redisClient())
+	case "memory":
+		return database.NewMemoryCache(maxEntries)
+	case "tiered":
+		return database.NewTieredCache(database.NewMemoryCache(maxEntries),
+			database.NewRedisCacheServiceWithClient(a.redisClient()), localTTL)
+	case "none":
+		return database.NewNoopCache()
+	default:
+		log.Fatalf("❌ Unknown CACHE_BACKEND %q, expected redis, memory, tiered or none", a.Config.CACHE_BACKEND)
+		return nil
+	}
+}
+
+// securityCache is Redis whatever the cache backend, with CACHE_BACKEND=redis
+// it is the cache itself
+func (a *Application) securityCache() database.RedisCache {
+	if a.Config.CACHE_BACKEND == "" || a.Config.CACHE_BACKEND == "redis" {
+		return a.Cache
+	}
+	return database.NewRedisCacheServiceWithClient(a.redisClient())
+}
+
+// rateLimitPolicy holds the limit of each route group, counted with the
+// sliding window algorithm unless RATE_LIMIT_ALGORITHM says otherwise.
+// Anonymous clients and customers get the group limits and staff roles ten
+// times as much. RATE_LIMITS overrides single groups, roles or the staff
+// tier within them.
+func (a *Application) rateLimitPolicy() *ratelimit.Policy {
+	algorithm := ratelimit.SlidingWindow
+	if a.Config.RATE_LIMIT_ALGORITHM != "" {
+		var err error
+		if algorithm, err = ratelimit.ParseAlgorithm(a.Config.RATE_LIMIT_ALGORITHM); err != nil {
+			log.Fatalf("❌ Invalid RATE_LIMIT_ALGORITHM: %v", err)
+		}
+	}
+
+	policy := ratelimit.NewPolicy(algorithm)
+	policy.Set(constants.RateLimitGlobal, "", ratelimit.Rule{Max: 100, Window: time.Hour})
+	policy.Set(constants.RateLimitStrict, "", ratelimit.Rule{Max: 20, Window: time.Minute})
+	policy.Set(constants.RateLimitAuth, "", ratelimit.Rule{Max: 5, Window: 15 * time.Minute})
+	policy.Set(constants.RateLimitPaymentWebhook, "", ratelimit.Rule{Max: 10, Window: time.Hour})
+	policy.Set(constants.RateLimitMenusPublic, "", ratelimit.Rule{Max: 50, Window: 15 * time.Minute})
+	policy.Set(constants.RateLimitAPI, "", ratelimit.Rule{Max: 200, Window: time.Hour})
+	policy.Set(constants.RateLimitCarts, "", ratelimit.Rule{Max: 100, Window: 15 * time.Minute})
+	policy.Set(constants.RateLimitOrders, "", ratelimit.Rule{Max: 50, Window: time.Hour})
+	policy.Set(constants.RateLimitPayments, "", ratelimit.Rule{Max: 10, Window: time.Hour})
+	policy.Set(constants.RateLimitWishlists, "", ratelimit.Rule{Max: 30, Window: 15 * time.Minute})
+	policy.Set(constants.RateLimitReservations, "", ratelimit.Rule{Max: 20, Window: time.Hour})
+	policy.Set(constants.RateLimitInventories, "", ratelimit.Rule{Max: 100, Window: time.Hour})
+	policy.Set(constants.RateLimitTables, "", ratelimit.Rule{Max: 80, Window: time.Hour})
+
+	// Staff get ten times the limits of customers on authenticated routes
+	policy.SetTiers(func(role string) string {
+		if role != constants.RoleCustomer {
+			return constants.RateLimitTierStaff
 		}
-		return c.JSON(health)
+		return role
 	})
+	policy.Scale(constants.RateLimitTierStaff, 10, constants.RateLimitAPI, constants.RateLimitCarts,
+		constants.RateLimitOrders, constants.RateLimitPayments, constants.RateLimitWishlists,
+		constants.RateLimitReservations, constants.RateLimitInventories, constants.RateLimitTables)
+
+	if err := policy.Parse(a.Config.RATE_LIMITS); err != nil {
+		log.Fatalf("❌ Invalid RATE_LIMITS: %v", err)
+	}
+	return policy
+}
+
+// rateLimiter counts requests in Redis so limits hold across replicas and
+// restarts, RATE_LIMIT_STORE=memory counts per process instead
+func (a *Application) rateLimiter() *ratelimit.Limiter {
+	var store ratelimit.Store
+	switch a.Config.RATE_LIMIT_STORE {
+	case "", "redis":
+		store = ratelimit.NewRedisStore(a.redisClient())
+	case "memory":
+		store = ratelimit.NewMemoryStore(time.Minute)
+	default:
+		log.Fatalf("❌ Unknown RATE_LIMIT_STORE %q, expected redis or memory", a.Config.RATE_LIMIT_STORE)
+	}
+	return ratelimit.NewLimiter(store, a.rateLimitPolicy())
+}
+
+// requestTimeout bounds the work done for a request, 10 seconds by default
+func (a *Application) requestTimeout() time.Duration {
+	if a.Config.REQUEST_TIMEOUT_SECONDS <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(a.Config.REQUEST_TIMEOUT_SECONDS) * time.Second
+}
+
+// shutdownDrain is how long readiness fails before the server stops
+// accepting connections, 5 seconds by default
+func (a *Application) shutdownDrain() time.Duration {
+	if a.Config.SHUTDOWN_DRAIN_SECONDS < 0 {
+		return 0
+	}
+	if a.Config.SHUTDOWN_DRAIN_SECONDS == 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(a.Config.SHUTDOWN_DRAIN_SECONDS) * time.Second
+}
+
+// shutdownTimeout bounds the time in-flight requests and running jobs get
+// to finish once draining is over, 20 seconds by default
+func (a *Application) shutdownTimeout() time.Duration {
+	if a.Config.SHUTDOWN_TIMEOUT_SECONDS <= 0 {
+		return 20 * time.Second
+	}
+	return time.Duration(a.Config.SHUTDOWN_TIMEOUT_SECONDS) * time.Second
+}
+
+// baseURL is the public address used in emailed links
+func (a *Application) baseURL() string {
+	if a.Config.APP_BASE_URL == "" {
+		return "http://localhost:" + a.Config.SERVER_PORT
+	}
+	return a.Config.APP_BASE_URL
+}
+
+// notificationChannel picks the delivery channel, the file channel is used
+// unless SMTP is configured so local setups need no mail server
+func (a *Application) notificationChannel() notification.Channel {
+	if a.Config.NOTIFICATION_CHANNEL == "smtp" && a.Config.SMTP_HOST != "" {
+		return notification.NewSMTPChannel(notification.SMTPConfig{
+			Host:     a.Config.SMTP_HOST,
+			Port:     a.Config.SMTP_PORT,
+			Username: a.Config.SMTP_USERNAME,
+			Password: a.Config.SMTP_PASSWORD,
+			From:     a.Config.SMTP_FROM,
+		})
+	}
+	return notification.NewFileChannel(a.Config.NOTIFICATION_FILE, a.Logger)
+}
+
+func (a *Application) setupWorkers(deps *Dependencies) {
+	// Cancel reservations whose deposit was not paid in time, in case the
+	// expiry notification from Midtrans never arrives
+	a.Worker.Add(worker.Job{
+		Name:     "expire-reservation-deposits",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			expired, err := deps.ReservationUseCase.ExpireDeposits(ctx)
+			if expired > 0 {
+				a.Logger.Infof("Cancelled %d reservations with unpaid deposits", expired)
+			}
+			return err
+		},
+	})
+	a.Worker.Add(worker.Job{
+		Name:     "dispatch-notifications",
+		Interval: 15 * time.Second,
+		Run: func(ctx context.Context) error {
+			_, err := deps.NotificationUseCase.Dispatch(ctx)
+			return err
+		},
+	})
+	a.Worker.Add(worker.Job{
+		Name:     "queue-reservation-reminders",
+		Interval: 5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := deps.NotificationUseCase.QueueReservationReminders(ctx)
+			return err
+		},
+	})
+}
+
+// healthCacheTTL is how long check results are reused, so probes from every
+// load balancer don't each reach the dependencies
+const healthCacheTTL = 5 * time.Second
+
+// setupHealthCheck serves the probes. /livez only tells the process is up,
+// /readyz checks the dependencies and fails while draining, /health is kept
+// for existing load balancer configurations.
+func (a *Application) setupHealthCheck() {
+	checks := health.NewRegistry(healthCacheTTL)
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		log.Fatalf("❌ Failed to get database connection pool: %v", err)
+	}
+	checks.Register(health.DatabaseCheck(sqlDB))
+	if err := health.RegisterPoolMetrics(prometheus.DefaultRegisterer, sqlDB); err != nil {
+		a.Logger.Warnf("Database pool metrics not exposed: %v", err)
+	}
+	checks.Register(health.RedisCheck(a.redisClient()))
+	if a.Config.MIDTRANS_ENDPOINT != "" {
+		midtrans := health.HTTPCheck("midtrans", a.Config.MIDTRANS_ENDPOINT)
+		midtrans.Timeout = 3 * time.Second
+		checks.Register(midtrans)
+	}
+	checks.Register(health.Check{Name: "workers", Run: a.Worker.Check})
+
+	a.App.Get("/livez", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "alive", "timestamp": time.Now()})
+	})
+	ready := func(c *fiber.Ctx) error {
+		status := checks.Check(c.UserContext())
+		// Fail while draining so load balancers stop sending requests
+		// before the server stops accepting them
+		if !a.Lifecycle.Ready() {
+			status.Status = health.StatusDraining
+		}
+		if status.Status != health.StatusHealthy && status.Status != health.StatusDegraded {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(status)
+		}
+		return c.JSON(status)
+	}
+	a.App.Get("/readyz", ready)
+	a.App.Get("/health", ready)
 }
 
 // New function to set up Prometheus metrics
@@ -170,18 +647,36 @@ func (a *Application) setupPrometheus() {
 
 func (a *Application) setupRoutes(deps *Dependencies) {
 	routeConfig := route.RouteConfig{
-		App:                   a.App,
-		MenuController:        deps.MenuController,
-		CustomerController:    deps.CustomerController,
-		CartController:        deps.CartController,
-		OrderController:       deps.OrderController,
-		PaymentController:     deps.PaymentController,
-		WishlistController:    deps.WishlistController,
-		ReservationController: deps.ReservationController,
-		InventoryController:   deps.InventoryController,
-		TableController:       deps.TableController,
-		JWTSecret:             a.Config.JWT_SECRET,
-		Log:                   a.Logger,
+		App:                    a.App,
+		MenuController:         deps.MenuController,
+		CustomerController:     deps.CustomerController,
+		CartController:         deps.CartController,
+		OrderController:        deps.OrderController,
+		PaymentController:      deps.PaymentController,
+		WishlistController:     deps.WishlistController,
+		ReservationController:  deps.ReservationController,
+		InventoryController:    deps.InventoryController,
+		TableController:        deps.TableController,
+		BillController:         deps.BillController,
+		FloorController:        deps.FloorController,
+		DepositController:      deps.DepositController,
+		NotificationController: deps.NotificationController,
+		JWKSController:         deps.JWKSController,
+		RoleController:         deps.RoleController,
+		PosController:          deps.PosController,
+		TwoFactorController:    deps.TwoFactorController,
+		AuditController:        deps.AuditController,
+		EmployeeController:     deps.EmployeeController,
+		ShiftController:        deps.ShiftController,
+		TimeClockController:    deps.TimeClockController,
+		RateLimitController:    deps.RateLimitController,
+		AuthUseCase:            deps.AuthUseCase,
+		RoleUseCase:            deps.RoleUseCase,
+		PosUseCase:             deps.PosUseCase,
+		Tokens:                 deps.Tokens,
+		RateLimiter:            a.RateLimiter,
+		Log:                    a.Logger,
+		RequestTimeout:         a.requestTimeout(),
 	}
 	routeConfig.Setup()
 }
@@ -192,8 +687,8 @@ func (a *Application) Bootstrap() {
 	a.initializeUseCases(&deps)
 	a.initializeControllers(&deps)
 
-	// Seed database
-	a.seedDatabase(&deps)
+	// Setup background workers
+	a.setupWorkers(&deps)
 
 	// Setup health check
 	a.setupHealthCheck()
@@ -205,11 +700,49 @@ func (a *Application) Bootstrap() {
 	a.setupRoutes(&deps)
 }
 
-func (a *Application) Start() {
+// setupShutdown registers what is stopped on shutdown. The HTTP server goes
+// first so in-flight requests such as payment webhooks can still use the
+// workers' dependencies, the connections they all share go last.
+func (a *Application) setupShutdown() {
+	a.Lifecycle.Add("http server", a.App.ShutdownWithContext)
+	a.Lifecycle.Add("workers", a.Worker.Stop)
+	a.Lifecycle.Add("rate limiter", func(context.Context) error {
+		return a.RateLimiter.Close()
+	})
+	a.Lifecycle.Add("redis", func(context.Context) error {
+		if a.Redis == nil {
+			return nil
+		}
+		return a.Redis.Close()
+	})
+	a.Lifecycle.Add("database", func(context.Context) error {
+		sqlDB, err := a.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+}
+
+// Start serves requests and runs the background workers until ctx is
+// cancelled, then shuts down gracefully
+func (a *Application) Start(ctx context.Context) error {
 	port := a.Config.SERVER_PORT
 	if port == "" {
 		port = "8080"
 	}
+
+	a.setupShutdown()
+	// Workers are stopped by the shutdown, after the HTTP server
+	a.Worker.Start(context.WithoutCancel(ctx))
+
 	log.Printf("🚀 Server running on port %s", port)
-	log.Fatal(a.App.Listen("0.0.0.0:" + port))
+	err := a.Lifecycle.Run(ctx, func() error {
+		return a.App.Listen("0.0.0.0:" + port)
+	})
+	if err != nil {
+		return err
+	}
+	log.Println("👋 Server stopped")
+	return nil
 }
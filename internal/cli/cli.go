@@ -0,0 +1,147 @@
+// Package cli runs the commands of the binary. They share the wiring of
+// package bootstrap, only serve starts the HTTP server so maintenance tasks
+// can run from the same image next to it.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "serve", "Start the HTTP server, the default", runServe},
+		{"migrate", "migrate up | down [n] | status | create <name>", "Apply, roll back, list or create database migrations", runMigrate},
+		{"seed", "seed [--only=menus,tables]", "Seed roles, staff accounts and demo data, skipping what exists", runSeed},
+		{"create-admin", "create-admin --email=<email> [--name=<name>] [--password=<password>]", "Create an admin account, the password is read from stdin unless given", runCreateAdmin},
+		{"reindex-search", "reindex-search", "Rebuild the menu and inventory search indexes", runReindexSearch},
+		{"cache", "cache flush [--prefix=menu:,orders:]", "Drop every cached read, or the keys under the given prefixes", runCache},
+		{"reconcile-payments", "reconcile-payments [--since=24h|2026-10-01]", "Apply the Midtrans status of payments still pending", runReconcilePayments},
+		{"export", "export orders --from=<date> [--to=<date>] [--format=csv|json] [--output=<file>]", "Export the orders created between two dates, both included", runExport},
+	}
+}
+
+// usageError is a mistake in the arguments, it's reported together with
+// the usage of the command
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...any) error {
+	return usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// helpError asks for the usage of a command, flags lists its flags
+type helpError struct {
+	flags string
+}
+
+func (e helpError) Error() string {
+	return flag.ErrHelp.Error()
+}
+
+func (e helpError) Unwrap() error {
+	return flag.ErrHelp
+}
+
+// Run runs the command named by the first argument, serve when there is
+// none, and returns the exit code. SIGINT and SIGTERM cancel the context
+// commands run with, serve shuts down gracefully on them.
+func Run(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != name {
+			continue
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := cmd.run(ctx, args)
+		stop()
+		var usageErr usageError
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			fmt.Printf("Usage: main %s\n\n%s\n", cmd.usage, cmd.summary)
+			var help helpError
+			if errors.As(err, &help) && help.flags != "" {
+				fmt.Printf("\nFlags:\n%s", help.flags)
+			}
+			return 0
+		case errors.As(err, &usageErr):
+			fmt.Fprintf(os.Stderr, "%v\n\nUsage: main %s\n", err, cmd.usage)
+			return 2
+		default:
+			log.Printf("❌ %v", err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "Usage: main <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-20s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun main <command> --help for the arguments of a command.\n")
+}
+
+// parseFlags parses the flags of a command, mistakes are returned as a
+// usageError instead of being printed by the flag package
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			var defaults strings.Builder
+			flags.SetOutput(&defaults)
+			flags.PrintDefaults()
+			return helpError{flags: defaults.String()}
+		}
+		return usagef("%v", err)
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
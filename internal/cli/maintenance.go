@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bufio"
+	"cakestore/internal/bootstrap"
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func runCreateAdmin(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email address the admin logs in with")
+	name := flags.String("name", "Admin", "name of the admin")
+	address := flags.String("address", "Admin Address", "address of the admin")
+	password := flags.String("password", "", "password, read from stdin when empty so it stays out of the shell history")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return usagef("--email is required")
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read the password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	request := &model.CreateCustomerRequest{Name: *name, Email: *email, Password: *password, Address: *address}
+	if err := validator.New().Struct(request); err != nil {
+		return usagef("invalid admin: %v", err)
+	}
+
+	deps := bootstrap.NewApplication().UseCases()
+	admin, err := deps.CustomerUseCase.Register(ctx, request, constants.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+	log.Printf("✅ Created admin %s with id %d", admin.Email, admin.ID)
+	return nil
+}
+
+func runReindexSearch(ctx context.Context, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("reindex-search", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	indexes, err := bootstrap.NewApplication().UseCases().MaintenanceUseCase.ReindexSearch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild search indexes after %d of them: %w", len(indexes), err)
+	}
+	log.Printf("✅ Rebuilt %s", strings.Join(indexes, ", "))
+	return nil
+}
+
+func runCache(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usagef("missing cache command")
+	}
+	if args[0] == "-h" || args[0] == "--help" {
+		return flag.ErrHelp
+	}
+	if args[0] != "flush" {
+		return usagef("unknown cache command %q", args[0])
+	}
+	flags := flag.NewFlagSet("cache flush", flag.ContinueOnError)
+	prefixes := flags.String("prefix", "", "comma separated key prefixes, every cached read when empty")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	app := bootstrap.NewApplication()
+	switch app.Config.CACHE_BACKEND {
+	case "memory", "none":
+		log.Printf("⚠️ CACHE_BACKEND is %s, every server process caches on its own and can't be flushed from here", app.Config.CACHE_BACKEND)
+		return nil
+	case "tiered":
+		log.Println("⚠️ Servers keep local copies of cached reads until they expire, see CACHE_LOCAL_TTL_SECONDS")
+	}
+
+	flushed, err := app.UseCases().MaintenanceUseCase.FlushCache(ctx, splitList(*prefixes))
+	if err != nil {
+		return fmt.Errorf("failed to flush cache after %d prefixes: %w", len(flushed), err)
+	}
+	log.Printf("✅ Flushed %s", strings.Join(flushed, ", "))
+	return nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"cakestore/internal/bootstrap"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+func runReconcilePayments(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reconcile-payments", flag.ContinueOnError)
+	sinceFlag := flags.String("since", "24h", "how far back to look, a duration or a date such as 2026-10-01")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	since, err := parseSince(*sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Changes are audited as made by the system, like those of the webhook
+	deps := bootstrap.NewApplication().UseCases()
+	result, err := deps.PaymentReconcileUseCase.Reconcile(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile payments: %w", err)
+	}
+	log.Printf("✅ Checked %d pending payments since %s: %d updated, %d still pending, %d failed",
+		result.Checked, since.Format(time.RFC3339), result.Updated, result.Unchanged, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d payments could not be reconciled, see the log above", result.Failed)
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usagef("missing what to export")
+	}
+	if args[0] == "-h" || args[0] == "--help" {
+		return flag.ErrHelp
+	}
+	if args[0] != "orders" {
+		return usagef("unknown export %q, only orders can be exported", args[0])
+	}
+
+	flags := flag.NewFlagSet("export orders", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "first day to export, YYYY-MM-DD")
+	toFlag := flags.String("to", "", "last day to export, YYYY-MM-DD, today when empty")
+	format := flags.String("format", model.OrderExportCSV, "csv or json, json writes one order per line")
+	output := flags.String("output", "", "file to write, stdout when empty")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return usagef("--from is required")
+	}
+	from, err := time.ParseInLocation(time.DateOnly, *fromFlag, time.Local)
+	if err != nil {
+		return usagef("invalid --from, expected YYYY-MM-DD")
+	}
+	to := time.Now()
+	if *toFlag != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, *toFlag, time.Local); err != nil {
+			return usagef("invalid --to, expected YYYY-MM-DD")
+		}
+	}
+	// The last day is included
+	to = time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, time.Local)
+	if *format != model.OrderExportCSV && *format != model.OrderExportJSON {
+		return usagef("unknown format %q, expected csv or json", *format)
+	}
+
+	deps := bootstrap.NewApplication().UseCases()
+	query := &model.OrderExportQuery{From: from, To: to, Format: *format}
+	if *output == "" {
+		return exportOrders(ctx, deps.OrderUseCase, query, os.Stdout)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := exportOrders(ctx, deps.OrderUseCase, query, file); err != nil {
+		file.Close()
+		return err
+	}
+	// Buffered writes can still fail on close
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	log.Printf("✅ Exported orders to %s", *output)
+	return nil
+}
+
+func exportOrders(ctx context.Context, orders usecase.OrderUseCase, query *model.OrderExportQuery, w io.Writer) error {
+	if err := orders.Export(ctx, query, w); err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+	return nil
+}
+
+// parseSince reads a duration before now, a date or an RFC 3339 time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, usagef("invalid --since %q, expected a duration such as 24h or a date such as 2026-10-01", value)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"cakestore/internal/bootstrap"
+	configs "cakestore/internal/config"
+	"cakestore/internal/database"
+	"cakestore/internal/seeder"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func runServe(ctx context.Context, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("serve", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+	app := bootstrap.NewApplication()
+	app.Bootstrap()
+	return app.Start(ctx)
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usagef("missing migrate command")
+	}
+	if args[0] == "-h" || args[0] == "--help" {
+		return flag.ErrHelp
+	}
+
+	// Creating files needs no database
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return usagef("migrate create takes the name of the migration")
+		}
+		paths, err := database.CreateMigration(database.MigrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			log.Printf("✅ Created %s", path)
+		}
+		return nil
+	}
+
+	steps := 1
+	switch {
+	case args[0] == "down" && len(args) == 2:
+		var err error
+		if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+			return usagef("invalid number of migrations %q", args[1])
+		}
+	case args[0] != "up" && args[0] != "down" && args[0] != "status":
+		return usagef("unknown migrate command %q", args[0])
+	case len(args) > 1:
+		return usagef("unexpected argument %q", args[1])
+	}
+
+	migrator, err := database.NewMigrator(database.ConnectPostgres(configs.LoadConfig()))
+	if err != nil {
+		return fmt.Errorf("failed to load database migrations: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Applied %d migrations", len(applied))
+	case "down":
+		rolledBack, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Rolled back %d migrations", len(rolledBack))
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d  %-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+	}
+	return nil
+}
+
+func runSeed(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	only := flags.String("only", "", "comma separated seeds to run: roles, customers, inventory, menus, tables")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	names := splitList(*only)
+	for _, name := range names {
+		if !slices.Contains(seeder.Seeds, name) {
+			return usagef("unknown seed %q, expected one of %s", name, strings.Join(seeder.Seeds, ", "))
+		}
+	}
+
+	if err := bootstrap.NewApplication().Seed(ctx, names...); err != nil {
+		return fmt.Errorf("failed to seed database: %w", err)
+	}
+	log.Println("✅ Database seeded")
+	return nil
+}
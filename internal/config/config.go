@@ -1,5 +1,7 @@
 package configs
 
+import "strings"
+
 type Config struct {
 	DBName               string
 	DBPassword           string
@@ -14,6 +16,68 @@ type Config struct {
 	SERVER_ENV           string
 	SERVER_PORT          string
 	REDIS_ADDR           string
+	// DEPOSIT_EXPIRY_MINUTES is how long a reservation deposit can stay unpaid
+	DEPOSIT_EXPIRY_MINUTES int
+	// RESERVATION_CHANGE_CUTOFF_MINUTES is how long before the reservation
+	// customers can still cancel or modify it themselves
+	RESERVATION_CHANGE_CUTOFF_MINUTES int
+	// NOTIFICATION_CHANNEL is smtp or file, file writes messages to NOTIFICATION_FILE
+	NOTIFICATION_CHANNEL string
+	NOTIFICATION_FILE    string
+	SMTP_HOST            string
+	SMTP_PORT            string
+	SMTP_USERNAME        string
+	SMTP_PASSWORD        string
+	SMTP_FROM            string
+	// ACCESS_TOKEN_TTL_MINUTES and REFRESH_TOKEN_TTL_DAYS control session lifetimes
+	ACCESS_TOKEN_TTL_MINUTES int
+	REFRESH_TOKEN_TTL_DAYS   int
+	// JWT_ALGORITHM is HS256 (signed with JWT_SECRET), RS256 or EdDSA (signed
+	// with JWT_PRIVATE_KEY_FILE). JWT_PREVIOUS_PUBLIC_KEYS lists retired public
+	// keys, optionally as kid=path, that still verify tokens during rotation
+	JWT_ALGORITHM            string
+	JWT_PRIVATE_KEY_FILE     string
+	JWT_KEY_ID               string
+	JWT_PREVIOUS_PUBLIC_KEYS []string
+	JWT_ISSUER               string
+	JWT_AUDIENCE             string
+	// APP_BASE_URL is the public address used in emailed links
+	APP_BASE_URL string
+	// POS_SESSION_TTL_MINUTES is how long a PIN login on a POS terminal lasts
+	POS_SESSION_TTL_MINUTES int
+	// TWO_FACTOR_REQUIRED_ROLES lists the roles that must use two-factor
+	// authentication, "none" enforces it for no role
+	TWO_FACTOR_REQUIRED_ROLES []string
+	// PAY_PERIOD_START (YYYY-MM-DD) and PAY_PERIOD_DAYS define the pay
+	// periods of timesheets, OVERTIME_WEEKLY_HOURS is the regular work per
+	// week and OVERTIME_MULTIPLIER the pay rate of the hours beyond it
+	PAY_PERIOD_START      string
+	PAY_PERIOD_DAYS       int
+	OVERTIME_WEEKLY_HOURS float64
+	OVERTIME_MULTIPLIER   float64
+	// CACHE_BACKEND is redis, memory (in-process LRU), tiered (memory in
+	// front of redis) or none. CACHE_MAX_ENTRIES bounds the in-process cache
+	// and CACHE_LOCAL_TTL_SECONDS how long the tiered cache keeps local copies.
+	// Revoked tokens, two-factor challenges and role permissions stay in
+	// redis whatever the backend.
+	CACHE_BACKEND           string
+	CACHE_MAX_ENTRIES       int
+	CACHE_LOCAL_TTL_SECONDS int
+	// RATE_LIMIT_STORE is redis (shared by all replicas) or memory.
+	// RATE_LIMIT_ALGORITHM is fixed_window, sliding_window or token_bucket.
+	// RATE_LIMITS overrides limits as group[:role]=max/window[/algorithm],
+	// such as orders=50/1h,carts:cashier=500/15m
+	RATE_LIMIT_STORE     string
+	RATE_LIMIT_ALGORITHM string
+	RATE_LIMITS          []string
+	// SHUTDOWN_DRAIN_SECONDS is how long /health fails before the server
+	// stops accepting connections, SHUTDOWN_TIMEOUT_SECONDS how long
+	// in-flight requests and running jobs then get to finish
+	SHUTDOWN_DRAIN_SECONDS   int
+	SHUTDOWN_TIMEOUT_SECONDS int
+	// REQUEST_TIMEOUT_SECONDS bounds the work done for a request, its
+	// queries are cancelled once it passes
+	REQUEST_TIMEOUT_SECONDS int
 }
 
 func LoadConfig() *Config {
@@ -33,5 +97,58 @@ func LoadConfig() *Config {
 		SERVER_ENV:           viper.GetString("SERVER_ENV"),
 		SERVER_PORT:          viper.GetString("SERVER_PORT"),
 		REDIS_ADDR:           viper.GetString("REDIS_URL"),
+
+		DEPOSIT_EXPIRY_MINUTES:            viper.GetInt("DEPOSIT_EXPIRY_MINUTES"),
+		RESERVATION_CHANGE_CUTOFF_MINUTES: viper.GetInt("RESERVATION_CHANGE_CUTOFF_MINUTES"),
+
+		NOTIFICATION_CHANNEL: viper.GetString("NOTIFICATION_CHANNEL"),
+		NOTIFICATION_FILE:    viper.GetString("NOTIFICATION_FILE"),
+		SMTP_HOST:            viper.GetString("SMTP_HOST"),
+		SMTP_PORT:            viper.GetString("SMTP_PORT"),
+		SMTP_USERNAME:        viper.GetString("SMTP_USERNAME"),
+		SMTP_PASSWORD:        viper.GetString("SMTP_PASSWORD"),
+		SMTP_FROM:            viper.GetString("SMTP_FROM"),
+
+		ACCESS_TOKEN_TTL_MINUTES: viper.GetInt("ACCESS_TOKEN_TTL_MINUTES"),
+		REFRESH_TOKEN_TTL_DAYS:   viper.GetInt("REFRESH_TOKEN_TTL_DAYS"),
+		JWT_ALGORITHM:            viper.GetString("JWT_ALGORITHM"),
+		JWT_PRIVATE_KEY_FILE:     viper.GetString("JWT_PRIVATE_KEY_FILE"),
+		JWT_KEY_ID:               viper.GetString("JWT_KEY_ID"),
+		JWT_PREVIOUS_PUBLIC_KEYS: splitList(viper.GetString("JWT_PREVIOUS_PUBLIC_KEYS")),
+		JWT_ISSUER:               viper.GetString("JWT_ISSUER"),
+		JWT_AUDIENCE:             viper.GetString("JWT_AUDIENCE"),
+
+		APP_BASE_URL:            viper.GetString("APP_BASE_URL"),
+		POS_SESSION_TTL_MINUTES: viper.GetInt("POS_SESSION_TTL_MINUTES"),
+
+		TWO_FACTOR_REQUIRED_ROLES: splitList(viper.GetString("TWO_FACTOR_REQUIRED_ROLES")),
+
+		PAY_PERIOD_START:      viper.GetString("PAY_PERIOD_START"),
+		PAY_PERIOD_DAYS:       viper.GetInt("PAY_PERIOD_DAYS"),
+		OVERTIME_WEEKLY_HOURS: viper.GetFloat64("OVERTIME_WEEKLY_HOURS"),
+		OVERTIME_MULTIPLIER:   viper.GetFloat64("OVERTIME_MULTIPLIER"),
+
+		CACHE_BACKEND:           viper.GetString("CACHE_BACKEND"),
+		CACHE_MAX_ENTRIES:       viper.GetInt("CACHE_MAX_ENTRIES"),
+		CACHE_LOCAL_TTL_SECONDS: viper.GetInt("CACHE_LOCAL_TTL_SECONDS"),
+
+		RATE_LIMIT_STORE:     viper.GetString("RATE_LIMIT_STORE"),
+		RATE_LIMIT_ALGORITHM: viper.GetString("RATE_LIMIT_ALGORITHM"),
+		RATE_LIMITS:          splitList(viper.GetString("RATE_LIMITS")),
+
+		SHUTDOWN_DRAIN_SECONDS:   viper.GetInt("SHUTDOWN_DRAIN_SECONDS"),
+		SHUTDOWN_TIMEOUT_SECONDS: viper.GetInt("SHUTDOWN_TIMEOUT_SECONDS"),
+		REQUEST_TIMEOUT_SECONDS:  viper.GetInt("REQUEST_TIMEOUT_SECONDS"),
+	}
+}
+
+// splitList parses a comma separated setting, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
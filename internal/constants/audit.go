@@ -0,0 +1,43 @@
+package constants
+
+// RequestIDKey is the fiber local holding the id of the current request, it
+// is echoed in the X-Request-ID response header and stored with audit entries
+const RequestIDKey = "request_id"
+
+// Audit actions
+const (
+	AuditActionCreate       = "create"
+	AuditActionUpdate       = "update"
+	AuditActionDelete       = "delete"
+	AuditActionStatusChange = "status_change"
+	AuditActionStockAdjust  = "stock_adjust"
+	AuditActionCancel       = "cancel"
+	AuditActionNoShow       = "no_show"
+	AuditActionInvite       = "invite"
+	AuditActionCorrect      = "correct"
+	AuditActionReset        = "reset"
+	AuditActionRevoke       = "revoke"
+	AuditActionUnlock       = "unlock"
+	AuditActionRefund       = "refund"
+)
+
+// Audited entity types
+const (
+	AuditEntityMenu         = "menu"
+	AuditEntityInventory    = "inventory"
+	AuditEntityTable        = "table"
+	AuditEntityEmployee     = "employee"
+	AuditEntityReservation  = "reservation"
+	AuditEntityOrder        = "order"
+	AuditEntityShift        = "shift"
+	AuditEntityTimeEntry    = "time_entry"
+	AuditEntityRateLimit    = "rate_limit"
+	AuditEntityRole         = "role"
+	AuditEntityPosDevice    = "pos_device"
+	AuditEntityTwoFactor    = "two_factor"
+	AuditEntityCustomer     = "customer"
+	AuditEntityPayment      = "payment"
+	AuditEntityDeposit      = "deposit"
+	AuditEntityDepositRule  = "deposit_rule"
+	AuditEntityFloorSection = "floor_section"
+)
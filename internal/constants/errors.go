@@ -8,6 +8,11 @@ var (
 	ErrInvalidRequestBody         = errors.New("invalid request body")
 	ErrInvalidRequestParam        = errors.New("invalid request param")
 	ErrUnauthorized               = errors.New("unauthorized")
+	ErrInvalidCredentials         = errors.New("invalid email or password")
+	ErrAccountLocked              = errors.New("account is temporarily locked after too many failed logins")
+	ErrInvalidToken               = errors.New("invalid or expired token")
+	ErrEmailNotVerified           = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified       = errors.New("email address is already verified")
 	ErrInvalidEmail               = errors.New("invalid email")
 	ErrInvalidPassword            = errors.New("invalid password")
 	ErrInvalidName                = errors.New("invalid name")
@@ -23,4 +28,33 @@ var (
 	ErrNotFound                   = errors.New("not found")
 	ErrInvalidInterfaceConversion = errors.New("invalid data type for interface conversion")
 	ErrMenuAlreadyInWishlist      = errors.New("menu already in wishlist")
+	ErrInvalidSplit               = errors.New("invalid bill split")
+	ErrBillHasPayments            = errors.New("bill already has successful payments")
+	ErrBillSettled                = errors.New("bill is already settled")
+	ErrAmountExceedsBalance       = errors.New("amount exceeds the outstanding balance")
+	ErrInvalidTableTransition     = errors.New("invalid table status transition")
+	ErrAreaHasSections            = errors.New("area still has sections")
+	ErrInvalidReservationStatus   = errors.New("invalid reservation status transition")
+	ErrDepositNotPaid             = errors.New("reservation deposit is not paid")
+	ErrTableUnavailable           = errors.New("table is not available for the selected date")
+	ErrReservationLocked          = errors.New("reservation can no longer be changed, please contact the restaurant")
+	ErrRoleExists                 = errors.New("role already exists")
+	ErrRoleBuiltIn                = errors.New("built-in roles can't be deleted")
+	ErrRoleInUse                  = errors.New("role is still assigned to employees")
+	ErrPinLocked                  = errors.New("too many failed PIN attempts, try again later")
+	ErrInvalidDevice              = errors.New("unknown or revoked device")
+	ErrInvalidTwoFactorCode       = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled           = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorEnforced          = errors.New("two-factor authentication is required for this role")
+	ErrEmailTaken                 = errors.New("email already registered")
+	ErrInviteAccepted             = errors.New("employee already accepted the invite")
+	ErrShiftOverlap               = errors.New("shift overlaps another shift of the employee")
+	ErrNoStaffOnShift             = errors.New("no waitstaff on shift")
+	ErrDeviceRequired             = errors.New("punches must be made on a registered POS terminal")
+	ErrAlreadyClockedIn           = errors.New("employee is already clocked in")
+	ErrNotClockedIn               = errors.New("employee is not clocked in")
+	ErrAlreadyOnBreak             = errors.New("employee is already on a break")
+	ErrNotOnBreak                 = errors.New("employee is not on a break")
+	ErrTimeEntryOverlap           = errors.New("time entry overlaps another entry of the employee")
 )
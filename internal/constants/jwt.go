@@ -5,4 +5,14 @@ const (
 	ClaimsKeyName  = "name"
 	ClaimsKeyID    = "customer_id"
 	ClaimsKeyRole  = "role"
+
+	// ClaimsKeyTokenID and ClaimsKeyTokenExpiry hold the jti and expiry of
+	// the access token so logout can revoke it
+	ClaimsKeyTokenID     = "token_id"
+	ClaimsKeyTokenExpiry = "token_expires_at"
+
+	// ClaimsKeyDeviceID is set for PIN sessions bound to a POS terminal, those
+	// requests must carry the terminal's token in DeviceTokenHeader
+	ClaimsKeyDeviceID = "device_id"
+	DeviceTokenHeader = "X-Device-Token"
 )
\ No newline at end of file
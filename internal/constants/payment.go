@@ -8,4 +8,23 @@ const (
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusExpired   PaymentStatus = "expired"
 	PaymentStatusCancelled PaymentStatus = "cancelled"
+	PaymentStatusRefunded  PaymentStatus = "refunded"
+)
+
+type PaymentMethod string
+
+const (
+	PaymentMethodMidtrans PaymentMethod = "midtrans"
+	PaymentMethodCash     PaymentMethod = "cash"
+	PaymentMethodCard     PaymentMethod = "card"
+	// PaymentMethodDeposit is a reservation deposit credited to the final bill
+	PaymentMethodDeposit PaymentMethod = "deposit"
+)
+
+// Prefixes of the order_id sent to Midtrans, used by the webhook to route
+// notifications back to the right flow.
+const (
+	PaymentPrefixOrder   = "ORDER"
+	PaymentPrefixSplit   = "SPLIT"
+	PaymentPrefixDeposit = "DEPOSIT"
 )
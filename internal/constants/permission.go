@@ -0,0 +1,114 @@
+package constants
+
+// Permissions guard individual routes, roles are granted a set of them through
+// the policy stored in the roles table
+const (
+	PermEmployeeRead      = "employee:read"
+	PermEmployeeWrite     = "employee:write"
+	PermMenuWrite         = "menu:write"
+	PermOrderReadAll      = "order:read_all"
+	PermOrderFoodStatus   = "order:food_status"
+	PermBillManage        = "bill:manage"
+	PermPaymentCreate     = "payment:create"
+	PermRefundCreate      = "refund:create"
+	PermReservationRead   = "reservation:read_all"
+	PermReservationWrite  = "reservation:write"
+	PermDepositRuleManage = "deposit_rule:manage"
+	PermNotificationRead  = "notification:read"
+	PermInventoryRead     = "inventory:read"
+	PermInventoryWrite    = "inventory:write"
+	PermInventoryAdjust   = "inventory:adjust"
+	PermTableWrite        = "table:write"
+	PermTableStatus       = "table:status"
+	PermFloorRead         = "floor:read"
+	PermFloorManage       = "floor:manage"
+	PermRoleManage        = "role:manage"
+	PermDeviceManage      = "device:manage"
+	PermAccountUnlock     = "account:unlock"
+	PermAuditRead         = "audit:read"
+	PermShiftRead         = "shift:read"
+	PermShiftManage       = "shift:manage"
+	PermTimeClock         = "timeclock:punch"
+	PermTimesheetManage   = "timesheet:manage"
+	PermRateLimitManage   = "rate_limit:manage"
+)
+
+// Permissions lists every permission a role can be granted
+var Permissions = []string{
+	PermEmployeeRead,
+	PermEmployeeWrite,
+	PermMenuWrite,
+	PermOrderReadAll,
+	PermOrderFoodStatus,
+	PermBillManage,
+	PermPaymentCreate,
+	PermRefundCreate,
+	PermReservationRead,
+	PermReservationWrite,
+	PermDepositRuleManage,
+	PermNotificationRead,
+	PermInventoryRead,
+	PermInventoryWrite,
+	PermInventoryAdjust,
+	PermTableWrite,
+	PermTableStatus,
+	PermFloorRead,
+	PermFloorManage,
+	PermRoleManage,
+	PermDeviceManage,
+	PermAccountUnlock,
+	PermAuditRead,
+	PermShiftRead,
+	PermShiftManage,
+	PermTimeClock,
+	PermTimesheetManage,
+	PermRateLimitManage,
+}
+
+// DefaultRolePermissions is the policy seeded for the built-in roles. Admins
+// always hold every permission so the policy can't lock them out.
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin:    Permissions,
+	RoleCustomer: {},
+	RoleKitchen: {
+		PermMenuWrite,
+		PermOrderReadAll,
+		PermOrderFoodStatus,
+		PermInventoryRead,
+		PermInventoryWrite,
+		PermInventoryAdjust,
+		PermShiftRead,
+		PermTimeClock,
+	},
+	RoleCashier: {
+		PermOrderReadAll,
+		PermBillManage,
+		PermPaymentCreate,
+		PermRefundCreate,
+		PermTableWrite,
+		PermTableStatus,
+		PermFloorRead,
+		PermShiftRead,
+		PermTimeClock,
+	},
+	RoleWaitress: {
+		PermOrderReadAll,
+		PermBillManage,
+		PermPaymentCreate,
+		PermReservationWrite,
+		PermTableStatus,
+		PermFloorRead,
+		PermShiftRead,
+		PermTimeClock,
+	},
+}
+
+// IsPermission reports whether permission is a known permission
+func IsPermission(permission string) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package constants
+
+// Rate limit groups, each group has its own limit and counters. Limits are
+// configured per group and optionally per role with RATE_LIMITS.
+const (
+	RateLimitGlobal         = "global"
+	RateLimitStrict         = "strict"
+	RateLimitAuth           = "auth"
+	RateLimitPaymentWebhook = "payment_webhook"
+	RateLimitMenusPublic    = "menus_public"
+	RateLimitAPI            = "api"
+	RateLimitCarts          = "carts"
+	RateLimitOrders         = "orders"
+	RateLimitPayments       = "payments"
+	RateLimitWishlists      = "wishlists"
+	RateLimitReservations   = "reservations"
+	RateLimitInventories    = "inventories"
+	RateLimitTables         = "tables"
+)
+
+// RateLimitTierStaff is the rate limit tier of every role but customers,
+// staff work all day on shared terminals and get much higher limits
+const RateLimitTierStaff = "staff"
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+)
+
+// ttlJitter spreads expirations by up to this fraction of the TTL so keys
+// cached together don't all expire together
+const ttlJitter = 0.1
+
+// loadTimeout bounds a load. Loads run detached from the request that
+// started them, other readers and background refreshes share the result.
+const loadTimeout = 10 * time.Second
+
+var (
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cakestore",
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache reads through GetOrLoad by key namespace and result (hit, stale or miss).",
+	}, []string{"namespace", "result"})
+	cacheLoadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "cakestore",
+		Subsystem: "cache",
+		Name:      "load_duration_seconds",
+		Help:      "Time spent loading missed keys from the database by key namespace.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"namespace"})
+
+	// loads de-duplicates concurrent loads of the same key
+	loads singleflight.Group
+)
+
+// cachedValue is what GetOrLoad stores, FreshUntil marks when the value
+// turns stale
+type cachedValue[T any] struct {
+	Value      T         `json:"value"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+type loadOptions struct {
+	stale time.Duration
+}
+
+// LoadOption tunes GetOrLoad
+type LoadOption func(*loadOptions)
+
+// WithStale keeps serving a value for up to stale after it expired while a
+// background load refreshes it, so readers never wait on the database for
+// popular keys.
+func WithStale(stale time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.stale = stale
+	}
+}
+
+// GetOrLoad returns the value cached under key or calls loader and caches
+// its result for about ttl. Concurrent misses of a key share one loader call,
+// and cache errors are treated as misses so an outage only costs database
+// reads. Loader errors are returned and not cached.
+//
+// loader must use the context it is given rather than the caller's, which
+// keeps its values but not its cancellation and is bounded by loadTimeout.
+// A caller whose own ctx ends stops waiting without failing the others.
+func GetOrLoad[T any](ctx context.Context, cache RedisCache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...LoadOption) (T, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	namespace := keyNamespace(key)
+
+	var cached cachedValue[T]
+	if err := cache.Get(ctx, key, &cached); err == nil {
+		if time.Now().Before(cached.FreshUntil) {
+			cacheRequests.WithLabelValues(namespace, "hit").Inc()
+			return cached.Value, nil
+		}
+		if options.stale > 0 {
+			cacheRequests.WithLabelValues(namespace, "stale").Inc()
+			go load(ctx, cache, key, namespace, ttl, options, loader)
+			return cached.Value, nil
+		}
+	}
+
+	cacheRequests.WithLabelValues(namespace, "miss").Inc()
+	return load(ctx, cache, key, namespace, ttl, options, loader)
+}
+
+func load[T any](ctx context.Context, cache RedisCache, key, namespace string, ttl time.Duration, options loadOptions, loader func(ctx context.Context) (T, error)) (T, error) {
+	results := loads.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		start := time.Now()
+		value, err := loader(loadCtx)
+		cacheLoadDuration.WithLabelValues(namespace).Observe(time.Since(start).Seconds())
+		if err != nil {
+			return value, err
+		}
+
+		fresh := jitter(ttl)
+		entry := cachedValue[T]{Value: value, FreshUntil: time.Now().Add(fresh)}
+		if err := cache.Set(loadCtx, key, entry, fresh+options.stale); err != nil && !errors.Is(err, ErrRedisUnavailable) {
+			log.Printf("Cache: Failed to store key %s: %v", key, err)
+		}
+		return value, nil
+	})
+
+	select {
+	case res := <-results:
+		result, _ := res.Val.(T)
+		return result, res.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// jitter returns ttl stretched or shrunk by up to ttlJitter
+func jitter(ttl time.Duration) time.Duration {
+	spread := float64(ttl) * ttlJitter
+	return ttl + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// keyNamespace is the part of key before the first colon, such as menus or
+// order, used to label metrics
+func keyNamespace(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	return namespace
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrLoad_CachesResult(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "cake", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := GetOrLoad(ctx, cache, "loader:cached", time.Minute, loader)
+		require.NoError(t, err)
+		assert.Equal(t, "cake", value)
+	}
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestGetOrLoad_LoadErrorIsNotCached(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	errLoad := errors.New("database down")
+
+	_, err := GetOrLoad(ctx, cache, "loader:error", time.Minute, func(ctx context.Context) (string, error) {
+		return "", errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+	assert.Zero(t, cache.Len())
+
+	value, err := GetOrLoad(ctx, cache, "loader:error", time.Minute, func(ctx context.Context) (string, error) {
+		return "cake", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cake", value)
+}
+
+func TestGetOrLoad_Singleflight(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "cake", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	values := make([]string, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], errs[i] = GetOrLoad(ctx, cache, "loader:singleflight", time.Minute, loader)
+		}(i)
+	}
+	// give every caller time to join the load before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, calls.Load())
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, "cake", values[i])
+	}
+}
+
+func TestGetOrLoad_CallerCancellation(t *testing.T) {
+	cache := NewMemoryCache(10)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loaderErr error
+	loader := func(ctx context.Context) (string, error) {
+		close(started)
+		<-release
+		loaderErr = ctx.Err()
+		return "cake", nil
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := GetOrLoad(cancelled, cache, "loader:cancel", time.Minute, loader)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan string, 1)
+	go func() {
+		value, _ := GetOrLoad(context.Background(), cache, "loader:cancel", time.Minute, loader)
+		second <- value
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	assert.Equal(t, "cake", <-second)
+	assert.NoError(t, loaderErr)
+
+	// the shared load still filled the cache
+	var cached cachedValue[string]
+	require.NoError(t, cache.Get(context.Background(), "loader:cancel", &cached))
+	assert.Equal(t, "cake", cached.Value)
+}
+
+func TestGetOrLoad_Stale(t *testing.T) {
+	errLoad := errors.New("database down")
+	tests := []struct {
+		name    string
+		opts    []LoadOption
+		want    string
+		wantErr error
+	}{
+		{name: "stale value served on a load error", opts: []LoadOption{WithStale(time.Minute)}, want: "old"},
+		{name: "without WithStale the error is returned", wantErr: errLoad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewMemoryCache(10)
+			key := "loader:stale:" + tt.name
+			expired := cachedValue[string]{Value: "old", FreshUntil: time.Now().Add(-time.Second)}
+			require.NoError(t, cache.Set(ctx, key, expired, time.Minute))
+
+			var calls atomic.Int32
+			loader := func(ctx context.Context) (string, error) {
+				calls.Add(1)
+				return "", errLoad
+			}
+
+			for i := 0; i < 2; i++ {
+				value, err := GetOrLoad(ctx, cache, key, time.Minute, loader, tt.opts...)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, value)
+			}
+
+			// the failed refresh leaves the stale entry in place
+			assert.Eventually(t, func() bool { return calls.Load() >= 1 }, time.Second, 5*time.Millisecond)
+			var cached cachedValue[string]
+			require.NoError(t, cache.Get(ctx, key, &cached))
+			assert.Equal(t, "old", cached.Value)
+		})
+	}
+}
+
+func TestGetOrLoad_StaleRefresh(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	expired := cachedValue[string]{Value: "old", FreshUntil: time.Now().Add(-time.Second)}
+	require.NoError(t, cache.Set(ctx, "loader:refresh", expired, time.Minute))
+
+	value, err := GetOrLoad(ctx, cache, "loader:refresh", time.Minute, func(ctx context.Context) (string, error) {
+		return "new", nil
+	}, WithStale(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+
+	assert.Eventually(t, func() bool {
+		var cached cachedValue[string]
+		return cache.Get(ctx, "loader:refresh", &cached) == nil && cached.Value == "new"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{name: "zero", ttl: 0},
+		{name: "seconds", ttl: 30 * time.Second},
+		{name: "minutes", ttl: 5 * time.Minute},
+		{name: "hours", ttl: 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spread := time.Duration(float64(tt.ttl) * ttlJitter)
+			lowest, highest := tt.ttl, tt.ttl
+			for i := 0; i < 1000; i++ {
+				got := jitter(tt.ttl)
+				require.GreaterOrEqual(t, got, tt.ttl-spread)
+				require.LessOrEqual(t, got, tt.ttl+spread)
+				lowest, highest = min(lowest, got), max(highest, got)
+			}
+			if tt.ttl > 0 {
+				// the TTL is spread both ways
+				assert.Less(t, lowest, tt.ttl)
+				assert.Greater(t, highest, tt.ttl)
+			}
+		})
+	}
+}
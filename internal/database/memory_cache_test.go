@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		// steps are "set:key", "get:key" or "delete:key"
+		steps []string
+		kept  []string
+		gone  []string
+	}{
+		{name: "least recently set key goes first", maxEntries: 2, steps: []string{"set:a", "set:b", "set:c"}, kept: []string{"b", "c"}, gone: []string{"a"}},
+		{name: "reads keep a key", maxEntries: 2, steps: []string{"set:a", "set:b", "get:a", "set:c"}, kept: []string{"a", "c"}, gone: []string{"b"}},
+		{name: "overwrites keep a key", maxEntries: 2, steps: []string{"set:a", "set:b", "set:a", "set:c"}, kept: []string{"a", "c"}, gone: []string{"b"}},
+		{name: "deletes free a slot", maxEntries: 2, steps: []string{"set:a", "set:b", "delete:a", "set:c"}, kept: []string{"b", "c"}, gone: []string{"a"}},
+		{name: "zero max entries is unbounded", maxEntries: 0, steps: []string{"set:a", "set:b", "set:c"}, kept: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewMemoryCache(tt.maxEntries)
+
+			for _, step := range tt.steps {
+				op, key, _ := strings.Cut(step, ":")
+				switch op {
+				case "set":
+					require.NoError(t, cache.Set(ctx, key, key, 0))
+				case "get":
+					var value string
+					require.NoError(t, cache.Get(ctx, key, &value))
+				case "delete":
+					require.NoError(t, cache.Delete(ctx, key))
+				}
+			}
+
+			assert.Equal(t, len(tt.kept), cache.Len())
+			for _, key := range tt.kept {
+				var value string
+				assert.NoError(t, cache.Get(ctx, key, &value), key)
+				assert.Equal(t, key, value)
+			}
+			for _, key := range tt.gone {
+				var value string
+				assert.ErrorIs(t, cache.Get(ctx, key, &value), ErrCacheMiss, key)
+			}
+		})
+	}
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		// refresh sets the key again with expiration before it would expire
+		refresh bool
+		wait    time.Duration
+		hit     bool
+	}{
+		{name: "fresh key", expiration: time.Minute, wait: 0, hit: true},
+		{name: "expired key", expiration: 10 * time.Millisecond, wait: 30 * time.Millisecond},
+		{name: "zero expiration keeps the key", expiration: 0, wait: 30 * time.Millisecond, hit: true},
+		{name: "overwrite restarts the expiration", expiration: 100 * time.Millisecond, refresh: true, wait: 60 * time.Millisecond, hit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewMemoryCache(10)
+
+			require.NoError(t, cache.Set(ctx, "key", "value", tt.expiration))
+			if tt.refresh {
+				time.Sleep(tt.wait)
+				require.NoError(t, cache.Set(ctx, "key", "value", tt.expiration))
+			}
+			time.Sleep(tt.wait)
+
+			var value string
+			err := cache.Get(ctx, "key", &value)
+			if !tt.hit {
+				assert.ErrorIs(t, err, ErrCacheMiss)
+				// the expired key is dropped when it is read
+				assert.Zero(t, cache.Len())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		})
+	}
+}
+
+func TestMemoryCache_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	for _, key := range []string{"menus:page:1", "menus:page:2", "menu:1", "orders:page:1"} {
+		require.NoError(t, cache.Set(ctx, key, key, 0))
+	}
+
+	require.NoError(t, cache.DeletePrefix(ctx, "menus:"))
+
+	var value string
+	assert.ErrorIs(t, cache.Get(ctx, "menus:page:1", &value), ErrCacheMiss)
+	assert.ErrorIs(t, cache.Get(ctx, "menus:page:2", &value), ErrCacheMiss)
+	assert.NoError(t, cache.Get(ctx, "menu:1", &value))
+	assert.NoError(t, cache.Get(ctx, "orders:page:1", &value))
+}
+
+func TestMemoryCache_ValuesAreCopies(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	items := []string{"a", "b"}
+	require.NoError(t, cache.Set(ctx, "items", items, 0))
+
+	items[0] = "changed"
+	var cached []string
+	require.NoError(t, cache.Get(ctx, "items", &cached))
+	cached[1] = "changed"
+
+	var again []string
+	require.NoError(t, cache.Get(ctx, "items", &again))
+	assert.Equal(t, []string{"a", "b"}, again)
+}
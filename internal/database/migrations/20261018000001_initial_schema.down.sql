@@ -0,0 +1,12 @@
+-- Drops the whole schema, every row is lost
+
+DROP TABLE IF EXISTS "inventories";
+DROP TABLE IF EXISTS "reservations";
+DROP TABLE IF EXISTS "tables";
+DROP TABLE IF EXISTS "wishlists";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "customers";
+DROP TABLE IF EXISTS "menus";
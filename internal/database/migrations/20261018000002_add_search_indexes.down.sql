@@ -0,0 +1,5 @@
+-- The pg_trgm extension is kept, other objects may depend on it
+
+DROP INDEX IF EXISTS "idx_inventories_name_trgm";
+DROP INDEX IF EXISTS "idx_menus_category_trgm";
+DROP INDEX IF EXISTS "idx_menus_title_trgm";
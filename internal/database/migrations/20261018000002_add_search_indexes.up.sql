@@ -0,0 +1,8 @@
+-- Trigram indexes behind the substring search on menus and inventory, the
+-- reindex-search command rebuilds them
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS "idx_menus_title_trgm" ON "menus" USING gin (LOWER("title") gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_menus_category_trgm" ON "menus" USING gin (LOWER("category") gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_inventories_name_trgm" ON "inventories" USING gin ("name" gin_trgm_ops);
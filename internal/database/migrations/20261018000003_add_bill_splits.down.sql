@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "bill_shares";
+DROP TABLE IF EXISTS "bill_splits";
+
+DROP INDEX IF EXISTS "idx_payments_external_id";
+DROP INDEX IF EXISTS "idx_payments_share_id";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "external_id";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "method";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "share_id";
@@ -0,0 +1,37 @@
+-- Split bills, a payment belongs to one share of the bill and records how
+-- it was tendered
+
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "share_id" bigint;
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "method" text DEFAULT 'midtrans';
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "external_id" text;
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "reference" text;
+CREATE INDEX IF NOT EXISTS "idx_payments_share_id" ON "payments" ("share_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_external_id" ON "payments" ("external_id");
+
+CREATE TABLE IF NOT EXISTS "bill_splits" (
+    "id" bigserial,
+    "order_id" bigint,
+    "mode" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_bill_splits_order_id" ON "bill_splits" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "bill_shares" (
+    "id" bigserial,
+    "bill_split_id" bigint,
+    "order_id" bigint,
+    "label" text,
+    "amount" decimal,
+    "item_ids" text,
+    "status" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_bill_splits_shares" FOREIGN KEY ("bill_split_id") REFERENCES "bill_splits"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_bill_shares_order_id" ON "bill_shares" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_bill_shares_bill_split_id" ON "bill_shares" ("bill_split_id");
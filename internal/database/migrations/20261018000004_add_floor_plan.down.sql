@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS "table_sessions";
+
+DROP INDEX IF EXISTS "idx_tables_section_id";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "rotation";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "height";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "width";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "pos_y";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "pos_x";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "shape";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "status";
+ALTER TABLE "tables" DROP COLUMN IF EXISTS "section_id";
+
+DROP TABLE IF EXISTS "section_staff";
+DROP TABLE IF EXISTS "floor_sections";
+DROP TABLE IF EXISTS "floor_areas";
@@ -0,0 +1,61 @@
+-- Floor plan areas and sections, table layout and live status, and the
+-- seating sessions behind it
+
+CREATE TABLE IF NOT EXISTS "floor_areas" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "type" text NOT NULL DEFAULT 'indoor',
+    "sort_order" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_floor_areas_name" UNIQUE ("name")
+);
+
+CREATE TABLE IF NOT EXISTS "floor_sections" (
+    "id" bigserial,
+    "area_id" bigint NOT NULL,
+    "name" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_floor_areas_sections" FOREIGN KEY ("area_id") REFERENCES "floor_areas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_floor_sections_area_id" ON "floor_sections" ("area_id");
+
+CREATE TABLE IF NOT EXISTS "section_staff" (
+    "id" bigserial,
+    "section_id" bigint NOT NULL,
+    "employee_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_section_staff_employee" FOREIGN KEY ("employee_id") REFERENCES "customers"("id"),
+    CONSTRAINT "fk_floor_sections_staff" FOREIGN KEY ("section_id") REFERENCES "floor_sections"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_section_staff" ON "section_staff" ("section_id","employee_id");
+
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "section_id" bigint;
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'free';
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "shape" text NOT NULL DEFAULT 'square';
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "pos_x" decimal NOT NULL DEFAULT 0;
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "pos_y" decimal NOT NULL DEFAULT 0;
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "width" decimal NOT NULL DEFAULT 1;
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "height" decimal NOT NULL DEFAULT 1;
+ALTER TABLE "tables" ADD COLUMN IF NOT EXISTS "rotation" bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_tables_section_id" ON "tables" ("section_id");
+
+CREATE TABLE IF NOT EXISTS "table_sessions" (
+    "id" bigserial,
+    "table_id" bigint NOT NULL,
+    "reservation_id" bigint,
+    "guest_count" bigint NOT NULL,
+    "seated_at" timestamptz NOT NULL,
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_table_sessions_closed_at" ON "table_sessions" ("closed_at");
+CREATE INDEX IF NOT EXISTS "idx_table_sessions_table_id" ON "table_sessions" ("table_id");
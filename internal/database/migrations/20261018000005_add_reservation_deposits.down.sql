@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "reservation_deposits";
+DROP TABLE IF EXISTS "deposit_rules";
+
+ALTER TABLE "customers" DROP COLUMN IF EXISTS "no_show_count";
@@ -0,0 +1,41 @@
+-- Deposit rules, the deposits taken for reservations and the no-show count
+-- of each customer
+
+ALTER TABLE "customers" ADD COLUMN IF NOT EXISTS "no_show_count" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "deposit_rules" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "min_guests" bigint NOT NULL DEFAULT 1,
+    "weekday" bigint,
+    "start_time" text,
+    "end_time" text,
+    "flat_amount" decimal NOT NULL DEFAULT 0,
+    "amount_per_guest" decimal NOT NULL DEFAULT 0,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "reservation_deposits" (
+    "id" bigserial,
+    "reservation_id" bigint NOT NULL,
+    "rule_id" bigint,
+    "amount" decimal NOT NULL,
+    "status" text NOT NULL DEFAULT 'pending',
+    "external_id" text,
+    "payment_token" text,
+    "payment_url" text,
+    "expires_at" timestamptz,
+    "paid_at" timestamptz,
+    "applied_order_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reservation_deposits_expires_at" ON "reservation_deposits" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_reservation_deposits_external_id" ON "reservation_deposits" ("external_id");
+CREATE INDEX IF NOT EXISTS "idx_reservation_deposits_status" ON "reservation_deposits" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reservation_deposits_reservation_id" ON "reservation_deposits" ("reservation_id");
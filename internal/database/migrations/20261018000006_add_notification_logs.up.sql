@@ -0,0 +1,25 @@
+-- Outbox of customer notifications, retried until sent
+
+CREATE TABLE IF NOT EXISTS "notification_logs" (
+    "id" bigserial,
+    "customer_id" bigint,
+    "event" text NOT NULL,
+    "channel" text NOT NULL,
+    "recipient" text NOT NULL,
+    "subject" text,
+    "body" text,
+    "dedupe_key" text,
+    "status" text NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "last_error" text,
+    "next_attempt_at" timestamptz,
+    "claimed_at" timestamptz,
+    "sent_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notification_logs_next_attempt_at" ON "notification_logs" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_notification_logs_status" ON "notification_logs" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_logs_dedupe_key" ON "notification_logs" ("dedupe_key");
+CREATE INDEX IF NOT EXISTS "idx_notification_logs_customer_id" ON "notification_logs" ("customer_id");
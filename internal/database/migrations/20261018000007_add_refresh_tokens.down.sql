@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "refresh_tokens";
+
+ALTER TABLE "customers" DROP COLUMN IF EXISTS "token_version";
@@ -0,0 +1,20 @@
+-- Refresh tokens, and the token version bumped to revoke every access token
+-- of a customer
+
+ALTER TABLE "customers" ADD COLUMN IF NOT EXISTS "token_version" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" bigserial,
+    "customer_id" bigint NOT NULL,
+    "token_hash" text NOT NULL,
+    "user_agent" text,
+    "ip_address" text,
+    "expires_at" timestamptz NOT NULL,
+    "revoked_at" timestamptz,
+    "replaced_by_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_customer_id" ON "refresh_tokens" ("customer_id");
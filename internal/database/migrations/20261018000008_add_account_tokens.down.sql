@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "account_tokens";
+
+ALTER TABLE "customers" DROP COLUMN IF EXISTS "email_verified_at";
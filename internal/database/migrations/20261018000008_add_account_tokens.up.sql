@@ -0,0 +1,21 @@
+-- Password reset and email verification tokens
+
+ALTER TABLE "customers" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+
+-- Existing accounts never got a link to verify with, they count as verified
+-- so they can keep ordering
+UPDATE "customers" SET "email_verified_at" = COALESCE("created_at", NOW())
+WHERE "email_verified_at" IS NULL;
+
+CREATE TABLE IF NOT EXISTS "account_tokens" (
+    "id" bigserial,
+    "customer_id" bigint NOT NULL,
+    "purpose" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_account_tokens_token_hash" ON "account_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_account_tokens_customer_id" ON "account_tokens" ("customer_id");
@@ -0,0 +1,21 @@
+-- Roles and the permissions granted to them
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text,
+    "built_in" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "id" bigserial,
+    "role_id" bigint NOT NULL,
+    "permission" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_roles_permissions" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_role_permission" ON "role_permissions" ("role_id","permission");
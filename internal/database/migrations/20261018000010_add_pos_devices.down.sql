@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "staff_pins";
+DROP TABLE IF EXISTS "pos_devices";
@@ -0,0 +1,25 @@
+-- Registered POS devices and the PINs staff sign in with on them
+
+CREATE TABLE IF NOT EXISTS "pos_devices" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "registered_by" bigint,
+    "last_seen_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pos_devices_token_hash" ON "pos_devices" ("token_hash");
+
+CREATE TABLE IF NOT EXISTS "staff_pins" (
+    "customer_id" bigint,
+    "pin_hash" text NOT NULL,
+    "failed_attempts" bigint NOT NULL DEFAULT 0,
+    "locked_until" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("customer_id"),
+    CONSTRAINT "fk_staff_pins_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id") ON DELETE CASCADE
+);
@@ -0,0 +1,25 @@
+-- Login history and per-account lockouts
+
+CREATE TABLE IF NOT EXISTS "login_events" (
+    "id" bigserial,
+    "customer_id" bigint,
+    "email" text NOT NULL,
+    "success" boolean NOT NULL,
+    "suspicious" boolean NOT NULL DEFAULT false,
+    "reason" text,
+    "ip_address" text,
+    "user_agent" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_events_created_at" ON "login_events" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_login_events_customer_id" ON "login_events" ("customer_id");
+
+CREATE TABLE IF NOT EXISTS "account_lockouts" (
+    "customer_id" bigint,
+    "failed_attempts" bigint NOT NULL DEFAULT 0,
+    "last_failed_at" timestamptz,
+    "locked_until" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("customer_id")
+);
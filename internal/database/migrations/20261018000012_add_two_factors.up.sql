@@ -0,0 +1,22 @@
+-- TOTP two-factor secrets and recovery codes
+
+CREATE TABLE IF NOT EXISTS "two_factors" (
+    "customer_id" bigint,
+    "secret" text NOT NULL,
+    "enabled_at" timestamptz,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("customer_id"),
+    CONSTRAINT "fk_two_factors_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" bigserial,
+    "customer_id" bigint NOT NULL,
+    "code_hash" text NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_customer_id" ON "recovery_codes" ("customer_id");
@@ -0,0 +1,37 @@
+-- Employee profiles and weekly shifts
+
+CREATE TABLE IF NOT EXISTS "employee_profiles" (
+    "customer_id" bigint,
+    "phone" text,
+    "hire_date" date,
+    "hourly_rate" decimal NOT NULL DEFAULT 0,
+    "invited_at" timestamptz,
+    "onboarded_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("customer_id"),
+    CONSTRAINT "fk_employee_profiles_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "shifts" (
+    "id" bigserial,
+    "employee_id" bigint NOT NULL,
+    "role" text NOT NULL,
+    "weekday" bigint NOT NULL,
+    "start_minute" bigint NOT NULL,
+    "end_minute" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_shifts_employee" FOREIGN KEY ("employee_id") REFERENCES "customers"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_shifts_employee_id" ON "shifts" ("employee_id");
+CREATE INDEX IF NOT EXISTS "idx_shifts_role" ON "shifts" ("role");
+
+-- The role seeder leaves existing roles alone, built-in roles seeded before
+-- this migration get the new permission here. Fresh databases have no roles
+-- yet and the seeder grants it.
+INSERT INTO "role_permissions" ("role_id", "permission")
+SELECT "id", 'shift:read' FROM "roles"
+WHERE "built_in" AND "name" IN ('kitchen_staff', 'cashier', 'waitress')
+ON CONFLICT ("role_id", "permission") DO NOTHING;
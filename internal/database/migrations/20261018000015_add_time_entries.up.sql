@@ -0,0 +1,38 @@
+-- Time clock entries and their breaks
+
+CREATE TABLE IF NOT EXISTS "time_entries" (
+    "id" bigserial,
+    "employee_id" bigint NOT NULL,
+    "clock_in_at" timestamptz NOT NULL,
+    "clock_out_at" timestamptz,
+    "clock_in_device_id" bigint,
+    "clock_out_device_id" bigint,
+    "corrected_by" bigint,
+    "corrected_at" timestamptz,
+    "correction_reason" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_time_entries_employee" FOREIGN KEY ("employee_id") REFERENCES "customers"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_time_entries_open" ON "time_entries" ("employee_id") WHERE clock_out_at IS NULL;
+CREATE INDEX IF NOT EXISTS "idx_time_entries_employee_clock_in" ON "time_entries" ("employee_id","clock_in_at");
+
+CREATE TABLE IF NOT EXISTS "time_breaks" (
+    "id" bigserial,
+    "time_entry_id" bigint NOT NULL,
+    "start_at" timestamptz NOT NULL,
+    "end_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_time_entries_breaks" FOREIGN KEY ("time_entry_id") REFERENCES "time_entries"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_time_breaks_time_entry_id" ON "time_breaks" ("time_entry_id");
+
+-- The role seeder leaves existing roles alone, built-in roles seeded before
+-- this migration get the new permission here. Fresh databases have no roles
+-- yet and the seeder grants it.
+INSERT INTO "role_permissions" ("role_id", "permission")
+SELECT "id", 'timeclock:punch' FROM "roles"
+WHERE "built_in" AND "name" IN ('kitchen_staff', 'cashier', 'waitress')
+ON CONFLICT ("role_id", "permission") DO NOTHING;
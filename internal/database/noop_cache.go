@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NoopCache caches nothing, every Get is a miss. It turns caching off without
+// touching the usecases.
+type NoopCache struct{}
+
+func NewNoopCache() NoopCache {
+	return NoopCache{}
+}
+
+func (NoopCache) Get(ctx context.Context, key string, dest interface{}) error {
+	return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+}
+
+func (NoopCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return nil
+}
+
+func (NoopCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func (NoopCache) DeletePrefix(ctx context.Context, prefix string) error {
+	return nil
+}
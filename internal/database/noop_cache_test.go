@@ -0,0 +1,22 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoopCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewNoopCache()
+
+	assert.NoError(t, cache.Set(ctx, "menu:1", "cake", time.Hour))
+
+	var value string
+	assert.ErrorIs(t, cache.Get(ctx, "menu:1", &value), ErrCacheMiss)
+	assert.Empty(t, value)
+	assert.NoError(t, cache.Delete(ctx, "menu:1"))
+	assert.NoError(t, cache.DeletePrefix(ctx, "menu:"))
+}
@@ -3,8 +3,11 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -15,22 +18,122 @@ type RedisCache interface {
 	Get(ctx context.Context, key string, dest interface{}) error
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every key starting with prefix, used to drop all
+	// cached pages of a list at once
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// ErrRedisUnavailable is returned while Redis can't be reached, the cache
+// treats it like a miss and the rate limiter lets requests through
+var ErrRedisUnavailable = errors.New("redis unavailable")
+
+// ErrCacheMiss is returned by Get when the key isn't cached, any other error
+// means the cache could not be read
+var ErrCacheMiss = errors.New("key not found in cache")
+
+// scanBatchSize is how many keys DeletePrefix asks SCAN for and removes per
+// round trip
+const scanBatchSize = 500
+
+const (
+	// redisDialTimeout bounds how long a request waits on an unreachable
+	// Redis before it falls back to the database
+	redisDialTimeout = time.Second
+	// redisRetryAfter is how long Redis is skipped after a connection error
+	redisRetryAfter = 5 * time.Second
+)
+
+// ConnectRedis creates a client for the Redis server at redisURI. An
+// unreachable server doesn't stop the application, commands fail fast with
+// ErrRedisUnavailable until it comes back.
+func ConnectRedis(ctx context.Context, redisURI string) *redis.Client {
+	opt, err := redis.ParseURL(redisURI)
+	if err != nil {
+		log.Fatalf("Invalid Redis URL: %v", err)
+	}
+	if opt.DialTimeout == 0 {
+		opt.DialTimeout = redisDialTimeout
+	}
+	client := redis.NewClient(opt)
+	client.AddHook(&redisBreaker{})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("Could not connect to Redis, continuing without it: %v", err)
+		return client
+	}
+	log.Println("Connected to Redis successfully!")
+	return client
+}
+
+// redisBreaker skips Redis for redisRetryAfter after a connection error, so
+// an outage costs one timeout per window instead of one per command
+type redisBreaker struct {
+	// downUntil is the unix time in nanoseconds until which Redis is
+	// considered unreachable
+	downUntil atomic.Int64
+}
+
+func (b *redisBreaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *redisBreaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !b.available() {
+			cmd.SetErr(ErrRedisUnavailable)
+			return ErrRedisUnavailable
+		}
+		return b.check(next(ctx, cmd))
+	}
+}
+
+func (b *redisBreaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !b.available() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrRedisUnavailable)
+			}
+			return ErrRedisUnavailable
+		}
+		return b.check(next(ctx, cmds))
+	}
+}
+
+func (b *redisBreaker) available() bool {
+	return time.Now().UnixNano() >= b.downUntil.Load()
+}
+
+// check marks Redis as unreachable when err is a connection error. Replies
+// from the server, such as a wrong type, leave it available.
+func (b *redisBreaker) check(err error) error {
+	if err == nil || err == redis.Nil {
+		return err
+	}
+	var replyErr redis.Error
+	if errors.As(err, &replyErr) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	now := time.Now().UnixNano()
+	if previous := b.downUntil.Load(); previous <= now && b.downUntil.CompareAndSwap(previous, now+int64(redisRetryAfter)) {
+		log.Printf("Redis unreachable, skipping it for %s: %v", redisRetryAfter, err)
+	}
+	return err
 }
 
 type RedisCacheService struct {
 	client *redis.Client
 }
 
+// NewRedisCacheService connects to the Redis server at redisURI, requests
+// are served without cache while it is unreachable.
 func NewRedisCacheService(ctx context.Context, redisURI string) *RedisCacheService {
-	opt, _ := redis.ParseURL(redisURI)
-	rdb := redis.NewClient(opt)
+	return NewRedisCacheServiceWithClient(ConnectRedis(ctx, redisURI))
+}
 
-	_, err := rdb.Ping(ctx).Result()
-	if err != nil {
-		log.Fatalf("Could not connect to Redis: %v", err)
-	}
-	log.Println("Connected to Redis successfully!")
-	return &RedisCacheService{client: rdb}
+// NewRedisCacheServiceWithClient caches in an existing client, so the cache
+// and the rate limiter can share one connection pool
+func NewRedisCacheServiceWithClient(client *redis.Client) *RedisCacheService {
+	return &RedisCacheService{client: client}
 }
 
 // Get retrieves data from Redis and unmarshals it into dest.
@@ -38,7 +141,10 @@ func (s *RedisCacheService) Get(ctx context.Context, key string, dest interface{
 	val, err := s.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return fmt.Errorf("key not found in cache: %s", key)
+			return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+		}
+		if errors.Is(err, ErrRedisUnavailable) {
+			return err
 		}
 		return fmt.Errorf("failed to get from Redis: %w", err)
 	}
@@ -54,8 +160,51 @@ func (s *RedisCacheService) Set(ctx context.Context, key string, value interface
 	return s.client.Set(ctx, key, data, expiration).Err()
 }
 
-// Delete removes a key from Redis.
+// Delete removes a key from Redis. Invalidations follow writes that already
+// happened, so they go through even when the request is cancelled.
 func (s *RedisCacheService) Delete(ctx context.Context, key string) error {
+	ctx = context.WithoutCancel(ctx)
 	log.Printf("Cache: Invalidating key: %s", key)
 	return s.client.Del(ctx, key).Err()
 }
+
+// DeletePrefix removes every key starting with prefix. Keys are found with
+// SCAN rather than KEYS so large keyspaces don't block Redis, and removed
+// with UNLINK in batches. Like Delete it isn't cancelled with the request.
+func (s *RedisCacheService) DeletePrefix(ctx context.Context, prefix string) error {
+	ctx = context.WithoutCancel(ctx)
+	log.Printf("Cache: Invalidating keys with prefix: %s", prefix)
+	iter := s.client.Scan(ctx, 0, escapePattern(prefix)+"*", scanBatchSize).Iterator()
+
+	batch := make([]string, 0, scanBatchSize)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanBatchSize {
+			if err := s.client.Unlink(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan Redis keys: %w", err)
+	}
+	if len(batch) > 0 {
+		return s.client.Unlink(ctx, batch...).Err()
+	}
+	return nil
+}
+
+// escapePattern escapes the glob characters of a key so SCAN MATCH treats
+// it literally
+func escapePattern(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
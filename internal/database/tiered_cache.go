@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// TieredCache serves reads from an in-process L1 cache in front of a shared
+// L2 cache such as Redis. Writes and invalidations go to both, but other
+// instances only notice them once their own L1 copy expires, so l1TTL bounds
+// how stale a read can be.
+type TieredCache struct {
+	l1    *MemoryCache
+	l2    RedisCache
+	l1TTL time.Duration
+}
+
+func NewTieredCache(l1 *MemoryCache, l2 RedisCache, l1TTL time.Duration) *TieredCache {
+	return &TieredCache{l1: l1, l2: l2, l1TTL: l1TTL}
+}
+
+// Get tries L1 first and keeps L2 hits in L1 for later reads.
+func (c *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if err := c.l1.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+	if err := c.l2.Get(ctx, key, dest); err != nil {
+		return err
+	}
+	return c.l1.Set(ctx, key, dest, c.l1TTL)
+}
+
+// Set writes to both tiers, L1 keeps the value for at most l1TTL. The value
+// stays in L1 when L2 is down, so an outage still serves local hits.
+func (c *TieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := c.l1.Set(ctx, key, value, c.localTTL(expiration)); err != nil {
+		return err
+	}
+	return c.l2.Set(ctx, key, value, expiration)
+}
+
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := c.l1.Delete(ctx, key); err != nil {
+		return err
+	}
+	return c.l2.Delete(ctx, key)
+}
+
+func (c *TieredCache) DeletePrefix(ctx context.Context, prefix string) error {
+	if err := c.l1.DeletePrefix(ctx, prefix); err != nil {
+		return err
+	}
+	return c.l2.DeletePrefix(ctx, prefix)
+}
+
+func (c *TieredCache) localTTL(expiration time.Duration) time.Duration {
+	if expiration > 0 && expiration < c.l1TTL {
+		return expiration
+	}
+	return c.l1TTL
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableRedis is a Redis cache whose server refuses connections
+func unreachableRedis(t *testing.T) *RedisCacheService {
+	t.Helper()
+	client := ConnectRedis(context.Background(), "redis://127.0.0.1:1/0")
+	t.Cleanup(func() { client.Close() })
+	return NewRedisCacheServiceWithClient(client)
+}
+
+func TestTieredCache_Get(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewMemoryCache(10)
+	l2 := NewMemoryCache(10)
+	cache := NewTieredCache(l1, l2, time.Minute)
+
+	require.NoError(t, l2.Set(ctx, "menu:1", "cake", time.Hour))
+
+	t.Run("L2 hit fills L1", func(t *testing.T) {
+		var value string
+		require.NoError(t, cache.Get(ctx, "menu:1", &value))
+		assert.Equal(t, "cake", value)
+
+		var local string
+		require.NoError(t, l1.Get(ctx, "menu:1", &local))
+		assert.Equal(t, "cake", local)
+	})
+
+	t.Run("L1 serves without L2", func(t *testing.T) {
+		require.NoError(t, l2.Delete(ctx, "menu:1"))
+
+		var value string
+		require.NoError(t, cache.Get(ctx, "menu:1", &value))
+		assert.Equal(t, "cake", value)
+	})
+
+	t.Run("miss in both", func(t *testing.T) {
+		var value string
+		assert.ErrorIs(t, cache.Get(ctx, "menu:2", &value), ErrCacheMiss)
+		assert.Equal(t, 1, l1.Len())
+	})
+}
+
+func TestTieredCache_Set(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		wait       time.Duration
+		l1Hit      bool
+	}{
+		{name: "L1 keeps the value for at most l1TTL", expiration: time.Hour, wait: 40 * time.Millisecond},
+		{name: "shorter expirations apply to L1", expiration: 20 * time.Millisecond, wait: 0, l1Hit: true},
+		{name: "zero expiration uses l1TTL", expiration: 0, wait: 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l1 := NewMemoryCache(10)
+			l2 := NewMemoryCache(10)
+			cache := NewTieredCache(l1, l2, 20*time.Millisecond)
+
+			require.NoError(t, cache.Set(ctx, "menu:1", "cake", tt.expiration))
+			time.Sleep(tt.wait)
+
+			var value string
+			if tt.l1Hit {
+				assert.NoError(t, l1.Get(ctx, "menu:1", &value))
+			} else {
+				assert.ErrorIs(t, l1.Get(ctx, "menu:1", &value), ErrCacheMiss)
+			}
+			assert.NoError(t, l2.Get(ctx, "menu:1", &value))
+		})
+	}
+}
+
+func TestTieredCache_Invalidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(ctx context.Context, cache *TieredCache) error
+		gone       []string
+		kept       []string
+	}{
+		{
+			name:       "Delete",
+			invalidate: func(ctx context.Context, cache *TieredCache) error { return cache.Delete(ctx, "menu:1") },
+			gone:       []string{"menu:1"},
+			kept:       []string{"menus:page:1", "menus:page:2"},
+		},
+		{
+			name:       "DeletePrefix",
+			invalidate: func(ctx context.Context, cache *TieredCache) error { return cache.DeletePrefix(ctx, "menus:") },
+			gone:       []string{"menus:page:1", "menus:page:2"},
+			kept:       []string{"menu:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l1 := NewMemoryCache(10)
+			l2 := NewMemoryCache(10)
+			cache := NewTieredCache(l1, l2, time.Minute)
+			for _, key := range []string{"menu:1", "menus:page:1", "menus:page:2"} {
+				require.NoError(t, cache.Set(ctx, key, key, time.Hour))
+			}
+
+			require.NoError(t, tt.invalidate(ctx, cache))
+
+			var value string
+			for _, key := range tt.gone {
+				assert.ErrorIs(t, l1.Get(ctx, key, &value), ErrCacheMiss, key)
+				assert.ErrorIs(t, l2.Get(ctx, key, &value), ErrCacheMiss, key)
+			}
+			for _, key := range tt.kept {
+				assert.NoError(t, l1.Get(ctx, key, &value), key)
+				assert.NoError(t, l2.Get(ctx, key, &value), key)
+			}
+		})
+	}
+}
+
+func TestTieredCache_RedisDown(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewMemoryCache(10)
+	cache := NewTieredCache(l1, unreachableRedis(t), time.Minute)
+
+	// the first command trips the breaker, the value stays in L1
+	assert.Error(t, cache.Set(ctx, "menu:1", "cake", time.Hour))
+	assert.ErrorIs(t, cache.Set(ctx, "menu:2", "pie", time.Hour), ErrRedisUnavailable)
+
+	var value string
+	require.NoError(t, cache.Get(ctx, "menu:1", &value))
+	assert.Equal(t, "cake", value)
+
+	// misses report the outage rather than a miss
+	assert.ErrorIs(t, cache.Get(ctx, "menu:3", &value), ErrRedisUnavailable)
+
+	// invalidations still clear L1
+	assert.ErrorIs(t, cache.Delete(ctx, "menu:1"), ErrRedisUnavailable)
+	assert.ErrorIs(t, l1.Get(ctx, "menu:1", &value), ErrCacheMiss)
+	assert.ErrorIs(t, cache.DeletePrefix(ctx, "menu:"), ErrRedisUnavailable)
+	assert.ErrorIs(t, l1.Get(ctx, "menu:2", &value), ErrCacheMiss)
+}
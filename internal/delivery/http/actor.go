@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// actorFromCtx reads the caller set by the auth middleware, unauthenticated
+// callers such as payment webhooks get an empty actor with the request details
+func actorFromCtx(ctx *fiber.Ctx) model.Actor {
+	actor := model.Actor{IPAddress: ctx.IP()}
+	if id, ok := ctx.Locals(constants.ClaimsKeyID).(int64); ok {
+		actor.CustomerID = id
+	}
+	if role, ok := ctx.Locals(constants.ClaimsKeyRole).(string); ok {
+		actor.Role = role
+	}
+	if requestID, ok := ctx.Locals(constants.RequestIDKey).(string); ok {
+		actor.RequestID = requestID
+	}
+	if deviceID, ok := ctx.Locals(constants.ClaimsKeyDeviceID).(int64); ok {
+		actor.DeviceID = deviceID
+	}
+	return actor
+}
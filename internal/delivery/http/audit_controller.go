@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditController struct {
+	useCase usecase.AuditUseCase
+	logger  *logrus.Logger
+}
+
+func NewAuditController(useCase usecase.AuditUseCase, logger *logrus.Logger) *AuditController {
+	return &AuditController{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (c *AuditController) GetAuditLogs(ctx *fiber.Ctx) error {
+	params, err := c.queryParams(ctx)
+	if err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	perPage, _ := strconv.Atoi(ctx.Query("per_page", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+	params.Page = int64(page)
+	params.Limit = int64(perPage)
+
+	logs, err := c.useCase.GetAll(ctx.UserContext(), params)
+	if err != nil {
+		c.logger.Errorf("Error getting audit logs: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get audit logs")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, logs.Data, "Audit logs retrieved successfully", model.ToPaginatedMeta(logs))
+}
+
+// ExportAuditLogs streams every entry matching the filters as a CSV download
+func (c *AuditController) ExportAuditLogs(ctx *fiber.Ctx) error {
+	params, err := c.queryParams(ctx)
+	if err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.csv", time.Now().Format("20060102-150405"))
+	ctx.Set(fiber.HeaderContentType, "text/csv")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := c.useCase.Export(ctx.UserContext(), params, ctx.Response().BodyWriter()); err != nil {
+		c.logger.Errorf("Error exporting audit logs: %v", err)
+		ctx.Response().ResetBody()
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to export audit logs")
+	}
+
+	return nil
+}
+
+func (c *AuditController) queryParams(ctx *fiber.Ctx) (*model.AuditLogQueryParams, error) {
+	params := &model.AuditLogQueryParams{
+		ActorRole:  ctx.Query("actor_role"),
+		Action:     ctx.Query("action"),
+		EntityType: ctx.Query("entity_type"),
+		EntityID:   ctx.Query("entity_id"),
+		RequestID:  ctx.Query("request_id"),
+	}
+
+	if actorID := ctx.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseInt(actorID, 10, 64)
+		if err != nil {
+			return nil, errors.New("Invalid actor_id")
+		}
+		params.ActorID = id
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &params.From}, {"to", &params.To}} {
+		raw := ctx.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s, expected RFC3339", bound.name)
+		}
+		*bound.value = parsed
+	}
+
+	return params, nil
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type BillController struct {
+	billUseCase usecase.BillUseCase
+	logger      *logrus.Logger
+	validator   *validator.Validate
+}
+
+func NewBillController(billUseCase usecase.BillUseCase, logger *logrus.Logger) *BillController {
+	return &BillController{
+		billUseCase: billUseCase,
+		logger:      logger,
+		validator:   validator.New(),
+	}
+}
+
+func (c *BillController) GetBill(ctx *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Error("Failed to parse order ID: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	bill, err := c.billUseCase.GetBill(ctx.UserContext(), orderID)
+	if err != nil {
+		c.logger.Error("Failed to get bill: ", err)
+		return c.writeBillError(ctx, err, "Failed to get bill")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, bill, "Bill fetched successfully", nil)
+}
+
+func (c *BillController) SplitBill(ctx *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Error("Failed to parse order ID: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var request model.SplitBillRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Error("Failed to parse body: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Error("Validation failed: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	bill, err := c.billUseCase.SplitBill(ctx.UserContext(), orderID, &request)
+	if err != nil {
+		c.logger.Error("Failed to split bill: ", err)
+		return c.writeBillError(ctx, err, "Failed to split bill")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, bill, "Bill split successfully", nil)
+}
+
+func (c *BillController) AddPayment(ctx *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Error("Failed to parse order ID: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var request model.TenderRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Error("Failed to parse body: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Error("Validation failed: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	tender, err := c.billUseCase.AddTender(ctx.UserContext(), actorFromCtx(ctx), orderID, &request)
+	if err != nil {
+		c.logger.Error("Failed to record payment: ", err)
+		return c.writeBillError(ctx, err, "Failed to record payment")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, tender, "Payment recorded successfully", nil)
+}
+
+func (c *BillController) writeBillError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidSplit),
+		errors.Is(err, constants.ErrInvalidRequest),
+		errors.Is(err, constants.ErrAmountExceedsBalance):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrBillHasPayments),
+		errors.Is(err, constants.ErrBillSettled):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
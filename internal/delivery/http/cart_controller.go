@@ -32,7 +32,7 @@ func (c *CartController) AddCart(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	err := c.cartUseCase.CreateCart(customerID, &req)
+	err := c.cartUseCase.CreateCart(ctx.UserContext(), customerID, &req)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to create cart: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
@@ -49,7 +49,7 @@ func (c *CartController) GetCartByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	cart, err := c.cartUseCase.GetCartByID(cartID)
+	cart, err := c.cartUseCase.GetCartByID(ctx.UserContext(), cartID)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to fetch cart: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
@@ -67,7 +67,7 @@ func (c *CartController) GetCartByCustomerID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	data, meta, err := c.cartUseCase.GetCartByCustomerID(customerID, params)
+	data, meta, err := c.cartUseCase.GetCartByCustomerID(ctx.UserContext(), customerID, params)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to fetch carts: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
@@ -84,7 +84,7 @@ func (c *CartController) RemoveCart(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	err = c.cartUseCase.RemoveCart(customerID, cartID)
+	err = c.cartUseCase.RemoveCart(ctx.UserContext(), customerID, cartID)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to remove cart: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
@@ -96,7 +96,7 @@ func (c *CartController) RemoveCart(ctx *fiber.Ctx) error {
 func (c *CartController) ClearCart(ctx *fiber.Ctx) error {
 	customerID := ctx.Locals(constants.ClaimsKeyID).(int64)
 
-	err := c.cartUseCase.ClearCart(customerID)
+	err := c.cartUseCase.ClearCart(ctx.UserContext(), customerID)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to clear cart: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
@@ -117,7 +117,7 @@ func (c *CartController) BulkDeleteCart(ctx *fiber.Ctx) error {
 	}
 
 	c.logger.Info("Cart IDs to delete: ", req.CartIDs)
-	err := c.cartUseCase.BulkDeleteCart(customerID, req.CartIDs)
+	err := c.cartUseCase.BulkDeleteCart(ctx.UserContext(), customerID, req.CartIDs)
 	if err != nil {
 		c.logger.Errorf("❌ Failed to bulk delete carts: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
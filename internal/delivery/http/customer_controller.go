@@ -5,7 +5,9 @@ import (
 	"cakestore/internal/domain/model"
 	"cakestore/internal/usecase"
 	"cakestore/utils"
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -14,18 +16,30 @@ import (
 
 type CustomerController struct {
 	customerUseCase usecase.CustomerUseCase
+	authUseCase     usecase.AuthUseCase
+	accountUseCase  usecase.AccountUseCase
 	logger          *logrus.Logger
 	validator       *validator.Validate
 }
 
-func NewCustomerController(customerUseCase usecase.CustomerUseCase, logger *logrus.Logger) *CustomerController {
+func NewCustomerController(customerUseCase usecase.CustomerUseCase, authUseCase usecase.AuthUseCase, accountUseCase usecase.AccountUseCase, logger *logrus.Logger) *CustomerController {
 	return &CustomerController{
 		customerUseCase: customerUseCase,
+		authUseCase:     authUseCase,
+		accountUseCase:  accountUseCase,
 		logger:          logger,
 		validator:       validator.New(),
 	}
 }
 
+// sessionMeta describes the client the request came from
+func sessionMeta(ctx *fiber.Ctx) model.SessionMeta {
+	return model.SessionMeta{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IPAddress: ctx.IP(),
+	}
+}
+
 func (c *CustomerController) Authorize(ctx *fiber.Ctx) error {
 	c.logger.Tracef("Authorize controller")
 	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Authorized successfully", nil)
@@ -46,20 +60,24 @@ func (c *CustomerController) Register(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	customer, err := c.customerUseCase.Register(&request, role)
+	customer, err := c.customerUseCase.Register(ctx.UserContext(), &request, role)
 	if err != nil {
 		c.logger.Error("Failed to register customer: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(customer.ID, customer.Email, customer.Name, customer.Role)
+	// The account works without a verified email, only ordering waits for it
+	if err := c.accountUseCase.SendVerification(ctx.UserContext(), customer.ID); err != nil {
+		c.logger.Error("Failed to send verification email: ", err)
+	}
+
+	tokens, err := c.authUseCase.IssueTokens(ctx.UserContext(), customer, sessionMeta(ctx))
 	if err != nil {
 		c.logger.Error("Failed to generate token: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to generate token")
 	}
 
-	return utils.WriteResponse(ctx, fiber.StatusCreated, token, "Customer registered successfully", nil)
+	return utils.WriteResponse(ctx, fiber.StatusCreated, tokens, "Customer registered successfully", nil)
 }
 
 func (c *CustomerController) Login(ctx *fiber.Ctx) error {
@@ -74,13 +92,151 @@ func (c *CustomerController) Login(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	token, err := c.customerUseCase.Login(&request)
+	tokens, err := c.authUseCase.Login(ctx.UserContext(), &request, sessionMeta(ctx))
 	if err != nil {
 		c.logger.Error("Failed to login: ", err)
+		if errors.Is(err, constants.ErrInvalidCredentials) {
+			return utils.WriteErrorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+		}
+		if errors.Is(err, constants.ErrAccountLocked) {
+			return utils.WriteErrorResponse(ctx, fiber.StatusLocked, err.Error())
+		}
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to login")
 	}
 
-	return utils.WriteResponse(ctx, fiber.StatusOK, token, "Login successful", nil)
+	return utils.WriteResponse(ctx, fiber.StatusOK, tokens, "Login successful", nil)
+}
+
+func (c *CustomerController) Refresh(ctx *fiber.Ctx) error {
+	var request model.RefreshTokenRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Error("Failed to parse body: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Error("Validation failed: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := c.authUseCase.Refresh(ctx.UserContext(), request.RefreshToken, sessionMeta(ctx))
+	if err != nil {
+		c.logger.Error("Failed to refresh token: ", err)
+		if errors.Is(err, constants.ErrUnauthorized) {
+			return utils.WriteErrorResponse(ctx, fiber.StatusUnauthorized, "Invalid or expired refresh token")
+		}
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to refresh token")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, tokens, "Token refreshed successfully", nil)
+}
+
+func (c *CustomerController) Logout(ctx *fiber.Ctx) error {
+	var request model.LogoutRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			c.logger.Error("Failed to parse body: ", err)
+			return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	actor := actorFromCtx(ctx)
+	tokenID, _ := ctx.Locals(constants.ClaimsKeyTokenID).(string)
+	expiresAt, _ := ctx.Locals(constants.ClaimsKeyTokenExpiry).(time.Time)
+
+	if err := c.authUseCase.Logout(ctx.UserContext(), actor.CustomerID, tokenID, expiresAt, request.RefreshToken); err != nil {
+		c.logger.Error("Failed to logout: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to logout")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Logged out successfully", nil)
+}
+
+func (c *CustomerController) LogoutAll(ctx *fiber.Ctx) error {
+	actor := actorFromCtx(ctx)
+	if err := c.authUseCase.LogoutAll(ctx.UserContext(), actor.CustomerID); err != nil {
+		c.logger.Error("Failed to logout all sessions: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to logout all sessions")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Logged out of all sessions successfully", nil)
+}
+
+func (c *CustomerController) ForgotPassword(ctx *fiber.Ctx) error {
+	var request model.ForgotPasswordRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Error("Failed to parse body: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Error("Validation failed: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.accountUseCase.ForgotPassword(ctx.UserContext(), request.Email); err != nil {
+		c.logger.Error("Failed to send password reset: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to send password reset")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "If the email is registered, a reset link has been sent", nil)
+}
+
+func (c *CustomerController) ResetPassword(ctx *fiber.Ctx) error {
+	var request model.ResetPasswordRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Error("Failed to parse body: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Error("Validation failed: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.accountUseCase.ResetPassword(ctx.UserContext(), &request); err != nil {
+		c.logger.Error("Failed to reset password: ", err)
+		return c.writeAccountError(ctx, err, "Failed to reset password")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Password reset successfully", nil)
+}
+
+func (c *CustomerController) VerifyEmail(ctx *fiber.Ctx) error {
+	token := ctx.Query("token")
+	if token == "" {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Missing token")
+	}
+
+	if err := c.accountUseCase.VerifyEmail(ctx.UserContext(), token); err != nil {
+		c.logger.Error("Failed to verify email: ", err)
+		return c.writeAccountError(ctx, err, "Failed to verify email")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Email verified successfully", nil)
+}
+
+func (c *CustomerController) ResendVerification(ctx *fiber.Ctx) error {
+	actor := actorFromCtx(ctx)
+	if err := c.accountUseCase.SendVerification(ctx.UserContext(), actor.CustomerID); err != nil {
+		c.logger.Error("Failed to send verification email: ", err)
+		return c.writeAccountError(ctx, err, "Failed to send verification email")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Verification email sent", nil)
+}
+
+func (c *CustomerController) writeAccountError(ctx *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, constants.ErrInvalidToken):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Customer not found")
+	case errors.Is(err, constants.ErrEmailAlreadyVerified):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, message)
+	}
 }
 
 func (c *CustomerController) UpdateProfile(ctx *fiber.Ctx) error {
@@ -101,11 +257,18 @@ func (c *CustomerController) UpdateProfile(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := c.customerUseCase.UpdateCustomer(customerID, &request); err != nil {
+	emailChanged, err := c.customerUseCase.UpdateCustomer(ctx.UserContext(), customerID, &request)
+	if err != nil {
 		c.logger.Error("Failed to update profile: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update profile")
 	}
 
+	if emailChanged {
+		if err := c.accountUseCase.SendVerification(ctx.UserContext(), customerID); err != nil {
+			c.logger.Error("Failed to send verification email: ", err)
+		}
+	}
+
 	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Profile updated successfully", nil)
 }
 
@@ -119,7 +282,7 @@ func (c *CustomerController) GetCustomerByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid customer ID")
 	}
 
-	customer, err := c.customerUseCase.GetCustomerByID(customerID)
+	customer, err := c.customerUseCase.GetCustomerByID(ctx.UserContext(), customerID)
 	if err != nil {
 		c.logger.Error("Failed to get customer: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get customer")
@@ -129,7 +292,7 @@ func (c *CustomerController) GetCustomerByID(ctx *fiber.Ctx) error {
 }
 
 func (c *CustomerController) GetEmployees(ctx *fiber.Ctx) error {
-	employees, err := c.customerUseCase.GetEmployees()
+	employees, err := c.customerUseCase.GetEmployees(ctx.UserContext())
 	if err != nil {
 		c.logger.Error("Failed to get employees: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get employees")
@@ -149,7 +312,7 @@ func (c *CustomerController) GetEmployeeByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
 	}
 
-	employee, err := c.customerUseCase.GetEmployeeByID(employeeId)
+	employee, err := c.customerUseCase.GetEmployeeByID(ctx.UserContext(), employeeId)
 	if err != nil {
 		c.logger.Error("Failed to get employee: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, err.Error())
@@ -179,7 +342,7 @@ func (c *CustomerController) UpdateEmployee(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := c.customerUseCase.UpdateEmployee(employeeID, &request, role); err != nil {
+	if err := c.customerUseCase.UpdateEmployee(ctx.UserContext(), actorFromCtx(ctx), employeeID, &request, role); err != nil {
 		c.logger.Error("Failed to update employee: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update employee")
 	}
@@ -194,10 +357,56 @@ func (c *CustomerController) DeleteEmployee(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
 	}
 
-	if err := c.customerUseCase.DeleteEmployee(employeeID); err != nil {
+	if err := c.customerUseCase.DeleteEmployee(ctx.UserContext(), actorFromCtx(ctx), employeeID); err != nil {
 		c.logger.Error("Failed to delete employee: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete employee")
 	}
 
 	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Employee deleted successfully", nil)
 }
+
+func (c *CustomerController) RevokeEmployeeSessions(ctx *fiber.Ctx) error {
+	employeeID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Error("Failed to parse employee ID: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	if err := c.authUseCase.LogoutAll(ctx.UserContext(), employeeID); err != nil {
+		c.logger.Error("Failed to revoke employee sessions: ", err)
+		if errors.Is(err, constants.ErrNotFound) {
+			return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Employee not found")
+		}
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to revoke employee sessions")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Employee sessions revoked successfully", nil)
+}
+
+func (c *CustomerController) GetSessions(ctx *fiber.Ctx) error {
+	sessions, err := c.authUseCase.GetSessions(ctx.UserContext(), actorFromCtx(ctx).CustomerID)
+	if err != nil {
+		c.logger.Error("Failed to get sessions: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get sessions")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, sessions, "Sessions fetched successfully", nil)
+}
+
+func (c *CustomerController) UnlockAccount(ctx *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Error("Failed to parse customer ID: ", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid customer ID")
+	}
+
+	if err := c.authUseCase.UnlockAccount(ctx.UserContext(), actorFromCtx(ctx), customerID); err != nil {
+		c.logger.Error("Failed to unlock account: ", err)
+		if errors.Is(err, constants.ErrNotFound) {
+			return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Customer not found")
+		}
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to unlock account")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Account unlocked successfully", nil)
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type DepositController struct {
+	useCase   usecase.DepositUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewDepositController(useCase usecase.DepositUseCase, logger *logrus.Logger) *DepositController {
+	return &DepositController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *DepositController) GetRules(ctx *fiber.Ctx) error {
+	rules, err := c.useCase.GetRules(ctx.UserContext())
+	if err != nil {
+		c.logger.Errorf("Error getting deposit rules: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get deposit rules")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, rules, "Deposit rules retrieved successfully", nil)
+}
+
+func (c *DepositController) CreateRule(ctx *fiber.Ctx) error {
+	var request model.DepositRuleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	rule, err := c.useCase.CreateRule(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating deposit rule: %v", err)
+		return c.writeDepositError(ctx, err, "Failed to create deposit rule")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, rule, "Deposit rule created successfully", nil)
+}
+
+func (c *DepositController) UpdateRule(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing deposit rule ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid deposit rule ID")
+	}
+
+	var request model.DepositRuleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	rule, err := c.useCase.UpdateRule(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating deposit rule: %v", err)
+		return c.writeDepositError(ctx, err, "Failed to update deposit rule")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, rule, "Deposit rule updated successfully", nil)
+}
+
+func (c *DepositController) DeleteRule(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing deposit rule ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid deposit rule ID")
+	}
+
+	if err := c.useCase.DeleteRule(ctx.UserContext(), actorFromCtx(ctx), id); err != nil {
+		c.logger.Errorf("Error deleting deposit rule: %v", err)
+		return c.writeDepositError(ctx, err, "Failed to delete deposit rule")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Deposit rule deleted successfully", nil)
+}
+
+func (c *DepositController) writeDepositError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
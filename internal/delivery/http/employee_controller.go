@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type EmployeeController struct {
+	useCase   usecase.EmployeeUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewEmployeeController(useCase usecase.EmployeeUseCase, logger *logrus.Logger) *EmployeeController {
+	return &EmployeeController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *EmployeeController) InviteEmployee(ctx *fiber.Ctx) error {
+	var request model.InviteEmployeeRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	employee, err := c.useCase.Invite(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error inviting employee: %v", err)
+		return c.writeEmployeeError(ctx, err, "Failed to invite employee")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, employee, "Employee invited successfully", nil)
+}
+
+func (c *EmployeeController) ResendInvite(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing employee ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	if err := c.useCase.ResendInvite(ctx.UserContext(), id); err != nil {
+		c.logger.Errorf("Error resending invite: %v", err)
+		return c.writeEmployeeError(ctx, err, "Failed to resend invite")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Invite sent successfully", nil)
+}
+
+// AcceptInvite is public, the emailed token identifies the employee
+func (c *EmployeeController) AcceptInvite(ctx *fiber.Ctx) error {
+	var request model.AcceptInviteRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.useCase.AcceptInvite(ctx.UserContext(), &request); err != nil {
+		c.logger.Errorf("Error accepting invite: %v", err)
+		return c.writeEmployeeError(ctx, err, "Failed to accept invite")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Account set up successfully, you can now log in", nil)
+}
+
+func (c *EmployeeController) GetProfile(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing employee ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	profile, err := c.useCase.GetProfile(ctx.UserContext(), id)
+	if err != nil {
+		c.logger.Errorf("Error getting employee profile: %v", err)
+		return c.writeEmployeeError(ctx, err, "Failed to get employee profile")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, profile, "Employee profile retrieved successfully", nil)
+}
+
+func (c *EmployeeController) UpdateProfile(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing employee ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	var request model.UpdateEmployeeProfileRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	profile, err := c.useCase.UpdateProfile(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating employee profile: %v", err)
+		return c.writeEmployeeError(ctx, err, "Failed to update employee profile")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, profile, "Employee profile updated successfully", nil)
+}
+
+func (c *EmployeeController) parse(ctx *fiber.Ctx, request interface{}) error {
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return errors.New("Invalid request body")
+	}
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (c *EmployeeController) writeEmployeeError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Employee not found")
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrEmailTaken),
+		errors.Is(err, constants.ErrInviteAccepted):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	case errors.Is(err, constants.ErrInvalidToken):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid or expired invite")
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type FloorController struct {
+	useCase   usecase.FloorUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewFloorController(useCase usecase.FloorUseCase, logger *logrus.Logger) *FloorController {
+	return &FloorController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *FloorController) GetFloor(ctx *fiber.Ctx) error {
+	floor, err := c.useCase.GetFloor(ctx.UserContext())
+	if err != nil {
+		c.logger.Errorf("Error getting floor state: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get floor state")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, floor, "Floor state retrieved successfully", nil)
+}
+
+func (c *FloorController) GetAreas(ctx *fiber.Ctx) error {
+	areas, err := c.useCase.GetAreas(ctx.UserContext())
+	if err != nil {
+		c.logger.Errorf("Error getting floor areas: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get floor areas")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, areas, "Floor areas retrieved successfully", nil)
+}
+
+func (c *FloorController) CreateArea(ctx *fiber.Ctx) error {
+	var request model.AreaRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	area, err := c.useCase.CreateArea(ctx.UserContext(), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating floor area: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to create floor area")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, area, "Floor area created successfully", nil)
+}
+
+func (c *FloorController) UpdateArea(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing area ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid area ID")
+	}
+
+	var request model.AreaRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	area, err := c.useCase.UpdateArea(ctx.UserContext(), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating floor area: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to update floor area")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, area, "Floor area updated successfully", nil)
+}
+
+func (c *FloorController) DeleteArea(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing area ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid area ID")
+	}
+
+	if err := c.useCase.DeleteArea(ctx.UserContext(), id); err != nil {
+		c.logger.Errorf("Error deleting floor area: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to delete floor area")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Floor area deleted successfully", nil)
+}
+
+func (c *FloorController) CreateSection(ctx *fiber.Ctx) error {
+	var request model.SectionRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	section, err := c.useCase.CreateSection(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating floor section: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to create floor section")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, section, "Floor section created successfully", nil)
+}
+
+func (c *FloorController) UpdateSection(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing section ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid section ID")
+	}
+
+	var request model.SectionRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	section, err := c.useCase.UpdateSection(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating floor section: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to update floor section")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, section, "Floor section updated successfully", nil)
+}
+
+func (c *FloorController) DeleteSection(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing section ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid section ID")
+	}
+
+	if err := c.useCase.DeleteSection(ctx.UserContext(), actorFromCtx(ctx), id); err != nil {
+		c.logger.Errorf("Error deleting floor section: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to delete floor section")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Floor section deleted successfully", nil)
+}
+
+func (c *FloorController) AssignSectionStaff(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing section ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid section ID")
+	}
+
+	var request model.AssignSectionStaffRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	section, err := c.useCase.AssignStaff(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error assigning section staff: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to assign section staff")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, section, "Section staff assigned successfully", nil)
+}
+
+// AssignOnShiftStaff spreads the waitstaff currently on shift over the sections
+func (c *FloorController) AssignOnShiftStaff(ctx *fiber.Ctx) error {
+	areas, err := c.useCase.AssignOnShiftStaff(ctx.UserContext(), actorFromCtx(ctx))
+	if err != nil {
+		c.logger.Errorf("Error assigning on-shift staff: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to assign on-shift staff")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, areas, "On-shift staff assigned successfully", nil)
+}
+
+func (c *FloorController) UpdateTableLayout(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing table ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid table ID")
+	}
+
+	var request model.UpdateTableLayoutRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	table, err := c.useCase.UpdateTableLayout(ctx.UserContext(), uint(id), &request)
+	if err != nil {
+		c.logger.Errorf("Error updating table layout: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to update table layout")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, table, "Table layout updated successfully", nil)
+}
+
+func (c *FloorController) UpdateTableStatus(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing table ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid table ID")
+	}
+
+	var request model.UpdateTableStatusRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	table, err := c.useCase.UpdateTableStatus(ctx.UserContext(), uint(id), &request)
+	if err != nil {
+		c.logger.Errorf("Error updating table status: %v", err)
+		return c.writeFloorError(ctx, err, "Failed to update table status")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, table, "Table status updated successfully", nil)
+}
+
+func (c *FloorController) writeFloorError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidTableTransition),
+		errors.Is(err, constants.ErrAreaHasSections),
+		errors.Is(err, constants.ErrNoStaffOnShift):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -29,7 +29,7 @@ func (c *InventoryController) CreateInventory(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	ingredient, err := c.useCase.Create(&request)
+	ingredient, err := c.useCase.Create(ctx.UserContext(), actorFromCtx(ctx), &request)
 	if err != nil {
 		c.logger.Errorf("Error creating ingredient: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to create ingredient")
@@ -48,7 +48,7 @@ func (c *InventoryController) GetInventoryByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid ingredient ID")
 	}
 
-	ingredient, err := c.useCase.GetByID(uint(id))
+	ingredient, err := c.useCase.GetByID(ctx.UserContext(), uint(id))
 	if err != nil {
 		c.logger.Errorf("Error getting ingredient: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Ingredient not found")
@@ -66,7 +66,7 @@ func (c *InventoryController) GetAllInventories(ctx *fiber.Ctx) error {
 	params.Limit = int64(perPage)
 	params.Search = ctx.Query("search")
 
-	ingredients, err := c.useCase.GetAll(params)
+	ingredients, err := c.useCase.GetAll(ctx.UserContext(), params)
 	if err != nil {
 		c.logger.Errorf("Error getting ingredients: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get ingredients")
@@ -88,7 +88,7 @@ func (c *InventoryController) UpdateInventory(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	ingredient, err := c.useCase.Update(uint(id), &request)
+	ingredient, err := c.useCase.Update(ctx.UserContext(), actorFromCtx(ctx), uint(id), &request)
 	if err != nil {
 		c.logger.Errorf("Error updating ingredient: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update ingredient")
@@ -104,7 +104,7 @@ func (c *InventoryController) DeleteInventory(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid ingredient ID")
 	}
 
-	if err := c.useCase.Delete(uint(id)); err != nil {
+	if err := c.useCase.Delete(ctx.UserContext(), actorFromCtx(ctx), uint(id)); err != nil {
 		c.logger.Errorf("Error deleting ingredient: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete ingredient")
 	}
@@ -127,7 +127,7 @@ func (c *InventoryController) UpdateInventoryStock(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := c.useCase.UpdateStock(uint(id), request.Quantity); err != nil {
+	if err := c.useCase.UpdateStock(ctx.UserContext(), actorFromCtx(ctx), uint(id), request.Quantity); err != nil {
 		c.logger.Errorf("Error updating ingredient stock: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update ingredient stock")
 	}
@@ -137,7 +137,7 @@ func (c *InventoryController) UpdateInventoryStock(ctx *fiber.Ctx) error {
 
 func (c *InventoryController) GetLowStockInventories(ctx *fiber.Ctx) error {
 	c.logger.Info("HIT")
-	ingredients, err := c.useCase.GetLowStockIngredients()
+	ingredients, err := c.useCase.GetLowStockIngredients(ctx.UserContext())
 	c.logger.Info(ingredients)
 	if err != nil {
 		c.logger.Errorf("Error getting low stock ingredients: %v", err)
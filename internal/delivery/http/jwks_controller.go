@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"cakestore/internal/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSController struct {
+	tokens *token.Service
+}
+
+func NewJWKSController(tokens *token.Service) *JWKSController {
+	return &JWKSController{
+		tokens: tokens,
+	}
+}
+
+// GetJWKS publishes the public signing keys so other services can verify
+// our tokens, it is served as a plain JWK set rather than the API envelope
+func (c *JWKSController) GetJWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(c.tokens.JWKS())
+}
@@ -39,7 +39,7 @@ func (c *MenuController) GetAllMenus(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid query params")
 	}
 
-	menus, err := c.menuUseCase.GetAllMenus(&params)
+	menus, err := c.menuUseCase.GetAllMenus(ctx.UserContext(), &params)
 	if err != nil {
 		c.logger.Errorf("Failed to fetch menus: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to fetch menus")
@@ -69,7 +69,7 @@ func (c *MenuController) GetMenuByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
-	menu, err := c.menuUseCase.GetMenuByID(id)
+	menu, err := c.menuUseCase.GetMenuByID(ctx.UserContext(), id)
 	if err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
 			return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Menu not found")
@@ -106,7 +106,7 @@ func (c *MenuController) CreateMenu(ctx *fiber.Ctx) error {
 		DeletedAt:   sql.NullTime{},
 	}
 
-	if err := c.menuUseCase.CreateMenu(menu); err != nil {
+	if err := c.menuUseCase.CreateMenu(ctx.UserContext(), actorFromCtx(ctx), menu); err != nil {
 		c.logger.Error("Failed to create menu: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to create menu")
 	}
@@ -144,7 +144,7 @@ func (c *MenuController) UpdateMenu(ctx *fiber.Ctx) error {
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := c.menuUseCase.UpdateMenu(menu); err != nil {
+	if err := c.menuUseCase.UpdateMenu(ctx.UserContext(), actorFromCtx(ctx), menu); err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
 			return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Menu not found")
 		}
@@ -163,7 +163,7 @@ func (c *MenuController) DeleteMenu(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
-	err = c.menuUseCase.SoftDeleteMenu(id)
+	err = c.menuUseCase.SoftDeleteMenu(ctx.UserContext(), actorFromCtx(ctx), id)
 	if err != nil {
 		c.logger.Error("Failed to delete menu: ", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete menu")
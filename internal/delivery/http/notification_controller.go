@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationController struct {
+	useCase usecase.NotificationUseCase
+	logger  *logrus.Logger
+}
+
+func NewNotificationController(useCase usecase.NotificationUseCase, logger *logrus.Logger) *NotificationController {
+	return &NotificationController{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (c *NotificationController) GetNotifications(ctx *fiber.Ctx) error {
+	params := new(model.NotificationQueryParams)
+	params.Page = int64(ctx.QueryInt("page", 1))
+	params.Limit = int64(ctx.QueryInt("per_page", 10))
+	params.CustomerID = int64(ctx.QueryInt("customer_id"))
+	params.Status = ctx.Query("status")
+	params.Event = ctx.Query("event")
+
+	notifications, err := c.useCase.GetAll(ctx.UserContext(), params)
+	if err != nil {
+		c.logger.Errorf("Error getting notifications: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get notifications")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, notifications.Data, "Notifications retrieved successfully", model.ToPaginatedMeta(notifications))
+}
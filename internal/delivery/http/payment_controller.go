@@ -2,7 +2,6 @@ package controller
 
 import (
 	"cakestore/internal/constants"
-	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
 	"cakestore/internal/usecase"
 	"cakestore/utils"
@@ -25,14 +24,18 @@ type PaymentControllerImpl struct {
 	midtransServerKey string
 	orderUseCase      usecase.OrderUseCase
 	paymentUseCase    usecase.PaymentUseCase
+	billUseCase       usecase.BillUseCase
+	reservationUC     usecase.ReservationUseCase
 }
 
-func NewPaymentController(logger *logrus.Logger, midtransServerKey string, orderUseCase usecase.OrderUseCase, paymentUseCase usecase.PaymentUseCase) PaymentController {
+func NewPaymentController(logger *logrus.Logger, midtransServerKey string, orderUseCase usecase.OrderUseCase, paymentUseCase usecase.PaymentUseCase, billUseCase usecase.BillUseCase, reservationUC usecase.ReservationUseCase) PaymentController {
 	return &PaymentControllerImpl{
 		logger:            logger,
 		midtransServerKey: midtransServerKey,
 		orderUseCase:      orderUseCase,
 		paymentUseCase:    paymentUseCase,
+		billUseCase:       billUseCase,
+		reservationUC:     reservationUC,
 	}
 }
 
@@ -47,13 +50,13 @@ func (c *PaymentControllerImpl) GetPaymentURL(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid orderID")
 	}
 
-	order, err := c.orderUseCase.GetPendingOrder(customerID, orderID)
+	order, err := c.orderUseCase.GetPendingOrder(ctx.UserContext(), customerID, orderID)
 	if err != nil {
 		c.logger.Errorf("Failed to get order: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get order")
 	}
 
-	payment, err := c.paymentUseCase.GetPaymentByOrderID(model.ToOrderEntity(order))
+	payment, err := c.paymentUseCase.GetPaymentByOrderID(ctx.UserContext(), model.ToOrderEntity(order))
 	if err != nil {
 		c.logger.Errorf("Failed to get payment: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get payment")
@@ -74,6 +77,7 @@ func (c *PaymentControllerImpl) GetTransactionStatus(ctx *fiber.Ctx) error {
 	}
 
 	rawSignature := notif.OrderID + notif.StatusCode + notif.GrossAmount + c.midtransServerKey
+	externalID := notif.OrderID
 	parts := strings.Split(notif.OrderID, "-")
 	if len(parts) >= 2 {
 		notif.OrderID = parts[1]
@@ -90,37 +94,40 @@ func (c *PaymentControllerImpl) GetTransactionStatus(ctx *fiber.Ctx) error {
 	}
 	c.logger.Info("Webhook received")
 
-	switch notif.TransactionStatus {
-	case "capture", "settlement":
-		if err := c.orderUseCase.UpdateOrderStatus(notif.OrderID, string(entity.OrderStatusPaid)); err != nil {
-			c.logger.Errorf("Failed to update order status for settlement: %v", err)
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		if err := c.paymentUseCase.UpdateOrderStatus(notif.OrderID, constants.PaymentStatusSuccess); err != nil {
-			c.logger.Info("Failed to update payment status for settelement")
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Transaction successful", nil)
-	case "pending":
-		if err := c.orderUseCase.UpdateOrderStatus(notif.OrderID, string(entity.OrderStatusPending)); err != nil {
-			c.logger.Errorf("Failed to update order status for pending: %v", err)
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		if err := c.paymentUseCase.UpdateOrderStatus(notif.OrderID, constants.PaymentStatusPending); err != nil {
-			c.logger.Errorf("Failed to update payment status for pending: %v", err)
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Transaction pending", nil)
-	case "expire", "cancel":
-		if err := c.orderUseCase.UpdateOrderStatus(notif.OrderID, string(entity.OrderStatusCancelled)); err != nil {
-			c.logger.Errorf("Failed to update order status for expire: %v", err)
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		if err := c.paymentUseCase.UpdateOrderStatus(notif.OrderID, constants.PaymentStatusCancelled); err != nil {
-			c.logger.Errorf("Failed to update payment status for expire: %v", err)
-			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update order status")
-		}
-		return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Transaction cancelled", nil)
+	if strings.HasPrefix(externalID, constants.PaymentPrefixDeposit+"-") {
+		return c.handleDepositNotification(ctx, externalID, notif.TransactionStatus)
 	}
-	return ctx.SendStatus(fiber.StatusOK)
+	// a denied card can be retried on the same checkout page
+	if strings.HasPrefix(externalID, constants.PaymentPrefixOrder+"-") && notif.TransactionStatus == "deny" {
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+	// checkout links and split bill shares are settled per payment, the
+	// order is only marked paid once fully covered
+	return c.handleOrderNotification(ctx, externalID, notif.TransactionStatus)
+}
+
+func (c *PaymentControllerImpl) handleOrderNotification(ctx *fiber.Ctx, externalID, transactionStatus string) error {
+	status, ok := usecase.GatewayPaymentStatus(transactionStatus)
+	if !ok {
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+
+	if err := c.billUseCase.ApplyGatewayStatus(ctx.UserContext(), externalID, status); err != nil {
+		c.logger.Errorf("Failed to update payment %s: %v", externalID, err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update payment status")
+	}
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Payment updated", nil)
+}
+
+func (c *PaymentControllerImpl) handleDepositNotification(ctx *fiber.Ctx, externalID, transactionStatus string) error {
+	status, ok := usecase.GatewayPaymentStatus(transactionStatus)
+	if !ok {
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+
+	if err := c.reservationUC.ApplyDepositStatus(ctx.UserContext(), externalID, status); err != nil {
+		c.logger.Errorf("Failed to update reservation deposit %s: %v", externalID, err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update payment status")
+	}
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Reservation deposit updated", nil)
 }
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PosController struct {
+	useCase   usecase.PosUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewPosController(useCase usecase.PosUseCase, logger *logrus.Logger) *PosController {
+	return &PosController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *PosController) RegisterDevice(ctx *fiber.Ctx) error {
+	var request model.RegisterDeviceRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	device, err := c.useCase.RegisterDevice(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error registering POS device: %v", err)
+		return c.writePosError(ctx, err, "Failed to register device")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, device, "Device registered successfully", nil)
+}
+
+func (c *PosController) GetDevices(ctx *fiber.Ctx) error {
+	devices, err := c.useCase.GetDevices(ctx.UserContext())
+	if err != nil {
+		c.logger.Errorf("Error getting POS devices: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get devices")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, devices, "Devices retrieved successfully", nil)
+}
+
+func (c *PosController) RevokeDevice(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing device ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid device ID")
+	}
+
+	if err := c.useCase.RevokeDevice(ctx.UserContext(), actorFromCtx(ctx), id); err != nil {
+		c.logger.Errorf("Error revoking POS device: %v", err)
+		return c.writePosError(ctx, err, "Failed to revoke device")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Device revoked successfully", nil)
+}
+
+func (c *PosController) SetPin(ctx *fiber.Ctx) error {
+	var request model.SetPinRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "PIN must be 4 to 6 digits and the current password is required")
+	}
+
+	if err := c.useCase.SetPin(ctx.UserContext(), actorFromCtx(ctx).CustomerID, &request); err != nil {
+		c.logger.Errorf("Error setting staff PIN: %v", err)
+		return c.writePosError(ctx, err, "Failed to set PIN")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "PIN set successfully", nil)
+}
+
+func (c *PosController) GetStaff(ctx *fiber.Ctx) error {
+	staff, err := c.useCase.GetStaff(ctx.UserContext(), ctx.Get(constants.DeviceTokenHeader))
+	if err != nil {
+		c.logger.Errorf("Error getting POS staff: %v", err)
+		return c.writePosError(ctx, err, "Failed to get staff")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, staff, "Staff retrieved successfully", nil)
+}
+
+func (c *PosController) PinLogin(ctx *fiber.Ctx) error {
+	var request model.PinLoginRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := c.useCase.PinLogin(ctx.UserContext(), ctx.Get(constants.DeviceTokenHeader), &request)
+	if err != nil {
+		c.logger.Errorf("Error logging in with PIN: %v", err)
+		return c.writePosError(ctx, err, "Failed to log in")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, tokens, "Login successful", nil)
+}
+
+func (c *PosController) writePosError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrInvalidDevice),
+		errors.Is(err, constants.ErrInvalidCredentials):
+		return utils.WriteErrorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, constants.ErrPinLocked):
+		return utils.WriteErrorResponse(ctx, fiber.StatusLocked, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RateLimitController struct {
+	useCase usecase.RateLimitUseCase
+	logger  *logrus.Logger
+}
+
+func NewRateLimitController(useCase usecase.RateLimitUseCase, logger *logrus.Logger) *RateLimitController {
+	return &RateLimitController{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (c *RateLimitController) GetRateLimits(ctx *fiber.Ctx) error {
+	var query model.RateLimitClientQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	status, err := c.useCase.GetStatus(ctx.UserContext(), &query)
+	if err != nil {
+		c.logger.Errorf("Error getting rate limits: %v", err)
+		return c.writeRateLimitError(ctx, err, "Failed to get rate limits")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, status, "Rate limits retrieved successfully", nil)
+}
+
+func (c *RateLimitController) ResetRateLimits(ctx *fiber.Ctx) error {
+	var query model.RateLimitClientQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	status, err := c.useCase.Reset(ctx.UserContext(), actorFromCtx(ctx), &query)
+	if err != nil {
+		c.logger.Errorf("Error resetting rate limits: %v", err)
+		return c.writeRateLimitError(ctx, err, "Failed to reset rate limits")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, status, "Rate limits reset successfully", nil)
+}
+
+func (c *RateLimitController) writeRateLimitError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Customer not found")
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -5,22 +5,26 @@ import (
 	"cakestore/internal/domain/model"
 	"cakestore/internal/usecase"
 	"cakestore/utils"
+	"errors"
 	"strconv"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
 type ReservationController struct {
-	useCase usecase.ReservationUseCase
-	logger  *logrus.Logger
+	useCase     usecase.ReservationUseCase
+	roleUseCase usecase.RoleUseCase
+	logger      *logrus.Logger
 }
 
-func NewReservationController(useCase usecase.ReservationUseCase, logger *logrus.Logger) *ReservationController {
+func NewReservationController(useCase usecase.ReservationUseCase, roleUseCase usecase.RoleUseCase, logger *logrus.Logger) *ReservationController {
 	return &ReservationController{
-		useCase: useCase,
-		logger:  logger,
+		useCase:     useCase,
+		roleUseCase: roleUseCase,
+		logger:      logger,
 	}
 }
 
@@ -33,7 +37,7 @@ func (c *ReservationController) AdminGetAllCustomerReservations(ctx *fiber.Ctx)
 	params.Page = int64(page)
 	params.Limit = int64(perPage)
 
-	reservations, err := c.useCase.AdminGetAllCustomerReservations(params)
+	reservations, err := c.useCase.AdminGetAllCustomerReservations(ctx.UserContext(), params)
 	if err!= nil {
 		c.logger.Errorf("Error getting reservations: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get reservations")
@@ -50,7 +54,7 @@ func (c *ReservationController) CreateReservation(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	reservation, err := c.useCase.Create(uint(customerID), &request)
+	reservation, err := c.useCase.Create(ctx.UserContext(), uint(customerID), &request)
 	if err != nil {
 		c.logger.Errorf("Error creating reservation: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to create reservation")
@@ -69,10 +73,10 @@ func (c *ReservationController) GetReservationByID(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
 	}
 
-	reservation, err := c.useCase.GetByID(uint(id))
+	reservation, err := c.useCase.GetByID(ctx.UserContext(), actorFromCtx(ctx), uint(id))
 	if err != nil {
 		c.logger.Errorf("Error getting reservation: %v", err)
-		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Reservation not found")
+		return c.writeReservationError(ctx, err, "Failed to get reservation")
 	}
 
 	return ctx.JSON(utils.Response{
@@ -90,8 +94,8 @@ func (c *ReservationController) GetAllReservations(ctx *fiber.Ctx) error {
 	params.Page = int64(page)
 	params.Limit = int64(perPage)
 
-	customerID := ctx.Locals(constants.ClaimsKeyID).(int64)
-	if customerID != 0 {
+	// staff may filter by customer, customers are limited to their own in the usecase
+	if customerID := ctx.QueryInt("customer_id"); customerID > 0 {
 		params.CustomerID = uint(customerID)
 	}
 
@@ -113,7 +117,7 @@ func (c *ReservationController) GetAllReservations(ctx *fiber.Ctx) error {
 		}
 	}
 
-	reservations, err := c.useCase.GetAll(params)
+	reservations, err := c.useCase.GetAll(ctx.UserContext(), actorFromCtx(ctx), params)
 	if err != nil {
 		c.logger.Errorf("Error getting reservations: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get reservations")
@@ -135,10 +139,15 @@ func (c *ReservationController) UpdateReservation(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	reservation, err := c.useCase.Update(uint(id), &request)
+	if err := validator.New().Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	reservation, err := c.useCase.Update(ctx.UserContext(), actorFromCtx(ctx), uint(id), &request)
 	if err != nil {
 		c.logger.Errorf("Error updating reservation: %v", err)
-		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to update reservation")
+		return c.writeReservationError(ctx, err, "Failed to update reservation")
 	}
 
 	return ctx.JSON(utils.Response{
@@ -154,7 +163,7 @@ func (c *ReservationController) DeleteReservation(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
 	}
 
-	if err := c.useCase.Delete(uint(id)); err != nil {
+	if err := c.useCase.Delete(ctx.UserContext(), actorFromCtx(ctx), uint(id)); err != nil {
 		c.logger.Errorf("Error deleting reservation: %v", err)
 		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete reservation")
 	}
@@ -163,3 +172,144 @@ func (c *ReservationController) DeleteReservation(ctx *fiber.Ctx) error {
 		Message: "Reservation deleted successfully",
 	})
 }
+
+func (c *ReservationController) CancelReservation(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing reservation ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	reservation, err := c.useCase.Cancel(ctx.UserContext(), actorFromCtx(ctx), uint(id))
+	if err != nil {
+		c.logger.Errorf("Error cancelling reservation: %v", err)
+		return c.writeReservationError(ctx, err, "Failed to cancel reservation")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, reservation, "Reservation cancelled successfully", nil)
+}
+
+func (c *ReservationController) ModifyReservation(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing reservation ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	var request model.ModifyReservationRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	reservation, err := c.useCase.Modify(ctx.UserContext(), actorFromCtx(ctx), uint(id), &request)
+	if err != nil {
+		c.logger.Errorf("Error modifying reservation: %v", err)
+		return c.writeReservationError(ctx, err, "Failed to modify reservation")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, reservation, "Reservation updated successfully", nil)
+}
+
+func (c *ReservationController) GetDeposit(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing reservation ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	deposit, err := c.useCase.GetDeposit(ctx.UserContext(), actorFromCtx(ctx), uint(id))
+	if err != nil {
+		c.logger.Errorf("Error getting reservation deposit: %v", err)
+		return c.writeReservationError(ctx, err, "Failed to get reservation deposit")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, deposit, "Reservation deposit retrieved successfully", nil)
+}
+
+func (c *ReservationController) MarkNoShow(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing reservation ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	// the body is optional, an empty one forfeits the deposit
+	var request model.NoShowRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			c.logger.Errorf("Error parsing request body: %v", err)
+			return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	// giving the deposit back is a refund and needs its own permission
+	if request.RefundDeposit {
+		allowed, err := c.roleUseCase.HasPermission(ctx.UserContext(), actorFromCtx(ctx).Role, constants.PermRefundCreate)
+		if err != nil {
+			c.logger.Errorf("Error checking refund permission: %v", err)
+			return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to mark reservation as no-show")
+		}
+		if !allowed {
+			return utils.WriteErrorResponse(ctx, fiber.StatusForbidden, "You don't have permission to refund deposits")
+		}
+	}
+
+	reservation, err := c.useCase.MarkNoShow(ctx.UserContext(), actorFromCtx(ctx), uint(id), &request)
+	if err != nil {
+		c.logger.Errorf("Error marking reservation as no-show: %v", err)
+		return c.writeReservationError(ctx, err, "Failed to mark reservation as no-show")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, reservation, "Reservation marked as no-show", nil)
+}
+
+func (c *ReservationController) ApplyDeposit(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		c.logger.Errorf("Error parsing reservation ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	var request model.ApplyDepositRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	tender, err := c.useCase.ApplyDeposit(ctx.UserContext(), actorFromCtx(ctx), uint(id), &request)
+	if err != nil {
+		c.logger.Errorf("Error applying reservation deposit: %v", err)
+		return c.writeReservationError(ctx, err, "Failed to apply reservation deposit")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, tender, "Reservation deposit applied to bill", nil)
+}
+
+func (c *ReservationController) writeReservationError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrReservationLocked):
+		return utils.WriteErrorResponse(ctx, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, constants.ErrInvalidReservationStatus),
+		errors.Is(err, constants.ErrTableUnavailable),
+		errors.Is(err, constants.ErrDepositNotPaid),
+		errors.Is(err, constants.ErrBillSettled):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
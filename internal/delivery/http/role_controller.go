@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RoleController struct {
+	useCase   usecase.RoleUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewRoleController(useCase usecase.RoleUseCase, logger *logrus.Logger) *RoleController {
+	return &RoleController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *RoleController) GetRoles(ctx *fiber.Ctx) error {
+	roles, err := c.useCase.GetRoles(ctx.UserContext())
+	if err != nil {
+		c.logger.Errorf("Error getting roles: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get roles")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, roles, "Roles retrieved successfully", nil)
+}
+
+func (c *RoleController) GetPermissions(ctx *fiber.Ctx) error {
+	return utils.WriteResponse(ctx, fiber.StatusOK, c.useCase.GetPermissions(ctx.UserContext()), "Permissions retrieved successfully", nil)
+}
+
+func (c *RoleController) CreateRole(ctx *fiber.Ctx) error {
+	var request model.CreateRoleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	role, err := c.useCase.CreateRole(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating role: %v", err)
+		return c.writeRoleError(ctx, err, "Failed to create role")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, role, "Role created successfully", nil)
+}
+
+func (c *RoleController) UpdateRole(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing role ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid role ID")
+	}
+
+	var request model.UpdateRoleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	role, err := c.useCase.UpdateRole(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating role: %v", err)
+		return c.writeRoleError(ctx, err, "Failed to update role")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, role, "Role updated successfully", nil)
+}
+
+func (c *RoleController) DeleteRole(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing role ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid role ID")
+	}
+
+	if err := c.useCase.DeleteRole(ctx.UserContext(), actorFromCtx(ctx), id); err != nil {
+		c.logger.Errorf("Error deleting role: %v", err)
+		return c.writeRoleError(ctx, err, "Failed to delete role")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Role deleted successfully", nil)
+}
+
+func (c *RoleController) writeRoleError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrRoleExists),
+		errors.Is(err, constants.ErrRoleBuiltIn),
+		errors.Is(err, constants.ErrRoleInUse):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -4,29 +4,57 @@ import (
 	"cakestore/internal/constants"
 	http "cakestore/internal/delivery/http"
 	"cakestore/internal/middleware"
+	"cakestore/internal/ratelimit"
+	"cakestore/internal/token"
+	"cakestore/internal/usecase"
+	"time"
 
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/sirupsen/logrus"
 )
 
 type RouteConfig struct {
-	App                   *fiber.App
-	MenuController        *http.MenuController
-	CustomerController    *http.CustomerController
-	CartController        *http.CartController
-	OrderController       *http.OrderController
-	WishlistController    *http.WishListController
-	PaymentController     http.PaymentController
-	ReservationController *http.ReservationController
-	InventoryController   *http.InventoryController
-	TableController       *http.TableController
-	JWTSecret             string
-	Log                   *logrus.Logger
+	App                    *fiber.App
+	MenuController         *http.MenuController
+	CustomerController     *http.CustomerController
+	CartController         *http.CartController
+	OrderController        *http.OrderController
+	WishlistController     *http.WishListController
+	PaymentController      http.PaymentController
+	ReservationController  *http.ReservationController
+	InventoryController    *http.InventoryController
+	TableController        *http.TableController
+	BillController         *http.BillController
+	FloorController        *http.FloorController
+	DepositController      *http.DepositController
+	NotificationController *http.NotificationController
+	JWKSController         *http.JWKSController
+	RoleController         *http.RoleController
+	PosController          *http.PosController
+	TwoFactorController    *http.TwoFactorController
+	AuditController        *http.AuditController
+	EmployeeController     *http.EmployeeController
+	ShiftController        *http.ShiftController
+	TimeClockController    *http.TimeClockController
+	RateLimitController    *http.RateLimitController
+	AuthUseCase            usecase.AuthUseCase
+	RoleUseCase            usecase.RoleUseCase
+	PosUseCase             usecase.PosUseCase
+	Tokens                 *token.Service
+	RateLimiter            *ratelimit.Limiter
+	Log                    *logrus.Logger
+	// RequestTimeout bounds the work done for a request, exports get
+	// exportTimeout instead
+	RequestTimeout time.Duration
 }
 
+// exportTimeout is how long CSV exports may stream
+const exportTimeout = 5 * time.Minute
+
 func (c *RouteConfig) Setup() {
 	c.SetupRoute()
 }
@@ -34,19 +62,27 @@ func (c *RouteConfig) Setup() {
 func (c *RouteConfig) SetupRoute() {
 	// CORS configuration
 	c.App.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PATCH,PUT,DELETE",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-App-Role, User-Agent",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,PATCH,PUT,DELETE",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-App-Role, X-Device-Token, X-Request-ID, User-Agent",
+		ExposeHeaders: "X-Request-ID",
 	}))
 
 	// Performance profiling
 	c.App.Use(pprof.New())
 
+	// Request IDs tie log lines and audit entries to a request, a client
+	// supplied X-Request-ID is kept
+	c.App.Use(requestid.New(requestid.Config{ContextKey: constants.RequestIDKey}))
+
 	// Logging middleware
 	c.App.Use(middleware.LogMiddleware(c.Log))
 
+	// Queries run for a request are cancelled once it took too long
+	c.App.Use(middleware.RequestTimeout(c.RequestTimeout))
+
 	// Global rate limiting - Apply to all routes
-	c.App.Use(middleware.BasicRateLimit(c.Log))
+	c.App.Use(middleware.BasicRateLimit(c.RateLimiter, c.Tokens, c.Log))
 
 	// Static files
 	c.App.Static("/docs", "./docs")
@@ -62,46 +98,131 @@ func (c *RouteConfig) SetupRoute() {
 
 	// Public routes with specific rate limiting
 
-	// Authentication routes - strict rate limiting
-	authGroup := c.App.Group("/", middleware.AuthRateLimit(c.Log))
-	authGroup.Post("/register", c.CustomerController.Register)
-	authGroup.Post("/login", c.CustomerController.Login)
+	// Public signing keys for services verifying our tokens
+	c.App.Get("/.well-known/jwks.json", c.JWKSController.GetJWKS)
+
+	// Authentication routes - strict rate limiting. The limiter is added per
+	// route, a handler on Group("/") would apply to every route of the app
+	authLimit := middleware.AuthRateLimit(c.RateLimiter, c.Log)
+	authGroup := c.App.Group("/")
+	authGroup.Post("/register", authLimit, c.CustomerController.Register)
+	authGroup.Post("/login", authLimit, c.CustomerController.Login)
+	authGroup.Post("/auth/2fa/enroll", authLimit, c.TwoFactorController.EnrollChallenge)
+	authGroup.Post("/auth/2fa/verify", authLimit, c.TwoFactorController.Verify)
+	authGroup.Post("/auth/refresh", authLimit, c.CustomerController.Refresh)
+	authGroup.Post("/auth/logout", authLimit, middleware.AuthMiddleware(c.Tokens, c.AuthUseCase, c.PosUseCase), c.CustomerController.Logout)
+	authGroup.Post("/auth/logout-all", authLimit, middleware.AuthMiddleware(c.Tokens, c.AuthUseCase, c.PosUseCase), c.CustomerController.LogoutAll)
+	authGroup.Post("/auth/forgot-password", authLimit, c.CustomerController.ForgotPassword)
+	authGroup.Post("/auth/reset-password", authLimit, c.CustomerController.ResetPassword)
+	authGroup.Get("/auth/verify-email", authLimit, c.CustomerController.VerifyEmail)
+	authGroup.Post("/auth/accept-invite", authLimit, c.EmployeeController.AcceptInvite)
+	authGroup.Post("/auth/resend-verification", authLimit, middleware.AuthMiddleware(c.Tokens, c.AuthUseCase, c.PosUseCase), c.CustomerController.ResendVerification)
+
+	// POS terminals - staff switch users with a PIN, identified by X-Device-Token
+	authGroup.Get("/pos/staff", authLimit, c.PosController.GetStaff)
+	authGroup.Post("/pos/pin-login", authLimit, c.PosController.PinLogin)
 
 	// Payment webhook - separate rate limiting
-	paymentWebhook := c.App.Group("/payment", middleware.PaymentRateLimit(c.Log))
+	paymentWebhook := c.App.Group("/payment", middleware.PaymentRateLimit(c.RateLimiter, c.Log))
 	paymentWebhook.Post("/notification/", c.PaymentController.GetTransactionStatus)
 
 	// Menu routes - moderate rate limiting for public access
-	menuPublic := c.App.Group("/menus", middleware.IPBasedRateLimit(50, 15*60, c.Log)) // 50 requests per 15 minutes
+	menuPublic := c.App.Group("/menus", middleware.IPBasedRateLimit(c.RateLimiter, constants.RateLimitMenusPublic, c.Log)) // 50 requests per 15 minutes by default
 	menuPublic.Get("/", c.MenuController.GetAllMenus)
 	menuPublic.Get("/:id", c.MenuController.GetMenuByID)
 
 	// Protected routes
 	protectedRoutes := c.App.Group("/api/v1",
-		middleware.AuthMiddleware(c.JWTSecret),
-		middleware.UserBasedRateLimit(200, 60*60, c.Log), // 200 requests per hour per user
+		middleware.AuthMiddleware(c.Tokens, c.AuthUseCase, c.PosUseCase),
+		middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitAPI, c.Log), // 200 requests per hour per user by default
 	)
 
 	// Customer routes
 	protectedRoutes.Get("/authorize", c.CustomerController.Authorize)
 	protectedRoutes.Get("/customers/me", c.CustomerController.GetCustomerByID)
 	protectedRoutes.Put("/customers/:id", c.CustomerController.UpdateProfile)
+	protectedRoutes.Get("/customers/me/sessions", c.CustomerController.GetSessions)
+	protectedRoutes.Put("/customers/me/pin", c.PosController.SetPin)
+	protectedRoutes.Post("/customers/:id/unlock", middleware.RequirePermission(c.RoleUseCase, constants.PermAccountUnlock), c.CustomerController.UnlockAccount)
+
+	// Two-factor authentication - enrollment, recovery codes and admin reset
+	protectedRoutes.Get("/customers/me/2fa", c.TwoFactorController.GetStatus)
+	protectedRoutes.Post("/customers/me/2fa/enroll", c.TwoFactorController.Enroll)
+	protectedRoutes.Post("/customers/me/2fa/confirm", c.TwoFactorController.Confirm)
+	protectedRoutes.Post("/customers/me/2fa/recovery-codes", c.TwoFactorController.RegenerateRecoveryCodes)
+	protectedRoutes.Delete("/customers/me/2fa", c.TwoFactorController.Disable)
+	protectedRoutes.Delete("/customers/:id/2fa", middleware.RequirePermission(c.RoleUseCase, constants.PermAccountUnlock), c.TwoFactorController.Reset)
 
 	// Employee routes - Admin/Manager level rate limiting
 	employeeRoutes := protectedRoutes.Group("/employees")
-	employeeRoutes.Get("/", c.CustomerController.GetEmployees)
-	employeeRoutes.Get("/:id", c.CustomerController.GetEmployeeByID)
-	employeeRoutes.Put("/:id", middleware.RoleMiddleware(constants.RoleAdmin), c.CustomerController.UpdateEmployee)
-	employeeRoutes.Delete("/:id", middleware.RoleMiddleware(constants.RoleAdmin), c.CustomerController.DeleteEmployee)
+	employeeRoutes.Get("/", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeRead), c.CustomerController.GetEmployees)
+	employeeRoutes.Get("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeRead), c.CustomerController.GetEmployeeByID)
+	employeeRoutes.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.CustomerController.UpdateEmployee)
+	employeeRoutes.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.CustomerController.DeleteEmployee)
+	employeeRoutes.Post("/:id/logout-all", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.CustomerController.RevokeEmployeeSessions)
+	employeeRoutes.Post("/invite", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.EmployeeController.InviteEmployee)
+	employeeRoutes.Post("/:id/invite", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.EmployeeController.ResendInvite)
+	employeeRoutes.Get("/:id/profile", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeRead), c.EmployeeController.GetProfile)
+	employeeRoutes.Put("/:id/profile", middleware.RequirePermission(c.RoleUseCase, constants.PermEmployeeWrite), c.EmployeeController.UpdateProfile)
+
+	// Shift routes - weekly schedule and who is working now
+	shifts := protectedRoutes.Group("/shifts", middleware.RequirePermission(c.RoleUseCase, constants.PermShiftRead))
+	shifts.Get("/", c.ShiftController.GetShifts)
+	shifts.Get("/on-shift", c.ShiftController.GetOnShift)
+	shifts.Post("/", middleware.RequirePermission(c.RoleUseCase, constants.PermShiftManage), c.ShiftController.CreateShift)
+	shifts.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermShiftManage), c.ShiftController.UpdateShift)
+	shifts.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermShiftManage), c.ShiftController.DeleteShift)
+
+	// Time clock routes - punches are accepted from POS PIN sessions only
+	timeClock := protectedRoutes.Group("/time-clock", middleware.RequirePermission(c.RoleUseCase, constants.PermTimeClock))
+	timeClock.Get("/me", c.TimeClockController.GetCurrent)
+	timeClock.Post("/clock-in", c.TimeClockController.ClockIn)
+	timeClock.Post("/clock-out", c.TimeClockController.ClockOut)
+	timeClock.Post("/breaks/start", c.TimeClockController.StartBreak)
+	timeClock.Post("/breaks/end", c.TimeClockController.EndBreak)
+
+	// Time entry and timesheet routes - manager corrections and pay period reports
+	timeEntries := protectedRoutes.Group("/time-entries", middleware.RequirePermission(c.RoleUseCase, constants.PermTimesheetManage))
+	timeEntries.Get("/", c.TimeClockController.GetEntries)
+	timeEntries.Post("/", c.TimeClockController.CreateEntry)
+	timeEntries.Put("/:id", c.TimeClockController.CorrectEntry)
+
+	timesheets := protectedRoutes.Group("/timesheets", middleware.RequirePermission(c.RoleUseCase, constants.PermTimesheetManage))
+	timesheets.Get("/", c.TimeClockController.GetTimesheet)
+	timesheets.Get("/export", middleware.RequestTimeout(exportTimeout), c.TimeClockController.ExportTimesheet)
+
+	// POS device routes - register and revoke shared terminals
+	devices := protectedRoutes.Group("/devices", middleware.RequirePermission(c.RoleUseCase, constants.PermDeviceManage))
+	devices.Get("/", c.PosController.GetDevices)
+	devices.Post("/", c.PosController.RegisterDevice)
+	devices.Delete("/:id", c.PosController.RevokeDevice)
+
+	// Role routes - custom roles and the permission policy
+	roles := protectedRoutes.Group("/roles", middleware.RequirePermission(c.RoleUseCase, constants.PermRoleManage))
+	roles.Get("/", c.RoleController.GetRoles)
+	roles.Get("/permissions", c.RoleController.GetPermissions)
+	roles.Post("/", c.RoleController.CreateRole)
+	roles.Put("/:id", c.RoleController.UpdateRole)
+	roles.Delete("/:id", c.RoleController.DeleteRole)
+
+	// Audit log routes - who changed what
+	auditLogs := protectedRoutes.Group("/audit-logs", middleware.RequirePermission(c.RoleUseCase, constants.PermAuditRead))
+	auditLogs.Get("/", c.AuditController.GetAuditLogs)
+	auditLogs.Get("/export", middleware.RequestTimeout(exportTimeout), c.AuditController.ExportAuditLogs)
+
+	// Rate limits - inspect and reset the counters of a customer or IP address
+	rateLimits := protectedRoutes.Group("/rate-limits", middleware.RequirePermission(c.RoleUseCase, constants.PermRateLimitManage))
+	rateLimits.Get("/", c.RateLimitController.GetRateLimits)
+	rateLimits.Delete("/", c.RateLimitController.ResetRateLimits)
 
 	// Menu routes - Staff operations
 	menus := protectedRoutes.Group("/menus")
-	menus.Post("/", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier, constants.RoleKitchen, constants.RoleWaitress), c.MenuController.CreateMenu)
-	menus.Put("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier, constants.RoleKitchen, constants.RoleWaitress), c.MenuController.UpdateMenu)
-	menus.Delete("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier, constants.RoleKitchen, constants.RoleWaitress), c.MenuController.DeleteMenu)
+	menus.Post("/", middleware.RequirePermission(c.RoleUseCase, constants.PermMenuWrite), c.MenuController.CreateMenu)
+	menus.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermMenuWrite), c.MenuController.UpdateMenu)
+	menus.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermMenuWrite), c.MenuController.DeleteMenu)
 
 	// Cart routes - Higher rate limiting for frequent operations
-	carts := protectedRoutes.Group("/carts", middleware.UserBasedRateLimit(100, 15*60, c.Log)) // 100 requests per 15 minutes
+	carts := protectedRoutes.Group("/carts", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitCarts, c.Log)) // 100 requests per 15 minutes by default
 	carts.Post("/", c.CartController.AddCart)
 	carts.Get("/customer", c.CartController.GetCartByCustomerID)
 	carts.Get("/:id", c.CartController.GetCartByID)
@@ -110,49 +231,84 @@ func (c *RouteConfig) SetupRoute() {
 	carts.Post("/bulk", c.CartController.BulkDeleteCart)
 
 	// Order routes - Moderate rate limiting
-	orders := protectedRoutes.Group("/orders", middleware.UserBasedRateLimit(50, 60*60, c.Log)) // 50 orders per hour
-	orders.Get("/customers", c.OrderController.GetAllOrders)
+	orders := protectedRoutes.Group("/orders", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitOrders, c.Log)) // 50 orders per hour by default
+	orders.Get("/customers", middleware.RequirePermission(c.RoleUseCase, constants.PermOrderReadAll), c.OrderController.GetAllOrders)
 	orders.Post("/", c.OrderController.CreateOrder)
 	orders.Get("/", c.OrderController.GetCustomerOrders)
 	orders.Get("/:id", c.OrderController.GetOrderByID)
-	orders.Patch("/:id/food-status", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.OrderController.UpdateFoodStatus)
+	orders.Patch("/:id/food-status", middleware.RequirePermission(c.RoleUseCase, constants.PermOrderFoodStatus), c.OrderController.UpdateFoodStatus)
+
+	// Bill routes - split checks and multi-tender payments at the POS
+	orders.Get("/:id/bill", middleware.RequirePermission(c.RoleUseCase, constants.PermBillManage), c.BillController.GetBill)
+	orders.Post("/:id/bill/split", middleware.RequirePermission(c.RoleUseCase, constants.PermBillManage), c.BillController.SplitBill)
+	orders.Post("/:id/bill/payments", middleware.RequirePermission(c.RoleUseCase, constants.PermPaymentCreate), c.BillController.AddPayment)
 
 	// Payment routes - Strict rate limiting for security
-	payment := protectedRoutes.Group("/payments", middleware.UserBasedRateLimit(10, 60*60, c.Log)) // 10 payment requests per hour
+	payment := protectedRoutes.Group("/payments", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitPayments, c.Log)) // 10 payment requests per hour by default
 	payment.Get("/:id", c.PaymentController.GetPaymentURL)
 
 	// Wishlist routes - Moderate rate limiting
-	wishlist := protectedRoutes.Group("/wishlists", middleware.UserBasedRateLimit(30, 15*60, c.Log)) // 30 requests per 15 minutes
+	wishlist := protectedRoutes.Group("/wishlists", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitWishlists, c.Log)) // 30 requests per 15 minutes by default
 	wishlist.Get("/", c.WishlistController.GetWishListByCustomerID)
 	wishlist.Post("/:menuId", c.WishlistController.CreateWishList)
 	wishlist.Delete("/:menuId", c.WishlistController.DeleteWishList)
 
 	// Reservation routes - Moderate rate limiting
-	reservation := protectedRoutes.Group("/reservations", middleware.UserBasedRateLimit(20, 60*60, c.Log)) // 20 reservations per hour
+	reservation := protectedRoutes.Group("/reservations", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitReservations, c.Log)) // 20 reservations per hour by default
 	reservation.Post("/", c.ReservationController.CreateReservation)
 	reservation.Get("/", c.ReservationController.GetAllReservations)
-	reservation.Get("/admin", middleware.RoleMiddleware(constants.RoleAdmin), c.ReservationController.AdminGetAllCustomerReservations)
+	reservation.Get("/admin", middleware.RequirePermission(c.RoleUseCase, constants.PermReservationRead), c.ReservationController.AdminGetAllCustomerReservations)
 	reservation.Get("/:id", c.ReservationController.GetReservationByID)
-	reservation.Put("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleWaitress), c.ReservationController.UpdateReservation)
-	reservation.Delete("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleWaitress), c.ReservationController.DeleteReservation)
+	reservation.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermReservationWrite), c.ReservationController.UpdateReservation)
+	reservation.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermReservationWrite), c.ReservationController.DeleteReservation)
+	reservation.Patch("/:id", c.ReservationController.ModifyReservation)
+	reservation.Post("/:id/cancel", c.ReservationController.CancelReservation)
+	reservation.Get("/:id/deposit", c.ReservationController.GetDeposit)
+	reservation.Post("/:id/no-show", middleware.RequirePermission(c.RoleUseCase, constants.PermReservationWrite), c.ReservationController.MarkNoShow)
+	reservation.Post("/:id/apply-deposit", middleware.RequirePermission(c.RoleUseCase, constants.PermPaymentCreate), c.ReservationController.ApplyDeposit)
+
+	// Deposit rule routes - Admin only
+	depositRules := protectedRoutes.Group("/deposit-rules", middleware.RequirePermission(c.RoleUseCase, constants.PermDepositRuleManage))
+	depositRules.Get("/", c.DepositController.GetRules)
+	depositRules.Post("/", c.DepositController.CreateRule)
+	depositRules.Put("/:id", c.DepositController.UpdateRule)
+	depositRules.Delete("/:id", c.DepositController.DeleteRule)
+
+	// Notification log - Admin only
+	protectedRoutes.Get("/notifications", middleware.RequirePermission(c.RoleUseCase, constants.PermNotificationRead), c.NotificationController.GetNotifications)
 
 	// Inventory routes - Staff only, moderate rate limiting
-	inventory := protectedRoutes.Group("/inventories", middleware.UserBasedRateLimit(100, 60*60, c.Log)) // 100 requests per hour for staff
-	inventory.Get("/", c.InventoryController.GetAllInventories)
-	inventory.Get("/low-stock", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.GetLowStockInventories)
+	inventory := protectedRoutes.Group("/inventories", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitInventories, c.Log)) // 100 requests per hour for staff by default
+	inventory.Get("/", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryRead), c.InventoryController.GetAllInventories)
+	inventory.Get("/low-stock", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryRead), c.InventoryController.GetLowStockInventories)
 	// temporary fix for conflicting route (/low-stock)
-	inventory.Get("/by-id/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.GetInventoryByID)
-	inventory.Post("/", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.CreateInventory)
-	inventory.Put("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.UpdateInventory)
-	inventory.Delete("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.DeleteInventory)
-	inventory.Put("/:id/stock", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleKitchen), c.InventoryController.UpdateInventoryStock)
+	inventory.Get("/by-id/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryRead), c.InventoryController.GetInventoryByID)
+	inventory.Post("/", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryWrite), c.InventoryController.CreateInventory)
+	inventory.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryWrite), c.InventoryController.UpdateInventory)
+	inventory.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryWrite), c.InventoryController.DeleteInventory)
+	inventory.Put("/:id/stock", middleware.RequirePermission(c.RoleUseCase, constants.PermInventoryAdjust), c.InventoryController.UpdateInventoryStock)
 
 	// Table routes - Staff operations, moderate rate limiting
-	tables := protectedRoutes.Group("/tables", middleware.UserBasedRateLimit(80, 60*60, c.Log)) // 80 requests per hour
+	tables := protectedRoutes.Group("/tables", middleware.UserBasedRateLimit(c.RateLimiter, constants.RateLimitTables, c.Log)) // 80 requests per hour by default
 	tables.Get("/", c.TableController.GetAllTables)
 	tables.Get("/:id", c.TableController.GetTableByID)
-	tables.Post("/", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier), c.TableController.CreateTable)
-	tables.Put("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier), c.TableController.UpdateTable)
-	tables.Patch("/:id/availability", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier), c.TableController.UpdateTableAvailability)
-	tables.Delete("/:id", middleware.RoleMiddleware(constants.RoleAdmin, constants.RoleCashier), c.TableController.DeleteTable)
+	tables.Post("/", middleware.RequirePermission(c.RoleUseCase, constants.PermTableWrite), c.TableController.CreateTable)
+	tables.Put("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermTableWrite), c.TableController.UpdateTable)
+	tables.Patch("/:id/availability", middleware.RequirePermission(c.RoleUseCase, constants.PermTableWrite), c.TableController.UpdateTableAvailability)
+	tables.Delete("/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermTableWrite), c.TableController.DeleteTable)
+	tables.Put("/:id/layout", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.UpdateTableLayout)
+	tables.Patch("/:id/status", middleware.RequirePermission(c.RoleUseCase, constants.PermTableStatus), c.FloorController.UpdateTableStatus)
+
+	// Floor plan routes - live host stand view and layout management
+	floor := protectedRoutes.Group("/floor", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorRead))
+	floor.Get("/", c.FloorController.GetFloor)
+	floor.Get("/areas", c.FloorController.GetAreas)
+	floor.Post("/areas", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.CreateArea)
+	floor.Put("/areas/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.UpdateArea)
+	floor.Delete("/areas/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.DeleteArea)
+	floor.Post("/sections", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.CreateSection)
+	floor.Put("/sections/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.UpdateSection)
+	floor.Delete("/sections/:id", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.DeleteSection)
+	floor.Put("/sections/:id/staff", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.AssignSectionStaff)
+	floor.Post("/sections/assign-on-shift", middleware.RequirePermission(c.RoleUseCase, constants.PermFloorManage), c.FloorController.AssignOnShiftStaff)
 }
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ShiftController struct {
+	useCase   usecase.ShiftUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewShiftController(useCase usecase.ShiftUseCase, logger *logrus.Logger) *ShiftController {
+	return &ShiftController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+func (c *ShiftController) GetShifts(ctx *fiber.Ctx) error {
+	params := &model.ShiftQueryParams{
+		EmployeeID: int64(ctx.QueryInt("employee_id")),
+		Role:       ctx.Query("role"),
+	}
+
+	shifts, err := c.useCase.GetShifts(ctx.UserContext(), params)
+	if err != nil {
+		c.logger.Errorf("Error getting shifts: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get shifts")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, shifts, "Shifts retrieved successfully", nil)
+}
+
+// GetOnShift lists who is working right now, optionally for one role
+func (c *ShiftController) GetOnShift(ctx *fiber.Ctx) error {
+	working, err := c.useCase.OnShift(ctx.UserContext(), time.Now(), ctx.Query("role"))
+	if err != nil {
+		c.logger.Errorf("Error getting staff on shift: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get staff on shift")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, working, "Staff on shift retrieved successfully", nil)
+}
+
+func (c *ShiftController) CreateShift(ctx *fiber.Ctx) error {
+	var request model.ShiftRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	shift, err := c.useCase.CreateShift(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating shift: %v", err)
+		return c.writeShiftError(ctx, err, "Failed to create shift")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, shift, "Shift created successfully", nil)
+}
+
+func (c *ShiftController) UpdateShift(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing shift ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid shift ID")
+	}
+
+	var request model.ShiftRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	shift, err := c.useCase.UpdateShift(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error updating shift: %v", err)
+		return c.writeShiftError(ctx, err, "Failed to update shift")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, shift, "Shift updated successfully", nil)
+}
+
+func (c *ShiftController) DeleteShift(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing shift ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid shift ID")
+	}
+
+	if err := c.useCase.DeleteShift(ctx.UserContext(), actorFromCtx(ctx), id); err != nil {
+		c.logger.Errorf("Error deleting shift: %v", err)
+		return c.writeShiftError(ctx, err, "Failed to delete shift")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Shift deleted successfully", nil)
+}
+
+func (c *ShiftController) parse(ctx *fiber.Ctx, request interface{}) error {
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return errors.New("Invalid request body")
+	}
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (c *ShiftController) writeShiftError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Shift not found")
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrShiftOverlap):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TimeClockController struct {
+	useCase   usecase.TimeClockUseCase
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func NewTimeClockController(useCase usecase.TimeClockUseCase, logger *logrus.Logger) *TimeClockController {
+	return &TimeClockController{
+		useCase:   useCase,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+// GetCurrent returns the running time entry of the caller, null when clocked out
+func (c *TimeClockController) GetCurrent(ctx *fiber.Ctx) error {
+	entry, err := c.useCase.GetCurrent(ctx.UserContext(), actorFromCtx(ctx).CustomerID)
+	if err != nil {
+		c.logger.Errorf("Error getting current time entry: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get current time entry")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entry, "Current time entry retrieved successfully", nil)
+}
+
+func (c *TimeClockController) ClockIn(ctx *fiber.Ctx) error {
+	entry, err := c.useCase.ClockIn(ctx.UserContext(), actorFromCtx(ctx))
+	if err != nil {
+		c.logger.Errorf("Error clocking in: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to clock in")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, entry, "Clocked in successfully", nil)
+}
+
+func (c *TimeClockController) ClockOut(ctx *fiber.Ctx) error {
+	entry, err := c.useCase.ClockOut(ctx.UserContext(), actorFromCtx(ctx))
+	if err != nil {
+		c.logger.Errorf("Error clocking out: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to clock out")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entry, "Clocked out successfully", nil)
+}
+
+func (c *TimeClockController) StartBreak(ctx *fiber.Ctx) error {
+	entry, err := c.useCase.StartBreak(ctx.UserContext(), actorFromCtx(ctx))
+	if err != nil {
+		c.logger.Errorf("Error starting break: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to start break")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entry, "Break started successfully", nil)
+}
+
+func (c *TimeClockController) EndBreak(ctx *fiber.Ctx) error {
+	entry, err := c.useCase.EndBreak(ctx.UserContext(), actorFromCtx(ctx))
+	if err != nil {
+		c.logger.Errorf("Error ending break: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to end break")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entry, "Break ended successfully", nil)
+}
+
+func (c *TimeClockController) GetEntries(ctx *fiber.Ctx) error {
+	params := &model.TimeEntryQueryParams{EmployeeID: int64(ctx.QueryInt("employee_id"))}
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &params.From}, {"to", &params.To}} {
+		raw := ctx.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, fmt.Sprintf("Invalid %s, expected RFC3339", bound.name))
+		}
+		*bound.value = parsed
+	}
+
+	entries, err := c.useCase.GetEntries(ctx.UserContext(), params)
+	if err != nil {
+		c.logger.Errorf("Error getting time entries: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get time entries")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entries, "Time entries retrieved successfully", nil)
+}
+
+func (c *TimeClockController) CreateEntry(ctx *fiber.Ctx) error {
+	var request model.CreateTimeEntryRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	entry, err := c.useCase.CreateEntry(ctx.UserContext(), actorFromCtx(ctx), &request)
+	if err != nil {
+		c.logger.Errorf("Error creating time entry: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to create time entry")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusCreated, entry, "Time entry created successfully", nil)
+}
+
+func (c *TimeClockController) CorrectEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing time entry ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid time entry ID")
+	}
+
+	var request model.CorrectTimeEntryRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	entry, err := c.useCase.CorrectEntry(ctx.UserContext(), actorFromCtx(ctx), id, &request)
+	if err != nil {
+		c.logger.Errorf("Error correcting time entry: %v", err)
+		return c.writeTimeClockError(ctx, err, "Failed to correct time entry")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, entry, "Time entry corrected successfully", nil)
+}
+
+func (c *TimeClockController) GetTimesheet(ctx *fiber.Ctx) error {
+	params, err := c.timesheetParams(ctx)
+	if err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	timesheet, err := c.useCase.GetTimesheet(ctx.UserContext(), params)
+	if err != nil {
+		c.logger.Errorf("Error getting timesheet: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to get timesheet")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, timesheet, "Timesheet retrieved successfully", nil)
+}
+
+// ExportTimesheet downloads the timesheet of a pay period as CSV
+func (c *TimeClockController) ExportTimesheet(ctx *fiber.Ctx) error {
+	params, err := c.timesheetParams(ctx)
+	if err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	date := params.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	filename := fmt.Sprintf("timesheet-%s.csv", date.Format("20060102"))
+	ctx.Set(fiber.HeaderContentType, "text/csv")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := c.useCase.ExportTimesheet(ctx.UserContext(), params, ctx.Response().BodyWriter()); err != nil {
+		c.logger.Errorf("Error exporting timesheet: %v", err)
+		ctx.Response().ResetBody()
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, "Failed to export timesheet")
+	}
+
+	return nil
+}
+
+func (c *TimeClockController) timesheetParams(ctx *fiber.Ctx) (*model.TimesheetQueryParams, error) {
+	params := &model.TimesheetQueryParams{EmployeeID: int64(ctx.QueryInt("employee_id"))}
+	if raw := ctx.Query("date"); raw != "" {
+		date, err := time.ParseInLocation(model.DateLayout, raw, time.Local)
+		if err != nil {
+			return nil, errors.New("Invalid date, expected YYYY-MM-DD")
+		}
+		params.Date = date
+	}
+	return params, nil
+}
+
+func (c *TimeClockController) parse(ctx *fiber.Ctx, request interface{}) error {
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return errors.New("Invalid request body")
+	}
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (c *TimeClockController) writeTimeClockError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Time entry not found")
+	case errors.Is(err, constants.ErrInvalidRequest):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrDeviceRequired):
+		return utils.WriteErrorResponse(ctx, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, constants.ErrAlreadyClockedIn),
+		errors.Is(err, constants.ErrNotClockedIn),
+		errors.Is(err, constants.ErrAlreadyOnBreak),
+		errors.Is(err, constants.ErrNotOnBreak),
+		errors.Is(err, constants.ErrTimeEntryOverlap):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
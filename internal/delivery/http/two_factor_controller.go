@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/usecase"
+	"cakestore/utils"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TwoFactorController struct {
+	useCase     usecase.TwoFactorUseCase
+	authUseCase usecase.AuthUseCase
+	logger      *logrus.Logger
+	validator   *validator.Validate
+}
+
+func NewTwoFactorController(useCase usecase.TwoFactorUseCase, authUseCase usecase.AuthUseCase, logger *logrus.Logger) *TwoFactorController {
+	return &TwoFactorController{
+		useCase:     useCase,
+		authUseCase: authUseCase,
+		logger:      logger,
+		validator:   validator.New(),
+	}
+}
+
+func (c *TwoFactorController) GetStatus(ctx *fiber.Ctx) error {
+	status, err := c.useCase.GetStatus(ctx.UserContext(), actorFromCtx(ctx).CustomerID)
+	if err != nil {
+		c.logger.Errorf("Error getting two-factor status: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to get two-factor status")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, status, "Two-factor status fetched successfully", nil)
+}
+
+func (c *TwoFactorController) Enroll(ctx *fiber.Ctx) error {
+	enrollment, err := c.useCase.Enroll(ctx.UserContext(), actorFromCtx(ctx).CustomerID)
+	if err != nil {
+		c.logger.Errorf("Error enrolling two-factor authentication: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to start enrollment")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, enrollment, "Scan the QR code and confirm with a code", nil)
+}
+
+func (c *TwoFactorController) Confirm(ctx *fiber.Ctx) error {
+	var request model.TwoFactorCodeRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	codes, err := c.useCase.Confirm(ctx.UserContext(), actorFromCtx(ctx).CustomerID, request.Code)
+	if err != nil {
+		c.logger.Errorf("Error confirming two-factor authentication: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to enable two-factor authentication")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, codes, "Two-factor authentication enabled, store the recovery codes safely", nil)
+}
+
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var request model.TwoFactorCodeRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	codes, err := c.useCase.RegenerateRecoveryCodes(ctx.UserContext(), actorFromCtx(ctx).CustomerID, request.Code)
+	if err != nil {
+		c.logger.Errorf("Error regenerating recovery codes: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to regenerate recovery codes")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, codes, "Recovery codes regenerated successfully", nil)
+}
+
+func (c *TwoFactorController) Disable(ctx *fiber.Ctx) error {
+	var request model.DisableTwoFactorRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.useCase.Disable(ctx.UserContext(), actorFromCtx(ctx).CustomerID, &request); err != nil {
+		c.logger.Errorf("Error disabling two-factor authentication: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to disable two-factor authentication")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Two-factor authentication disabled successfully", nil)
+}
+
+func (c *TwoFactorController) Reset(ctx *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		c.logger.Errorf("Error parsing customer ID: %v", err)
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, "Invalid customer ID")
+	}
+
+	if err := c.useCase.Reset(ctx.UserContext(), actorFromCtx(ctx), customerID); err != nil {
+		c.logger.Errorf("Error resetting two-factor authentication: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to reset two-factor authentication")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, nil, "Two-factor authentication reset successfully", nil)
+}
+
+// EnrollChallenge starts enrollment during login for roles that require
+// two-factor authentication
+func (c *TwoFactorController) EnrollChallenge(ctx *fiber.Ctx) error {
+	var request model.TwoFactorChallengeRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	enrollment, err := c.useCase.EnrollChallenge(ctx.UserContext(), request.ChallengeToken)
+	if err != nil {
+		c.logger.Errorf("Error enrolling two-factor authentication at login: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to start enrollment")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, enrollment, "Scan the QR code and verify with a code", nil)
+}
+
+// Verify completes a login that returned a two-factor challenge
+func (c *TwoFactorController) Verify(ctx *fiber.Ctx) error {
+	var request model.TwoFactorLoginRequest
+	if err := c.parse(ctx, &request); err != nil {
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := c.authUseCase.LoginTwoFactor(ctx.UserContext(), &request, sessionMeta(ctx))
+	if err != nil {
+		c.logger.Errorf("Error verifying two-factor login: %v", err)
+		return c.writeTwoFactorError(ctx, err, "Failed to login")
+	}
+
+	return utils.WriteResponse(ctx, fiber.StatusOK, tokens, "Login successful", nil)
+}
+
+func (c *TwoFactorController) parse(ctx *fiber.Ctx, request interface{}) error {
+	if err := ctx.BodyParser(request); err != nil {
+		c.logger.Errorf("Error parsing request body: %v", err)
+		return errors.New("Invalid request body")
+	}
+	if err := c.validator.Struct(request); err != nil {
+		c.logger.Errorf("Validation failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (c *TwoFactorController) writeTwoFactorError(ctx *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, constants.ErrNotFound):
+		return utils.WriteErrorResponse(ctx, fiber.StatusNotFound, "Customer not found")
+	case errors.Is(err, constants.ErrInvalidRequest),
+		errors.Is(err, constants.ErrTwoFactorNotEnabled):
+		return utils.WriteErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, constants.ErrTwoFactorEnabled):
+		return utils.WriteErrorResponse(ctx, fiber.StatusConflict, err.Error())
+	case errors.Is(err, constants.ErrTwoFactorEnforced):
+		return utils.WriteErrorResponse(ctx, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, constants.ErrInvalidTwoFactorCode),
+		errors.Is(err, constants.ErrInvalidCredentials),
+		errors.Is(err, constants.ErrInvalidToken),
+		errors.Is(err, constants.ErrUnauthorized):
+		return utils.WriteErrorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, constants.ErrAccountLocked):
+		return utils.WriteErrorResponse(ctx, fiber.StatusLocked, err.Error())
+	default:
+		return utils.WriteErrorResponse(ctx, fiber.StatusInternalServerError, fallback)
+	}
+}
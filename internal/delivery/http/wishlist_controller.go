@@ -35,7 +35,7 @@ func (h *WishListController) CreateWishList(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, http.StatusBadRequest, "Invalid menu ID")
 	}
 
-	err = h.wishListUseCase.CreateWishList(customerID, menuID)
+	err = h.wishListUseCase.CreateWishList(ctx.UserContext(), customerID, menuID)
 	if err != nil {
 		if err == constants.ErrMenuAlreadyInWishlist {
 			h.logger.Warnf("Wishlist already exists: %v", err)
@@ -54,7 +54,7 @@ func (h *WishListController) GetWishListByCustomerID(ctx *fiber.Ctx) error {
 
 	paginationQuery := utils.GetPaginationFromRequest(ctx)
 
-	wishlists, meta, err := h.wishListUseCase.GetWishList(customerID, paginationQuery)
+	wishlists, meta, err := h.wishListUseCase.GetWishList(ctx.UserContext(), customerID, paginationQuery)
 	if err != nil {
 		h.logger.Errorf("Error getting wishlists: %v", err)
 		return utils.WriteErrorResponse(ctx, http.StatusInternalServerError, "Failed to get wishlists")
@@ -72,7 +72,7 @@ func (c *WishListController) DeleteWishList(ctx *fiber.Ctx) error {
 		return utils.WriteErrorResponse(ctx, http.StatusBadRequest, "Invalid menu ID")
 	}
 
-	err = c.wishListUseCase.DeleteWishList(customerID, menuID)
+	err = c.wishListUseCase.DeleteWishList(ctx.UserContext(), customerID, menuID)
 	if err != nil {
 		c.logger.Errorf("Error deleting wishlist: %v", err)
 		return utils.WriteErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete wishlist")
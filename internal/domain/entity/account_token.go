@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type AccountTokenPurpose string
+
+const (
+	AccountTokenPasswordReset     AccountTokenPurpose = "password_reset"
+	AccountTokenEmailVerification AccountTokenPurpose = "email_verification"
+	AccountTokenEmployeeInvite    AccountTokenPurpose = "employee_invite"
+)
+
+// AccountToken is a single-use token emailed to a customer, only its SHA-256
+// hash is stored
+type AccountToken struct {
+	ID         int64               `gorm:"column:id;primaryKey"`
+	CustomerID int64               `gorm:"column:customer_id;not null;index"`
+	Purpose    AccountTokenPurpose `gorm:"column:purpose;not null"`
+	TokenHash  string              `gorm:"column:token_hash;not null;uniqueIndex"`
+	ExpiresAt  time.Time           `gorm:"column:expires_at;not null"`
+	UsedAt     *time.Time          `gorm:"column:used_at"`
+	CreatedAt  time.Time           `gorm:"column:created_at"`
+}
+
+func (t *AccountToken) TableName() string {
+	return "account_tokens"
+}
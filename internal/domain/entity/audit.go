@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// AuditLog records a change made through the API. Entries are append-only,
+// the initial schema migration installs a trigger that rejects updates and deletes.
+type AuditLog struct {
+	ID int64 `gorm:"column:id;primaryKey"`
+	// ActorID is 0 for changes made by the system, such as payment webhooks
+	ActorID    int64  `gorm:"column:actor_id;index"`
+	ActorRole  string `gorm:"column:actor_role"`
+	Action     string `gorm:"column:action;not null;index"`
+	EntityType string `gorm:"column:entity_type;not null;index:idx_audit_entity"`
+	EntityID   string `gorm:"column:entity_id;not null;index:idx_audit_entity"`
+	// Changes is a JSON object mapping every changed field to its from and to values
+	Changes   string    `gorm:"column:changes;type:text"`
+	IPAddress string    `gorm:"column:ip_address"`
+	RequestID string    `gorm:"column:request_id;index"`
+	CreatedAt time.Time `gorm:"column:created_at;index"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
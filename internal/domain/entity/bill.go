@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type SplitMode string
+
+const (
+	SplitModeEven   SplitMode = "even"
+	SplitModeItem   SplitMode = "item"
+	SplitModeCustom SplitMode = "custom"
+)
+
+type ShareStatus string
+
+const (
+	ShareStatusUnpaid        ShareStatus = "unpaid"
+	ShareStatusPartiallyPaid ShareStatus = "partially_paid"
+	ShareStatusPaid          ShareStatus = "paid"
+)
+
+// BillSplit describes how the check of a single order is divided between guests.
+type BillSplit struct {
+	ID        int64        `gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID   int64        `gorm:"column:order_id;uniqueIndex"`
+	Mode      SplitMode    `gorm:"column:mode"`
+	Shares    []BillShare  `gorm:"foreignKey:BillSplitID"`
+	CreatedAt time.Time    `gorm:"column:created_at"`
+	UpdatedAt time.Time    `gorm:"column:updated_at"`
+	DeletedAt sql.NullTime `gorm:"column:deleted_at"`
+}
+
+// BillShare is the portion of a check owed by one guest. ItemIDs holds the
+// comma separated order item IDs covered by the share when splitting by item.
+type BillShare struct {
+	ID          int64        `gorm:"column:id;primaryKey;autoIncrement"`
+	BillSplitID int64        `gorm:"column:bill_split_id;index"`
+	OrderID     int64        `gorm:"column:order_id;index"`
+	Label       string       `gorm:"column:label"`
+	Amount      float64      `gorm:"column:amount"`
+	ItemIDs     string       `gorm:"column:item_ids"`
+	Status      ShareStatus  `gorm:"column:status"`
+	CreatedAt   time.Time    `gorm:"column:created_at"`
+	UpdatedAt   time.Time    `gorm:"column:updated_at"`
+	DeletedAt   sql.NullTime `gorm:"column:deleted_at"`
+}
+
+func (b *BillSplit) TableName() string {
+	return "bill_splits"
+}
+
+func (s *BillShare) TableName() string {
+	return "bill_shares"
+}
@@ -6,15 +6,20 @@ import (
 )
 
 type Customer struct {
-	ID        int64        `gorm:"column:id;primaryKey"`
-	Name      string       `gorm:"column:name"`
-	Email     string       `gorm:"column:email;unique"`
-	Password  string       `gorm:"column:password"`
-	Address   string       `gorm:"column:address"`
-	Role      string       `gorm:"column:role;default:customer"`
-	CreatedAt time.Time    `gorm:"column:created_at"`
-	UpdatedAt time.Time    `gorm:"column:updated_at"`
-	DeletedAt sql.NullTime `gorm:"column:deleted_at"`
+	ID       int64  `gorm:"column:id;primaryKey"`
+	Name     string `gorm:"column:name"`
+	Email    string `gorm:"column:email;unique"`
+	Password string `gorm:"column:password"`
+	Address  string `gorm:"column:address"`
+	Role     string `gorm:"column:role;default:customer"`
+	NoShows  int    `gorm:"column:no_show_count;not null;default:0"`
+	// TokenVersion is embedded in access tokens, bumping it revokes all of them
+	TokenVersion int `gorm:"column:token_version;not null;default:0"`
+	// EmailVerifiedAt is set once the customer confirms their email address
+	EmailVerifiedAt *time.Time   `gorm:"column:email_verified_at"`
+	CreatedAt       time.Time    `gorm:"column:created_at"`
+	UpdatedAt       time.Time    `gorm:"column:updated_at"`
+	DeletedAt       sql.NullTime `gorm:"column:deleted_at"`
 }
 
 func (c *Customer) TableName() string {
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DepositStatus string
+
+const (
+	DepositStatusPending   DepositStatus = "pending"
+	DepositStatusPaid      DepositStatus = "paid"
+	DepositStatusExpired   DepositStatus = "expired"
+	DepositStatusForfeited DepositStatus = "forfeited"
+	DepositStatusRefunded  DepositStatus = "refunded"
+	DepositStatusApplied   DepositStatus = "applied"
+)
+
+// DepositRule requires a deposit for parties of at least MinGuests, optionally
+// limited to a weekday and a time slot in the restaurant's local time
+type DepositRule struct {
+	ID             int64          `gorm:"column:id;primaryKey"`
+	Name           string         `gorm:"column:name;not null"`
+	MinGuests      int            `gorm:"column:min_guests;not null;default:1"`
+	Weekday        *int           `gorm:"column:weekday"`
+	StartTime      string         `gorm:"column:start_time"`
+	EndTime        string         `gorm:"column:end_time"`
+	FlatAmount     float64        `gorm:"column:flat_amount;not null;default:0"`
+	AmountPerGuest float64        `gorm:"column:amount_per_guest;not null;default:0"`
+	Active         bool           `gorm:"column:active;not null;default:true"`
+	CreatedAt      time.Time      `gorm:"column:created_at"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+type ReservationDeposit struct {
+	ID             int64         `gorm:"column:id;primaryKey"`
+	ReservationID  uint          `gorm:"column:reservation_id;not null;uniqueIndex"`
+	RuleID         int64         `gorm:"column:rule_id"`
+	Amount         float64       `gorm:"column:amount;not null"`
+	Status         DepositStatus `gorm:"column:status;not null;default:pending;index"`
+	ExternalID     string        `gorm:"column:external_id;index"`
+	PaymentToken   string        `gorm:"column:payment_token"`
+	PaymentURL     string        `gorm:"column:payment_url"`
+	ExpiresAt      time.Time     `gorm:"column:expires_at;index"`
+	PaidAt         *time.Time    `gorm:"column:paid_at"`
+	AppliedOrderID *int64        `gorm:"column:applied_order_id"`
+	CreatedAt      time.Time     `gorm:"column:created_at"`
+	UpdatedAt      time.Time     `gorm:"column:updated_at"`
+}
+
+func (r *DepositRule) TableName() string {
+	return "deposit_rules"
+}
+
+func (d *ReservationDeposit) TableName() string {
+	return "reservation_deposits"
+}
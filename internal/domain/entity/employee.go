@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// MinutesPerWeek is the length of the weekly shift schedule in minutes
+const MinutesPerWeek = 7 * 24 * 60
+
+// EmployeeProfile holds the HR details of an employee, InvitedAt is set for
+// employees created through an invite and OnboardedAt once they accepted it
+type EmployeeProfile struct {
+	CustomerID  int64      `gorm:"column:customer_id;primaryKey;autoIncrement:false"`
+	Phone       string     `gorm:"column:phone"`
+	HireDate    *time.Time `gorm:"column:hire_date;type:date"`
+	HourlyRate  float64    `gorm:"column:hourly_rate;not null;default:0"`
+	InvitedAt   *time.Time `gorm:"column:invited_at"`
+	OnboardedAt *time.Time `gorm:"column:onboarded_at"`
+	Customer    Customer   `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (p *EmployeeProfile) TableName() string {
+	return "employee_profiles"
+}
+
+// Shift is a recurring weekly shift of an employee working in a role. Times
+// are minutes since midnight in the restaurant's time zone, a shift ending
+// at or before its start runs past midnight into the next day.
+type Shift struct {
+	ID          int64        `gorm:"column:id;primaryKey"`
+	EmployeeID  int64        `gorm:"column:employee_id;not null;index"`
+	Employee    Customer     `gorm:"foreignKey:EmployeeID;constraint:OnDelete:CASCADE"`
+	Role        string       `gorm:"column:role;not null;index"`
+	Weekday     time.Weekday `gorm:"column:weekday;not null"`
+	StartMinute int          `gorm:"column:start_minute;not null"`
+	EndMinute   int          `gorm:"column:end_minute;not null"`
+	CreatedAt   time.Time    `gorm:"column:created_at"`
+	UpdatedAt   time.Time    `gorm:"column:updated_at"`
+}
+
+func (s *Shift) TableName() string {
+	return "shifts"
+}
+
+// Duration is how long the shift lasts in minutes
+func (s *Shift) Duration() int {
+	if s.EndMinute > s.StartMinute {
+		return s.EndMinute - s.StartMinute
+	}
+	return s.EndMinute + 24*60 - s.StartMinute
+}
+
+// WeekStart is the start of the shift in minutes since Sunday midnight
+func (s *Shift) WeekStart() int {
+	return int(s.Weekday)*24*60 + s.StartMinute
+}
+
+// Covers reports whether the shift is running at the given minute of the week
+func (s *Shift) Covers(weekMinute int) bool {
+	offset := ((weekMinute-s.WeekStart())%MinutesPerWeek + MinutesPerWeek) % MinutesPerWeek
+	return offset < s.Duration()
+}
+
+// Overlaps reports whether two shifts share any time in the week
+func (s *Shift) Overlaps(other *Shift) bool {
+	return s.Covers(other.WeekStart()) || other.Covers(s.WeekStart())
+}
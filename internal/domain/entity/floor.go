@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AreaType string
+
+const (
+	AreaTypeIndoor  AreaType = "indoor"
+	AreaTypeTerrace AreaType = "terrace"
+	AreaTypeVIP     AreaType = "vip"
+)
+
+// FloorArea is a part of the restaurant floor such as the main room or the terrace
+type FloorArea struct {
+	ID        int64          `gorm:"column:id;primaryKey"`
+	Name      string         `gorm:"column:name;not null;unique"`
+	Type      AreaType       `gorm:"column:type;not null;default:indoor"`
+	SortOrder int            `gorm:"column:sort_order;not null;default:0"`
+	Sections  []FloorSection `gorm:"foreignKey:AreaID"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+// FloorSection groups tables within an area, waitstaff are assigned per section
+type FloorSection struct {
+	ID        int64          `gorm:"column:id;primaryKey"`
+	AreaID    int64          `gorm:"column:area_id;not null;index"`
+	Name      string         `gorm:"column:name;not null"`
+	Staff     []SectionStaff `gorm:"foreignKey:SectionID"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+type SectionStaff struct {
+	ID         int64     `gorm:"column:id;primaryKey"`
+	SectionID  int64     `gorm:"column:section_id;not null;uniqueIndex:idx_section_staff"`
+	EmployeeID int64     `gorm:"column:employee_id;not null;uniqueIndex:idx_section_staff"`
+	Employee   Customer  `gorm:"foreignKey:EmployeeID"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// TableSession tracks a party seated at a table, it stays open until the table is cleared
+type TableSession struct {
+	ID            int64      `gorm:"column:id;primaryKey"`
+	TableID       int64      `gorm:"column:table_id;not null;index"`
+	ReservationID *uint      `gorm:"column:reservation_id"`
+	GuestCount    int        `gorm:"column:guest_count;not null"`
+	SeatedAt      time.Time  `gorm:"column:seated_at;not null"`
+	ClosedAt      *time.Time `gorm:"column:closed_at;index"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at"`
+}
+
+func (a *FloorArea) TableName() string {
+	return "floor_areas"
+}
+
+func (s *FloorSection) TableName() string {
+	return "floor_sections"
+}
+
+func (s *SectionStaff) TableName() string {
+	return "section_staff"
+}
+
+func (s *TableSession) TableName() string {
+	return "table_sessions"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// LoginEvent records a password login attempt. Successful logins from a
+// client the account never used before are flagged as suspicious.
+type LoginEvent struct {
+	ID         int64     `gorm:"column:id;primaryKey"`
+	CustomerID int64     `gorm:"column:customer_id;index"`
+	Email      string    `gorm:"column:email;not null"`
+	Success    bool      `gorm:"column:success;not null"`
+	Suspicious bool      `gorm:"column:suspicious;not null;default:false"`
+	Reason     string    `gorm:"column:reason"`
+	IPAddress  string    `gorm:"column:ip_address"`
+	UserAgent  string    `gorm:"column:user_agent"`
+	CreatedAt  time.Time `gorm:"column:created_at;index"`
+}
+
+func (e *LoginEvent) TableName() string {
+	return "login_events"
+}
+
+// AccountLockout counts failed logins in a row, each one past the free
+// attempts locks the account a little longer
+type AccountLockout struct {
+	CustomerID     int64      `gorm:"column:customer_id;primaryKey;autoIncrement:false"`
+	FailedAttempts int        `gorm:"column:failed_attempts;not null;default:0"`
+	LastFailedAt   *time.Time `gorm:"column:last_failed_at"`
+	LockedUntil    *time.Time `gorm:"column:locked_until"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+}
+
+func (l *AccountLockout) TableName() string {
+	return "account_lockouts"
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSending NotificationStatus = "sending"
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusFailed  NotificationStatus = "failed"
+)
+
+// NotificationLog is one message to a customer and its delivery history.
+// DedupeKey keeps the same event from being sent twice for one record.
+type NotificationLog struct {
+	ID            int64              `gorm:"column:id;primaryKey"`
+	CustomerID    int64              `gorm:"column:customer_id;index"`
+	Event         string             `gorm:"column:event;not null"`
+	Channel       string             `gorm:"column:channel;not null"`
+	Recipient     string             `gorm:"column:recipient;not null"`
+	Subject       string             `gorm:"column:subject"`
+	Body          string             `gorm:"column:body;type:text"`
+	DedupeKey     string             `gorm:"column:dedupe_key;uniqueIndex"`
+	Status        NotificationStatus `gorm:"column:status;not null;default:pending;index"`
+	Attempts      int                `gorm:"column:attempts;not null;default:0"`
+	LastError     string             `gorm:"column:last_error"`
+	NextAttemptAt time.Time          `gorm:"column:next_attempt_at;index"`
+	ClaimedAt     *time.Time         `gorm:"column:claimed_at"`
+	SentAt        *time.Time         `gorm:"column:sent_at"`
+	CreatedAt     time.Time          `gorm:"column:created_at"`
+	UpdatedAt     time.Time          `gorm:"column:updated_at"`
+}
+
+func (n *NotificationLog) TableName() string {
+	return "notification_logs"
+}
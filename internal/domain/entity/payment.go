@@ -12,6 +12,10 @@ type Payment struct {
 	ID           int64                   `gorm:"column:id;primaryKey"`
 	OrderID      int64                   `gorm:"column:order_id"`
 	Order        Order                   `gorm:"foreignKey:OrderID"`
+	ShareID      *int64                  `gorm:"column:share_id;index"`
+	Method       constants.PaymentMethod `gorm:"column:method;default:midtrans"`
+	ExternalID   string                  `gorm:"column:external_id;index"`
+	Reference    string                  `gorm:"column:reference"`
 	Amount       float64                 `gorm:"column:amount"`
 	Status       constants.PaymentStatus `gorm:"column:status"`
 	PaymentToken string                  `gorm:"column:payment_token"`
@@ -26,6 +30,11 @@ func (p *Payment) TableName() string {
 }
 
 func (p *Payment) BeforeCreate(tx *gorm.DB) error {
-	p.Status = constants.PaymentStatusPending
+	if p.Status == "" {
+		p.Status = constants.PaymentStatusPending
+	}
+	if p.Method == "" {
+		p.Method = constants.PaymentMethodMidtrans
+	}
 	return nil
 }
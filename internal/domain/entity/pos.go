@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// PosDevice is a registered POS terminal shared by staff. Only the SHA-256
+// hash of its device token is stored.
+type PosDevice struct {
+	ID           int64      `gorm:"column:id;primaryKey"`
+	Name         string     `gorm:"column:name;not null"`
+	TokenHash    string     `gorm:"column:token_hash;not null;uniqueIndex"`
+	RegisteredBy int64      `gorm:"column:registered_by"`
+	LastSeenAt   *time.Time `gorm:"column:last_seen_at"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at"`
+}
+
+func (d *PosDevice) TableName() string {
+	return "pos_devices"
+}
+
+// StaffPin is the bcrypt hashed PIN an employee uses to sign in on a POS
+// terminal, repeated failures lock it until LockedUntil
+type StaffPin struct {
+	CustomerID     int64      `gorm:"column:customer_id;primaryKey;autoIncrement:false"`
+	PinHash        string     `gorm:"column:pin_hash;not null"`
+	FailedAttempts int        `gorm:"column:failed_attempts;not null;default:0"`
+	LockedUntil    *time.Time `gorm:"column:locked_until"`
+	Customer       Customer   `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+}
+
+func (p *StaffPin) TableName() string {
+	return "staff_pins"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// RefreshToken is one login session, only the SHA-256 hash of the token is
+// stored. Refreshing revokes the token and issues its replacement.
+type RefreshToken struct {
+	ID           int64      `gorm:"column:id;primaryKey"`
+	CustomerID   int64      `gorm:"column:customer_id;not null;index"`
+	TokenHash    string     `gorm:"column:token_hash;not null;uniqueIndex"`
+	UserAgent    string     `gorm:"column:user_agent"`
+	IPAddress    string     `gorm:"column:ip_address"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at"`
+	ReplacedByID *int64     `gorm:"column:replaced_by_id"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at"`
+}
+
+func (r *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
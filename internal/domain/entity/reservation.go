@@ -11,8 +11,10 @@ type ReservationStatus string
 const (
 	ReservationStatusPending   ReservationStatus = "pending"
 	ReservationStatusConfirmed ReservationStatus = "confirmed"
+	ReservationStatusSeated    ReservationStatus = "seated"
 	ReservationStatusCancelled ReservationStatus = "cancelled"
 	ReservationStatusCompleted ReservationStatus = "completed"
+	ReservationStatusNoShow    ReservationStatus = "no_show"
 )
 
 type Reservation struct {
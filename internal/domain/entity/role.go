@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// Role is a named set of permissions. Built-in roles are seeded from the
+// default policy and can be edited but not deleted.
+type Role struct {
+	ID          int64            `gorm:"column:id;primaryKey"`
+	Name        string           `gorm:"column:name;not null;uniqueIndex"`
+	Description string           `gorm:"column:description"`
+	BuiltIn     bool             `gorm:"column:built_in;not null;default:false"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `gorm:"column:created_at"`
+	UpdatedAt   time.Time        `gorm:"column:updated_at"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+// PermissionNames returns the permissions granted to the role
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Permission)
+	}
+	return names
+}
+
+type RolePermission struct {
+	ID         int64  `gorm:"column:id;primaryKey"`
+	RoleID     int64  `gorm:"column:role_id;not null;uniqueIndex:idx_role_permission"`
+	Permission string `gorm:"column:permission;not null;uniqueIndex:idx_role_permission"`
+}
+
+func (p *RolePermission) TableName() string {
+	return "role_permissions"
+}
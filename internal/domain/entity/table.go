@@ -6,11 +6,37 @@ import (
 	"gorm.io/gorm"
 )
 
+type TableStatus string
+
+const (
+	TableStatusFree          TableStatus = "free"
+	TableStatusReserved      TableStatus = "reserved"
+	TableStatusSeated        TableStatus = "seated"
+	TableStatusNeedsCleaning TableStatus = "needs_cleaning"
+	TableStatusBlocked       TableStatus = "blocked"
+)
+
+type TableShape string
+
+const (
+	TableShapeRound     TableShape = "round"
+	TableShapeSquare    TableShape = "square"
+	TableShapeRectangle TableShape = "rectangle"
+)
+
 type Table struct {
 	ID           int64          `gorm:"column:id;primaryKey"`
 	TableNumber  int            `gorm:"not null;unique"`
 	Capacity     int            `gorm:"not null"`
 	IsAvailable  bool           `gorm:"not null;default:true"`
+	SectionID    *int64         `gorm:"column:section_id;index"`
+	Status       TableStatus    `gorm:"column:status;not null;default:free"`
+	Shape        TableShape     `gorm:"column:shape;not null;default:square"`
+	PosX         float64        `gorm:"column:pos_x;not null;default:0"`
+	PosY         float64        `gorm:"column:pos_y;not null;default:0"`
+	Width        float64        `gorm:"column:width;not null;default:1"`
+	Height       float64        `gorm:"column:height;not null;default:1"`
+	Rotation     int            `gorm:"column:rotation;not null;default:0"`
 	Reservations []Reservation  `gorm:"foreignKey:TableID;constraint:OnDelete:SET NULL"`
 	CreatedAt    time.Time      `gorm:"created_at"`
 	UpdatedAt    time.Time      `gorm:"updated_at"`
@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// TimeEntry is one stretch of work of an employee from clock-in to
+// clock-out. Punches come from a registered POS terminal, entries added or
+// corrected by a manager keep who changed them and why.
+type TimeEntry struct {
+	ID         int64      `gorm:"column:id;primaryKey"`
+	EmployeeID int64      `gorm:"column:employee_id;not null;index:idx_time_entries_employee_clock_in,priority:1;uniqueIndex:idx_time_entries_open,where:clock_out_at IS NULL"`
+	Employee   Customer   `gorm:"foreignKey:EmployeeID;constraint:OnDelete:CASCADE"`
+	ClockInAt  time.Time  `gorm:"column:clock_in_at;not null;index:idx_time_entries_employee_clock_in,priority:2"`
+	ClockOutAt *time.Time `gorm:"column:clock_out_at"`
+	// ClockInDeviceID and ClockOutDeviceID are the terminals punched on, nil
+	// for times entered by a manager
+	ClockInDeviceID  *int64      `gorm:"column:clock_in_device_id"`
+	ClockOutDeviceID *int64      `gorm:"column:clock_out_device_id"`
+	Breaks           []TimeBreak `gorm:"foreignKey:TimeEntryID;constraint:OnDelete:CASCADE"`
+	CorrectedBy      *int64      `gorm:"column:corrected_by"`
+	CorrectedAt      *time.Time  `gorm:"column:corrected_at"`
+	CorrectionReason string      `gorm:"column:correction_reason"`
+	CreatedAt        time.Time   `gorm:"column:created_at"`
+	UpdatedAt        time.Time   `gorm:"column:updated_at"`
+}
+
+func (e *TimeEntry) TableName() string {
+	return "time_entries"
+}
+
+// TimeBreak is an unpaid break inside a time entry, EndAt is nil while the
+// employee is still on break
+type TimeBreak struct {
+	ID          int64      `gorm:"column:id;primaryKey"`
+	TimeEntryID int64      `gorm:"column:time_entry_id;not null;index"`
+	StartAt     time.Time  `gorm:"column:start_at;not null"`
+	EndAt       *time.Time `gorm:"column:end_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+}
+
+func (b *TimeBreak) TableName() string {
+	return "time_breaks"
+}
+
+// OpenBreak returns the break the employee is on, nil if none
+func (e *TimeEntry) OpenBreak() *TimeBreak {
+	for i := range e.Breaks {
+		if e.Breaks[i].EndAt == nil {
+			return &e.Breaks[i]
+		}
+	}
+	return nil
+}
+
+// BreakTime is the time spent on breaks, open breaks count until now
+func (e *TimeEntry) BreakTime(now time.Time) time.Duration {
+	var total time.Duration
+	for _, b := range e.Breaks {
+		end := now
+		if b.EndAt != nil {
+			end = *b.EndAt
+		}
+		if end.After(b.StartAt) {
+			total += end.Sub(b.StartAt)
+		}
+	}
+	return total
+}
+
+// Worked is the paid time of the entry, an entry without clock-out counts
+// until now
+func (e *TimeEntry) Worked(now time.Time) time.Duration {
+	end := now
+	if e.ClockOutAt != nil {
+		end = *e.ClockOutAt
+	}
+	worked := end.Sub(e.ClockInAt) - e.BreakTime(end)
+	if worked < 0 {
+		return 0
+	}
+	return worked
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// TwoFactor holds a customer's TOTP secret. It is pending until the first
+// code is confirmed, LastUsedStep rejects codes that were already used.
+type TwoFactor struct {
+	CustomerID   int64      `gorm:"column:customer_id;primaryKey;autoIncrement:false"`
+	Secret       string     `gorm:"column:secret;not null"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	Customer     Customer   `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at"`
+}
+
+func (t *TwoFactor) TableName() string {
+	return "two_factors"
+}
+
+// Enabled reports whether enrollment was confirmed
+func (t *TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the phone
+// is lost. Only the SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID         int64      `gorm:"column:id;primaryKey"`
+	CustomerID int64      `gorm:"column:customer_id;not null;index"`
+	CodeHash   string     `gorm:"column:code_hash;not null"`
+	UsedAt     *time.Time `gorm:"column:used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
+
+func (c *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "cakestore/internal/constants"
+
+// Actor is the authenticated caller a usecase acts on behalf of
+type Actor struct {
+	CustomerID int64
+	Role       string
+	// IPAddress and RequestID identify the request in the audit log
+	IPAddress string
+	RequestID string
+	// DeviceID is the POS terminal of a PIN session, zero otherwise
+	DeviceID int64
+}
+
+// IsStaff reports whether the actor works for the restaurant rather than
+// being a customer, staff are not limited to their own records
+func (a Actor) IsStaff() bool {
+	return a.Role != "" && a.Role != constants.RoleCustomer
+}
+
+// Owns reports whether the actor may access a record owned by customerID
+func (a Actor) Owns(customerID int64) bool {
+	return a.IsStaff() || a.CustomerID == customerID
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"encoding/json"
+	"time"
+)
+
+type AuditLogQueryParams struct {
+	PaginationQuery
+	ActorID    int64     `query:"actor_id"`
+	ActorRole  string    `query:"actor_role"`
+	Action     string    `query:"action"`
+	EntityType string    `query:"entity_type"`
+	EntityID   string    `query:"entity_id"`
+	RequestID  string    `query:"request_id"`
+	From       time.Time `query:"from"`
+	To         time.Time `query:"to"`
+}
+
+// AuditChange is the value of a field before and after the change, From is
+// empty for created records and To for deleted ones
+type AuditChange struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+type AuditLogResponse struct {
+	ID         int64                  `json:"id"`
+	ActorID    int64                  `json:"actor_id"`
+	ActorRole  string                 `json:"actor_role"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Changes    map[string]AuditChange `json:"changes"`
+	IPAddress  string                 `json:"ip_address"`
+	RequestID  string                 `json:"request_id"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+func ToAuditLogResponse(log *entity.AuditLog) AuditLogResponse {
+	changes := map[string]AuditChange{}
+	if log.Changes != "" {
+		_ = json.Unmarshal([]byte(log.Changes), &changes)
+	}
+	return AuditLogResponse{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		ActorRole:  log.ActorRole,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		Changes:    changes,
+		IPAddress:  log.IPAddress,
+		RequestID:  log.RequestID,
+		CreatedAt:  log.CreatedAt,
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"time"
+)
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in"`
+
+	// TwoFactorRequired is set instead of the tokens when the login has to be
+	// completed with a TOTP code through /auth/2fa/verify using ChallengeToken.
+	// EnrollmentRequired means the role requires two-factor authentication
+	// and the account still has to enroll through /auth/2fa/enroll first.
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	EnrollmentRequired bool   `json:"enrollment_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	// RecoveryCodes are returned once, when enrollment is confirmed at login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	// RefreshToken is optional, when set the session it belongs to is revoked too
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SessionMeta describes the client a session was issued to
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// SessionsResponse lists the sessions that can still be refreshed and the
+// most recent login attempts
+type SessionsResponse struct {
+	Active  []SessionResponse    `json:"active"`
+	History []LoginEventResponse `json:"history"`
+}
+
+type SessionResponse struct {
+	ID        int64     `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LoginEventResponse struct {
+	Success    bool      `json:"success"`
+	Suspicious bool      `json:"suspicious"`
+	Reason     string    `json:"reason,omitempty"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func ToSessionResponse(token *entity.RefreshToken) SessionResponse {
+	return SessionResponse{
+		ID:        token.ID,
+		UserAgent: token.UserAgent,
+		IPAddress: token.IPAddress,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: token.ExpiresAt,
+	}
+}
+
+func ToLoginEventResponse(event *entity.LoginEvent) LoginEventResponse {
+	return LoginEventResponse{
+		Success:    event.Success,
+		Suspicious: event.Suspicious,
+		Reason:     event.Reason,
+		IPAddress:  event.IPAddress,
+		UserAgent:  event.UserAgent,
+		CreatedAt:  event.CreatedAt,
+	}
+}
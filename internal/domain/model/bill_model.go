@@ -0,0 +1,109 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SplitBillRequest struct {
+	Mode   string              `json:"mode" validate:"required,oneof=even item custom"`
+	Parts  int                 `json:"parts" validate:"omitempty,min=2,max=50"`
+	Shares []SplitShareRequest `json:"shares" validate:"omitempty,dive"`
+}
+
+type SplitShareRequest struct {
+	Label   string  `json:"label"`
+	Amount  float64 `json:"amount" validate:"omitempty,gt=0"`
+	ItemIDs []int64 `json:"item_ids"`
+}
+
+type TenderRequest struct {
+	ShareID *int64 `json:"share_id"`
+	Method  string `json:"method" validate:"required,oneof=cash card midtrans"`
+	// Amount to apply to the check, defaults to the outstanding balance of the share or check
+	Amount float64 `json:"amount" validate:"omitempty,gt=0"`
+	// Tendered is the cash handed over by the guest, used to compute change
+	Tendered  float64 `json:"tendered" validate:"omitempty,gt=0"`
+	Reference string  `json:"reference"`
+}
+
+type BillShareResponse struct {
+	ID        int64   `json:"id"`
+	Label     string  `json:"label"`
+	Amount    float64 `json:"amount"`
+	Paid      float64 `json:"paid"`
+	Pending   float64 `json:"pending"`
+	Remaining float64 `json:"remaining"`
+	ItemIDs   []int64 `json:"item_ids,omitempty"`
+	Status    string  `json:"status"`
+}
+
+type BillPaymentResponse struct {
+	ID         int64     `json:"id"`
+	ShareID    *int64    `json:"share_id,omitempty"`
+	Method     string    `json:"method"`
+	Amount     float64   `json:"amount"`
+	Status     string    `json:"status"`
+	Reference  string    `json:"reference,omitempty"`
+	PaymentURL string    `json:"payment_url,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type BillResponse struct {
+	OrderID     int64                 `json:"order_id"`
+	OrderStatus string                `json:"order_status"`
+	Mode        string                `json:"mode,omitempty"`
+	Total       float64               `json:"total"`
+	Paid        float64               `json:"paid"`
+	Pending     float64               `json:"pending"`
+	Remaining   float64               `json:"remaining"`
+	Settled     bool                  `json:"settled"`
+	Shares      []BillShareResponse   `json:"shares"`
+	Payments    []BillPaymentResponse `json:"payments"`
+}
+
+type TenderResponse struct {
+	Payment     BillPaymentResponse `json:"payment"`
+	Change      float64             `json:"change"`
+	RedirectURL string              `json:"redirect_url,omitempty"`
+	Bill        *BillResponse       `json:"bill"`
+}
+
+func ToBillPaymentResponse(payment *entity.Payment) BillPaymentResponse {
+	return BillPaymentResponse{
+		ID:         payment.ID,
+		ShareID:    payment.ShareID,
+		Method:     string(payment.Method),
+		Amount:     payment.Amount,
+		Status:     string(payment.Status),
+		Reference:  payment.Reference,
+		PaymentURL: payment.PaymentURL,
+		CreatedAt:  payment.CreatedAt,
+	}
+}
+
+// JoinItemIDs encodes order item IDs for storage on a bill share
+func JoinItemIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SplitItemIDs decodes the order item IDs stored on a bill share
+func SplitItemIDs(value string) []int64 {
+	if value == "" {
+		return nil
+	}
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -3,10 +3,11 @@ package model
 import "cakestore/internal/domain/entity"
 
 type CustomerResponse struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Address string `json:"address"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Address       string `json:"address"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 type EmployeeResponse struct {
@@ -41,10 +42,11 @@ type LoginResponse struct {
 
 func ToCustomerResponse(customer *entity.Customer) *CustomerResponse {
 	return &CustomerResponse{
-		ID:      customer.ID,
-		Name:    customer.Name,
-		Email:   customer.Email,
-		Address: customer.Address,
+		ID:            customer.ID,
+		Name:          customer.Name,
+		Email:         customer.Email,
+		Address:       customer.Address,
+		EmailVerified: customer.EmailVerifiedAt != nil,
 	}
 }
 
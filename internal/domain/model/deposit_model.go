@@ -0,0 +1,82 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"time"
+)
+
+type DepositRuleRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	MinGuests int    `json:"min_guests" validate:"required,min=1"`
+	// Weekday follows time.Weekday, 0 is Sunday, omit to apply every day
+	Weekday *int `json:"weekday" validate:"omitempty,min=0,max=6"`
+	// StartTime and EndTime bound the slot in HH:MM, omit both to apply all day
+	StartTime      string  `json:"start_time" validate:"required_with=EndTime,omitempty,datetime=15:04"`
+	EndTime        string  `json:"end_time" validate:"required_with=StartTime,omitempty,datetime=15:04"`
+	FlatAmount     float64 `json:"flat_amount" validate:"min=0"`
+	AmountPerGuest float64 `json:"amount_per_guest" validate:"min=0"`
+	Active         *bool   `json:"active"`
+}
+
+type DepositRuleResponse struct {
+	ID             int64   `json:"id"`
+	Name           string  `json:"name"`
+	MinGuests      int     `json:"min_guests"`
+	Weekday        *int    `json:"weekday"`
+	StartTime      string  `json:"start_time,omitempty"`
+	EndTime        string  `json:"end_time,omitempty"`
+	FlatAmount     float64 `json:"flat_amount"`
+	AmountPerGuest float64 `json:"amount_per_guest"`
+	Active         bool    `json:"active"`
+}
+
+type DepositResponse struct {
+	ID             int64      `json:"id"`
+	ReservationID  uint       `json:"reservation_id"`
+	Amount         float64    `json:"amount"`
+	Status         string     `json:"status"`
+	PaymentURL     string     `json:"payment_url,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	AppliedOrderID *int64     `json:"applied_order_id,omitempty"`
+}
+
+type NoShowRequest struct {
+	// RefundDeposit returns a paid deposit to the guest instead of keeping it
+	RefundDeposit bool `json:"refund_deposit"`
+}
+
+type ApplyDepositRequest struct {
+	OrderID int64 `json:"order_id" validate:"required"`
+}
+
+func ToDepositRuleResponse(rule *entity.DepositRule) *DepositRuleResponse {
+	return &DepositRuleResponse{
+		ID:             rule.ID,
+		Name:           rule.Name,
+		MinGuests:      rule.MinGuests,
+		Weekday:        rule.Weekday,
+		StartTime:      rule.StartTime,
+		EndTime:        rule.EndTime,
+		FlatAmount:     rule.FlatAmount,
+		AmountPerGuest: rule.AmountPerGuest,
+		Active:         rule.Active,
+	}
+}
+
+func ToDepositResponse(deposit *entity.ReservationDeposit) *DepositResponse {
+	response := &DepositResponse{
+		ID:             deposit.ID,
+		ReservationID:  deposit.ReservationID,
+		Amount:         deposit.Amount,
+		Status:         string(deposit.Status),
+		ExpiresAt:      deposit.ExpiresAt,
+		PaidAt:         deposit.PaidAt,
+		AppliedOrderID: deposit.AppliedOrderID,
+	}
+	// the payment link is only useful while the deposit is still open
+	if deposit.Status == entity.DepositStatusPending {
+		response.PaymentURL = deposit.PaymentURL
+	}
+	return response
+}
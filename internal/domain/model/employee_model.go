@@ -0,0 +1,137 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of calendar dates such as the hire date
+const DateLayout = "2006-01-02"
+
+// ClockLayout is the format of shift start and end times
+const ClockLayout = "15:04"
+
+type InviteEmployeeRequest struct {
+	Name       string  `json:"name" validate:"required"`
+	Email      string  `json:"email" validate:"required,email"`
+	Role       string  `json:"role" validate:"required"`
+	Phone      string  `json:"phone" validate:"omitempty,max=30"`
+	HireDate   string  `json:"hire_date" validate:"omitempty,datetime=2006-01-02"`
+	HourlyRate float64 `json:"hourly_rate" validate:"min=0"`
+}
+
+type UpdateEmployeeProfileRequest struct {
+	Phone      string  `json:"phone" validate:"omitempty,max=30"`
+	HireDate   string  `json:"hire_date" validate:"omitempty,datetime=2006-01-02"`
+	HourlyRate float64 `json:"hourly_rate" validate:"min=0"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type EmployeeProfileResponse struct {
+	EmployeeResponse
+	Phone      string  `json:"phone"`
+	HireDate   string  `json:"hire_date,omitempty"`
+	HourlyRate float64 `json:"hourly_rate"`
+	// Status is invited until the employee accepted the invite, active otherwise
+	Status      string     `json:"status"`
+	InvitedAt   *time.Time `json:"invited_at,omitempty"`
+	OnboardedAt *time.Time `json:"onboarded_at,omitempty"`
+}
+
+// ToEmployeeProfileResponse combines an employee with their profile, profile
+// may be nil for employees created before profiles existed
+func ToEmployeeProfileResponse(employee *entity.Customer, profile *entity.EmployeeProfile) *EmployeeProfileResponse {
+	response := &EmployeeProfileResponse{
+		EmployeeResponse: *ToEmployeeResponse(employee),
+		Status:           "active",
+	}
+	if profile == nil {
+		return response
+	}
+
+	response.Phone = profile.Phone
+	response.HourlyRate = profile.HourlyRate
+	response.InvitedAt = profile.InvitedAt
+	response.OnboardedAt = profile.OnboardedAt
+	if profile.HireDate != nil {
+		response.HireDate = profile.HireDate.Format(DateLayout)
+	}
+	if profile.InvitedAt != nil && profile.OnboardedAt == nil {
+		response.Status = "invited"
+	}
+	return response
+}
+
+type ShiftRequest struct {
+	EmployeeID int64 `json:"employee_id" validate:"required"`
+	// Role defaults to the employee's role
+	Role string `json:"role"`
+	// Weekday is 0 for Sunday through 6 for Saturday
+	Weekday   int    `json:"weekday" validate:"min=0,max=6"`
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time" validate:"required,datetime=15:04"`
+}
+
+type ShiftQueryParams struct {
+	EmployeeID int64
+	Role       string
+}
+
+type ShiftResponse struct {
+	ID           int64  `json:"id"`
+	EmployeeID   int64  `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Role         string `json:"role"`
+	Weekday      int    `json:"weekday"`
+	WeekdayName  string `json:"weekday_name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	// Overnight is set for shifts that end on the next day
+	Overnight bool `json:"overnight"`
+	Minutes   int  `json:"minutes"`
+}
+
+// OnShiftResponse is an employee working right now, StartsAt and EndsAt are
+// the bounds of the current occurrence of their shift
+type OnShiftResponse struct {
+	EmployeeID int64     `json:"employee_id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	ShiftID    int64     `json:"shift_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+func ToShiftResponse(shift *entity.Shift) ShiftResponse {
+	return ShiftResponse{
+		ID:           shift.ID,
+		EmployeeID:   shift.EmployeeID,
+		EmployeeName: shift.Employee.Name,
+		Role:         shift.Role,
+		Weekday:      int(shift.Weekday),
+		WeekdayName:  shift.Weekday.String(),
+		StartTime:    FormatClock(shift.StartMinute),
+		EndTime:      FormatClock(shift.EndMinute),
+		Overnight:    shift.EndMinute <= shift.StartMinute,
+		Minutes:      shift.Duration(),
+	}
+}
+
+// ParseClock turns an HH:MM time into minutes since midnight
+func ParseClock(value string) (int, error) {
+	parsed, err := time.Parse(ClockLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// FormatClock turns minutes since midnight into an HH:MM time
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
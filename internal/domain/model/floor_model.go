@@ -0,0 +1,164 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"time"
+)
+
+type AreaRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	Type      string `json:"type" validate:"required,oneof=indoor terrace vip"`
+	SortOrder int    `json:"sort_order" validate:"min=0"`
+}
+
+type SectionRequest struct {
+	AreaID int64  `json:"area_id" validate:"required"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type AssignSectionStaffRequest struct {
+	EmployeeIDs []int64 `json:"employee_ids" validate:"dive,gt=0"`
+}
+
+type UpdateTableLayoutRequest struct {
+	SectionID *int64  `json:"section_id"`
+	Shape     string  `json:"shape" validate:"required,oneof=round square rectangle"`
+	PosX      float64 `json:"pos_x" validate:"min=0"`
+	PosY      float64 `json:"pos_y" validate:"min=0"`
+	Width     float64 `json:"width" validate:"gt=0"`
+	Height    float64 `json:"height" validate:"gt=0"`
+	Rotation  int     `json:"rotation" validate:"min=0,max=359"`
+}
+
+type UpdateTableStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=free reserved seated needs_cleaning blocked"`
+	// GuestCount and ReservationID are only used when seating a party
+	GuestCount    int   `json:"guest_count" validate:"omitempty,min=1"`
+	ReservationID *uint `json:"reservation_id"`
+}
+
+type StaffSummary struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// OnShift is only filled in on the live floor view
+	OnShift bool `json:"on_shift"`
+}
+
+type SectionResponse struct {
+	ID     int64          `json:"id"`
+	AreaID int64          `json:"area_id"`
+	Name   string         `json:"name"`
+	Staff  []StaffSummary `json:"staff"`
+}
+
+type AreaResponse struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	SortOrder int               `json:"sort_order"`
+	Sections  []SectionResponse `json:"sections"`
+}
+
+type FloorSessionResponse struct {
+	ID            int64     `json:"id"`
+	GuestCount    int       `json:"guest_count"`
+	ReservationID *uint     `json:"reservation_id,omitempty"`
+	SeatedAt      time.Time `json:"seated_at"`
+	SeatedMinutes int       `json:"seated_minutes"`
+}
+
+type FloorReservationResponse struct {
+	ID           uint      `json:"id"`
+	CustomerName string    `json:"customer_name"`
+	GuestCount   int       `json:"guest_count"`
+	ReserveDate  time.Time `json:"reserve_date"`
+	Status       string    `json:"status"`
+	Late         bool      `json:"late"`
+}
+
+type FloorTableResponse struct {
+	ID              int64                     `json:"id"`
+	TableNumber     int                       `json:"table_number"`
+	Capacity        int                       `json:"capacity"`
+	Status          string                    `json:"status"`
+	Shape           string                    `json:"shape"`
+	PosX            float64                   `json:"pos_x"`
+	PosY            float64                   `json:"pos_y"`
+	Width           float64                   `json:"width"`
+	Height          float64                   `json:"height"`
+	Rotation        int                       `json:"rotation"`
+	Session         *FloorSessionResponse     `json:"session,omitempty"`
+	NextReservation *FloorReservationResponse `json:"next_reservation,omitempty"`
+}
+
+type FloorSectionResponse struct {
+	ID     int64                `json:"id"`
+	Name   string               `json:"name"`
+	Staff  []StaffSummary       `json:"staff"`
+	Tables []FloorTableResponse `json:"tables"`
+}
+
+type FloorAreaResponse struct {
+	ID       int64                  `json:"id"`
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Sections []FloorSectionResponse `json:"sections"`
+}
+
+type FloorResponse struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Summary     map[string]int       `json:"summary"`
+	Areas       []FloorAreaResponse  `json:"areas"`
+	Unassigned  []FloorTableResponse `json:"unassigned"`
+}
+
+func ToStaffSummaries(staff []entity.SectionStaff) []StaffSummary {
+	summaries := make([]StaffSummary, 0, len(staff))
+	for _, member := range staff {
+		summaries = append(summaries, StaffSummary{
+			ID:   member.EmployeeID,
+			Name: member.Employee.Name,
+			Role: member.Employee.Role,
+		})
+	}
+	return summaries
+}
+
+func ToSectionResponse(section *entity.FloorSection) SectionResponse {
+	return SectionResponse{
+		ID:     section.ID,
+		AreaID: section.AreaID,
+		Name:   section.Name,
+		Staff:  ToStaffSummaries(section.Staff),
+	}
+}
+
+func ToAreaResponse(area *entity.FloorArea) *AreaResponse {
+	sections := make([]SectionResponse, 0, len(area.Sections))
+	for i := range area.Sections {
+		sections = append(sections, ToSectionResponse(&area.Sections[i]))
+	}
+	return &AreaResponse{
+		ID:        area.ID,
+		Name:      area.Name,
+		Type:      string(area.Type),
+		SortOrder: area.SortOrder,
+		Sections:  sections,
+	}
+}
+
+func ToFloorTableResponse(table *entity.Table) FloorTableResponse {
+	return FloorTableResponse{
+		ID:          table.ID,
+		TableNumber: table.TableNumber,
+		Capacity:    table.Capacity,
+		Status:      string(table.Status),
+		Shape:       string(table.Shape),
+		PosX:        table.PosX,
+		PosY:        table.PosY,
+		Width:       table.Width,
+		Height:      table.Height,
+		Rotation:    table.Rotation,
+	}
+}
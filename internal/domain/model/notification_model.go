@@ -0,0 +1,45 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"time"
+)
+
+type NotificationQueryParams struct {
+	PaginationQuery
+	CustomerID int64  `query:"customer_id"`
+	Status     string `query:"status"`
+	Event      string `query:"event"`
+}
+
+type NotificationResponse struct {
+	ID            int64      `json:"id"`
+	CustomerID    int64      `json:"customer_id"`
+	Event         string     `json:"event"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func ToNotificationResponse(notification *entity.NotificationLog) NotificationResponse {
+	return NotificationResponse{
+		ID:            notification.ID,
+		CustomerID:    notification.CustomerID,
+		Event:         notification.Event,
+		Channel:       notification.Channel,
+		Recipient:     notification.Recipient,
+		Subject:       notification.Subject,
+		Status:        string(notification.Status),
+		Attempts:      notification.Attempts,
+		LastError:     notification.LastError,
+		NextAttemptAt: notification.NextAttemptAt,
+		SentAt:        notification.SentAt,
+		CreatedAt:     notification.CreatedAt,
+	}
+}
@@ -94,3 +94,17 @@ func ToOrderEntity(order *OrderResponse) *entity.Order {
 		UpdatedAt:  time.Now(),
 	}
 }
+
+// Formats orders can be exported in
+const (
+	OrderExportCSV  = "csv"
+	OrderExportJSON = "json"
+)
+
+// OrderExportQuery selects the orders created from From up to, but not
+// including, To
+type OrderExportQuery struct {
+	From   time.Time
+	To     time.Time
+	Format string
+}
@@ -21,6 +21,27 @@ type CreatePaymentRequest struct {
 	TransactionDetails midtrans.TransactionDetails `json:"transaction_details" validate:"required"`
 	ItemDetails        []midtrans.ItemDetails      `json:"item_details"`
 	CustomerDetails    midtrans.CustomerDetails    `json:"customer_details"`
+	Expiry             *SnapExpiry                 `json:"expiry,omitempty"`
+}
+
+// SnapExpiryTimeFormat is the start_time layout expected by Snap
+const SnapExpiryTimeFormat = "2006-01-02 15:04:05 -0700"
+
+type SnapExpiry struct {
+	StartTime string `json:"start_time"`
+	Unit      string `json:"unit"`
+	Duration  int64  `json:"duration"`
+}
+
+type RefundRequest struct {
+	RefundKey string `json:"refund_key"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+}
+
+type RefundResponse struct {
+	StatusCode    string `json:"status_code"`
+	StatusMessage string `json:"status_message"`
 }
 
 type PaymentResponse struct {
@@ -86,3 +107,12 @@ func ToPaymentEntity(paymentModel *PaymentModel) *entity.Payment {
 		UpdatedAt:    time.Now(),
 	}
 }
+
+// PaymentReconcileResult counts the pending payments a reconciliation
+// checked with Midtrans by outcome
+type PaymentReconcileResult struct {
+	Checked   int `json:"checked"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"time"
+)
+
+type RegisterDeviceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type DeviceResponse struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	RegisteredBy int64      `json:"registered_by"`
+	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RegisterDeviceResponse carries the device token, it is only shown once and
+// must be configured on the terminal
+type RegisterDeviceResponse struct {
+	DeviceResponse
+	DeviceToken string `json:"device_token"`
+}
+
+type SetPinRequest struct {
+	Pin string `json:"pin" validate:"required,numeric,min=4,max=6"`
+	// Password is the employee's current password
+	Password string `json:"password" validate:"required"`
+}
+
+type PinLoginRequest struct {
+	EmployeeID int64  `json:"employee_id" validate:"required"`
+	Pin        string `json:"pin" validate:"required,numeric,min=4,max=6"`
+}
+
+// PosStaffResponse is an employee that can sign in on a terminal with a PIN
+type PosStaffResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func ToDeviceResponse(device *entity.PosDevice) DeviceResponse {
+	return DeviceResponse{
+		ID:           device.ID,
+		Name:         device.Name,
+		RegisteredBy: device.RegisteredBy,
+		LastSeenAt:   device.LastSeenAt,
+		RevokedAt:    device.RevokedAt,
+		CreatedAt:    device.CreatedAt,
+	}
+}
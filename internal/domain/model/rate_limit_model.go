@@ -0,0 +1,30 @@
+package model
+
+// RateLimitClientQuery picks the client whose counters are inspected or
+// reset, a customer for the limits of authenticated routes or an IP address
+// for anonymous ones
+type RateLimitClientQuery struct {
+	CustomerID int64  `query:"customer_id"`
+	IP         string `query:"ip"`
+	// Group limits a reset to one group, every group is reset when empty
+	Group string `query:"group"`
+}
+
+type RateLimitStatusResponse struct {
+	Client string `json:"client"`
+	// Role is the role of the customer whose limits apply, empty for IP
+	// addresses
+	Role   string                 `json:"role,omitempty"`
+	Limits []RateLimitGroupStatus `json:"limits"`
+}
+
+type RateLimitGroupStatus struct {
+	Group         string `json:"group"`
+	Algorithm     string `json:"algorithm"`
+	Limit         int    `json:"limit"`
+	WindowSeconds int64  `json:"window_seconds"`
+	Remaining     int    `json:"remaining"`
+	ResetSeconds  int64  `json:"reset_seconds"`
+	// Limited is true while the next request of the client would be rejected
+	Limited bool `json:"limited"`
+}
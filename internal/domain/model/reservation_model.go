@@ -1,6 +1,7 @@
 package model
 
 import (
+	"cakestore/internal/domain/entity"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -22,13 +23,20 @@ type CreateReservationRequest struct {
 }
 
 type UpdateReservationRequest struct {
-	Status       string    `json:"status" validate:"omitempty,oneof=pending confirmed cancelled completed"`
+	Status       string    `json:"status" validate:"omitempty,oneof=pending confirmed seated completed cancelled no_show"`
 	TableNumber  int       `json:"table_number"`
 	GuestCount   int       `json:"guest_count" validate:"omitempty,min=1"`
 	ReserveDate  time.Time `json:"reserve_date" validate:"omitempty,future"`
 	SpecialNotes string    `json:"special_notes"`
 }
 
+// ModifyReservationRequest is what customers may change on their own booking
+type ModifyReservationRequest struct {
+	GuestCount   int       `json:"guest_count" validate:"omitempty,min=1"`
+	ReserveDate  time.Time `json:"reserve_date"`
+	SpecialNotes *string   `json:"special_notes"`
+}
+
 type ReservationResponse struct {
 	ID           uint             `json:"id"`
 	CustomerID   uint             `json:"customer_id"`
@@ -38,6 +46,7 @@ type ReservationResponse struct {
 	ReserveDate  time.Time        `json:"reserve_date"`
 	Status       string           `json:"status"`
 	SpecialNotes string           `json:"special_notes"`
+	Deposit      *DepositResponse `json:"deposit,omitempty"`
 	CreatedAt    time.Time        `json:"created_at"`
 	UpdatedAt    time.Time        `json:"updated_at"`
 }
@@ -56,3 +65,18 @@ func (r *CreateReservationRequest) Validate() error {
 
 	return validate.Struct(r)
 }
+
+func ToReservationResponse(reservation *entity.Reservation) *ReservationResponse {
+	return &ReservationResponse{
+		ID:           reservation.ID,
+		CustomerID:   reservation.CustomerID,
+		Customer:     *ToCustomerResponse(&reservation.Customer),
+		TableNumber:  reservation.TableNumber,
+		GuestCount:   reservation.GuestCount,
+		ReserveDate:  reservation.ReserveDate,
+		Status:       string(reservation.Status),
+		SpecialNotes: reservation.SpecialNotes,
+		CreatedAt:    reservation.CreatedAt,
+		UpdatedAt:    reservation.UpdatedAt,
+	}
+}
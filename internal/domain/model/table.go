@@ -21,6 +21,8 @@ type TableResponse struct {
 	TableNumber int       `json:"table_number"`
 	Capacity    int       `json:"capacity"`
 	IsAvailable bool      `json:"is_available"`
+	SectionID   *int64    `json:"section_id"`
+	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -38,6 +40,8 @@ func ToTableResponse(table *entity.Table) *TableResponse {
 		TableNumber: table.TableNumber,
 		Capacity:    table.Capacity,
 		IsAvailable: table.IsAvailable,
+		SectionID:   table.SectionID,
+		Status:      string(table.Status),
 		CreatedAt:   table.CreatedAt,
 		UpdatedAt:   table.UpdatedAt,
 	}
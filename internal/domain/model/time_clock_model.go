@@ -0,0 +1,148 @@
+package model
+
+import (
+	"cakestore/internal/domain/entity"
+	"math"
+	"time"
+)
+
+// Timesheet exception types found by reconciling time entries against the
+// shift schedule
+const (
+	TimesheetMissedShift = "missed_shift"
+	TimesheetUnscheduled = "unscheduled"
+	TimesheetLate        = "late"
+	TimesheetLeftEarly   = "left_early"
+	TimesheetOpenEntry   = "open_entry"
+)
+
+type TimeBreakRequest struct {
+	StartAt time.Time  `json:"start_at" validate:"required"`
+	EndAt   *time.Time `json:"end_at" validate:"required"`
+}
+
+// CorrectTimeEntryRequest replaces the times of an entry, the reason is kept
+// on the entry and in the audit log
+type CorrectTimeEntryRequest struct {
+	ClockInAt  time.Time          `json:"clock_in_at" validate:"required"`
+	ClockOutAt *time.Time         `json:"clock_out_at"`
+	Breaks     []TimeBreakRequest `json:"breaks" validate:"dive"`
+	Reason     string             `json:"reason" validate:"required,max=255"`
+}
+
+// CreateTimeEntryRequest adds an entry an employee forgot to punch
+type CreateTimeEntryRequest struct {
+	EmployeeID int64 `json:"employee_id" validate:"required"`
+	CorrectTimeEntryRequest
+}
+
+type TimeEntryQueryParams struct {
+	EmployeeID int64
+	From       time.Time
+	To         time.Time
+}
+
+// TimesheetQueryParams selects the pay period containing Date, the current
+// one when Date is zero
+type TimesheetQueryParams struct {
+	EmployeeID int64
+	Date       time.Time
+}
+
+type TimeBreakResponse struct {
+	ID      int64      `json:"id"`
+	StartAt time.Time  `json:"start_at"`
+	EndAt   *time.Time `json:"end_at,omitempty"`
+}
+
+type TimeEntryResponse struct {
+	ID               int64               `json:"id"`
+	EmployeeID       int64               `json:"employee_id"`
+	EmployeeName     string              `json:"employee_name"`
+	ClockInAt        time.Time           `json:"clock_in_at"`
+	ClockOutAt       *time.Time          `json:"clock_out_at,omitempty"`
+	ClockInDeviceID  *int64              `json:"clock_in_device_id,omitempty"`
+	ClockOutDeviceID *int64              `json:"clock_out_device_id,omitempty"`
+	Breaks           []TimeBreakResponse `json:"breaks"`
+	OnBreak          bool                `json:"on_break"`
+	BreakMinutes     int                 `json:"break_minutes"`
+	WorkedMinutes    int                 `json:"worked_minutes"`
+	CorrectedBy      *int64              `json:"corrected_by,omitempty"`
+	CorrectedAt      *time.Time          `json:"corrected_at,omitempty"`
+	CorrectionReason string              `json:"correction_reason,omitempty"`
+}
+
+// TimesheetWeek splits the hours of a pay period into weeks, overtime is
+// counted per week
+type TimesheetWeek struct {
+	StartsOn      string  `json:"starts_on"`
+	WorkedHours   float64 `json:"worked_hours"`
+	RegularHours  float64 `json:"regular_hours"`
+	OvertimeHours float64 `json:"overtime_hours"`
+}
+
+// TimesheetException is a difference between the schedule and the punches
+type TimesheetException struct {
+	Type    string    `json:"type"`
+	At      time.Time `json:"at"`
+	ShiftID int64     `json:"shift_id,omitempty"`
+	EntryID int64     `json:"entry_id,omitempty"`
+	Minutes int       `json:"minutes,omitempty"`
+}
+
+type EmployeeTimesheet struct {
+	EmployeeID     int64                `json:"employee_id"`
+	Name           string               `json:"name"`
+	Role           string               `json:"role"`
+	HourlyRate     float64              `json:"hourly_rate"`
+	ScheduledHours float64              `json:"scheduled_hours"`
+	WorkedHours    float64              `json:"worked_hours"`
+	RegularHours   float64              `json:"regular_hours"`
+	OvertimeHours  float64              `json:"overtime_hours"`
+	BreakHours     float64              `json:"break_hours"`
+	RegularPay     float64              `json:"regular_pay"`
+	OvertimePay    float64              `json:"overtime_pay"`
+	GrossPay       float64              `json:"gross_pay"`
+	Weeks          []TimesheetWeek      `json:"weeks"`
+	Exceptions     []TimesheetException `json:"exceptions"`
+}
+
+// TimesheetResponse covers one pay period, PeriodEnd is exclusive
+type TimesheetResponse struct {
+	PeriodStart time.Time           `json:"period_start"`
+	PeriodEnd   time.Time           `json:"period_end"`
+	Employees   []EmployeeTimesheet `json:"employees"`
+}
+
+func ToTimeEntryResponse(entry *entity.TimeEntry, now time.Time) TimeEntryResponse {
+	breaks := make([]TimeBreakResponse, 0, len(entry.Breaks))
+	for _, b := range entry.Breaks {
+		breaks = append(breaks, TimeBreakResponse{ID: b.ID, StartAt: b.StartAt, EndAt: b.EndAt})
+	}
+
+	end := now
+	if entry.ClockOutAt != nil {
+		end = *entry.ClockOutAt
+	}
+	return TimeEntryResponse{
+		ID:               entry.ID,
+		EmployeeID:       entry.EmployeeID,
+		EmployeeName:     entry.Employee.Name,
+		ClockInAt:        entry.ClockInAt,
+		ClockOutAt:       entry.ClockOutAt,
+		ClockInDeviceID:  entry.ClockInDeviceID,
+		ClockOutDeviceID: entry.ClockOutDeviceID,
+		Breaks:           breaks,
+		OnBreak:          entry.OpenBreak() != nil,
+		BreakMinutes:     int(entry.BreakTime(end) / time.Minute),
+		WorkedMinutes:    int(entry.Worked(now) / time.Minute),
+		CorrectedBy:      entry.CorrectedBy,
+		CorrectedAt:      entry.CorrectedAt,
+		CorrectionReason: entry.CorrectionReason,
+	}
+}
+
+// Hours converts a duration to hours rounded to two decimals
+func Hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,40 @@
+package model
+
+type TwoFactorStatusResponse struct {
+	Enabled bool `json:"enabled"`
+	// Required is set when the customer's role enforces two-factor authentication
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollResponse carries the new secret, ProvisioningURI is the
+// otpauth:// URI to render as a QR code for authenticator apps
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	// Code is a TOTP code or an unused recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse is only shown once, the codes are stored hashed
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code is a TOTP code or an unused recovery code
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// PoolStats is the part of the database pool statistics worth watching
+type PoolStats struct {
+	MaxOpen   int   `json:"max_open"`
+	Open      int   `json:"open"`
+	InUse     int   `json:"in_use"`
+	Idle      int   `json:"idle"`
+	WaitCount int64 `json:"wait_count"`
+	// WaitDuration is the total time spent waiting for a connection
+	WaitDuration string `json:"wait_duration"`
+}
+
+// DatabaseCheck pings the database and reports its pool statistics
+func DatabaseCheck(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run:      db.PingContext,
+		Details: func() any {
+			stats := db.Stats()
+			return PoolStats{
+				MaxOpen:      stats.MaxOpenConnections,
+				Open:         stats.OpenConnections,
+				InUse:        stats.InUse,
+				Idle:         stats.Idle,
+				WaitCount:    stats.WaitCount,
+				WaitDuration: stats.WaitDuration.String(),
+			}
+		},
+	}
+}
+
+// RedisCheck pings Redis. The cache and the rate limiter keep working
+// without it, so it isn't critical.
+func RedisCheck(client *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Run: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// HTTPCheck reports whether url answers. Any response below 500 counts, the
+// check is about reaching the service rather than being authorised by it.
+func HTTPCheck(name, url string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("responded with status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
+
+// RegisterPoolMetrics exposes the database pool statistics as gauges
+func RegisterPoolMetrics(registerer prometheus.Registerer, db *sql.DB) error {
+	gauges := []struct {
+		name, help string
+		value      func(sql.DBStats) float64
+	}{
+		{"open_connections", "Connections open to the database, in use or idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+		{"in_use_connections", "Connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+		{"idle_connections", "Idle connections kept in the pool.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+		{"wait_count", "Total number of times a query waited for a free connection.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+	}
+	for _, gauge := range gauges {
+		value := gauge.value
+		err := registerer.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "cakestore",
+			Subsystem: "db_pool",
+			Name:      gauge.name,
+			Help:      gauge.help,
+		}, func() float64 {
+			return value(db.Stats())
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to register %s gauge: %w", gauge.name, err)
+		}
+	}
+	return nil
+}
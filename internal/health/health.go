@@ -1,11 +1,26 @@
+// Package health runs the dependency checks behind the readiness probe.
+// Checks are registered with a timeout each and marked critical when the
+// server can't serve requests without the dependency, results are cached
+// briefly so frequent probes don't add load to the dependencies.
 package health
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"time"
+)
 
-	"gorm.io/gorm"
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+	StatusDraining  = "draining"
 )
 
+// defaultTimeout bounds checks registered without a timeout
+const defaultTimeout = 2 * time.Second
+
 type HealthStatus struct {
 	Status    string            `json:"status"`
 	Timestamp time.Time         `json:"timestamp"`
@@ -14,57 +29,138 @@ type HealthStatus struct {
 
 type Status struct {
 	Status    string        `json:"status"`
+	Critical  bool          `json:"critical"`
 	Latency   time.Duration `json:"latency"`
 	Error     string        `json:"error,omitempty"`
+	Details   any           `json:"details,omitempty"`
 	Timestamp time.Time     `json:"timestamp"`
 }
 
-type HealthChecker struct {
-	db *gorm.DB
+// Check is a dependency probed for readiness
+type Check struct {
+	Name string
+	// Critical checks failing make the server unhealthy, the others only
+	// degraded since requests can still be served without them
+	Critical bool
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+	// Details adds information such as pool statistics to the result
+	Details func() any
+}
+
+// Registry runs the registered checks and caches their results
+type Registry struct {
+	checks   []Check
+	cacheTTL time.Duration
+
+	mu      sync.Mutex
+	results map[string]Status
+}
+
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL, results: make(map[string]Status)}
 }
 
-func NewHealthChecker(db *gorm.DB) *HealthChecker {
-	return &HealthChecker{
-		db: db,
+// Register adds a check, it must be called before Check
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultTimeout
 	}
+	r.checks = append(r.checks, check)
 }
 
-func (h *HealthChecker) Check() HealthStatus {
+// Check runs the checks whose cached result expired, all at once so a slow
+// dependency only delays the result by its own timeout
+func (r *Registry) Check(ctx context.Context) HealthStatus {
 	health := HealthStatus{
-		Status:    "healthy",
+		Status:    StatusHealthy,
 		Timestamp: time.Now(),
-		Services:  make(map[string]Status),
+		Services:  make(map[string]Status, len(r.checks)),
 	}
 
-	// Check database
-	dbStart := time.Now()
-	sqlDB, err := h.db.DB()
-	if err != nil {
-		health.Services["database"] = Status{
-			Status:    "unhealthy",
-			Latency:   time.Since(dbStart),
-			Error:     err.Error(),
-			Timestamp: time.Now(),
-		}
-		health.Status = "unhealthy"
-	} else {
-		err = sqlDB.Ping()
-		if err != nil {
-			health.Services["database"] = Status{
-				Status:    "unhealthy",
-				Latency:   time.Since(dbStart),
-				Error:     err.Error(),
-				Timestamp: time.Now(),
-			}
-			health.Status = "unhealthy"
-		} else {
-			health.Services["database"] = Status{
-				Status:    "healthy",
-				Latency:   time.Since(dbStart),
-				Timestamp: time.Now(),
-			}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range r.checks {
+		if status, ok := r.cached(check.Name); ok {
+			health.Services[check.Name] = status
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := run(ctx, check)
+			r.store(check.Name, status)
+
+			mu.Lock()
+			health.Services[check.Name] = status
+			mu.Unlock()
+		}()
 	}
+	wg.Wait()
 
+	for _, status := range health.Services {
+		if status.Status == StatusHealthy {
+			continue
+		}
+		if status.Critical {
+			health.Status = StatusUnhealthy
+		} else if health.Status == StatusHealthy {
+			health.Status = StatusDegraded
+		}
+	}
 	return health
 }
+
+func (r *Registry) cached(name string) (Status, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status, ok := r.results[name]
+	if !ok || time.Since(status.Timestamp) >= r.cacheTTL {
+		return Status{}, false
+	}
+	return status, true
+}
+
+func (r *Registry) store(name string, status Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[name] = status
+}
+
+func run(ctx context.Context, check Check) Status {
+	// Results are shared with other probes, so only the check's own timeout
+	// may cut it short
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	// Checks that ignore ctx still can't hold up the probe
+	go func() {
+		errCh <- check.Run(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := Status{
+		Status:    StatusHealthy,
+		Critical:  check.Critical,
+		Latency:   time.Since(start),
+		Timestamp: time.Now(),
+	}
+	if err != nil {
+		status.Status = StatusUnhealthy
+		status.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			status.Error = "timed out after " + check.Timeout.String()
+		}
+	}
+	if check.Details != nil {
+		status.Details = check.Details()
+	}
+	return status
+}
@@ -0,0 +1,145 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func passing(context.Context) error { return nil }
+
+func TestRegistry_Check(t *testing.T) {
+	t.Run("healthy when every check passes", func(t *testing.T) {
+		registry := NewRegistry(0)
+		registry.Register(Check{Name: "database", Critical: true, Run: passing})
+		registry.Register(Check{Name: "redis", Run: passing})
+
+		status := registry.Check(context.Background())
+
+		assert.Equal(t, StatusHealthy, status.Status)
+		assert.Equal(t, StatusHealthy, status.Services["database"].Status)
+		assert.True(t, status.Services["database"].Critical)
+		assert.Equal(t, StatusHealthy, status.Services["redis"].Status)
+	})
+
+	t.Run("degraded when a non-critical check fails", func(t *testing.T) {
+		registry := NewRegistry(0)
+		registry.Register(Check{Name: "database", Critical: true, Run: passing})
+		registry.Register(Check{Name: "redis", Run: func(context.Context) error { return errors.New("connection refused") }})
+
+		status := registry.Check(context.Background())
+
+		assert.Equal(t, StatusDegraded, status.Status)
+		assert.Equal(t, StatusUnhealthy, status.Services["redis"].Status)
+		assert.Equal(t, "connection refused", status.Services["redis"].Error)
+	})
+
+	t.Run("unhealthy when a critical check fails", func(t *testing.T) {
+		registry := NewRegistry(0)
+		registry.Register(Check{Name: "database", Critical: true, Run: func(context.Context) error { return errors.New("too many connections") }})
+		registry.Register(Check{Name: "redis", Run: func(context.Context) error { return errors.New("connection refused") }})
+
+		status := registry.Check(context.Background())
+
+		assert.Equal(t, StatusUnhealthy, status.Status)
+	})
+
+	t.Run("bounds each check by its timeout", func(t *testing.T) {
+		registry := NewRegistry(0)
+		registry.Register(Check{Name: "midtrans", Timeout: 20 * time.Millisecond, Run: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}})
+		registry.Register(Check{Name: "database", Critical: true, Timeout: time.Second, Run: passing})
+
+		start := time.Now()
+		status := registry.Check(context.Background())
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, StatusDegraded, status.Status)
+		assert.Contains(t, status.Services["midtrans"].Error, "timed out")
+		assert.Equal(t, StatusHealthy, status.Services["database"].Status)
+	})
+
+	t.Run("reuses results until they expire", func(t *testing.T) {
+		registry := NewRegistry(50 * time.Millisecond)
+		var calls atomic.Int32
+		registry.Register(Check{Name: "database", Critical: true, Run: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}})
+
+		registry.Check(context.Background())
+		registry.Check(context.Background())
+		assert.Equal(t, int32(1), calls.Load())
+
+		time.Sleep(60 * time.Millisecond)
+		registry.Check(context.Background())
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("includes check details", func(t *testing.T) {
+		registry := NewRegistry(0)
+		registry.Register(Check{Name: "database", Critical: true, Run: passing, Details: func() any {
+			return PoolStats{Open: 3, InUse: 1, Idle: 2}
+		}})
+
+		status := registry.Check(context.Background())
+
+		assert.Equal(t, PoolStats{Open: 3, InUse: 1, Idle: 2}, status.Services["database"].Details)
+	})
+}
+
+func TestHTTPCheck(t *testing.T) {
+	t.Run("passes on client errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		assert.NoError(t, HTTPCheck("midtrans", server.URL).Run(context.Background()))
+	})
+
+	t.Run("fails on server errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		assert.ErrorContains(t, HTTPCheck("midtrans", server.URL).Run(context.Background()), "502")
+	})
+}
+
+func TestRegisterPoolMetrics(t *testing.T) {
+	// The pool only connects on first use, its statistics are all zero
+	db, err := sql.Open("pgx", "postgres://localhost:1/cakestore")
+	assert.NoError(t, err)
+	defer db.Close()
+	registry := prometheus.NewRegistry()
+
+	assert.NoError(t, RegisterPoolMetrics(registry, db))
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+		assert.Equal(t, 0.0, family.GetMetric()[0].GetGauge().GetValue())
+	}
+	assert.ElementsMatch(t, []string{
+		"cakestore_db_pool_open_connections",
+		"cakestore_db_pool_in_use_connections",
+		"cakestore_db_pool_idle_connections",
+		"cakestore_db_pool_wait_count",
+	}, names)
+	assert.Error(t, RegisterPoolMetrics(registry, db), "registering twice")
+}
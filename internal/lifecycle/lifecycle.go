@@ -0,0 +1,103 @@
+// Package lifecycle runs the server until it is told to stop and then shuts
+// it down in order: readiness is reported as failing first so load balancers
+// stop sending traffic, then every registered step is stopped in turn.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Step is something stopped on shutdown, such as the HTTP server or a
+// connection pool
+type Step struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// Manager stops the registered steps in the order they were added
+type Manager struct {
+	steps    []Step
+	logger   *logrus.Logger
+	draining atomic.Bool
+	// drainDelay is how long readiness fails before anything is stopped
+	drainDelay time.Duration
+	// timeout bounds the whole shutdown, steps still running when it
+	// passes see their context expire
+	timeout time.Duration
+}
+
+func NewManager(logger *logrus.Logger, drainDelay, timeout time.Duration) *Manager {
+	return &Manager{logger: logger, drainDelay: drainDelay, timeout: timeout}
+}
+
+// Add registers a step, steps are stopped in the order they were added
+func (m *Manager) Add(name string, stop func(ctx context.Context) error) {
+	m.steps = append(m.steps, Step{Name: name, Stop: stop})
+}
+
+// Ready reports whether the server accepts traffic, it turns false as soon
+// as the shutdown starts
+func (m *Manager) Ready() bool {
+	return !m.draining.Load()
+}
+
+// Run calls serve and blocks until it returns or ctx is cancelled, usually
+// by a signal, then shuts down. serve is expected to return once the step
+// stopping it has run.
+func (m *Manager) Run(ctx context.Context, serve func() error) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	select {
+	case err := <-served:
+		// The server failed to start or stopped on its own, nobody is
+		// being sent traffic so there is nothing to drain
+		m.logger.Errorf("Server stopped unexpectedly: %v", err)
+		return errors.Join(err, m.shutdown(0))
+	case <-ctx.Done():
+		m.logger.Info("Shutdown requested")
+	}
+
+	err := m.shutdown(m.drainDelay)
+	return errors.Join(<-served, err)
+}
+
+// Shutdown fails readiness, waits for the drain delay and stops every step.
+// A failing step doesn't keep the later ones from being stopped, their
+// errors are returned together.
+func (m *Manager) Shutdown() error {
+	return m.shutdown(m.drainDelay)
+}
+
+func (m *Manager) shutdown(drainDelay time.Duration) error {
+	if m.draining.Swap(true) {
+		return errors.New("shutdown already started")
+	}
+	if drainDelay > 0 {
+		m.logger.Infof("Draining for %v before shutting down", drainDelay)
+		time.Sleep(drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	var errs []error
+	for _, step := range m.steps {
+		start := time.Now()
+		if err := step.Stop(ctx); err != nil {
+			m.logger.Errorf("Failed to stop %s: %v", step.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+			continue
+		}
+		m.logger.Infof("Stopped %s in %v", step.Name, time.Since(start))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,175 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(drainDelay, timeout time.Duration) *Manager {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewManager(logger, drainDelay, timeout)
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	t.Run("fails readiness and stops steps in order", func(t *testing.T) {
+		manager := newTestManager(0, time.Second)
+		var stopped []string
+		var readyWhileStopping bool
+		for _, name := range []string{"http server", "workers", "database"} {
+			manager.Add(name, func(context.Context) error {
+				readyWhileStopping = readyWhileStopping || manager.Ready()
+				stopped = append(stopped, name)
+				return nil
+			})
+		}
+
+		assert.True(t, manager.Ready())
+		err := manager.Shutdown()
+
+		assert.NoError(t, err)
+		assert.False(t, manager.Ready())
+		assert.False(t, readyWhileStopping)
+		assert.Equal(t, []string{"http server", "workers", "database"}, stopped)
+	})
+
+	t.Run("keeps stopping after a step fails", func(t *testing.T) {
+		manager := newTestManager(0, time.Second)
+		failure := errors.New("connection reset")
+		var databaseClosed bool
+		manager.Add("redis", func(context.Context) error { return failure })
+		manager.Add("database", func(context.Context) error {
+			databaseClosed = true
+			return nil
+		})
+
+		err := manager.Shutdown()
+
+		assert.ErrorIs(t, err, failure)
+		assert.ErrorContains(t, err, "redis")
+		assert.True(t, databaseClosed)
+	})
+
+	t.Run("bounds slow steps by the timeout", func(t *testing.T) {
+		manager := newTestManager(0, 50*time.Millisecond)
+		var databaseClosed bool
+		manager.Add("workers", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		manager.Add("database", func(context.Context) error {
+			databaseClosed = true
+			return nil
+		})
+
+		start := time.Now()
+		err := manager.Shutdown()
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, databaseClosed)
+	})
+
+	t.Run("waits for the drain delay before stopping", func(t *testing.T) {
+		manager := newTestManager(100*time.Millisecond, time.Second)
+		start := time.Now()
+		var stoppedAfter time.Duration
+		manager.Add("http server", func(context.Context) error {
+			stoppedAfter = time.Since(start)
+			return nil
+		})
+
+		assert.NoError(t, manager.Shutdown())
+		assert.GreaterOrEqual(t, stoppedAfter, 100*time.Millisecond)
+	})
+
+	t.Run("runs only once", func(t *testing.T) {
+		manager := newTestManager(0, time.Second)
+		calls := 0
+		manager.Add("database", func(context.Context) error {
+			calls++
+			return nil
+		})
+
+		assert.NoError(t, manager.Shutdown())
+		assert.Error(t, manager.Shutdown())
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestManager_Run(t *testing.T) {
+	t.Run("lets in-flight requests finish on shutdown", func(t *testing.T) {
+		manager := newTestManager(0, 5*time.Second)
+		app := fiber.New(fiber.Config{DisableStartupMessage: true})
+		started := make(chan struct{})
+		app.Post("/payments/notification", func(c *fiber.Ctx) error {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			return c.SendString("processed")
+		})
+		var workersStopped bool
+		manager.Add("http server", app.ShutdownWithContext)
+		manager.Add("workers", func(context.Context) error {
+			workersStopped = true
+			return nil
+		})
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		ran := make(chan error, 1)
+		go func() {
+			ran <- manager.Run(ctx, func() error { return app.Listener(ln) })
+		}()
+
+		type result struct {
+			body string
+			err  error
+		}
+		responses := make(chan result, 1)
+		go func() {
+			resp, err := http.Post("http://"+ln.Addr().String()+"/payments/notification", "application/json", nil)
+			if err != nil {
+				responses <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			responses <- result{body: string(body), err: err}
+		}()
+
+		<-started
+		cancel()
+
+		response := <-responses
+		assert.NoError(t, response.err)
+		assert.Equal(t, "processed", response.body)
+		assert.NoError(t, <-ran)
+		assert.True(t, workersStopped)
+		assert.False(t, manager.Ready())
+	})
+
+	t.Run("shuts down when the server fails to start", func(t *testing.T) {
+		manager := newTestManager(time.Hour, time.Second)
+		var databaseClosed bool
+		manager.Add("database", func(context.Context) error {
+			databaseClosed = true
+			return nil
+		})
+		failure := errors.New("address already in use")
+
+		err := manager.Run(context.Background(), func() error { return failure })
+
+		assert.ErrorIs(t, err, failure)
+		assert.True(t, databaseClosed)
+	})
+}
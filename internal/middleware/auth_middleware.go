@@ -1,16 +1,29 @@
 package middleware
 
 import (
-	"cakestore/utils"
-	"fmt"
+	"cakestore/internal/constants"
+	"cakestore/internal/token"
+	"context"
 	"log"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(jwtSecret string) fiber.Handler {
+// TokenRevocationChecker reports whether a token was revoked by logout,
+// logout-all or a change to the account. An error means revocation could not
+// be checked.
+type TokenRevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, customerID int64, tokenID string, version int) (bool, error)
+}
+
+// DeviceVerifier checks the device token of the POS terminal a PIN session
+// is bound to
+type DeviceVerifier interface {
+	VerifyDevice(ctx context.Context, deviceID int64, deviceToken string) (bool, error)
+}
+
+func AuthMiddleware(tokens *token.Service, revocations TokenRevocationChecker, devices DeviceVerifier) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -21,26 +34,55 @@ func AuthMiddleware(jwtSecret string) fiber.Handler {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims := &utils.Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := tokens.Parse(tokenString)
+		if err != nil {
 			log.Println(err.Error())
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"message": "Invalid or expired token",
 			})
 		}
 
+		if revocations != nil {
+			revoked, err := revocations.IsTokenRevoked(c.UserContext(), claims.CustomerID, claims.ID, claims.TokenVersion)
+			if err != nil {
+				log.Println(err.Error())
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+					"message": "Unable to verify the token, please try again",
+				})
+			}
+			if revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message": "Token has been revoked",
+				})
+			}
+		}
+
+		// PIN sessions only work from the terminal they were issued on
+		if claims.DeviceID != 0 {
+			verified := false
+			if devices != nil {
+				verified, err = devices.VerifyDevice(c.UserContext(), claims.DeviceID, c.Get(constants.DeviceTokenHeader))
+				if err != nil {
+					log.Println(err.Error())
+				}
+			}
+			if !verified {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message": "Session is bound to another device",
+				})
+			}
+			c.Locals(constants.ClaimsKeyDeviceID, claims.DeviceID)
+		}
+
 		// Use data from custom claims
 		c.Locals("email", claims.Email)
 		c.Locals("name", claims.Name)
 		c.Locals("customer_id", claims.CustomerID)
 		c.Locals("role", claims.Role)
+		c.Locals("token_id", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Locals("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		return c.Next()
 	}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"cakestore/internal/constants"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
@@ -18,11 +20,12 @@ func LogMiddleware(logger *logrus.Logger) fiber.Handler {
 		status := c.Response().StatusCode()
 
 		logger.WithFields(logrus.Fields{
-			"ip":     ip,
-			"method": method,
-			"path":   path,
-			"status": status,
-			"error":  err,
+			"ip":         ip,
+			"method":     method,
+			"path":       path,
+			"status":     status,
+			"error":      err,
+			"request_id": c.Locals(constants.RequestIDKey),
 		}).Info("HTTP Request")
 
 		return err
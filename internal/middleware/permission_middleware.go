@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionChecker resolves the permission policy for a role
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// RequirePermission only lets the request through when the caller's role is
+// granted the permission. It must run after AuthMiddleware.
+func RequirePermission(checker PermissionChecker, permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+
+		allowed, err := checker.HasPermission(c.UserContext(), role, permission)
+		if err != nil {
+			log.Println(err.Error())
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check permissions",
+			})
+		}
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to access this resource",
+			})
+		}
+
+		return c.Next()
+	}
+}
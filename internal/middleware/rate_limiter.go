@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"cakestore/internal/constants"
+	"cakestore/internal/ratelimit"
+	"cakestore/internal/token"
 	"fmt"
+	"math"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,12 +15,15 @@ import (
 )
 
 type RateLimitConfig struct {
-	// Max number of requests allowed per window
-	Max int
-	// Duration of the rate limiting window
-	Duration time.Duration
+	// Limiter counts requests, its policy holds the limits of each group
+	Limiter *ratelimit.Limiter
+	// Group names the limit applied, see the RateLimit* constants
+	Group string
 	// Key generator function to identify clients
 	KeyGenerator func(c *fiber.Ctx) string
+	// Role picks the tier of the limit, the role of the authenticated
+	// customer by default
+	Role func(c *fiber.Ctx) string
 	// Handler to call when rate limit is exceeded
 	LimitReached func(c *fiber.Ctx) error
 	// Skip middleware based on condition
@@ -25,32 +32,23 @@ type RateLimitConfig struct {
 	Logger *logrus.Logger
 }
 
-type ClientInfo struct {
-	Count     int
-	ResetTime time.Time
-	mutex     sync.RWMutex
-}
-
-type RateLimiter struct {
-	clients map[string]*ClientInfo
-	mutex   sync.RWMutex
-	config  RateLimitConfig
-	cleanup *time.Ticker
-}
-
-func NewRateLimiter(config RateLimitConfig) *RateLimiter {
-	// Set defaults
-	if config.Max == 0 {
-		config.Max = 100
-	}
-	if config.Duration == 0 {
-		config.Duration = time.Hour
-	}
+// RateLimit limits requests to the rule of config.Group for the role of the
+// caller. Responses carry the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers of the IETF draft, rejected
+// ones also Retry-After. When the store fails requests are let through, an
+// outage of Redis shouldn't take the API down with it.
+func RateLimit(config RateLimitConfig) fiber.Handler {
 	if config.KeyGenerator == nil {
 		config.KeyGenerator = func(c *fiber.Ctx) string {
 			return c.IP()
 		}
 	}
+	if config.Role == nil {
+		config.Role = func(c *fiber.Ctx) string {
+			role, _ := c.Locals(constants.ClaimsKeyRole).(string)
+			return role
+		}
+	}
 	if config.LimitReached == nil {
 		config.LimitReached = func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
@@ -60,125 +58,109 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 		}
 	}
 
-	rl := &RateLimiter{
-		clients: make(map[string]*ClientInfo),
-		config:  config,
-	}
-
-	// Start cleanup routine to remove expired entries
-	rl.cleanup = time.NewTicker(config.Duration)
-	go rl.cleanupExpired()
-
-	return rl
-}
-
-func (rl *RateLimiter) cleanupExpired() {
-	for range rl.cleanup.C {
-		now := time.Now()
-		rl.mutex.Lock()
-		for key, client := range rl.clients {
-			client.mutex.RLock()
-			if now.After(client.ResetTime) {
-				delete(rl.clients, key)
-			}
-			client.mutex.RUnlock()
-		}
-		rl.mutex.Unlock()
-	}
-}
-
-func (rl *RateLimiter) Stop() {
-	if rl.cleanup != nil {
-		rl.cleanup.Stop()
-	}
-}
-
-func (rl *RateLimiter) Middleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Skip if condition is met
-		if rl.config.Skip != nil && rl.config.Skip(c) {
+		if config.Skip != nil && config.Skip(c) {
 			return c.Next()
 		}
 
-		key := rl.config.KeyGenerator(c)
-		now := time.Now()
-
-		// Get or create client info
-		rl.mutex.Lock()
-		client, exists := rl.clients[key]
-		if !exists {
-			client = &ClientInfo{
-				Count:     0,
-				ResetTime: now.Add(rl.config.Duration),
+		key := config.KeyGenerator(c)
+		result, err := config.Limiter.Allow(c.UserContext(), config.Group, config.Role(c), key)
+		if err != nil {
+			if config.Logger != nil {
+				config.Logger.Warnf("Rate limit %s not enforced for client %s: %v", config.Group, key, err)
 			}
-			rl.clients[key] = client
+			return c.Next()
 		}
-		rl.mutex.Unlock()
-
-		client.mutex.Lock()
-		defer client.mutex.Unlock()
-
-		// Reset if window has expired
-		if now.After(client.ResetTime) {
-			client.Count = 0
-			client.ResetTime = now.Add(rl.config.Duration)
+		if result.Limit == 0 {
+			return c.Next()
 		}
 
-		// Check if limit is exceeded
-		if client.Count >= rl.config.Max {
-			// Set rate limit headers
-			c.Set("X-RateLimit-Limit", strconv.Itoa(rl.config.Max))
-			c.Set("X-RateLimit-Remaining", "0")
-			c.Set("X-RateLimit-Reset", strconv.FormatInt(client.ResetTime.Unix(), 10))
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", seconds(result.Reset))
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", result.Limit, seconds(result.Window)))
 
-			// Log rate limit exceeded
-			if rl.config.Logger != nil {
-				rl.config.Logger.Warnf("Rate limit exceeded for client: %s", key)
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, seconds(result.RetryAfter))
+			if config.Logger != nil {
+				config.Logger.Warnf("Rate limit %s exceeded for client: %s", config.Group, key)
 			}
-
-			return rl.config.LimitReached(c)
+			return config.LimitReached(c)
 		}
-
-		// Increment counter
-		client.Count++
-
-		// Set rate limit headers
-		c.Set("X-RateLimit-Limit", strconv.Itoa(rl.config.Max))
-		c.Set("X-RateLimit-Remaining", strconv.Itoa(rl.config.Max-client.Count))
-		c.Set("X-RateLimit-Reset", strconv.FormatInt(client.ResetTime.Unix(), 10))
-
 		return c.Next()
 	}
 }
 
-// Predefined rate limit configurations
+// seconds formats d as whole seconds for headers, rounding up so clients
+// never retry too early
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
 
-// BasicRateLimit creates a basic rate limiter (100 requests per hour)
-func BasicRateLimit(logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      100,
-		Duration: time.Hour,
-		Logger:   logger,
+// Predefined rate limits, their limits are set in the policy of limiter
+
+// BasicRateLimit limits every request. Requests with a valid access token
+// are counted per customer, otherwise staff terminals sharing the
+// restaurant's IP would exhaust one limit together, the others by IP
+// address. Only the signature and expiry of the token are checked here,
+// AuthMiddleware still checks revocation.
+func BasicRateLimit(limiter *ratelimit.Limiter, tokens *token.Service, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   constants.RateLimitGlobal,
+		Logger:  logger,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if claims := bearerClaims(c, tokens); claims != nil {
+				return ratelimit.UserClient(claims.CustomerID)
+			}
+			return c.IP()
+		},
+		Role: func(c *fiber.Ctx) string {
+			if claims := bearerClaims(c, tokens); claims != nil {
+				return claims.Role
+			}
+			return ""
+		},
 	})
-	return rl.Middleware()
 }
 
-// StrictRateLimit creates a strict rate limiter (20 requests per minute)
-func StrictRateLimit(logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      20,
-		Duration: time.Minute,
-		Logger:   logger,
+// rateLimitClaimsKey keeps the claims bearerClaims verified for the rest of
+// the request
+const rateLimitClaimsKey = "rate_limit_claims"
+
+// bearerClaims returns the claims of a valid bearer token, or nil without one
+func bearerClaims(c *fiber.Ctx, tokens *token.Service) *token.Claims {
+	if claims, ok := c.Locals(rateLimitClaimsKey).(*token.Claims); ok {
+		return claims
+	}
+	header := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil
+	}
+	claims, err := tokens.Parse(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return nil
+	}
+	c.Locals(rateLimitClaimsKey, claims)
+	return claims
+}
+
+// StrictRateLimit limits sensitive endpoints by IP address
+func StrictRateLimit(limiter *ratelimit.Limiter, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   constants.RateLimitStrict,
+		Logger:  logger,
 	})
-	return rl.Middleware()
 }
 
 // AuthRateLimit creates rate limiting for auth endpoints
-func AuthRateLimit(logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      5, // 5 login/register attempts per 15 minutes
-		Duration: 15 * time.Minute,
-		Logger:   logger,
+func AuthRateLimit(limiter *ratelimit.Limiter, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   constants.RateLimitAuth,
+		Logger:  logger,
 		LimitReached: func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error":   "Too many authentication attempts",
@@ -186,15 +168,14 @@ func AuthRateLimit(logger *logrus.Logger) fiber.Handler {
 			})
 		},
 	})
-	return rl.Middleware()
 }
 
 // PaymentRateLimit creates rate limiting for payment endpoints
-func PaymentRateLimit(logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      10, // 10 payment requests per hour
-		Duration: time.Hour,
-		Logger:   logger,
+func PaymentRateLimit(limiter *ratelimit.Limiter, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   constants.RateLimitPaymentWebhook,
+		Logger:  logger,
 		LimitReached: func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error":   "Payment rate limit exceeded",
@@ -202,36 +183,33 @@ func PaymentRateLimit(logger *logrus.Logger) fiber.Handler {
 			})
 		},
 	})
-	return rl.Middleware()
 }
 
-// UserBasedRateLimit creates a rate limiter based on user ID
-func UserBasedRateLimit(max int, duration time.Duration, logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      max,
-		Duration: duration,
-		Logger:   logger,
+// UserBasedRateLimit limits the authenticated customer, it must run after
+// AuthMiddleware
+func UserBasedRateLimit(limiter *ratelimit.Limiter, group string, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   group,
+		Logger:  logger,
 		KeyGenerator: func(c *fiber.Ctx) string {
-			// Try to get user ID from JWT claims
-			if userID := c.Locals("userID"); userID != nil {
-				return fmt.Sprintf("user:%v", userID)
+			if customerID, ok := c.Locals(constants.ClaimsKeyID).(int64); ok {
+				return ratelimit.UserClient(customerID)
 			}
 			// Fallback to IP if no user ID
 			return c.IP()
 		},
 	})
-	return rl.Middleware()
 }
 
 // IPBasedRateLimit creates a rate limiter based on IP address
-func IPBasedRateLimit(max int, duration time.Duration, logger *logrus.Logger) fiber.Handler {
-	rl := NewRateLimiter(RateLimitConfig{
-		Max:      max,
-		Duration: duration,
-		Logger:   logger,
+func IPBasedRateLimit(limiter *ratelimit.Limiter, group string, logger *logrus.Logger) fiber.Handler {
+	return RateLimit(RateLimitConfig{
+		Limiter: limiter,
+		Group:   group,
+		Logger:  logger,
 		KeyGenerator: func(c *fiber.Ctx) string {
 			return c.IP()
 		},
 	})
-	return rl.Middleware()
 }
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"cakestore/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestContextKey holds the user context of the request before any
+// deadline was set on it
+const requestContextKey = "request_context"
+
+// RequestTimeout cancels the user context of the request after timeout, and
+// with it the queries run for the request. Routes that need longer, such as
+// exports, add their own RequestTimeout, which replaces the deadline rather
+// than shortening it. Requests failing because they ran out of time are
+// answered with 504.
+func RequestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent, ok := c.Locals(requestContextKey).(context.Context)
+		if !ok {
+			parent = c.UserContext()
+			c.Locals(requestContextKey, parent)
+		}
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Only the innermost timeout answers, the deadline that ran out is its own
+		if c.UserContext() == ctx && errors.Is(ctx.Err(), context.DeadlineExceeded) && c.Response().StatusCode() >= fiber.StatusInternalServerError {
+			c.Response().ResetBody()
+			return utils.WriteErrorResponse(c, fiber.StatusGatewayTimeout, "Request timed out")
+		}
+		return err
+	}
+}
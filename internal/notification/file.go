@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileChannel appends messages to a local file, or only logs them when no
+// path is set, so notifications can be checked without external services
+type FileChannel struct {
+	path   string
+	logger *logrus.Logger
+	mu     sync.Mutex
+}
+
+func NewFileChannel(path string, logger *logrus.Logger) *FileChannel {
+	return &FileChannel{path: path, logger: logger}
+}
+
+func (c *FileChannel) Name() string {
+	return "file"
+}
+
+func (c *FileChannel) Send(_ context.Context, message Message) error {
+	c.logger.Infof("Notification to %s: %s", message.To, message.Subject)
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "--- %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+	return err
+}
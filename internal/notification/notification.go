@@ -0,0 +1,31 @@
+package notification
+
+import "context"
+
+// Event identifies why a customer is notified, each event has its own template
+type Event string
+
+const (
+	EventReservationConfirmed Event = "reservation_confirmed"
+	EventReservationReminder  Event = "reservation_reminder"
+	EventOrderPaid            Event = "order_paid"
+	EventOrderReady           Event = "order_ready"
+	EventRefundProcessed      Event = "refund_processed"
+	EventPasswordReset        Event = "password_reset"
+	EventEmailVerification    Event = "email_verification"
+	EventEmployeeInvite       Event = "employee_invite"
+)
+
+// Message is a rendered notification ready to be delivered
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Channel delivers messages over one medium, such as email, SMS or WhatsApp.
+// Send returns an error when the message should be retried.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, message Message) error
+}
@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func mustTemplate(event Event, subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New(string(event) + ":subject").Parse(subject)),
+		body:    template.Must(template.New(string(event) + ":body").Parse(body)),
+	}
+}
+
+var templates = map[Event]messageTemplate{
+	EventReservationConfirmed: mustTemplate(EventReservationConfirmed,
+		"Your reservation #{{.ReservationID}} is confirmed",
+		`Hi {{.Name}},
+
+Your table for {{.GuestCount}} on {{.Date}} is confirmed. We look forward to seeing you.
+`),
+	EventReservationReminder: mustTemplate(EventReservationReminder,
+		"See you soon: reservation #{{.ReservationID}}",
+		`Hi {{.Name}},
+
+This is a reminder of your reservation for {{.GuestCount}} today at {{.Time}}.
+If your plans changed, please let us know.
+`),
+	EventOrderPaid: mustTemplate(EventOrderPaid,
+		"Payment received for order #{{.OrderID}}",
+		`Hi {{.Name}},
+
+We received your payment of {{.Amount}} for order #{{.OrderID}}. We will let you know when it is ready.
+`),
+	EventOrderReady: mustTemplate(EventOrderReady,
+		"Order #{{.OrderID}} is ready for pickup",
+		`Hi {{.Name}},
+
+Your order #{{.OrderID}} is ready. Please pick it up at the counter.
+`),
+	EventRefundProcessed: mustTemplate(EventRefundProcessed,
+		"Your refund of {{.Amount}} has been processed",
+		`Hi {{.Name}},
+
+We refunded {{.Amount}} for {{.Reason}}. Depending on your bank it may take a few days to appear.
+`),
+	EventPasswordReset: mustTemplate(EventPasswordReset,
+		"Reset your CakeStore password",
+		`Hi {{.Name}},
+
+We received a request to reset your password. Open the link below to choose a new one:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}} and can only be used once. If you did not ask for this, you can ignore this email.
+`),
+	EventEmailVerification: mustTemplate(EventEmailVerification,
+		"Confirm your email address",
+		`Hi {{.Name}},
+
+Please confirm your email address by opening the link below:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}}.
+`),
+	EventEmployeeInvite: mustTemplate(EventEmployeeInvite,
+		"You're invited to join the CakeStore team",
+		`Hi {{.Name}},
+
+You have been added to the CakeStore staff. Open the link below to choose your password and finish setting up your account:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}} and can only be used once.
+`),
+}
+
+// Render fills the template of an event, data must provide every field the
+// template uses
+func Render(event Event, to string, data map[string]any) (Message, error) {
+	tmpl, ok := templates[event]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for notification event %q", event)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Option("missingkey=error").Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s subject: %w", event, err)
+	}
+	if err := tmpl.body.Option("missingkey=error").Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s body: %w", event, err)
+	}
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
+
+// FormatAmount formats a rupiah amount with thousand separators, e.g. Rp150.000
+func FormatAmount(amount float64) string {
+	digits := fmt.Sprintf("%.0f", amount)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + "Rp" + b.String()
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore counts in process. Counters aren't shared between replicas
+// and are lost on restart, it suits single instances and local setups.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	done     chan struct{}
+	stopOnce sync.Once
+	// now is replaced by tests to move across window boundaries
+	now func() time.Time
+}
+
+// memoryCounter is a window count or, for token buckets, the tokens left
+// at last
+type memoryCounter struct {
+	count     int64
+	tokens    float64
+	last      time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a store that drops expired counters every
+// cleanupInterval until Close is called
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		counters: make(map[string]*memoryCounter),
+		done:     make(chan struct{}),
+		now:      time.Now,
+	}
+	go s.cleanup(cleanupInterval)
+	return s
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch rule.Algorithm {
+	case TokenBucket:
+		bucket := s.counter(bucketKey(key), now, rule.Window)
+		if bucket.last.IsZero() {
+			bucket.tokens = float64(rule.Max)
+		} else {
+			bucket.tokens = refill(rule, bucket.tokens, bucket.last, now)
+		}
+		if now.After(bucket.last) {
+			bucket.last = now
+		}
+		allowed := bucket.tokens >= 1
+		if allowed {
+			bucket.tokens--
+		}
+		return tokenBucketResult(rule, allowed, bucket.tokens), nil
+
+	case SlidingWindow:
+		index, elapsed := currentWindow(now, rule.Window)
+		previous := s.count(windowKey(key, index-1), now)
+		current := s.counter(windowKey(key, index), now, 2*rule.Window-elapsed)
+		allowed := slidingEstimate(previous, current.count+1, elapsed, rule.Window) <= float64(rule.Max)
+		if allowed {
+			current.count++
+		}
+		return slidingWindowResult(rule, allowed, previous, current.count, elapsed), nil
+
+	default:
+		index, elapsed := currentWindow(now, rule.Window)
+		current := s.counter(windowKey(key, index), now, rule.Window-elapsed)
+		allowed := current.count < int64(rule.Max)
+		if allowed {
+			current.count++
+		}
+		return fixedWindowResult(rule, allowed, current.count, elapsed), nil
+	}
+}
+
+func (s *MemoryStore) Peek(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch rule.Algorithm {
+	case TokenBucket:
+		tokens := float64(rule.Max)
+		if bucket, ok := s.counters[bucketKey(key)]; ok && !now.After(bucket.expiresAt) {
+			tokens = refill(rule, bucket.tokens, bucket.last, now)
+		}
+		return tokenBucketResult(rule, tokens >= 1, tokens), nil
+
+	case SlidingWindow:
+		index, elapsed := currentWindow(now, rule.Window)
+		previous := s.count(windowKey(key, index-1), now)
+		current := s.count(windowKey(key, index), now)
+		allowed := slidingEstimate(previous, current+1, elapsed, rule.Window) <= float64(rule.Max)
+		return slidingWindowResult(rule, allowed, previous, current, elapsed), nil
+
+	default:
+		index, elapsed := currentWindow(now, rule.Window)
+		current := s.count(windowKey(key, index), now)
+		return fixedWindowResult(rule, current < int64(rule.Max), current, elapsed), nil
+	}
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string, rule Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range counterKeys(key, rule, s.now()) {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// Close stops the cleanup goroutine
+func (s *MemoryStore) Close() error {
+	s.stopOnce.Do(func() { close(s.done) })
+	return nil
+}
+
+// counter returns the live counter of key, creating it to expire after ttl
+func (s *MemoryStore) counter(key string, now time.Time, ttl time.Duration) *memoryCounter {
+	counter, ok := s.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		counter = &memoryCounter{}
+		s.counters[key] = counter
+	}
+	counter.expiresAt = now.Add(ttl)
+	return counter
+}
+
+// count is the count of key without creating it
+func (s *MemoryStore) count(key string, now time.Time) int64 {
+	if counter, ok := s.counters[key]; ok && !now.After(counter.expiresAt) {
+		return counter.count
+	}
+	return 0
+}
+
+func (s *MemoryStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, counter := range s.counters {
+				if now.After(counter.expiresAt) {
+					delete(s.counters, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
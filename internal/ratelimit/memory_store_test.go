@@ -0,0 +1,15 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T, clock *testClock) Store {
+		store := NewMemoryStore(time.Minute)
+		store.now = clock.Now
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
@@ -0,0 +1,363 @@
+// Package ratelimit counts requests per client in a shared store, so limits
+// hold across replicas and restarts, and decides which ones are allowed.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm decides how requests are counted against a rule
+type Algorithm string
+
+const (
+	// FixedWindow counts requests in consecutive windows, a client can make
+	// up to twice the limit around a window boundary
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindow weighs the previous window by how much of it still
+	// overlaps the last Window, smoothing out the boundary burst
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket refills Max tokens per Window one at a time, allowing
+	// bursts of up to Max requests
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// ParseAlgorithm validates an algorithm name from config
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch algorithm := Algorithm(name); algorithm {
+	case FixedWindow, SlidingWindow, TokenBucket:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown rate limit algorithm %q, expected fixed_window, sliding_window or token_bucket", name)
+	}
+}
+
+// Rule allows Max requests per Window
+type Rule struct {
+	Max       int
+	Window    time.Duration
+	Algorithm Algorithm
+}
+
+// Result is the outcome of counting a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Window is the period Limit applies to
+	Window time.Duration
+	// Reset is how long until the current window ends, or for token buckets
+	// until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long a rejected client has to wait
+	RetryAfter time.Duration
+}
+
+// Store counts requests, implementations must count atomically so
+// concurrent requests can't exceed the limit
+type Store interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+	// Peek reports the state of key without counting a request
+	Peek(ctx context.Context, key string, rule Rule) (Result, error)
+	// Reset forgets the requests counted for key
+	Reset(ctx context.Context, key string, rule Rule) error
+	// Close releases what the store holds, it is called on shutdown
+	Close() error
+}
+
+// Status is the state of the counter of a client in a group
+type Status struct {
+	Group string
+	Rule  Rule
+	Result
+}
+
+// Policy holds the rules of each group, optionally overridden per role or
+// per tier of roles
+type Policy struct {
+	algorithm Algorithm
+	rules     map[string]Rule
+	tier      func(role string) string
+}
+
+// NewPolicy creates an empty policy, rules without an algorithm use
+// algorithm
+func NewPolicy(algorithm Algorithm) *Policy {
+	return &Policy{algorithm: algorithm, rules: make(map[string]Rule)}
+}
+
+// Set sets the rule of group, or of role within group when role isn't empty
+func (p *Policy) Set(group, role string, rule Rule) {
+	if rule.Algorithm == "" {
+		rule.Algorithm = p.algorithm
+	}
+	p.rules[policyKey(group, role)] = rule
+}
+
+// SetTiers groups roles in tiers, a role without a rule of its own gets the
+// rule of its tier
+func (p *Policy) SetTiers(tier func(role string) string) {
+	p.tier = tier
+}
+
+// Rule returns the rule of role within group, falling back to the rule of
+// its tier and then of the group
+func (p *Policy) Rule(group, role string) (Rule, bool) {
+	if role != "" {
+		if rule, ok := p.rules[policyKey(group, role)]; ok {
+			return rule, true
+		}
+		if p.tier != nil {
+			if rule, ok := p.rules[policyKey(group, p.tier(role))]; ok {
+				return rule, true
+			}
+		}
+	}
+	rule, ok := p.rules[group]
+	return rule, ok
+}
+
+// Scale gives role, or a tier, factor times the limit of each of groups.
+// Groups without a rule are skipped.
+func (p *Policy) Scale(role string, factor int, groups ...string) {
+	for _, group := range groups {
+		if rule, ok := p.rules[group]; ok {
+			rule.Max *= factor
+			p.rules[policyKey(group, role)] = rule
+		}
+	}
+}
+
+// Groups lists the groups with a rule, sorted by name
+func (p *Policy) Groups() []string {
+	var groups []string
+	for key := range p.rules {
+		if !strings.Contains(key, ":") {
+			groups = append(groups, key)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Parse sets the rules of specs formatted as group[:role]=max/window[/algorithm],
+// such as orders=50/1h or carts:cashier=500/15m/token_bucket. The role may
+// also be a tier.
+func (p *Policy) Parse(specs []string) error {
+	for _, spec := range specs {
+		target, limit, ok := strings.Cut(spec, "=")
+		if !ok {
+			return fmt.Errorf("invalid rate limit %q, expected group[:role]=max/window[/algorithm]", spec)
+		}
+		group, role, _ := strings.Cut(strings.TrimSpace(target), ":")
+		rule, err := parseRule(strings.TrimSpace(limit))
+		if err != nil {
+			return fmt.Errorf("invalid rate limit %q: %w", spec, err)
+		}
+		p.Set(group, role, rule)
+	}
+	return nil
+}
+
+func parseRule(limit string) (Rule, error) {
+	parts := strings.Split(limit, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Rule{}, fmt.Errorf("expected max/window[/algorithm]")
+	}
+	maxRequests, err := strconv.Atoi(parts[0])
+	if err != nil || maxRequests <= 0 {
+		return Rule{}, fmt.Errorf("max must be a positive number")
+	}
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return Rule{}, fmt.Errorf("window must be a positive duration such as 15m")
+	}
+	rule := Rule{Max: maxRequests, Window: window}
+	if len(parts) == 3 {
+		if rule.Algorithm, err = ParseAlgorithm(parts[2]); err != nil {
+			return Rule{}, err
+		}
+	}
+	return rule, nil
+}
+
+func policyKey(group, role string) string {
+	if role == "" {
+		return group
+	}
+	return group + ":" + role
+}
+
+// Limiter applies a policy to clients, counting in store
+type Limiter struct {
+	store  Store
+	policy *Policy
+}
+
+func NewLimiter(store Store, policy *Policy) *Limiter {
+	return &Limiter{store: store, policy: policy}
+}
+
+// Allow counts a request of client against the rule of group for role.
+// Groups without a rule are unlimited, their results have a Limit of zero.
+func (l *Limiter) Allow(ctx context.Context, group, role, client string) (Result, error) {
+	rule, ok := l.policy.Rule(group, role)
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Allow(ctx, counterKey(group, client), rule)
+}
+
+// Status reports the counters of client in every group, using the rules of
+// role
+func (l *Limiter) Status(ctx context.Context, client, role string) ([]Status, error) {
+	var statuses []Status
+	for _, group := range l.policy.Groups() {
+		rule, _ := l.policy.Rule(group, role)
+		result, err := l.store.Peek(ctx, counterKey(group, client), rule)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, Status{Group: group, Rule: rule, Result: result})
+	}
+	return statuses, nil
+}
+
+// Reset forgets the requests of client in group, or in every group when
+// group is empty
+func (l *Limiter) Reset(ctx context.Context, client, role, group string) error {
+	groups := l.policy.Groups()
+	if group != "" {
+		groups = []string{group}
+	}
+	for _, group := range groups {
+		rule, ok := l.policy.Rule(group, role)
+		if !ok {
+			continue
+		}
+		if err := l.store.Reset(ctx, counterKey(group, client), rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasGroup reports whether group has a rule
+func (l *Limiter) HasGroup(group string) bool {
+	_, ok := l.policy.Rule(group, "")
+	return ok
+}
+
+// Close closes the store
+func (l *Limiter) Close() error {
+	return l.store.Close()
+}
+
+// UserClient identifies an authenticated client by its customer ID, so its
+// limit follows it across devices and networks
+func UserClient(customerID int64) string {
+	return "user:" + strconv.FormatInt(customerID, 10)
+}
+
+func counterKey(group, client string) string {
+	return "ratelimit:" + group + ":" + client
+}
+
+// counterKeys are every key Allow may store for key
+func counterKeys(key string, rule Rule, now time.Time) []string {
+	index, _ := currentWindow(now, rule.Window)
+	return []string{windowKey(key, index), windowKey(key, index-1), bucketKey(key)}
+}
+
+func bucketKey(key string) string {
+	return key + ":bucket"
+}
+
+// currentWindow returns the index of the window containing now and how far
+// into it now is. Windows are aligned to the unix epoch so every replica
+// agrees on them.
+func currentWindow(now time.Time, size time.Duration) (int64, time.Duration) {
+	nanos := now.UnixNano()
+	return nanos / int64(size), time.Duration(nanos % int64(size))
+}
+
+func windowKey(key string, index int64) string {
+	return key + ":" + strconv.FormatInt(index, 10)
+}
+
+// slidingEstimate is the number of requests in the last window, assuming
+// the requests of the previous window were evenly spread
+func slidingEstimate(previous, current int64, elapsed, size time.Duration) float64 {
+	return float64(previous)*(1-float64(elapsed)/float64(size)) + float64(current)
+}
+
+func fixedWindowResult(rule Rule, allowed bool, count int64, elapsed time.Duration) Result {
+	reset := rule.Window - elapsed
+	result := Result{
+		Allowed:   allowed,
+		Limit:     rule.Max,
+		Window:    rule.Window,
+		Remaining: max(rule.Max-int(count), 0),
+		Reset:     reset,
+	}
+	if !allowed {
+		result.RetryAfter = reset
+	}
+	return result
+}
+
+func slidingWindowResult(rule Rule, allowed bool, previous, current int64, elapsed time.Duration) Result {
+	estimate := slidingEstimate(previous, current, elapsed, rule.Window)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     rule.Max,
+		Window:    rule.Window,
+		Remaining: max(rule.Max-int(math.Ceil(estimate)), 0),
+		Reset:     rule.Window - elapsed,
+	}
+	if allowed {
+		return result
+	}
+
+	// Wait until enough of the previous window has slid out for one more
+	// request, or into the next window when this one is already full
+	free := float64(rule.Max - 1)
+	if current <= int64(free) {
+		share := 1 - (free-float64(current))/float64(previous)
+		result.RetryAfter = time.Duration(share*float64(rule.Window)) - elapsed
+	} else {
+		share := 1 - free/float64(current)
+		result.RetryAfter = rule.Window - elapsed + time.Duration(share*float64(rule.Window))
+	}
+	result.RetryAfter = max(result.RetryAfter, 0)
+	return result
+}
+
+func tokenBucketResult(rule Rule, allowed bool, tokens float64) Result {
+	perToken := float64(rule.Window) / float64(rule.Max)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     rule.Max,
+		Window:    rule.Window,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(rule.Max) - tokens) * perToken),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return result
+}
+
+// refill adds the tokens earned since last to a bucket holding tokens
+func refill(rule Rule, tokens float64, last, now time.Time) float64 {
+	if !now.After(last) {
+		return tokens
+	}
+	earned := float64(now.Sub(last)) * float64(rule.Max) / float64(rule.Window)
+	return math.Min(float64(rule.Max), tokens+earned)
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowStart is aligned to the minute, so the windows of the rules below
+// start on it
+var windowStart = time.Unix(1_800_000_000, 0)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// storeStep moves the clock by advance and then counts a request, or only
+// peeks at the counter, expecting the result
+type storeStep struct {
+	name       string
+	advance    time.Duration
+	peek       bool
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+var storeTests = []struct {
+	name  string
+	rule  Rule
+	steps []storeStep
+}{
+	{
+		name: "fixed window",
+		rule: Rule{Max: 3, Window: time.Minute, Algorithm: FixedWindow},
+		steps: []storeStep{
+			{name: "first request", advance: 10 * time.Second, allowed: true, remaining: 2, reset: 50 * time.Second},
+			{name: "second request", allowed: true, remaining: 1, reset: 50 * time.Second},
+			{name: "last request", allowed: true, remaining: 0, reset: 50 * time.Second},
+			{name: "over the limit", allowed: false, remaining: 0, reset: 50 * time.Second, retryAfter: 50 * time.Second},
+			{name: "peek reports the denial", peek: true, allowed: false, remaining: 0, reset: 50 * time.Second, retryAfter: 50 * time.Second},
+			{name: "last millisecond of the window", advance: 49*time.Second + 999*time.Millisecond, allowed: false, remaining: 0, reset: time.Millisecond, retryAfter: time.Millisecond},
+			{name: "next window starts over", advance: time.Millisecond, allowed: true, remaining: 2, reset: time.Minute},
+		},
+	},
+	{
+		name: "sliding window",
+		rule: Rule{Max: 4, Window: time.Minute, Algorithm: SlidingWindow},
+		steps: []storeStep{
+			{name: "first request", advance: 30 * time.Second, allowed: true, remaining: 3, reset: 30 * time.Second},
+			{name: "second request", allowed: true, remaining: 2, reset: 30 * time.Second},
+			{name: "third request", allowed: true, remaining: 1, reset: 30 * time.Second},
+			{name: "last request", allowed: true, remaining: 0, reset: 30 * time.Second},
+			// a quarter of the next window must pass before the four requests weigh three
+			{name: "full window waits into the next", allowed: false, remaining: 0, reset: 30 * time.Second, retryAfter: 45 * time.Second},
+			{name: "previous window weighs three", advance: 45 * time.Second, allowed: true, remaining: 0, reset: 45 * time.Second},
+			{name: "waits for the previous window to slide", allowed: false, remaining: 0, reset: 45 * time.Second, retryAfter: 15 * time.Second},
+			{name: "peek once half of it slid out", advance: 15 * time.Second, peek: true, allowed: true, remaining: 1, reset: 30 * time.Second},
+			{name: "request once half of it slid out", allowed: true, remaining: 0, reset: 30 * time.Second},
+		},
+	},
+	{
+		name: "token bucket",
+		rule: Rule{Max: 4, Window: time.Minute, Algorithm: TokenBucket},
+		steps: []storeStep{
+			{name: "starts full", allowed: true, remaining: 3, reset: 15 * time.Second},
+			{name: "second token", allowed: true, remaining: 2, reset: 30 * time.Second},
+			{name: "third token", allowed: true, remaining: 1, reset: 45 * time.Second},
+			{name: "last token", allowed: true, remaining: 0, reset: time.Minute},
+			{name: "empty bucket", allowed: false, remaining: 0, reset: time.Minute, retryAfter: 15 * time.Second},
+			{name: "half a token", advance: 7500 * time.Millisecond, allowed: false, remaining: 0, reset: 52500 * time.Millisecond, retryAfter: 7500 * time.Millisecond},
+			{name: "refilled token", advance: 7500 * time.Millisecond, allowed: true, remaining: 0, reset: time.Minute},
+			{name: "peek refills without taking", advance: 30 * time.Second, peek: true, allowed: true, remaining: 2, reset: 30 * time.Second},
+		},
+	},
+}
+
+// testStore runs the store tests against the store newStore creates, the
+// store must read the time from clock
+func testStore(t *testing.T, newStore func(t *testing.T, clock *testClock) Store) {
+	for _, tt := range storeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: windowStart}
+			store := newStore(t, clock)
+			key := counterKey("orders", UserClient(1))
+
+			for _, step := range tt.steps {
+				clock.now = clock.now.Add(step.advance)
+				var result Result
+				var err error
+				if step.peek {
+					result, err = store.Peek(context.Background(), key, tt.rule)
+				} else {
+					result, err = store.Allow(context.Background(), key, tt.rule)
+				}
+				require.NoError(t, err, step.name)
+
+				assert.Equal(t, step.allowed, result.Allowed, step.name)
+				assert.Equal(t, tt.rule.Max, result.Limit, step.name)
+				assert.Equal(t, tt.rule.Window, result.Window, step.name)
+				assert.Equal(t, step.remaining, result.Remaining, step.name)
+				assert.Equal(t, step.reset, result.Reset, step.name)
+				assert.Equal(t, step.retryAfter, result.RetryAfter, step.name)
+			}
+		})
+
+		t.Run(tt.name+" reset", func(t *testing.T) {
+			clock := &testClock{now: windowStart.Add(10 * time.Second)}
+			store := newStore(t, clock)
+			key := counterKey("orders", UserClient(1))
+			for i := 0; i < tt.rule.Max; i++ {
+				_, err := store.Allow(context.Background(), key, tt.rule)
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, store.Reset(context.Background(), key, tt.rule))
+
+			result, err := store.Allow(context.Background(), key, tt.rule)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, tt.rule.Max-1, result.Remaining)
+		})
+	}
+}
+
+func TestSlidingWindowResult(t *testing.T) {
+	rule := Rule{Max: 4, Window: time.Minute, Algorithm: SlidingWindow}
+	tests := []struct {
+		name              string
+		allowed           bool
+		previous, current int64
+		elapsed           time.Duration
+		remaining         int
+		retryAfter        time.Duration
+	}{
+		{name: "allowed requests don't wait", allowed: true, previous: 4, current: 1, elapsed: 15 * time.Second, remaining: 0},
+		{name: "remaining rounds the estimate up", allowed: true, previous: 3, current: 1, elapsed: 30 * time.Second, remaining: 1},
+		{name: "waits for the previous window to slide", previous: 4, current: 1, elapsed: 15 * time.Second, retryAfter: 15 * time.Second},
+		{name: "full window waits into the next", previous: 0, current: 4, elapsed: 30 * time.Second, retryAfter: 45 * time.Second},
+		{name: "full window ignores the previous one", previous: 4, current: 4, elapsed: 50 * time.Second, retryAfter: 25 * time.Second},
+		{name: "never negative", previous: 4, current: 0, elapsed: 40 * time.Second, remaining: 2, retryAfter: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := slidingWindowResult(rule, tt.allowed, tt.previous, tt.current, tt.elapsed)
+
+			assert.Equal(t, tt.allowed, result.Allowed)
+			assert.Equal(t, tt.remaining, result.Remaining)
+			assert.Equal(t, rule.Window-tt.elapsed, result.Reset)
+			assert.Equal(t, tt.retryAfter, result.RetryAfter)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The scripts count and decide in one round trip, so replicas sharing a
+// counter can't both take the last request. Every key is passed in KEYS as
+// Redis Cluster and Dragonfly require.
+var (
+	// fixedWindowScript counts in KEYS[1] unless it already reached ARGV[1],
+	// the counter expires after ARGV[2] milliseconds
+	fixedWindowScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count >= tonumber(ARGV[1]) then
+	return {0, count}
+end
+count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return {1, count}
+`)
+
+	// slidingWindowScript counts in the current window KEYS[1] unless the
+	// previous window KEYS[2], weighted by ARGV[2], and the current one
+	// already reached ARGV[1]
+	slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * tonumber(ARGV[2]) + current + 1 > tonumber(ARGV[1]) then
+	return {0, previous, current}
+end
+current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, previous, current}
+`)
+
+	// tokenBucketScript takes a token from the bucket KEYS[1] holding up to
+	// ARGV[1] tokens, refilled over ARGV[2] milliseconds, at ARGV[3] unix
+	// milliseconds. Tokens are returned as a string to keep the fraction.
+	tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or capacity
+local last = tonumber(bucket[2]) or now
+if now > last then
+	tokens = math.min(capacity, tokens + (now - last) * capacity / window)
+	last = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(last))
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+)
+
+// RedisStore counts in Redis, so every replica shares the same counters and
+// they survive restarts
+type RedisStore struct {
+	client *redis.Client
+	// now is replaced by tests to move across window boundaries
+	now func() time.Time
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, now: time.Now}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := s.now()
+
+	switch rule.Algorithm {
+	case TokenBucket:
+		reply, err := tokenBucketScript.Run(ctx, s.client, []string{bucketKey(key)},
+			rule.Max, rule.Window.Milliseconds(), now.UnixMilli()).Slice()
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to count request in Redis: %w", err)
+		}
+		tokens, err := strconv.ParseFloat(fmt.Sprint(reply[1]), 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("unexpected token bucket reply %v: %w", reply, err)
+		}
+		return tokenBucketResult(rule, reply[0] == int64(1), tokens), nil
+
+	case SlidingWindow:
+		index, elapsed := currentWindow(now, rule.Window)
+		weight := 1 - float64(elapsed)/float64(rule.Window)
+		reply, err := slidingWindowScript.Run(ctx, s.client,
+			[]string{windowKey(key, index), windowKey(key, index-1)},
+			rule.Max, weight, (2*rule.Window - elapsed).Milliseconds()).Int64Slice()
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to count request in Redis: %w", err)
+		}
+		return slidingWindowResult(rule, reply[0] == 1, reply[1], reply[2], elapsed), nil
+
+	default:
+		index, elapsed := currentWindow(now, rule.Window)
+		reply, err := fixedWindowScript.Run(ctx, s.client, []string{windowKey(key, index)},
+			rule.Max, (rule.Window-elapsed).Milliseconds()+1).Int64Slice()
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to count request in Redis: %w", err)
+		}
+		return fixedWindowResult(rule, reply[0] == 1, reply[1], elapsed), nil
+	}
+}
+
+func (s *RedisStore) Peek(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := s.now()
+
+	switch rule.Algorithm {
+	case TokenBucket:
+		bucket, err := s.client.HMGet(ctx, bucketKey(key), "tokens", "last").Result()
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read rate limit from Redis: %w", err)
+		}
+		tokens := float64(rule.Max)
+		if bucket[0] != nil && bucket[1] != nil {
+			stored, _ := strconv.ParseFloat(fmt.Sprint(bucket[0]), 64)
+			last, _ := strconv.ParseInt(fmt.Sprint(bucket[1]), 10, 64)
+			tokens = refill(rule, stored, time.UnixMilli(last), now)
+		}
+		return tokenBucketResult(rule, tokens >= 1, tokens), nil
+
+	case SlidingWindow:
+		index, elapsed := currentWindow(now, rule.Window)
+		counts, err := s.counts(ctx, windowKey(key, index), windowKey(key, index-1))
+		if err != nil {
+			return Result{}, err
+		}
+		current, previous := counts[0], counts[1]
+		allowed := slidingEstimate(previous, current+1, elapsed, rule.Window) <= float64(rule.Max)
+		return slidingWindowResult(rule, allowed, previous, current, elapsed), nil
+
+	default:
+		index, elapsed := currentWindow(now, rule.Window)
+		counts, err := s.counts(ctx, windowKey(key, index))
+		if err != nil {
+			return Result{}, err
+		}
+		return fixedWindowResult(rule, counts[0] < int64(rule.Max), counts[0], elapsed), nil
+	}
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string, rule Rule) error {
+	if err := s.client.Del(ctx, counterKeys(key, rule, s.now())...).Err(); err != nil {
+		return fmt.Errorf("failed to reset rate limit in Redis: %w", err)
+	}
+	return nil
+}
+
+// Close does nothing, the client is shared with the cache and closed by its
+// owner
+func (s *RedisStore) Close() error {
+	return nil
+}
+
+// counts reads window counters, missing ones count zero
+func (s *RedisStore) counts(ctx context.Context, keys ...string) ([]int64, error) {
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit from Redis: %w", err)
+	}
+	counts := make([]int64, len(values))
+	for i, value := range values {
+		if value != nil {
+			counts[i], _ = strconv.ParseInt(fmt.Sprint(value), 10, 64)
+		}
+	}
+	return counts, nil
+}
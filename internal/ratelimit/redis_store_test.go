@@ -0,0 +1,44 @@
+//go:build redis
+
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisStore runs against the Redis at REDIS_URL, e.g.
+// REDIS_URL=redis://localhost:6379/15 go test -tags redis ./internal/ratelimit/
+// The keys it uses are deleted before and after every test.
+func TestRedisStore(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+	options, err := redis.ParseURL(url)
+	require.NoError(t, err)
+
+	testStore(t, func(t *testing.T, clock *testClock) Store {
+		client := redis.NewClient(options)
+		store := NewRedisStore(client)
+		store.now = clock.Now
+
+		clean := func() {
+			keys, err := client.Keys(context.Background(), counterKey("orders", "*")).Result()
+			require.NoError(t, err)
+			if len(keys) > 0 {
+				require.NoError(t, client.Del(context.Background(), keys...).Err())
+			}
+		}
+		clean()
+		t.Cleanup(func() {
+			clean()
+			client.Close()
+		})
+		return store
+	})
+}
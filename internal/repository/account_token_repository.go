@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AccountTokenRepository interface {
+	Create(ctx context.Context, token *entity.AccountToken) error
+	GetByHash(ctx context.Context, hash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error)
+	// Consume marks the token used, it fails with ErrInvalidToken when the
+	// token was already used so it can only succeed once
+	Consume(ctx context.Context, id int64) error
+	// InvalidateForCustomer marks every unused token of a purpose as used
+	InvalidateForCustomer(ctx context.Context, customerID int64, purpose entity.AccountTokenPurpose) error
+}
+
+type accountTokenRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewAccountTokenRepository(db *gorm.DB, logger *logrus.Logger) AccountTokenRepository {
+	return &accountTokenRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *accountTokenRepository) Create(ctx context.Context, token *entity.AccountToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		r.logger.Errorf("Error creating account token: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *accountTokenRepository) GetByHash(ctx context.Context, hash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error) {
+	var token entity.AccountToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ? AND purpose = ?", hash, purpose).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting account token: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accountTokenRepository) Consume(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Model(&entity.AccountToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.Errorf("Error consuming account token: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrInvalidToken
+	}
+	return nil
+}
+
+func (r *accountTokenRepository) InvalidateForCustomer(ctx context.Context, customerID int64, purpose entity.AccountTokenPurpose) error {
+	if err := r.db.WithContext(ctx).Model(&entity.AccountToken{}).
+		Where("customer_id = ? AND purpose = ? AND used_at IS NULL", customerID, purpose).
+		Update("used_at", time.Now()).Error; err != nil {
+		r.logger.Errorf("Error invalidating account tokens: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// auditExportBatchSize is how many entries Each loads at a time
+const auditExportBatchSize = 500
+
+// AuditRepository only appends and reads, audit entries are never changed
+type AuditRepository interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+	GetAll(ctx context.Context, params *model.AuditLogQueryParams) (*model.PaginationResponse[[]entity.AuditLog], error)
+	// Each calls fn with every matching entry in batches, oldest first
+	Each(ctx context.Context, params *model.AuditLogQueryParams, fn func(logs []entity.AuditLog) error) error
+}
+
+type auditRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewAuditRepository(db *gorm.DB, logger *logrus.Logger) AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	if err := r.db.WithContext(ctx).Create(log).Error; err != nil {
+		r.logger.Errorf("Error creating audit log: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *auditRepository) GetAll(ctx context.Context, params *model.AuditLogQueryParams) (*model.PaginationResponse[[]entity.AuditLog], error) {
+	var logs []entity.AuditLog
+	var total int64
+
+	query := r.filter(ctx, params)
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Errorf("Error counting audit logs: %v", err)
+		return nil, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Order("created_at DESC, id DESC").Offset(int(offset)).Limit(int(params.Limit)).Find(&logs).Error; err != nil {
+		r.logger.Errorf("Error getting audit logs: %v", err)
+		return nil, err
+	}
+
+	return &model.PaginationResponse[[]entity.AuditLog]{
+		Data:       logs,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.Limit,
+		TotalPages: (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}, nil
+}
+
+func (r *auditRepository) Each(ctx context.Context, params *model.AuditLogQueryParams, fn func(logs []entity.AuditLog) error) error {
+	var batch []entity.AuditLog
+	result := r.filter(ctx, params).FindInBatches(&batch, auditExportBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	if result.Error != nil {
+		r.logger.Errorf("Error reading audit logs: %v", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+func (r *auditRepository) filter(ctx context.Context, params *model.AuditLogQueryParams) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&entity.AuditLog{})
+	if params.ActorID != 0 {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+	if params.ActorRole != "" {
+		query = query.Where("actor_role = ?", params.ActorRole)
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.EntityType != "" {
+		query = query.Where("entity_type = ?", params.EntityType)
+	}
+	if params.EntityID != "" {
+		query = query.Where("entity_id = ?", params.EntityID)
+	}
+	if params.RequestID != "" {
+		query = query.Where("request_id = ?", params.RequestID)
+	}
+	if !params.From.IsZero() {
+		query = query.Where("created_at >= ?", params.From)
+	}
+	if !params.To.IsZero() {
+		query = query.Where("created_at < ?", params.To)
+	}
+	return query
+}
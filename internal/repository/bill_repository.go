@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BillRepository interface {
+	// ReplaceSplit stores a new split for the order, discarding any previous one
+	ReplaceSplit(ctx context.Context, split *entity.BillSplit) error
+	GetSplitByOrderID(ctx context.Context, orderID int64) (*entity.BillSplit, error)
+	UpdateShareStatus(ctx context.Context, shareID int64, status entity.ShareStatus) error
+	// AddPayment records a payment while holding a lock on the order, allow
+	// sees the order, its split and its payments as they are under the lock
+	// and can reject or adjust the payment
+	AddPayment(ctx context.Context, payment *entity.Payment, allow func(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) error) error
+}
+
+type billRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewBillRepository(db *gorm.DB, logger *logrus.Logger) BillRepository {
+	return &billRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *billRepository) ReplaceSplit(ctx context.Context, split *entity.BillSplit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("order_id = ?", split.OrderID).Delete(&entity.BillShare{}).Error; err != nil {
+			r.logger.Errorf("Error deleting previous bill shares: %v", err)
+			return err
+		}
+		if err := tx.Where("order_id = ?", split.OrderID).Delete(&entity.BillSplit{}).Error; err != nil {
+			r.logger.Errorf("Error deleting previous bill split: %v", err)
+			return err
+		}
+		if err := tx.Create(split).Error; err != nil {
+			r.logger.Errorf("Error creating bill split: %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *billRepository) GetSplitByOrderID(ctx context.Context, orderID int64) (*entity.BillSplit, error) {
+	var split entity.BillSplit
+	if err := r.db.WithContext(ctx).Preload("Shares", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("order_id = ?", orderID).First(&split).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting bill split by order ID: %v", err)
+		return nil, err
+	}
+	return &split, nil
+}
+
+func (r *billRepository) UpdateShareStatus(ctx context.Context, shareID int64, status entity.ShareStatus) error {
+	result := r.db.WithContext(ctx).Model(&entity.BillShare{}).Where("id = ?", shareID).Update("status", status)
+	if result.Error != nil {
+		r.logger.Errorf("Error updating bill share status: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *billRepository) AddPayment(ctx context.Context, payment *entity.Payment, allow func(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order entity.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, payment.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return constants.ErrNotFound
+			}
+			r.logger.Errorf("Error locking order %d: %v", payment.OrderID, err)
+			return err
+		}
+
+		var split *entity.BillSplit
+		var found entity.BillSplit
+		err := tx.Preload("Shares", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).Where("order_id = ?", payment.OrderID).First(&found).Error
+		switch {
+		case err == nil:
+			split = &found
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			r.logger.Errorf("Error getting bill split by order ID: %v", err)
+			return err
+		}
+
+		var payments []entity.Payment
+		if err := tx.Where("order_id = ?", payment.OrderID).Order("created_at ASC").Find(&payments).Error; err != nil {
+			r.logger.Errorf("Error getting payments by order ID: %v", err)
+			return err
+		}
+
+		if err := allow(&order, split, payments); err != nil {
+			return err
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			r.logger.Errorf("Error creating payment: %v", err)
+			return err
+		}
+		return nil
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"cakestore/internal/constants"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -12,15 +14,18 @@ import (
 )
 
 type CustomerRepository interface {
-	Create(customer *entity.Customer) error
-	GetByID(id int64) (*entity.Customer, error)
-	GetByEmail(email string) (*entity.Customer, error)
-	Update(customer *entity.Customer) error
-	Delete(id int64) error
-	GetEmployees() ([]entity.Customer, error)
-	GetEmployeeByID(id int64) (*entity.Customer, error)
-	UpdateEmployee(id int64, request *model.UpdateUserRequest, role string) error
-	DeleteEmployee(id int64) error
+	Create(ctx context.Context, customer *entity.Customer) error
+	GetByID(ctx context.Context, id int64) (*entity.Customer, error)
+	GetByEmail(ctx context.Context, email string) (*entity.Customer, error)
+	Update(ctx context.Context, customer *entity.Customer) error
+	Delete(ctx context.Context, id int64) error
+	GetEmployees(ctx context.Context) ([]entity.Customer, error)
+	GetEmployeeByID(ctx context.Context, id int64) (*entity.Customer, error)
+	UpdateEmployee(ctx context.Context, id int64, request *model.UpdateUserRequest, role string) error
+	DeleteEmployee(ctx context.Context, id int64) error
+	IncrementNoShows(ctx context.Context, id int64) error
+	// IncrementTokenVersion invalidates every access token issued to the customer
+	IncrementTokenVersion(ctx context.Context, id int64) (int, error)
 }
 
 type customerRepository struct {
@@ -35,9 +40,9 @@ func NewCustomerRepository(db *gorm.DB, logger *logrus.Logger) CustomerRepositor
 	}
 }
 
-func (r *customerRepository) GetEmployees() ([]entity.Customer, error) {
+func (r *customerRepository) GetEmployees(ctx context.Context) ([]entity.Customer, error) {
 	var customer []entity.Customer
-	if err := r.db.Where("role != ?", "customer").Find(&customer).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("role != ?", "customer").Find(&customer).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("employee not found")
 		}
@@ -47,9 +52,9 @@ func (r *customerRepository) GetEmployees() ([]entity.Customer, error) {
 	return customer, nil
 }
 
-func (r *customerRepository) GetEmployeeByID(id int64) (*entity.Customer, error) {
+func (r *customerRepository) GetEmployeeByID(ctx context.Context, id int64) (*entity.Customer, error) {
 	var customer entity.Customer
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Where("id = ? AND role != ?", id, "customer").
 		First(&customer).Error; err != nil {
 
@@ -62,8 +67,8 @@ func (r *customerRepository) GetEmployeeByID(id int64) (*entity.Customer, error)
 	return &customer, nil
 }
 
-func (r *customerRepository) UpdateEmployee(id int64, request *model.UpdateUserRequest, role string) error {
-	customer, err := r.GetEmployeeByID(id)
+func (r *customerRepository) UpdateEmployee(ctx context.Context, id int64, request *model.UpdateUserRequest, role string) error {
+	customer, err := r.GetEmployeeByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -77,15 +82,15 @@ func (r *customerRepository) UpdateEmployee(id int64, request *model.UpdateUserR
 		customer.Role = role
 
 	}
-	if err := r.db.Save(customer).Error; err != nil {
+	if err := r.db.WithContext(ctx).Save(customer).Error; err != nil {
 		r.logger.Errorf("Error updating employee: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *customerRepository) DeleteEmployee(id int64) error {
-	result := r.db.Delete(&entity.Customer{}, id)
+func (r *customerRepository) DeleteEmployee(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.Customer{}, id)
 	if result.Error != nil {
 		r.logger.Errorf("Error deleting employee: %v", result.Error)
 		return result.Error
@@ -96,19 +101,19 @@ func (r *customerRepository) DeleteEmployee(id int64) error {
 	return nil
 }
 
-func (r *customerRepository) Create(customer *entity.Customer) error {
-	if err := r.db.Create(customer).Error; err != nil {
+func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) error {
+	if err := r.db.WithContext(ctx).Create(customer).Error; err != nil {
 		r.logger.Errorf("Error creating customer: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *customerRepository) GetByID(id int64) (*entity.Customer, error) {
+func (r *customerRepository) GetByID(ctx context.Context, id int64) (*entity.Customer, error) {
 	var customer entity.Customer
-	if err := r.db.First(&customer, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&customer, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("customer not found")
+			return nil, fmt.Errorf("customer %w", constants.ErrNotFound)
 		}
 		r.logger.Errorf("Error getting customer by ID: %v", err)
 		return nil, err
@@ -116,9 +121,9 @@ func (r *customerRepository) GetByID(id int64) (*entity.Customer, error) {
 	return &customer, nil
 }
 
-func (r *customerRepository) GetByEmail(email string) (*entity.Customer, error) {
+func (r *customerRepository) GetByEmail(ctx context.Context, email string) (*entity.Customer, error) {
 	var customer entity.Customer
-	if err := r.db.Where("email = ?", email).First(&customer).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&customer).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("customer not found")
 		}
@@ -128,16 +133,16 @@ func (r *customerRepository) GetByEmail(email string) (*entity.Customer, error)
 	return &customer, nil
 }
 
-func (r *customerRepository) Update(customer *entity.Customer) error {
-	if err := r.db.Save(customer).Error; err != nil {
+func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) error {
+	if err := r.db.WithContext(ctx).Save(customer).Error; err != nil {
 		r.logger.Errorf("Error updating customer: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *customerRepository) Delete(id int64) error {
-	result := r.db.Delete(&entity.Customer{}, id)
+func (r *customerRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.Customer{}, id)
 	if result.Error != nil {
 		r.logger.Errorf("Error deleting customer: %v", result.Error)
 		return result.Error
@@ -147,3 +152,31 @@ func (r *customerRepository) Delete(id int64) error {
 	}
 	return nil
 }
+
+func (r *customerRepository) IncrementNoShows(ctx context.Context, id int64) error {
+	if err := r.db.WithContext(ctx).Model(&entity.Customer{}).Where("id = ?", id).
+		UpdateColumn("no_show_count", gorm.Expr("no_show_count + 1")).Error; err != nil {
+		r.logger.Errorf("Error recording no-show for customer: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *customerRepository) IncrementTokenVersion(ctx context.Context, id int64) (int, error) {
+	var customer entity.Customer
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Customer{}).Where("id = ?", id).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Select("token_version").First(&customer, id).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error incrementing token version: %v", err)
+		return 0, err
+	}
+	return customer.TokenVersion, nil
+}
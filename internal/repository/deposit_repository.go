@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DepositRepository interface {
+	GetRules(ctx context.Context) ([]entity.DepositRule, error)
+	GetActiveRules(ctx context.Context) ([]entity.DepositRule, error)
+	GetRuleByID(ctx context.Context, id int64) (*entity.DepositRule, error)
+	CreateRule(ctx context.Context, rule *entity.DepositRule) error
+	UpdateRule(ctx context.Context, rule *entity.DepositRule) error
+	DeleteRule(ctx context.Context, id int64) error
+
+	CreateDeposit(ctx context.Context, deposit *entity.ReservationDeposit) error
+	GetDepositByReservationID(ctx context.Context, reservationID uint) (*entity.ReservationDeposit, error)
+	GetDepositByExternalID(ctx context.Context, externalID string) (*entity.ReservationDeposit, error)
+	// TransitionDeposit moves the deposit from the status from to its Status,
+	// saving PaidAt and AppliedOrderID with it. It reports false, changing
+	// nothing, when the deposit is no longer in from.
+	TransitionDeposit(ctx context.Context, deposit *entity.ReservationDeposit, from entity.DepositStatus) (bool, error)
+	// GetExpiredDeposits returns pending deposits whose payment window closed before now
+	GetExpiredDeposits(ctx context.Context, now time.Time) ([]entity.ReservationDeposit, error)
+}
+
+type depositRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewDepositRepository(db *gorm.DB, logger *logrus.Logger) DepositRepository {
+	return &depositRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *depositRepository) GetRules(ctx context.Context) ([]entity.DepositRule, error) {
+	var rules []entity.DepositRule
+	if err := r.db.WithContext(ctx).Order("min_guests ASC, id ASC").Find(&rules).Error; err != nil {
+		r.logger.Errorf("Error getting deposit rules: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *depositRepository) GetActiveRules(ctx context.Context) ([]entity.DepositRule, error) {
+	var rules []entity.DepositRule
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&rules).Error; err != nil {
+		r.logger.Errorf("Error getting active deposit rules: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *depositRepository) GetRuleByID(ctx context.Context, id int64) (*entity.DepositRule, error) {
+	var rule entity.DepositRule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting deposit rule by ID: %v", err)
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *depositRepository) CreateRule(ctx context.Context, rule *entity.DepositRule) error {
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		r.logger.Errorf("Error creating deposit rule: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *depositRepository) UpdateRule(ctx context.Context, rule *entity.DepositRule) error {
+	if err := r.db.WithContext(ctx).Save(rule).Error; err != nil {
+		r.logger.Errorf("Error updating deposit rule: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *depositRepository) DeleteRule(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.DepositRule{}, id)
+	if result.Error != nil {
+		r.logger.Errorf("Error deleting deposit rule: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *depositRepository) CreateDeposit(ctx context.Context, deposit *entity.ReservationDeposit) error {
+	if err := r.db.WithContext(ctx).Create(deposit).Error; err != nil {
+		r.logger.Errorf("Error creating reservation deposit: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *depositRepository) GetDepositByReservationID(ctx context.Context, reservationID uint) (*entity.ReservationDeposit, error) {
+	var deposit entity.ReservationDeposit
+	if err := r.db.WithContext(ctx).Where("reservation_id = ?", reservationID).First(&deposit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting deposit by reservation ID: %v", err)
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+func (r *depositRepository) GetDepositByExternalID(ctx context.Context, externalID string) (*entity.ReservationDeposit, error) {
+	var deposit entity.ReservationDeposit
+	if err := r.db.WithContext(ctx).Where("external_id = ?", externalID).First(&deposit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting deposit by external ID: %v", err)
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+func (r *depositRepository) TransitionDeposit(ctx context.Context, deposit *entity.ReservationDeposit, from entity.DepositStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.ReservationDeposit{}).
+		Where("id = ? AND status = ?", deposit.ID, from).
+		Updates(map[string]any{
+			"status":           deposit.Status,
+			"paid_at":          deposit.PaidAt,
+			"applied_order_id": deposit.AppliedOrderID,
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Errorf("Error updating reservation deposit: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *depositRepository) GetExpiredDeposits(ctx context.Context, now time.Time) ([]entity.ReservationDeposit, error) {
+	var deposits []entity.ReservationDeposit
+	if err := r.db.WithContext(ctx).Where("status = ? AND expires_at < ?", entity.DepositStatusPending, now).Find(&deposits).Error; err != nil {
+		r.logger.Errorf("Error getting expired deposits: %v", err)
+		return nil, err
+	}
+	return deposits, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmployeeRepository stores employee profiles and their weekly shifts, the
+// employee accounts themselves live in the customers table
+type EmployeeRepository interface {
+	// CreateWithProfile creates the employee account and profile together
+	CreateWithProfile(ctx context.Context, employee *entity.Customer, profile *entity.EmployeeProfile) error
+	GetProfile(ctx context.Context, customerID int64) (*entity.EmployeeProfile, error)
+	GetProfiles(ctx context.Context, customerIDs []int64) ([]entity.EmployeeProfile, error)
+	// SaveProfile creates or replaces the profile of an employee
+	SaveProfile(ctx context.Context, profile *entity.EmployeeProfile) error
+
+	GetShifts(ctx context.Context, params *model.ShiftQueryParams) ([]entity.Shift, error)
+	GetShiftByID(ctx context.Context, id int64) (*entity.Shift, error)
+	CreateShift(ctx context.Context, shift *entity.Shift) error
+	UpdateShift(ctx context.Context, shift *entity.Shift) error
+	DeleteShift(ctx context.Context, id int64) error
+}
+
+type employeeRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewEmployeeRepository(db *gorm.DB, logger *logrus.Logger) EmployeeRepository {
+	return &employeeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *employeeRepository) CreateWithProfile(ctx context.Context, employee *entity.Customer, profile *entity.EmployeeProfile) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(employee).Error; err != nil {
+			return err
+		}
+		profile.CustomerID = employee.ID
+		return tx.Omit("Customer").Create(profile).Error
+	})
+	if err != nil {
+		r.logger.Errorf("Error creating employee: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *employeeRepository) GetProfile(ctx context.Context, customerID int64) (*entity.EmployeeProfile, error) {
+	var profile entity.EmployeeProfile
+	if err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting employee profile: %v", err)
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *employeeRepository) GetProfiles(ctx context.Context, customerIDs []int64) ([]entity.EmployeeProfile, error) {
+	var profiles []entity.EmployeeProfile
+	if len(customerIDs) == 0 {
+		return profiles, nil
+	}
+	if err := r.db.WithContext(ctx).Where("customer_id IN ?", customerIDs).Find(&profiles).Error; err != nil {
+		r.logger.Errorf("Error getting employee profiles: %v", err)
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (r *employeeRepository) SaveProfile(ctx context.Context, profile *entity.EmployeeProfile) error {
+	err := r.db.WithContext(ctx).Omit("Customer").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"phone", "hire_date", "hourly_rate", "invited_at", "onboarded_at", "updated_at"}),
+	}).Create(profile).Error
+	if err != nil {
+		r.logger.Errorf("Error saving employee profile: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *employeeRepository) GetShifts(ctx context.Context, params *model.ShiftQueryParams) ([]entity.Shift, error) {
+	query := r.db.WithContext(ctx).Preload("Employee")
+	if params.EmployeeID != 0 {
+		query = query.Where("employee_id = ?", params.EmployeeID)
+	}
+	if params.Role != "" {
+		query = query.Where("role = ?", params.Role)
+	}
+
+	var shifts []entity.Shift
+	if err := query.Order("weekday ASC, start_minute ASC, id ASC").Find(&shifts).Error; err != nil {
+		r.logger.Errorf("Error getting shifts: %v", err)
+		return nil, err
+	}
+	return shifts, nil
+}
+
+func (r *employeeRepository) GetShiftByID(ctx context.Context, id int64) (*entity.Shift, error) {
+	var shift entity.Shift
+	if err := r.db.WithContext(ctx).Preload("Employee").First(&shift, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting shift: %v", err)
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (r *employeeRepository) CreateShift(ctx context.Context, shift *entity.Shift) error {
+	if err := r.db.WithContext(ctx).Omit("Employee").Create(shift).Error; err != nil {
+		r.logger.Errorf("Error creating shift: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *employeeRepository) UpdateShift(ctx context.Context, shift *entity.Shift) error {
+	if err := r.db.WithContext(ctx).Omit("Employee").Save(shift).Error; err != nil {
+		r.logger.Errorf("Error updating shift: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *employeeRepository) DeleteShift(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.Shift{}, id)
+	if result.Error != nil {
+		r.logger.Errorf("Error deleting shift: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type FloorRepository interface {
+	CreateArea(ctx context.Context, area *entity.FloorArea) error
+	GetAreas(ctx context.Context) ([]entity.FloorArea, error)
+	GetAreaByID(ctx context.Context, id int64) (*entity.FloorArea, error)
+	UpdateArea(ctx context.Context, area *entity.FloorArea) error
+	DeleteArea(ctx context.Context, id int64) error
+
+	CreateSection(ctx context.Context, section *entity.FloorSection) error
+	GetSectionByID(ctx context.Context, id int64) (*entity.FloorSection, error)
+	UpdateSection(ctx context.Context, section *entity.FloorSection) error
+	// DeleteSection removes the section and its staff, tables in it become unassigned
+	DeleteSection(ctx context.Context, id int64) error
+	ReplaceSectionStaff(ctx context.Context, sectionID int64, employeeIDs []int64) error
+
+	GetOpenSessions(ctx context.Context) ([]entity.TableSession, error)
+	OpenSession(ctx context.Context, session *entity.TableSession) error
+	CloseSession(ctx context.Context, tableID int64, closedAt time.Time) error
+}
+
+type floorRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewFloorRepository(db *gorm.DB, logger *logrus.Logger) FloorRepository {
+	return &floorRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *floorRepository) CreateArea(ctx context.Context, area *entity.FloorArea) error {
+	if err := r.db.WithContext(ctx).Create(area).Error; err != nil {
+		r.logger.Errorf("Error creating floor area: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *floorRepository) GetAreas(ctx context.Context) ([]entity.FloorArea, error) {
+	var areas []entity.FloorArea
+	if err := r.db.WithContext(ctx).
+		Preload("Sections", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		}).
+		Preload("Sections.Staff.Employee").
+		Order("sort_order ASC, name ASC").
+		Find(&areas).Error; err != nil {
+		r.logger.Errorf("Error getting floor areas: %v", err)
+		return nil, err
+	}
+	return areas, nil
+}
+
+func (r *floorRepository) GetAreaByID(ctx context.Context, id int64) (*entity.FloorArea, error) {
+	var area entity.FloorArea
+	if err := r.db.WithContext(ctx).Preload("Sections").First(&area, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting floor area by ID: %v", err)
+		return nil, err
+	}
+	return &area, nil
+}
+
+func (r *floorRepository) UpdateArea(ctx context.Context, area *entity.FloorArea) error {
+	if err := r.db.WithContext(ctx).Omit("Sections").Save(area).Error; err != nil {
+		r.logger.Errorf("Error updating floor area: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *floorRepository) DeleteArea(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.FloorArea{}, id)
+	if result.Error != nil {
+		r.logger.Errorf("Error deleting floor area: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *floorRepository) CreateSection(ctx context.Context, section *entity.FloorSection) error {
+	if err := r.db.WithContext(ctx).Create(section).Error; err != nil {
+		r.logger.Errorf("Error creating floor section: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *floorRepository) GetSectionByID(ctx context.Context, id int64) (*entity.FloorSection, error) {
+	var section entity.FloorSection
+	if err := r.db.WithContext(ctx).Preload("Staff.Employee").First(&section, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting floor section by ID: %v", err)
+		return nil, err
+	}
+	return &section, nil
+}
+
+func (r *floorRepository) UpdateSection(ctx context.Context, section *entity.FloorSection) error {
+	if err := r.db.WithContext(ctx).Omit("Staff").Save(section).Error; err != nil {
+		r.logger.Errorf("Error updating floor section: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *floorRepository) DeleteSection(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Table{}).Where("section_id = ?", id).Update("section_id", nil).Error; err != nil {
+			r.logger.Errorf("Error unassigning tables from section: %v", err)
+			return err
+		}
+		if err := tx.Where("section_id = ?", id).Delete(&entity.SectionStaff{}).Error; err != nil {
+			r.logger.Errorf("Error deleting section staff: %v", err)
+			return err
+		}
+		result := tx.Delete(&entity.FloorSection{}, id)
+		if result.Error != nil {
+			r.logger.Errorf("Error deleting floor section: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constants.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *floorRepository) ReplaceSectionStaff(ctx context.Context, sectionID int64, employeeIDs []int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("section_id = ?", sectionID).Delete(&entity.SectionStaff{}).Error; err != nil {
+			r.logger.Errorf("Error clearing section staff: %v", err)
+			return err
+		}
+		if len(employeeIDs) == 0 {
+			return nil
+		}
+		staff := make([]entity.SectionStaff, len(employeeIDs))
+		for i, employeeID := range employeeIDs {
+			staff[i] = entity.SectionStaff{SectionID: sectionID, EmployeeID: employeeID}
+		}
+		if err := tx.Create(&staff).Error; err != nil {
+			r.logger.Errorf("Error assigning section staff: %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *floorRepository) GetOpenSessions(ctx context.Context) ([]entity.TableSession, error) {
+	var sessions []entity.TableSession
+	if err := r.db.WithContext(ctx).Where("closed_at IS NULL").Find(&sessions).Error; err != nil {
+		r.logger.Errorf("Error getting open table sessions: %v", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *floorRepository) OpenSession(ctx context.Context, session *entity.TableSession) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		r.logger.Errorf("Error opening table session: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *floorRepository) CloseSession(ctx context.Context, tableID int64, closedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entity.TableSession{}).
+		Where("table_id = ? AND closed_at IS NULL", tableID).
+		Update("closed_at", closedAt).Error; err != nil {
+		r.logger.Errorf("Error closing table session: %v", err)
+		return err
+	}
+	return nil
+}
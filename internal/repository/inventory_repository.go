@@ -3,20 +3,21 @@ package repository
 import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type InventoryRepository interface {
-	Create(ingredient *entity.Inventory) error
-	GetByID(id uint) (*entity.Inventory, error)
-	GetAll(params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error)
-	Update(ingredient *entity.Inventory) error
-	Delete(id uint) error
-	UpdateStock(id uint, quantity float64) error
-	GetLowStockIngredients() ([]entity.Inventory, error)
-	Count() (int64, error)
+	Create(ctx context.Context, ingredient *entity.Inventory) error
+	GetByID(ctx context.Context, id uint) (*entity.Inventory, error)
+	GetAll(ctx context.Context, params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error)
+	Update(ctx context.Context, ingredient *entity.Inventory) error
+	Delete(ctx context.Context, id uint) error
+	UpdateStock(ctx context.Context, id uint, quantity float64) error
+	GetLowStockIngredients(ctx context.Context) ([]entity.Inventory, error)
+	Count(ctx context.Context) (int64, error)
 }
 
 type inventoryRepository struct {
@@ -31,31 +32,31 @@ func NewInventoryRepository(db *gorm.DB, logger *logrus.Logger) InventoryReposit
 	}
 }
 
-func (r *inventoryRepository) Count() (int64, error) {
+func (r *inventoryRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.Model(&entity.Inventory{}).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Inventory{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *inventoryRepository) Create(ingredient *entity.Inventory) error {
-	return r.db.Create(ingredient).Error
+func (r *inventoryRepository) Create(ctx context.Context, ingredient *entity.Inventory) error {
+	return r.db.WithContext(ctx).Create(ingredient).Error
 }
 
-func (r *inventoryRepository) GetByID(id uint) (*entity.Inventory, error) {
+func (r *inventoryRepository) GetByID(ctx context.Context, id uint) (*entity.Inventory, error) {
 	var ingredient entity.Inventory
-	if err := r.db.First(&ingredient, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&ingredient, id).Error; err != nil {
 		return nil, err
 	}
 	return &ingredient, nil
 }
 
-func (r *inventoryRepository) GetAll(params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error) {
+func (r *inventoryRepository) GetAll(ctx context.Context, params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error) {
 	var ingredients []entity.Inventory
 	var total int64
 
-	query := r.db.Model(&entity.Inventory{})
+	query := r.db.WithContext(ctx).Model(&entity.Inventory{})
 
 	if params.Search != "" {
 		query = query.Where("name ILIKE ?", "%"+params.Search+"%")
@@ -80,21 +81,21 @@ func (r *inventoryRepository) GetAll(params *model.InventoryQueryParams) (*model
 	}, nil
 }
 
-func (r *inventoryRepository) Update(ingredient *entity.Inventory) error {
-	return r.db.Save(ingredient).Error
+func (r *inventoryRepository) Update(ctx context.Context, ingredient *entity.Inventory) error {
+	return r.db.WithContext(ctx).Save(ingredient).Error
 }
 
-func (r *inventoryRepository) Delete(id uint) error {
-	return r.db.Delete(&entity.Inventory{}, id).Error
+func (r *inventoryRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entity.Inventory{}, id).Error
 }
 
-func (r *inventoryRepository) UpdateStock(id uint, quantity float64) error {
-	return r.db.Model(&entity.Inventory{}).Where("id = ?", id).UpdateColumn("quantity", gorm.Expr("quantity + ?", quantity)).Error
+func (r *inventoryRepository) UpdateStock(ctx context.Context, id uint, quantity float64) error {
+	return r.db.WithContext(ctx).Model(&entity.Inventory{}).Where("id = ?", id).UpdateColumn("quantity", gorm.Expr("quantity + ?", quantity)).Error
 }
 
-func (r *inventoryRepository) GetLowStockIngredients() ([]entity.Inventory, error) {
+func (r *inventoryRepository) GetLowStockIngredients(ctx context.Context) ([]entity.Inventory, error) {
 	var ingredients []entity.Inventory
-	if err := r.db.Where("quantity <= minimum_stock").Find(&ingredients).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("quantity <= minimum_stock").Find(&ingredients).Error; err != nil {
 		return nil, err
 	}
 	return ingredients, nil
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginRepository interface {
+	CreateEvent(ctx context.Context, event *entity.LoginEvent) error
+	// GetEvents returns the most recent login attempts of the customer
+	GetEvents(ctx context.Context, customerID int64, limit int) ([]entity.LoginEvent, error)
+	// HasLoggedInFrom reports whether the customer ever logged in successfully
+	// from the IP address with the user agent in the same login, and whether
+	// they ever logged in at all
+	HasLoggedInFrom(ctx context.Context, customerID int64, ipAddress, userAgent string) (seen bool, any bool, err error)
+
+	GetLockout(ctx context.Context, customerID int64) (*entity.AccountLockout, error)
+	// RecordFailure counts a failed login and returns the failures in a row,
+	// failures older than window are forgotten
+	RecordFailure(ctx context.Context, customerID int64, window time.Duration) (int, error)
+	Lock(ctx context.Context, customerID int64, until time.Time) error
+	// Unlock clears the failed attempts and any lock
+	Unlock(ctx context.Context, customerID int64) error
+}
+
+type loginRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewLoginRepository(db *gorm.DB, logger *logrus.Logger) LoginRepository {
+	return &loginRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loginRepository) CreateEvent(ctx context.Context, event *entity.LoginEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		r.logger.Errorf("Error recording login event: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *loginRepository) GetEvents(ctx context.Context, customerID int64, limit int) ([]entity.LoginEvent, error) {
+	var events []entity.LoginEvent
+	if err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).
+		Order("created_at DESC").Limit(limit).Find(&events).Error; err != nil {
+		r.logger.Errorf("Error getting login events: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *loginRepository) HasLoggedInFrom(ctx context.Context, customerID int64, ipAddress, userAgent string) (bool, bool, error) {
+	var total, seen int64
+	if err := r.db.WithContext(ctx).Model(&entity.LoginEvent{}).
+		Where("customer_id = ? AND success = ?", customerID, true).
+		Count(&total).Error; err != nil {
+		r.logger.Errorf("Error counting logins: %v", err)
+		return false, false, err
+	}
+	if total == 0 {
+		return false, false, nil
+	}
+
+	if err := r.db.WithContext(ctx).Model(&entity.LoginEvent{}).
+		Where("customer_id = ? AND success = ?", customerID, true).
+		Where("ip_address = ? AND user_agent = ?", ipAddress, userAgent).
+		Count(&seen).Error; err != nil {
+		r.logger.Errorf("Error counting logins from client: %v", err)
+		return false, true, err
+	}
+	return seen > 0, true, nil
+}
+
+func (r *loginRepository) GetLockout(ctx context.Context, customerID int64) (*entity.AccountLockout, error) {
+	var lockout entity.AccountLockout
+	if err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&lockout).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting account lockout: %v", err)
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+func (r *loginRepository) RecordFailure(ctx context.Context, customerID int64, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		lockout := entity.AccountLockout{CustomerID: customerID}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ?", customerID).
+			FirstOrCreate(&lockout).Error; err != nil {
+			return err
+		}
+
+		failures = lockout.FailedAttempts + 1
+		if lockout.LastFailedAt != nil && now.Sub(*lockout.LastFailedAt) > window {
+			failures = 1
+		}
+		return tx.Model(&entity.AccountLockout{}).Where("customer_id = ?", customerID).
+			Updates(map[string]interface{}{"failed_attempts": failures, "last_failed_at": now}).Error
+	})
+	if err != nil {
+		r.logger.Errorf("Error recording failed login: %v", err)
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (r *loginRepository) Lock(ctx context.Context, customerID int64, until time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entity.AccountLockout{}).Where("customer_id = ?", customerID).
+		Update("locked_until", until).Error; err != nil {
+		r.logger.Errorf("Error locking account: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *loginRepository) Unlock(ctx context.Context, customerID int64) error {
+	if err := r.db.WithContext(ctx).Model(&entity.AccountLockout{}).Where("customer_id = ?", customerID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error; err != nil {
+		r.logger.Errorf("Error unlocking account: %v", err)
+		return err
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/constants"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"time"
 
@@ -12,12 +13,12 @@ import (
 )
 
 type MenuRepository interface {
-	GetAll(params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error)
-	GetByID(id int64) (*entity.Menu, error)
-	Create(menu *entity.Menu) error
-	UpdateMenu(menu *entity.Menu) error
-	SoftDelete(id int64) error
-	DecreaseStock(menuID int64, quantity int) error
+	GetAll(ctx context.Context, params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error)
+	GetByID(ctx context.Context, id int64) (*entity.Menu, error)
+	Create(ctx context.Context, menu *entity.Menu) error
+	UpdateMenu(ctx context.Context, menu *entity.Menu) error
+	SoftDelete(ctx context.Context, id int64) error
+	DecreaseStock(ctx context.Context, menuID int64, quantity int) error
 }
 
 type menuRepository struct {
@@ -29,11 +30,11 @@ func NewMenuRepository(db *gorm.DB, log *logrus.Logger) MenuRepository {
 	return &menuRepository{db: db, log: log}
 }
 
-func (c *menuRepository) GetAll(params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
+func (c *menuRepository) GetAll(ctx context.Context, params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
 	var menus []entity.Menu
 	var total int64
 
-	query := c.db.Model(&entity.Menu{}).Where("deleted_at IS NULL")
+	query := c.db.WithContext(ctx).Model(&entity.Menu{}).Where("deleted_at IS NULL")
 
 	if params.Title != "" {
 		query = query.Where("LOWER(title) LIKE LOWER(?)", "%"+params.Title+"%")
@@ -86,9 +87,9 @@ func (c *menuRepository) GetAll(params *model.MenuQueryParams) (*model.Paginatio
 	}, nil
 }
 
-func (c *menuRepository) GetByID(id int64) (*entity.Menu, error) {
+func (c *menuRepository) GetByID(ctx context.Context, id int64) (*entity.Menu, error) {
 	var menu entity.Menu
-	err := c.db.Where("deleted_at IS NULL").First(&menu, id).Error
+	err := c.db.WithContext(ctx).Where("deleted_at IS NULL").First(&menu, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, constants.ErrNotFound
 	}
@@ -98,12 +99,12 @@ func (c *menuRepository) GetByID(id int64) (*entity.Menu, error) {
 	return &menu, nil
 }
 
-func (c *menuRepository) Create(menu *entity.Menu) error {
-	return c.db.Create(menu).Error
+func (c *menuRepository) Create(ctx context.Context, menu *entity.Menu) error {
+	return c.db.WithContext(ctx).Create(menu).Error
 }
 
-func (c *menuRepository) UpdateMenu(menu *entity.Menu) error {
-	result := c.db.Model(&entity.Menu{}).
+func (c *menuRepository) UpdateMenu(ctx context.Context, menu *entity.Menu) error {
+	result := c.db.WithContext(ctx).Model(&entity.Menu{}).
 		Where("id = ?", menu.ID).
 		Updates(map[string]interface{}{
 			"title":       menu.Title,
@@ -127,8 +128,8 @@ func (c *menuRepository) UpdateMenu(menu *entity.Menu) error {
 	return nil
 }
 
-func (c *menuRepository) SoftDelete(id int64) error {
-	result := c.db.Model(&entity.Menu{}).
+func (c *menuRepository) SoftDelete(ctx context.Context, id int64) error {
+	result := c.db.WithContext(ctx).Model(&entity.Menu{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"deleted_at": time.Now(),
@@ -145,7 +146,7 @@ func (c *menuRepository) SoftDelete(id int64) error {
 	return nil
 }
 
-func (c *menuRepository) DecreaseStock(menuID int64, quantity int) error {
+func (c *menuRepository) DecreaseStock(ctx context.Context, menuID int64, quantity int) error {
 	query := `
         UPDATE menus 
         SET quantity = quantity - ? 
@@ -153,7 +154,7 @@ func (c *menuRepository) DecreaseStock(menuID int64, quantity int) error {
         RETURNING id`
 
 	var id int64
-	err := c.db.Raw(query, quantity, menuID, quantity).Scan(&id).Error
+	err := c.db.WithContext(ctx).Raw(query, quantity, menuID, quantity).Scan(&id).Error
 	if err != nil {
 		return err
 	}
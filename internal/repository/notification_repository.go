@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	// Create stores a new notification, created is false when one with the
+	// same dedupe key already exists
+	Create(ctx context.Context, notification *entity.NotificationLog) (created bool, err error)
+	Update(ctx context.Context, notification *entity.NotificationLog) error
+	// ClaimDue marks pending notifications whose next attempt is due as
+	// sending and returns them, so each is sent by one worker only. Claims
+	// older than notificationClaimTimeout are taken over, their worker died.
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]entity.NotificationLog, error)
+	GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]entity.NotificationLog], error)
+}
+
+// notificationClaimTimeout is how long a claimed notification waits for its
+// worker before another one sends it
+const notificationClaimTimeout = 5 * time.Minute
+
+type notificationRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewNotificationRepository(db *gorm.DB, logger *logrus.Logger) NotificationRepository {
+	return &notificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *entity.NotificationLog) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedupe_key"}}, DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		r.logger.Errorf("Error creating notification: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *notificationRepository) Update(ctx context.Context, notification *entity.NotificationLog) error {
+	if err := r.db.WithContext(ctx).Save(notification).Error; err != nil {
+		r.logger.Errorf("Error updating notification: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *notificationRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]entity.NotificationLog, error) {
+	query := `
+        UPDATE notification_logs
+        SET status = ?, claimed_at = ?, updated_at = ?
+        WHERE id IN (
+            SELECT id FROM notification_logs
+            WHERE (status = ? AND next_attempt_at <= ?) OR (status = ? AND claimed_at <= ?)
+            ORDER BY next_attempt_at ASC
+            LIMIT ?
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING *`
+
+	var notifications []entity.NotificationLog
+	if err := r.db.WithContext(ctx).Raw(query,
+		entity.NotificationStatusSending, now, now,
+		entity.NotificationStatusPending, now,
+		entity.NotificationStatusSending, now.Add(-notificationClaimTimeout),
+		limit,
+	).Scan(&notifications).Error; err != nil {
+		r.logger.Errorf("Error claiming due notifications: %v", err)
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]entity.NotificationLog], error) {
+	var notifications []entity.NotificationLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&entity.NotificationLog{})
+	if params.CustomerID != 0 {
+		query = query.Where("customer_id = ?", params.CustomerID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Event != "" {
+		query = query.Where("event = ?", params.Event)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Errorf("Error counting notifications: %v", err)
+		return nil, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Order("created_at DESC").Offset(int(offset)).Limit(int(params.Limit)).Find(&notifications).Error; err != nil {
+		r.logger.Errorf("Error getting notifications: %v", err)
+		return nil, err
+	}
+
+	return &model.PaginationResponse[[]entity.NotificationLog]{
+		Data:       notifications,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.Limit,
+		TotalPages: (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}, nil
+}
@@ -4,27 +4,38 @@ import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
 	"cakestore/utils"
+	"context"
 	"errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
-	Create(order *entity.Order) error
-	GetByID(id int64) (*entity.Order, error)
-	GetAll(params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error)
-	GetByCustomerID(customerID int64) ([]entity.Order, error)
-	Update(order *entity.Order) error
-	Delete(id int64) error
-	UpdateStatus(id int64, status entity.OrderStatus) error
+	Create(ctx context.Context, order *entity.Order) error
+	GetByID(ctx context.Context, id int64) (*entity.Order, error)
+	GetAll(ctx context.Context, params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error)
+	GetByCustomerID(ctx context.Context, customerID int64) ([]entity.Order, error)
+	Update(ctx context.Context, order *entity.Order) error
+	Delete(ctx context.Context, id int64) error
+	UpdateStatus(ctx context.Context, id int64, status entity.OrderStatus) error
+	// TransitionStatus moves the order from the status from to to. It reports
+	// false, changing nothing, when the order is no longer in from.
+	TransitionStatus(ctx context.Context, id int64, from, to entity.OrderStatus) (bool, error)
 	// GetPendingOrder retrieves the first pending order from the database for testing purposes
-	GetPendingOrder() (int64, error)
-	FindByDateRange(startDate, endDate string) ([]entity.Order, error)
-	GetPendingPaymentByOrderID(customerID, orderID int64) (entity.Order, error)
-	UpdateFoodStatus(orderID int64, foodStatus entity.FoodStatus) error
+	GetPendingOrder(ctx context.Context) (int64, error)
+	FindByDateRange(ctx context.Context, startDate, endDate string) ([]entity.Order, error)
+	GetPendingPaymentByOrderID(ctx context.Context, customerID, orderID int64) (entity.Order, error)
+	UpdateFoodStatus(ctx context.Context, orderID int64, foodStatus entity.FoodStatus) error
+	// Each calls fn with the orders created in [from, to) in batches, oldest
+	// first, together with their customer and items
+	Each(ctx context.Context, from, to time.Time, fn func(orders []entity.Order) error) error
 }
 
+// orderExportBatchSize is how many orders Each loads at a time
+const orderExportBatchSize = 500
+
 type orderRepository struct {
 	db     *gorm.DB
 	logger *logrus.Logger
@@ -37,8 +48,8 @@ func NewOrderRepository(db *gorm.DB, logger *logrus.Logger) OrderRepository {
 	}
 }
 
-func (r *orderRepository) UpdateFoodStatus(orderID int64, foodStatus entity.FoodStatus) error {
-	result := r.db.Model(&entity.Order{}).Where("id =?", orderID).Update("food_status", foodStatus)
+func (r *orderRepository) UpdateFoodStatus(ctx context.Context, orderID int64, foodStatus entity.FoodStatus) error {
+	result := r.db.WithContext(ctx).Model(&entity.Order{}).Where("id =?", orderID).Update("food_status", foodStatus)
 	if result.Error != nil {
 		r.logger.Errorf("UpdateFoodStatus repository ~ Error updating order status: %v", result.Error)
 		return result.Error
@@ -49,9 +60,9 @@ func (r *orderRepository) UpdateFoodStatus(orderID int64, foodStatus entity.Food
 	return nil
 }
 
-func (r *orderRepository) GetPendingPaymentByOrderID(customerID, orderID int64) (entity.Order, error) {
+func (r *orderRepository) GetPendingPaymentByOrderID(ctx context.Context, customerID, orderID int64) (entity.Order, error) {
 	var order entity.Order
-	if err := r.db.Preload("Items.Menu").Preload("Customer").Where("customer_id = ? AND status = ? AND id = ?", customerID, entity.OrderStatusPending, orderID).First(&order).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Menu").Preload("Customer").Where("customer_id = ? AND status = ? AND id = ?", customerID, entity.OrderStatusPending, orderID).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return entity.Order{}, errors.New("order not found")
 		}
@@ -61,16 +72,30 @@ func (r *orderRepository) GetPendingPaymentByOrderID(customerID, orderID int64)
 	return order, nil
 }
 
-func (r *orderRepository) FindByDateRange(startDate, endDate string) ([]entity.Order, error) {
+func (r *orderRepository) Each(ctx context.Context, from, to time.Time, fn func(orders []entity.Order) error) error {
+	var batch []entity.Order
+	result := r.db.WithContext(ctx).Preload("Items.Menu").Preload("Customer").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		FindInBatches(&batch, orderExportBatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	if result.Error != nil {
+		r.logger.Errorf("Error reading orders: %v", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+func (r *orderRepository) FindByDateRange(ctx context.Context, startDate, endDate string) ([]entity.Order, error) {
 	var orders []entity.Order
-	if err := r.db.Preload("Items.Menu").Preload("Customer").Where("created_at BETWEEN ? AND ?", startDate, endDate).Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Menu").Preload("Customer").Where("created_at BETWEEN ? AND ?", startDate, endDate).Find(&orders).Error; err != nil {
 		r.logger.Errorf("Error getting orders by date range: %v", err)
 		return nil, err
 	}
 	return orders, nil
 }
 
-func (r *orderRepository) GetAll(params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error) {
+func (r *orderRepository) GetAll(ctx context.Context, params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error) {
 	var orders []entity.Order
 	var total int64
 	var meta *model.PaginatedMeta
@@ -88,14 +113,14 @@ func (r *orderRepository) GetAll(params *model.PaginationQuery) ([]entity.Order,
 		params.Offset = params.Page - 1*params.Limit
 	}
 
-	if err := r.db.Model(&entity.Order{}).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Order{}).Count(&total).Error; err != nil {
 		r.logger.Errorf("Error getting total orders: %v", err)
 		return nil, nil, err
 	}
 
 	meta = utils.CreatePaginationMeta(params.Page, params.Limit, total)
 
-	if err := r.db.Preload("Items.Menu").
+	if err := r.db.WithContext(ctx).Preload("Items.Menu").
 		Preload("Customer").
 		Limit(int(params.Limit)).
 		Offset(int((params.Page - 1) * params.Limit)).
@@ -106,8 +131,8 @@ func (r *orderRepository) GetAll(params *model.PaginationQuery) ([]entity.Order,
 	return orders, meta, nil
 }
 
-func (r *orderRepository) Create(order *entity.Order) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(order).Error; err != nil {
 			r.logger.Errorf("Error creating order: %v", err)
 			return err
@@ -116,9 +141,9 @@ func (r *orderRepository) Create(order *entity.Order) error {
 	})
 }
 
-func (r *orderRepository) GetByID(id int64) (*entity.Order, error) {
+func (r *orderRepository) GetByID(ctx context.Context, id int64) (*entity.Order, error) {
 	var order entity.Order
-	if err := r.db.Preload("Items.Menu").Preload("Customer").First(&order, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Menu").Preload("Customer").First(&order, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("order not found")
 		}
@@ -128,17 +153,17 @@ func (r *orderRepository) GetByID(id int64) (*entity.Order, error) {
 	return &order, nil
 }
 
-func (r *orderRepository) GetByCustomerID(customerID int64) ([]entity.Order, error) {
+func (r *orderRepository) GetByCustomerID(ctx context.Context, customerID int64) ([]entity.Order, error) {
 	var orders []entity.Order
-	if err := r.db.Preload("Customer").Preload("Items.Menu").Where("customer_id = ?", customerID).Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Customer").Preload("Items.Menu").Where("customer_id = ?", customerID).Find(&orders).Error; err != nil {
 		r.logger.Errorf("Error getting orders by customer ID: %v", err)
 		return nil, err
 	}
 	return orders, nil
 }
 
-func (r *orderRepository) Update(order *entity.Order) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *orderRepository) Update(ctx context.Context, order *entity.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Save(order).Error; err != nil {
 			r.logger.Errorf("Error updating order: %v", err)
 			return err
@@ -154,8 +179,8 @@ func (r *orderRepository) Update(order *entity.Order) error {
 	})
 }
 
-func (r *orderRepository) Delete(id int64) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *orderRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("order_id = ?", id).Delete(&entity.OrderItem{}).Error; err != nil {
 			r.logger.Errorf("Error deleting order items: %v", err)
 			return err
@@ -173,8 +198,8 @@ func (r *orderRepository) Delete(id int64) error {
 	})
 }
 
-func (r *orderRepository) UpdateStatus(id int64, status entity.OrderStatus) error {
-	result := r.db.Model(&entity.Order{}).Where("id = ?", id).Update("status", status)
+func (r *orderRepository) UpdateStatus(ctx context.Context, id int64, status entity.OrderStatus) error {
+	result := r.db.WithContext(ctx).Model(&entity.Order{}).Where("id = ?", id).Update("status", status)
 	if result.Error != nil {
 		r.logger.Errorf("UpdateStatus repository ~ Error updating order status: %v", result.Error)
 		return result.Error
@@ -185,10 +210,19 @@ func (r *orderRepository) UpdateStatus(id int64, status entity.OrderStatus) erro
 	return nil
 }
 
+func (r *orderRepository) TransitionStatus(ctx context.Context, id int64, from, to entity.OrderStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.Order{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+	if result.Error != nil {
+		r.logger.Errorf("TransitionStatus repository ~ Error updating order status: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 // GetPendingOrder retrieves the first pending order from the database for testing purposes
-func (r *orderRepository) GetPendingOrder() (int64, error) {
+func (r *orderRepository) GetPendingOrder(ctx context.Context) (int64, error) {
 	var order entity.Order
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Items.Menu").
 		Preload("Customer").
 		Where("status = ?", entity.OrderStatusPending).
@@ -3,18 +3,27 @@ package repository
 import (
 	"cakestore/internal/constants"
 	"cakestore/internal/domain/entity"
+	"context"
 	"errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type PaymentRepository interface {
-	CreatePayment(payment *entity.Payment) error
-	GetPaymentByOrderID(orderID int64) (*entity.Payment, error)
-	UpdatePayment(payment *entity.Payment) error
+	CreatePayment(ctx context.Context, payment *entity.Payment) error
+	GetPaymentByOrderID(ctx context.Context, orderID int64) (*entity.Payment, error)
+	// UpdatePayment saves the status and the gateway link of the payment
+	UpdatePayment(ctx context.Context, payment *entity.Payment) error
+	GetPaymentsByOrderID(ctx context.Context, orderID int64) ([]entity.Payment, error)
+	GetPaymentByExternalID(ctx context.Context, externalID string) (*entity.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, id int64, status constants.PaymentStatus) error
+	// GetPendingSince returns the Midtrans payments created since the given
+	// time that are still pending, oldest first
+	GetPendingSince(ctx context.Context, since time.Time) ([]entity.Payment, error)
 	// function to retrieve the first pending payment for testing purposes in development mode
-	GetPendingPayment() (int64, error)
+	GetPendingPayment(ctx context.Context) (int64, error)
 }
 
 type paymentRespositoryImpl struct {
@@ -29,8 +38,8 @@ func NewPaymentRepository(db *gorm.DB, log *logrus.Logger) PaymentRepository {
 	}
 }
 
-func (r *paymentRespositoryImpl) CreatePayment(payment *entity.Payment) error {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+func (r *paymentRespositoryImpl) CreatePayment(ctx context.Context, payment *entity.Payment) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(payment).Error; err != nil {
 			r.log.WithError(err).Error("Failed to create payment")
 			return err
@@ -43,21 +52,23 @@ func (r *paymentRespositoryImpl) CreatePayment(payment *entity.Payment) error {
 	return nil
 }
 
-func (r *paymentRespositoryImpl) GetPaymentByOrderID(orderID int64) (*entity.Payment, error) {
+func (r *paymentRespositoryImpl) GetPaymentByOrderID(ctx context.Context, orderID int64) (*entity.Payment, error) {
 	var payment entity.Payment
-	if err := r.db.Where("order_id = ?", orderID).First(&payment).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&payment).Error; err != nil {
 		r.log.WithError(err).Error("Failed to get payment")
 		return nil, err
 	}
 	return &payment, nil
 }
 
-func (r *paymentRespositoryImpl) UpdatePayment(payment *entity.Payment) error {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+func (r *paymentRespositoryImpl) UpdatePayment(ctx context.Context, payment *entity.Payment) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&entity.Payment{}).
-			Where("order_id = ?", payment.OrderID).
+			Where("id = ?", payment.ID).
 			Updates(map[string]interface{}{
-				"status": payment.Status,
+				"status":        payment.Status,
+				"payment_token": payment.PaymentToken,
+				"payment_url":   payment.PaymentURL,
 			}).Error; err != nil {
 			r.log.WithError(err).Error("Failed to update payment")
 			return err
@@ -70,9 +81,54 @@ func (r *paymentRespositoryImpl) UpdatePayment(payment *entity.Payment) error {
 	return nil
 }
 
-func (r *paymentRespositoryImpl) GetPendingPayment() (int64, error) {
+func (r *paymentRespositoryImpl) GetPaymentsByOrderID(ctx context.Context, orderID int64) ([]entity.Payment, error) {
+	var payments []entity.Payment
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC").Find(&payments).Error; err != nil {
+		r.log.WithError(err).Error("Failed to get payments")
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRespositoryImpl) GetPaymentByExternalID(ctx context.Context, externalID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := r.db.WithContext(ctx).Where("external_id = ?", externalID).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.log.WithError(err).Error("Failed to get payment")
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRespositoryImpl) UpdatePaymentStatus(ctx context.Context, id int64, status constants.PaymentStatus) error {
+	result := r.db.WithContext(ctx).Model(&entity.Payment{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		r.log.WithError(result.Error).Error("Failed to update payment status")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *paymentRespositoryImpl) GetPendingSince(ctx context.Context, since time.Time) ([]entity.Payment, error) {
+	var payments []entity.Payment
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND method = ? AND created_at >= ?", constants.PaymentStatusPending, constants.PaymentMethodMidtrans, since).
+		Order("created_at ASC").
+		Find(&payments).Error; err != nil {
+		r.log.WithError(err).Error("Failed to get pending payments")
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRespositoryImpl) GetPendingPayment(ctx context.Context) (int64, error) {
 	var payment entity.Payment
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Order").
 		Where("status = ?", constants.PaymentStatusPending).
 		Order("created_at DESC").
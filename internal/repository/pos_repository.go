@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PosRepository interface {
+	CreateDevice(ctx context.Context, device *entity.PosDevice) error
+	GetDevices(ctx context.Context) ([]entity.PosDevice, error)
+	GetDeviceByID(ctx context.Context, id int64) (*entity.PosDevice, error)
+	GetDeviceByHash(ctx context.Context, hash string) (*entity.PosDevice, error)
+	RevokeDevice(ctx context.Context, id int64) error
+	TouchDevice(ctx context.Context, id int64, at time.Time) error
+
+	GetPin(ctx context.Context, customerID int64) (*entity.StaffPin, error)
+	// GetPins returns every PIN with its employee
+	GetPins(ctx context.Context) ([]entity.StaffPin, error)
+	// SavePin sets the employee's PIN and clears any failed attempts
+	SavePin(ctx context.Context, pin *entity.StaffPin) error
+	// RecordPinFailure counts a failed attempt and locks the PIN until
+	// now+lockFor once maxAttempts is reached, it returns the lock expiry
+	RecordPinFailure(ctx context.Context, customerID int64, maxAttempts int, lockFor time.Duration) (*time.Time, error)
+	ResetPinFailures(ctx context.Context, customerID int64) error
+}
+
+type posRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewPosRepository(db *gorm.DB, logger *logrus.Logger) PosRepository {
+	return &posRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *posRepository) CreateDevice(ctx context.Context, device *entity.PosDevice) error {
+	if err := r.db.WithContext(ctx).Create(device).Error; err != nil {
+		r.logger.Errorf("Error creating POS device: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *posRepository) GetDevices(ctx context.Context) ([]entity.PosDevice, error) {
+	var devices []entity.PosDevice
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&devices).Error; err != nil {
+		r.logger.Errorf("Error getting POS devices: %v", err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *posRepository) GetDeviceByID(ctx context.Context, id int64) (*entity.PosDevice, error) {
+	var device entity.PosDevice
+	if err := r.db.WithContext(ctx).First(&device, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting POS device by ID: %v", err)
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *posRepository) GetDeviceByHash(ctx context.Context, hash string) (*entity.PosDevice, error) {
+	var device entity.PosDevice
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting POS device by token: %v", err)
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *posRepository) RevokeDevice(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Model(&entity.PosDevice{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		r.logger.Errorf("Error revoking POS device: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *posRepository) TouchDevice(ctx context.Context, id int64, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entity.PosDevice{}).Where("id = ?", id).
+		UpdateColumn("last_seen_at", at).Error; err != nil {
+		r.logger.Errorf("Error updating POS device last seen: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *posRepository) GetPin(ctx context.Context, customerID int64) (*entity.StaffPin, error) {
+	var pin entity.StaffPin
+	if err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&pin).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting staff PIN: %v", err)
+		return nil, err
+	}
+	return &pin, nil
+}
+
+func (r *posRepository) GetPins(ctx context.Context) ([]entity.StaffPin, error) {
+	var pins []entity.StaffPin
+	if err := r.db.WithContext(ctx).Preload("Customer").Find(&pins).Error; err != nil {
+		r.logger.Errorf("Error getting staff PINs: %v", err)
+		return nil, err
+	}
+	return pins, nil
+}
+
+func (r *posRepository) SavePin(ctx context.Context, pin *entity.StaffPin) error {
+	pin.FailedAttempts = 0
+	pin.LockedUntil = nil
+	err := r.db.WithContext(ctx).Omit("Customer").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pin_hash", "failed_attempts", "locked_until", "updated_at"}),
+	}).Create(pin).Error
+	if err != nil {
+		r.logger.Errorf("Error saving staff PIN: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *posRepository) RecordPinFailure(ctx context.Context, customerID int64, maxAttempts int, lockFor time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pin entity.StaffPin
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ?", customerID).First(&pin).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"failed_attempts": pin.FailedAttempts + 1}
+		if pin.FailedAttempts+1 >= maxAttempts {
+			until := time.Now().Add(lockFor)
+			lockedUntil = &until
+			updates["failed_attempts"] = 0
+			updates["locked_until"] = until
+		}
+		return tx.Model(&entity.StaffPin{}).Where("customer_id = ?", customerID).Updates(updates).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error recording failed PIN attempt: %v", err)
+		return nil, err
+	}
+	return lockedUntil, nil
+}
+
+func (r *posRepository) ResetPinFailures(ctx context.Context, customerID int64) error {
+	if err := r.db.WithContext(ctx).Model(&entity.StaffPin{}).Where("customer_id = ?", customerID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error; err != nil {
+		r.logger.Errorf("Error resetting failed PIN attempts: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*entity.RefreshToken, error)
+	// Rotate revokes current and stores next in one transaction, it fails with
+	// ErrUnauthorized if current was already revoked by a concurrent refresh
+	Rotate(ctx context.Context, current, next *entity.RefreshToken) error
+	Revoke(ctx context.Context, id int64) error
+	RevokeAllForCustomer(ctx context.Context, customerID int64) error
+	// GetActiveForCustomer returns the customer's sessions that are neither
+	// revoked nor expired, newest first
+	GetActiveForCustomer(ctx context.Context, customerID int64) ([]entity.RefreshToken, error)
+}
+
+type refreshTokenRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewRefreshTokenRepository(db *gorm.DB, logger *logrus.Logger) RefreshTokenRepository {
+	return &refreshTokenRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		r.logger.Errorf("Error creating refresh token: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting refresh token: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, current, next *entity.RefreshToken) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constants.ErrUnauthorized
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, constants.ErrUnauthorized) {
+		r.logger.Errorf("Error rotating refresh token: %v", err)
+	}
+	return err
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id int64) error {
+	if err := r.db.WithContext(ctx).Model(&entity.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		r.logger.Errorf("Error revoking refresh token: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeAllForCustomer(ctx context.Context, customerID int64) error {
+	if err := r.db.WithContext(ctx).Model(&entity.RefreshToken{}).
+		Where("customer_id = ? AND revoked_at IS NULL", customerID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		r.logger.Errorf("Error revoking refresh tokens for customer %d: %v", customerID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetActiveForCustomer(ctx context.Context, customerID int64) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+	if err := r.db.WithContext(ctx).Where("customer_id = ? AND revoked_at IS NULL AND expires_at > ?", customerID, time.Now()).
+		Order("created_at DESC").Find(&tokens).Error; err != nil {
+		r.logger.Errorf("Error getting active refresh tokens: %v", err)
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
+	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -10,13 +13,19 @@ import (
 )
 
 type ReservationRepository interface {
-	Create(reservation *entity.Reservation) error
-	GetByID(id uint) (*entity.Reservation, error)
-	GetAll(params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error)
-	AdminGetAllCustomerReservations(params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error)
-	Update(reservation *entity.Reservation) error
-	Delete(id uint) error
-	CheckTableAvailability(tableID uint, reserveDate time.Time) (bool, error)
+	Create(ctx context.Context, reservation *entity.Reservation) error
+	GetByID(ctx context.Context, id uint) (*entity.Reservation, error)
+	GetAll(ctx context.Context, params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error)
+	AdminGetAllCustomerReservations(ctx context.Context, params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error)
+	Update(ctx context.Context, reservation *entity.Reservation) error
+	Delete(ctx context.Context, id uint) error
+	// CheckTableAvailability reports whether the table is free that day, ignoring
+	// reservation excludeID so a booking does not conflict with itself
+	CheckTableAvailability(ctx context.Context, tableID uint, reserveDate time.Time, excludeID uint) (bool, error)
+	// GetUpcoming returns active reservations with a table between from and to
+	GetUpcoming(ctx context.Context, from, to time.Time) ([]entity.Reservation, error)
+	// GetActiveBetween returns pending and confirmed reservations starting between from and to
+	GetActiveBetween(ctx context.Context, from, to time.Time) ([]entity.Reservation, error)
 }
 
 type reservationRepository struct {
@@ -31,11 +40,11 @@ func NewReservationRepository(db *gorm.DB, logger *logrus.Logger) ReservationRep
 	}
 }
 
-func (r *reservationRepository) AdminGetAllCustomerReservations(params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error) {
+func (r *reservationRepository) AdminGetAllCustomerReservations(ctx context.Context, params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error) {
 	var reservations []entity.Reservation
 	var total int64
 
-	query := r.db.Model(&entity.Reservation{})
+	query := r.db.WithContext(ctx).Model(&entity.Reservation{})
 
 	if err := query.Count(&total).Error; err != nil {
 		r.logger.Errorf("Error counting reservations: %v", err)
@@ -58,28 +67,31 @@ func (r *reservationRepository) AdminGetAllCustomerReservations(params *model.Pa
 	}, nil
 }
 
-func (r *reservationRepository) Create(reservation *entity.Reservation) error {
-	if err := r.db.Create(reservation).Error; err != nil {
+func (r *reservationRepository) Create(ctx context.Context, reservation *entity.Reservation) error {
+	if err := r.db.WithContext(ctx).Create(reservation).Error; err != nil {
 		r.logger.Errorf("Error creating reservation: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *reservationRepository) GetByID(id uint) (*entity.Reservation, error) {
+func (r *reservationRepository) GetByID(ctx context.Context, id uint) (*entity.Reservation, error) {
 	var reservation entity.Reservation
-	if err := r.db.Preload("Customer").First(&reservation, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Customer").First(&reservation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
 		r.logger.Errorf("Error getting reservation by ID: %v", err)
 		return nil, err
 	}
 	return &reservation, nil
 }
 
-func (r *reservationRepository) GetAll(params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error) {
+func (r *reservationRepository) GetAll(ctx context.Context, params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error) {
 	var reservations []entity.Reservation
 	var total int64
 
-	query := r.db.Model(&entity.Reservation{})
+	query := r.db.WithContext(ctx).Model(&entity.Reservation{})
 	if params.CustomerID != 0 {
 		query = query.Where("customer_id = ?", params.CustomerID)
 	}
@@ -121,33 +133,34 @@ func (r *reservationRepository) GetAll(params *model.ReservationQueryParams) (*m
 	}, nil
 }
 
-func (r *reservationRepository) Update(reservation *entity.Reservation) error {
-	if err := r.db.Save(reservation).Error; err != nil {
+func (r *reservationRepository) Update(ctx context.Context, reservation *entity.Reservation) error {
+	if err := r.db.WithContext(ctx).Save(reservation).Error; err != nil {
 		r.logger.Errorf("Error updating reservation: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *reservationRepository) Delete(id uint) error {
-	if err := r.db.Delete(&entity.Reservation{}, id).Error; err != nil {
+func (r *reservationRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&entity.Reservation{}, id).Error; err != nil {
 		r.logger.Errorf("Error deleting reservation: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *reservationRepository) CheckTableAvailability(tableID uint, reserveDate time.Time) (bool, error) {
+func (r *reservationRepository) CheckTableAvailability(ctx context.Context, tableID uint, reserveDate time.Time, excludeID uint) (bool, error) {
 	var count int64
 	start := reserveDate.Truncate(24 * time.Hour)
 	end := start.Add(24 * time.Hour)
 
-	if err := r.db.Model(&entity.Reservation{}).Where(
-		"table_id = ? AND reserve_date BETWEEN ? AND ? AND status NOT IN ?",
+	if err := r.db.WithContext(ctx).Model(&entity.Reservation{}).Where(
+		"table_id = ? AND id <> ? AND reserve_date BETWEEN ? AND ? AND status NOT IN ?",
 		tableID,
+		excludeID,
 		start,
 		end,
-		[]string{string(entity.ReservationStatusCancelled), string(entity.ReservationStatusCompleted)},
+		[]string{string(entity.ReservationStatusCancelled), string(entity.ReservationStatusCompleted), string(entity.ReservationStatusNoShow)},
 	).Count(&count).Error; err != nil {
 		r.logger.Errorf("Error checking table availability: %v", err)
 		return false, err
@@ -155,3 +168,31 @@ func (r *reservationRepository) CheckTableAvailability(tableID uint, reserveDate
 
 	return count == 0, nil
 }
+
+func (r *reservationRepository) GetUpcoming(ctx context.Context, from, to time.Time) ([]entity.Reservation, error) {
+	var reservations []entity.Reservation
+	if err := r.db.WithContext(ctx).Preload("Customer").Where(
+		"table_id IS NOT NULL AND reserve_date BETWEEN ? AND ? AND status IN ?",
+		from,
+		to,
+		[]string{string(entity.ReservationStatusPending), string(entity.ReservationStatusConfirmed)},
+	).Order("reserve_date ASC").Find(&reservations).Error; err != nil {
+		r.logger.Errorf("Error getting upcoming reservations: %v", err)
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepository) GetActiveBetween(ctx context.Context, from, to time.Time) ([]entity.Reservation, error) {
+	var reservations []entity.Reservation
+	if err := r.db.WithContext(ctx).Where(
+		"reserve_date BETWEEN ? AND ? AND status IN ?",
+		from,
+		to,
+		[]string{string(entity.ReservationStatusPending), string(entity.ReservationStatusConfirmed)},
+	).Order("reserve_date ASC").Find(&reservations).Error; err != nil {
+		r.logger.Errorf("Error getting reservations between %v and %v: %v", from, to, err)
+		return nil, err
+	}
+	return reservations, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	GetAll(ctx context.Context) ([]entity.Role, error)
+	GetByID(ctx context.Context, id int64) (*entity.Role, error)
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	Create(ctx context.Context, role *entity.Role) error
+	// Update saves the role and replaces its permissions with role.Permissions
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, id int64) error
+	// CountAssigned returns how many accounts currently hold the role
+	CountAssigned(ctx context.Context, name string) (int64, error)
+}
+
+type roleRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewRoleRepository(db *gorm.DB, logger *logrus.Logger) RoleRepository {
+	return &roleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *roleRepository) GetAll(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		r.logger.Errorf("Error getting roles: %v", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id int64) (*entity.Role, error) {
+	var role entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting role by ID: %v", err)
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	var role entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting role by name: %v", err)
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	if err := r.db.WithContext(ctx).Create(role).Error; err != nil {
+		r.logger.Errorf("Error creating role: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&entity.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for i := range role.Permissions {
+			role.Permissions[i].ID = 0
+			role.Permissions[i].RoleID = role.ID
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+	if err != nil {
+		r.logger.Errorf("Error updating role: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&entity.Role{}, id)
+	if result.Error != nil {
+		r.logger.Errorf("Error deleting role: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrNotFound
+	}
+	return nil
+}
+
+func (r *roleRepository) CountAssigned(ctx context.Context, name string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entity.Customer{}).Where("role = ?", name).Count(&count).Error; err != nil {
+		r.logger.Errorf("Error counting accounts with role: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// searchIndexes are the trigram indexes behind menu and inventory search,
+// created by the add_search_indexes migration
+var searchIndexes = []string{
+	"idx_menus_title_trgm",
+	"idx_menus_category_trgm",
+	"idx_inventories_name_trgm",
+}
+
+type SearchRepository interface {
+	// Reindex rebuilds the search indexes without locking out writes and
+	// refreshes the planner statistics of their tables, it returns the
+	// indexes rebuilt
+	Reindex(ctx context.Context) ([]string, error)
+}
+
+type searchRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewSearchRepository(db *gorm.DB, logger *logrus.Logger) SearchRepository {
+	return &searchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *searchRepository) Reindex(ctx context.Context) ([]string, error) {
+	var rebuilt []string
+	for _, index := range searchIndexes {
+		// REINDEX CONCURRENTLY can't run inside a transaction
+		if err := r.db.WithContext(ctx).Exec(`REINDEX INDEX CONCURRENTLY "` + index + `"`).Error; err != nil {
+			r.logger.Errorf("Error rebuilding search index %s: %v", index, err)
+			return rebuilt, err
+		}
+		rebuilt = append(rebuilt, index)
+	}
+	if err := r.db.WithContext(ctx).Exec("ANALYZE menus, inventories").Error; err != nil {
+		r.logger.Errorf("Error analyzing searched tables: %v", err)
+		return rebuilt, err
+	}
+	return rebuilt, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type TimeClockRepository interface {
+	// GetOpenEntry returns the entry of an employee that has no clock-out yet
+	GetOpenEntry(ctx context.Context, employeeID int64) (*entity.TimeEntry, error)
+	// GetEntries returns entries clocked in within [From, To), zero bounds
+	// are ignored
+	GetEntries(ctx context.Context, params *model.TimeEntryQueryParams) ([]entity.TimeEntry, error)
+	GetEntryByID(ctx context.Context, id int64) (*entity.TimeEntry, error)
+	CreateEntry(ctx context.Context, entry *entity.TimeEntry) error
+	// UpdateEntry saves the entry and replaces its breaks
+	UpdateEntry(ctx context.Context, entry *entity.TimeEntry) error
+}
+
+type timeClockRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewTimeClockRepository(db *gorm.DB, logger *logrus.Logger) TimeClockRepository {
+	return &timeClockRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *timeClockRepository) GetOpenEntry(ctx context.Context, employeeID int64) (*entity.TimeEntry, error) {
+	var entry entity.TimeEntry
+	err := r.db.WithContext(ctx).Preload("Employee").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_at ASC")
+	}).Where("employee_id = ? AND clock_out_at IS NULL", employeeID).First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting open time entry: %v", err)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeClockRepository) GetEntries(ctx context.Context, params *model.TimeEntryQueryParams) ([]entity.TimeEntry, error) {
+	query := r.db.WithContext(ctx).Preload("Employee").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_at ASC")
+	})
+	if params.EmployeeID != 0 {
+		query = query.Where("employee_id = ?", params.EmployeeID)
+	}
+	if !params.From.IsZero() {
+		query = query.Where("clock_in_at >= ?", params.From)
+	}
+	if !params.To.IsZero() {
+		query = query.Where("clock_in_at < ?", params.To)
+	}
+
+	var entries []entity.TimeEntry
+	if err := query.Order("clock_in_at ASC, id ASC").Find(&entries).Error; err != nil {
+		r.logger.Errorf("Error getting time entries: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *timeClockRepository) GetEntryByID(ctx context.Context, id int64) (*entity.TimeEntry, error) {
+	var entry entity.TimeEntry
+	err := r.db.WithContext(ctx).Preload("Employee").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_at ASC")
+	}).First(&entry, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting time entry: %v", err)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeClockRepository) CreateEntry(ctx context.Context, entry *entity.TimeEntry) error {
+	if err := r.db.WithContext(ctx).Omit("Employee").Create(entry).Error; err != nil {
+		r.logger.Errorf("Error creating time entry: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *timeClockRepository) UpdateEntry(ctx context.Context, entry *entity.TimeEntry) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Employee", "Breaks").Save(entry).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("time_entry_id = ?", entry.ID).Delete(&entity.TimeBreak{}).Error; err != nil {
+			return err
+		}
+		for i := range entry.Breaks {
+			entry.Breaks[i].ID = 0
+			entry.Breaks[i].TimeEntryID = entry.ID
+		}
+		if len(entry.Breaks) == 0 {
+			return nil
+		}
+		return tx.Create(&entry.Breaks).Error
+	})
+	if err != nil {
+		r.logger.Errorf("Error updating time entry: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepository interface {
+	Get(ctx context.Context, customerID int64) (*entity.TwoFactor, error)
+	// SavePending stores a new secret that still has to be confirmed,
+	// replacing any earlier pending one
+	SavePending(ctx context.Context, twoFactor *entity.TwoFactor) error
+	// Enable confirms the secret and replaces the recovery codes
+	Enable(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error
+	ReplaceRecoveryCodes(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error
+	// UseStep records the time step of an accepted code, it reports false
+	// when the step or a later one was already used
+	UseStep(ctx context.Context, customerID int64, step int64) (bool, error)
+	// UseRecoveryCode marks an unused recovery code as used, it reports false
+	// when there is no such code
+	UseRecoveryCode(ctx context.Context, customerID int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, customerID int64) (int64, error)
+	// Delete disables two-factor authentication and drops the recovery codes
+	Delete(ctx context.Context, customerID int64) error
+}
+
+type twoFactorRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewTwoFactorRepository(db *gorm.DB, logger *logrus.Logger) TwoFactorRepository {
+	return &twoFactorRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *twoFactorRepository) Get(ctx context.Context, customerID int64) (*entity.TwoFactor, error) {
+	var twoFactor entity.TwoFactor
+	if err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&twoFactor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrNotFound
+		}
+		r.logger.Errorf("Error getting two-factor settings: %v", err)
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) SavePending(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	twoFactor.EnabledAt = nil
+	twoFactor.LastUsedStep = 0
+	err := r.db.WithContext(ctx).Omit("Customer").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled_at", "last_used_step", "updated_at"}),
+	}).Create(twoFactor).Error
+	if err != nil {
+		r.logger.Errorf("Error saving two-factor secret: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.TwoFactor{}).Where("customer_id = ?", customerID).
+			Update("enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, customerID, codes)
+	})
+	if err != nil {
+		r.logger.Errorf("Error enabling two-factor authentication: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, customerID, codes)
+	})
+	if err != nil {
+		r.logger.Errorf("Error replacing recovery codes: %v", err)
+		return err
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, customerID int64, codes []entity.RecoveryCode) error {
+	if err := tx.Where("customer_id = ?", customerID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	for i := range codes {
+		codes[i].CustomerID = customerID
+	}
+	return tx.Create(&codes).Error
+}
+
+func (r *twoFactorRepository) UseStep(ctx context.Context, customerID int64, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.TwoFactor{}).
+		Where("customer_id = ? AND last_used_step < ?", customerID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		r.logger.Errorf("Error recording two-factor code: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, customerID int64, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.RecoveryCode{}).
+		Where("customer_id = ? AND code_hash = ? AND used_at IS NULL", customerID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.Errorf("Error using recovery code: %v", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, customerID int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entity.RecoveryCode{}).
+		Where("customer_id = ? AND used_at IS NULL", customerID).
+		Count(&count).Error; err != nil {
+		r.logger.Errorf("Error counting recovery codes: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, customerID int64) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", customerID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("customer_id = ?", customerID).Delete(&entity.TwoFactor{}).Error
+	})
+	if err != nil {
+		r.logger.Errorf("Error disabling two-factor authentication: %v", err)
+		return err
+	}
+	return nil
+}
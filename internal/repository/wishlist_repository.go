@@ -3,16 +3,17 @@ package repository
 import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type WishListRepository interface {
-	Create(wishlist *entity.WishList) error
-	GetByCustomerID(customerID int64, params *model.PaginationQuery) ([]entity.Menu, *model.PaginatedMeta, error)
-	Delete(customerID, menuID int64) error
-	GetByCustomerIDAndMenuID(customerID, menuID int64) (*entity.WishList, error)
+	Create(ctx context.Context, wishlist *entity.WishList) error
+	GetByCustomerID(ctx context.Context, customerID int64, params *model.PaginationQuery) ([]entity.Menu, *model.PaginatedMeta, error)
+	Delete(ctx context.Context, customerID, menuID int64) error
+	GetByCustomerIDAndMenuID(ctx context.Context, customerID, menuID int64) (*entity.WishList, error)
 }
 
 type wishListRepository struct {
@@ -27,23 +28,23 @@ func NewWishListRepository(db *gorm.DB, logger *logrus.Logger) WishListRepositor
 	}
 }
 
-func (r *wishListRepository) GetByCustomerIDAndMenuID(customerID, menuID int64) (*entity.WishList, error) {
+func (r *wishListRepository) GetByCustomerIDAndMenuID(ctx context.Context, customerID, menuID int64) (*entity.WishList, error) {
 	var wishlist entity.WishList
-	if err := r.db.Where("customer_id = ? AND menu_id = ? AND deleted_at IS NULL", customerID, menuID).First(&wishlist).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("customer_id = ? AND menu_id = ? AND deleted_at IS NULL", customerID, menuID).First(&wishlist).Error; err != nil {
 		return nil, err
 	}
 	return &wishlist, nil
 }
 
-func (r *wishListRepository) Create(wishlist *entity.WishList) error {
-	return r.db.Create(wishlist).Error
+func (r *wishListRepository) Create(ctx context.Context, wishlist *entity.WishList) error {
+	return r.db.WithContext(ctx).Create(wishlist).Error
 }
 
-func (r *wishListRepository) GetByCustomerID(customerID int64, params *model.PaginationQuery) ([]entity.Menu, *model.PaginatedMeta, error) {
+func (r *wishListRepository) GetByCustomerID(ctx context.Context, customerID int64, params *model.PaginationQuery) ([]entity.Menu, *model.PaginatedMeta, error) {
 	var menus []entity.Menu
 	var total int64
 
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Table("menus").
 		Joins("JOIN wishlists ON wishlists.menu_id = menus.id").
 		Where("wishlists.customer_id = ? AND wishlists.deleted_at IS NULL", customerID).
@@ -53,7 +54,7 @@ func (r *wishListRepository) GetByCustomerID(customerID int64, params *model.Pag
 
 	offset := (params.Page - 1) * params.Limit
 
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Table("menus").
 		Joins("JOIN wishlists ON wishlists.menu_id = menus.id").
 		Where("wishlists.customer_id = ? AND wishlists.deleted_at IS NULL", customerID).
@@ -76,8 +77,8 @@ func (r *wishListRepository) GetByCustomerID(customerID int64, params *model.Pag
 	return menus, meta, nil
 }
 
-func (r *wishListRepository) Delete(customerID, menuID int64) error {
-	return r.db.Model(&entity.WishList{}).
+func (r *wishListRepository) Delete(ctx context.Context, customerID, menuID int64) error {
+	return r.db.WithContext(ctx).Model(&entity.WishList{}).
 		Where("customer_id = ? AND menu_id = ?", customerID, menuID).
 		Updates(map[string]interface{}{
 			"deleted_at": gorm.Expr("NOW()"),
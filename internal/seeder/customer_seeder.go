@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/constants"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/repository"
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -22,9 +23,9 @@ func NewCustomerSeeder(repo repository.CustomerRepository, logger *logrus.Logger
 	}
 }
 
-func (s *CustomerSeeder) SeedAdmin(email, password string) error {
+func (s *CustomerSeeder) SeedAdmin(ctx context.Context, email, password string) error {
 	// Check if admin already exists
-	existingAdmin, err := s.repo.GetByEmail(email)
+	existingAdmin, err := s.repo.GetByEmail(ctx, email)
 	if err == nil && existingAdmin != nil {
 		s.logger.Info("Admin user already exists")
 		return nil
@@ -39,16 +40,17 @@ func (s *CustomerSeeder) SeedAdmin(email, password string) error {
 
 	// Create admin user
 	admin := &entity.Customer{
-		Name:      "Admin",
-		Email:     email,
-		Password:  string(hashedPassword),
-		Address:   "Admin Address",
-		Role:      constants.RoleAdmin,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:            "Admin",
+		Email:           email,
+		Password:        string(hashedPassword),
+		Address:         "Admin Address",
+		Role:            constants.RoleAdmin,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		EmailVerifiedAt: verifiedNow(),
 	}
 
-	if err := s.repo.Create(admin); err != nil {
+	if err := s.repo.Create(ctx, admin); err != nil {
 		s.logger.Errorf("Error creating admin user: %v", err)
 		return err
 	}
@@ -57,9 +59,9 @@ func (s *CustomerSeeder) SeedAdmin(email, password string) error {
 	return nil
 }
 
-func (s *CustomerSeeder) SeedKitchenStaff(email, password string) error {
+func (s *CustomerSeeder) SeedKitchenStaff(ctx context.Context, email, password string) error {
 	// Check if admin already exists
-	existingKitchenStaff, err := s.repo.GetByEmail(email)
+	existingKitchenStaff, err := s.repo.GetByEmail(ctx, email)
 	if err == nil && existingKitchenStaff != nil {
 		s.logger.Info("Kitchen staff user already exists")
 		return nil
@@ -74,16 +76,17 @@ func (s *CustomerSeeder) SeedKitchenStaff(email, password string) error {
 
 	// Create kitchen staff user
 	kitchenStaff := &entity.Customer{
-		Name:      "Andy",
-		Email:     email,
-		Password:  string(hashedPassword),
-		Address:   "Jakarta Barat",
-		Role:      constants.RoleKitchen,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:            "Andy",
+		Email:           email,
+		Password:        string(hashedPassword),
+		Address:         "Jakarta Barat",
+		Role:            constants.RoleKitchen,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		EmailVerifiedAt: verifiedNow(),
 	}
 
-	if err := s.repo.Create(kitchenStaff); err != nil {
+	if err := s.repo.Create(ctx, kitchenStaff); err != nil {
 		s.logger.Errorf("Error creating kitchen staff user: %v", err)
 		return err
 	}
@@ -92,9 +95,9 @@ func (s *CustomerSeeder) SeedKitchenStaff(email, password string) error {
 	return nil
 }
 
-func (s *CustomerSeeder) SeedWaiter(email, password string) error {
+func (s *CustomerSeeder) SeedWaiter(ctx context.Context, email, password string) error {
 	// Check if admin already exists
-	existingWaiter, err := s.repo.GetByEmail(email)
+	existingWaiter, err := s.repo.GetByEmail(ctx, email)
 	if err == nil && existingWaiter != nil {
 		s.logger.Info("Waiter user already exists")
 		return nil
@@ -109,16 +112,17 @@ func (s *CustomerSeeder) SeedWaiter(email, password string) error {
 
 	// Create waiter user
 	waiter := &entity.Customer{
-		Name:      "Jackson",
-		Email:     email,
-		Password:  string(hashedPassword),
-		Address:   "Jakarta Selatan",
-		Role:      constants.RoleWaitress,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:            "Jackson",
+		Email:           email,
+		Password:        string(hashedPassword),
+		Address:         "Jakarta Selatan",
+		Role:            constants.RoleWaitress,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		EmailVerifiedAt: verifiedNow(),
 	}
 
-	if err := s.repo.Create(waiter); err != nil {
+	if err := s.repo.Create(ctx, waiter); err != nil {
 		s.logger.Errorf("Error creating waiter user: %v", err)
 		return err
 	}
@@ -127,9 +131,9 @@ func (s *CustomerSeeder) SeedWaiter(email, password string) error {
 	return nil
 }
 
-func (s *CustomerSeeder) SeedCashier(email, password string) error {
+func (s *CustomerSeeder) SeedCashier(ctx context.Context, email, password string) error {
 	// Check if admin already exists
-	existingCashier, err := s.repo.GetByEmail(email)
+	existingCashier, err := s.repo.GetByEmail(ctx, email)
 	if err == nil && existingCashier != nil {
 		s.logger.Info("Cashier user already exists")
 		return nil
@@ -143,16 +147,17 @@ func (s *CustomerSeeder) SeedCashier(email, password string) error {
 
 	// Create cashier user
 	cashier := &entity.Customer{
-		Name:      "Amanda",
-		Email:     email,
-		Password:  string(hashedPassword),
-		Address:   "Jakarta Timur",
-		Role:      constants.RoleCashier,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:            "Amanda",
+		Email:           email,
+		Password:        string(hashedPassword),
+		Address:         "Jakarta Timur",
+		Role:            constants.RoleCashier,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		EmailVerifiedAt: verifiedNow(),
 	}
 
-	if err := s.repo.Create(cashier); err != nil {
+	if err := s.repo.Create(ctx, cashier); err != nil {
 		s.logger.Errorf("Error creating cashier user: %v", err)
 		return err
 	}
@@ -161,9 +166,9 @@ func (s *CustomerSeeder) SeedCashier(email, password string) error {
 	return nil
 }
 
-func (s *CustomerSeeder) SeedBasic(email, password string) error {
+func (s *CustomerSeeder) SeedBasic(ctx context.Context, email, password string) error {
 	// Check if admin already exists
-	existingCust, err := s.repo.GetByEmail(email)
+	existingCust, err := s.repo.GetByEmail(ctx, email)
 	if err == nil && existingCust != nil {
 		s.logger.Info("user already exists")
 		return nil
@@ -178,16 +183,17 @@ func (s *CustomerSeeder) SeedBasic(email, password string) error {
 
 	// Create cust user
 	cust := &entity.Customer{
-		Name:      "Rafli Dewanto",
-		Email:     email,
-		Password:  string(hashedPassword),
-		Address:   "Bekasi",
-		Role:      constants.RoleCustomer,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:            "Rafli Dewanto",
+		Email:           email,
+		Password:        string(hashedPassword),
+		Address:         "Bekasi",
+		Role:            constants.RoleCustomer,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		EmailVerifiedAt: verifiedNow(),
 	}
 
-	if err := s.repo.Create(cust); err != nil {
+	if err := s.repo.Create(ctx, cust); err != nil {
 		s.logger.Errorf("Error creating customer user: %v", err)
 		return err
 	}
@@ -195,3 +201,9 @@ func (s *CustomerSeeder) SeedBasic(email, password string) error {
 	s.logger.Info("customer created successfully")
 	return nil
 }
+
+// verifiedNow marks seeded accounts as verified, they never get a verification email
+func verifiedNow() *time.Time {
+	now := time.Now()
+	return &now
+}
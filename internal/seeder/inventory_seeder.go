@@ -3,6 +3,7 @@ package seeder
 import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/repository"
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,8 +21,8 @@ func NewInventorySeeder(repo repository.InventoryRepository, logger *logrus.Logg
 	}
 }
 
-func (s *InventorySeeder) Seed() error {
-	count, err := s.repo.Count()
+func (s *InventorySeeder) Seed(ctx context.Context) error {
+	count, err := s.repo.Count(ctx)
 	if err != nil {
 		s.logger.Errorf("Error checking inventory count: %v", err)
 		return err
@@ -108,7 +109,7 @@ func (s *InventorySeeder) Seed() error {
 	}
 
 	for _, ingredient := range ingredients {
-		if err := s.repo.Create(&ingredient); err != nil {
+		if err := s.repo.Create(ctx, &ingredient); err != nil {
 			s.logger.Errorf("Error seeding ingredient %s: %v", ingredient.Name, err)
 			return err
 		}
@@ -0,0 +1,50 @@
+package seeder
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/repository"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RoleSeeder struct {
+	repo   repository.RoleRepository
+	logger *logrus.Logger
+}
+
+func NewRoleSeeder(repo repository.RoleRepository, logger *logrus.Logger) *RoleSeeder {
+	return &RoleSeeder{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Seed creates the built-in roles with the default policy, roles that already
+// exist are left alone so edits made through the API survive restarts.
+// Permissions added to the default policy later are granted to existing
+// roles by a migration.
+func (s *RoleSeeder) Seed(ctx context.Context) error {
+	for name, permissions := range constants.DefaultRolePermissions {
+		_, err := s.repo.GetByName(ctx, name)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, constants.ErrNotFound) {
+			return err
+		}
+
+		role := &entity.Role{Name: name, BuiltIn: true}
+		for _, permission := range permissions {
+			role.Permissions = append(role.Permissions, entity.RolePermission{Permission: permission})
+		}
+		if err := s.repo.Create(ctx, role); err != nil {
+			s.logger.Errorf("Failed to seed role %s: %v", name, err)
+			return err
+		}
+		s.logger.Infof("Seeded role %s", name)
+	}
+	return nil
+}
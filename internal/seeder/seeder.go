@@ -2,6 +2,10 @@ package seeder
 
 import (
 	"cakestore/internal/repository"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -11,6 +15,7 @@ type Seeder struct {
 	menuSeeder      *MenuSeeder
 	inventorySeeder *InventorySeeder
 	tableSeeder     *TableSeeder
+	roleSeeder      *RoleSeeder
 	logger          *logrus.Logger
 }
 
@@ -20,6 +25,7 @@ func NewSeeder(
 	logger *logrus.Logger,
 	inventorySeeder repository.InventoryRepository,
 	tableSeeder repository.TableRepository,
+	roleRepo repository.RoleRepository,
 ) *Seeder {
 	return &Seeder{
 		customerSeeder:  NewCustomerSeeder(customerRepo, logger),
@@ -27,57 +33,101 @@ func NewSeeder(
 		logger:          logger,
 		inventorySeeder: NewInventorySeeder(inventorySeeder, logger),
 		tableSeeder:     NewTableSeeder(tableSeeder, logger),
+		roleSeeder:      NewRoleSeeder(roleRepo, logger),
 	}
 }
 
-func (s *Seeder) SeedAll() error {
+// Seeds names what SeedAll fills, in the order it runs them. Roles come
+// first as accounts refer to them.
+var Seeds = []string{"roles", "customers", "inventory", "menus", "tables"}
+
+func (s *Seeder) SeedAll(ctx context.Context) error {
+	return s.Seed(ctx, Seeds...)
+}
+
+// Seed runs the seeds named, in the order of Seeds whatever order they are
+// given in
+func (s *Seeder) Seed(ctx context.Context, names ...string) error {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !slices.Contains(Seeds, name) {
+			return fmt.Errorf("unknown seed %q, expected one of %s", name, strings.Join(Seeds, ", "))
+		}
+		selected[name] = true
+	}
+
 	s.logger.Info("Starting database seeding...")
 
+	// Seed the built-in roles and their permissions
+	if selected["roles"] {
+		if err := s.roleSeeder.Seed(ctx); err != nil {
+			s.logger.Errorf("Error seeding roles: %v", err)
+			return err
+		}
+	}
+
+	if selected["customers"] {
+		if err := s.seedCustomers(ctx); err != nil {
+			return err
+		}
+	}
+
+	// seed inventory
+	if selected["inventory"] {
+		if err := s.inventorySeeder.Seed(ctx); err != nil {
+			s.logger.Errorf("Error seeding inventory: %v", err)
+			return err
+		}
+	}
+
+	// Seed menus
+	if selected["menus"] {
+		if err := s.menuSeeder.SeedMenus(ctx); err != nil {
+			s.logger.Errorf("Error seeding menus: %v", err)
+			return err
+		}
+	}
+
+	// seed tables
+	if selected["tables"] {
+		if err := s.tableSeeder.Seed(ctx); err != nil {
+			s.logger.Errorf("Error seeding 'tables': %v", err)
+			return err
+		}
+	}
+
+	s.logger.Info("Database seeding completed successfully")
+	return nil
+}
+
+// seedCustomers creates the admin, one account for each staff role and a
+// customer
+func (s *Seeder) seedCustomers(ctx context.Context) error {
 	// Seed admin user
-	if err := s.customerSeeder.SeedAdmin("admin@email.com", "master123"); err != nil {
+	if err := s.customerSeeder.SeedAdmin(ctx, "admin@email.com", "master123"); err != nil {
 		s.logger.Errorf("Error seeding admin user: %v", err)
 		return err
 	}
 
 	// seed staffs
-	if err := s.customerSeeder.SeedKitchenStaff("andy@email.com", "master123"); err != nil {
+	if err := s.customerSeeder.SeedKitchenStaff(ctx, "andy@email.com", "master123"); err != nil {
 		s.logger.Errorf("Error seeding kitchen staff user: %v", err)
 		return err
 	}
 
-	if err := s.customerSeeder.SeedWaiter("jack@email.com", "master123"); err != nil {
+	if err := s.customerSeeder.SeedWaiter(ctx, "jack@email.com", "master123"); err != nil {
 		s.logger.Errorf("Error seeding waiter user: %v", err)
 		return err
 	}
 
-	if err := s.customerSeeder.SeedCashier("amanda@email.com", "master123"); err != nil {
+	if err := s.customerSeeder.SeedCashier(ctx, "amanda@email.com", "master123"); err != nil {
 		s.logger.Errorf("Error seeding cashier user: %v", err)
 		return err
 	}
 
-	if err := s.customerSeeder.SeedBasic("rafli@email.com", "master123"); err != nil {
+	if err := s.customerSeeder.SeedBasic(ctx, "rafli@email.com", "master123"); err != nil {
 		s.logger.Errorf("Error seeding customer user: %v", err)
 		return err
 	}
-
-	// seed inventory
-	if err := s.inventorySeeder.Seed(); err != nil {
-		s.logger.Errorf("Error seeding inventory: %v", err)
-		return err
-	}
-
-	// Seed menus
-	if err := s.menuSeeder.SeedMenus(); err != nil {
-		s.logger.Errorf("Error seeding menus: %v", err)
-		return err
-	}
-
-	// seed tables
-	if err := s.tableSeeder.Seed(); err != nil {
-		s.logger.Errorf("Error seeding 'tables': %v", err)
-		return err
-	}
-
-	s.logger.Info("Database seeding completed successfully")
 	return nil
 }
@@ -3,6 +3,7 @@ package seeder
 import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/repository"
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,9 +21,9 @@ func NewTableSeeder(repo repository.TableRepository, logger *logrus.Logger) *Tab
 	}
 }
 
-func (s *TableSeeder) Seed() error {
+func (s *TableSeeder) Seed(ctx context.Context) error {
 	// if seeded, return nil
-	num, err := s.repo.Count()
+	num, err := s.repo.Count(ctx)
 	if err != nil {
 		s.logger.Errorf("Failed to count tables: %v", err)
 		return err
@@ -46,7 +47,7 @@ func (s *TableSeeder) Seed() error {
 	}
 
 	for _, table := range tables {
-		if err := s.repo.Create(&table); err != nil {
+		if err := s.repo.Create(ctx, &table); err != nil {
 			s.logger.Errorf("Failed to seed table %d: %v", table.TableNumber, err)
 			return err
 		}
@@ -0,0 +1,100 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWK_Thumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+		want string
+	}{
+		{
+			// RFC 7638 section 3.1
+			name: "RSA",
+			jwk: JWK{
+				KeyType:   "RSA",
+				Algorithm: AlgorithmRS256,
+				KeyID:     "2011-04-29",
+				N:         "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				E:         "AQAB",
+			},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// RFC 8037 appendix A.3
+			name: "Ed25519",
+			jwk:  JWK{KeyType: "OKP", Curve: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.jwk.thumbprint())
+		})
+	}
+}
+
+func TestService_JWKS(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	edKey := newEd25519Key(t)
+	rsaPath, _ := writeKey(t, "rsa", rsaKey)
+	_, edPublic := writeKey(t, "ed25519", edKey)
+
+	tests := []struct {
+		name string
+		cfg  Config
+		keys int
+	}{
+		{name: "HS256 secrets are not published", cfg: Config{Secret: "secret"}, keys: 0},
+		{name: "signing key", cfg: Config{PrivateKeyFile: rsaPath}, keys: 1},
+		{name: "signing and previous keys", cfg: Config{PrivateKeyFile: rsaPath, PreviousPublicKeyFiles: []string{edPublic}}, keys: 2},
+		{name: "previous keys next to a secret", cfg: Config{Secret: "secret", PreviousPublicKeyFiles: []string{edPublic}}, keys: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewService(tt.cfg)
+			require.NoError(t, err)
+
+			set := service.JWKS()
+
+			require.Len(t, set.Keys, tt.keys)
+			for i, jwk := range set.Keys {
+				if i > 0 {
+					assert.Less(t, set.Keys[i-1].KeyID, jwk.KeyID)
+				}
+				assert.Equal(t, "sig", jwk.Use)
+				assert.Equal(t, jwk.thumbprint(), jwk.KeyID)
+
+				switch jwk.KeyType {
+				case "RSA":
+					assert.Equal(t, AlgorithmRS256, jwk.Algorithm)
+					assert.Equal(t, rsaKey.PublicKey, rsa.PublicKey{N: decodeInt(t, jwk.N), E: int(decodeInt(t, jwk.E).Int64())})
+				case "OKP":
+					assert.Equal(t, AlgorithmEdDSA, jwk.Algorithm)
+					assert.Equal(t, "Ed25519", jwk.Curve)
+					x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+					require.NoError(t, err)
+					assert.Equal(t, edKey.Public(), ed25519.PublicKey(x))
+				default:
+					t.Errorf("unexpected key type %q", jwk.KeyType)
+				}
+			}
+		})
+	}
+}
+
+func decodeInt(t *testing.T, encoded string) *big.Int {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	return new(big.Int).SetBytes(data)
+}
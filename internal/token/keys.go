@@ -0,0 +1,132 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+func signingKey(cfg Config) (*key, error) {
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmHS256
+		if cfg.PrivateKeyFile != "" {
+			algorithm = AlgorithmRS256
+		}
+	}
+
+	if algorithm == AlgorithmHS256 {
+		if cfg.Secret == "" {
+			return nil, errors.New("token: HS256 requires a secret")
+		}
+		id := cfg.KeyID
+		if id == "" {
+			id = "hs256"
+		}
+		return &key{id: id, method: jwt.SigningMethodHS256, private: []byte(cfg.Secret), public: []byte(cfg.Secret)}, nil
+	}
+
+	if cfg.PrivateKeyFile == "" {
+		return nil, fmt.Errorf("token: %s requires a private key file", algorithm)
+	}
+	block, err := readPEM(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	private, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("token: %s: %w", cfg.PrivateKeyFile, err)
+	}
+
+	k, err := newAsymmetricKey(cfg.KeyID, private.Public())
+	if err != nil {
+		return nil, err
+	}
+	if k.method.Alg() != algorithm {
+		return nil, fmt.Errorf("token: %s holds a %s key, not %s", cfg.PrivateKeyFile, k.method.Alg(), algorithm)
+	}
+	k.private = private
+	return k, nil
+}
+
+// verificationKey loads a retired public key, entry is a path optionally
+// prefixed with "kid="
+func verificationKey(entry string) (*key, error) {
+	id, path := "", entry
+	if i := strings.Index(entry, "="); i > 0 {
+		id, path = entry[:i], entry[i+1:]
+	}
+
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("token: %s: %w", path, err)
+	}
+	return newAsymmetricKey(id, public)
+}
+
+func newAsymmetricKey(id string, public crypto.PublicKey) (*key, error) {
+	k := &key{id: id, public: public}
+	switch public.(type) {
+	case *rsa.PublicKey:
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("token: unsupported key type %T", public)
+	}
+
+	if k.id == "" {
+		jwk, err := toJWK(k)
+		if err != nil {
+			return nil, err
+		}
+		k.id = jwk.thumbprint()
+	}
+	return k, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("token: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("token: %s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return signer, nil
+}
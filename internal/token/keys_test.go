@@ -0,0 +1,84 @@
+package token
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService_Keys(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	rsaPath, rsaPublic := writeKey(t, "rsa", rsaKey)
+	edPath, _ := writeKey(t, "ed25519", newEd25519Key(t))
+
+	dir := t.TempDir()
+	pkcs1Path := filepath.Join(dir, "pkcs1.pem")
+	require.NoError(t, os.WriteFile(pkcs1Path, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), 0o600))
+	plainPath := filepath.Join(dir, "plain.txt")
+	require.NoError(t, os.WriteFile(plainPath, []byte("not a key"), 0o600))
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		alg     string
+		wantErr string
+	}{
+		{name: "PKCS #1 RSA key", cfg: Config{PrivateKeyFile: pkcs1Path}, alg: AlgorithmRS256},
+		{name: "PKCS #8 Ed25519 key", cfg: Config{Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPath}, alg: AlgorithmEdDSA},
+		{name: "HS256 without a secret", cfg: Config{}, wantErr: "HS256 requires a secret"},
+		{name: "RS256 without a key file", cfg: Config{Algorithm: AlgorithmRS256}, wantErr: "RS256 requires a private key file"},
+		{name: "key of another algorithm", cfg: Config{Algorithm: AlgorithmEdDSA, PrivateKeyFile: rsaPath}, wantErr: "holds a RS256 key, not EdDSA"},
+		{name: "missing key file", cfg: Config{PrivateKeyFile: filepath.Join(dir, "missing.pem")}, wantErr: "no such file"},
+		{name: "file that is not PEM", cfg: Config{PrivateKeyFile: plainPath}, wantErr: "is not PEM encoded"},
+		{name: "public key as the signing key", cfg: Config{PrivateKeyFile: rsaPublic}, wantErr: rsaPublic},
+		{name: "previous key that is not PEM", cfg: Config{Secret: "secret", PreviousPublicKeyFiles: []string{plainPath}}, wantErr: "is not PEM encoded"},
+		{name: "private key as a previous key", cfg: Config{Secret: "secret", PreviousPublicKeyFiles: []string{edPath}}, wantErr: edPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewService(tt.cfg)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, service.signing.method.Alg())
+		})
+	}
+}
+
+func TestVerificationKey(t *testing.T) {
+	_, public := writeKey(t, "rsa", newRSAKey(t))
+
+	tests := []struct {
+		name  string
+		entry string
+		kid   string
+	}{
+		{name: "kid derived from the key", entry: public},
+		{name: "pinned kid", entry: "2026-09=" + public, kid: "2026-09"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := verificationKey(tt.entry)
+			require.NoError(t, err)
+
+			kid := tt.kid
+			if kid == "" {
+				jwk, err := toJWK(k)
+				require.NoError(t, err)
+				kid = jwk.thumbprint()
+			}
+			assert.Equal(t, kid, k.id)
+			assert.Equal(t, AlgorithmRS256, k.method.Alg())
+			assert.Nil(t, k.private)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package token issues and verifies the JWT access tokens used by the API.
+// Tokens are signed with HS256, RS256 or EdDSA, carry the signing key's id in
+// the kid header and can be verified by other services through the JWKS.
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims are carried by every access token
+type Claims struct {
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	CustomerID int64  `json:"customer_id"`
+	Role       string `json:"role"`
+	// TokenVersion must match the customer's current token version, it is
+	// bumped to revoke every token issued before it
+	TokenVersion int `json:"ver"`
+	// DeviceID binds a PIN session to the POS terminal it was issued on
+	DeviceID int64 `json:"dev,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type Config struct {
+	// Algorithm is HS256, RS256 or EdDSA
+	Algorithm string
+	// Secret signs HS256 tokens
+	Secret string
+	// PrivateKeyFile is a PEM encoded RSA or Ed25519 key for RS256 and EdDSA
+	PrivateKeyFile string
+	// KeyID is the kid of the signing key, derived from the key when empty
+	KeyID string
+	// PreviousPublicKeyFiles are PEM public keys of retired signing keys that
+	// still verify tokens, an entry may be prefixed with "kid=" to pin its kid
+	PreviousPublicKeyFiles []string
+	Issuer                 string
+	Audience               string
+}
+
+// Service signs tokens with the current key and verifies them with any of
+// the active keys, so keys can be rotated without logging everyone out
+type Service struct {
+	signing  *key
+	keys     map[string]*key
+	methods  []string
+	issuer   string
+	audience string
+}
+
+func NewService(cfg Config) (*Service, error) {
+	signing, err := signingKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		signing:  signing,
+		keys:     map[string]*key{signing.id: signing},
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}
+	for _, entry := range cfg.PreviousPublicKeyFiles {
+		k, err := verificationKey(entry)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[k.id] = k
+	}
+
+	seen := map[string]bool{}
+	for _, k := range s.keys {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			s.methods = append(s.methods, alg)
+		}
+	}
+
+	return s, nil
+}
+
+// Issue signs claims with the current key, filling in the token id, issuer,
+// audience and lifetime
+func (s *Service) Issue(claims *Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
+	claims.Issuer = s.issuer
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.id
+
+	signed, err := token.SignedString(s.signing.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Parse verifies the signature, expiry, issuer and audience of a token
+func (s *Service) Parse(tokenString string) (*Claims, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods(s.methods), jwt.WithExpirationRequired()}
+	if s.issuer != "" {
+		options = append(options, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		options = append(options, jwt.WithAudience(s.audience))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keyFor, options...)
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (s *Service) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return k.public, nil
+}
@@ -0,0 +1,204 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKey stores private as a PKCS #8 key and its public half next to it,
+// returning both paths
+func writeKey(t *testing.T, name string, private crypto.Signer) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	privatePath := filepath.Join(dir, name+".pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	der, err = x509.MarshalPKIXPublicKey(private.Public())
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	return privatePath, publicPath
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return private
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return private
+}
+
+// header decodes the header of a signed token without verifying it
+func header(t *testing.T, signed string) map[string]interface{} {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(signed, &Claims{})
+	require.NoError(t, err)
+	return token.Header
+}
+
+func TestService_IssueAndParse(t *testing.T) {
+	rsaPath, _ := writeKey(t, "rsa", newRSAKey(t))
+	edPath, _ := writeKey(t, "ed25519", newEd25519Key(t))
+
+	tests := []struct {
+		name string
+		cfg  Config
+		alg  string
+		kid  string
+	}{
+		{name: "HS256", cfg: Config{Secret: "secret"}, alg: AlgorithmHS256, kid: "hs256"},
+		{name: "HS256 with a configured kid", cfg: Config{Secret: "secret", KeyID: "2026-10"}, alg: AlgorithmHS256, kid: "2026-10"},
+		{name: "RS256", cfg: Config{Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPath}, alg: AlgorithmRS256},
+		{name: "RS256 by default for a key file", cfg: Config{PrivateKeyFile: rsaPath}, alg: AlgorithmRS256},
+		{name: "RS256 with a configured kid", cfg: Config{PrivateKeyFile: rsaPath, KeyID: "rsa-1"}, alg: AlgorithmRS256, kid: "rsa-1"},
+		{name: "EdDSA", cfg: Config{Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPath}, alg: AlgorithmEdDSA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Issuer = "cakestore"
+			tt.cfg.Audience = "cakestore-api"
+			service, err := NewService(tt.cfg)
+			require.NoError(t, err)
+
+			signed, err := service.Issue(&Claims{CustomerID: 7, Email: "rina@example.com", Role: "customer", TokenVersion: 2, DeviceID: 3}, time.Minute)
+			require.NoError(t, err)
+
+			h := header(t, signed)
+			assert.Equal(t, tt.alg, h["alg"])
+			kid := tt.kid
+			if kid == "" {
+				// asymmetric keys are named by their thumbprint
+				jwk, err := toJWK(service.signing)
+				require.NoError(t, err)
+				kid = jwk.thumbprint()
+			}
+			assert.Equal(t, kid, h["kid"])
+
+			claims, err := service.Parse(signed)
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), claims.CustomerID)
+			assert.Equal(t, "rina@example.com", claims.Email)
+			assert.Equal(t, "customer", claims.Role)
+			assert.Equal(t, 2, claims.TokenVersion)
+			assert.Equal(t, int64(3), claims.DeviceID)
+			assert.NotEmpty(t, claims.ID)
+			assert.Equal(t, "cakestore", claims.Issuer)
+			assert.Equal(t, jwt.ClaimStrings{"cakestore-api"}, claims.Audience)
+		})
+	}
+}
+
+func TestService_Rotation(t *testing.T) {
+	oldRSA, oldRSAPublic := writeKey(t, "old-rsa", newRSAKey(t))
+	oldEd, oldEdPublic := writeKey(t, "old-ed25519", newEd25519Key(t))
+	current, _ := writeKey(t, "current", newRSAKey(t))
+
+	tests := []struct {
+		name     string
+		old      Config
+		previous []string
+		valid    bool
+	}{
+		{name: "previous RSA key still verifies", old: Config{PrivateKeyFile: oldRSA}, previous: []string{oldRSAPublic}, valid: true},
+		{name: "previous key of another algorithm", old: Config{Algorithm: AlgorithmEdDSA, PrivateKeyFile: oldEd}, previous: []string{oldRSAPublic, oldEdPublic}, valid: true},
+		{name: "previous key with a pinned kid", old: Config{PrivateKeyFile: oldRSA, KeyID: "2026-09"}, previous: []string{"2026-09=" + oldRSAPublic}, valid: true},
+		{name: "pinned kid must match", old: Config{PrivateKeyFile: oldRSA, KeyID: "2026-09"}, previous: []string{"2026-08=" + oldRSAPublic}},
+		{name: "retired key is dropped", old: Config{PrivateKeyFile: oldRSA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldService, err := NewService(tt.old)
+			require.NoError(t, err)
+			signed, err := oldService.Issue(&Claims{CustomerID: 7}, time.Minute)
+			require.NoError(t, err)
+
+			service, err := NewService(Config{PrivateKeyFile: current, PreviousPublicKeyFiles: tt.previous})
+			require.NoError(t, err)
+
+			claims, err := service.Parse(signed)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), claims.CustomerID)
+
+			// new tokens are signed with the current key only
+			signed, err = service.Issue(&Claims{CustomerID: 7}, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, service.signing.id, header(t, signed)["kid"])
+			_, err = oldService.Parse(signed)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestService_ParseRejects(t *testing.T) {
+	rsaPath, _ := writeKey(t, "rsa", newRSAKey(t))
+	service, err := NewService(Config{PrivateKeyFile: rsaPath, Issuer: "cakestore", Audience: "cakestore-api"})
+	require.NoError(t, err)
+
+	// sign builds a token by hand, so header and claims can be anything
+	sign := func(method jwt.SigningMethod, kid string, claims *Claims, signingKey interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signingKey)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func(issuer, audience string, expiresIn time.Duration) *Claims {
+		return &Claims{CustomerID: 7, RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		}}
+	}
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(service.signing.public)
+	require.NoError(t, err)
+	kid := service.signing.id
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "wrong issuer", token: sign(jwt.SigningMethodRS256, kid, claims("elsewhere", "cakestore-api", time.Minute), service.signing.private)},
+		{name: "wrong audience", token: sign(jwt.SigningMethodRS256, kid, claims("cakestore", "other-api", time.Minute), service.signing.private)},
+		{name: "expired", token: sign(jwt.SigningMethodRS256, kid, claims("cakestore", "cakestore-api", -time.Minute), service.signing.private)},
+		{name: "no expiry", token: sign(jwt.SigningMethodRS256, kid, &Claims{CustomerID: 7, RegisteredClaims: jwt.RegisteredClaims{Issuer: "cakestore", Audience: jwt.ClaimStrings{"cakestore-api"}}}, service.signing.private)},
+		{name: "unknown kid", token: sign(jwt.SigningMethodRS256, "other", claims("cakestore", "cakestore-api", time.Minute), service.signing.private)},
+		// the public key must not work as an HMAC secret
+		{name: "HS256 signed with the public key", token: sign(jwt.SigningMethodHS256, kid, claims("cakestore", "cakestore-api", time.Minute), rsaPublicDER)},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, kid, claims("cakestore", "cakestore-api", time.Minute), jwt.UnsafeAllowNoneSignatureType)},
+		{name: "another RSA key", token: sign(jwt.SigningMethodRS256, kid, claims("cakestore", "cakestore-api", time.Minute), newRSAKey(t))},
+		{name: "malformed", token: "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Parse(tt.token)
+
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
+	"cakestore/internal/repository"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+	employeeInviteTTL    = 7 * 24 * time.Hour
+	accountTokenLength   = 48
+)
+
+type AccountUseCase interface {
+	// ForgotPassword emails a reset link, unknown addresses are ignored so the
+	// response does not reveal who has an account
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword sets a new password and signs the customer out everywhere
+	ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error
+	SendVerification(ctx context.Context, customerID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	EmployeeInviter
+}
+
+// EmployeeInviter sends and redeems the setup links of invited employees
+type EmployeeInviter interface {
+	// SendInvite emails a one-time link for choosing a password, replacing
+	// any earlier invite
+	SendInvite(ctx context.Context, customerID int64) error
+	// AcceptInvite sets the password of an invited employee and returns their id
+	AcceptInvite(ctx context.Context, request *model.AcceptInviteRequest) (int64, error)
+}
+
+type accountUseCase struct {
+	customerRepo     repository.CustomerRepository
+	accountTokenRepo repository.AccountTokenRepository
+	auth             AuthUseCase
+	mailer           notification.Channel
+	logger           *logrus.Logger
+	cache            database.RedisCache
+	baseURL          string
+}
+
+func NewAccountUseCase(
+	customerRepo repository.CustomerRepository,
+	accountTokenRepo repository.AccountTokenRepository,
+	auth AuthUseCase,
+	mailer notification.Channel,
+	logger *logrus.Logger,
+	cache database.RedisCache,
+	baseURL string,
+) AccountUseCase {
+	return &accountUseCase{
+		customerRepo:     customerRepo,
+		accountTokenRepo: accountTokenRepo,
+		auth:             auth,
+		mailer:           mailer,
+		logger:           logger,
+		cache:            cache,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (uc *accountUseCase) ForgotPassword(ctx context.Context, email string) error {
+	customer, err := uc.customerRepo.GetByEmail(ctx, email)
+	if err != nil {
+		uc.logger.Infof("Password reset requested for unknown email")
+		return nil
+	}
+
+	// Failures are only logged, failing for known addresses alone would
+	// reveal who has an account
+	raw, err := uc.issue(ctx, customer.ID, entity.AccountTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		uc.logger.Errorf("Error issuing password reset for customer %d: %v", customer.ID, err)
+		return nil
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", uc.baseURL, url.QueryEscape(raw))
+	_ = uc.send(ctx, notification.EventPasswordReset, customer, link, passwordResetTTL)
+	return nil
+}
+
+func (uc *accountUseCase) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error {
+	token, err := uc.consume(ctx, request.Token, entity.AccountTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	customer, err := uc.customerRepo.GetByID(ctx, token.CustomerID)
+	if err != nil {
+		return constants.ErrInvalidToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		uc.logger.Errorf("Error hashing password: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	customer.Password = string(hashedPassword)
+	// Following the emailed link proves the customer owns the address
+	if customer.EmailVerifiedAt == nil {
+		customer.EmailVerifiedAt = &now
+	}
+	customer.UpdatedAt = now
+	if err := uc.customerRepo.Update(ctx, customer); err != nil {
+		return err
+	}
+	uc.invalidateCustomer(ctx, customer.ID)
+
+	// Resetting the password lifts a lockout from failed logins, the customer
+	// lifts it themselves by proving they own the address
+	self := model.Actor{CustomerID: customer.ID, Role: customer.Role}
+	if err := uc.auth.UnlockAccount(ctx, self, customer.ID); err != nil {
+		uc.logger.Errorf("Error unlocking customer %d: %v", customer.ID, err)
+	}
+
+	return uc.auth.LogoutAll(ctx, customer.ID)
+}
+
+func (uc *accountUseCase) SendVerification(ctx context.Context, customerID int64) error {
+	customer, err := uc.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return constants.ErrNotFound
+	}
+	if customer.EmailVerifiedAt != nil {
+		return constants.ErrEmailAlreadyVerified
+	}
+
+	raw, err := uc.issue(ctx, customer.ID, entity.AccountTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/verify-email?token=%s", uc.baseURL, url.QueryEscape(raw))
+	return uc.send(ctx, notification.EventEmailVerification, customer, link, emailVerificationTTL)
+}
+
+func (uc *accountUseCase) VerifyEmail(ctx context.Context, raw string) error {
+	token, err := uc.consume(ctx, raw, entity.AccountTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	customer, err := uc.customerRepo.GetByID(ctx, token.CustomerID)
+	if err != nil {
+		return constants.ErrInvalidToken
+	}
+	if customer.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	customer.EmailVerifiedAt = &now
+	customer.UpdatedAt = now
+	if err := uc.customerRepo.Update(ctx, customer); err != nil {
+		return err
+	}
+	uc.invalidateCustomer(ctx, customer.ID)
+
+	return nil
+}
+
+func (uc *accountUseCase) SendInvite(ctx context.Context, customerID int64) error {
+	customer, err := uc.customerRepo.GetEmployeeByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	raw, err := uc.issue(ctx, customer.ID, entity.AccountTokenEmployeeInvite, employeeInviteTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/accept-invite?token=%s", uc.baseURL, url.QueryEscape(raw))
+	return uc.send(ctx, notification.EventEmployeeInvite, customer, link, employeeInviteTTL)
+}
+
+func (uc *accountUseCase) AcceptInvite(ctx context.Context, request *model.AcceptInviteRequest) (int64, error) {
+	token, err := uc.consume(ctx, request.Token, entity.AccountTokenEmployeeInvite)
+	if err != nil {
+		return 0, err
+	}
+
+	customer, err := uc.customerRepo.GetEmployeeByID(ctx, token.CustomerID)
+	if err != nil {
+		return 0, constants.ErrInvalidToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		uc.logger.Errorf("Error hashing password: %v", err)
+		return 0, err
+	}
+
+	now := time.Now()
+	customer.Password = string(hashedPassword)
+	// the invite was emailed, so following it verifies the address
+	if customer.EmailVerifiedAt == nil {
+		customer.EmailVerifiedAt = &now
+	}
+	customer.UpdatedAt = now
+	if err := uc.customerRepo.Update(ctx, customer); err != nil {
+		return 0, err
+	}
+	uc.invalidateCustomer(ctx, customer.ID)
+
+	return customer.ID, nil
+}
+
+// issue replaces any outstanding token of the same purpose with a new one and
+// returns the raw token, which is only ever sent by email
+func (uc *accountUseCase) issue(ctx context.Context, customerID int64, purpose entity.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	if err := uc.accountTokenRepo.InvalidateForCustomer(ctx, customerID, purpose); err != nil {
+		return "", err
+	}
+
+	raw, err := utils.GenerateRandomString(accountTokenLength)
+	if err != nil {
+		uc.logger.Errorf("Error generating account token: %v", err)
+		return "", err
+	}
+
+	now := time.Now()
+	if err := uc.accountTokenRepo.Create(ctx, &entity.AccountToken{
+		CustomerID: customerID,
+		Purpose:    purpose,
+		TokenHash:  utils.GenerateSHA256Hash(raw),
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+	}); err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+func (uc *accountUseCase) consume(ctx context.Context, raw string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error) {
+	token, err := uc.accountTokenRepo.GetByHash(ctx, utils.GenerateSHA256Hash(raw), purpose)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, constants.ErrInvalidToken
+	}
+
+	if err := uc.accountTokenRepo.Consume(ctx, token.ID); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// send mails the link straight away instead of queueing it in the
+// notification log, so the raw token is never stored
+func (uc *accountUseCase) send(ctx context.Context, event notification.Event, customer *entity.Customer, link string, ttl time.Duration) error {
+	message, err := notification.Render(event, customer.Email, map[string]any{
+		"Name":      customer.Name,
+		"Link":      link,
+		"ExpiresIn": humanDuration(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := uc.mailer.Send(ctx, message); err != nil {
+		uc.logger.Errorf("Error sending %s email to customer %d: %v", event, customer.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (uc *accountUseCase) invalidateCustomer(ctx context.Context, customerID int64) {
+	cacheKey := fmt.Sprintf("customer:%d", customerID)
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
+		uc.logger.Errorf("Error deleting cache for customer ID %d: %v", customerID, err)
+	}
+}
+
+func humanDuration(d time.Duration) string {
+	// a week reads better in days, shorter links keep hours
+	if days := int(d.Hours()) / 24; days >= 7 {
+		return fmt.Sprintf("%d days", days)
+	}
+	if hours := int(d.Hours()); hours > 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockAccountTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountTokenRepository) Create(ctx context.Context, token *entity.AccountToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAccountTokenRepository) GetByHash(ctx context.Context, hash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error) {
+	args := m.Called(ctx, hash, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.AccountToken), args.Error(1)
+}
+
+func (m *MockAccountTokenRepository) Consume(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAccountTokenRepository) InvalidateForCustomer(ctx context.Context, customerID int64, purpose entity.AccountTokenPurpose) error {
+	args := m.Called(ctx, customerID, purpose)
+	return args.Error(0)
+}
+
+type MockAuthUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUseCase) Login(ctx context.Context, request *model.LoginRequest, meta model.SessionMeta) (*model.TokenResponse, error) {
+	args := m.Called(ctx, request, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) LoginTwoFactor(ctx context.Context, request *model.TwoFactorLoginRequest, meta model.SessionMeta) (*model.TokenResponse, error) {
+	args := m.Called(ctx, request, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) IssueTokens(ctx context.Context, customer *entity.Customer, meta model.SessionMeta) (*model.TokenResponse, error) {
+	args := m.Called(ctx, customer, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) IssueDeviceToken(ctx context.Context, customer *entity.Customer, deviceID int64, ttl time.Duration) (*model.TokenResponse, error) {
+	args := m.Called(ctx, customer, deviceID, ttl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) UnlockAccount(ctx context.Context, actor model.Actor, customerID int64) error {
+	args := m.Called(ctx, actor, customerID)
+	return args.Error(0)
+}
+
+func (m *MockAuthUseCase) GetSessions(ctx context.Context, customerID int64) (*model.SessionsResponse, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SessionsResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) Refresh(ctx context.Context, refreshToken string, meta model.SessionMeta) (*model.TokenResponse, error) {
+	args := m.Called(ctx, refreshToken, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUseCase) Logout(ctx context.Context, customerID int64, tokenID string, expiresAt time.Time, refreshToken string) error {
+	args := m.Called(ctx, customerID, tokenID, expiresAt, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthUseCase) LogoutAll(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func (m *MockAuthUseCase) IsTokenRevoked(ctx context.Context, customerID int64, tokenID string, version int) (bool, error) {
+	args := m.Called(ctx, customerID, tokenID, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestAccountUseCase_ForgotPassword(t *testing.T) {
+	t.Run("emails a reset link and stores only the hash", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		mailer := new(MockChannel)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(&entity.Customer{ID: 1, Name: "Rina", Email: "rina@example.com"}, nil)
+		accountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(1), entity.AccountTokenPasswordReset).Return(nil)
+		accountTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AccountToken")).Return(nil)
+		mailer.On("Send", mock.Anything).Return(nil)
+
+		err := useCase.ForgotPassword(context.Background(), "rina@example.com")
+
+		assert.NoError(t, err)
+		message := mailer.Calls[0].Arguments.Get(0).(notification.Message)
+		assert.Equal(t, "rina@example.com", message.To)
+		assert.Contains(t, message.Body, "https://cakestore.test/reset-password?token=")
+
+		raw := strings.TrimSpace(strings.SplitN(strings.SplitN(message.Body, "token=", 2)[1], "\n", 2)[0])
+		stored := accountTokenRepo.Calls[1].Arguments.Get(1).(*entity.AccountToken)
+		assert.Equal(t, utils.GenerateSHA256Hash(raw), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(passwordResetTTL), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("unknown email is not revealed", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		mailer := new(MockChannel)
+		useCase := NewAccountUseCase(customerRepo, nil, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+		customerRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, errors.New("customer not found"))
+
+		err := useCase.ForgotPassword(context.Background(), "nobody@example.com")
+
+		assert.NoError(t, err)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("mail failure is not revealed", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		mailer := new(MockChannel)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(&entity.Customer{ID: 1, Name: "Rina", Email: "rina@example.com"}, nil)
+		accountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(1), entity.AccountTokenPasswordReset).Return(nil)
+		accountTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AccountToken")).Return(nil)
+		mailer.On("Send", mock.Anything).Return(errors.New("smtp unavailable"))
+
+		err := useCase.ForgotPassword(context.Background(), "rina@example.com")
+
+		assert.NoError(t, err)
+		mailer.AssertExpectations(t)
+	})
+}
+
+func TestAccountUseCase_ResetPassword(t *testing.T) {
+	t.Run("sets the password and revokes sessions", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		authUseCase := new(MockAuthUseCase)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, authUseCase, nil, logrus.New(), cache, "https://cakestore.test/")
+
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		accountTokenRepo.On("GetByHash", mock.Anything, utils.GenerateSHA256Hash("reset-token"), entity.AccountTokenPasswordReset).
+			Return(&entity.AccountToken{ID: 3, CustomerID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		accountTokenRepo.On("Consume", mock.Anything, int64(3)).Return(nil)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Password: "old"}, nil)
+		customerRepo.On("Update", mock.Anything, mock.MatchedBy(func(c *entity.Customer) bool {
+			return bcrypt.CompareHashAndPassword([]byte(c.Password), []byte("new-password")) == nil && c.EmailVerifiedAt != nil
+		})).Return(nil)
+		authUseCase.On("UnlockAccount", mock.Anything, mock.Anything, int64(1)).Return(nil)
+		authUseCase.On("LogoutAll", mock.Anything, int64(1)).Return(nil)
+
+		err := useCase.ResetPassword(context.Background(), &model.ResetPasswordRequest{Token: "reset-token", Password: "new-password"})
+
+		assert.NoError(t, err)
+		customerRepo.AssertExpectations(t)
+		authUseCase.AssertExpectations(t)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		accountTokenRepo := new(MockAccountTokenRepository)
+		useCase := NewAccountUseCase(nil, accountTokenRepo, nil, nil, logrus.New(), nil, "https://cakestore.test/")
+
+		accountTokenRepo.On("GetByHash", mock.Anything, mock.Anything, entity.AccountTokenPasswordReset).
+			Return(&entity.AccountToken{ID: 3, CustomerID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		err := useCase.ResetPassword(context.Background(), &model.ResetPasswordRequest{Token: "reset-token", Password: "new-password"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+		accountTokenRepo.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything)
+	})
+
+	t.Run("token already used", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, nil, logrus.New(), nil, "https://cakestore.test/")
+
+		usedAt := time.Now().Add(-time.Minute)
+		accountTokenRepo.On("GetByHash", mock.Anything, mock.Anything, entity.AccountTokenPasswordReset).
+			Return(&entity.AccountToken{ID: 3, CustomerID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+
+		err := useCase.ResetPassword(context.Background(), &model.ResetPasswordRequest{Token: "reset-token", Password: "new-password"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+		customerRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountUseCase_VerifyEmail(t *testing.T) {
+	t.Run("marks the email verified", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, nil, logrus.New(), cache, "https://cakestore.test/")
+
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		accountTokenRepo.On("GetByHash", mock.Anything, utils.GenerateSHA256Hash("verify-token"), entity.AccountTokenEmailVerification).
+			Return(&entity.AccountToken{ID: 4, CustomerID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		accountTokenRepo.On("Consume", mock.Anything, int64(4)).Return(nil)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1}, nil)
+		customerRepo.On("Update", mock.Anything, mock.MatchedBy(func(c *entity.Customer) bool {
+			return c.EmailVerifiedAt != nil
+		})).Return(nil)
+
+		err := useCase.VerifyEmail(context.Background(), "verify-token")
+
+		assert.NoError(t, err)
+		customerRepo.AssertExpectations(t)
+		cache.AssertCalled(t, "Delete", mock.Anything, "customer:1")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		accountTokenRepo := new(MockAccountTokenRepository)
+		useCase := NewAccountUseCase(nil, accountTokenRepo, nil, nil, logrus.New(), nil, "https://cakestore.test/")
+
+		accountTokenRepo.On("GetByHash", mock.Anything, mock.Anything, entity.AccountTokenEmailVerification).Return(nil, constants.ErrNotFound)
+
+		err := useCase.VerifyEmail(context.Background(), "nope")
+
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+}
+
+func TestAccountUseCase_SendVerification(t *testing.T) {
+	t.Run("already verified", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		mailer := new(MockChannel)
+		useCase := NewAccountUseCase(customerRepo, nil, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+		verifiedAt := time.Now()
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, EmailVerifiedAt: &verifiedAt}, nil)
+
+		err := useCase.SendVerification(context.Background(), 1)
+
+		assert.ErrorIs(t, err, constants.ErrEmailAlreadyVerified)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("sends a verification link", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		accountTokenRepo := new(MockAccountTokenRepository)
+		mailer := new(MockChannel)
+		useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Name: "Rina", Email: "rina@example.com"}, nil)
+		accountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(1), entity.AccountTokenEmailVerification).Return(nil)
+		accountTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AccountToken")).Return(nil)
+		mailer.On("Send", mock.MatchedBy(func(message notification.Message) bool {
+			return strings.Contains(message.Body, "https://cakestore.test/auth/verify-email?token=") &&
+				strings.Contains(message.Body, "48 hours")
+		})).Return(nil)
+
+		err := useCase.SendVerification(context.Background(), 1)
+
+		assert.NoError(t, err)
+		mailer.AssertExpectations(t)
+	})
+}
+
+func TestAccountUseCase_SendInvite(t *testing.T) {
+	customerRepo := new(MockCustomerRepository)
+	accountTokenRepo := new(MockAccountTokenRepository)
+	mailer := new(MockChannel)
+	useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, mailer, logrus.New(), nil, "https://cakestore.test/")
+
+	customerRepo.On("GetEmployeeByID", mock.Anything, int64(12)).Return(&entity.Customer{ID: 12, Name: "Sari", Email: "sari@example.com"}, nil)
+	accountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(12), entity.AccountTokenEmployeeInvite).Return(nil)
+	accountTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AccountToken")).Return(nil)
+	mailer.On("Send", mock.MatchedBy(func(message notification.Message) bool {
+		return strings.Contains(message.Body, "https://cakestore.test/accept-invite?token=") &&
+			strings.Contains(message.Body, "7 days")
+	})).Return(nil)
+
+	err := useCase.SendInvite(context.Background(), 12)
+
+	assert.NoError(t, err)
+	mailer.AssertExpectations(t)
+}
+
+func TestAccountUseCase_AcceptInvite(t *testing.T) {
+	customerRepo := new(MockCustomerRepository)
+	accountTokenRepo := new(MockAccountTokenRepository)
+	cache := new(database.MockRedisCacheService)
+	useCase := NewAccountUseCase(customerRepo, accountTokenRepo, nil, nil, logrus.New(), cache, "https://cakestore.test/")
+
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	accountTokenRepo.On("GetByHash", mock.Anything, utils.GenerateSHA256Hash("invite-token"), entity.AccountTokenEmployeeInvite).
+		Return(&entity.AccountToken{ID: 5, CustomerID: 12, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	accountTokenRepo.On("Consume", mock.Anything, int64(5)).Return(nil)
+	customerRepo.On("GetEmployeeByID", mock.Anything, int64(12)).Return(&entity.Customer{ID: 12, Role: "waitress"}, nil)
+	customerRepo.On("Update", mock.Anything, mock.MatchedBy(func(c *entity.Customer) bool {
+		return bcrypt.CompareHashAndPassword([]byte(c.Password), []byte("secret123")) == nil && c.EmailVerifiedAt != nil
+	})).Return(nil)
+
+	id, err := useCase.AcceptInvite(context.Background(), &model.AcceptInviteRequest{Token: "invite-token", Password: "secret123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), id)
+	customerRepo.AssertExpectations(t)
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// auditIgnoredFields are left out of the recorded changes, timestamps change
+// on every write and secrets must never end up in the log
+var auditIgnoredFields = map[string]bool{
+	"CreatedAt":  true,
+	"created_at": true,
+	"UpdatedAt":  true,
+	"updated_at": true,
+	"DeletedAt":  true,
+	"deleted_at": true,
+	"Password":   true,
+	"password":   true,
+	"PinHash":    true,
+	"TokenHash":  true,
+	"Secret":     true,
+}
+
+// AuditRecorder is what mutating usecases use to append to the audit log
+type AuditRecorder interface {
+	// Record stores who changed what, before and after are snapshots of the
+	// entity and are nil for created and deleted records. Failures are logged
+	// and never fail the change itself.
+	Record(ctx context.Context, actor model.Actor, action, entityType string, entityID any, before, after any)
+}
+
+type AuditUseCase interface {
+	AuditRecorder
+	GetAll(ctx context.Context, params *model.AuditLogQueryParams) (*model.PaginationResponse[[]model.AuditLogResponse], error)
+	// Export writes every matching entry as CSV, oldest first
+	Export(ctx context.Context, params *model.AuditLogQueryParams, w io.Writer) error
+}
+
+type auditUseCase struct {
+	repo repository.AuditRepository
+	log  *logrus.Logger
+}
+
+func NewAuditUseCase(repo repository.AuditRepository, log *logrus.Logger) AuditUseCase {
+	return &auditUseCase{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (u *auditUseCase) Record(ctx context.Context, actor model.Actor, action, entityType string, entityID any, before, after any) {
+	// The change already happened, it is recorded even if the request was cancelled since
+	ctx = context.WithoutCancel(ctx)
+	entry := &entity.AuditLog{
+		ActorID:    actor.CustomerID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		IPAddress:  actor.IPAddress,
+		RequestID:  actor.RequestID,
+	}
+
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		u.log.Errorf("Error computing audit changes for %s %s: %v", entityType, entry.EntityID, err)
+	} else if len(changes) > 0 {
+		data, err := json.Marshal(changes)
+		if err != nil {
+			u.log.Errorf("Error encoding audit changes for %s %s: %v", entityType, entry.EntityID, err)
+		}
+		entry.Changes = string(data)
+	}
+
+	if err := u.repo.Create(ctx, entry); err != nil {
+		u.log.Errorf("Error recording audit log %s %s %s by %d: %v", action, entityType, entry.EntityID, actor.CustomerID, err)
+	}
+}
+
+func (u *auditUseCase) GetAll(ctx context.Context, params *model.AuditLogQueryParams) (*model.PaginationResponse[[]model.AuditLogResponse], error) {
+	result, err := u.repo.GetAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.AuditLogResponse, len(result.Data))
+	for i := range result.Data {
+		responses[i] = model.ToAuditLogResponse(&result.Data[i])
+	}
+
+	return &model.PaginationResponse[[]model.AuditLogResponse]{
+		Data:       responses,
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		TotalPages: result.TotalPages,
+	}, nil
+}
+
+func (u *auditUseCase) Export(ctx context.Context, params *model.AuditLogQueryParams, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "created_at", "actor_id", "actor_role", "action", "entity_type", "entity_id", "changes", "ip_address", "request_id"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := u.repo.Each(ctx, params, func(logs []entity.AuditLog) error {
+		for _, log := range logs {
+			record := []string{
+				strconv.FormatInt(log.ID, 10),
+				log.CreatedAt.Format(time.RFC3339),
+				strconv.FormatInt(log.ActorID, 10),
+				log.ActorRole,
+				log.Action,
+				log.EntityType,
+				log.EntityID,
+				log.Changes,
+				log.IPAddress,
+				log.RequestID,
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// auditChanges compares the JSON form of two snapshots field by field
+func auditChanges(before, after any) (map[string]model.AuditChange, error) {
+	from, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]model.AuditChange{}
+	for field, value := range from {
+		if next, ok := to[field]; !ok || !reflect.DeepEqual(value, next) {
+			changes[field] = model.AuditChange{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, ok := from[field]; !ok {
+			changes[field] = model.AuditChange{To: value}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(snapshot any) (map[string]any, error) {
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return map[string]any{}, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for field := range fields {
+		if auditIgnoredFields[field] {
+			delete(fields, field)
+		}
+	}
+	return fields, nil
+}
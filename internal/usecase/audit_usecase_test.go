@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"bytes"
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) GetAll(ctx context.Context, params *model.AuditLogQueryParams) (*model.PaginationResponse[[]entity.AuditLog], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaginationResponse[[]entity.AuditLog]), args.Error(1)
+}
+
+func (m *MockAuditRepository) Each(ctx context.Context, params *model.AuditLogQueryParams, fn func(logs []entity.AuditLog) error) error {
+	args := m.Called(ctx, params, fn)
+	if logs, ok := args.Get(0).([]entity.AuditLog); ok {
+		if err := fn(logs); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+type MockAuditRecorder struct {
+	mock.Mock
+}
+
+func (m *MockAuditRecorder) Record(ctx context.Context, actor model.Actor, action, entityType string, entityID any, before, after any) {
+	m.Called(ctx, actor, action, entityType, entityID, before, after)
+}
+
+// newMockAuditRecorder accepts any audit entry, tests that care about the
+// entry assert on the recorded calls
+func newMockAuditRecorder() *MockAuditRecorder {
+	recorder := new(MockAuditRecorder)
+	recorder.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	return recorder
+}
+
+func TestAuditUseCase_Record(t *testing.T) {
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin, IPAddress: "10.0.0.5", RequestID: "req-1"}
+
+	t.Run("stores the actor and changed fields only", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		useCase := NewAuditUseCase(repo, logrus.New())
+		before := &entity.Menu{ID: 3, Title: "Black Forest", Price: 150000, UpdatedAt: time.Now().Add(-time.Hour)}
+		after := &entity.Menu{ID: 3, Title: "Black Forest", Price: 175000, UpdatedAt: time.Now()}
+
+		var stored *entity.AuditLog
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AuditLog")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*entity.AuditLog)
+		}).Return(nil)
+
+		useCase.Record(context.Background(), actor, constants.AuditActionUpdate, constants.AuditEntityMenu, int64(3), before, after)
+
+		assert.Equal(t, int64(1), stored.ActorID)
+		assert.Equal(t, constants.RoleAdmin, stored.ActorRole)
+		assert.Equal(t, "3", stored.EntityID)
+		assert.Equal(t, "10.0.0.5", stored.IPAddress)
+		assert.Equal(t, "req-1", stored.RequestID)
+
+		var changes map[string]model.AuditChange
+		assert.NoError(t, json.Unmarshal([]byte(stored.Changes), &changes))
+		assert.Equal(t, map[string]model.AuditChange{"Price": {From: 150000.0, To: 175000.0}}, changes)
+	})
+
+	t.Run("never records secrets", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		useCase := NewAuditUseCase(repo, logrus.New())
+
+		var stored *entity.AuditLog
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AuditLog")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*entity.AuditLog)
+		}).Return(nil)
+
+		useCase.Record(context.Background(), actor, constants.AuditActionCreate, constants.AuditEntityEmployee, int64(9), nil,
+			&entity.Customer{ID: 9, Name: "Sari", Password: "hashed-secret"})
+
+		assert.NotContains(t, stored.Changes, "hashed-secret")
+		assert.Contains(t, stored.Changes, "Sari")
+	})
+
+	t.Run("storage failures do not panic", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		useCase := NewAuditUseCase(repo, logrus.New())
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*entity.AuditLog")).Return(errors.New("db down"))
+
+		assert.NotPanics(t, func() {
+			useCase.Record(context.Background(), actor, constants.AuditActionDelete, constants.AuditEntityTable, int64(2), &model.TableResponse{ID: 2}, nil)
+		})
+	})
+
+	t.Run("records after the request was cancelled", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		useCase := NewAuditUseCase(repo, logrus.New())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		repo.On("Create", mock.MatchedBy(func(got context.Context) bool {
+			return got.Err() == nil
+		}), mock.AnythingOfType("*entity.AuditLog")).Return(nil)
+
+		useCase.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityTable, int64(2), &model.TableResponse{ID: 2}, nil)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestAuditUseCase_Export(t *testing.T) {
+	repo := new(MockAuditRepository)
+	useCase := NewAuditUseCase(repo, logrus.New())
+	params := &model.AuditLogQueryParams{EntityType: constants.AuditEntityMenu}
+	logs := []entity.AuditLog{
+		{ID: 1, ActorID: 1, ActorRole: constants.RoleAdmin, Action: constants.AuditActionCreate, EntityType: constants.AuditEntityMenu, EntityID: "3", CreatedAt: time.Now()},
+		{ID: 2, ActorID: 1, ActorRole: constants.RoleAdmin, Action: constants.AuditActionDelete, EntityType: constants.AuditEntityMenu, EntityID: "3", CreatedAt: time.Now()},
+	}
+	repo.On("Each", mock.Anything, params, mock.Anything).Return(logs, nil)
+
+	var buf bytes.Buffer
+	err := useCase.Export(context.Background(), params, &buf)
+
+	assert.NoError(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "entity_type", records[0][5])
+	assert.Equal(t, constants.AuditActionDelete, records[2][4])
+}
+
+func TestMenuUseCase_UpdateMenuRecordsAudit(t *testing.T) {
+	menuRepo := new(MockMenuRepository)
+	cache := new(database.MockRedisCacheService)
+	recorder := newMockAuditRecorder()
+	useCase := NewMenuUseCase(menuRepo, logrus.New(), cache, recorder)
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+
+	menuRepo.On("GetByID", mock.Anything, int64(3)).Return(&entity.Menu{ID: 3, Title: "Black Forest", Price: 150000}, nil)
+	menuRepo.On("UpdateMenu", mock.Anything, mock.AnythingOfType("*entity.Menu")).Return(nil)
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	cache.On("DeletePrefix", mock.Anything, menuListCachePrefix).Return(nil)
+
+	err := useCase.UpdateMenu(context.Background(), actor, &entity.Menu{ID: 3, Title: "Black Forest", Price: 175000})
+
+	assert.NoError(t, err)
+	recorder.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionUpdate, constants.AuditEntityMenu, int64(3),
+		mock.MatchedBy(func(before *entity.Menu) bool { return before.Price == 150000 }),
+		mock.MatchedBy(func(after *entity.Menu) bool { return after.Price == 175000 }))
+	cache.AssertExpectations(t)
+}
@@ -0,0 +1,499 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"cakestore/internal/token"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	refreshTokenLength = 48
+	// loginFreeAttempts failed logins in a row are allowed without delay, each
+	// further one locks the account for loginBaseDelay, doubling every time,
+	// until loginLockoutAttempts locks it for loginLockout
+	loginFreeAttempts    = 3
+	loginBaseDelay       = 5 * time.Second
+	loginLockoutAttempts = 10
+	loginLockout         = 30 * time.Minute
+	// loginFailureWindow is how long failed logins are remembered
+	loginFailureWindow = 24 * time.Hour
+	// loginHistoryLimit is how many login attempts GetSessions returns
+	loginHistoryLimit = 20
+)
+
+type AuthUseCase interface {
+	// Login checks the password and starts a session, or returns a two-factor
+	// challenge to complete with LoginTwoFactor
+	Login(ctx context.Context, request *model.LoginRequest, meta model.SessionMeta) (*model.TokenResponse, error)
+	// LoginTwoFactor exchanges a two-factor challenge and its code for a session
+	LoginTwoFactor(ctx context.Context, request *model.TwoFactorLoginRequest, meta model.SessionMeta) (*model.TokenResponse, error)
+	// IssueTokens starts a new session for an already authenticated customer
+	IssueTokens(ctx context.Context, customer *entity.Customer, meta model.SessionMeta) (*model.TokenResponse, error)
+	// IssueDeviceToken starts a PIN session bound to a POS terminal, it has no
+	// refresh token and only works together with the terminal's device token
+	IssueDeviceToken(ctx context.Context, customer *entity.Customer, deviceID int64, ttl time.Duration) (*model.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string, meta model.SessionMeta) (*model.TokenResponse, error)
+	// Logout revokes the access token identified by tokenID and, when given,
+	// the refresh token of the same session
+	Logout(ctx context.Context, customerID int64, tokenID string, expiresAt time.Time, refreshToken string) error
+	// LogoutAll revokes every access and refresh token issued to the customer
+	LogoutAll(ctx context.Context, customerID int64) error
+	IsTokenRevoked(ctx context.Context, customerID int64, tokenID string, version int) (bool, error)
+	// UnlockAccount clears failed logins and any lock on the account
+	UnlockAccount(ctx context.Context, actor model.Actor, customerID int64) error
+	// GetSessions returns the customer's active sessions and recent login attempts
+	GetSessions(ctx context.Context, customerID int64) (*model.SessionsResponse, error)
+}
+
+type authUseCase struct {
+	customerRepo     repository.CustomerRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	loginRepo        repository.LoginRepository
+	twoFactor        TwoFactorChallenger
+	logger           *logrus.Logger
+	cache            database.RedisCache
+	tokens           *token.Service
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+	audit            AuditRecorder
+}
+
+func NewAuthUseCase(
+	customerRepo repository.CustomerRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	loginRepo repository.LoginRepository,
+	twoFactor TwoFactorChallenger,
+	logger *logrus.Logger,
+	cache database.RedisCache,
+	tokens *token.Service,
+	accessTTL time.Duration,
+	refreshTTL time.Duration,
+	audit AuditRecorder,
+) AuthUseCase {
+	return &authUseCase{
+		customerRepo:     customerRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginRepo:        loginRepo,
+		twoFactor:        twoFactor,
+		logger:           logger,
+		cache:            cache,
+		tokens:           tokens,
+		accessTTL:        accessTTL,
+		refreshTTL:       refreshTTL,
+		audit:            audit,
+	}
+}
+
+func tokenVersionCacheKey(customerID int64) string {
+	return fmt.Sprintf("token_version:%d", customerID)
+}
+
+func revokedTokenCacheKey(tokenID string) string {
+	return fmt.Sprintf("revoked_token:%s", tokenID)
+}
+
+func (uc *authUseCase) Login(ctx context.Context, request *model.LoginRequest, meta model.SessionMeta) (*model.TokenResponse, error) {
+	event := &entity.LoginEvent{
+		Email:     request.Email,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+	}
+
+	customer, err := uc.customerRepo.GetByEmail(ctx, request.Email)
+	if err != nil {
+		event.Reason = "unknown email"
+		uc.recordLogin(ctx, event)
+		return nil, constants.ErrInvalidCredentials
+	}
+	event.CustomerID = customer.ID
+
+	// A locked account is rejected before the password is checked, so
+	// guessing on doesn't help even with the right password
+	lockout, err := uc.lockout(ctx, customer.ID)
+	if err != nil {
+		if errors.Is(err, constants.ErrAccountLocked) {
+			event.Reason = "locked"
+			uc.recordLogin(ctx, event)
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
+		event.Reason = "wrong password"
+		uc.recordLogin(ctx, event)
+		uc.recordFailure(ctx, customer.ID)
+		return nil, constants.ErrInvalidCredentials
+	}
+
+	// A login is recorded as suspicious unless an earlier one came from the
+	// same IP address with the same user agent, except for the very first login
+	seen, loggedInBefore, err := uc.loginRepo.HasLoggedInFrom(ctx, customer.ID, meta.IPAddress, meta.UserAgent)
+	if err != nil {
+		uc.logger.Errorf("Error checking login history of customer %d: %v", customer.ID, err)
+	} else if loggedInBefore && !seen {
+		event.Suspicious = true
+		event.Reason = "new device or IP address"
+		uc.logger.Warnf("Suspicious login for customer %d from %s (%s)", customer.ID, meta.IPAddress, meta.UserAgent)
+	}
+	event.Success = true
+	uc.recordLogin(ctx, event)
+
+	if uc.twoFactor != nil {
+		challenge, err := uc.twoFactor.Challenge(ctx, customer)
+		if err != nil {
+			return nil, err
+		}
+		// Failed logins are kept until the code is verified as well
+		if challenge != nil {
+			return challenge, nil
+		}
+	}
+
+	uc.clearFailures(ctx, customer.ID, lockout)
+	return uc.IssueTokens(ctx, customer, meta)
+}
+
+func (uc *authUseCase) LoginTwoFactor(ctx context.Context, request *model.TwoFactorLoginRequest, meta model.SessionMeta) (*model.TokenResponse, error) {
+	if uc.twoFactor == nil {
+		return nil, constants.ErrInvalidToken
+	}
+
+	customerID, err := uc.twoFactor.ChallengeCustomer(ctx, request.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	// Codes are guessed against the same lock as passwords
+	lockout, err := uc.lockout(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	customerID, recoveryCodes, err := uc.twoFactor.CompleteChallenge(ctx, request.ChallengeToken, request.Code)
+	if err != nil {
+		// Wrong codes count towards the same lockout as wrong passwords
+		if errors.Is(err, constants.ErrInvalidTwoFactorCode) && customerID != 0 {
+			uc.recordFailure(ctx, customerID)
+		}
+		return nil, err
+	}
+
+	customer, err := uc.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, constants.ErrUnauthorized
+	}
+
+	uc.clearFailures(ctx, customerID, lockout)
+	tokens, err := uc.IssueTokens(ctx, customer, meta)
+	if err != nil {
+		return nil, err
+	}
+	tokens.RecoveryCodes = recoveryCodes
+	return tokens, nil
+}
+
+func (uc *authUseCase) UnlockAccount(ctx context.Context, actor model.Actor, customerID int64) error {
+	if _, err := uc.customerRepo.GetByID(ctx, customerID); err != nil {
+		return constants.ErrNotFound
+	}
+	before, err := uc.loginRepo.GetLockout(ctx, customerID)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return err
+	}
+	if err := uc.loginRepo.Unlock(ctx, customerID); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, actor, constants.AuditActionUnlock, constants.AuditEntityCustomer, customerID, before, &entity.AccountLockout{CustomerID: customerID})
+	return nil
+}
+
+func (uc *authUseCase) GetSessions(ctx context.Context, customerID int64) (*model.SessionsResponse, error) {
+	sessions, err := uc.refreshTokenRepo.GetActiveForCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := uc.loginRepo.GetEvents(ctx, customerID, loginHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.SessionsResponse{
+		Active:  make([]model.SessionResponse, 0, len(sessions)),
+		History: make([]model.LoginEventResponse, 0, len(events)),
+	}
+	for i := range sessions {
+		response.Active = append(response.Active, model.ToSessionResponse(&sessions[i]))
+	}
+	for i := range events {
+		response.History = append(response.History, model.ToLoginEventResponse(&events[i]))
+	}
+	return response, nil
+}
+
+// lockout returns the failed logins of the customer, or ErrAccountLocked
+// while the account is locked
+func (uc *authUseCase) lockout(ctx context.Context, customerID int64) (*entity.AccountLockout, error) {
+	lockout, err := uc.loginRepo.GetLockout(ctx, customerID)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+	if lockout != nil && lockout.LockedUntil != nil && time.Now().Before(*lockout.LockedUntil) {
+		return nil, fmt.Errorf("%w until %s", constants.ErrAccountLocked, lockout.LockedUntil.Format(time.RFC3339))
+	}
+	return lockout, nil
+}
+
+// clearFailures forgets the failed logins once the whole login succeeded
+func (uc *authUseCase) clearFailures(ctx context.Context, customerID int64, lockout *entity.AccountLockout) {
+	if lockout == nil || lockout.FailedAttempts == 0 {
+		return
+	}
+	if err := uc.loginRepo.Unlock(ctx, customerID); err != nil {
+		uc.logger.Errorf("Error clearing failed logins of customer %d: %v", customerID, err)
+	}
+}
+
+// recordFailure counts a failed login and locks the account once the free
+// attempts are used up
+func (uc *authUseCase) recordFailure(ctx context.Context, customerID int64) {
+	failures, err := uc.loginRepo.RecordFailure(ctx, customerID, loginFailureWindow)
+	if err != nil {
+		uc.logger.Errorf("Error recording failed login of customer %d: %v", customerID, err)
+		return
+	}
+
+	delay := loginDelay(failures)
+	if delay == 0 {
+		return
+	}
+	if failures >= loginLockoutAttempts {
+		uc.logger.Warnf("Customer %d locked out after %d failed logins", customerID, failures)
+	}
+	if err := uc.loginRepo.Lock(ctx, customerID, time.Now().Add(delay)); err != nil {
+		uc.logger.Errorf("Error locking customer %d: %v", customerID, err)
+	}
+}
+
+// loginDelay is how long the account stays locked after the given number of
+// failed logins in a row
+func loginDelay(failures int) time.Duration {
+	if failures <= loginFreeAttempts {
+		return 0
+	}
+	if failures >= loginLockoutAttempts {
+		return loginLockout
+	}
+	return loginBaseDelay << (failures - loginFreeAttempts - 1)
+}
+
+func (uc *authUseCase) recordLogin(ctx context.Context, event *entity.LoginEvent) {
+	if err := uc.loginRepo.CreateEvent(ctx, event); err != nil {
+		uc.logger.Errorf("Error recording login of %s: %v", event.Email, err)
+	}
+}
+
+func (uc *authUseCase) IssueTokens(ctx context.Context, customer *entity.Customer, meta model.SessionMeta) (*model.TokenResponse, error) {
+	refreshToken, rawRefreshToken, err := uc.newRefreshToken(customer.ID, meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return uc.tokenResponse(customer, rawRefreshToken)
+}
+
+func (uc *authUseCase) IssueDeviceToken(ctx context.Context, customer *entity.Customer, deviceID int64, ttl time.Duration) (*model.TokenResponse, error) {
+	accessToken, err := uc.accessToken(customer, deviceID, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+	}, nil
+}
+
+func (uc *authUseCase) Refresh(ctx context.Context, rawRefreshToken string, meta model.SessionMeta) (*model.TokenResponse, error) {
+	current, err := uc.refreshTokenRepo.GetByHash(ctx, utils.GenerateSHA256Hash(rawRefreshToken))
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	// A refresh token is only ever used once, seeing a revoked one again means
+	// it was copied, so the whole family of sessions is shut down
+	if current.RevokedAt != nil {
+		uc.logger.Warnf("Refresh token reuse detected for customer %d, revoking all sessions", current.CustomerID)
+		if err := uc.LogoutAll(ctx, current.CustomerID); err != nil {
+			uc.logger.Errorf("Error revoking sessions for customer %d: %v", current.CustomerID, err)
+		}
+		return nil, constants.ErrUnauthorized
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, constants.ErrUnauthorized
+	}
+
+	customer, err := uc.customerRepo.GetByID(ctx, current.CustomerID)
+	if err != nil {
+		return nil, constants.ErrUnauthorized
+	}
+
+	next, rawNext, err := uc.newRefreshToken(customer.ID, meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.refreshTokenRepo.Rotate(ctx, current, next); err != nil {
+		return nil, err
+	}
+
+	return uc.tokenResponse(customer, rawNext)
+}
+
+func (uc *authUseCase) Logout(ctx context.Context, customerID int64, tokenID string, expiresAt time.Time, rawRefreshToken string) error {
+	if rawRefreshToken != "" {
+		refreshToken, err := uc.refreshTokenRepo.GetByHash(ctx, utils.GenerateSHA256Hash(rawRefreshToken))
+		if err != nil && !errors.Is(err, constants.ErrNotFound) {
+			return err
+		}
+		if err == nil && refreshToken.CustomerID == customerID {
+			if err := uc.refreshTokenRepo.Revoke(ctx, refreshToken.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The access token stays on the revocation list until it would have expired anyway
+	if ttl := time.Until(expiresAt); tokenID != "" && ttl > 0 {
+		if err := uc.cache.Set(ctx, revokedTokenCacheKey(tokenID), true, ttl); err != nil {
+			uc.logger.Errorf("Error revoking access token %s: %v", tokenID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *authUseCase) LogoutAll(ctx context.Context, customerID int64) error {
+	version, err := uc.customerRepo.IncrementTokenVersion(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if err := uc.refreshTokenRepo.RevokeAllForCustomer(ctx, customerID); err != nil {
+		return err
+	}
+
+	if err := uc.cache.Set(ctx, tokenVersionCacheKey(customerID), version, uc.accessTTL); err != nil {
+		uc.logger.Errorf("Error caching token version for customer %d: %v", customerID, err)
+		if err := uc.cache.Delete(ctx, tokenVersionCacheKey(customerID)); err != nil {
+			uc.logger.Errorf("Error deleting cache for token version of customer %d: %v", customerID, err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *authUseCase) IsTokenRevoked(ctx context.Context, customerID int64, tokenID string, version int) (bool, error) {
+	if tokenID != "" {
+		var revoked bool
+		err := uc.cache.Get(ctx, revokedTokenCacheKey(tokenID), &revoked)
+		if err == nil && revoked {
+			return true, nil
+		}
+		// Logouts are only recorded in the cache, so the token can't be
+		// trusted while it is unreadable
+		if err != nil && !errors.Is(err, database.ErrCacheMiss) {
+			uc.logger.Errorf("Error checking revocation of token %s: %v", tokenID, err)
+			return true, err
+		}
+	}
+
+	var current int
+	if err := uc.cache.Get(ctx, tokenVersionCacheKey(customerID), &current); err != nil {
+		// Deleted accounts fail here, so their tokens stop working as well
+		customer, err := uc.customerRepo.GetByID(ctx, customerID)
+		if errors.Is(err, constants.ErrNotFound) {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		current = customer.TokenVersion
+
+		if err := uc.cache.Set(ctx, tokenVersionCacheKey(customerID), current, uc.accessTTL); err != nil {
+			uc.logger.Errorf("Error caching token version for customer %d: %v", customerID, err)
+		}
+	}
+
+	return version < current, nil
+}
+
+func (uc *authUseCase) newRefreshToken(customerID int64, meta model.SessionMeta) (*entity.RefreshToken, string, error) {
+	raw, err := utils.GenerateRandomString(refreshTokenLength)
+	if err != nil {
+		uc.logger.Errorf("Error generating refresh token: %v", err)
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &entity.RefreshToken{
+		CustomerID: customerID,
+		TokenHash:  utils.GenerateSHA256Hash(raw),
+		UserAgent:  meta.UserAgent,
+		IPAddress:  meta.IPAddress,
+		ExpiresAt:  now.Add(uc.refreshTTL),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, raw, nil
+}
+
+func (uc *authUseCase) tokenResponse(customer *entity.Customer, rawRefreshToken string) (*model.TokenResponse, error) {
+	accessToken, err := uc.accessToken(customer, 0, uc.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(uc.accessTTL.Seconds()),
+	}, nil
+}
+
+func (uc *authUseCase) accessToken(customer *entity.Customer, deviceID int64, ttl time.Duration) (string, error) {
+	accessToken, err := uc.tokens.Issue(&token.Claims{
+		Email:        customer.Email,
+		Name:         customer.Name,
+		CustomerID:   customer.ID,
+		Role:         customer.Role,
+		TokenVersion: customer.TokenVersion,
+		DeviceID:     deviceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.FormatInt(customer.ID, 10),
+		},
+	}, ttl)
+	if err != nil {
+		uc.logger.Errorf("Error generating token: %v", err)
+		return "", err
+	}
+	return accessToken, nil
+}
@@ -0,0 +1,545 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/token"
+	"cakestore/utils"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, current, next *entity.RefreshToken) error {
+	args := m.Called(ctx, current, next)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForCustomer(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) GetActiveForCustomer(ctx context.Context, customerID int64) ([]entity.RefreshToken, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.RefreshToken), args.Error(1)
+}
+
+type MockLoginRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginRepository) CreateEvent(ctx context.Context, event *entity.LoginEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockLoginRepository) GetEvents(ctx context.Context, customerID int64, limit int) ([]entity.LoginEvent, error) {
+	args := m.Called(ctx, customerID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.LoginEvent), args.Error(1)
+}
+
+func (m *MockLoginRepository) HasLoggedInFrom(ctx context.Context, customerID int64, ipAddress, userAgent string) (bool, bool, error) {
+	args := m.Called(ctx, customerID, ipAddress, userAgent)
+	return args.Bool(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockLoginRepository) GetLockout(ctx context.Context, customerID int64) (*entity.AccountLockout, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.AccountLockout), args.Error(1)
+}
+
+func (m *MockLoginRepository) RecordFailure(ctx context.Context, customerID int64, window time.Duration) (int, error) {
+	args := m.Called(ctx, customerID, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginRepository) Lock(ctx context.Context, customerID int64, until time.Time) error {
+	args := m.Called(ctx, customerID, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginRepository) Unlock(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func newTestTokenService() *token.Service {
+	tokens, _ := token.NewService(token.Config{Secret: "secret", Issuer: "cakestore", Audience: "cakestore-api"})
+	return tokens
+}
+
+func TestAuthUseCase_Login(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	customer := &entity.Customer{ID: 1, Email: "rina@example.com", Password: string(hashed), Role: constants.RoleCustomer, TokenVersion: 3}
+
+	t.Run("success", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		loginRepo := new(MockLoginRepository)
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, loginRepo, nil, logrus.New(), nil, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		loginRepo.On("GetLockout", mock.Anything, mock.Anything).Return(nil, constants.ErrNotFound)
+		loginRepo.On("HasLoggedInFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, false, nil)
+		loginRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(customer, nil)
+		refreshTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *entity.RefreshToken) bool {
+			return token.CustomerID == 1 && token.UserAgent == "pos" && token.TokenHash != ""
+		})).Return(nil)
+
+		tokens, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "password123"}, model.SessionMeta{UserAgent: "pos"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, int64(900), tokens.ExpiresIn)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		claims, err := newTestTokenService().Parse(tokens.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), claims.CustomerID)
+		assert.Equal(t, 3, claims.TokenVersion)
+		assert.NotEmpty(t, claims.ID)
+
+		// Only the hash of the refresh token is stored
+		stored := refreshTokenRepo.Calls[0].Arguments.Get(1).(*entity.RefreshToken)
+		assert.Equal(t, utils.GenerateSHA256Hash(tokens.RefreshToken), stored.TokenHash)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		loginRepo := new(MockLoginRepository)
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, loginRepo, nil, logrus.New(), nil, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		loginRepo.On("GetLockout", mock.Anything, mock.Anything).Return(nil, constants.ErrNotFound)
+		loginRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+		loginRepo.On("RecordFailure", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(customer, nil)
+
+		tokens, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "wrong"}, model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		assert.Nil(t, tokens)
+		refreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUseCase_LoginLockout(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	customer := &entity.Customer{ID: 1, Email: "rina@example.com", Password: string(hashed), Role: constants.RoleCustomer}
+	meta := model.SessionMeta{UserAgent: "firefox", IPAddress: "10.0.0.9"}
+
+	newUseCase := func() (AuthUseCase, *MockCustomerRepository, *MockRefreshTokenRepository, *MockLoginRepository) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		loginRepo := new(MockLoginRepository)
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(customer, nil)
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, loginRepo, nil, logrus.New(), new(database.MockRedisCacheService), newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+		return useCase, customerRepo, refreshTokenRepo, loginRepo
+	}
+
+	t.Run("locked account is rejected even with the right password", func(t *testing.T) {
+		useCase, _, refreshTokenRepo, loginRepo := newUseCase()
+		until := time.Now().Add(time.Minute)
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(&entity.AccountLockout{CustomerID: 1, FailedAttempts: 5, LockedUntil: &until}, nil)
+		loginRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *entity.LoginEvent) bool {
+			return !e.Success && e.Reason == "locked"
+		})).Return(nil)
+
+		_, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "password123"}, meta)
+
+		assert.ErrorIs(t, err, constants.ErrAccountLocked)
+		refreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		loginRepo.AssertExpectations(t)
+	})
+
+	t.Run("failure past the free attempts locks the account", func(t *testing.T) {
+		useCase, _, _, loginRepo := newUseCase()
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		loginRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+		loginRepo.On("RecordFailure", mock.Anything, int64(1), loginFailureWindow).Return(loginFreeAttempts+1, nil)
+		loginRepo.On("Lock", mock.Anything, int64(1), mock.MatchedBy(func(until time.Time) bool {
+			return time.Until(until) > 0 && time.Until(until) <= loginBaseDelay
+		})).Return(nil)
+
+		_, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "wrong"}, meta)
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		loginRepo.AssertExpectations(t)
+	})
+
+	t.Run("free attempts don't lock", func(t *testing.T) {
+		useCase, _, _, loginRepo := newUseCase()
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		loginRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+		loginRepo.On("RecordFailure", mock.Anything, int64(1), loginFailureWindow).Return(2, nil)
+
+		_, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "wrong"}, meta)
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		loginRepo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("success clears failures and flags a new client", func(t *testing.T) {
+		useCase, _, refreshTokenRepo, loginRepo := newUseCase()
+		expired := time.Now().Add(-time.Minute)
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(&entity.AccountLockout{CustomerID: 1, FailedAttempts: 4, LockedUntil: &expired}, nil)
+		loginRepo.On("Unlock", mock.Anything, int64(1)).Return(nil)
+		loginRepo.On("HasLoggedInFrom", mock.Anything, int64(1), "10.0.0.9", "firefox").Return(false, true, nil)
+		loginRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *entity.LoginEvent) bool {
+			return e.Success && e.Suspicious && e.CustomerID == 1 && e.Reason == "new device or IP address"
+		})).Return(nil)
+		refreshTokenRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "password123"}, meta)
+
+		assert.NoError(t, err)
+		loginRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUseCase_UnlockAccount(t *testing.T) {
+	customerRepo := new(MockCustomerRepository)
+	loginRepo := new(MockLoginRepository)
+	recorder := newMockAuditRecorder()
+	useCase := NewAuthUseCase(customerRepo, new(MockRefreshTokenRepository), loginRepo, nil, logrus.New(), new(database.MockRedisCacheService), newTestTokenService(), 15*time.Minute, 24*time.Hour, recorder)
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	customerRepo.On("GetByID", mock.Anything, int64(7)).Return(&entity.Customer{ID: 7}, nil)
+	loginRepo.On("GetLockout", mock.Anything, int64(7)).Return(&entity.AccountLockout{CustomerID: 7, FailedAttempts: 5, LockedUntil: &lockedUntil}, nil)
+	loginRepo.On("Unlock", mock.Anything, int64(7)).Return(nil)
+
+	err := useCase.UnlockAccount(context.Background(), actor, 7)
+
+	assert.NoError(t, err)
+	loginRepo.AssertExpectations(t)
+	recorder.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionUnlock, constants.AuditEntityCustomer, int64(7),
+		mock.MatchedBy(func(before *entity.AccountLockout) bool { return before.FailedAttempts == 5 }),
+		mock.MatchedBy(func(after *entity.AccountLockout) bool { return after.FailedAttempts == 0 && after.LockedUntil == nil }))
+}
+
+func TestLoginDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), loginDelay(loginFreeAttempts))
+	assert.Equal(t, loginBaseDelay, loginDelay(loginFreeAttempts+1))
+	assert.Equal(t, 4*loginBaseDelay, loginDelay(loginFreeAttempts+3))
+	assert.Equal(t, loginLockout, loginDelay(loginLockoutAttempts))
+	assert.Equal(t, loginLockout, loginDelay(loginLockoutAttempts+5))
+}
+
+func TestAuthUseCase_Refresh(t *testing.T) {
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, nil, nil, logrus.New(), nil, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		current := &entity.RefreshToken{ID: 5, CustomerID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		refreshTokenRepo.On("GetByHash", mock.Anything, utils.GenerateSHA256Hash("old-token")).Return(current, nil)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Role: constants.RoleCustomer}, nil)
+		refreshTokenRepo.On("Rotate", mock.Anything, current, mock.AnythingOfType("*entity.RefreshToken")).Return(nil)
+
+		tokens, err := useCase.Refresh(context.Background(), "old-token", model.SessionMeta{})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, "old-token", tokens.RefreshToken)
+		refreshTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		useCase := NewAuthUseCase(nil, refreshTokenRepo, nil, nil, logrus.New(), nil, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		refreshTokenRepo.On("GetByHash", mock.Anything, mock.Anything).Return(nil, constants.ErrNotFound)
+
+		_, err := useCase.Refresh(context.Background(), "nope", model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrUnauthorized)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		useCase := NewAuthUseCase(nil, refreshTokenRepo, nil, nil, logrus.New(), nil, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		refreshTokenRepo.On("GetByHash", mock.Anything, mock.Anything).Return(&entity.RefreshToken{ID: 5, CustomerID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		_, err := useCase.Refresh(context.Background(), "old-token", model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrUnauthorized)
+		refreshTokenRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reused token revokes every session", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		revokedAt := time.Now().Add(-time.Minute)
+		refreshTokenRepo.On("GetByHash", mock.Anything, mock.Anything).Return(&entity.RefreshToken{ID: 5, CustomerID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+		customerRepo.On("IncrementTokenVersion", mock.Anything, int64(1)).Return(4, nil)
+		refreshTokenRepo.On("RevokeAllForCustomer", mock.Anything, int64(1)).Return(nil)
+		cache.On("Set", mock.Anything, "token_version:1", 4, 15*time.Minute).Return(nil)
+
+		_, err := useCase.Refresh(context.Background(), "old-token", model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrUnauthorized)
+		customerRepo.AssertExpectations(t)
+		refreshTokenRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUseCase_Logout(t *testing.T) {
+	t.Run("revokes access and refresh token", func(t *testing.T) {
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(nil, refreshTokenRepo, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		refreshTokenRepo.On("GetByHash", mock.Anything, utils.GenerateSHA256Hash("refresh")).Return(&entity.RefreshToken{ID: 5, CustomerID: 1}, nil)
+		refreshTokenRepo.On("Revoke", mock.Anything, int64(5)).Return(nil)
+		cache.On("Set", mock.Anything, "revoked_token:jti-1", true, mock.AnythingOfType("time.Duration")).Return(nil)
+
+		err := useCase.Logout(context.Background(), 1, "jti-1", time.Now().Add(10*time.Minute), "refresh")
+
+		assert.NoError(t, err)
+		refreshTokenRepo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("ignores refresh tokens of other customers", func(t *testing.T) {
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(nil, refreshTokenRepo, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		refreshTokenRepo.On("GetByHash", mock.Anything, mock.Anything).Return(&entity.RefreshToken{ID: 5, CustomerID: 2}, nil)
+		cache.On("Set", mock.Anything, "revoked_token:jti-1", true, mock.Anything).Return(nil)
+
+		err := useCase.Logout(context.Background(), 1, "jti-1", time.Now().Add(10*time.Minute), "refresh")
+
+		assert.NoError(t, err)
+		refreshTokenRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUseCase_IsTokenRevoked(t *testing.T) {
+	t.Run("revoked token id", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(nil, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, "revoked_token:jti-1", mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(2).(*bool) = true
+		}).Return(nil)
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("outdated token version", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(nil, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, "revoked_token:jti-1", mock.Anything).Return(database.ErrCacheMiss)
+		cache.On("Get", mock.Anything, "token_version:1", mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(2).(*int) = 2
+		}).Return(nil)
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 1)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("loads version on cache miss", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(customerRepo, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(database.ErrCacheMiss)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, TokenVersion: 2}, nil)
+		cache.On("Set", mock.Anything, "token_version:1", 2, 15*time.Minute).Return(nil)
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 2)
+
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("deleted account", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(customerRepo, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(database.ErrCacheMiss)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("unreadable revocation list fails closed", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(customerRepo, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, "revoked_token:jti-1", mock.Anything).Return(database.ErrRedisUnavailable)
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+
+		assert.ErrorIs(t, err, database.ErrRedisUnavailable)
+		assert.True(t, revoked)
+		customerRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unreachable database fails closed", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewAuthUseCase(customerRepo, nil, nil, nil, logrus.New(), cache, newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(database.ErrCacheMiss)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, errors.New("connection refused"))
+
+		revoked, err := useCase.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+
+		assert.Error(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestCustomerUseCase_UpdateEmployeeRoleRevokesTokens(t *testing.T) {
+	customerRepo := new(MockCustomerRepository)
+	cache := new(database.MockRedisCacheService)
+	securityCache := new(database.MockRedisCacheService)
+	useCase := NewCustomerUseCase(customerRepo, nil, logrus.New(), cache, securityCache, newMockAuditRecorder())
+	request := &model.UpdateUserRequest{Name: "Budi", Email: "budi@example.com", Address: "Jl. Merdeka"}
+
+	customerRepo.On("GetEmployeeByID", mock.Anything, int64(4)).Return(&entity.Customer{ID: 4, Role: constants.RoleAdmin}, nil)
+	customerRepo.On("UpdateEmployee", mock.Anything, int64(4), request, constants.RoleWaitress).Return(nil)
+	customerRepo.On("IncrementTokenVersion", mock.Anything, int64(4)).Return(1, nil)
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	securityCache.On("Delete", mock.Anything, "token_version:4").Return(nil)
+
+	err := useCase.UpdateEmployee(context.Background(), model.Actor{}, 4, request, constants.RoleWaitress)
+
+	assert.NoError(t, err)
+	customerRepo.AssertExpectations(t)
+	// Token versions live in the security cache whatever the cache backend
+	securityCache.AssertExpectations(t)
+	cache.AssertNotCalled(t, "Delete", mock.Anything, "token_version:4")
+}
+
+func TestTokenService(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string, private crypto.Signer) (string, string) {
+		privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+		publicDER, _ := x509.MarshalPKIXPublicKey(private.Public())
+		privatePath := filepath.Join(dir, name+".pem")
+		publicPath := filepath.Join(dir, name+".pub")
+		_ = os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600)
+		_ = os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600)
+		return privatePath, publicPath
+	}
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldPrivate, oldPublic := writeKey("old", rsaKey)
+	newPrivate, _ := writeKey("new", edKey)
+
+	oldService, err := token.NewService(token.Config{Algorithm: token.AlgorithmRS256, PrivateKeyFile: oldPrivate, Issuer: "cakestore", Audience: "cakestore-api"})
+	assert.NoError(t, err)
+	oldToken, err := oldService.Issue(&token.Claims{CustomerID: 1}, time.Minute)
+	assert.NoError(t, err)
+
+	t.Run("rotated keys still verify old tokens", func(t *testing.T) {
+		rotated, err := token.NewService(token.Config{
+			Algorithm:              token.AlgorithmEdDSA,
+			PrivateKeyFile:         newPrivate,
+			PreviousPublicKeyFiles: []string{oldPublic},
+			Issuer:                 "cakestore",
+			Audience:               "cakestore-api",
+		})
+		assert.NoError(t, err)
+
+		claims, err := rotated.Parse(oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), claims.CustomerID)
+
+		newToken, err := rotated.Issue(&token.Claims{CustomerID: 2}, time.Minute)
+		assert.NoError(t, err)
+		_, err = oldService.Parse(newToken)
+		assert.ErrorIs(t, err, token.ErrInvalidToken)
+
+		jwks := rotated.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		for _, key := range jwks.Keys {
+			assert.NotEmpty(t, key.KeyID)
+			assert.Contains(t, []string{"RS256", "EdDSA"}, key.Algorithm)
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		other, err := token.NewService(token.Config{Algorithm: token.AlgorithmRS256, PrivateKeyFile: oldPrivate, Issuer: "cakestore", Audience: "reporting"})
+		assert.NoError(t, err)
+
+		_, err = other.Parse(oldToken)
+		assert.ErrorIs(t, err, token.ErrInvalidToken)
+	})
+
+	t.Run("secrets are not published", func(t *testing.T) {
+		assert.Empty(t, newTestTokenService().JWKS().Keys)
+	})
+
+	t.Run("algorithm must match the key", func(t *testing.T) {
+		_, err := token.NewService(token.Config{Algorithm: token.AlgorithmEdDSA, PrivateKeyFile: oldPrivate})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,601 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type BillUseCase interface {
+	SplitBill(ctx context.Context, orderID int64, request *model.SplitBillRequest) (*model.BillResponse, error)
+	GetBill(ctx context.Context, orderID int64) (*model.BillResponse, error)
+	AddTender(ctx context.Context, actor model.Actor, orderID int64, request *model.TenderRequest) (*model.TenderResponse, error)
+	// ApplyGatewayStatus updates a Midtrans payment of the order, a checkout
+	// link or a share, from the payment webhook
+	ApplyGatewayStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error
+	// ApplyCredit records money collected outside the check, such as a reservation
+	// deposit of customerID, capped at the outstanding balance. The order must
+	// belong to customerID. An error means nothing was credited.
+	ApplyCredit(ctx context.Context, actor model.Actor, orderID, customerID int64, amount float64, method constants.PaymentMethod, reference string) (*model.TenderResponse, error)
+}
+
+type billUseCase struct {
+	billRepo       repository.BillRepository
+	orderRepo      repository.OrderRepository
+	paymentRepo    repository.PaymentRepository
+	paymentUseCase PaymentUseCase
+	notifier       NotificationUseCase
+	logger         *logrus.Logger
+	cache          database.RedisCache
+	audit          AuditRecorder
+}
+
+func NewBillUseCase(
+	billRepo repository.BillRepository,
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.PaymentRepository,
+	paymentUseCase PaymentUseCase,
+	notifier NotificationUseCase,
+	logger *logrus.Logger,
+	cache database.RedisCache,
+	audit AuditRecorder,
+) BillUseCase {
+	return &billUseCase{
+		billRepo:       billRepo,
+		orderRepo:      orderRepo,
+		paymentRepo:    paymentRepo,
+		paymentUseCase: paymentUseCase,
+		notifier:       notifier,
+		logger:         logger,
+		cache:          cache,
+		audit:          audit,
+	}
+}
+
+// amounts are kept to two decimals to avoid float drift when summing tenders
+func roundAmount(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func (uc *billUseCase) SplitBill(ctx context.Context, orderID int64, request *model.SplitBillRequest) (*model.BillResponse, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == entity.OrderStatusCancelled {
+		return nil, fmt.Errorf("%w: order is cancelled", constants.ErrInvalidSplit)
+	}
+
+	payments, err := uc.paymentRepo.GetPaymentsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range payments {
+		if payment.Status == constants.PaymentStatusSuccess {
+			return nil, constants.ErrBillHasPayments
+		}
+	}
+
+	var shares []entity.BillShare
+	switch entity.SplitMode(request.Mode) {
+	case entity.SplitModeEven:
+		shares, err = splitEvenly(order.TotalPrice, request.Parts)
+	case entity.SplitModeItem:
+		shares, err = splitByItem(order, request.Shares)
+	case entity.SplitModeCustom:
+		shares, err = splitByAmount(order.TotalPrice, request.Shares)
+	default:
+		err = fmt.Errorf("%w: unknown mode %q", constants.ErrInvalidSplit, request.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shares {
+		shares[i].OrderID = orderID
+		shares[i].Status = entity.ShareStatusUnpaid
+		if shares[i].Label == "" {
+			shares[i].Label = fmt.Sprintf("Guest %d", i+1)
+		}
+	}
+
+	split := &entity.BillSplit{
+		OrderID: orderID,
+		Mode:    entity.SplitMode(request.Mode),
+		Shares:  shares,
+	}
+	if err := uc.billRepo.ReplaceSplit(ctx, split); err != nil {
+		uc.logger.Errorf("Error saving bill split for order %d: %v", orderID, err)
+		return nil, err
+	}
+
+	return uc.GetBill(ctx, orderID)
+}
+
+func splitEvenly(total float64, parts int) ([]entity.BillShare, error) {
+	if parts < 2 {
+		return nil, fmt.Errorf("%w: parts must be at least 2", constants.ErrInvalidSplit)
+	}
+
+	// shares are whole currency units, the first guest picks up the remainder
+	base := math.Floor(total / float64(parts))
+	shares := make([]entity.BillShare, parts)
+	for i := range shares {
+		shares[i].Amount = base
+	}
+	shares[0].Amount = roundAmount(total - base*float64(parts-1))
+	return shares, nil
+}
+
+func splitByItem(order *entity.Order, requested []model.SplitShareRequest) ([]entity.BillShare, error) {
+	if len(requested) < 2 {
+		return nil, fmt.Errorf("%w: at least 2 shares are required", constants.ErrInvalidSplit)
+	}
+
+	itemTotals := make(map[int64]float64, len(order.Items))
+	for _, item := range order.Items {
+		itemTotals[item.ID] = item.Price * float64(item.Quantity)
+	}
+
+	assigned := make(map[int64]bool, len(order.Items))
+	shares := make([]entity.BillShare, len(requested))
+	for i, share := range requested {
+		if len(share.ItemIDs) == 0 {
+			return nil, fmt.Errorf("%w: share %d has no items", constants.ErrInvalidSplit, i+1)
+		}
+		var amount float64
+		for _, itemID := range share.ItemIDs {
+			itemTotal, ok := itemTotals[itemID]
+			if !ok {
+				return nil, fmt.Errorf("%w: item %d does not belong to the order", constants.ErrInvalidSplit, itemID)
+			}
+			if assigned[itemID] {
+				return nil, fmt.Errorf("%w: item %d is assigned twice", constants.ErrInvalidSplit, itemID)
+			}
+			assigned[itemID] = true
+			amount += itemTotal
+		}
+		shares[i] = entity.BillShare{
+			Label:   share.Label,
+			Amount:  roundAmount(amount),
+			ItemIDs: model.JoinItemIDs(share.ItemIDs),
+		}
+	}
+
+	if len(assigned) != len(itemTotals) {
+		return nil, fmt.Errorf("%w: every item must be assigned to a share", constants.ErrInvalidSplit)
+	}
+	return shares, nil
+}
+
+func splitByAmount(total float64, requested []model.SplitShareRequest) ([]entity.BillShare, error) {
+	if len(requested) < 2 {
+		return nil, fmt.Errorf("%w: at least 2 shares are required", constants.ErrInvalidSplit)
+	}
+
+	var sum float64
+	shares := make([]entity.BillShare, len(requested))
+	for i, share := range requested {
+		if share.Amount <= 0 {
+			return nil, fmt.Errorf("%w: share %d must have a positive amount", constants.ErrInvalidSplit, i+1)
+		}
+		sum += share.Amount
+		shares[i] = entity.BillShare{
+			Label:  share.Label,
+			Amount: roundAmount(share.Amount),
+		}
+	}
+
+	if roundAmount(sum) != roundAmount(total) {
+		return nil, fmt.Errorf("%w: shares add up to %.2f but the total is %.2f", constants.ErrInvalidSplit, sum, total)
+	}
+	return shares, nil
+}
+
+func (uc *billUseCase) GetBill(ctx context.Context, orderID int64) (*model.BillResponse, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := uc.billRepo.GetSplitByOrderID(ctx, orderID)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+
+	payments, err := uc.paymentRepo.GetPaymentsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBillResponse(order, split, payments), nil
+}
+
+func buildBillResponse(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) *model.BillResponse {
+	paidByShare := make(map[int64]float64)
+	pendingByShare := make(map[int64]float64)
+	var paid, pending float64
+	paymentResponses := make([]model.BillPaymentResponse, len(payments))
+	for i, payment := range payments {
+		paymentResponses[i] = model.ToBillPaymentResponse(&payment)
+		switch payment.Status {
+		case constants.PaymentStatusSuccess:
+			paid += payment.Amount
+			if payment.ShareID != nil {
+				paidByShare[*payment.ShareID] += payment.Amount
+			}
+		case constants.PaymentStatusPending:
+			pending += payment.Amount
+			if payment.ShareID != nil {
+				pendingByShare[*payment.ShareID] += payment.Amount
+			}
+		}
+	}
+
+	paid = roundAmount(paid)
+	remaining := math.Max(roundAmount(order.TotalPrice-paid), 0)
+	response := &model.BillResponse{
+		OrderID:     order.ID,
+		OrderStatus: string(order.Status),
+		Total:       order.TotalPrice,
+		Paid:        paid,
+		Pending:     roundAmount(pending),
+		Remaining:   remaining,
+		Settled:     remaining == 0,
+		Shares:      []model.BillShareResponse{},
+		Payments:    paymentResponses,
+	}
+
+	if split != nil {
+		response.Mode = string(split.Mode)
+		for _, share := range split.Shares {
+			sharePaid := roundAmount(paidByShare[share.ID])
+			response.Shares = append(response.Shares, model.BillShareResponse{
+				ID:        share.ID,
+				Label:     share.Label,
+				Amount:    share.Amount,
+				Paid:      sharePaid,
+				Pending:   roundAmount(pendingByShare[share.ID]),
+				Remaining: math.Max(roundAmount(share.Amount-sharePaid), 0),
+				ItemIDs:   model.SplitItemIDs(share.ItemIDs),
+				Status:    string(shareStatus(share.Amount, sharePaid)),
+			})
+		}
+	}
+
+	return response
+}
+
+func shareStatus(amount, paid float64) entity.ShareStatus {
+	switch {
+	case paid <= 0:
+		return entity.ShareStatusUnpaid
+	case roundAmount(paid) >= roundAmount(amount):
+		return entity.ShareStatusPaid
+	default:
+		return entity.ShareStatusPartiallyPaid
+	}
+}
+
+// tenderDue is what can still be tendered on the check or the share,
+// payment links the guests haven't paid yet hold their amount until they
+// settle or lapse
+func tenderDue(bill *model.BillResponse, shareID *int64) (float64, error) {
+	if bill.OrderStatus == string(entity.OrderStatusCancelled) {
+		return 0, fmt.Errorf("%w: order is cancelled", constants.ErrInvalidSplit)
+	}
+	if bill.Settled {
+		return 0, constants.ErrBillSettled
+	}
+
+	due := roundAmount(bill.Remaining - bill.Pending)
+	if shareID != nil {
+		share := findShare(bill, *shareID)
+		if share == nil {
+			return 0, constants.ErrNotFound
+		}
+		if share.Remaining == 0 {
+			return 0, constants.ErrBillSettled
+		}
+		due = math.Min(roundAmount(share.Remaining-share.Pending), due)
+	}
+	if due <= 0 {
+		return 0, fmt.Errorf("%w: pending payments cover the balance", constants.ErrAmountExceedsBalance)
+	}
+	return due, nil
+}
+
+func (uc *billUseCase) AddTender(ctx context.Context, actor model.Actor, orderID int64, request *model.TenderRequest) (*model.TenderResponse, error) {
+	bill, err := uc.GetBill(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	due, err := tenderDue(bill, request.ShareID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := roundAmount(request.Amount)
+	if amount == 0 {
+		amount = due
+	}
+	if amount > due {
+		return nil, constants.ErrAmountExceedsBalance
+	}
+
+	payment := &entity.Payment{
+		OrderID:   orderID,
+		ShareID:   request.ShareID,
+		Method:    constants.PaymentMethod(request.Method),
+		Amount:    amount,
+		Reference: request.Reference,
+	}
+
+	var change float64
+	switch payment.Method {
+	case constants.PaymentMethodCash:
+		if request.Tendered > 0 {
+			if roundAmount(request.Tendered) < amount {
+				return nil, fmt.Errorf("%w: tendered cash is less than the amount", constants.ErrInvalidRequest)
+			}
+			change = roundAmount(request.Tendered - amount)
+		}
+		payment.Status = constants.PaymentStatusSuccess
+	case constants.PaymentMethodCard:
+		payment.Status = constants.PaymentStatusSuccess
+	case constants.PaymentMethodMidtrans:
+		// the payment link is created once the payment is recorded, a tender
+		// rejected under the lock must not leave a payable link behind
+		payment.ExternalID = fmt.Sprintf("%s-%d-%s", constants.PaymentPrefixSplit, orderID, uuid.New().String())
+		payment.Status = constants.PaymentStatusPending
+	default:
+		return nil, fmt.Errorf("%w: unsupported payment method %q", constants.ErrInvalidRequest, request.Method)
+	}
+
+	// Checked again under the lock, another tender may have been taken
+	// since the bill was read
+	err = uc.billRepo.AddPayment(ctx, payment, func(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) error {
+		due, err := tenderDue(buildBillResponse(order, split, payments), request.ShareID)
+		if err != nil {
+			return err
+		}
+		if amount > due {
+			return constants.ErrAmountExceedsBalance
+		}
+		return nil
+	})
+	if err != nil {
+		uc.logger.Errorf("Error recording payment for order %d: %v", orderID, err)
+		return nil, err
+	}
+	if payment.Method == constants.PaymentMethodMidtrans {
+		if err := uc.createPaymentLink(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	tendered := model.ToBillPaymentResponse(payment)
+	uc.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityPayment, payment.ID, nil, tendered)
+
+	updated, err := uc.settle(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TenderResponse{
+		Payment:     tendered,
+		Change:      change,
+		RedirectURL: payment.PaymentURL,
+		Bill:        updated,
+	}, nil
+}
+
+func (uc *billUseCase) ApplyCredit(ctx context.Context, actor model.Actor, orderID, customerID int64, amount float64, method constants.PaymentMethod, reference string) (*model.TenderResponse, error) {
+	payment := &entity.Payment{
+		OrderID:   orderID,
+		Method:    method,
+		Status:    constants.PaymentStatusSuccess,
+		Reference: reference,
+	}
+	// The credit is capped at the balance as it is under the lock
+	err := uc.billRepo.AddPayment(ctx, payment, func(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) error {
+		if order.CustomerID != customerID {
+			return fmt.Errorf("%w: order belongs to another customer", constants.ErrInvalidRequest)
+		}
+		bill := buildBillResponse(order, split, payments)
+		if bill.OrderStatus == string(entity.OrderStatusCancelled) {
+			return fmt.Errorf("%w: order is cancelled", constants.ErrInvalidRequest)
+		}
+		if bill.Settled {
+			return constants.ErrBillSettled
+		}
+		payment.Amount = math.Min(roundAmount(amount), bill.Remaining)
+		return nil
+	})
+	if err != nil {
+		uc.logger.Errorf("Error recording credit for order %d: %v", orderID, err)
+		return nil, err
+	}
+
+	credited := model.ToBillPaymentResponse(payment)
+	uc.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityPayment, payment.ID, nil, credited)
+
+	// the credit is recorded, so settling is left to the next tender when it fails
+	updated, err := uc.settle(ctx, orderID)
+	if err != nil {
+		uc.logger.Errorf("Error settling order %d after the credit: %v", orderID, err)
+	}
+
+	return &model.TenderResponse{
+		Payment: credited,
+		Bill:    updated,
+	}, nil
+}
+
+// createPaymentLink opens the Midtrans transaction of a recorded payment. The
+// payment fails when the gateway refuses, so its amount can be tendered again.
+func (uc *billUseCase) createPaymentLink(ctx context.Context, payment *entity.Payment) error {
+	transaction, err := uc.paymentUseCase.CreateTransaction(ctx, payment.ExternalID, payment.Amount, 0)
+	if err != nil {
+		uc.logger.Errorf("Error creating Midtrans transaction for order %d: %v", payment.OrderID, err)
+		if err := uc.paymentRepo.UpdatePaymentStatus(ctx, payment.ID, constants.PaymentStatusFailed); err != nil {
+			uc.logger.Errorf("Error failing payment %d: %v", payment.ID, err)
+		}
+		return err
+	}
+
+	payment.PaymentToken = transaction.Token
+	payment.PaymentURL = transaction.RedirectURL
+	// the webhook finds the payment by its external ID, so the link still
+	// works when saving it fails
+	if err := uc.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+		uc.logger.Errorf("Error saving the payment link of payment %d: %v", payment.ID, err)
+	}
+	return nil
+}
+
+func findShare(bill *model.BillResponse, shareID int64) *model.BillShareResponse {
+	for i := range bill.Shares {
+		if bill.Shares[i].ID == shareID {
+			return &bill.Shares[i]
+		}
+	}
+	return nil
+}
+
+func (uc *billUseCase) ApplyGatewayStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error {
+	payment, err := uc.paymentRepo.GetPaymentByExternalID(ctx, externalID)
+	if err != nil {
+		return err
+	}
+	// a refunded payment stays refunded whatever the gateway resends
+	if payment.Status == constants.PaymentStatusRefunded {
+		uc.logger.Infof("Ignoring %s notification for refunded payment %d", status, payment.ID)
+		return nil
+	}
+
+	if err := uc.paymentRepo.UpdatePaymentStatus(ctx, payment.ID, status); err != nil {
+		uc.logger.Errorf("Error updating payment %d status: %v", payment.ID, err)
+		return err
+	}
+	payment.Status = status
+	statusCacheKey := fmt.Sprintf("order_status:%s", externalID)
+	if err := uc.cache.Delete(ctx, statusCacheKey); err != nil {
+		uc.logger.Errorf("Error deleting cache for order status %s: %v", externalID, err)
+	}
+
+	bill, err := uc.settle(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if status == constants.PaymentStatusSuccess && bill.OrderStatus == string(entity.OrderStatusCancelled) {
+		return uc.refundCancelled(ctx, payment)
+	}
+
+	// A lapsed checkout link only cancels the order when nothing else
+	// paid for it or still can
+	if strings.HasPrefix(externalID, constants.PaymentPrefixOrder+"-") &&
+		status != constants.PaymentStatusSuccess && status != constants.PaymentStatusPending {
+		return uc.cancelUnpaid(ctx, bill)
+	}
+	return nil
+}
+
+func (uc *billUseCase) cancelUnpaid(ctx context.Context, bill *model.BillResponse) error {
+	if bill.OrderStatus != string(entity.OrderStatusPending) || bill.Paid > 0 {
+		return nil
+	}
+	for _, payment := range bill.Payments {
+		if payment.Status == string(constants.PaymentStatusPending) {
+			return nil
+		}
+	}
+
+	// a payment may have settled the order since the bill was read
+	cancelled, err := uc.orderRepo.TransitionStatus(ctx, bill.OrderID, entity.OrderStatusPending, entity.OrderStatusCancelled)
+	if err != nil {
+		uc.logger.Errorf("Error cancelling unpaid order %d: %v", bill.OrderID, err)
+		return err
+	}
+	if !cancelled {
+		return nil
+	}
+	if order, err := uc.orderRepo.GetByID(ctx, bill.OrderID); err == nil {
+		// Invalidate cache
+		invalidateOrderCache(ctx, uc.cache, uc.logger, bill.OrderID, order.CustomerID)
+	}
+	return nil
+}
+
+// refundCancelled gives back a payment captured after its order was
+// cancelled, the order is not served anymore
+func (uc *billUseCase) refundCancelled(ctx context.Context, payment *entity.Payment) error {
+	reason := fmt.Sprintf("Payment for cancelled order %d", payment.OrderID)
+	if err := uc.paymentUseCase.RefundTransaction(ctx, payment.ExternalID, payment.Amount, reason); err != nil {
+		uc.logger.Errorf("Error refunding payment %d of cancelled order %d: %v", payment.ID, payment.OrderID, err)
+		return err
+	}
+	uc.logger.Warnf("Refunded payment %d, it was captured after order %d was cancelled", payment.ID, payment.OrderID)
+
+	before := model.ToBillPaymentResponse(payment)
+	if err := uc.paymentRepo.UpdatePaymentStatus(ctx, payment.ID, constants.PaymentStatusRefunded); err != nil {
+		uc.logger.Errorf("Error updating payment %d status: %v", payment.ID, err)
+		return err
+	}
+	payment.Status = constants.PaymentStatusRefunded
+	uc.audit.Record(ctx, model.Actor{}, constants.AuditActionRefund, constants.AuditEntityPayment, payment.ID, before, model.ToBillPaymentResponse(payment))
+	return nil
+}
+
+// settle refreshes share statuses and marks a pending order paid once the
+// successful payments cover its total
+func (uc *billUseCase) settle(ctx context.Context, orderID int64) (*model.BillResponse, error) {
+	bill, err := uc.GetBill(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentCacheKey := fmt.Sprintf("payment:order:%d", orderID)
+	if err := uc.cache.Delete(ctx, paymentCacheKey); err != nil {
+		uc.logger.Errorf("Error deleting cache for payment by order ID %d: %v", orderID, err)
+	}
+
+	for _, share := range bill.Shares {
+		if err := uc.billRepo.UpdateShareStatus(ctx, share.ID, entity.ShareStatus(share.Status)); err != nil {
+			uc.logger.Errorf("Error updating bill share %d status: %v", share.ID, err)
+			return nil, err
+		}
+	}
+
+	// orders already being prepared, delivered or cancelled keep their status
+	if bill.Settled && bill.OrderStatus == string(entity.OrderStatusPending) {
+		paid, err := uc.orderRepo.TransitionStatus(ctx, orderID, entity.OrderStatusPending, entity.OrderStatusPaid)
+		if err != nil {
+			uc.logger.Errorf("Error marking order %d as paid: %v", orderID, err)
+			return nil, err
+		}
+		if !paid {
+			return uc.GetBill(ctx, orderID)
+		}
+		bill.OrderStatus = string(entity.OrderStatusPaid)
+
+		if order, err := uc.orderRepo.GetByID(ctx, orderID); err == nil {
+			// Invalidate cache
+			invalidateOrderCache(ctx, uc.cache, uc.logger, orderID, order.CustomerID)
+			notifyOrderPaid(ctx, uc.notifier, uc.logger, order)
+		}
+	}
+
+	return bill, nil
+}
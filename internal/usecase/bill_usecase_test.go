@@ -0,0 +1,542 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBillRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillRepository) ReplaceSplit(ctx context.Context, split *entity.BillSplit) error {
+	args := m.Called(ctx, split)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) GetSplitByOrderID(ctx context.Context, orderID int64) (*entity.BillSplit, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BillSplit), args.Error(1)
+}
+
+func (m *MockBillRepository) UpdateShareStatus(ctx context.Context, shareID int64, status entity.ShareStatus) error {
+	args := m.Called(ctx, shareID, status)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) AddPayment(ctx context.Context, payment *entity.Payment, allow func(order *entity.Order, split *entity.BillSplit, payments []entity.Payment) error) error {
+	args := m.Called(ctx, payment, allow)
+	return args.Error(0)
+}
+
+// underLock runs the check given to AddPayment against the bill as it is
+// once the order is locked, the mocked call returns what the check returned
+func underLock(order *entity.Order, split *entity.BillSplit, payments []entity.Payment, result *error) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		allow := args.Get(2).(func(*entity.Order, *entity.BillSplit, []entity.Payment) error)
+		*result = allow(order, split, payments)
+	}
+}
+
+type MockPaymentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentUseCase) CreatePaymentURL(ctx context.Context, order *entity.Order) (*model.PaymentResponse, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) CreateTransaction(ctx context.Context, externalID string, amount float64, expiry time.Duration) (*model.PaymentResponse, error) {
+	args := m.Called(ctx, externalID, amount, expiry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) RefundTransaction(ctx context.Context, externalID string, amount float64, reason string) error {
+	args := m.Called(ctx, externalID, amount, reason)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) GetOrderStatus(ctx context.Context, orderID string) (string, error) {
+	args := m.Called(ctx, orderID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetPaymentByOrderID(ctx context.Context, order *entity.Order) (*entity.Payment, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func TestBillUseCase_SplitBill(t *testing.T) {
+	order := &entity.Order{
+		ID:         1,
+		Status:     entity.OrderStatusPending,
+		TotalPrice: 100,
+		Items: []entity.OrderItem{
+			{ID: 10, Price: 30, Quantity: 2},
+			{ID: 11, Price: 40, Quantity: 1},
+		},
+	}
+
+	t.Run("even split gives the remainder to the first share", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		saved := &entity.BillSplit{}
+		billRepo.On("ReplaceSplit", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			*saved = *args.Get(1).(*entity.BillSplit)
+		}).Return(nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(saved, nil)
+
+		bill, err := useCase.SplitBill(context.Background(), 1, &model.SplitBillRequest{Mode: "even", Parts: 3})
+
+		assert.NoError(t, err)
+		assert.Len(t, bill.Shares, 3)
+		assert.Equal(t, float64(34), bill.Shares[0].Amount)
+		assert.Equal(t, float64(33), bill.Shares[1].Amount)
+		assert.Equal(t, float64(33), bill.Shares[2].Amount)
+		assert.Equal(t, "Guest 1", bill.Shares[0].Label)
+	})
+
+	t.Run("item split requires every item once", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(nil, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		_, err := useCase.SplitBill(context.Background(), 1, &model.SplitBillRequest{
+			Mode: "item",
+			Shares: []model.SplitShareRequest{
+				{ItemIDs: []int64{10}},
+				{ItemIDs: []int64{10}},
+			},
+		})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidSplit)
+	})
+
+	t.Run("custom amounts must add up to the total", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(nil, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		_, err := useCase.SplitBill(context.Background(), 1, &model.SplitBillRequest{
+			Mode: "custom",
+			Shares: []model.SplitShareRequest{
+				{Amount: 50},
+				{Amount: 40},
+			},
+		})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidSplit)
+	})
+
+	t.Run("rejected once payments were taken", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(nil, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 1, Amount: 20, Status: constants.PaymentStatusSuccess},
+		}, nil)
+
+		_, err := useCase.SplitBill(context.Background(), 1, &model.SplitBillRequest{Mode: "even", Parts: 2})
+
+		assert.ErrorIs(t, err, constants.ErrBillHasPayments)
+	})
+}
+
+func TestBillUseCase_AddTender(t *testing.T) {
+	t.Run("cash with change settles the order", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		notifier := new(MockNotificationUseCase)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, notifier, logrus.New(), cache, newMockAuditRecorder())
+
+		notifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil).Once()
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodCash, Amount: 100, Status: constants.PaymentStatusSuccess},
+		}, nil)
+		orderRepo.On("TransitionStatus", mock.Anything, int64(1), entity.OrderStatusPending, entity.OrderStatusPaid).Return(true, nil).Once()
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		tender, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "cash", Tendered: 150})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(50), tender.Change)
+		assert.Equal(t, float64(100), tender.Payment.Amount)
+		assert.True(t, tender.Bill.Settled)
+		assert.Equal(t, string(entity.OrderStatusPaid), tender.Bill.OrderStatus)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("amount above the share balance", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(&entity.BillSplit{
+			OrderID: 1,
+			Mode:    entity.SplitModeEven,
+			Shares: []entity.BillShare{
+				{ID: 5, Amount: 50},
+				{ID: 6, Amount: 50},
+			},
+		}, nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		shareID := int64(5)
+		_, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{ShareID: &shareID, Method: "card", Amount: 60})
+
+		assert.ErrorIs(t, err, constants.ErrAmountExceedsBalance)
+		billRepo.AssertNotCalled(t, "AddPayment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pending payment links hold their amount", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodMidtrans, Amount: 60, Status: constants.PaymentStatusPending},
+		}, nil)
+
+		_, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "card", Amount: 50})
+
+		assert.ErrorIs(t, err, constants.ErrAmountExceedsBalance)
+		billRepo.AssertNotCalled(t, "AddPayment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rechecks the balance once the order is locked", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		// another terminal took cash for most of the check meanwhile
+		var allowed error
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Run(underLock(order, nil, []entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodCash, Amount: 80, Status: constants.PaymentStatusSuccess},
+		}, &allowed)).Return(constants.ErrAmountExceedsBalance)
+
+		_, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "card", Amount: 50})
+
+		assert.ErrorIs(t, err, constants.ErrAmountExceedsBalance)
+		assert.ErrorIs(t, allowed, constants.ErrAmountExceedsBalance)
+	})
+
+	t.Run("payment link is created once the payment is recorded", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		paymentUseCase := new(MockPaymentUseCase)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, paymentUseCase, nil, logrus.New(), cache, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+
+		var recorded bool
+		billRepo.On("AddPayment", mock.Anything, mock.MatchedBy(func(p *entity.Payment) bool {
+			return p.Status == constants.PaymentStatusPending && p.PaymentURL == ""
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			recorded = true
+			args.Get(1).(*entity.Payment).ID = 2
+		}).Return(nil)
+		paymentUseCase.On("CreateTransaction", mock.Anything, mock.Anything, float64(100), time.Duration(0)).Run(func(args mock.Arguments) {
+			assert.True(t, recorded)
+		}).Return(&model.PaymentResponse{Token: "token", RedirectURL: "https://pay.example/x"}, nil)
+		paymentRepo.On("UpdatePayment", mock.Anything, mock.MatchedBy(func(p *entity.Payment) bool {
+			return p.ID == 2 && p.PaymentURL == "https://pay.example/x"
+		})).Return(nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		tender, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "midtrans"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://pay.example/x", tender.RedirectURL)
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("no payment link when the locked check rejects", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		paymentUseCase := new(MockPaymentUseCase)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, paymentUseCase, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Return(constants.ErrAmountExceedsBalance)
+
+		_, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "midtrans", Amount: 50})
+
+		assert.ErrorIs(t, err, constants.ErrAmountExceedsBalance)
+		paymentUseCase.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("payment fails when the gateway refuses", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		paymentUseCase := new(MockPaymentUseCase)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, paymentUseCase, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{}, nil)
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.Payment).ID = 2
+		}).Return(nil)
+		paymentUseCase.On("CreateTransaction", mock.Anything, mock.Anything, float64(100), time.Duration(0)).Return(nil, errors.New("gateway down"))
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusFailed).Return(nil).Once()
+
+		_, err := useCase.AddTender(context.Background(), model.Actor{}, 1, &model.TenderRequest{Method: "midtrans"})
+
+		assert.Error(t, err)
+		paymentRepo.AssertExpectations(t)
+	})
+}
+
+func TestBillUseCase_ApplyCredit(t *testing.T) {
+	t.Run("credit above the balance is capped", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		notifier := new(MockNotificationUseCase)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, notifier, logrus.New(), cache, newMockAuditRecorder())
+
+		notifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		order := &entity.Order{ID: 1, CustomerID: 3, Status: entity.OrderStatusPending, TotalPrice: 80}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+
+		var allowed error
+		billRepo.On("AddPayment", mock.Anything, mock.MatchedBy(func(p *entity.Payment) bool {
+			return p.Method == constants.PaymentMethodDeposit
+		}), mock.Anything).Run(underLock(order, nil, []entity.Payment{}, &allowed)).Return(nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodDeposit, Amount: 80, Status: constants.PaymentStatusSuccess},
+		}, nil)
+		orderRepo.On("TransitionStatus", mock.Anything, int64(1), entity.OrderStatusPending, entity.OrderStatusPaid).Return(true, nil).Once()
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		tender, err := useCase.ApplyCredit(context.Background(), model.Actor{}, 1, 3, 100, constants.PaymentMethodDeposit, "reservation #7")
+
+		assert.NoError(t, err)
+		assert.NoError(t, allowed)
+		assert.Equal(t, float64(80), tender.Payment.Amount)
+		assert.True(t, tender.Bill.Settled)
+		billRepo.AssertExpectations(t)
+	})
+
+	t.Run("settled bill", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		useCase := NewBillUseCase(billRepo, nil, nil, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, CustomerID: 3, Status: entity.OrderStatusPaid, TotalPrice: 80}
+		var allowed error
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Run(underLock(order, nil, []entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodCash, Amount: 80, Status: constants.PaymentStatusSuccess},
+		}, &allowed)).Return(constants.ErrBillSettled)
+
+		_, err := useCase.ApplyCredit(context.Background(), model.Actor{}, 1, 3, 50, constants.PaymentMethodDeposit, "reservation #7")
+
+		assert.ErrorIs(t, err, constants.ErrBillSettled)
+		assert.ErrorIs(t, allowed, constants.ErrBillSettled)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		useCase := NewBillUseCase(billRepo, nil, nil, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, CustomerID: 4, Status: entity.OrderStatusPending, TotalPrice: 80}
+		var allowed error
+		billRepo.On("AddPayment", mock.Anything, mock.Anything, mock.Anything).Run(underLock(order, nil, []entity.Payment{}, &allowed)).Return(constants.ErrInvalidRequest)
+
+		_, err := useCase.ApplyCredit(context.Background(), model.Actor{}, 1, 3, 50, constants.PaymentMethodDeposit, "reservation #7")
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		assert.ErrorIs(t, allowed, constants.ErrInvalidRequest)
+	})
+}
+
+func TestBillUseCase_ApplyGatewayStatus(t *testing.T) {
+	t.Run("expired checkout link leaves the other tenders alone", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), cache, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentByExternalID", mock.Anything, "ORDER-1-a").Return(&entity.Payment{ID: 2, OrderID: 1, ExternalID: "ORDER-1-a"}, nil)
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusExpired).Return(nil).Once()
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 1, OrderID: 1, Method: constants.PaymentMethodCash, Amount: 40, Status: constants.PaymentStatusSuccess},
+			{ID: 2, OrderID: 1, Method: constants.PaymentMethodMidtrans, Amount: 100, Status: constants.PaymentStatusExpired, ExternalID: "ORDER-1-a"},
+		}, nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		err := useCase.ApplyGatewayStatus(context.Background(), "ORDER-1-a", constants.PaymentStatusExpired)
+
+		assert.NoError(t, err)
+		paymentRepo.AssertExpectations(t)
+		orderRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired checkout link cancels an unpaid order", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), cache, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, CustomerID: 3, Status: entity.OrderStatusPending, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentByExternalID", mock.Anything, "ORDER-1-a").Return(&entity.Payment{ID: 2, OrderID: 1, ExternalID: "ORDER-1-a"}, nil)
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusExpired).Return(nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 2, OrderID: 1, Method: constants.PaymentMethodMidtrans, Amount: 100, Status: constants.PaymentStatusExpired, ExternalID: "ORDER-1-a"},
+		}, nil)
+		orderRepo.On("TransitionStatus", mock.Anything, int64(1), entity.OrderStatusPending, entity.OrderStatusCancelled).Return(true, nil).Once()
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		err := useCase.ApplyGatewayStatus(context.Background(), "ORDER-1-a", constants.PaymentStatusExpired)
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("late payment keeps a preparing order", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, nil, nil, logrus.New(), cache, newMockAuditRecorder())
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusPreparing, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentByExternalID", mock.Anything, "SPLIT-1-a").Return(&entity.Payment{ID: 2, OrderID: 1, ExternalID: "SPLIT-1-a"}, nil)
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusSuccess).Return(nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 2, OrderID: 1, Method: constants.PaymentMethodMidtrans, Amount: 100, Status: constants.PaymentStatusSuccess, ExternalID: "SPLIT-1-a"},
+		}, nil)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		err := useCase.ApplyGatewayStatus(context.Background(), "SPLIT-1-a", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("payment on a cancelled order is refunded", func(t *testing.T) {
+		billRepo := new(MockBillRepository)
+		orderRepo := new(MockOrderRepository)
+		paymentRepo := new(MockPaymentRepository)
+		paymentUseCase := new(MockPaymentUseCase)
+		cache := new(database.MockRedisCacheService)
+		recorder := newMockAuditRecorder()
+		useCase := NewBillUseCase(billRepo, orderRepo, paymentRepo, paymentUseCase, nil, logrus.New(), cache, recorder)
+
+		order := &entity.Order{ID: 1, Status: entity.OrderStatusCancelled, TotalPrice: 100}
+		orderRepo.On("GetByID", mock.Anything, int64(1)).Return(order, nil)
+		billRepo.On("GetSplitByOrderID", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		paymentRepo.On("GetPaymentByExternalID", mock.Anything, "SPLIT-1-a").Return(&entity.Payment{ID: 2, OrderID: 1, Amount: 100, ExternalID: "SPLIT-1-a"}, nil)
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusSuccess).Return(nil)
+		paymentRepo.On("GetPaymentsByOrderID", mock.Anything, int64(1)).Return([]entity.Payment{
+			{ID: 2, OrderID: 1, Method: constants.PaymentMethodMidtrans, Amount: 100, Status: constants.PaymentStatusSuccess, ExternalID: "SPLIT-1-a"},
+		}, nil)
+		paymentUseCase.On("RefundTransaction", mock.Anything, "SPLIT-1-a", float64(100), mock.Anything).Return(nil)
+		paymentRepo.On("UpdatePaymentStatus", mock.Anything, int64(2), constants.PaymentStatusRefunded).Return(nil).Once()
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		err := useCase.ApplyGatewayStatus(context.Background(), "SPLIT-1-a", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		paymentUseCase.AssertExpectations(t)
+		paymentRepo.AssertExpectations(t)
+		orderRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		recorder.AssertCalled(t, "Record", mock.Anything, mock.Anything, constants.AuditActionRefund, constants.AuditEntityPayment, int64(2), mock.Anything, mock.Anything)
+	})
+
+	t.Run("refunded payment ignores later notifications", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		useCase := NewBillUseCase(nil, nil, paymentRepo, nil, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		paymentRepo.On("GetPaymentByExternalID", mock.Anything, "SPLIT-1-a").Return(&entity.Payment{ID: 2, OrderID: 1, Status: constants.PaymentStatusRefunded}, nil)
+
+		err := useCase.ApplyGatewayStatus(context.Background(), "SPLIT-1-a", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		paymentRepo.AssertNotCalled(t, "UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"cakestore/internal/domain/model"
+	"fmt"
+	"time"
+)
+
+const (
+	// cacheTTL is how long cached reads are served, GetOrLoad spreads it a
+	// little so keys don't all expire at once
+	cacheTTL = 5 * time.Minute
+	// cacheStaleTTL is how long busy lists may be served stale while they
+	// are reloaded in the background
+	cacheStaleTTL = time.Minute
+)
+
+// Prefixes of cached list pages. Page keys are built under the prefix, so a
+// change to any record can drop every page of the list with DeletePrefix.
+const (
+	menuListCachePrefix             = "menus:all:"
+	orderListCachePrefix            = "orders:all:"
+	inventoryListCachePrefix        = "inventory:all:"
+	tableListCachePrefix            = "tables:all:"
+	availableTablesCachePrefix      = "available_tables:"
+	reservationListCachePrefix      = "reservations:all:"
+	adminReservationListCachePrefix = "reservations:admin:all:"
+)
+
+// readCachePrefixes cover every key cached in front of the database, each can
+// be dropped and is loaded again on the next read. Revoked tokens, token
+// versions and two-factor challenges only exist in the cache and are left
+// out.
+var readCachePrefixes = []string{
+	"menu:", "menus:",
+	"inventory:", "low_stock_ingredients",
+	"table:", "tables:", "available_tables:",
+	"floor:",
+	"reservation:", "reservations:",
+	"deposit_rules:",
+	"order:", "orders:", "order_status:",
+	"payment:",
+	"cart:",
+	"wishlist:",
+	"customer:", "employee:", "employees",
+	"role_permissions:",
+	"pos_device:",
+}
+
+// customerOrdersCacheKey holds the order history of a customer
+func customerOrdersCacheKey(customerID int64) string {
+	return fmt.Sprintf("orders:customer:%d", customerID)
+}
+
+// pendingOrderCacheKey holds an order of a customer awaiting payment
+func pendingOrderCacheKey(customerID, orderID int64) string {
+	return fmt.Sprintf("order:pending:%d:%d", customerID, orderID)
+}
+
+// cartListCachePrefix is the prefix of the cached cart pages of a customer
+func cartListCachePrefix(customerID int64) string {
+	return fmt.Sprintf("cart:customer:%d:", customerID)
+}
+
+// wishlistCachePrefix is the prefix of the cached wishlist pages of a customer
+func wishlistCachePrefix(customerID int64) string {
+	return fmt.Sprintf("wishlist:%d:", customerID)
+}
+
+// cachedPage is a cached list page together with its pagination
+type cachedPage[T any] struct {
+	Data T
+	Meta *model.PaginatedMeta
+}
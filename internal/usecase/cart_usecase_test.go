@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -17,57 +18,57 @@ type MockCartRepository struct {
 	mock.Mock
 }
 
-func (m *MockCartRepository) Create(cart *entity.Cart) error {
-	args := m.Called(cart)
+func (m *MockCartRepository) Create(ctx context.Context, cart *entity.Cart) error {
+	args := m.Called(ctx, cart)
 	return args.Error(0)
 }
 
-func (m *MockCartRepository) GetByID(id int64) (*entity.Cart, error) {
-	args := m.Called(id)
+func (m *MockCartRepository) GetByID(ctx context.Context, id int64) (*entity.Cart, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Cart), args.Error(1)
 }
 
-func (m *MockCartRepository) GetByCustomerID(customerID int64, params *model.PaginationQuery) (*model.PaginationResponse[[]model.UserCartResponse], error) {
-	args := m.Called(customerID, params)
+func (m *MockCartRepository) GetByCustomerID(ctx context.Context, customerID int64, params *model.PaginationQuery) (*model.PaginationResponse[[]model.UserCartResponse], error) {
+	args := m.Called(ctx, customerID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.PaginationResponse[[]model.UserCartResponse]), args.Error(1)
 }
 
-func (m *MockCartRepository) GetByCustomerIDAndMenuID(customerID, menuID int64) (*entity.Cart, error) {
-	args := m.Called(customerID, menuID)
+func (m *MockCartRepository) GetByCustomerIDAndMenuID(ctx context.Context, customerID, menuID int64) (*entity.Cart, error) {
+	args := m.Called(ctx, customerID, menuID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Cart), args.Error(1)
 }
 
-func (m *MockCartRepository) Update(cart *entity.Cart) error {
-	args := m.Called(cart)
+func (m *MockCartRepository) Update(ctx context.Context, cart *entity.Cart) error {
+	args := m.Called(ctx, cart)
 	return args.Error(0)
 }
 
-func (m *MockCartRepository) RemoveItem(customerID, cartID int64) error {
-	args := m.Called(customerID, cartID)
+func (m *MockCartRepository) RemoveItem(ctx context.Context, customerID, cartID int64) error {
+	args := m.Called(ctx, customerID, cartID)
 	return args.Error(0)
 }
 
-func (m *MockCartRepository) ClearCustomerCart(customerID int64) error {
-	args := m.Called(customerID)
+func (m *MockCartRepository) ClearCustomerCart(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
 	return args.Error(0)
 }
 
-func (m *MockCartRepository) BulkDelete(customerID int64, cartIDs []int64) error {
-	args := m.Called(customerID, cartIDs)
+func (m *MockCartRepository) BulkDelete(ctx context.Context, customerID int64, cartIDs []int64) error {
+	args := m.Called(ctx, customerID, cartIDs)
 	return args.Error(0)
 }
 
-func (m *MockCartRepository) Delete(id int64) error {
-	args := m.Called(id)
+func (m *MockCartRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
@@ -89,10 +90,10 @@ func TestCartUseCase_GetCartByID(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCartRepo.On("GetByID", int64(1)).Return(expectedCart, nil).Once()
+		mockCartRepo.On("GetByID", mock.Anything, int64(1)).Return(expectedCart, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		cart, err := useCase.GetCartByID(1)
+		cart, err := useCase.GetCartByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, cart)
@@ -102,9 +103,9 @@ func TestCartUseCase_GetCartByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCartRepo.On("GetByID", int64(1)).Return(nil, errors.New("not found")).Once()
+		mockCartRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, errors.New("not found")).Once()
 
-		cart, err := useCase.GetCartByID(1)
+		cart, err := useCase.GetCartByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, cart)
@@ -134,10 +135,10 @@ func TestCartUseCase_GetCartByCustomerID(t *testing.T) {
 		}
 		params := &model.PaginationQuery{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCartRepo.On("GetByCustomerID", int64(1), params).Return(expectedResponse, nil).Once()
+		mockCartRepo.On("GetByCustomerID", mock.Anything, int64(1), params).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		carts, meta, err := useCase.GetCartByCustomerID(1, params)
+		carts, meta, err := useCase.GetCartByCustomerID(context.Background(), 1, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, carts)
@@ -149,9 +150,9 @@ func TestCartUseCase_GetCartByCustomerID(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.PaginationQuery{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCartRepo.On("GetByCustomerID", int64(1), params).Return(nil, errors.New("error")).Once()
+		mockCartRepo.On("GetByCustomerID", mock.Anything, int64(1), params).Return(nil, errors.New("error")).Once()
 
-		carts, meta, err := useCase.GetCartByCustomerID(1, params)
+		carts, meta, err := useCase.GetCartByCustomerID(context.Background(), 1, params)
 
 		assert.Error(t, err)
 		assert.Nil(t, carts)
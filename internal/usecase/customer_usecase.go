@@ -9,43 +9,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type CustomerUseCase interface {
-	Register(request *model.CreateCustomerRequest, role string) (*entity.Customer, error)
-	Login(request *model.LoginRequest) (*string, error)
-	GetCustomerByID(id int64) (*entity.Customer, error)
-	UpdateCustomer(id int64, request *model.UpdateUserRequest) error
-	GetEmployees() ([]entity.Customer, error)
-	GetEmployeeByID(id int64) (*entity.Customer, error)
-	UpdateEmployee(id int64, request *model.UpdateUserRequest, role string) error
-	DeleteEmployee(id int64) error
+	Register(ctx context.Context, request *model.CreateCustomerRequest, role string) (*entity.Customer, error)
+	GetCustomerByID(ctx context.Context, id int64) (*entity.Customer, error)
+	// UpdateCustomer reports whether the email changed, the new address is
+	// unverified until the customer follows a new verification link
+	UpdateCustomer(ctx context.Context, id int64, request *model.UpdateUserRequest) (bool, error)
+	GetEmployees(ctx context.Context) ([]entity.Customer, error)
+	GetEmployeeByID(ctx context.Context, id int64) (*entity.Customer, error)
+	UpdateEmployee(ctx context.Context, actor model.Actor, id int64, request *model.UpdateUserRequest, role string) error
+	DeleteEmployee(ctx context.Context, actor model.Actor, id int64) error
 }
 
 type customerUseCase struct {
-	repo      repository.CustomerRepository
-	logger    *logrus.Logger
-	jwtSecret string
-	cache     database.RedisCache
+	repo             repository.CustomerRepository
+	accountTokenRepo repository.AccountTokenRepository
+	logger           *logrus.Logger
+	cache            database.RedisCache
+	// securityCache holds the token versions checked on every request
+	securityCache database.RedisCache
+	audit         AuditRecorder
 }
 
-func NewCustomerUseCase(repo repository.CustomerRepository, logger *logrus.Logger, jwtSecret string, cache database.RedisCache) CustomerUseCase {
+func NewCustomerUseCase(repo repository.CustomerRepository, accountTokenRepo repository.AccountTokenRepository, logger *logrus.Logger, cache database.RedisCache, securityCache database.RedisCache, audit AuditRecorder) CustomerUseCase {
 	return &customerUseCase{
-		repo:      repo,
-		logger:    logger,
-		jwtSecret: jwtSecret,
-		cache:     cache,
+		repo:             repo,
+		accountTokenRepo: accountTokenRepo,
+		logger:           logger,
+		cache:            cache,
+		securityCache:    securityCache,
+		audit:            audit,
 	}
 }
 
-func (uc *customerUseCase) Register(request *model.CreateCustomerRequest, role string) (*entity.Customer, error) {
+func (uc *customerUseCase) Register(ctx context.Context, request *model.CreateCustomerRequest, role string) (*entity.Customer, error) {
 	// Check if email already exists
-	existingCustomer, err := uc.repo.GetByEmail(request.Email)
+	existingCustomer, err := uc.repo.GetByEmail(ctx, request.Email)
 	if err == nil && existingCustomer != nil {
 		return nil, errors.New("email already registered")
 	}
@@ -71,7 +77,7 @@ func (uc *customerUseCase) Register(request *model.CreateCustomerRequest, role s
 		UpdatedAt: time.Now(),
 	}
 
-	if err := uc.repo.Create(customer); err != nil {
+	if err := uc.repo.Create(ctx, customer); err != nil {
 		uc.logger.Errorf("Error creating customer: %v", err)
 		return nil, err
 	}
@@ -79,133 +85,87 @@ func (uc *customerUseCase) Register(request *model.CreateCustomerRequest, role s
 	return customer, nil
 }
 
-func (uc *customerUseCase) Login(request *model.LoginRequest) (*string, error) {
-	customer, err := uc.repo.GetByEmail(request.Email)
-	if err != nil {
-		return nil, errors.New("invalid email or password")
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
-	}
-
-	// Generate JWT token
-	claims := jwt.MapClaims{
-		"customer_id": customer.ID,
-		"email":       customer.Email,
-		"role":        customer.Role,
-		"exp":         time.Now().Add(time.Hour * 24).Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(uc.jwtSecret))
-	if err != nil {
-		uc.logger.Errorf("Error generating token: %v", err)
-		return nil, err
-	}
-
-	return &tokenString, nil
-}
-
-func (uc *customerUseCase) GetCustomerByID(id int64) (*entity.Customer, error) {
+func (uc *customerUseCase) GetCustomerByID(ctx context.Context, id int64) (*entity.Customer, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetCustomerByID took %v", time.Since(start))
 	}()
 
-	// Try to get the customer from the cache first
-	cacheKey := fmt.Sprintf("customer:%d", id)
-	var customer entity.Customer
-	if err := uc.cache.Get(context.Background(), cacheKey, &customer); err == nil {
-		uc.logger.Info("Customer fetched from cache")
-		return &customer, nil
-	}
-
-	// If not in cache, get from the database
-	customerEntity, err := uc.repo.GetByID(id)
+	customer, err := database.GetOrLoad(ctx, uc.cache, fmt.Sprintf("customer:%d", id), cacheTTL, func(ctx context.Context) (*entity.Customer, error) {
+		return uc.repo.GetByID(ctx, id)
+	})
 	if err != nil {
 		uc.logger.Errorf("Error getting customer by ID: %v", err)
 		return nil, err
 	}
 
-	// Store the customer in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, customerEntity, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for customer ID %d: %v", id, err)
-	}
-
-	return customerEntity, nil
+	return customer, nil
 }
 
-func (uc *customerUseCase) UpdateCustomer(id int64, request *model.UpdateUserRequest) error {
-	customer, err := uc.repo.GetByID(id)
+func (uc *customerUseCase) UpdateCustomer(ctx context.Context, id int64, request *model.UpdateUserRequest) (bool, error) {
+	customer, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	emailChanged := !strings.EqualFold(customer.Email, request.Email)
+	if emailChanged {
+		// links mailed to the old address must not verify or reset the new one
+		for _, purpose := range []entity.AccountTokenPurpose{entity.AccountTokenEmailVerification, entity.AccountTokenPasswordReset} {
+			if err := uc.accountTokenRepo.InvalidateForCustomer(ctx, id, purpose); err != nil {
+				return false, err
+			}
+		}
 	}
 
 	customer.Name = request.Name
 	customer.Address = request.Address
 	customer.Email = request.Email
+	if emailChanged {
+		customer.EmailVerifiedAt = nil
+	}
 	customer.UpdatedAt = time.Now()
 
-	if err := uc.repo.Update(customer); err != nil {
+	if err := uc.repo.Update(ctx, customer); err != nil {
 		uc.logger.Errorf("Error updating customer: %v", err)
-		return err
+		return false, err
 	}
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("customer:%d", id)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
 		uc.logger.Errorf("Error deleting cache for customer ID %d: %v", id, err)
 	}
 
-	return nil
+	return emailChanged, nil
 }
 
-func (uc *customerUseCase) GetEmployees() ([]entity.Customer, error) {
+func (uc *customerUseCase) GetEmployees(ctx context.Context) ([]entity.Customer, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetEmployees took %v", time.Since(start))
 	}()
 
-	// Try to get the employees from the cache first
-	cacheKey := "employees"
-	var employees []entity.Customer
-	if err := uc.cache.Get(context.Background(), cacheKey, &employees); err == nil {
-		uc.logger.Info("Employees fetched from cache")
-		return employees, nil
-	}
-
-	// If not in cache, get from the database
-	employees, err := uc.repo.GetEmployees()
+	employees, err := database.GetOrLoad(ctx, uc.cache, "employees", cacheTTL, func(ctx context.Context) ([]entity.Customer, error) {
+		return uc.repo.GetEmployees(ctx)
+	})
 	if err != nil {
 		uc.logger.Errorf("Error getting employees: %v", err)
 		return nil, err
 	}
 
-	// Store the employees in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, employees, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for employees: %v", err)
-	}
-
 	return employees, nil
 }
 
-func (uc *customerUseCase) GetEmployeeByID(id int64) (*entity.Customer, error) {
+func (uc *customerUseCase) GetEmployeeByID(ctx context.Context, id int64) (*entity.Customer, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetEmployeeByID took %v", time.Since(start))
 	}()
 
-	// Try to get the employee from the cache first
-	cacheKey := fmt.Sprintf("employee:%d", id)
-	var employee entity.Customer
-	if err := uc.cache.Get(context.Background(), cacheKey, &employee); err == nil {
-		uc.logger.Info("Employee fetched from cache")
-		return &employee, nil
-	}
-
-	// If not in cache, get from the database
-	employeeEntity, err := uc.repo.GetEmployeeByID(id)
+	employee, err := database.GetOrLoad(ctx, uc.cache, fmt.Sprintf("employee:%d", id), cacheTTL, func(ctx context.Context) (*entity.Customer, error) {
+		return uc.repo.GetEmployeeByID(ctx, id)
+	})
 	if err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
 			return nil, err
@@ -214,19 +174,17 @@ func (uc *customerUseCase) GetEmployeeByID(id int64) (*entity.Customer, error) {
 		return nil, err
 	}
 
-	// Store the employee in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, employeeEntity, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for employee ID %d: %v", id, err)
-	}
-
-	return employeeEntity, nil
+	return employee, nil
 }
 
-func (uc *customerUseCase) UpdateEmployee(id int64, request *model.UpdateUserRequest, role string) error {
-	employee, err := uc.repo.GetEmployeeByID(id)
+func (uc *customerUseCase) UpdateEmployee(ctx context.Context, actor model.Actor, id int64, request *model.UpdateUserRequest, role string) error {
+	employee, err := uc.repo.GetEmployeeByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	before := model.ToEmployeeResponse(employee)
+
+	roleChanged := role != "" && role != employee.Role
 
 	// Update fields
 	employee.Name = request.Name
@@ -237,35 +195,56 @@ func (uc *customerUseCase) UpdateEmployee(id int64, request *model.UpdateUserReq
 		employee.Role = role
 	}
 
-	if err := uc.repo.UpdateEmployee(id, request, role); err != nil {
+	if err := uc.repo.UpdateEmployee(ctx, id, request, role); err != nil {
 		uc.logger.Errorf("Error updating employee: %v", err)
 		return err
 	}
+	uc.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityEmployee, id, before, model.ToEmployeeResponse(employee))
+
+	// Tokens carry the role, so outstanding ones must not outlive a role change
+	if roleChanged {
+		if _, err := uc.repo.IncrementTokenVersion(ctx, id); err != nil {
+			uc.logger.Errorf("Error revoking tokens for employee %d: %v", id, err)
+			return err
+		}
+		if err := uc.securityCache.Delete(ctx, tokenVersionCacheKey(id)); err != nil {
+			uc.logger.Errorf("Error deleting cache for token version of employee %d: %v", id, err)
+		}
+	}
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("employee:%d", id)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
 		uc.logger.Errorf("Error deleting cache for employee ID %d: %v", id, err)
 	}
-	if err := uc.cache.Delete(context.Background(), "employees"); err != nil {
+	if err := uc.cache.Delete(ctx, "employees"); err != nil {
 		uc.logger.Errorf("Error deleting cache for employees: %v", err)
 	}
 
 	return nil
 }
 
-func (uc *customerUseCase) DeleteEmployee(id int64) error {
-	if err := uc.repo.DeleteEmployee(id); err != nil {
+func (uc *customerUseCase) DeleteEmployee(ctx context.Context, actor model.Actor, id int64) error {
+	employee, err := uc.repo.GetEmployeeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteEmployee(ctx, id); err != nil {
 		uc.logger.Errorf("Error deleting employee: %v", err)
 		return err
 	}
+	uc.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityEmployee, id, model.ToEmployeeResponse(employee), nil)
 
-	// Invalidate cache
+	// Invalidate cache, dropping the token version makes the next request
+	// look the employee up again and reject their tokens
 	cacheKey := fmt.Sprintf("employee:%d", id)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := uc.securityCache.Delete(ctx, tokenVersionCacheKey(id)); err != nil {
+		uc.logger.Errorf("Error deleting cache for token version of employee %d: %v", id, err)
+	}
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
 		uc.logger.Errorf("Error deleting cache for employee ID %d: %v", id, err)
 	}
-	if err := uc.cache.Delete(context.Background(), "employees"); err != nil {
+	if err := uc.cache.Delete(ctx, "employees"); err != nil {
 		uc.logger.Errorf("Error deleting cache for employees: %v", err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -17,60 +18,70 @@ type MockCustomerRepository struct {
 	mock.Mock
 }
 
-func (m *MockCustomerRepository) Create(customer *entity.Customer) error {
-	args := m.Called(customer)
+func (m *MockCustomerRepository) Create(ctx context.Context, customer *entity.Customer) error {
+	args := m.Called(ctx, customer)
 	return args.Error(0)
 }
 
-func (m *MockCustomerRepository) GetByEmail(email string) (*entity.Customer, error) {
-	args := m.Called(email)
+func (m *MockCustomerRepository) GetByEmail(ctx context.Context, email string) (*entity.Customer, error) {
+	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) GetByID(id int64) (*entity.Customer, error) {
-	args := m.Called(id)
+func (m *MockCustomerRepository) GetByID(ctx context.Context, id int64) (*entity.Customer, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) Update(customer *entity.Customer) error {
-	args := m.Called(customer)
+func (m *MockCustomerRepository) Update(ctx context.Context, customer *entity.Customer) error {
+	args := m.Called(ctx, customer)
 	return args.Error(0)
 }
 
-func (m *MockCustomerRepository) GetEmployees() ([]entity.Customer, error) {
-	args := m.Called()
+func (m *MockCustomerRepository) GetEmployees(ctx context.Context) ([]entity.Customer, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) GetEmployeeByID(id int64) (*entity.Customer, error) {
-	args := m.Called(id)
+func (m *MockCustomerRepository) GetEmployeeByID(ctx context.Context, id int64) (*entity.Customer, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) UpdateEmployee(id int64, request *model.UpdateUserRequest, role string) error {
-	args := m.Called(id, request, role)
+func (m *MockCustomerRepository) UpdateEmployee(ctx context.Context, id int64, request *model.UpdateUserRequest, role string) error {
+	args := m.Called(ctx, id, request, role)
 	return args.Error(0)
 }
 
-func (m *MockCustomerRepository) DeleteEmployee(id int64) error {
-	args := m.Called(id)
+func (m *MockCustomerRepository) DeleteEmployee(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockCustomerRepository) Delete(id int64) error {
-	args := m.Called(id)
+func (m *MockCustomerRepository) IncrementNoShows(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) IncrementTokenVersion(ctx context.Context, id int64) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
@@ -78,7 +89,7 @@ func TestCustomerUseCase_GetCustomerByID(t *testing.T) {
 	logger := logrus.New()
 	mockCustomerRepo := new(MockCustomerRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewCustomerUseCase(mockCustomerRepo, logger, "secret", mockCache)
+	useCase := NewCustomerUseCase(mockCustomerRepo, nil, logger, mockCache, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedCustomer := &entity.Customer{
@@ -91,10 +102,10 @@ func TestCustomerUseCase_GetCustomerByID(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetByID", int64(1)).Return(expectedCustomer, nil).Once()
+		mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(expectedCustomer, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		customer, err := useCase.GetCustomerByID(1)
+		customer, err := useCase.GetCustomerByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, customer)
@@ -104,9 +115,9 @@ func TestCustomerUseCase_GetCustomerByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetByID", int64(1)).Return(nil, errors.New("not found")).Once()
+		mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, errors.New("not found")).Once()
 
-		customer, err := useCase.GetCustomerByID(1)
+		customer, err := useCase.GetCustomerByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, customer)
@@ -118,7 +129,7 @@ func TestCustomerUseCase_GetEmployees(t *testing.T) {
 	logger := logrus.New()
 	mockCustomerRepo := new(MockCustomerRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewCustomerUseCase(mockCustomerRepo, logger, "secret", mockCache)
+	useCase := NewCustomerUseCase(mockCustomerRepo, nil, logger, mockCache, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedEmployees := []entity.Customer{
@@ -133,10 +144,10 @@ func TestCustomerUseCase_GetEmployees(t *testing.T) {
 			},
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetEmployees").Return(expectedEmployees, nil).Once()
+		mockCustomerRepo.On("GetEmployees", mock.Anything).Return(expectedEmployees, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		employees, err := useCase.GetEmployees()
+		employees, err := useCase.GetEmployees(context.Background())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, employees)
@@ -146,9 +157,9 @@ func TestCustomerUseCase_GetEmployees(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetEmployees").Return(nil, errors.New("error")).Once()
+		mockCustomerRepo.On("GetEmployees", mock.Anything).Return(nil, errors.New("error")).Once()
 
-		employees, err := useCase.GetEmployees()
+		employees, err := useCase.GetEmployees(context.Background())
 
 		assert.Error(t, err)
 		assert.Nil(t, employees)
@@ -160,7 +171,7 @@ func TestCustomerUseCase_GetEmployeeByID(t *testing.T) {
 	logger := logrus.New()
 	mockCustomerRepo := new(MockCustomerRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewCustomerUseCase(mockCustomerRepo, logger, "secret", mockCache)
+	useCase := NewCustomerUseCase(mockCustomerRepo, nil, logger, mockCache, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedEmployee := &entity.Customer{
@@ -173,10 +184,10 @@ func TestCustomerUseCase_GetEmployeeByID(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetEmployeeByID", int64(1)).Return(expectedEmployee, nil).Once()
+		mockCustomerRepo.On("GetEmployeeByID", mock.Anything, int64(1)).Return(expectedEmployee, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		employee, err := useCase.GetEmployeeByID(1)
+		employee, err := useCase.GetEmployeeByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, employee)
@@ -186,12 +197,57 @@ func TestCustomerUseCase_GetEmployeeByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockCustomerRepo.On("GetEmployeeByID", int64(1)).Return(nil, errors.New("not found")).Once()
+		mockCustomerRepo.On("GetEmployeeByID", mock.Anything, int64(1)).Return(nil, errors.New("not found")).Once()
 
-		employee, err := useCase.GetEmployeeByID(1)
+		employee, err := useCase.GetEmployeeByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, employee)
 		mockCustomerRepo.AssertExpectations(t)
 	})
 }
+
+func TestCustomerUseCase_UpdateCustomer(t *testing.T) {
+	logger := logrus.New()
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	t.Run("new email needs verifying again", func(t *testing.T) {
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockAccountTokenRepo := new(MockAccountTokenRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewCustomerUseCase(mockCustomerRepo, mockAccountTokenRepo, logger, mockCache, mockCache, newMockAuditRecorder())
+
+		mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Email: "old@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		mockAccountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(1), entity.AccountTokenEmailVerification).Return(nil).Once()
+		mockAccountTokenRepo.On("InvalidateForCustomer", mock.Anything, int64(1), entity.AccountTokenPasswordReset).Return(nil).Once()
+		mockCustomerRepo.On("Update", mock.Anything, mock.MatchedBy(func(customer *entity.Customer) bool {
+			return customer.Email == "new@example.com" && customer.EmailVerifiedAt == nil
+		})).Return(nil)
+		mockCache.On("Delete", mock.Anything, "customer:1").Return(nil)
+
+		emailChanged, err := useCase.UpdateCustomer(context.Background(), 1, &model.UpdateUserRequest{Name: "Test User", Email: "new@example.com"})
+
+		assert.NoError(t, err)
+		assert.True(t, emailChanged)
+		mockCustomerRepo.AssertExpectations(t)
+		mockAccountTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("same email stays verified", func(t *testing.T) {
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewCustomerUseCase(mockCustomerRepo, nil, logger, mockCache, mockCache, newMockAuditRecorder())
+
+		mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Email: "test@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		mockCustomerRepo.On("Update", mock.Anything, mock.MatchedBy(func(customer *entity.Customer) bool {
+			return customer.Name == "Renamed" && customer.EmailVerifiedAt != nil
+		})).Return(nil)
+		mockCache.On("Delete", mock.Anything, "customer:1").Return(nil)
+
+		emailChanged, err := useCase.UpdateCustomer(context.Background(), 1, &model.UpdateUserRequest{Name: "Renamed", Email: "Test@example.com"})
+
+		assert.NoError(t, err)
+		assert.False(t, emailChanged)
+		mockCustomerRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const depositRulesCacheKey = "deposit_rules:all"
+
+type DepositUseCase interface {
+	GetRules(ctx context.Context) ([]model.DepositRuleResponse, error)
+	CreateRule(ctx context.Context, actor model.Actor, request *model.DepositRuleRequest) (*model.DepositRuleResponse, error)
+	UpdateRule(ctx context.Context, actor model.Actor, id int64, request *model.DepositRuleRequest) (*model.DepositRuleResponse, error)
+	DeleteRule(ctx context.Context, actor model.Actor, id int64) error
+}
+
+type depositUseCase struct {
+	depositRepo repository.DepositRepository
+	log         *logrus.Logger
+	cache       database.RedisCache
+	audit       AuditRecorder
+}
+
+func NewDepositUseCase(depositRepo repository.DepositRepository, log *logrus.Logger, cache database.RedisCache, audit AuditRecorder) DepositUseCase {
+	return &depositUseCase{
+		depositRepo: depositRepo,
+		log:         log,
+		cache:       cache,
+		audit:       audit,
+	}
+}
+
+func (u *depositUseCase) GetRules(ctx context.Context) ([]model.DepositRuleResponse, error) {
+	start := time.Now()
+	defer func() {
+		u.log.Infof("GetRules took %v", time.Since(start))
+	}()
+
+	return database.GetOrLoad(ctx, u.cache, depositRulesCacheKey, cacheTTL, func(ctx context.Context) ([]model.DepositRuleResponse, error) {
+		rules, err := u.depositRepo.GetRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		responses := make([]model.DepositRuleResponse, 0, len(rules))
+		for i := range rules {
+			responses = append(responses, *model.ToDepositRuleResponse(&rules[i]))
+		}
+		return responses, nil
+	})
+}
+
+func (u *depositUseCase) CreateRule(ctx context.Context, actor model.Actor, request *model.DepositRuleRequest) (*model.DepositRuleResponse, error) {
+	rule := &entity.DepositRule{Active: true}
+	if err := applyDepositRuleRequest(rule, request); err != nil {
+		return nil, err
+	}
+	if err := u.depositRepo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	u.invalidateRules(ctx)
+	response := model.ToDepositRuleResponse(rule)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityDepositRule, rule.ID, nil, response)
+	return response, nil
+}
+
+func (u *depositUseCase) UpdateRule(ctx context.Context, actor model.Actor, id int64, request *model.DepositRuleRequest) (*model.DepositRuleResponse, error) {
+	rule, err := u.depositRepo.GetRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToDepositRuleResponse(rule)
+	if err := applyDepositRuleRequest(rule, request); err != nil {
+		return nil, err
+	}
+	if err := u.depositRepo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	u.invalidateRules(ctx)
+	response := model.ToDepositRuleResponse(rule)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityDepositRule, id, before, response)
+	return response, nil
+}
+
+func (u *depositUseCase) DeleteRule(ctx context.Context, actor model.Actor, id int64) error {
+	rule, err := u.depositRepo.GetRuleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.depositRepo.DeleteRule(ctx, id); err != nil {
+		return err
+	}
+
+	u.invalidateRules(ctx)
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityDepositRule, id, model.ToDepositRuleResponse(rule), nil)
+	return nil
+}
+
+func (u *depositUseCase) invalidateRules(ctx context.Context) {
+	if err := u.cache.Delete(ctx, depositRulesCacheKey); err != nil {
+		u.log.Errorf("Error deleting cache for deposit rules: %v", err)
+	}
+}
+
+func applyDepositRuleRequest(rule *entity.DepositRule, request *model.DepositRuleRequest) error {
+	if request.FlatAmount <= 0 && request.AmountPerGuest <= 0 {
+		return fmt.Errorf("%w: a flat amount or an amount per guest is required", constants.ErrInvalidRequest)
+	}
+	if request.StartTime != "" && request.StartTime >= request.EndTime {
+		return fmt.Errorf("%w: start_time must be before end_time", constants.ErrInvalidRequest)
+	}
+
+	rule.Name = request.Name
+	rule.MinGuests = request.MinGuests
+	rule.Weekday = request.Weekday
+	rule.StartTime = request.StartTime
+	rule.EndTime = request.EndTime
+	rule.FlatAmount = request.FlatAmount
+	rule.AmountPerGuest = request.AmountPerGuest
+	if request.Active != nil {
+		rule.Active = *request.Active
+	}
+	return nil
+}
+
+// matchDepositRule returns the applicable rule asking for the highest deposit,
+// or nil when the reservation needs no deposit
+func matchDepositRule(rules []entity.DepositRule, guests int, at time.Time) (*entity.DepositRule, float64) {
+	local := at.Local()
+	clock := local.Format("15:04")
+
+	var matched *entity.DepositRule
+	var amount float64
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Active || guests < rule.MinGuests {
+			continue
+		}
+		if rule.Weekday != nil && time.Weekday(*rule.Weekday) != local.Weekday() {
+			continue
+		}
+		if rule.StartTime != "" && (clock < rule.StartTime || clock >= rule.EndTime) {
+			continue
+		}
+		ruleAmount := roundAmount(rule.FlatAmount + rule.AmountPerGuest*float64(guests))
+		if ruleAmount > amount {
+			matched, amount = rule, ruleAmount
+		}
+	}
+	return matched, amount
+}
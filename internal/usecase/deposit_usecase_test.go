@@ -0,0 +1,560 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDepositRepository struct {
+	mock.Mock
+}
+
+func (m *MockDepositRepository) GetRules(ctx context.Context) ([]entity.DepositRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.DepositRule), args.Error(1)
+}
+
+func (m *MockDepositRepository) GetActiveRules(ctx context.Context) ([]entity.DepositRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.DepositRule), args.Error(1)
+}
+
+func (m *MockDepositRepository) GetRuleByID(ctx context.Context, id int64) (*entity.DepositRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.DepositRule), args.Error(1)
+}
+
+func (m *MockDepositRepository) CreateRule(ctx context.Context, rule *entity.DepositRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockDepositRepository) UpdateRule(ctx context.Context, rule *entity.DepositRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockDepositRepository) DeleteRule(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDepositRepository) CreateDeposit(ctx context.Context, deposit *entity.ReservationDeposit) error {
+	args := m.Called(ctx, deposit)
+	return args.Error(0)
+}
+
+func (m *MockDepositRepository) GetDepositByReservationID(ctx context.Context, reservationID uint) (*entity.ReservationDeposit, error) {
+	args := m.Called(ctx, reservationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReservationDeposit), args.Error(1)
+}
+
+func (m *MockDepositRepository) GetDepositByExternalID(ctx context.Context, externalID string) (*entity.ReservationDeposit, error) {
+	args := m.Called(ctx, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReservationDeposit), args.Error(1)
+}
+
+func (m *MockDepositRepository) TransitionDeposit(ctx context.Context, deposit *entity.ReservationDeposit, from entity.DepositStatus) (bool, error) {
+	args := m.Called(ctx, deposit, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDepositRepository) GetExpiredDeposits(ctx context.Context, now time.Time) ([]entity.ReservationDeposit, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.ReservationDeposit), args.Error(1)
+}
+
+type MockBillUseCase struct {
+	mock.Mock
+}
+
+func (m *MockBillUseCase) SplitBill(ctx context.Context, orderID int64, request *model.SplitBillRequest) (*model.BillResponse, error) {
+	args := m.Called(ctx, orderID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BillResponse), args.Error(1)
+}
+
+func (m *MockBillUseCase) GetBill(ctx context.Context, orderID int64) (*model.BillResponse, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BillResponse), args.Error(1)
+}
+
+func (m *MockBillUseCase) AddTender(ctx context.Context, actor model.Actor, orderID int64, request *model.TenderRequest) (*model.TenderResponse, error) {
+	args := m.Called(ctx, actor, orderID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TenderResponse), args.Error(1)
+}
+
+func (m *MockBillUseCase) ApplyGatewayStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error {
+	args := m.Called(ctx, externalID, status)
+	return args.Error(0)
+}
+
+func (m *MockBillUseCase) ApplyCredit(ctx context.Context, actor model.Actor, orderID, customerID int64, amount float64, method constants.PaymentMethod, reference string) (*model.TenderResponse, error) {
+	args := m.Called(ctx, actor, orderID, customerID, amount, method, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TenderResponse), args.Error(1)
+}
+
+func TestMatchDepositRule(t *testing.T) {
+	saturday := 6
+	rules := []entity.DepositRule{
+		{ID: 1, Name: "Large party", MinGuests: 6, FlatAmount: 100000, Active: true},
+		{ID: 2, Name: "Saturday dinner", MinGuests: 2, Weekday: &saturday, StartTime: "18:00", EndTime: "22:00", AmountPerGuest: 25000, Active: true},
+		{ID: 3, Name: "Disabled", MinGuests: 1, FlatAmount: 1000000, Active: false},
+	}
+	saturdayDinner := time.Date(2026, 10, 24, 19, 0, 0, 0, time.Local)
+	fridayDinner := time.Date(2026, 10, 23, 19, 0, 0, 0, time.Local)
+
+	t.Run("No matching rule", func(t *testing.T) {
+		rule, amount := matchDepositRule(rules, 2, fridayDinner)
+		assert.Nil(t, rule)
+		assert.Zero(t, amount)
+	})
+
+	t.Run("Weekday slot rule", func(t *testing.T) {
+		rule, amount := matchDepositRule(rules, 4, saturdayDinner)
+		assert.Equal(t, int64(2), rule.ID)
+		assert.Equal(t, float64(100000), amount)
+	})
+
+	t.Run("Highest deposit wins", func(t *testing.T) {
+		rule, amount := matchDepositRule(rules, 6, saturdayDinner)
+		assert.Equal(t, int64(2), rule.ID)
+		assert.Equal(t, float64(150000), amount)
+
+		rule, amount = matchDepositRule(rules, 6, fridayDinner)
+		assert.Equal(t, int64(1), rule.ID)
+		assert.Equal(t, float64(100000), amount)
+	})
+
+	t.Run("Outside time slot", func(t *testing.T) {
+		rule, _ := matchDepositRule(rules, 4, saturdayDinner.Add(4*time.Hour))
+		assert.Nil(t, rule)
+	})
+}
+
+func TestDepositUseCase_CreateRule(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockDepositRepository)
+		mockCache := new(database.MockRedisCacheService)
+		recorder := newMockAuditRecorder()
+		useCase := NewDepositUseCase(mockRepo, logger, mockCache, recorder)
+		actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+
+		mockRepo.On("CreateRule", mock.Anything, mock.AnythingOfType("*entity.DepositRule")).Return(nil)
+		mockCache.On("Delete", mock.Anything, "deposit_rules:all").Return(nil)
+
+		rule, err := useCase.CreateRule(context.Background(), actor, &model.DepositRuleRequest{Name: "Large party", MinGuests: 6, FlatAmount: 100000})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Large party", rule.Name)
+		assert.True(t, rule.Active)
+		mockRepo.AssertExpectations(t)
+		recorder.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionCreate, constants.AuditEntityDepositRule, mock.Anything, nil, rule)
+	})
+
+	t.Run("Missing amount", func(t *testing.T) {
+		mockRepo := new(MockDepositRepository)
+		useCase := NewDepositUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		_, err := useCase.CreateRule(context.Background(), model.Actor{}, &model.DepositRuleRequest{Name: "Free", MinGuests: 1})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockRepo.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid time slot", func(t *testing.T) {
+		mockRepo := new(MockDepositRepository)
+		useCase := NewDepositUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		_, err := useCase.CreateRule(context.Background(), model.Actor{}, &model.DepositRuleRequest{Name: "Late", MinGuests: 1, FlatAmount: 1, StartTime: "22:00", EndTime: "18:00"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+	})
+}
+
+func TestReservationUseCase_CreateWithDeposit(t *testing.T) {
+	mockReservationRepo := new(MockReservationRepository)
+	mockDepositRepo := new(MockDepositRepository)
+	mockPaymentUseCase := new(MockPaymentUseCase)
+	useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+	reserveDate := time.Now().Add(48 * time.Hour)
+
+	mockReservationRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Reservation")).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Reservation).ID = 7
+	}).Return(nil)
+	mockDepositRepo.On("GetActiveRules", mock.Anything).Return([]entity.DepositRule{
+		{ID: 1, MinGuests: 4, AmountPerGuest: 50000, Active: true},
+	}, nil)
+	mockPaymentUseCase.On("CreateTransaction", mock.Anything, mock.MatchedBy(func(id string) bool {
+		return len(id) > len("DEPOSIT-7-") && id[:len("DEPOSIT-7-")] == "DEPOSIT-7-"
+	}), float64(200000), 30*time.Minute).Return(&model.PaymentResponse{Token: "tok", RedirectURL: "https://pay"}, nil)
+	mockDepositRepo.On("CreateDeposit", mock.Anything, mock.MatchedBy(func(d *entity.ReservationDeposit) bool {
+		return d.ReservationID == 7 && d.Amount == 200000 && d.Status == entity.DepositStatusPending
+	})).Return(nil)
+	mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, GuestCount: 4, ReserveDate: reserveDate, Status: entity.ReservationStatusPending}, nil)
+
+	response, err := useCase.Create(context.Background(), 1, &model.CreateReservationRequest{GuestCount: 4, ReserveDate: reserveDate})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, response.Deposit)
+	assert.Equal(t, float64(200000), response.Deposit.Amount)
+	assert.Equal(t, "https://pay", response.Deposit.PaymentURL)
+	mockPaymentUseCase.AssertExpectations(t)
+	mockDepositRepo.AssertExpectations(t)
+}
+
+func TestReservationUseCase_ApplyDepositStatus(t *testing.T) {
+	t.Run("Paid deposit confirms reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		mockNotifier := new(MockNotificationUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, mockNotifier, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Status: entity.DepositStatusPending}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPending).Return(true, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, Status: entity.ReservationStatusPending}, nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *entity.Reservation) bool {
+			return r.Status == entity.ReservationStatusConfirmed
+		})).Return(nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusPaid, deposit.Status)
+		assert.NotNil(t, deposit.PaidAt)
+		mockReservationRepo.AssertExpectations(t)
+		mockNotifier.AssertCalled(t, "Notify", mock.Anything, notification.EventReservationConfirmed, int64(0), "7", mock.Anything)
+	})
+
+	t.Run("Expired deposit cancels reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Status: entity.DepositStatusPending}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPending).Return(true, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, Status: entity.ReservationStatusPending}, nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *entity.Reservation) bool {
+			return r.Status == entity.ReservationStatusCancelled
+		})).Return(nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusExpired)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusExpired, deposit.Status)
+		mockReservationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Payment after expiry is refunded", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		mockNotifier := new(MockNotificationUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, mockNotifier, newMockAuditRecorder())
+
+		mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusExpired}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusCancelled}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusExpired).Return(true, nil)
+		mockPaymentUseCase.On("RefundTransaction", mock.Anything, "DEPOSIT-7-x", float64(100000), mock.Anything).Return(nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusRefunded, deposit.Status)
+		mockPaymentUseCase.AssertExpectations(t)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockNotifier.AssertCalled(t, "Notify", mock.Anything, notification.EventRefundProcessed, int64(3), "DEPOSIT-7-x", mock.Anything)
+	})
+
+	t.Run("Failed refund of a late payment is retried", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusExpired}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusCancelled}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusExpired).Return(true, nil).Once()
+		mockPaymentUseCase.On("RefundTransaction", mock.Anything, "DEPOSIT-7-x", float64(100000), mock.Anything).Return(errors.New("gateway down"))
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, mock.MatchedBy(func(d *entity.ReservationDeposit) bool {
+			return d.Status == entity.DepositStatusExpired && d.PaidAt == nil
+		}), entity.DepositStatusRefunded).Return(true, nil).Once()
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusSuccess)
+
+		assert.Error(t, err)
+		mockDepositRepo.AssertExpectations(t)
+	})
+
+	t.Run("Payment racing the expiry is refunded", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		mockNotifier := new(MockNotificationUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, mockNotifier, newMockAuditRecorder())
+
+		mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusPending}
+		expired := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusExpired}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil).Once()
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPending).Return(false, nil)
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(expired, nil).Once()
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusCancelled}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, expired, entity.DepositStatusExpired).Return(true, nil)
+		mockPaymentUseCase.On("RefundTransaction", mock.Anything, "DEPOSIT-7-x", float64(100000), mock.Anything).Return(nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusSuccess)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusRefunded, expired.Status)
+		mockPaymentUseCase.AssertExpectations(t)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expiry racing the payment keeps the reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Status: entity.DepositStatusPending}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPending).Return(false, nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusExpired)
+
+		assert.NoError(t, err)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Late notification is ignored", func(t *testing.T) {
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(nil, logrus.New(), nil, nil, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Status: entity.DepositStatusPaid}
+
+		mockDepositRepo.On("GetDepositByExternalID", mock.Anything, "DEPOSIT-7-x").Return(deposit, nil)
+
+		err := useCase.ApplyDepositStatus(context.Background(), "DEPOSIT-7-x", constants.PaymentStatusExpired)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusPaid, deposit.Status)
+		mockDepositRepo.AssertNotCalled(t, "TransitionDeposit", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReservationUseCase_MarkNoShow(t *testing.T) {
+	t.Run("Forfeits paid deposit", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, mockCustomerRepo, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, Status: entity.DepositStatusPaid}
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusConfirmed}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(true, nil)
+		mockCustomerRepo.On("IncrementNoShows", mock.Anything, int64(3)).Return(nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Reservation")).Return(nil)
+
+		response, err := useCase.MarkNoShow(context.Background(), model.Actor{}, 7, &model.NoShowRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, string(entity.ReservationStatusNoShow), response.Status)
+		assert.Equal(t, entity.DepositStatusForfeited, deposit.Status)
+		mockPaymentUseCase.AssertNotCalled(t, "RefundTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockCustomerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refunds paid deposit", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		mockNotifier := new(MockNotificationUseCase)
+		recorder := newMockAuditRecorder()
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, mockCustomerRepo, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, mockNotifier, recorder)
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusPaid}
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusConfirmed}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockPaymentUseCase.On("RefundTransaction", mock.Anything, "DEPOSIT-7-x", float64(100000), mock.Anything).Return(nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(true, nil)
+		mockCustomerRepo.On("IncrementNoShows", mock.Anything, int64(3)).Return(nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Reservation")).Return(nil)
+
+		_, err := useCase.MarkNoShow(context.Background(), model.Actor{}, 7, &model.NoShowRequest{RefundDeposit: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.DepositStatusRefunded, deposit.Status)
+		mockPaymentUseCase.AssertExpectations(t)
+		recorder.AssertCalled(t, "Record", mock.Anything, mock.Anything, constants.AuditActionRefund, constants.AuditEntityDeposit, int64(1), mock.Anything, mock.Anything)
+	})
+
+	t.Run("Completed reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, mockCustomerRepo, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, Status: entity.ReservationStatusCompleted}, nil)
+
+		_, err := useCase.MarkNoShow(context.Background(), model.Actor{}, 7, &model.NoShowRequest{})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidReservationStatus)
+		mockCustomerRepo.AssertNotCalled(t, "IncrementNoShows", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReservationUseCase_ApplyDeposit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockBillUseCase := new(MockBillUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, mockBillUseCase, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, Status: entity.DepositStatusPaid}
+
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3, Status: entity.ReservationStatusCompleted}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(true, nil)
+		mockBillUseCase.On("ApplyCredit", mock.Anything, mock.Anything, int64(12), int64(3), float64(100000), constants.PaymentMethodDeposit, "reservation #7").
+			Return(&model.TenderResponse{Payment: model.BillPaymentResponse{Amount: 100000, Method: string(constants.PaymentMethodDeposit)}}, nil)
+
+		tender, err := useCase.ApplyDeposit(context.Background(), model.Actor{}, 7, &model.ApplyDepositRequest{OrderID: 12})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100000), tender.Payment.Amount)
+		assert.Equal(t, entity.DepositStatusApplied, deposit.Status)
+		assert.Equal(t, int64(12), *deposit.AppliedOrderID)
+	})
+
+	t.Run("Unpaid deposit", func(t *testing.T) {
+		mockDepositRepo := new(MockDepositRepository)
+		mockBillUseCase := new(MockBillUseCase)
+		useCase := NewReservationUseCase(nil, logrus.New(), nil, nil, mockDepositRepo, nil, nil, mockBillUseCase, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(&entity.ReservationDeposit{Status: entity.DepositStatusPending}, nil)
+
+		_, err := useCase.ApplyDeposit(context.Background(), model.Actor{}, 7, &model.ApplyDepositRequest{OrderID: 12})
+
+		assert.ErrorIs(t, err, constants.ErrDepositNotPaid)
+		mockBillUseCase.AssertNotCalled(t, "ApplyCredit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Deposit applied meanwhile", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockBillUseCase := new(MockBillUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, mockBillUseCase, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, Status: entity.DepositStatusPaid}
+
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(false, nil)
+
+		_, err := useCase.ApplyDeposit(context.Background(), model.Actor{}, 7, &model.ApplyDepositRequest{OrderID: 12})
+
+		assert.ErrorIs(t, err, constants.ErrDepositNotPaid)
+		mockBillUseCase.AssertNotCalled(t, "ApplyCredit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed credit gives the deposit back", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		mockBillUseCase := new(MockBillUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, mockBillUseCase, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, Status: entity.DepositStatusPaid}
+
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 3}, nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(true, nil)
+		mockBillUseCase.On("ApplyCredit", mock.Anything, mock.Anything, int64(12), int64(3), float64(100000), constants.PaymentMethodDeposit, "reservation #7").
+			Return(nil, constants.ErrInvalidRequest)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, mock.MatchedBy(func(d *entity.ReservationDeposit) bool {
+			return d.Status == entity.DepositStatusPaid && d.AppliedOrderID == nil
+		}), entity.DepositStatusApplied).Return(true, nil)
+
+		_, err := useCase.ApplyDeposit(context.Background(), model.Actor{}, 7, &model.ApplyDepositRequest{OrderID: 12})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockDepositRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type EmployeeUseCase interface {
+	// Invite creates an employee without a password and emails them a setup
+	// link, the account can't log in until the invite is accepted
+	Invite(ctx context.Context, actor model.Actor, request *model.InviteEmployeeRequest) (*model.EmployeeProfileResponse, error)
+	ResendInvite(ctx context.Context, id int64) error
+	AcceptInvite(ctx context.Context, request *model.AcceptInviteRequest) error
+	GetProfile(ctx context.Context, id int64) (*model.EmployeeProfileResponse, error)
+	UpdateProfile(ctx context.Context, actor model.Actor, id int64, request *model.UpdateEmployeeProfileRequest) (*model.EmployeeProfileResponse, error)
+}
+
+type employeeUseCase struct {
+	employeeRepo repository.EmployeeRepository
+	customerRepo repository.CustomerRepository
+	roleRepo     repository.RoleRepository
+	inviter      EmployeeInviter
+	log          *logrus.Logger
+	cache        database.RedisCache
+	audit        AuditRecorder
+}
+
+func NewEmployeeUseCase(
+	employeeRepo repository.EmployeeRepository,
+	customerRepo repository.CustomerRepository,
+	roleRepo repository.RoleRepository,
+	inviter EmployeeInviter,
+	log *logrus.Logger,
+	cache database.RedisCache,
+	audit AuditRecorder,
+) EmployeeUseCase {
+	return &employeeUseCase{
+		employeeRepo: employeeRepo,
+		customerRepo: customerRepo,
+		roleRepo:     roleRepo,
+		inviter:      inviter,
+		log:          log,
+		cache:        cache,
+		audit:        audit,
+	}
+}
+
+func (u *employeeUseCase) Invite(ctx context.Context, actor model.Actor, request *model.InviteEmployeeRequest) (*model.EmployeeProfileResponse, error) {
+	if request.Role == constants.RoleCustomer {
+		return nil, fmt.Errorf("%w: employees can't have the customer role", constants.ErrInvalidRequest)
+	}
+	if _, err := u.roleRepo.GetByName(ctx, request.Role); err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown role %s", constants.ErrInvalidRequest, request.Role)
+		}
+		return nil, err
+	}
+	if existing, err := u.customerRepo.GetByEmail(ctx, request.Email); err == nil && existing != nil {
+		return nil, constants.ErrEmailTaken
+	}
+	hireDate, err := parseHireDate(request.HireDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	employee := &entity.Customer{
+		Name:      request.Name,
+		Email:     request.Email,
+		Role:      request.Role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	profile := &entity.EmployeeProfile{
+		Phone:      request.Phone,
+		HireDate:   hireDate,
+		HourlyRate: request.HourlyRate,
+		InvitedAt:  &now,
+	}
+	if err := u.employeeRepo.CreateWithProfile(ctx, employee, profile); err != nil {
+		return nil, err
+	}
+	u.invalidateEmployee(ctx, employee.ID)
+
+	response := model.ToEmployeeProfileResponse(employee, profile)
+	u.audit.Record(ctx, actor, constants.AuditActionInvite, constants.AuditEntityEmployee, employee.ID, nil, response)
+
+	// the employee exists either way, a failed email can be sent again
+	if err := u.inviter.SendInvite(ctx, employee.ID); err != nil {
+		u.log.Errorf("Error sending invite to employee %d, resend it later: %v", employee.ID, err)
+	}
+
+	return response, nil
+}
+
+func (u *employeeUseCase) ResendInvite(ctx context.Context, id int64) error {
+	profile, err := u.employeeRepo.GetProfile(ctx, id)
+	if err != nil {
+		return err
+	}
+	if profile.InvitedAt == nil || profile.OnboardedAt != nil {
+		return constants.ErrInviteAccepted
+	}
+
+	now := time.Now()
+	profile.InvitedAt = &now
+	profile.UpdatedAt = now
+	if err := u.employeeRepo.SaveProfile(ctx, profile); err != nil {
+		return err
+	}
+
+	return u.inviter.SendInvite(ctx, id)
+}
+
+func (u *employeeUseCase) AcceptInvite(ctx context.Context, request *model.AcceptInviteRequest) error {
+	id, err := u.inviter.AcceptInvite(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	profile, err := u.employeeRepo.GetProfile(ctx, id)
+	if err != nil {
+		if !errors.Is(err, constants.ErrNotFound) {
+			return err
+		}
+		profile = &entity.EmployeeProfile{CustomerID: id}
+	}
+
+	now := time.Now()
+	profile.OnboardedAt = &now
+	profile.UpdatedAt = now
+	if err := u.employeeRepo.SaveProfile(ctx, profile); err != nil {
+		return err
+	}
+
+	u.invalidateEmployee(ctx, id)
+	return nil
+}
+
+func (u *employeeUseCase) GetProfile(ctx context.Context, id int64) (*model.EmployeeProfileResponse, error) {
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := u.employeeRepo.GetProfile(ctx, id)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+	return model.ToEmployeeProfileResponse(employee, profile), nil
+}
+
+func (u *employeeUseCase) UpdateProfile(ctx context.Context, actor model.Actor, id int64, request *model.UpdateEmployeeProfileRequest) (*model.EmployeeProfileResponse, error) {
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hireDate, err := parseHireDate(request.HireDate)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := u.employeeRepo.GetProfile(ctx, id)
+	if err != nil {
+		if !errors.Is(err, constants.ErrNotFound) {
+			return nil, err
+		}
+		profile = &entity.EmployeeProfile{CustomerID: id}
+	}
+	before := model.ToEmployeeProfileResponse(employee, profile)
+
+	profile.Phone = request.Phone
+	profile.HireDate = hireDate
+	profile.HourlyRate = request.HourlyRate
+	profile.UpdatedAt = time.Now()
+	if err := u.employeeRepo.SaveProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	response := model.ToEmployeeProfileResponse(employee, profile)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityEmployee, id, before, response)
+	return response, nil
+}
+
+func (u *employeeUseCase) invalidateEmployee(ctx context.Context, id int64) {
+	cacheKey := fmt.Sprintf("employee:%d", id)
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
+		u.log.Errorf("Error deleting cache for employee ID %d: %v", id, err)
+	}
+	if err := u.cache.Delete(ctx, "employees"); err != nil {
+		u.log.Errorf("Error deleting cache for employees: %v", err)
+	}
+}
+
+func parseHireDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(model.DateLayout, value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: hire_date must be YYYY-MM-DD", constants.ErrInvalidRequest)
+	}
+	return &date, nil
+}
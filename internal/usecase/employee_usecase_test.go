@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmployeeInviter struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeInviter) SendInvite(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeInviter) AcceptInvite(ctx context.Context, request *model.AcceptInviteRequest) (int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestEmployeeUseCase_Invite(t *testing.T) {
+	request := &model.InviteEmployeeRequest{
+		Name:       "Sari",
+		Email:      "sari@example.com",
+		Role:       constants.RoleWaitress,
+		HireDate:   "2026-11-01",
+		HourlyRate: 25000,
+	}
+
+	t.Run("creates the employee and emails the invite", func(t *testing.T) {
+		employeeRepo := new(MockEmployeeRepository)
+		customerRepo := new(MockCustomerRepository)
+		roleRepo := new(MockRoleRepository)
+		inviter := new(MockEmployeeInviter)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewEmployeeUseCase(employeeRepo, customerRepo, roleRepo, inviter, logrus.New(), cache, newMockAuditRecorder())
+
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		roleRepo.On("GetByName", mock.Anything, constants.RoleWaitress).Return(&entity.Role{Name: constants.RoleWaitress}, nil)
+		customerRepo.On("GetByEmail", mock.Anything, "sari@example.com").Return(nil, constants.ErrNotFound)
+		employeeRepo.On("CreateWithProfile", mock.Anything, mock.MatchedBy(func(employee *entity.Customer) bool {
+			return employee.Password == "" && employee.Role == constants.RoleWaitress
+		}), mock.MatchedBy(func(profile *entity.EmployeeProfile) bool {
+			return profile.InvitedAt != nil && profile.HireDate != nil && profile.HourlyRate == 25000
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.Customer).ID = 12
+		}).Return(nil)
+		inviter.On("SendInvite", mock.Anything, int64(12)).Return(nil)
+
+		employee, err := useCase.Invite(context.Background(), model.Actor{}, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "invited", employee.Status)
+		assert.Equal(t, "2026-11-01", employee.HireDate)
+		inviter.AssertExpectations(t)
+	})
+
+	t.Run("a failed email still creates the employee", func(t *testing.T) {
+		employeeRepo := new(MockEmployeeRepository)
+		customerRepo := new(MockCustomerRepository)
+		roleRepo := new(MockRoleRepository)
+		inviter := new(MockEmployeeInviter)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewEmployeeUseCase(employeeRepo, customerRepo, roleRepo, inviter, logrus.New(), cache, newMockAuditRecorder())
+
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		roleRepo.On("GetByName", mock.Anything, constants.RoleWaitress).Return(&entity.Role{Name: constants.RoleWaitress}, nil)
+		customerRepo.On("GetByEmail", mock.Anything, "sari@example.com").Return(nil, constants.ErrNotFound)
+		employeeRepo.On("CreateWithProfile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		inviter.On("SendInvite", mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		_, err := useCase.Invite(context.Background(), model.Actor{}, request)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("email already registered", func(t *testing.T) {
+		employeeRepo := new(MockEmployeeRepository)
+		customerRepo := new(MockCustomerRepository)
+		roleRepo := new(MockRoleRepository)
+		useCase := NewEmployeeUseCase(employeeRepo, customerRepo, roleRepo, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		roleRepo.On("GetByName", mock.Anything, constants.RoleWaitress).Return(&entity.Role{Name: constants.RoleWaitress}, nil)
+		customerRepo.On("GetByEmail", mock.Anything, "sari@example.com").Return(&entity.Customer{ID: 3}, nil)
+
+		_, err := useCase.Invite(context.Background(), model.Actor{}, request)
+
+		assert.ErrorIs(t, err, constants.ErrEmailTaken)
+		employeeRepo.AssertNotCalled(t, "CreateWithProfile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		roleRepo := new(MockRoleRepository)
+		useCase := NewEmployeeUseCase(nil, nil, roleRepo, nil, logrus.New(), nil, newMockAuditRecorder())
+
+		roleRepo.On("GetByName", mock.Anything, "sommelier").Return(nil, constants.ErrNotFound)
+
+		_, err := useCase.Invite(context.Background(), model.Actor{}, &model.InviteEmployeeRequest{Name: "Sari", Email: "sari@example.com", Role: "sommelier"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+	})
+}
+
+func TestEmployeeUseCase_AcceptInvite(t *testing.T) {
+	employeeRepo := new(MockEmployeeRepository)
+	inviter := new(MockEmployeeInviter)
+	cache := new(database.MockRedisCacheService)
+	useCase := NewEmployeeUseCase(employeeRepo, nil, nil, inviter, logrus.New(), cache, newMockAuditRecorder())
+
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	request := &model.AcceptInviteRequest{Token: "invite-token", Password: "secret123"}
+	invitedAt := time.Now().Add(-time.Hour)
+	inviter.On("AcceptInvite", mock.Anything, request).Return(int64(12), nil)
+	employeeRepo.On("GetProfile", mock.Anything, int64(12)).Return(&entity.EmployeeProfile{CustomerID: 12, InvitedAt: &invitedAt}, nil)
+	employeeRepo.On("SaveProfile", mock.Anything, mock.MatchedBy(func(profile *entity.EmployeeProfile) bool {
+		return profile.OnboardedAt != nil
+	})).Return(nil)
+
+	err := useCase.AcceptInvite(context.Background(), request)
+
+	assert.NoError(t, err)
+	employeeRepo.AssertExpectations(t)
+}
+
+func TestEmployeeUseCase_ResendInvite(t *testing.T) {
+	employeeRepo := new(MockEmployeeRepository)
+	inviter := new(MockEmployeeInviter)
+	useCase := NewEmployeeUseCase(employeeRepo, nil, nil, inviter, logrus.New(), nil, newMockAuditRecorder())
+
+	invitedAt := time.Now().Add(-48 * time.Hour)
+	onboardedAt := time.Now().Add(-time.Hour)
+	employeeRepo.On("GetProfile", mock.Anything, int64(12)).Return(&entity.EmployeeProfile{CustomerID: 12, InvitedAt: &invitedAt, OnboardedAt: &onboardedAt}, nil)
+
+	err := useCase.ResendInvite(context.Background(), 12)
+
+	assert.ErrorIs(t, err, constants.ErrInviteAccepted)
+	inviter.AssertNotCalled(t, "SendInvite", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,512 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	floorAreasCacheKey = "floor:areas"
+	// reservations starting within this window are shown on the floor
+	floorLookahead = 4 * time.Hour
+	// guests arriving later than this are no longer expected at the table
+	floorLateGrace = 30 * time.Minute
+)
+
+// allowed table status changes, seating and clearing a table drive the session lifecycle
+var tableTransitions = map[entity.TableStatus][]entity.TableStatus{
+	entity.TableStatusFree:          {entity.TableStatusReserved, entity.TableStatusSeated, entity.TableStatusBlocked},
+	entity.TableStatusReserved:      {entity.TableStatusFree, entity.TableStatusSeated, entity.TableStatusBlocked},
+	entity.TableStatusSeated:        {entity.TableStatusNeedsCleaning, entity.TableStatusFree},
+	entity.TableStatusNeedsCleaning: {entity.TableStatusFree, entity.TableStatusBlocked},
+	entity.TableStatusBlocked:       {entity.TableStatusFree},
+}
+
+type FloorUseCase interface {
+	GetFloor(ctx context.Context) (*model.FloorResponse, error)
+
+	GetAreas(ctx context.Context) ([]model.AreaResponse, error)
+	CreateArea(ctx context.Context, request *model.AreaRequest) (*model.AreaResponse, error)
+	UpdateArea(ctx context.Context, id int64, request *model.AreaRequest) (*model.AreaResponse, error)
+	DeleteArea(ctx context.Context, id int64) error
+
+	CreateSection(ctx context.Context, actor model.Actor, request *model.SectionRequest) (*model.SectionResponse, error)
+	UpdateSection(ctx context.Context, actor model.Actor, id int64, request *model.SectionRequest) (*model.SectionResponse, error)
+	DeleteSection(ctx context.Context, actor model.Actor, id int64) error
+	AssignStaff(ctx context.Context, actor model.Actor, sectionID int64, request *model.AssignSectionStaffRequest) (*model.SectionResponse, error)
+	// AssignOnShiftStaff spreads the waitstaff on shift right now over all
+	// sections, replacing the current assignments
+	AssignOnShiftStaff(ctx context.Context, actor model.Actor) ([]model.AreaResponse, error)
+
+	UpdateTableLayout(ctx context.Context, tableID uint, request *model.UpdateTableLayoutRequest) (*model.FloorTableResponse, error)
+	UpdateTableStatus(ctx context.Context, tableID uint, request *model.UpdateTableStatusRequest) (*model.FloorTableResponse, error)
+}
+
+type floorUseCase struct {
+	floorRepo       repository.FloorRepository
+	tableRepo       repository.TableRepository
+	reservationRepo repository.ReservationRepository
+	customerRepo    repository.CustomerRepository
+	log             *logrus.Logger
+	cache           database.RedisCache
+	shifts          OnShiftLister
+	audit           AuditRecorder
+}
+
+func NewFloorUseCase(
+	floorRepo repository.FloorRepository,
+	tableRepo repository.TableRepository,
+	reservationRepo repository.ReservationRepository,
+	customerRepo repository.CustomerRepository,
+	log *logrus.Logger,
+	cache database.RedisCache,
+	shifts OnShiftLister,
+	audit AuditRecorder,
+) FloorUseCase {
+	return &floorUseCase{
+		floorRepo:       floorRepo,
+		tableRepo:       tableRepo,
+		reservationRepo: reservationRepo,
+		customerRepo:    customerRepo,
+		log:             log,
+		cache:           cache,
+		shifts:          shifts,
+		audit:           audit,
+	}
+}
+
+// GetFloor is not cached, the host stand needs the live state
+func (u *floorUseCase) GetFloor(ctx context.Context) (*model.FloorResponse, error) {
+	start := time.Now()
+	defer func() {
+		u.log.Infof("GetFloor took %v", time.Since(start))
+	}()
+
+	now := time.Now()
+	areas, err := u.floorRepo.GetAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.floorRepo.GetOpenSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reservations, err := u.reservationRepo.GetUpcoming(ctx, now.Add(-floorLateGrace), now.Add(floorLookahead))
+	if err != nil {
+		return nil, err
+	}
+	// the floor still renders when the schedule can't be read
+	onShift := make(map[int64]bool)
+	if working, err := u.shifts.OnShift(ctx, now, ""); err != nil {
+		u.log.Errorf("Error getting staff on shift: %v", err)
+	} else {
+		for _, employee := range working {
+			onShift[employee.EmployeeID] = true
+		}
+	}
+
+	sessionByTable := make(map[int64]*entity.TableSession, len(sessions))
+	for i := range sessions {
+		sessionByTable[sessions[i].TableID] = &sessions[i]
+	}
+	// reservations are ordered by time, keep the first one per table
+	nextByTable := make(map[int64]*entity.Reservation)
+	for i := range reservations {
+		tableID := int64(*reservations[i].TableID)
+		if _, ok := nextByTable[tableID]; !ok {
+			nextByTable[tableID] = &reservations[i]
+		}
+	}
+
+	response := &model.FloorResponse{
+		GeneratedAt: now,
+		Summary:     make(map[string]int),
+		Areas:       make([]model.FloorAreaResponse, 0, len(areas)),
+		Unassigned:  []model.FloorTableResponse{},
+	}
+
+	tablesBySection := make(map[int64][]model.FloorTableResponse)
+	for i := range tables {
+		table := model.ToFloorTableResponse(&tables[i])
+		if session, ok := sessionByTable[table.ID]; ok {
+			table.Session = &model.FloorSessionResponse{
+				ID:            session.ID,
+				GuestCount:    session.GuestCount,
+				ReservationID: session.ReservationID,
+				SeatedAt:      session.SeatedAt,
+				SeatedMinutes: int(now.Sub(session.SeatedAt).Minutes()),
+			}
+		}
+		if reservation, ok := nextByTable[table.ID]; ok {
+			table.NextReservation = &model.FloorReservationResponse{
+				ID:           reservation.ID,
+				CustomerName: reservation.Customer.Name,
+				GuestCount:   reservation.GuestCount,
+				ReserveDate:  reservation.ReserveDate,
+				Status:       string(reservation.Status),
+				Late:         reservation.ReserveDate.Before(now),
+			}
+		}
+		response.Summary[table.Status]++
+
+		if tables[i].SectionID == nil {
+			response.Unassigned = append(response.Unassigned, table)
+			continue
+		}
+		tablesBySection[*tables[i].SectionID] = append(tablesBySection[*tables[i].SectionID], table)
+	}
+
+	for _, area := range areas {
+		floorArea := model.FloorAreaResponse{
+			ID:       area.ID,
+			Name:     area.Name,
+			Type:     string(area.Type),
+			Sections: make([]model.FloorSectionResponse, 0, len(area.Sections)),
+		}
+		for _, section := range area.Sections {
+			sectionTables := tablesBySection[section.ID]
+			if sectionTables == nil {
+				sectionTables = []model.FloorTableResponse{}
+			}
+			staff := model.ToStaffSummaries(section.Staff)
+			for i := range staff {
+				staff[i].OnShift = onShift[staff[i].ID]
+			}
+			floorArea.Sections = append(floorArea.Sections, model.FloorSectionResponse{
+				ID:     section.ID,
+				Name:   section.Name,
+				Staff:  staff,
+				Tables: sectionTables,
+			})
+		}
+		response.Areas = append(response.Areas, floorArea)
+	}
+
+	return response, nil
+}
+
+func (u *floorUseCase) GetAreas(ctx context.Context) ([]model.AreaResponse, error) {
+	start := time.Now()
+	defer func() {
+		u.log.Infof("GetAreas took %v", time.Since(start))
+	}()
+
+	return database.GetOrLoad(ctx, u.cache, floorAreasCacheKey, cacheTTL, func(ctx context.Context) ([]model.AreaResponse, error) {
+		areas, err := u.floorRepo.GetAreas(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		responses := make([]model.AreaResponse, 0, len(areas))
+		for i := range areas {
+			responses = append(responses, *model.ToAreaResponse(&areas[i]))
+		}
+		return responses, nil
+	})
+}
+
+func (u *floorUseCase) CreateArea(ctx context.Context, request *model.AreaRequest) (*model.AreaResponse, error) {
+	area := &entity.FloorArea{
+		Name:      request.Name,
+		Type:      entity.AreaType(request.Type),
+		SortOrder: request.SortOrder,
+	}
+	if err := u.floorRepo.CreateArea(ctx, area); err != nil {
+		return nil, err
+	}
+
+	u.invalidateAreas(ctx)
+	return model.ToAreaResponse(area), nil
+}
+
+func (u *floorUseCase) UpdateArea(ctx context.Context, id int64, request *model.AreaRequest) (*model.AreaResponse, error) {
+	area, err := u.floorRepo.GetAreaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	area.Name = request.Name
+	area.Type = entity.AreaType(request.Type)
+	area.SortOrder = request.SortOrder
+	if err := u.floorRepo.UpdateArea(ctx, area); err != nil {
+		return nil, err
+	}
+
+	u.invalidateAreas(ctx)
+	return model.ToAreaResponse(area), nil
+}
+
+func (u *floorUseCase) DeleteArea(ctx context.Context, id int64) error {
+	area, err := u.floorRepo.GetAreaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(area.Sections) > 0 {
+		return constants.ErrAreaHasSections
+	}
+	if err := u.floorRepo.DeleteArea(ctx, id); err != nil {
+		return err
+	}
+
+	u.invalidateAreas(ctx)
+	return nil
+}
+
+func (u *floorUseCase) CreateSection(ctx context.Context, actor model.Actor, request *model.SectionRequest) (*model.SectionResponse, error) {
+	if _, err := u.floorRepo.GetAreaByID(ctx, request.AreaID); err != nil {
+		return nil, err
+	}
+
+	section := &entity.FloorSection{
+		AreaID: request.AreaID,
+		Name:   request.Name,
+	}
+	if err := u.floorRepo.CreateSection(ctx, section); err != nil {
+		return nil, err
+	}
+
+	u.invalidateAreas(ctx)
+	response := model.ToSectionResponse(section)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityFloorSection, section.ID, nil, response)
+	return &response, nil
+}
+
+func (u *floorUseCase) UpdateSection(ctx context.Context, actor model.Actor, id int64, request *model.SectionRequest) (*model.SectionResponse, error) {
+	section, err := u.floorRepo.GetSectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToSectionResponse(section)
+	if section.AreaID != request.AreaID {
+		if _, err := u.floorRepo.GetAreaByID(ctx, request.AreaID); err != nil {
+			return nil, err
+		}
+	}
+
+	section.AreaID = request.AreaID
+	section.Name = request.Name
+	if err := u.floorRepo.UpdateSection(ctx, section); err != nil {
+		return nil, err
+	}
+
+	u.invalidateAreas(ctx)
+	response := model.ToSectionResponse(section)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityFloorSection, id, before, response)
+	return &response, nil
+}
+
+func (u *floorUseCase) DeleteSection(ctx context.Context, actor model.Actor, id int64) error {
+	section, err := u.floorRepo.GetSectionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.floorRepo.DeleteSection(ctx, id); err != nil {
+		return err
+	}
+
+	u.invalidateAreas(ctx)
+	u.invalidateTables(ctx, 0)
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityFloorSection, id, model.ToSectionResponse(section), nil)
+	return nil
+}
+
+func (u *floorUseCase) AssignStaff(ctx context.Context, actor model.Actor, sectionID int64, request *model.AssignSectionStaffRequest) (*model.SectionResponse, error) {
+	current, err := u.floorRepo.GetSectionByID(ctx, sectionID)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToSectionResponse(current)
+
+	seen := make(map[int64]bool, len(request.EmployeeIDs))
+	employeeIDs := make([]int64, 0, len(request.EmployeeIDs))
+	for _, employeeID := range request.EmployeeIDs {
+		if seen[employeeID] {
+			continue
+		}
+		seen[employeeID] = true
+		if _, err := u.customerRepo.GetEmployeeByID(ctx, employeeID); err != nil {
+			return nil, fmt.Errorf("employee %d: %w", employeeID, err)
+		}
+		employeeIDs = append(employeeIDs, employeeID)
+	}
+
+	if err := u.floorRepo.ReplaceSectionStaff(ctx, sectionID, employeeIDs); err != nil {
+		return nil, err
+	}
+
+	u.invalidateAreas(ctx)
+	section, err := u.floorRepo.GetSectionByID(ctx, sectionID)
+	if err != nil {
+		return nil, err
+	}
+	response := model.ToSectionResponse(section)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityFloorSection, sectionID, before, response)
+	return &response, nil
+}
+
+func (u *floorUseCase) AssignOnShiftStaff(ctx context.Context, actor model.Actor) ([]model.AreaResponse, error) {
+	working, err := u.shifts.OnShift(ctx, time.Now(), constants.RoleWaitress)
+	if err != nil {
+		return nil, err
+	}
+	if len(working) == 0 {
+		return nil, constants.ErrNoStaffOnShift
+	}
+	areas, err := u.floorRepo.GetAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []int64
+	before := make(map[int64]model.SectionResponse)
+	for _, area := range areas {
+		for i := range area.Sections {
+			sections = append(sections, area.Sections[i].ID)
+			before[area.Sections[i].ID] = model.ToSectionResponse(&area.Sections[i])
+		}
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("%w: the floor has no sections", constants.ErrInvalidRequest)
+	}
+
+	// round robin so every section is covered and every waiter has a section,
+	// waiters cover several sections when there are fewer of them
+	assignments := make(map[int64][]int64, len(sections))
+	for i := 0; i < len(sections) || i < len(working); i++ {
+		section := sections[i%len(sections)]
+		assignments[section] = append(assignments[section], working[i%len(working)].EmployeeID)
+	}
+	for _, section := range sections {
+		if err := u.floorRepo.ReplaceSectionStaff(ctx, section, assignments[section]); err != nil {
+			return nil, err
+		}
+	}
+
+	u.invalidateAreas(ctx)
+	updated, err := u.GetAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, area := range updated {
+		for _, section := range area.Sections {
+			u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityFloorSection, section.ID, before[section.ID], section)
+		}
+	}
+	return updated, nil
+}
+
+func (u *floorUseCase) UpdateTableLayout(ctx context.Context, tableID uint, request *model.UpdateTableLayoutRequest) (*model.FloorTableResponse, error) {
+	table, err := u.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if request.SectionID != nil {
+		if _, err := u.floorRepo.GetSectionByID(ctx, *request.SectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	table.SectionID = request.SectionID
+	table.Shape = entity.TableShape(request.Shape)
+	table.PosX = request.PosX
+	table.PosY = request.PosY
+	table.Width = request.Width
+	table.Height = request.Height
+	table.Rotation = request.Rotation
+	if err := u.tableRepo.Update(ctx, table); err != nil {
+		return nil, err
+	}
+
+	u.invalidateTables(ctx, tableID)
+	response := model.ToFloorTableResponse(table)
+	return &response, nil
+}
+
+func (u *floorUseCase) UpdateTableStatus(ctx context.Context, tableID uint, request *model.UpdateTableStatusRequest) (*model.FloorTableResponse, error) {
+	table, err := u.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := entity.TableStatus(request.Status)
+	if !canTransitionTable(table.Status, next) {
+		return nil, fmt.Errorf("%w: %s to %s", constants.ErrInvalidTableTransition, table.Status, next)
+	}
+
+	if next == entity.TableStatusSeated {
+		guests := request.GuestCount
+		if guests == 0 {
+			guests = table.Capacity
+		}
+		session := &entity.TableSession{
+			TableID:       table.ID,
+			ReservationID: request.ReservationID,
+			GuestCount:    guests,
+			SeatedAt:      time.Now(),
+		}
+		if err := u.floorRepo.OpenSession(ctx, session); err != nil {
+			return nil, err
+		}
+	} else if table.Status == entity.TableStatusSeated {
+		if err := u.floorRepo.CloseSession(ctx, table.ID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.tableRepo.UpdateStatus(ctx, tableID, next); err != nil {
+		return nil, err
+	}
+	table.Status = next
+	table.IsAvailable = next != entity.TableStatusBlocked
+
+	u.invalidateTables(ctx, tableID)
+	response := model.ToFloorTableResponse(table)
+	return &response, nil
+}
+
+func canTransitionTable(from, to entity.TableStatus) bool {
+	// tables created before statuses existed have no status yet
+	if from == "" {
+		from = entity.TableStatusFree
+	}
+	for _, allowed := range tableTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *floorUseCase) invalidateAreas(ctx context.Context) {
+	if err := u.cache.Delete(ctx, floorAreasCacheKey); err != nil {
+		u.log.Errorf("Error deleting cache for floor areas: %v", err)
+	}
+}
+
+// invalidateTables drops the cached table listings, and the single table when id is set
+func (u *floorUseCase) invalidateTables(ctx context.Context, id uint) {
+	if id != 0 {
+		cacheKey := fmt.Sprintf("table:%d", id)
+		if err := u.cache.Delete(ctx, cacheKey); err != nil {
+			u.log.Errorf("Error deleting cache for table ID %d: %v", id, err)
+		}
+	}
+	if err := u.cache.DeletePrefix(ctx, tableListCachePrefix); err != nil {
+		u.log.Errorf("Error deleting cache for all tables: %v", err)
+	}
+	if err := u.cache.DeletePrefix(ctx, availableTablesCachePrefix); err != nil {
+		u.log.Errorf("Error deleting cache for available tables: %v", err)
+	}
+}
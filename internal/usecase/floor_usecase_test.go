@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFloorRepository struct {
+	mock.Mock
+}
+
+func (m *MockFloorRepository) CreateArea(ctx context.Context, area *entity.FloorArea) error {
+	args := m.Called(ctx, area)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) GetAreas(ctx context.Context) ([]entity.FloorArea, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.FloorArea), args.Error(1)
+}
+
+func (m *MockFloorRepository) GetAreaByID(ctx context.Context, id int64) (*entity.FloorArea, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FloorArea), args.Error(1)
+}
+
+func (m *MockFloorRepository) UpdateArea(ctx context.Context, area *entity.FloorArea) error {
+	args := m.Called(ctx, area)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) DeleteArea(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) CreateSection(ctx context.Context, section *entity.FloorSection) error {
+	args := m.Called(ctx, section)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) GetSectionByID(ctx context.Context, id int64) (*entity.FloorSection, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FloorSection), args.Error(1)
+}
+
+func (m *MockFloorRepository) UpdateSection(ctx context.Context, section *entity.FloorSection) error {
+	args := m.Called(ctx, section)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) DeleteSection(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) ReplaceSectionStaff(ctx context.Context, sectionID int64, employeeIDs []int64) error {
+	args := m.Called(ctx, sectionID, employeeIDs)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) GetOpenSessions(ctx context.Context) ([]entity.TableSession, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.TableSession), args.Error(1)
+}
+
+func (m *MockFloorRepository) OpenSession(ctx context.Context, session *entity.TableSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockFloorRepository) CloseSession(ctx context.Context, tableID int64, closedAt time.Time) error {
+	args := m.Called(ctx, tableID, closedAt)
+	return args.Error(0)
+}
+
+func TestFloorUseCase_GetFloor(t *testing.T) {
+	floorRepo := new(MockFloorRepository)
+	tableRepo := new(MockTableRepository)
+	reservationRepo := new(MockReservationRepository)
+	shifts := new(MockOnShiftLister)
+	useCase := NewFloorUseCase(floorRepo, tableRepo, reservationRepo, new(MockCustomerRepository), logrus.New(), new(database.MockRedisCacheService), shifts, newMockAuditRecorder())
+
+	sectionID := int64(3)
+	tableID := uint(2)
+	floorRepo.On("GetAreas", mock.Anything).Return([]entity.FloorArea{
+		{
+			ID:   1,
+			Name: "Terrace",
+			Type: entity.AreaTypeTerrace,
+			Sections: []entity.FloorSection{
+				{ID: sectionID, AreaID: 1, Name: "T1", Staff: []entity.SectionStaff{
+					{SectionID: sectionID, EmployeeID: 9, Employee: entity.Customer{ID: 9, Name: "Sari", Role: constants.RoleWaitress}},
+				}},
+			},
+		},
+	}, nil)
+	tableRepo.On("GetAll", mock.Anything).Return([]entity.Table{
+		{ID: 1, TableNumber: 1, Capacity: 4, Status: entity.TableStatusSeated, SectionID: &sectionID},
+		{ID: 2, TableNumber: 2, Capacity: 2, Status: entity.TableStatusReserved, SectionID: &sectionID},
+		{ID: 3, TableNumber: 3, Capacity: 6, Status: entity.TableStatusFree},
+	}, nil)
+	floorRepo.On("GetOpenSessions", mock.Anything).Return([]entity.TableSession{
+		{ID: 7, TableID: 1, GuestCount: 3, SeatedAt: time.Now().Add(-45 * time.Minute)},
+	}, nil)
+	reservationRepo.On("GetUpcoming", mock.Anything, mock.Anything, mock.Anything).Return([]entity.Reservation{
+		{ID: 5, TableID: &tableID, GuestCount: 2, ReserveDate: time.Now().Add(30 * time.Minute), Status: entity.ReservationStatusConfirmed, Customer: entity.Customer{Name: "Budi"}},
+	}, nil)
+	shifts.On("OnShift", mock.Anything, mock.Anything, "").Return([]model.OnShiftResponse{{EmployeeID: 9, Name: "Sari", Role: constants.RoleWaitress}}, nil)
+
+	floor, err := useCase.GetFloor(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, floor.Areas, 1)
+	section := floor.Areas[0].Sections[0]
+	assert.Len(t, section.Tables, 2)
+	assert.Equal(t, "Sari", section.Staff[0].Name)
+	assert.True(t, section.Staff[0].OnShift)
+	assert.Equal(t, 3, section.Tables[0].Session.GuestCount)
+	assert.GreaterOrEqual(t, section.Tables[0].Session.SeatedMinutes, 44)
+	assert.Equal(t, "Budi", section.Tables[1].NextReservation.CustomerName)
+	assert.False(t, section.Tables[1].NextReservation.Late)
+	assert.Len(t, floor.Unassigned, 1)
+	assert.Equal(t, 1, floor.Summary[string(entity.TableStatusFree)])
+}
+
+func TestFloorUseCase_UpdateTableStatus(t *testing.T) {
+	newUseCase := func() (FloorUseCase, *MockFloorRepository, *MockTableRepository) {
+		floorRepo := new(MockFloorRepository)
+		tableRepo := new(MockTableRepository)
+		cache := new(database.MockRedisCacheService)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		useCase := NewFloorUseCase(floorRepo, tableRepo, new(MockReservationRepository), new(MockCustomerRepository), logrus.New(), cache, new(MockOnShiftLister), newMockAuditRecorder())
+		return useCase, floorRepo, tableRepo
+	}
+
+	t.Run("seating opens a session", func(t *testing.T) {
+		useCase, floorRepo, tableRepo := newUseCase()
+		tableRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.Table{ID: 1, Capacity: 4, Status: entity.TableStatusFree}, nil)
+		floorRepo.On("OpenSession", mock.Anything, mock.MatchedBy(func(session *entity.TableSession) bool {
+			return session.TableID == 1 && session.GuestCount == 4
+		})).Return(nil).Once()
+		tableRepo.On("UpdateStatus", mock.Anything, uint(1), entity.TableStatusSeated).Return(nil).Once()
+
+		table, err := useCase.UpdateTableStatus(context.Background(), 1, &model.UpdateTableStatusRequest{Status: "seated"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, string(entity.TableStatusSeated), table.Status)
+		floorRepo.AssertExpectations(t)
+		tableRepo.AssertExpectations(t)
+	})
+
+	t.Run("clearing a seated table closes the session", func(t *testing.T) {
+		useCase, floorRepo, tableRepo := newUseCase()
+		tableRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.Table{ID: 1, Status: entity.TableStatusSeated}, nil)
+		floorRepo.On("CloseSession", mock.Anything, int64(1), mock.Anything).Return(nil).Once()
+		tableRepo.On("UpdateStatus", mock.Anything, uint(1), entity.TableStatusNeedsCleaning).Return(nil).Once()
+
+		_, err := useCase.UpdateTableStatus(context.Background(), 1, &model.UpdateTableStatusRequest{Status: "needs_cleaning"})
+
+		assert.NoError(t, err)
+		floorRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		useCase, _, tableRepo := newUseCase()
+		tableRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.Table{ID: 1, Status: entity.TableStatusNeedsCleaning}, nil)
+
+		_, err := useCase.UpdateTableStatus(context.Background(), 1, &model.UpdateTableStatusRequest{Status: "seated"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidTableTransition)
+		tableRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFloorUseCase_DeleteArea(t *testing.T) {
+	floorRepo := new(MockFloorRepository)
+	useCase := NewFloorUseCase(floorRepo, new(MockTableRepository), new(MockReservationRepository), new(MockCustomerRepository), logrus.New(), new(database.MockRedisCacheService), new(MockOnShiftLister), newMockAuditRecorder())
+
+	floorRepo.On("GetAreaByID", mock.Anything, int64(1)).Return(&entity.FloorArea{ID: 1, Sections: []entity.FloorSection{{ID: 2}}}, nil)
+
+	err := useCase.DeleteArea(context.Background(), 1)
+
+	assert.ErrorIs(t, err, constants.ErrAreaHasSections)
+	floorRepo.AssertNotCalled(t, "DeleteArea", mock.Anything, mock.Anything)
+}
+
+func TestFloorUseCase_AssignOnShiftStaff(t *testing.T) {
+	newUseCase := func() (FloorUseCase, *MockFloorRepository, *MockOnShiftLister) {
+		floorRepo := new(MockFloorRepository)
+		shifts := new(MockOnShiftLister)
+		cache := new(database.MockRedisCacheService)
+		cache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("cache miss"))
+		cache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		useCase := NewFloorUseCase(floorRepo, new(MockTableRepository), new(MockReservationRepository), new(MockCustomerRepository), logrus.New(), cache, shifts, newMockAuditRecorder())
+		return useCase, floorRepo, shifts
+	}
+
+	t.Run("every section gets a waiter", func(t *testing.T) {
+		useCase, floorRepo, shifts := newUseCase()
+		shifts.On("OnShift", mock.Anything, mock.Anything, constants.RoleWaitress).Return([]model.OnShiftResponse{
+			{EmployeeID: 4, Name: "Ayu"}, {EmployeeID: 9, Name: "Sari"},
+		}, nil)
+		floorRepo.On("GetAreas", mock.Anything).Return([]entity.FloorArea{
+			{ID: 1, Sections: []entity.FloorSection{{ID: 1}, {ID: 2}}},
+			{ID: 2, Sections: []entity.FloorSection{{ID: 3}}},
+		}, nil)
+		floorRepo.On("ReplaceSectionStaff", mock.Anything, int64(1), []int64{4}).Return(nil).Once()
+		floorRepo.On("ReplaceSectionStaff", mock.Anything, int64(2), []int64{9}).Return(nil).Once()
+		floorRepo.On("ReplaceSectionStaff", mock.Anything, int64(3), []int64{4}).Return(nil).Once()
+
+		_, err := useCase.AssignOnShiftStaff(context.Background(), model.Actor{})
+
+		assert.NoError(t, err)
+		floorRepo.AssertExpectations(t)
+	})
+
+	t.Run("nobody on shift", func(t *testing.T) {
+		useCase, floorRepo, shifts := newUseCase()
+		shifts.On("OnShift", mock.Anything, mock.Anything, constants.RoleWaitress).Return([]model.OnShiftResponse{}, nil)
+
+		_, err := useCase.AssignOnShiftStaff(context.Background(), model.Actor{})
+
+		assert.ErrorIs(t, err, constants.ErrNoStaffOnShift)
+		floorRepo.AssertNotCalled(t, "ReplaceSectionStaff", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
@@ -14,30 +15,32 @@ import (
 )
 
 type InventoryUseCase interface {
-	Create(request *model.CreateInventoryRequest) (*model.InventoryResponse, error)
-	GetByID(id uint) (*model.InventoryResponse, error)
-	GetAll(params *model.InventoryQueryParams) (*model.PaginationResponse[[]model.InventoryResponse], error)
-	Update(id uint, request *model.UpdateInventoryRequest) (*model.InventoryResponse, error)
-	Delete(id uint) error
-	UpdateStock(id uint, quantity float64) error
-	GetLowStockIngredients() ([]model.InventoryResponse, error)
+	Create(ctx context.Context, actor model.Actor, request *model.CreateInventoryRequest) (*model.InventoryResponse, error)
+	GetByID(ctx context.Context, id uint) (*model.InventoryResponse, error)
+	GetAll(ctx context.Context, params *model.InventoryQueryParams) (*model.PaginationResponse[[]model.InventoryResponse], error)
+	Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateInventoryRequest) (*model.InventoryResponse, error)
+	Delete(ctx context.Context, actor model.Actor, id uint) error
+	UpdateStock(ctx context.Context, actor model.Actor, id uint, quantity float64) error
+	GetLowStockIngredients(ctx context.Context) ([]model.InventoryResponse, error)
 }
 
 type inventoryUseCase struct {
 	repo   repository.InventoryRepository
 	logger *logrus.Logger
 	cache  database.RedisCache
+	audit  AuditRecorder
 }
 
-func NewInventoryUseCase(repo repository.InventoryRepository, logger *logrus.Logger, cache database.RedisCache) InventoryUseCase {
+func NewInventoryUseCase(repo repository.InventoryRepository, logger *logrus.Logger, cache database.RedisCache, audit AuditRecorder) InventoryUseCase {
 	return &inventoryUseCase{
 		repo:   repo,
 		logger: logger,
 		cache:  cache,
+		audit:  audit,
 	}
 }
 
-func (u *inventoryUseCase) Create(request *model.CreateInventoryRequest) (*model.InventoryResponse, error) {
+func (u *inventoryUseCase) Create(ctx context.Context, actor model.Actor, request *model.CreateInventoryRequest) (*model.InventoryResponse, error) {
 	ingredient := &entity.Inventory{
 		Name:            request.Name,
 		Quantity:        request.Quantity,
@@ -48,9 +51,10 @@ func (u *inventoryUseCase) Create(request *model.CreateInventoryRequest) (*model
 		LastRestockDate: time.Now(),
 	}
 
-	if err := u.repo.Create(ingredient); err != nil {
+	if err := u.repo.Create(ctx, ingredient); err != nil {
 		return nil, err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityInventory, ingredient.ID, nil, ingredient)
 
 	return &model.InventoryResponse{
 		ID:              ingredient.ID,
@@ -66,102 +70,77 @@ func (u *inventoryUseCase) Create(request *model.CreateInventoryRequest) (*model
 	}, nil
 }
 
-func (u *inventoryUseCase) GetByID(id uint) (*model.InventoryResponse, error) {
+func (u *inventoryUseCase) GetByID(ctx context.Context, id uint) (*model.InventoryResponse, error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("GetByID took %v", time.Since(start))
 	}()
 
-	// Try to get the ingredient from the cache first
-	cacheKey := fmt.Sprintf("inventory:%d", id)
-	var ingredient model.InventoryResponse
-	if err := u.cache.Get(context.Background(), cacheKey, &ingredient); err == nil {
-		u.logger.Info("Ingredient fetched from cache")
-		return &ingredient, nil
-	}
-
-	// If not in cache, get from the database
-	ingredientEntity, err := u.repo.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store the ingredient in the cache for future requests
-	ingredientModel := &model.InventoryResponse{
-		ID:              ingredientEntity.ID,
-		Name:            ingredientEntity.Name,
-		Quantity:        ingredientEntity.Quantity,
-		Unit:            ingredientEntity.Unit,
-		MinimumStock:    ingredientEntity.MinimumStock,
-		ReorderPoint:    ingredientEntity.ReorderPoint,
-		UnitPrice:       ingredientEntity.UnitPrice,
-		LastRestockDate: ingredientEntity.LastRestockDate,
-		CreatedAt:       ingredientEntity.CreatedAt,
-		UpdatedAt:       ingredientEntity.UpdatedAt,
-	}
-	if err := u.cache.Set(context.Background(), cacheKey, ingredientModel, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for ingredient ID %d: %v", id, err)
-	}
+	return database.GetOrLoad(ctx, u.cache, fmt.Sprintf("inventory:%d", id), cacheTTL, func(ctx context.Context) (*model.InventoryResponse, error) {
+		ingredientEntity, err := u.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
 
-	return ingredientModel, nil
+		return &model.InventoryResponse{
+			ID:              ingredientEntity.ID,
+			Name:            ingredientEntity.Name,
+			Quantity:        ingredientEntity.Quantity,
+			Unit:            ingredientEntity.Unit,
+			MinimumStock:    ingredientEntity.MinimumStock,
+			ReorderPoint:    ingredientEntity.ReorderPoint,
+			UnitPrice:       ingredientEntity.UnitPrice,
+			LastRestockDate: ingredientEntity.LastRestockDate,
+			CreatedAt:       ingredientEntity.CreatedAt,
+			UpdatedAt:       ingredientEntity.UpdatedAt,
+		}, nil
+	})
 }
 
-func (u *inventoryUseCase) GetAll(params *model.InventoryQueryParams) (*model.PaginationResponse[[]model.InventoryResponse], error) {
+func (u *inventoryUseCase) GetAll(ctx context.Context, params *model.InventoryQueryParams) (*model.PaginationResponse[[]model.InventoryResponse], error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("GetAll took %v", time.Since(start))
 	}()
 
-	// Try to get the ingredients from the cache first
-	cacheKey := fmt.Sprintf("inventory:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData model.PaginationResponse[[]model.InventoryResponse]
-	if err := u.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		u.logger.Info("Ingredients fetched from cache")
-		return &cachedData, nil
-	}
-
-	// If not in cache, get from the database
-	result, err := u.repo.GetAll(params)
-	if err != nil {
-		return nil, err
-	}
-
-	responses := make([]model.InventoryResponse, len(result.Data))
-	for i, ingredient := range result.Data {
-		responses[i] = model.InventoryResponse{
-			ID:              ingredient.ID,
-			Name:            ingredient.Name,
-			Quantity:        ingredient.Quantity,
-			Unit:            ingredient.Unit,
-			MinimumStock:    ingredient.MinimumStock,
-			ReorderPoint:    ingredient.ReorderPoint,
-			UnitPrice:       ingredient.UnitPrice,
-			LastRestockDate: ingredient.LastRestockDate,
-			CreatedAt:       ingredient.CreatedAt,
-			UpdatedAt:       ingredient.UpdatedAt,
+	cacheKey := fmt.Sprintf("%spage:%d:limit:%d", inventoryListCachePrefix, params.Page, params.Limit)
+	return database.GetOrLoad(ctx, u.cache, cacheKey, cacheTTL, func(ctx context.Context) (*model.PaginationResponse[[]model.InventoryResponse], error) {
+		result, err := u.repo.GetAll(ctx, params)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	paginatedResponse := &model.PaginationResponse[[]model.InventoryResponse]{
-		Data:       responses,
-		Total:      result.Total,
-		Page:       result.Page,
-		TotalPages: result.TotalPages,
-	}
-
-	// Store the ingredients in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, paginatedResponse, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for all ingredients: %v", err)
-	}
+		responses := make([]model.InventoryResponse, len(result.Data))
+		for i, ingredient := range result.Data {
+			responses[i] = model.InventoryResponse{
+				ID:              ingredient.ID,
+				Name:            ingredient.Name,
+				Quantity:        ingredient.Quantity,
+				Unit:            ingredient.Unit,
+				MinimumStock:    ingredient.MinimumStock,
+				ReorderPoint:    ingredient.ReorderPoint,
+				UnitPrice:       ingredient.UnitPrice,
+				LastRestockDate: ingredient.LastRestockDate,
+				CreatedAt:       ingredient.CreatedAt,
+				UpdatedAt:       ingredient.UpdatedAt,
+			}
+		}
 
-	return paginatedResponse, nil
+		return &model.PaginationResponse[[]model.InventoryResponse]{
+			Data:       responses,
+			Total:      result.Total,
+			Page:       result.Page,
+			TotalPages: result.TotalPages,
+		}, nil
+	})
 }
 
-func (u *inventoryUseCase) Update(id uint, request *model.UpdateInventoryRequest) (*model.InventoryResponse, error) {
-	existing, err := u.repo.GetByID(id)
+func (u *inventoryUseCase) Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateInventoryRequest) (*model.InventoryResponse, error) {
+	existing, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *existing
 
 	if request.Name != "" {
 		existing.Name = request.Name
@@ -182,19 +161,20 @@ func (u *inventoryUseCase) Update(id uint, request *model.UpdateInventoryRequest
 		existing.UnitPrice = request.UnitPrice
 	}
 
-	if err := u.repo.Update(existing); err != nil {
+	if err := u.repo.Update(ctx, existing); err != nil {
 		return nil, err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityInventory, id, before, existing)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("inventory:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.logger.Errorf("Error deleting cache for ingredient ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "inventory:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, inventoryListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for all ingredients: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "low_stock_ingredients"); err != nil {
+	if err := u.cache.Delete(ctx, "low_stock_ingredients"); err != nil {
 		u.logger.Errorf("Error deleting cache for low stock ingredients: %v", err)
 	}
 
@@ -212,28 +192,33 @@ func (u *inventoryUseCase) Update(id uint, request *model.UpdateInventoryRequest
 	}, nil
 }
 
-func (u *inventoryUseCase) Delete(id uint) error {
-	if err := u.repo.Delete(id); err != nil {
+func (u *inventoryUseCase) Delete(ctx context.Context, actor model.Actor, id uint) error {
+	before, err := u.repo.GetByID(ctx, id)
+	if err != nil {
 		return err
 	}
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityInventory, id, before, nil)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("inventory:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.logger.Errorf("Error deleting cache for ingredient ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "inventory:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, inventoryListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for all ingredients: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "low_stock_ingredients"); err != nil {
+	if err := u.cache.Delete(ctx, "low_stock_ingredients"); err != nil {
 		u.logger.Errorf("Error deleting cache for low stock ingredients: %v", err)
 	}
 
 	return nil
 }
 
-func (u *inventoryUseCase) UpdateStock(id uint, quantity float64) error {
-	ingredient, err := u.repo.GetByID(id)
+func (u *inventoryUseCase) UpdateStock(ctx context.Context, actor model.Actor, id uint, quantity float64) error {
+	ingredient, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -242,65 +227,55 @@ func (u *inventoryUseCase) UpdateStock(id uint, quantity float64) error {
 		return errors.New("insufficient stock")
 	}
 
-	if err := u.repo.UpdateStock(id, quantity); err != nil {
+	if err := u.repo.UpdateStock(ctx, id, quantity); err != nil {
 		return err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionStockAdjust, constants.AuditEntityInventory, id,
+		map[string]float64{"quantity": ingredient.Quantity},
+		map[string]float64{"quantity": ingredient.Quantity + quantity})
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("inventory:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.logger.Errorf("Error deleting cache for ingredient ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "inventory:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, inventoryListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for all ingredients: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "low_stock_ingredients"); err != nil {
+	if err := u.cache.Delete(ctx, "low_stock_ingredients"); err != nil {
 		u.logger.Errorf("Error deleting cache for low stock ingredients: %v", err)
 	}
 
 	return nil
 }
 
-func (u *inventoryUseCase) GetLowStockIngredients() ([]model.InventoryResponse, error) {
+func (u *inventoryUseCase) GetLowStockIngredients(ctx context.Context) ([]model.InventoryResponse, error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("GetLowStockIngredients took %v", time.Since(start))
 	}()
 
-	// Try to get the ingredients from the cache first
-	cacheKey := "low_stock_ingredients"
-	var ingredients []model.InventoryResponse
-	if err := u.cache.Get(context.Background(), cacheKey, &ingredients); err == nil {
-		u.logger.Info("Low stock ingredients fetched from cache")
-		return ingredients, nil
-	}
-
-	// If not in cache, get from the database
-	ingredientEntities, err := u.repo.GetLowStockIngredients()
-	if err != nil {
-		return nil, err
-	}
-
-	responses := make([]model.InventoryResponse, len(ingredientEntities))
-	for i, ingredient := range ingredientEntities {
-		responses[i] = model.InventoryResponse{
-			ID:              ingredient.ID,
-			Name:            ingredient.Name,
-			Quantity:        ingredient.Quantity,
-			Unit:            ingredient.Unit,
-			MinimumStock:    ingredient.MinimumStock,
-			ReorderPoint:    ingredient.ReorderPoint,
-			UnitPrice:       ingredient.UnitPrice,
-			LastRestockDate: ingredient.LastRestockDate,
-			CreatedAt:       ingredient.CreatedAt,
-			UpdatedAt:       ingredient.UpdatedAt,
+	return database.GetOrLoad(ctx, u.cache, "low_stock_ingredients", cacheTTL, func(ctx context.Context) ([]model.InventoryResponse, error) {
+		ingredientEntities, err := u.repo.GetLowStockIngredients(ctx)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	// Store the ingredients in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, responses, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for low stock ingredients: %v", err)
-	}
 
-	return responses, nil
+		responses := make([]model.InventoryResponse, len(ingredientEntities))
+		for i, ingredient := range ingredientEntities {
+			responses[i] = model.InventoryResponse{
+				ID:              ingredient.ID,
+				Name:            ingredient.Name,
+				Quantity:        ingredient.Quantity,
+				Unit:            ingredient.Unit,
+				MinimumStock:    ingredient.MinimumStock,
+				ReorderPoint:    ingredient.ReorderPoint,
+				UnitPrice:       ingredient.UnitPrice,
+				LastRestockDate: ingredient.LastRestockDate,
+				CreatedAt:       ingredient.CreatedAt,
+				UpdatedAt:       ingredient.UpdatedAt,
+			}
+		}
+		return responses, nil
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"testing"
 
@@ -16,52 +17,52 @@ type MockInventoryRepository struct {
 	mock.Mock
 }
 
-func (m *MockInventoryRepository) Create(ingredient *entity.Inventory) error {
-	args := m.Called(ingredient)
+func (m *MockInventoryRepository) Create(ctx context.Context, ingredient *entity.Inventory) error {
+	args := m.Called(ctx, ingredient)
 	return args.Error(0)
 }
 
-func (m *MockInventoryRepository) GetByID(id uint) (*entity.Inventory, error) {
-	args := m.Called(id)
+func (m *MockInventoryRepository) GetByID(ctx context.Context, id uint) (*entity.Inventory, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Inventory), args.Error(1)
 }
 
-func (m *MockInventoryRepository) GetAll(params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error) {
-	args := m.Called(params)
+func (m *MockInventoryRepository) GetAll(ctx context.Context, params *model.InventoryQueryParams) (*model.PaginationResponse[[]entity.Inventory], error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.PaginationResponse[[]entity.Inventory]), args.Error(1)
 }
 
-func (m *MockInventoryRepository) Update(ingredient *entity.Inventory) error {
-	args := m.Called(ingredient)
+func (m *MockInventoryRepository) Update(ctx context.Context, ingredient *entity.Inventory) error {
+	args := m.Called(ctx, ingredient)
 	return args.Error(0)
 }
 
-func (m *MockInventoryRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockInventoryRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockInventoryRepository) UpdateStock(id uint, quantity float64) error {
-	args := m.Called(id, quantity)
+func (m *MockInventoryRepository) UpdateStock(ctx context.Context, id uint, quantity float64) error {
+	args := m.Called(ctx, id, quantity)
 	return args.Error(0)
 }
 
-func (m *MockInventoryRepository) GetLowStockIngredients() ([]entity.Inventory, error) {
-	args := m.Called()
+func (m *MockInventoryRepository) GetLowStockIngredients(ctx context.Context) ([]entity.Inventory, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Inventory), args.Error(1)
 }
 
-func (m *MockInventoryRepository) Count() (int64, error) {
-	args := m.Called()
+func (m *MockInventoryRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -69,7 +70,7 @@ func TestInventoryUseCase_GetByID(t *testing.T) {
 	logger := logrus.New()
 	mockInventoryRepo := new(MockInventoryRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache)
+	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedInventory := &entity.Inventory{
@@ -80,10 +81,10 @@ func TestInventoryUseCase_GetByID(t *testing.T) {
 			MinimumStock: 5,
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetByID", uint(1)).Return(expectedInventory, nil).Once()
+		mockInventoryRepo.On("GetByID", mock.Anything, uint(1)).Return(expectedInventory, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		inventory, err := useCase.GetByID(1)
+		inventory, err := useCase.GetByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, inventory)
@@ -93,9 +94,9 @@ func TestInventoryUseCase_GetByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetByID", uint(1)).Return(nil, errors.New("not found")).Once()
+		mockInventoryRepo.On("GetByID", mock.Anything, uint(1)).Return(nil, errors.New("not found")).Once()
 
-		inventory, err := useCase.GetByID(1)
+		inventory, err := useCase.GetByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, inventory)
@@ -107,7 +108,7 @@ func TestInventoryUseCase_GetAll(t *testing.T) {
 	logger := logrus.New()
 	mockInventoryRepo := new(MockInventoryRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache)
+	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := &model.PaginationResponse[[]entity.Inventory]{
@@ -125,10 +126,10 @@ func TestInventoryUseCase_GetAll(t *testing.T) {
 		}
 		params := &model.InventoryQueryParams{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetAll", params).Return(expectedResponse, nil).Once()
+		mockInventoryRepo.On("GetAll", mock.Anything, params).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		inventories, err := useCase.GetAll(params)
+		inventories, err := useCase.GetAll(context.Background(), params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, inventories)
@@ -139,9 +140,9 @@ func TestInventoryUseCase_GetAll(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.InventoryQueryParams{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetAll", params).Return(nil, errors.New("error")).Once()
+		mockInventoryRepo.On("GetAll", mock.Anything, params).Return(nil, errors.New("error")).Once()
 
-		inventories, err := useCase.GetAll(params)
+		inventories, err := useCase.GetAll(context.Background(), params)
 
 		assert.Error(t, err)
 		assert.Nil(t, inventories)
@@ -153,7 +154,7 @@ func TestInventoryUseCase_GetLowStockIngredients(t *testing.T) {
 	logger := logrus.New()
 	mockInventoryRepo := new(MockInventoryRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache)
+	useCase := NewInventoryUseCase(mockInventoryRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedIngredients := []entity.Inventory{
@@ -166,10 +167,10 @@ func TestInventoryUseCase_GetLowStockIngredients(t *testing.T) {
 			},
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetLowStockIngredients").Return(expectedIngredients, nil).Once()
+		mockInventoryRepo.On("GetLowStockIngredients", mock.Anything).Return(expectedIngredients, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		ingredients, err := useCase.GetLowStockIngredients()
+		ingredients, err := useCase.GetLowStockIngredients(context.Background())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, ingredients)
@@ -179,9 +180,9 @@ func TestInventoryUseCase_GetLowStockIngredients(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockInventoryRepo.On("GetLowStockIngredients").Return(nil, errors.New("error")).Once()
+		mockInventoryRepo.On("GetLowStockIngredients", mock.Anything).Return(nil, errors.New("error")).Once()
 
-		ingredients, err := useCase.GetLowStockIngredients()
+		ingredients, err := useCase.GetLowStockIngredients(context.Background())
 
 		assert.Error(t, err)
 		assert.Nil(t, ingredients)
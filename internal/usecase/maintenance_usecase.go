@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"cakestore/internal/database"
+	"cakestore/internal/repository"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaintenanceUseCase holds the housekeeping tasks operators run from the
+// command line
+type MaintenanceUseCase interface {
+	// FlushCache drops the cached reads under prefixes, or every cached read
+	// when there are none, and returns the prefixes dropped. Revoked tokens,
+	// two-factor challenges and rate limits are only dropped when a prefix
+	// names them.
+	FlushCache(ctx context.Context, prefixes []string) ([]string, error)
+	// ReindexSearch rebuilds the indexes behind menu and inventory search and
+	// drops the cached pages of both lists, it returns the indexes rebuilt
+	ReindexSearch(ctx context.Context) ([]string, error)
+}
+
+type maintenanceUseCase struct {
+	searchRepo    repository.SearchRepository
+	log           *logrus.Logger
+	cache         database.RedisCache
+	securityCache database.RedisCache
+}
+
+func NewMaintenanceUseCase(searchRepo repository.SearchRepository, log *logrus.Logger, cache database.RedisCache, securityCache database.RedisCache) MaintenanceUseCase {
+	return &maintenanceUseCase{
+		searchRepo:    searchRepo,
+		log:           log,
+		cache:         cache,
+		securityCache: securityCache,
+	}
+}
+
+func (u *maintenanceUseCase) FlushCache(ctx context.Context, prefixes []string) ([]string, error) {
+	if len(prefixes) == 0 {
+		prefixes = readCachePrefixes
+	}
+	caches := []database.RedisCache{u.cache}
+	// Role permissions and POS devices are kept in the security cache, it is
+	// only a separate one when the cache isn't Redis
+	if u.securityCache != u.cache {
+		caches = append(caches, u.securityCache)
+	}
+	for i, prefix := range prefixes {
+		for _, cache := range caches {
+			if err := cache.DeletePrefix(ctx, prefix); err != nil {
+				u.log.Errorf("Error flushing cache prefix %s: %v", prefix, err)
+				return prefixes[:i], err
+			}
+		}
+	}
+	return prefixes, nil
+}
+
+func (u *maintenanceUseCase) ReindexSearch(ctx context.Context) ([]string, error) {
+	indexes, err := u.searchRepo.Reindex(ctx)
+	if err != nil {
+		return indexes, err
+	}
+	if _, err := u.FlushCache(ctx, []string{menuListCachePrefix, inventoryListCachePrefix}); err != nil {
+		return indexes, err
+	}
+	return indexes, nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"cakestore/internal/database"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchRepository) Reindex(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestMaintenanceUseCase_FlushCache(t *testing.T) {
+	t.Run("drops every cached read by default", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(new(MockSearchRepository), logrus.New(), cache, cache)
+		cache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		flushed, err := useCase.FlushCache(context.Background(), nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, readCachePrefixes, flushed)
+		cache.AssertCalled(t, "DeletePrefix", mock.Anything, "menus:")
+		cache.AssertNotCalled(t, "DeletePrefix", mock.Anything, "revoked_token:")
+		cache.AssertNotCalled(t, "DeletePrefix", mock.Anything, "token_version:")
+	})
+
+	t.Run("drops them from a separate security cache as well", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		securityCache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(new(MockSearchRepository), logrus.New(), cache, securityCache)
+		cache.On("DeletePrefix", mock.Anything, "role_permissions:").Return(nil)
+		securityCache.On("DeletePrefix", mock.Anything, "role_permissions:").Return(nil)
+
+		_, err := useCase.FlushCache(context.Background(), []string{"role_permissions:"})
+
+		assert.NoError(t, err)
+		cache.AssertExpectations(t)
+		securityCache.AssertExpectations(t)
+	})
+
+	t.Run("only drops the given prefixes", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(new(MockSearchRepository), logrus.New(), cache, cache)
+		cache.On("DeletePrefix", mock.Anything, "menu:").Return(nil)
+
+		flushed, err := useCase.FlushCache(context.Background(), []string{"menu:"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"menu:"}, flushed)
+		cache.AssertNumberOfCalls(t, "DeletePrefix", 1)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(new(MockSearchRepository), logrus.New(), cache, cache)
+		cache.On("DeletePrefix", mock.Anything, "menu:").Return(nil)
+		cache.On("DeletePrefix", mock.Anything, "order:").Return(database.ErrRedisUnavailable)
+
+		flushed, err := useCase.FlushCache(context.Background(), []string{"menu:", "order:", "table:"})
+
+		assert.ErrorIs(t, err, database.ErrRedisUnavailable)
+		assert.Equal(t, []string{"menu:"}, flushed)
+	})
+}
+
+func TestMaintenanceUseCase_ReindexSearch(t *testing.T) {
+	t.Run("drops cached menu and inventory pages", func(t *testing.T) {
+		searchRepo := new(MockSearchRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(searchRepo, logrus.New(), cache, cache)
+		searchRepo.On("Reindex", mock.Anything).Return([]string{"idx_menus_title_trgm"}, nil)
+		cache.On("DeletePrefix", mock.Anything, menuListCachePrefix).Return(nil)
+		cache.On("DeletePrefix", mock.Anything, inventoryListCachePrefix).Return(nil)
+
+		indexes, err := useCase.ReindexSearch(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"idx_menus_title_trgm"}, indexes)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("keeps the cache when reindexing fails", func(t *testing.T) {
+		searchRepo := new(MockSearchRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewMaintenanceUseCase(searchRepo, logrus.New(), cache, cache)
+		searchRepo.On("Reindex", mock.Anything).Return(nil, errors.New("index does not exist"))
+
+		_, err := useCase.ReindexSearch(context.Background())
+
+		assert.Error(t, err)
+		cache.AssertNotCalled(t, "DeletePrefix", mock.Anything, mock.Anything)
+	})
+}
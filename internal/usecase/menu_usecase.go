@@ -16,11 +16,11 @@ import (
 )
 
 type MenuUseCase interface {
-	GetAllMenus(params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error)
-	GetMenuByID(id int64) (*entity.Menu, error)
-	CreateMenu(menu *entity.Menu) error
-	UpdateMenu(menu *entity.Menu) error
-	SoftDeleteMenu(id int64) error
+	GetAllMenus(ctx context.Context, params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error)
+	GetMenuByID(ctx context.Context, id int64) (*entity.Menu, error)
+	CreateMenu(ctx context.Context, actor model.Actor, menu *entity.Menu) error
+	UpdateMenu(ctx context.Context, actor model.Actor, menu *entity.Menu) error
+	SoftDeleteMenu(ctx context.Context, actor model.Actor, id int64) error
 }
 
 type menuUseCase struct {
@@ -28,18 +28,20 @@ type menuUseCase struct {
 	logger   *logrus.Logger
 	validate *validator.Validate
 	cache    database.RedisCache
+	audit    AuditRecorder
 }
 
-func NewMenuUseCase(repo repository.MenuRepository, logger *logrus.Logger, cache database.RedisCache) MenuUseCase {
+func NewMenuUseCase(repo repository.MenuRepository, logger *logrus.Logger, cache database.RedisCache, audit AuditRecorder) MenuUseCase {
 	return &menuUseCase{
 		repo:     repo,
 		logger:   logger,
 		validate: validator.New(),
 		cache:    cache,
+		audit:    audit,
 	}
 }
 
-func (uc *menuUseCase) GetAllMenus(params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
+func (uc *menuUseCase) GetAllMenus(ctx context.Context, params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetAllMenus took %v", time.Since(start))
@@ -49,45 +51,31 @@ func (uc *menuUseCase) GetAllMenus(params *model.MenuQueryParams) (*model.Pagina
 		params = &model.MenuQueryParams{}
 	}
 
-	// Try to get the menus from the cache first
-	cacheKey := fmt.Sprintf("menus:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData model.PaginationResponse[[]entity.Menu]
-	if err := uc.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		uc.logger.Info("Menus fetched from cache")
-		return &cachedData, nil
-	}
-
-	// If not in cache, get from the database
-	response, err := uc.repo.GetAll(params)
+	// The menu is the busiest public page, a slightly stale list is served
+	// while it is reloaded
+	cacheKey := fmt.Sprintf("%spage:%d:limit:%d:title:%s:category:%s:price:%g-%g", menuListCachePrefix,
+		params.Page, params.Limit, params.Title, params.Category, params.MinPrice, params.MaxPrice)
+	response, err := database.GetOrLoad(ctx, uc.cache, cacheKey, cacheTTL, func(ctx context.Context) (*model.PaginationResponse[[]entity.Menu], error) {
+		return uc.repo.GetAll(ctx, params)
+	}, database.WithStale(cacheStaleTTL))
 	if err != nil {
 		uc.logger.Errorf("Error fetching menus with params: %v, error: %v", params, err)
 		return nil, err
 	}
 
-	// Store the menus in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, response, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for all menus: %v", err)
-	}
-
 	return response, nil
 }
 
-func (uc *menuUseCase) GetMenuByID(id int64) (*entity.Menu, error) {
+func (uc *menuUseCase) GetMenuByID(ctx context.Context, id int64) (*entity.Menu, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetMenuByID took %v", time.Since(start))
 	}()
 
-	// Try to get the menu from the cache first
 	cacheKey := fmt.Sprintf("menu:%d", id)
-	var menu entity.Menu
-	if err := uc.cache.Get(context.Background(), cacheKey, &menu); err == nil {
-		uc.logger.Info("Menu fetched from cache")
-		return &menu, nil
-	}
-
-	// If not in cache, get from the database
-	menuEntity, err := uc.repo.GetByID(id)
+	menuEntity, err := database.GetOrLoad(ctx, uc.cache, cacheKey, cacheTTL, func(ctx context.Context) (*entity.Menu, error) {
+		return uc.repo.GetByID(ctx, id)
+	})
 	if err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
 			return nil, constants.ErrNotFound
@@ -96,36 +84,47 @@ func (uc *menuUseCase) GetMenuByID(id int64) (*entity.Menu, error) {
 		return nil, err
 	}
 
-	// Store the menu in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, menuEntity, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for menu ID %d: %v", id, err)
-	}
-
 	uc.logger.Infof("Successfully fetched menu with ID %d", id)
 	return menuEntity, nil
 }
 
-func (uc *menuUseCase) CreateMenu(menu *entity.Menu) error {
+func (uc *menuUseCase) CreateMenu(ctx context.Context, actor model.Actor, menu *entity.Menu) error {
 	if err := uc.validate.Struct(menu); err != nil {
 		uc.logger.Errorf("Validation failed for menu: %v", err)
 		return err
 	}
 
-	if err := uc.repo.Create(menu); err != nil {
+	if err := uc.repo.Create(ctx, menu); err != nil {
 		uc.logger.Errorf("Error creating menu: %v", err)
 		return err
 	}
+
+	// Invalidate cache
+	if err := uc.cache.DeletePrefix(ctx, menuListCachePrefix); err != nil {
+		uc.logger.Errorf("Error deleting cache for all menus: %v", err)
+	}
+
+	uc.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityMenu, menu.ID, nil, menu)
 	uc.logger.Infof("Successfully created a new menu: %s", menu.Title)
 	return nil
 }
 
-func (uc *menuUseCase) UpdateMenu(menu *entity.Menu) error {
+func (uc *menuUseCase) UpdateMenu(ctx context.Context, actor model.Actor, menu *entity.Menu) error {
 	if err := uc.validate.Struct(menu); err != nil {
 		uc.logger.Errorf("Validation failed for menu: %v", err)
 		return err
 	}
 
-	if err := uc.repo.UpdateMenu(menu); err != nil {
+	before, err := uc.repo.GetByID(ctx, menu.ID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return constants.ErrNotFound
+		}
+		uc.logger.Errorf("Error getting menu with ID %d: %v", menu.ID, err)
+		return err
+	}
+
+	if err := uc.repo.UpdateMenu(ctx, menu); err != nil {
 		if errors.Is(err, constants.ErrNotFound) {
 			return constants.ErrNotFound
 		}
@@ -135,32 +134,40 @@ func (uc *menuUseCase) UpdateMenu(menu *entity.Menu) error {
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("menu:%d", menu.ID)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
 		uc.logger.Errorf("Error deleting cache for menu ID %d: %v", menu.ID, err)
 	}
-	if err := uc.cache.Delete(context.Background(), "menus:all:*"); err != nil {
+	if err := uc.cache.DeletePrefix(ctx, menuListCachePrefix); err != nil {
 		uc.logger.Errorf("Error deleting cache for all menus: %v", err)
 	}
 
+	uc.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityMenu, menu.ID, before, menu)
 	uc.logger.Infof("Successfully updated menu with ID %d", menu.ID)
 	return nil
 }
 
-func (uc *menuUseCase) SoftDeleteMenu(id int64) error {
-	if err := uc.repo.SoftDelete(id); err != nil {
+func (uc *menuUseCase) SoftDeleteMenu(ctx context.Context, actor model.Actor, id int64) error {
+	before, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Errorf("Error getting menu with ID %d: %v", id, err)
+		return err
+	}
+
+	if err := uc.repo.SoftDelete(ctx, id); err != nil {
 		uc.logger.Errorf("Error deleting menu with ID %d: %v", id, err)
 		return err
 	}
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("menu:%d", id)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := uc.cache.Delete(ctx, cacheKey); err != nil {
 		uc.logger.Errorf("Error deleting cache for menu ID %d: %v", id, err)
 	}
-	if err := uc.cache.Delete(context.Background(), "menus:all:*"); err != nil {
+	if err := uc.cache.DeletePrefix(ctx, menuListCachePrefix); err != nil {
 		uc.logger.Errorf("Error deleting cache for all menus: %v", err)
 	}
 
+	uc.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityMenu, id, before, nil)
 	uc.logger.Infof("Successfully deleted menu with ID %d", id)
 	return nil
 }
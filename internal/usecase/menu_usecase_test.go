@@ -4,8 +4,11 @@ import (
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -16,39 +19,39 @@ type MockMenuRepository struct {
 	mock.Mock
 }
 
-func (m *MockMenuRepository) GetAll(params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
-	args := m.Called(params)
+func (m *MockMenuRepository) GetAll(ctx context.Context, params *model.MenuQueryParams) (*model.PaginationResponse[[]entity.Menu], error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.PaginationResponse[[]entity.Menu]), args.Error(1)
 }
 
-func (m *MockMenuRepository) GetByID(id int64) (*entity.Menu, error) {
-	args := m.Called(id)
+func (m *MockMenuRepository) GetByID(ctx context.Context, id int64) (*entity.Menu, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Menu), args.Error(1)
 }
 
-func (m *MockMenuRepository) Create(menu *entity.Menu) error {
-	args := m.Called(menu)
+func (m *MockMenuRepository) Create(ctx context.Context, menu *entity.Menu) error {
+	args := m.Called(ctx, menu)
 	return args.Error(0)
 }
 
-func (m *MockMenuRepository) UpdateMenu(menu *entity.Menu) error {
-	args := m.Called(menu)
+func (m *MockMenuRepository) UpdateMenu(ctx context.Context, menu *entity.Menu) error {
+	args := m.Called(ctx, menu)
 	return args.Error(0)
 }
 
-func (m *MockMenuRepository) SoftDelete(id int64) error {
-	args := m.Called(id)
+func (m *MockMenuRepository) SoftDelete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockMenuRepository) DecreaseStock(menuID int64, quantity int) error {
-	args := m.Called(menuID, quantity)
+func (m *MockMenuRepository) DecreaseStock(ctx context.Context, menuID int64, quantity int) error {
+	args := m.Called(ctx, menuID, quantity)
 	return args.Error(0)
 }
 
@@ -56,7 +59,7 @@ func TestMenuUseCase_GetAllMenus(t *testing.T) {
 	logger := logrus.New()
 	mockMenuRepo := new(MockMenuRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewMenuUseCase(mockMenuRepo, logger, mockCache)
+	useCase := NewMenuUseCase(mockMenuRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := &model.PaginationResponse[[]entity.Menu]{
@@ -71,10 +74,10 @@ func TestMenuUseCase_GetAllMenus(t *testing.T) {
 		}
 		params := &model.MenuQueryParams{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockMenuRepo.On("GetAll", params).Return(expectedResponse, nil).Once()
+		mockMenuRepo.On("GetAll", mock.Anything, params).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		menus, err := useCase.GetAllMenus(params)
+		menus, err := useCase.GetAllMenus(context.Background(), params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, menus)
@@ -85,9 +88,9 @@ func TestMenuUseCase_GetAllMenus(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.MenuQueryParams{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockMenuRepo.On("GetAll", params).Return(nil, errors.New("error")).Once()
+		mockMenuRepo.On("GetAll", mock.Anything, params).Return(nil, errors.New("error")).Once()
 
-		menus, err := useCase.GetAllMenus(params)
+		menus, err := useCase.GetAllMenus(context.Background(), params)
 
 		assert.Error(t, err)
 		assert.Nil(t, menus)
@@ -99,7 +102,7 @@ func TestMenuUseCase_GetMenuByID(t *testing.T) {
 	logger := logrus.New()
 	mockMenuRepo := new(MockMenuRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewMenuUseCase(mockMenuRepo, logger, mockCache)
+	useCase := NewMenuUseCase(mockMenuRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedMenu := &entity.Menu{
@@ -107,10 +110,10 @@ func TestMenuUseCase_GetMenuByID(t *testing.T) {
 			Title: "Test Menu",
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockMenuRepo.On("GetByID", int64(1)).Return(expectedMenu, nil).Once()
+		mockMenuRepo.On("GetByID", mock.Anything, int64(1)).Return(expectedMenu, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		menu, err := useCase.GetMenuByID(1)
+		menu, err := useCase.GetMenuByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, menu)
@@ -118,14 +121,116 @@ func TestMenuUseCase_GetMenuByID(t *testing.T) {
 		mockMenuRepo.AssertExpectations(t)
 	})
 
+	t.Run("loads with the request's values but its own deadline", func(t *testing.T) {
+		type requestKey struct{}
+		ctx := context.WithValue(context.Background(), requestKey{}, "req-4")
+		mockMenuRepo.On("GetByID", mock.MatchedBy(func(got context.Context) bool {
+			_, hasDeadline := got.Deadline()
+			return got.Value(requestKey{}) == "req-4" && hasDeadline
+		}), int64(4)).Return(&entity.Menu{ID: 4}, nil).Once()
+
+		menu, err := useCase.GetMenuByID(ctx, 4)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), menu.ID)
+		mockMenuRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancelled request stops waiting for the load", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		loaded := make(chan struct{})
+		mockMenuRepo.On("GetByID", mock.MatchedBy(func(got context.Context) bool {
+			return got.Err() == nil
+		}), int64(5)).WaitUntil(time.After(50*time.Millisecond)).Return(&entity.Menu{ID: 5}, nil).Run(func(mock.Arguments) {
+			close(loaded)
+		}).Once()
+
+		menu, err := useCase.GetMenuByID(ctx, 5)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, menu)
+		// The load carries on for the readers that are still waiting
+		<-loaded
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockMenuRepo.On("GetByID", int64(1)).Return(nil, errors.New("not found")).Once()
+		mockMenuRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, errors.New("not found")).Once()
 
-		menu, err := useCase.GetMenuByID(1)
+		menu, err := useCase.GetMenuByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, menu)
 		mockMenuRepo.AssertExpectations(t)
 	})
 }
+
+func TestMenuUseCase_GetAllMenusWithMemoryCache(t *testing.T) {
+	mockMenuRepo := new(MockMenuRepository)
+	useCase := NewMenuUseCase(mockMenuRepo, logrus.New(), database.NewMemoryCache(100), newMockAuditRecorder())
+
+	firstPage := &model.MenuQueryParams{Page: 1, Limit: 10}
+	cakes := &model.MenuQueryParams{Page: 1, Limit: 10, Category: "cake"}
+	mockMenuRepo.On("GetAll", mock.Anything, firstPage).Return(&model.PaginationResponse[[]entity.Menu]{
+		Data: []entity.Menu{{ID: 1, Title: "Black Forest"}, {ID: 2, Title: "Croissant"}}, Total: 2, Page: 1,
+	}, nil).Twice()
+	mockMenuRepo.On("GetAll", mock.Anything, cakes).Return(&model.PaginationResponse[[]entity.Menu]{
+		Data: []entity.Menu{{ID: 1, Title: "Black Forest"}}, Total: 1, Page: 1,
+	}, nil).Once()
+	mockMenuRepo.On("GetByID", mock.Anything, int64(2)).Return(&entity.Menu{ID: 2, Title: "Croissant"}, nil)
+	mockMenuRepo.On("UpdateMenu", mock.Anything, mock.AnythingOfType("*entity.Menu")).Return(nil)
+
+	// Filters are part of the key, the second call of each is served from the cache
+	for i := 0; i < 2; i++ {
+		menus, err := useCase.GetAllMenus(context.Background(), firstPage)
+		assert.NoError(t, err)
+		assert.Len(t, menus.Data, 2)
+		menus, err = useCase.GetAllMenus(context.Background(), cakes)
+		assert.NoError(t, err)
+		assert.Len(t, menus.Data, 1)
+	}
+
+	// An update drops every cached page, so the list is read again
+	assert.NoError(t, useCase.UpdateMenu(context.Background(), model.Actor{}, &entity.Menu{ID: 2, Title: "Butter Croissant"}))
+	_, err := useCase.GetAllMenus(context.Background(), firstPage)
+
+	assert.NoError(t, err)
+	mockMenuRepo.AssertExpectations(t)
+}
+
+func TestMenuUseCase_GetMenuByIDLoadsOnce(t *testing.T) {
+	mockMenuRepo := new(MockMenuRepository)
+	useCase := NewMenuUseCase(mockMenuRepo, logrus.New(), database.NewMemoryCache(100), newMockAuditRecorder())
+
+	t.Run("concurrent misses share one query", func(t *testing.T) {
+		mockMenuRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Menu{ID: 1, Title: "Black Forest"}, nil).
+			After(50 * time.Millisecond).Once()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				menu, err := useCase.GetMenuByID(context.Background(), 1)
+				assert.NoError(t, err)
+				assert.Equal(t, "Black Forest", menu.Title)
+			}()
+		}
+		wg.Wait()
+
+		mockMenuRepo.AssertNumberOfCalls(t, "GetByID", 1)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		mockMenuRepo.On("GetByID", mock.Anything, int64(2)).Return(nil, errors.New("db down")).Once()
+		mockMenuRepo.On("GetByID", mock.Anything, int64(2)).Return(&entity.Menu{ID: 2, Title: "Croissant"}, nil).Once()
+
+		_, err := useCase.GetMenuByID(context.Background(), 2)
+		assert.Error(t, err)
+		menu, err := useCase.GetMenuByID(context.Background(), 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Croissant", menu.Title)
+	})
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
+	"cakestore/internal/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxNotificationAttempts is how often delivery is tried before a message is marked failed
+	maxNotificationAttempts = 5
+	notificationBatchSize   = 50
+	// reservationReminderLead is how long before a reservation the reminder goes out
+	reservationReminderLead = 2 * time.Hour
+)
+
+type NotificationUseCase interface {
+	// Notify renders the event template for a customer and queues it for
+	// delivery. The same event is only queued once per reference.
+	Notify(ctx context.Context, event notification.Event, customerID int64, reference string, data map[string]any) error
+	// Dispatch delivers queued messages that are due, failed deliveries are
+	// retried with exponential backoff
+	Dispatch(ctx context.Context) (int, error)
+	// QueueReservationReminders queues a reminder for reservations starting within two hours
+	QueueReservationReminders(ctx context.Context) (int, error)
+	GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]model.NotificationResponse], error)
+}
+
+type notificationUseCase struct {
+	repo            repository.NotificationRepository
+	customerRepo    repository.CustomerRepository
+	reservationRepo repository.ReservationRepository
+	channel         notification.Channel
+	logger          *logrus.Logger
+}
+
+func NewNotificationUseCase(
+	repo repository.NotificationRepository,
+	customerRepo repository.CustomerRepository,
+	reservationRepo repository.ReservationRepository,
+	channel notification.Channel,
+	logger *logrus.Logger,
+) NotificationUseCase {
+	return &notificationUseCase{
+		repo:            repo,
+		customerRepo:    customerRepo,
+		reservationRepo: reservationRepo,
+		channel:         channel,
+		logger:          logger,
+	}
+}
+
+func (u *notificationUseCase) Notify(ctx context.Context, event notification.Event, customerID int64, reference string, data map[string]any) error {
+	// Notifications follow changes that already happened
+	ctx = context.WithoutCancel(ctx)
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = customer.Name
+	}
+
+	message, err := notification.Render(event, customer.Email, data)
+	if err != nil {
+		return err
+	}
+
+	log := &entity.NotificationLog{
+		CustomerID:    customerID,
+		Event:         string(event),
+		Channel:       u.channel.Name(),
+		Recipient:     message.To,
+		Subject:       message.Subject,
+		Body:          message.Body,
+		DedupeKey:     fmt.Sprintf("%s:%s", event, reference),
+		Status:        entity.NotificationStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	created, err := u.repo.Create(ctx, log)
+	if err != nil {
+		return err
+	}
+	if !created {
+		u.logger.Debugf("Notification %s already queued", log.DedupeKey)
+	}
+	return nil
+}
+
+func (u *notificationUseCase) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := u.repo.ClaimDue(ctx, now, notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range due {
+		log := &due[i]
+		if u.deliver(ctx, log, now) {
+			sent++
+		}
+		if err := u.repo.Update(ctx, log); err != nil {
+			u.logger.Errorf("Error saving notification %d: %v", log.ID, err)
+		}
+	}
+	return sent, nil
+}
+
+// deliver makes one delivery attempt and records the outcome on the log
+func (u *notificationUseCase) deliver(ctx context.Context, log *entity.NotificationLog, now time.Time) bool {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	log.Attempts++
+	err := u.channel.Send(ctx, notification.Message{To: log.Recipient, Subject: log.Subject, Body: log.Body})
+	if err == nil {
+		log.Status = entity.NotificationStatusSent
+		log.SentAt = &now
+		log.LastError = ""
+		return true
+	}
+
+	log.LastError = err.Error()
+	if log.Attempts >= maxNotificationAttempts {
+		log.Status = entity.NotificationStatusFailed
+		u.logger.Errorf("Notification %d to %s failed after %d attempts: %v", log.ID, log.Recipient, log.Attempts, err)
+		return false
+	}
+	// 1, 2, 4, 8 minutes between attempts
+	log.Status = entity.NotificationStatusPending
+	log.NextAttemptAt = now.Add(time.Minute << (log.Attempts - 1))
+	u.logger.Warnf("Notification %d to %s failed, retrying at %s: %v", log.ID, log.Recipient, log.NextAttemptAt.Format(time.RFC3339), err)
+	return false
+}
+
+func (u *notificationUseCase) QueueReservationReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+	reservations, err := u.reservationRepo.GetActiveBetween(ctx, now, now.Add(reservationReminderLead))
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, reservation := range reservations {
+		data := map[string]any{
+			"ReservationID": reservation.ID,
+			"GuestCount":    reservation.GuestCount,
+			"Time":          reservation.ReserveDate.Local().Format("15:04"),
+		}
+		// rescheduled reservations get a new reminder
+		reference := fmt.Sprintf("%d:%d", reservation.ID, reservation.ReserveDate.Unix())
+		if err := u.Notify(ctx, notification.EventReservationReminder, int64(reservation.CustomerID), reference, data); err != nil {
+			u.logger.Errorf("Error queueing reminder for reservation %d: %v", reservation.ID, err)
+			continue
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (u *notificationUseCase) GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]model.NotificationResponse], error) {
+	result, err := u.repo.GetAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.NotificationResponse, len(result.Data))
+	for i := range result.Data {
+		responses[i] = model.ToNotificationResponse(&result.Data[i])
+	}
+
+	return &model.PaginationResponse[[]model.NotificationResponse]{
+		Data:       responses,
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		TotalPages: result.TotalPages,
+	}, nil
+}
+
+// notifyOrderPaid queues the payment confirmation for an order, failures are
+// only logged since the payment itself succeeded
+func notifyOrderPaid(ctx context.Context, notifier NotificationUseCase, logger *logrus.Logger, order *entity.Order) {
+	data := map[string]any{
+		"OrderID": order.ID,
+		"Amount":  notification.FormatAmount(order.TotalPrice),
+	}
+	if err := notifier.Notify(ctx, notification.EventOrderPaid, order.CustomerID, fmt.Sprint(order.ID), data); err != nil {
+		logger.Errorf("Error queueing paid notification for order %d: %v", order.ID, err)
+	}
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *entity.NotificationLog) (bool, error) {
+	args := m.Called(ctx, notification)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Update(ctx context.Context, notification *entity.NotificationLog) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]entity.NotificationLog, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.NotificationLog), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]entity.NotificationLog], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaginationResponse[[]entity.NotificationLog]), args.Error(1)
+}
+
+type MockNotificationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockNotificationUseCase) Notify(ctx context.Context, event notification.Event, customerID int64, reference string, data map[string]any) error {
+	args := m.Called(ctx, event, customerID, reference, data)
+	return args.Error(0)
+}
+
+func (m *MockNotificationUseCase) Dispatch(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) QueueReservationReminders(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationUseCase) GetAll(ctx context.Context, params *model.NotificationQueryParams) (*model.PaginationResponse[[]model.NotificationResponse], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaginationResponse[[]model.NotificationResponse]), args.Error(1)
+}
+
+type MockChannel struct {
+	mock.Mock
+}
+
+func (m *MockChannel) Name() string {
+	return "mock"
+}
+
+func (m *MockChannel) Send(ctx context.Context, message notification.Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func TestNotificationUseCase_Notify(t *testing.T) {
+	t.Run("renders and queues the message", func(t *testing.T) {
+		repo := new(MockNotificationRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewNotificationUseCase(repo, customerRepo, nil, new(MockChannel), logrus.New())
+
+		customerRepo.On("GetByID", mock.Anything, int64(3)).Return(&entity.Customer{ID: 3, Name: "Rina", Email: "rina@example.com"}, nil)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(n *entity.NotificationLog) bool {
+			return n.Recipient == "rina@example.com" &&
+				n.Subject == "Order #12 is ready for pickup" &&
+				n.DedupeKey == "order_ready:12" &&
+				n.Channel == "mock" &&
+				n.Status == entity.NotificationStatusPending
+		})).Return(true, nil)
+
+		err := useCase.Notify(context.Background(), notification.EventOrderReady, 3, "12", map[string]any{"OrderID": 12})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("missing template data", func(t *testing.T) {
+		repo := new(MockNotificationRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewNotificationUseCase(repo, customerRepo, nil, new(MockChannel), logrus.New())
+
+		customerRepo.On("GetByID", mock.Anything, int64(3)).Return(&entity.Customer{ID: 3, Name: "Rina", Email: "rina@example.com"}, nil)
+
+		err := useCase.Notify(context.Background(), notification.EventOrderPaid, 3, "12", map[string]any{"OrderID": 12})
+
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestNotificationUseCase_Dispatch(t *testing.T) {
+	t.Run("successful delivery", func(t *testing.T) {
+		repo := new(MockNotificationRepository)
+		channel := new(MockChannel)
+		useCase := NewNotificationUseCase(repo, nil, nil, channel, logrus.New())
+
+		repo.On("ClaimDue", mock.Anything, mock.Anything, notificationBatchSize).Return([]entity.NotificationLog{
+			{ID: 1, Recipient: "rina@example.com", Subject: "Hi", Body: "Body", Status: entity.NotificationStatusSending},
+		}, nil)
+		channel.On("Send", notification.Message{To: "rina@example.com", Subject: "Hi", Body: "Body"}).Return(nil)
+		repo.On("Update", mock.Anything, mock.MatchedBy(func(n *entity.NotificationLog) bool {
+			return n.Status == entity.NotificationStatusSent && n.Attempts == 1 && n.SentAt != nil
+		})).Return(nil)
+
+		sent, err := useCase.Dispatch(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		repo := new(MockNotificationRepository)
+		channel := new(MockChannel)
+		useCase := NewNotificationUseCase(repo, nil, nil, channel, logrus.New())
+
+		repo.On("ClaimDue", mock.Anything, mock.Anything, notificationBatchSize).Return([]entity.NotificationLog{
+			{ID: 1, Recipient: "rina@example.com", Attempts: 2, Status: entity.NotificationStatusSending},
+		}, nil)
+		channel.On("Send", mock.Anything).Return(errors.New("connection refused"))
+		repo.On("Update", mock.Anything, mock.MatchedBy(func(n *entity.NotificationLog) bool {
+			return n.Status == entity.NotificationStatusPending &&
+				n.Attempts == 3 &&
+				n.LastError == "connection refused" &&
+				time.Until(n.NextAttemptAt) > 3*time.Minute
+		})).Return(nil)
+
+		sent, err := useCase.Dispatch(context.Background())
+
+		assert.NoError(t, err)
+		assert.Zero(t, sent)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		repo := new(MockNotificationRepository)
+		channel := new(MockChannel)
+		useCase := NewNotificationUseCase(repo, nil, nil, channel, logrus.New())
+
+		repo.On("ClaimDue", mock.Anything, mock.Anything, notificationBatchSize).Return([]entity.NotificationLog{
+			{ID: 1, Recipient: "rina@example.com", Attempts: maxNotificationAttempts - 1, Status: entity.NotificationStatusSending},
+		}, nil)
+		channel.On("Send", mock.Anything).Return(errors.New("mailbox unavailable"))
+		repo.On("Update", mock.Anything, mock.MatchedBy(func(n *entity.NotificationLog) bool {
+			return n.Status == entity.NotificationStatusFailed
+		})).Return(nil)
+
+		_, err := useCase.Dispatch(context.Background())
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestNotificationUseCase_QueueReservationReminders(t *testing.T) {
+	repo := new(MockNotificationRepository)
+	customerRepo := new(MockCustomerRepository)
+	reservationRepo := new(MockReservationRepository)
+	useCase := NewNotificationUseCase(repo, customerRepo, reservationRepo, new(MockChannel), logrus.New())
+
+	reserveDate := time.Now().Add(90 * time.Minute)
+	reservationRepo.On("GetActiveBetween", mock.Anything, mock.Anything, mock.Anything).Return([]entity.Reservation{
+		{ID: 7, CustomerID: 3, GuestCount: 4, ReserveDate: reserveDate},
+	}, nil)
+	customerRepo.On("GetByID", mock.Anything, int64(3)).Return(&entity.Customer{ID: 3, Name: "Rina", Email: "rina@example.com"}, nil)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(n *entity.NotificationLog) bool {
+		return n.Event == string(notification.EventReservationReminder) && n.CustomerID == 3
+	})).Return(true, nil)
+
+	queued, err := useCase.QueueReservationReminders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queued)
+	repo.AssertExpectations(t)
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "Rp0", notification.FormatAmount(0))
+	assert.Equal(t, "Rp950", notification.FormatAmount(950))
+	assert.Equal(t, "Rp150.000", notification.FormatAmount(150000))
+	assert.Equal(t, "Rp1.250.000", notification.FormatAmount(1250000))
+}
@@ -1,108 +1,119 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
 	"cakestore/internal/repository"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type OrderUseCase interface {
-	CreateOrder(customerID int64, request *model.CreateOrderRequest) (*entity.Order, error)
-	GetOrderByID(id int64) (*model.OrderResponse, error)
-	GetPendingOrder(customerID int64, orderID int64) (*model.OrderResponse, error)
-	GetAllOrders(params *model.PaginationQuery) (*[]model.OrderResponse, *model.PaginatedMeta, error)
-	GetCustomerOrders(customerID int64) ([]model.OrderResponse, error)
-	UpdateOrderStatus(id string, status string) error
-	DeleteOrder(id int64) error
-	UpdateFoodStatus(orderID int64, foodStatus entity.FoodStatus) error
+	CreateOrder(ctx context.Context, customerID int64, request *model.CreateOrderRequest) (*entity.Order, error)
+	GetOrderByID(ctx context.Context, id int64) (*model.OrderResponse, error)
+	GetPendingOrder(ctx context.Context, customerID int64, orderID int64) (*model.OrderResponse, error)
+	GetAllOrders(ctx context.Context, params *model.PaginationQuery) (*[]model.OrderResponse, *model.PaginatedMeta, error)
+	GetCustomerOrders(ctx context.Context, customerID int64) ([]model.OrderResponse, error)
+	UpdateOrderStatus(ctx context.Context, actor model.Actor, id string, status string) error
+	DeleteOrder(ctx context.Context, actor model.Actor, id int64) error
+	UpdateFoodStatus(ctx context.Context, actor model.Actor, orderID int64, foodStatus entity.FoodStatus) error
+	// Export writes the orders created in the queried range, oldest first,
+	// as CSV with one row per order or as JSON with one order per line
+	Export(ctx context.Context, query *model.OrderExportQuery, w io.Writer) error
 }
 
 type orderUseCaseImpl struct {
 	orderRepo    repository.OrderRepository
 	menuRepo     repository.MenuRepository
 	customerRepo repository.CustomerRepository
+	notifier     NotificationUseCase
 	logger       *logrus.Logger
 	env          string
 	cache        database.RedisCache
+	audit        AuditRecorder
 }
 
 func NewOrderUseCase(
 	orderRepo repository.OrderRepository,
 	menuRepo repository.MenuRepository,
 	customerRepo repository.CustomerRepository,
+	notifier NotificationUseCase,
 	logger *logrus.Logger,
 	env string,
 	cache database.RedisCache,
+	audit AuditRecorder,
 ) OrderUseCase {
 	return &orderUseCaseImpl{
 		orderRepo:    orderRepo,
 		menuRepo:     menuRepo,
 		customerRepo: customerRepo,
+		notifier:     notifier,
 		logger:       logger,
 		env:          env,
 		cache:        cache,
+		audit:        audit,
 	}
 }
 
-func (uc *orderUseCaseImpl) UpdateFoodStatus(orderID int64, foodStatus entity.FoodStatus) error {
-	if err := uc.orderRepo.UpdateFoodStatus(orderID, foodStatus); err != nil {
+func (uc *orderUseCaseImpl) UpdateFoodStatus(ctx context.Context, actor model.Actor, orderID int64, foodStatus entity.FoodStatus) error {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
 		return err
 	}
-
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("order:%d", orderID)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
-		uc.logger.Errorf("Error deleting cache for order ID %d: %v", orderID, err)
+	if err := uc.orderRepo.UpdateFoodStatus(ctx, orderID, foodStatus); err != nil {
+		return err
 	}
-	if err := uc.cache.Delete(context.Background(), "orders:all:*"); err != nil {
-		uc.logger.Errorf("Error deleting cache for all orders: %v", err)
+	uc.audit.Record(ctx, actor, constants.AuditActionStatusChange, constants.AuditEntityOrder, orderID,
+		map[string]any{"food_status": order.FoodStatus}, map[string]any{"food_status": foodStatus})
+
+	if foodStatus == entity.FoodStatusReady {
+		data := map[string]any{"OrderID": order.ID}
+		if err := uc.notifier.Notify(ctx, notification.EventOrderReady, order.CustomerID, fmt.Sprint(order.ID), data); err != nil {
+			uc.logger.Errorf("Error queueing ready notification for order %d: %v", order.ID, err)
+		}
 	}
 
+	// Invalidate cache
+	invalidateOrderCache(ctx, uc.cache, uc.logger, orderID, order.CustomerID)
+
 	return nil
 }
 
-func (uc *orderUseCaseImpl) GetPendingOrder(customerID int64, orderID int64) (*model.OrderResponse, error) {
+func (uc *orderUseCaseImpl) GetPendingOrder(ctx context.Context, customerID int64, orderID int64) (*model.OrderResponse, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetPendingOrder took %v", time.Since(start))
 	}()
 
-	// Try to get the order from the cache first
-	cacheKey := fmt.Sprintf("order:pending:%d:%d", customerID, orderID)
-	var order model.OrderResponse
-	if err := uc.cache.Get(context.Background(), cacheKey, &order); err == nil {
-		uc.logger.Info("Pending order fetched from cache")
-		return &order, nil
-	}
-
-	// If not in cache, get from the database
-	orderEntity, err := uc.orderRepo.GetPendingPaymentByOrderID(customerID, orderID)
-	if err != nil {
-		return nil, err
-	}
-	response := model.ToOrderResponse(&orderEntity)
-
-	// Store the order in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, response, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for pending order: %v", err)
-	}
-
-	return response, nil
+	return database.GetOrLoad(ctx, uc.cache, pendingOrderCacheKey(customerID, orderID), cacheTTL, func(ctx context.Context) (*model.OrderResponse, error) {
+		orderEntity, err := uc.orderRepo.GetPendingPaymentByOrderID(ctx, customerID, orderID)
+		if err != nil {
+			return nil, err
+		}
+		return model.ToOrderResponse(&orderEntity), nil
+	})
 }
 
-func (uc *orderUseCaseImpl) CreateOrder(customerID int64, request *model.CreateOrderRequest) (*entity.Order, error) {
-	customer, err := uc.customerRepo.GetByID(customerID)
+func (uc *orderUseCaseImpl) CreateOrder(ctx context.Context, customerID int64, request *model.CreateOrderRequest) (*entity.Order, error) {
+	customer, err := uc.customerRepo.GetByID(ctx, customerID)
 	if err != nil {
 		return nil, errors.New("customer not found")
 	}
+	if customer.EmailVerifiedAt == nil {
+		return nil, constants.ErrEmailNotVerified
+	}
 
 	// Create order items and calculate total price
 	var orderItems []entity.OrderItem
@@ -110,7 +121,7 @@ func (uc *orderUseCaseImpl) CreateOrder(customerID int64, request *model.CreateO
 
 	// Check and decrease stock for each item
 	for _, item := range request.Items {
-		menu, err := uc.menuRepo.GetByID(item.MenuID)
+		menu, err := uc.menuRepo.GetByID(ctx, item.MenuID)
 		if err != nil {
 			return nil, errors.New("menu not found")
 		}
@@ -122,7 +133,7 @@ func (uc *orderUseCaseImpl) CreateOrder(customerID int64, request *model.CreateO
 		}
 
 		// Decrease stock
-		if err := uc.menuRepo.DecreaseStock(item.MenuID, int(item.Quantity)); err != nil {
+		if err := uc.menuRepo.DecreaseStock(ctx, item.MenuID, int(item.Quantity)); err != nil {
 			return nil, fmt.Errorf("failed to decrease stock for menu item %d: %v", item.MenuID, err)
 		}
 
@@ -147,77 +158,56 @@ func (uc *orderUseCaseImpl) CreateOrder(customerID int64, request *model.CreateO
 		UpdatedAt:  time.Now(),
 	}
 
-	if err := uc.orderRepo.Create(order); err != nil {
+	if err := uc.orderRepo.Create(ctx, order); err != nil {
 		uc.logger.Errorf("Error creating order: %v", err)
 		return nil, err
 	}
 
+	// Invalidate cache, the new order and the lower stock show up in the lists
+	invalidateOrderCache(ctx, uc.cache, uc.logger, order.ID, customerID)
+	if err := uc.cache.DeletePrefix(ctx, menuListCachePrefix); err != nil {
+		uc.logger.Errorf("Error deleting cache for all menus: %v", err)
+	}
+
 	return order, nil
 }
 
-func (uc *orderUseCaseImpl) GetOrderByID(id int64) (*model.OrderResponse, error) {
+func (uc *orderUseCaseImpl) GetOrderByID(ctx context.Context, id int64) (*model.OrderResponse, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetOrderByID took %v", time.Since(start))
 	}()
 
-	// Try to get the order from the cache first
-	cacheKey := fmt.Sprintf("order:%d", id)
-	var order model.OrderResponse
-	if err := uc.cache.Get(context.Background(), cacheKey, &order); err == nil {
-		uc.logger.Info("Order fetched from cache")
-		return &order, nil
-	}
-
-	// If not in cache, get from the database
-	orderEntity, err := uc.orderRepo.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-	response := model.ToOrderResponse(orderEntity)
-
-	// Store the order in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, response, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for order ID %d: %v", id, err)
-	}
-
-	return response, nil
+	return database.GetOrLoad(ctx, uc.cache, fmt.Sprintf("order:%d", id), cacheTTL, func(ctx context.Context) (*model.OrderResponse, error) {
+		orderEntity, err := uc.orderRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return model.ToOrderResponse(orderEntity), nil
+	})
 }
 
-func (uc *orderUseCaseImpl) GetCustomerOrders(customerID int64) ([]model.OrderResponse, error) {
+func (uc *orderUseCaseImpl) GetCustomerOrders(ctx context.Context, customerID int64) ([]model.OrderResponse, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetCustomerOrders took %v", time.Since(start))
 	}()
 
-	// Try to get the orders from the cache first
-	cacheKey := fmt.Sprintf("orders:customer:%d", customerID)
-	var orders []model.OrderResponse
-	if err := uc.cache.Get(context.Background(), cacheKey, &orders); err == nil {
-		uc.logger.Info("Customer orders fetched from cache")
-		return orders, nil
-	}
-
-	// If not in cache, get from the database
-	orderEntities, err := uc.orderRepo.GetByCustomerID(customerID)
-	if err != nil {
-		return nil, err
-	}
-
-	responses := make([]model.OrderResponse, len(orderEntities))
-	for i, order := range orderEntities {
-		responses[i] = *model.ToOrderResponse(&order)
-	}
-
-	// Store the orders in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, responses, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for customer orders: %v", err)
-	}
+	return database.GetOrLoad(ctx, uc.cache, customerOrdersCacheKey(customerID), cacheTTL, func(ctx context.Context) ([]model.OrderResponse, error) {
+		orderEntities, err := uc.orderRepo.GetByCustomerID(ctx, customerID)
+		if err != nil {
+			return nil, err
+		}
 
-	return responses, nil
+		responses := make([]model.OrderResponse, len(orderEntities))
+		for i, order := range orderEntities {
+			responses[i] = *model.ToOrderResponse(&order)
+		}
+		return responses, nil
+	})
 }
 
-func (uc *orderUseCaseImpl) UpdateOrderStatus(id string, status string) error {
+func (uc *orderUseCaseImpl) UpdateOrderStatus(ctx context.Context, actor model.Actor, id string, status string) error {
 	orderStatus := entity.OrderStatus(status)
 	uc.logger.Tracef("UpdateOrderStatus usecase ~ in %s", uc.env)
 
@@ -225,7 +215,7 @@ func (uc *orderUseCaseImpl) UpdateOrderStatus(id string, status string) error {
 	var err error
 
 	if uc.env == "development" {
-		orderID, err = uc.orderRepo.GetPendingOrder()
+		orderID, err = uc.orderRepo.GetPendingOrder(ctx)
 		if err != nil {
 			uc.logger.Errorf("UpdateOrderStatus usecase ~Error getting pending order: %v", err)
 			return err
@@ -237,42 +227,50 @@ func (uc *orderUseCaseImpl) UpdateOrderStatus(id string, status string) error {
 		}
 	}
 
-	if err := uc.orderRepo.UpdateStatus(orderID, orderStatus); err != nil {
-		uc.logger.Errorf("Error updating order status: %v", err)
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		uc.logger.Errorf("Error getting order %d: %v", orderID, err)
 		return err
 	}
+	previous := order.Status
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("order:%d", orderID)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
-		uc.logger.Errorf("Error deleting cache for order ID %d: %v", orderID, err)
+	if err := uc.orderRepo.UpdateStatus(ctx, orderID, orderStatus); err != nil {
+		uc.logger.Errorf("Error updating order status: %v", err)
+		return err
 	}
-	if err := uc.cache.Delete(context.Background(), "orders:all:*"); err != nil {
-		uc.logger.Errorf("Error deleting cache for all orders: %v", err)
+	uc.audit.Record(ctx, actor, constants.AuditActionStatusChange, constants.AuditEntityOrder, orderID,
+		map[string]any{"status": previous}, map[string]any{"status": orderStatus})
+
+	if orderStatus == entity.OrderStatusPaid {
+		order.Status = orderStatus
+		notifyOrderPaid(ctx, uc.notifier, uc.logger, order)
 	}
 
+	// Invalidate cache
+	invalidateOrderCache(ctx, uc.cache, uc.logger, orderID, order.CustomerID)
+
 	return nil
 }
 
-func (uc *orderUseCaseImpl) DeleteOrder(id int64) error {
-	if err := uc.orderRepo.Delete(id); err != nil {
+func (uc *orderUseCaseImpl) DeleteOrder(ctx context.Context, actor model.Actor, id int64) error {
+	order, err := uc.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Errorf("Error getting order %d: %v", id, err)
+		return err
+	}
+	if err := uc.orderRepo.Delete(ctx, id); err != nil {
 		uc.logger.Errorf("Error deleting order: %v", err)
 		return err
 	}
+	uc.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityOrder, id, model.ToOrderResponse(order), nil)
 
 	// Invalidate cache
-	cacheKey := fmt.Sprintf("order:%d", id)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
-		uc.logger.Errorf("Error deleting cache for order ID %d: %v", id, err)
-	}
-	if err := uc.cache.Delete(context.Background(), "orders:all:*"); err != nil {
-		uc.logger.Errorf("Error deleting cache for all orders: %v", err)
-	}
+	invalidateOrderCache(ctx, uc.cache, uc.logger, id, order.CustomerID)
 
 	return nil
 }
 
-func (uc *orderUseCaseImpl) GetAllOrders(params *model.PaginationQuery) (*[]model.OrderResponse, *model.PaginatedMeta, error) {
+func (uc *orderUseCaseImpl) GetAllOrders(ctx context.Context, params *model.PaginationQuery) (*[]model.OrderResponse, *model.PaginatedMeta, error) {
 	start := time.Now()
 	defer func() {
 		uc.logger.Infof("GetAllOrders took %v", time.Since(start))
@@ -280,36 +278,97 @@ func (uc *orderUseCaseImpl) GetAllOrders(params *model.PaginationQuery) (*[]mode
 
 	uc.logger.Trace("GetAllOrders usecase ~ in ", uc.env)
 
-	// Try to get the orders from the cache first
-	cacheKey := fmt.Sprintf("orders:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData struct {
-		Data []model.OrderResponse
-		Meta *model.PaginatedMeta
-	}
-	if err := uc.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		uc.logger.Info("All orders fetched from cache")
-		return &cachedData.Data, cachedData.Meta, nil
-	}
+	cacheKey := fmt.Sprintf("%spage:%d:limit:%d", orderListCachePrefix, params.Page, params.Limit)
+	page, err := database.GetOrLoad(ctx, uc.cache, cacheKey, cacheTTL, func(ctx context.Context) (cachedPage[[]model.OrderResponse], error) {
+		orders, meta, err := uc.orderRepo.GetAll(ctx, params)
+		if err != nil {
+			return cachedPage[[]model.OrderResponse]{}, err
+		}
 
-	// If not in cache, get from the database
-	orders, meta, err := uc.orderRepo.GetAll(params)
+		responses := make([]model.OrderResponse, len(orders))
+		for i, order := range orders {
+			responses[i] = *model.ToOrderResponse(&order)
+		}
+		return cachedPage[[]model.OrderResponse]{Data: responses, Meta: meta}, nil
+	})
 	if err != nil {
 		uc.logger.Errorf("Error getting all orders: %v", err)
 		return nil, nil, err
 	}
 
-	responses := make([]model.OrderResponse, len(orders))
-	for i, order := range orders {
-		responses[i] = *model.ToOrderResponse(&order)
+	return &page.Data, page.Meta, nil
+}
+
+func (uc *orderUseCaseImpl) Export(ctx context.Context, query *model.OrderExportQuery, w io.Writer) error {
+	if !query.To.After(query.From) {
+		return fmt.Errorf("%w: the end of the range must be after its start", constants.ErrInvalidRequest)
 	}
 
-	// Store the orders in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, struct {
-		Data []model.OrderResponse
-		Meta *model.PaginatedMeta
-	}{Data: responses, Meta: meta}, 5*time.Minute); err != nil {
-		uc.logger.Errorf("Error setting cache for all orders: %v", err)
+	switch query.Format {
+	case model.OrderExportCSV:
+		writer := csv.NewWriter(w)
+		header := []string{"id", "created_at", "customer_id", "customer_email", "status", "food_status", "total_price", "items", "delivery_address"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		err := uc.orderRepo.Each(ctx, query.From, query.To, func(orders []entity.Order) error {
+			for _, order := range orders {
+				items := make([]string, len(order.Items))
+				for i, item := range order.Items {
+					items[i] = fmt.Sprintf("%dx %s", item.Quantity, item.Menu.Title)
+				}
+				record := []string{
+					strconv.FormatInt(order.ID, 10),
+					order.CreatedAt.Format(time.RFC3339),
+					strconv.FormatInt(order.CustomerID, 10),
+					order.Customer.Email,
+					string(order.Status),
+					string(order.FoodStatus),
+					strconv.FormatFloat(order.TotalPrice, 'f', 2, 64),
+					strings.Join(items, "; "),
+					order.Address,
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case model.OrderExportJSON:
+		encoder := json.NewEncoder(w)
+		return uc.orderRepo.Each(ctx, query.From, query.To, func(orders []entity.Order) error {
+			for i := range orders {
+				if err := encoder.Encode(model.ToOrderResponse(&orders[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	default:
+		return fmt.Errorf("%w: unknown export format %q, expected csv or json", constants.ErrInvalidRequest, query.Format)
 	}
+}
 
-	return &responses, meta, nil
+// invalidateOrderCache drops the cached order together with every list it appears in
+func invalidateOrderCache(ctx context.Context, cache database.RedisCache, logger *logrus.Logger, orderID, customerID int64) {
+	if err := cache.Delete(ctx, fmt.Sprintf("order:%d", orderID)); err != nil {
+		logger.Errorf("Error deleting cache for order ID %d: %v", orderID, err)
+	}
+	if err := cache.Delete(ctx, customerOrdersCacheKey(customerID)); err != nil {
+		logger.Errorf("Error deleting cache for orders of customer ID %d: %v", customerID, err)
+	}
+	if err := cache.Delete(ctx, pendingOrderCacheKey(customerID, orderID)); err != nil {
+		logger.Errorf("Error deleting cache for pending order ID %d: %v", orderID, err)
+	}
+	if err := cache.DeletePrefix(ctx, orderListCachePrefix); err != nil {
+		logger.Errorf("Error deleting cache for all orders: %v", err)
+	}
 }
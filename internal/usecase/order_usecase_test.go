@@ -1,11 +1,17 @@
 package usecase
 
 import (
+	"bytes"
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -16,91 +22,106 @@ type MockOrderRepository struct {
 	mock.Mock
 }
 
-func (m *MockOrderRepository) Create(order *entity.Order) error {
-	args := m.Called(order)
+func (m *MockOrderRepository) Create(ctx context.Context, order *entity.Order) error {
+	args := m.Called(ctx, order)
 	return args.Error(0)
 }
 
-func (m *MockOrderRepository) GetByID(id int64) (*entity.Order, error) {
-	args := m.Called(id)
+func (m *MockOrderRepository) GetByID(ctx context.Context, id int64) (*entity.Order, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) GetPendingPaymentByOrderID(customerID, orderID int64) (entity.Order, error) {
-	args := m.Called(customerID, orderID)
+func (m *MockOrderRepository) GetPendingPaymentByOrderID(ctx context.Context, customerID, orderID int64) (entity.Order, error) {
+	args := m.Called(ctx, customerID, orderID)
 	if args.Get(0) == nil {
 		return entity.Order{}, args.Error(1)
 	}
 	return args.Get(0).(entity.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) GetAll(params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error) {
-	args := m.Called(params)
+func (m *MockOrderRepository) GetAll(ctx context.Context, params *model.PaginationQuery) ([]entity.Order, *model.PaginatedMeta, error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
 	}
 	return args.Get(0).([]entity.Order), args.Get(1).(*model.PaginatedMeta), args.Error(2)
 }
 
-func (m *MockOrderRepository) GetByCustomerID(customerID int64) ([]entity.Order, error) {
-	args := m.Called(customerID)
+func (m *MockOrderRepository) GetByCustomerID(ctx context.Context, customerID int64) ([]entity.Order, error) {
+	args := m.Called(ctx, customerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) UpdateStatus(id int64, status entity.OrderStatus) error {
-	args := m.Called(id, status)
+func (m *MockOrderRepository) UpdateStatus(ctx context.Context, id int64, status entity.OrderStatus) error {
+	args := m.Called(ctx, id, status)
 	return args.Error(0)
 }
 
-func (m *MockOrderRepository) Delete(id int64) error {
-	args := m.Called(id)
+func (m *MockOrderRepository) TransitionStatus(ctx context.Context, id int64, from, to entity.OrderStatus) (bool, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockOrderRepository) UpdateFoodStatus(orderID int64, foodStatus entity.FoodStatus) error {
-	args := m.Called(orderID, foodStatus)
+func (m *MockOrderRepository) UpdateFoodStatus(ctx context.Context, orderID int64, foodStatus entity.FoodStatus) error {
+	args := m.Called(ctx, orderID, foodStatus)
 	return args.Error(0)
 }
 
-func (m *MockOrderRepository) GetPendingOrder() (int64, error) {
-	args := m.Called()
+func (m *MockOrderRepository) GetPendingOrder(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockOrderRepository) FindByDateRange(startDate, endDate string) ([]entity.Order, error) {
-	args := m.Called(startDate, endDate)
+func (m *MockOrderRepository) FindByDateRange(ctx context.Context, startDate, endDate string) ([]entity.Order, error) {
+	args := m.Called(ctx, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) Update(order *entity.Order) error {
-	args := m.Called(order)
+func (m *MockOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	args := m.Called(ctx, order)
 	return args.Error(0)
 }
 
+func (m *MockOrderRepository) Each(ctx context.Context, from, to time.Time, fn func(orders []entity.Order) error) error {
+	args := m.Called(ctx, from, to)
+	if orders, ok := args.Get(0).([]entity.Order); ok {
+		if err := fn(orders); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func TestOrderUseCase_GetOrderByID(t *testing.T) {
 	logger := logrus.New()
 	mockOrderRepo := new(MockOrderRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, logger, "test", mockCache)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logger, "test", mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedOrder := &entity.Order{
 			ID: 1,
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetByID", int64(1)).Return(expectedOrder, nil).Once()
+		mockOrderRepo.On("GetByID", mock.Anything, int64(1)).Return(expectedOrder, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		order, err := useCase.GetOrderByID(1)
+		order, err := useCase.GetOrderByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, order)
@@ -110,9 +131,9 @@ func TestOrderUseCase_GetOrderByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetByID", int64(1)).Return(nil, errors.New("not found")).Once()
+		mockOrderRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, errors.New("not found")).Once()
 
-		order, err := useCase.GetOrderByID(1)
+		order, err := useCase.GetOrderByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, order)
@@ -124,17 +145,17 @@ func TestOrderUseCase_GetPendingOrder(t *testing.T) {
 	logger := logrus.New()
 	mockOrderRepo := new(MockOrderRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, logger, "test", mockCache)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logger, "test", mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedOrder := entity.Order{
 			ID: 1,
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetPendingPaymentByOrderID", int64(1), int64(1)).Return(expectedOrder, nil).Once()
+		mockOrderRepo.On("GetPendingPaymentByOrderID", mock.Anything, int64(1), int64(1)).Return(expectedOrder, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		order, err := useCase.GetPendingOrder(1, 1)
+		order, err := useCase.GetPendingOrder(context.Background(), 1, 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, order)
@@ -144,9 +165,9 @@ func TestOrderUseCase_GetPendingOrder(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetPendingPaymentByOrderID", int64(1), int64(1)).Return(entity.Order{}, errors.New("not found")).Once()
+		mockOrderRepo.On("GetPendingPaymentByOrderID", mock.Anything, int64(1), int64(1)).Return(entity.Order{}, errors.New("not found")).Once()
 
-		order, err := useCase.GetPendingOrder(1, 1)
+		order, err := useCase.GetPendingOrder(context.Background(), 1, 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, order)
@@ -158,7 +179,7 @@ func TestOrderUseCase_GetAllOrders(t *testing.T) {
 	logger := logrus.New()
 	mockOrderRepo := new(MockOrderRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, logger, "test", mockCache)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logger, "test", mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := []entity.Order{
@@ -171,10 +192,10 @@ func TestOrderUseCase_GetAllOrders(t *testing.T) {
 		}
 		params := &model.PaginationQuery{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetAll", params).Return(expectedResponse, meta, nil).Once()
+		mockOrderRepo.On("GetAll", mock.Anything, params).Return(expectedResponse, meta, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		orders, resultMeta, err := useCase.GetAllOrders(params)
+		orders, resultMeta, err := useCase.GetAllOrders(context.Background(), params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, orders)
@@ -186,9 +207,9 @@ func TestOrderUseCase_GetAllOrders(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.PaginationQuery{Page: 1, Limit: 10}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetAll", params).Return(nil, nil, errors.New("error")).Once()
+		mockOrderRepo.On("GetAll", mock.Anything, params).Return(nil, nil, errors.New("error")).Once()
 
-		orders, resultMeta, err := useCase.GetAllOrders(params)
+		orders, resultMeta, err := useCase.GetAllOrders(context.Background(), params)
 
 		assert.Error(t, err)
 		assert.Nil(t, orders)
@@ -201,7 +222,7 @@ func TestOrderUseCase_GetCustomerOrders(t *testing.T) {
 	logger := logrus.New()
 	mockOrderRepo := new(MockOrderRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, logger, "test", mockCache)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logger, "test", mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := []entity.Order{
@@ -210,10 +231,10 @@ func TestOrderUseCase_GetCustomerOrders(t *testing.T) {
 			},
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetByCustomerID", int64(1)).Return(expectedResponse, nil).Once()
+		mockOrderRepo.On("GetByCustomerID", mock.Anything, int64(1)).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		orders, err := useCase.GetCustomerOrders(1)
+		orders, err := useCase.GetCustomerOrders(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, orders)
@@ -223,12 +244,105 @@ func TestOrderUseCase_GetCustomerOrders(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockOrderRepo.On("GetByCustomerID", int64(1)).Return(nil, errors.New("error")).Once()
+		mockOrderRepo.On("GetByCustomerID", mock.Anything, int64(1)).Return(nil, errors.New("error")).Once()
 
-		orders, err := useCase.GetCustomerOrders(1)
+		orders, err := useCase.GetCustomerOrders(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, orders)
 		mockOrderRepo.AssertExpectations(t)
 	})
 }
+
+func TestOrderUseCase_CreateOrderRequiresVerifiedEmail(t *testing.T) {
+	mockOrderRepo := new(MockOrderRepository)
+	mockCustomerRepo := new(MockCustomerRepository)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, mockCustomerRepo, nil, logrus.New(), "test", new(database.MockRedisCacheService), newMockAuditRecorder())
+
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1}, nil)
+
+	order, err := useCase.CreateOrder(context.Background(), 1, &model.CreateOrderRequest{})
+
+	assert.ErrorIs(t, err, constants.ErrEmailNotVerified)
+	assert.Nil(t, order)
+	mockOrderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestOrderUseCase_UpdateOrderStatusInvalidatesLists(t *testing.T) {
+	mockOrderRepo := new(MockOrderRepository)
+	mockCache := new(database.MockRedisCacheService)
+	useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logrus.New(), "test", mockCache, newMockAuditRecorder())
+
+	mockOrderRepo.On("GetByID", mock.Anything, int64(4)).Return(&entity.Order{ID: 4, CustomerID: 7, Status: entity.OrderStatusPending}, nil)
+	mockOrderRepo.On("UpdateStatus", mock.Anything, int64(4), entity.OrderStatusCancelled).Return(nil)
+	mockCache.On("Delete", mock.Anything, "order:4").Return(nil)
+	mockCache.On("Delete", mock.Anything, "orders:customer:7").Return(nil)
+	mockCache.On("Delete", mock.Anything, "order:pending:7:4").Return(nil)
+	mockCache.On("DeletePrefix", mock.Anything, "orders:all:").Return(nil)
+
+	err := useCase.UpdateOrderStatus(context.Background(), model.Actor{}, "4", string(entity.OrderStatusCancelled))
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestOrderUseCase_Export(t *testing.T) {
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	orders := []entity.Order{
+		{
+			ID: 1, CustomerID: 7, Customer: entity.Customer{ID: 7, Email: "rafli@email.com"},
+			Status: entity.OrderStatusPaid, TotalPrice: 250000, CreatedAt: from,
+			Items: []entity.OrderItem{
+				{Quantity: 2, Menu: entity.Menu{Title: "Black Forest"}},
+				{Quantity: 1, Menu: entity.Menu{Title: "Tiramisu"}},
+			},
+		},
+		{ID: 2, CustomerID: 8, Status: entity.OrderStatusCancelled, CreatedAt: from.Add(time.Hour)},
+	}
+
+	t.Run("writes one csv row per order", func(t *testing.T) {
+		mockOrderRepo := new(MockOrderRepository)
+		useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logrus.New(), "test", new(database.MockRedisCacheService), newMockAuditRecorder())
+		mockOrderRepo.On("Each", mock.Anything, from, to).Return(orders, nil)
+
+		var buf bytes.Buffer
+		err := useCase.Export(context.Background(), &model.OrderExportQuery{From: from, To: to, Format: model.OrderExportCSV}, &buf)
+
+		assert.NoError(t, err)
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, []string{"1", "2026-10-01T00:00:00Z", "7", "rafli@email.com", "paid", "", "250000.00", "2x Black Forest; 1x Tiramisu", ""}, records[1])
+		assert.Equal(t, "cancelled", records[2][4])
+	})
+
+	t.Run("writes one json order per line", func(t *testing.T) {
+		mockOrderRepo := new(MockOrderRepository)
+		useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logrus.New(), "test", new(database.MockRedisCacheService), newMockAuditRecorder())
+		mockOrderRepo.On("Each", mock.Anything, from, to).Return(orders, nil)
+
+		var buf bytes.Buffer
+		err := useCase.Export(context.Background(), &model.OrderExportQuery{From: from, To: to, Format: model.OrderExportJSON}, &buf)
+
+		assert.NoError(t, err)
+		decoder := json.NewDecoder(&buf)
+		var first, second model.OrderResponse
+		assert.NoError(t, decoder.Decode(&first))
+		assert.NoError(t, decoder.Decode(&second))
+		assert.Len(t, first.Items, 2)
+		assert.Equal(t, int64(2), second.ID)
+	})
+
+	t.Run("rejects unknown formats and empty ranges", func(t *testing.T) {
+		mockOrderRepo := new(MockOrderRepository)
+		useCase := NewOrderUseCase(mockOrderRepo, nil, nil, nil, logrus.New(), "test", new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		err := useCase.Export(context.Background(), &model.OrderExportQuery{From: from, To: to, Format: "xlsx"}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+
+		err = useCase.Export(context.Background(), &model.OrderExportQuery{From: to, To: from, Format: model.OrderExportCSV}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockOrderRepo.AssertNotCalled(t, "Each", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GatewayPaymentStatus maps a Midtrans transaction status, ok is false for
+// statuses we ignore
+func GatewayPaymentStatus(transactionStatus string) (status constants.PaymentStatus, ok bool) {
+	switch transactionStatus {
+	case "capture", "settlement":
+		return constants.PaymentStatusSuccess, true
+	case "pending":
+		return constants.PaymentStatusPending, true
+	case "expire":
+		return constants.PaymentStatusExpired, true
+	case "cancel", "deny":
+		return constants.PaymentStatusCancelled, true
+	}
+	return "", false
+}
+
+// PaymentReconcileUseCase catches up on payment notifications Midtrans
+// couldn't deliver, while the API was down for instance
+type PaymentReconcileUseCase interface {
+	// Reconcile asks Midtrans for the status of every payment created since
+	// the given time that is still pending and applies it the way the
+	// payment webhook would. A payment that fails is counted and skipped.
+	Reconcile(ctx context.Context, since time.Time) (*model.PaymentReconcileResult, error)
+}
+
+type paymentReconcileUseCase struct {
+	paymentRepository repository.PaymentRepository
+	payments          PaymentUseCase
+	bills             BillUseCase
+	deposits          DepositStatusApplier
+	log               *logrus.Logger
+}
+
+func NewPaymentReconcileUseCase(
+	paymentRepository repository.PaymentRepository,
+	payments PaymentUseCase,
+	bills BillUseCase,
+	deposits DepositStatusApplier,
+	log *logrus.Logger,
+) PaymentReconcileUseCase {
+	return &paymentReconcileUseCase{
+		paymentRepository: paymentRepository,
+		payments:          payments,
+		bills:             bills,
+		deposits:          deposits,
+		log:               log,
+	}
+}
+
+func (u *paymentReconcileUseCase) Reconcile(ctx context.Context, since time.Time) (*model.PaymentReconcileResult, error) {
+	pending, err := u.paymentRepository.GetPendingSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.PaymentReconcileResult{Checked: len(pending)}
+	for _, payment := range pending {
+		transactionStatus, err := u.payments.GetOrderStatus(ctx, payment.ExternalID)
+		if err != nil {
+			u.log.Warnf("Error getting the Midtrans status of payment %s: %v", payment.ExternalID, err)
+			result.Failed++
+			continue
+		}
+
+		applied, err := u.apply(ctx, payment.ExternalID, transactionStatus)
+		switch {
+		case err != nil:
+			u.log.Errorf("Error reconciling payment %s as %s: %v", payment.ExternalID, transactionStatus, err)
+			result.Failed++
+		case applied:
+			u.log.Infof("Reconciled payment %s as %s", payment.ExternalID, transactionStatus)
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+	return result, nil
+}
+
+// apply updates the payment to the Midtrans status, the bill it belongs to
+// then decides whether the order is paid or cancelled. It reports false
+// when the status leaves the payment pending.
+func (u *paymentReconcileUseCase) apply(ctx context.Context, externalID, transactionStatus string) (bool, error) {
+	status, ok := GatewayPaymentStatus(transactionStatus)
+	if !ok || status == constants.PaymentStatusPending {
+		return false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(externalID, constants.PaymentPrefixDeposit+"-"):
+		return true, u.deposits.ApplyDepositStatus(ctx, externalID, status)
+	case strings.HasPrefix(externalID, constants.PaymentPrefixOrder+"-") && transactionStatus == "deny":
+		// a denied card can still be retried on the checkout page, the
+		// webhook leaves those payments pending too
+		return false, nil
+	}
+	return true, u.bills.ApplyGatewayStatus(ctx, externalID, status)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDepositStatusApplier struct {
+	mock.Mock
+}
+
+func (m *MockDepositStatusApplier) ApplyDepositStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error {
+	args := m.Called(ctx, externalID, status)
+	return args.Error(0)
+}
+
+func TestPaymentReconcileUseCase_Reconcile(t *testing.T) {
+	since := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+	t.Run("applies the Midtrans status like the webhook", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		payments := new(MockPaymentUseCase)
+		bills := new(MockBillUseCase)
+		deposits := new(MockDepositStatusApplier)
+		useCase := NewPaymentReconcileUseCase(paymentRepo, payments, bills, deposits, logrus.New())
+
+		paymentRepo.On("GetPendingSince", mock.Anything, since).Return([]entity.Payment{
+			{ID: 1, ExternalID: "ORDER-7-a"},
+			{ID: 2, ExternalID: "ORDER-8-b"},
+			{ID: 3, ExternalID: "SPLIT-9-c"},
+			{ID: 4, ExternalID: "DEPOSIT-4-d"},
+			{ID: 5, ExternalID: "ORDER-10-e"},
+			{ID: 6, ExternalID: "ORDER-11-f"},
+		}, nil)
+		payments.On("GetOrderStatus", mock.Anything, "ORDER-7-a").Return("settlement", nil)
+		payments.On("GetOrderStatus", mock.Anything, "ORDER-8-b").Return("expire", nil)
+		payments.On("GetOrderStatus", mock.Anything, "SPLIT-9-c").Return("settlement", nil)
+		payments.On("GetOrderStatus", mock.Anything, "DEPOSIT-4-d").Return("expire", nil)
+		payments.On("GetOrderStatus", mock.Anything, "ORDER-10-e").Return("pending", nil)
+		payments.On("GetOrderStatus", mock.Anything, "ORDER-11-f").Return("", errors.New("transaction doesn't exist"))
+		bills.On("ApplyGatewayStatus", mock.Anything, "ORDER-7-a", constants.PaymentStatusSuccess).Return(nil)
+		bills.On("ApplyGatewayStatus", mock.Anything, "ORDER-8-b", constants.PaymentStatusExpired).Return(nil)
+		bills.On("ApplyGatewayStatus", mock.Anything, "SPLIT-9-c", constants.PaymentStatusSuccess).Return(nil)
+		deposits.On("ApplyDepositStatus", mock.Anything, "DEPOSIT-4-d", constants.PaymentStatusExpired).Return(nil)
+
+		result, err := useCase.Reconcile(context.Background(), since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.PaymentReconcileResult{Checked: 6, Updated: 4, Unchanged: 1, Failed: 1}, result)
+		bills.AssertExpectations(t)
+		deposits.AssertExpectations(t)
+		bills.AssertNotCalled(t, "ApplyGatewayStatus", mock.Anything, "ORDER-10-e", mock.Anything)
+	})
+
+	t.Run("leaves denied order payments pending", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		payments := new(MockPaymentUseCase)
+		bills := new(MockBillUseCase)
+		useCase := NewPaymentReconcileUseCase(paymentRepo, payments, bills, new(MockDepositStatusApplier), logrus.New())
+
+		paymentRepo.On("GetPendingSince", mock.Anything, since).Return([]entity.Payment{{ID: 1, ExternalID: "ORDER-7-a"}}, nil)
+		payments.On("GetOrderStatus", mock.Anything, "ORDER-7-a").Return("deny", nil)
+
+		result, err := useCase.Reconcile(context.Background(), since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Unchanged)
+		bills.AssertNotCalled(t, "ApplyGatewayStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("counts failed updates and continues", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		payments := new(MockPaymentUseCase)
+		bills := new(MockBillUseCase)
+		useCase := NewPaymentReconcileUseCase(paymentRepo, payments, bills, new(MockDepositStatusApplier), logrus.New())
+
+		paymentRepo.On("GetPendingSince", mock.Anything, since).Return([]entity.Payment{
+			{ID: 1, ExternalID: "SPLIT-9-a"},
+			{ID: 2, ExternalID: "SPLIT-9-b"},
+		}, nil)
+		payments.On("GetOrderStatus", mock.Anything, mock.Anything).Return("cancel", nil)
+		bills.On("ApplyGatewayStatus", mock.Anything, "SPLIT-9-a", constants.PaymentStatusCancelled).Return(errors.New("database down"))
+		bills.On("ApplyGatewayStatus", mock.Anything, "SPLIT-9-b", constants.PaymentStatusCancelled).Return(nil)
+
+		result, err := useCase.Reconcile(context.Background(), since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &model.PaymentReconcileResult{Checked: 2, Updated: 1, Failed: 1}, result)
+	})
+}
@@ -22,10 +22,13 @@ import (
 )
 
 type PaymentUseCase interface {
-	CreatePaymentURL(order *entity.Order) (*model.PaymentResponse, error)
-	GetOrderStatus(orderID string) (string, error)
-	UpdateOrderStatus(id string, status constants.PaymentStatus) error
-	GetPaymentByOrderID(order *entity.Order) (*entity.Payment, error)
+	CreatePaymentURL(ctx context.Context, order *entity.Order) (*model.PaymentResponse, error)
+	// CreateTransaction opens a Midtrans Snap transaction without persisting a payment,
+	// a zero expiry keeps the gateway default
+	CreateTransaction(ctx context.Context, externalID string, amount float64, expiry time.Duration) (*model.PaymentResponse, error)
+	RefundTransaction(ctx context.Context, externalID string, amount float64, reason string) error
+	GetOrderStatus(ctx context.Context, orderID string) (string, error)
+	GetPaymentByOrderID(ctx context.Context, order *entity.Order) (*entity.Payment, error)
 }
 
 type paymentUseCase struct {
@@ -52,40 +55,54 @@ func NewPaymentUseCase(
 	}
 }
 
-func (uc *paymentUseCase) GetPaymentByOrderID(order *entity.Order) (*entity.Payment, error) {
+func (uc *paymentUseCase) GetPaymentByOrderID(ctx context.Context, order *entity.Order) (*entity.Payment, error) {
 	start := time.Now()
 	defer func() {
 		uc.log.Infof("GetPaymentByOrderID took %v", time.Since(start))
 	}()
 
-	// Try to get the payment from the cache first
-	cacheKey := fmt.Sprintf("payment:order:%d", order.ID)
-	var payment entity.Payment
-	if err := uc.cache.Get(context.Background(), cacheKey, &payment); err == nil {
-		uc.log.Info("Payment fetched from cache")
-		return &payment, nil
-	}
+	return database.GetOrLoad(ctx, uc.cache, fmt.Sprintf("payment:order:%d", order.ID), cacheTTL, func(ctx context.Context) (*entity.Payment, error) {
+		return uc.paymentRepository.GetPaymentByOrderID(ctx, order.ID)
+	})
+}
 
-	// If not in cache, get from the database
-	paymentEntity, err := uc.paymentRepository.GetPaymentByOrderID(order.ID)
+func (uc *paymentUseCase) CreatePaymentURL(ctx context.Context, order *entity.Order) (*model.PaymentResponse, error) {
+	externalID := constants.PaymentPrefixOrder + "-" + strconv.Itoa(int(order.ID)) + "-" + uuid.New().String()
+	paymentResponse, err := uc.CreateTransaction(ctx, externalID, order.TotalPrice, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	// Store the payment in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, paymentEntity, 5*time.Minute); err != nil {
-		uc.log.Errorf("Error setting cache for payment by order ID %d: %v", order.ID, err)
+	// insert payment to db
+	payment := &entity.Payment{
+		OrderID:      order.ID,
+		Amount:       order.TotalPrice,
+		Method:       constants.PaymentMethodMidtrans,
+		ExternalID:   externalID,
+		Status:       constants.PaymentStatusPending,
+		PaymentToken: paymentResponse.Token,
+		PaymentURL:   paymentResponse.RedirectURL,
+	}
+	if err := uc.paymentRepository.CreatePayment(ctx, payment); err != nil {
+		return nil, err
 	}
 
-	return paymentEntity, nil
+	return paymentResponse, nil
 }
 
-func (uc *paymentUseCase) CreatePaymentURL(order *entity.Order) (*model.PaymentResponse, error) {
+func (uc *paymentUseCase) CreateTransaction(ctx context.Context, externalID string, amount float64, expiry time.Duration) (*model.PaymentResponse, error) {
 	var req model.CreatePaymentRequest
 
 	req.TransactionDetails = midtrans.TransactionDetails{
-		OrderID:  "ORDER-" + strconv.Itoa(int(order.ID)) + "-" + uuid.New().String(),
-		GrossAmt: int64(order.TotalPrice),
+		OrderID:  externalID,
+		GrossAmt: int64(amount),
+	}
+	if expiry > 0 {
+		req.Expiry = &model.SnapExpiry{
+			StartTime: time.Now().Format(model.SnapExpiryTimeFormat),
+			Unit:      "minute",
+			Duration:  int64(expiry.Minutes()),
+		}
 	}
 
 	headers := utils.GenerateRequestHeader()
@@ -133,41 +150,65 @@ func (uc *paymentUseCase) CreatePaymentURL(order *entity.Order) (*model.PaymentR
 		return nil, err
 	}
 
-	// insert payment to db
-	payment := &entity.Payment{
-		OrderID: order.ID,
-		Amount:  order.TotalPrice,
-		// FIXME workaround for midtrans webhook delay or error, force update to success
-		Status:       constants.PaymentStatusSuccess,
-		PaymentToken: paymentResponse.Token,
-		PaymentURL:   paymentResponse.RedirectURL,
+	return &paymentResponse, nil
+}
+
+func (uc *paymentUseCase) RefundTransaction(ctx context.Context, externalID string, amount float64, reason string) error {
+	reqBody, err := json.Marshal(model.RefundRequest{
+		RefundKey: externalID + "-refund",
+		Amount:    int64(amount),
+		Reason:    reason,
+	})
+	if err != nil {
+		return err
 	}
-	if err := uc.paymentRepository.CreatePayment(payment); err != nil {
-		return nil, err
+
+	endpoint := fmt.Sprintf("%s/v2/%s/refund", uc.endpoint, externalID)
+	httpReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
 	}
 
-	return &paymentResponse, nil
+	for key, value := range utils.GenerateRequestHeader() {
+		httpReq.Header.Set(key, value)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var refundResponse model.RefundResponse
+	if err := json.NewDecoder(resp.Body).Decode(&refundResponse); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK || refundResponse.StatusCode != "200" {
+		return fmt.Errorf("failed to refund transaction %s, status code: %s, message: %s", externalID, refundResponse.StatusCode, refundResponse.StatusMessage)
+	}
+
+	return nil
 }
 
-func (uc *paymentUseCase) GetOrderStatus(orderID string) (string, error) {
+func (uc *paymentUseCase) GetOrderStatus(ctx context.Context, orderID string) (string, error) {
 	start := time.Now()
 	defer func() {
 		uc.log.Infof("GetOrderStatus took %v", time.Since(start))
 	}()
 
-	// Try to get the order status from the cache first
-	cacheKey := fmt.Sprintf("order_status:%s", orderID)
-	var status string
-	if err := uc.cache.Get(context.Background(), cacheKey, &status); err == nil {
-		uc.log.Info("Order status fetched from cache")
-		return status, nil
-	}
+	return database.GetOrLoad(ctx, uc.cache, fmt.Sprintf("order_status:%s", orderID), cacheTTL, func(ctx context.Context) (string, error) {
+		return uc.fetchOrderStatus(ctx, orderID)
+	})
+}
 
-	// If not in cache, get from the external service
+// fetchOrderStatus asks the payment gateway for the transaction status of
+// orderID
+func (uc *paymentUseCase) fetchOrderStatus(ctx context.Context, orderID string) (string, error) {
 	endpoint := fmt.Sprintf("%s/v2/%s/status", uc.endpoint, orderID)
 	headers := utils.GenerateRequestHeader()
 
-	httpReq, err := http.NewRequest("GET", endpoint, nil)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return "", err
 	}
@@ -196,51 +237,5 @@ func (uc *paymentUseCase) GetOrderStatus(orderID string) (string, error) {
 		return "", fmt.Errorf("failed to get order status, status code: %s", orderStatus.StatusCode)
 	}
 
-	// Store the order status in the cache for future requests
-	if err := uc.cache.Set(context.Background(), cacheKey, orderStatus.TransactionStatus, 5*time.Minute); err != nil {
-		uc.log.Errorf("Error setting cache for order status %s: %v", orderID, err)
-	}
-
 	return orderStatus.TransactionStatus, nil
 }
-
-func (uc *paymentUseCase) UpdateOrderStatus(id string, status constants.PaymentStatus) error {
-	var orderID int64
-	var err error
-
-	if uc.env == "development" {
-		orderID, err = uc.paymentRepository.GetPendingPayment()
-		if err != nil {
-			uc.log.Errorf("Error getting pending payment: %v", err)
-			return err
-		}
-	} else {
-		uc.log.Info("Running in production mode, updating payment status")
-		orderID, err = strconv.ParseInt(id, 10, 64)
-		if err != nil {
-			return err
-		}
-	}
-
-	payment := model.ToPaymentEntity(&model.PaymentModel{
-		OrderID: orderID,
-		Status:  status,
-	})
-
-	if err := uc.paymentRepository.UpdatePayment(payment); err != nil {
-		uc.log.Errorf("Error updating order status: %v", err)
-		return err
-	}
-
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("payment:order:%d", orderID)
-	if err := uc.cache.Delete(context.Background(), cacheKey); err != nil {
-		uc.log.Errorf("Error deleting cache for payment by order ID %d: %v", orderID, err)
-	}
-	orderStatusCacheKey := fmt.Sprintf("order_status:%s", id)
-	if err := uc.cache.Delete(context.Background(), orderStatusCacheKey); err != nil {
-		uc.log.Errorf("Error deleting cache for order status %s: %v", id, err)
-	}
-
-	return nil
-}
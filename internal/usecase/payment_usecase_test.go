@@ -1,10 +1,13 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -15,29 +18,55 @@ type MockPaymentRepository struct {
 	mock.Mock
 }
 
-func (m *MockPaymentRepository) CreatePayment(payment *entity.Payment) error {
-	args := m.Called(payment)
+func (m *MockPaymentRepository) CreatePayment(ctx context.Context, payment *entity.Payment) error {
+	args := m.Called(ctx, payment)
 	return args.Error(0)
 }
 
-func (m *MockPaymentRepository) GetPaymentByOrderID(orderID int64) (*entity.Payment, error) {
-	args := m.Called(orderID)
+func (m *MockPaymentRepository) GetPaymentByOrderID(ctx context.Context, orderID int64) (*entity.Payment, error) {
+	args := m.Called(ctx, orderID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepository) UpdatePayment(payment *entity.Payment) error {
-	args := m.Called(payment)
+func (m *MockPaymentRepository) UpdatePayment(ctx context.Context, payment *entity.Payment) error {
+	args := m.Called(ctx, payment)
 	return args.Error(0)
 }
 
-func (m *MockPaymentRepository) GetPendingPayment() (int64, error) {
-	args := m.Called()
+func (m *MockPaymentRepository) GetPendingPayment(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetPaymentsByOrderID(ctx context.Context, orderID int64) ([]entity.Payment, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetPaymentByExternalID(ctx context.Context, externalID string) (*entity.Payment, error) {
+	args := m.Called(ctx, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) UpdatePaymentStatus(ctx context.Context, id int64, status constants.PaymentStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetPendingSince(ctx context.Context, since time.Time) ([]entity.Payment, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Payment), args.Error(1)
+}
+
 func TestPaymentUseCase_GetPaymentByOrderID(t *testing.T) {
 	logger := logrus.New()
 	mockPaymentRepo := new(MockPaymentRepository)
@@ -51,10 +80,10 @@ func TestPaymentUseCase_GetPaymentByOrderID(t *testing.T) {
 		}
 		order := &entity.Order{ID: 1}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockPaymentRepo.On("GetPaymentByOrderID", order.ID).Return(expectedPayment, nil).Once()
+		mockPaymentRepo.On("GetPaymentByOrderID", mock.Anything, order.ID).Return(expectedPayment, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		payment, err := useCase.GetPaymentByOrderID(order)
+		payment, err := useCase.GetPaymentByOrderID(context.Background(), order)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, payment)
@@ -65,9 +94,9 @@ func TestPaymentUseCase_GetPaymentByOrderID(t *testing.T) {
 	t.Run("not found", func(t *testing.T) {
 		order := &entity.Order{ID: 1}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockPaymentRepo.On("GetPaymentByOrderID", order.ID).Return(nil, errors.New("not found")).Once()
+		mockPaymentRepo.On("GetPaymentByOrderID", mock.Anything, order.ID).Return(nil, errors.New("not found")).Once()
 
-		payment, err := useCase.GetPaymentByOrderID(order)
+		payment, err := useCase.GetPaymentByOrderID(context.Background(), order)
 
 		assert.Error(t, err)
 		assert.Nil(t, payment)
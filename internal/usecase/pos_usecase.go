@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	deviceTokenLength = 32
+	// maxPinAttempts failed PIN logins in a row lock the PIN for pinLockout
+	maxPinAttempts = 5
+	pinLockout     = 15 * time.Minute
+)
+
+// PosUseCase handles shared POS terminals, staff register a PIN once and then
+// switch users on a registered terminal without typing their password
+type PosUseCase interface {
+	RegisterDevice(ctx context.Context, actor model.Actor, request *model.RegisterDeviceRequest) (*model.RegisterDeviceResponse, error)
+	GetDevices(ctx context.Context) ([]model.DeviceResponse, error)
+	RevokeDevice(ctx context.Context, actor model.Actor, id int64) error
+	// VerifyDevice reports whether deviceToken belongs to the active device deviceID
+	VerifyDevice(ctx context.Context, deviceID int64, deviceToken string) (bool, error)
+
+	SetPin(ctx context.Context, customerID int64, request *model.SetPinRequest) error
+	// GetStaff lists the employees that can sign in on the terminal
+	GetStaff(ctx context.Context, deviceToken string) ([]model.PosStaffResponse, error)
+	PinLogin(ctx context.Context, deviceToken string, request *model.PinLoginRequest) (*model.TokenResponse, error)
+}
+
+type posUseCase struct {
+	posRepo      repository.PosRepository
+	customerRepo repository.CustomerRepository
+	auth         AuthUseCase
+	log          *logrus.Logger
+	cache        database.RedisCache
+	sessionTTL   time.Duration
+	audit        AuditRecorder
+}
+
+func NewPosUseCase(
+	posRepo repository.PosRepository,
+	customerRepo repository.CustomerRepository,
+	auth AuthUseCase,
+	log *logrus.Logger,
+	cache database.RedisCache,
+	sessionTTL time.Duration,
+	audit AuditRecorder,
+) PosUseCase {
+	return &posUseCase{
+		posRepo:      posRepo,
+		customerRepo: customerRepo,
+		auth:         auth,
+		log:          log,
+		cache:        cache,
+		sessionTTL:   sessionTTL,
+		audit:        audit,
+	}
+}
+
+func deviceCacheKey(id int64) string {
+	return fmt.Sprintf("pos_device:%d", id)
+}
+
+func (u *posUseCase) RegisterDevice(ctx context.Context, actor model.Actor, request *model.RegisterDeviceRequest) (*model.RegisterDeviceResponse, error) {
+	raw, err := utils.GenerateRandomString(deviceTokenLength)
+	if err != nil {
+		u.log.Errorf("Error generating device token: %v", err)
+		return nil, err
+	}
+
+	device := &entity.PosDevice{
+		Name:         request.Name,
+		TokenHash:    utils.GenerateSHA256Hash(raw),
+		RegisteredBy: actor.CustomerID,
+	}
+	if err := u.posRepo.CreateDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	response := model.ToDeviceResponse(device)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityPosDevice, device.ID, nil, response)
+	return &model.RegisterDeviceResponse{
+		DeviceResponse: response,
+		DeviceToken:    raw,
+	}, nil
+}
+
+func (u *posUseCase) GetDevices(ctx context.Context) ([]model.DeviceResponse, error) {
+	devices, err := u.posRepo.GetDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.DeviceResponse, 0, len(devices))
+	for i := range devices {
+		responses = append(responses, model.ToDeviceResponse(&devices[i]))
+	}
+	return responses, nil
+}
+
+func (u *posUseCase) RevokeDevice(ctx context.Context, actor model.Actor, id int64) error {
+	device, err := u.posRepo.GetDeviceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := model.ToDeviceResponse(device)
+	if err := u.posRepo.RevokeDevice(ctx, id); err != nil {
+		return err
+	}
+
+	// sessions on the terminal stop working with the next request
+	if err := u.cache.Delete(ctx, deviceCacheKey(id)); err != nil {
+		u.log.Errorf("Error deleting cache for POS device %d: %v", id, err)
+	}
+
+	now := time.Now()
+	device.RevokedAt = &now
+	u.audit.Record(ctx, actor, constants.AuditActionRevoke, constants.AuditEntityPosDevice, id, before, model.ToDeviceResponse(device))
+	return nil
+}
+
+func (u *posUseCase) VerifyDevice(ctx context.Context, deviceID int64, deviceToken string) (bool, error) {
+	if deviceToken == "" {
+		return false, nil
+	}
+
+	// the cache holds the token hash of an active device, or an empty string
+	// once it was revoked
+	hash, err := database.GetOrLoad(ctx, u.cache, deviceCacheKey(deviceID), cacheTTL, func(ctx context.Context) (string, error) {
+		device, err := u.posRepo.GetDeviceByID(ctx, deviceID)
+		if err != nil {
+			return "", err
+		}
+		if device.RevokedAt != nil {
+			return "", nil
+		}
+		return device.TokenHash, nil
+	})
+	if errors.Is(err, constants.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return hash != "" && hash == utils.GenerateSHA256Hash(deviceToken), nil
+}
+
+func (u *posUseCase) SetPin(ctx context.Context, customerID int64, request *model.SetPinRequest) error {
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer.Role == constants.RoleCustomer {
+		return fmt.Errorf("%w: PINs are only available to staff", constants.ErrInvalidRequest)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
+		return constants.ErrInvalidCredentials
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Pin), bcrypt.DefaultCost)
+	if err != nil {
+		u.log.Errorf("Error hashing PIN: %v", err)
+		return err
+	}
+
+	return u.posRepo.SavePin(ctx, &entity.StaffPin{
+		CustomerID: customerID,
+		PinHash:    string(hash),
+	})
+}
+
+func (u *posUseCase) GetStaff(ctx context.Context, deviceToken string) ([]model.PosStaffResponse, error) {
+	if _, err := u.activeDevice(ctx, deviceToken); err != nil {
+		return nil, err
+	}
+
+	pins, err := u.posRepo.GetPins(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := make([]model.PosStaffResponse, 0, len(pins))
+	for _, pin := range pins {
+		if pin.Customer.ID == 0 || pin.Customer.Role == constants.RoleCustomer {
+			continue
+		}
+		staff = append(staff, model.PosStaffResponse{
+			ID:   pin.Customer.ID,
+			Name: pin.Customer.Name,
+			Role: pin.Customer.Role,
+		})
+	}
+	return staff, nil
+}
+
+func (u *posUseCase) PinLogin(ctx context.Context, deviceToken string, request *model.PinLoginRequest) (*model.TokenResponse, error) {
+	device, err := u.activeDevice(ctx, deviceToken)
+	if err != nil {
+		return nil, err
+	}
+
+	pin, err := u.posRepo.GetPin(ctx, request.EmployeeID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if pin.LockedUntil != nil && time.Now().Before(*pin.LockedUntil) {
+		return nil, constants.ErrPinLocked
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(pin.PinHash), []byte(request.Pin)); err != nil {
+		lockedUntil, err := u.posRepo.RecordPinFailure(ctx, pin.CustomerID, maxPinAttempts, pinLockout)
+		if err != nil {
+			return nil, err
+		}
+		if lockedUntil != nil {
+			u.log.Warnf("PIN of employee %d locked after %d failed attempts on device %d", pin.CustomerID, maxPinAttempts, device.ID)
+			return nil, constants.ErrPinLocked
+		}
+		return nil, constants.ErrInvalidCredentials
+	}
+
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, pin.CustomerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if pin.FailedAttempts > 0 || pin.LockedUntil != nil {
+		if err := u.posRepo.ResetPinFailures(ctx, pin.CustomerID); err != nil {
+			u.log.Errorf("Error resetting failed PIN attempts of employee %d: %v", pin.CustomerID, err)
+		}
+	}
+	if err := u.posRepo.TouchDevice(ctx, device.ID, time.Now()); err != nil {
+		u.log.Errorf("Error updating last seen of POS device %d: %v", device.ID, err)
+	}
+
+	return u.auth.IssueDeviceToken(ctx, employee, device.ID, u.sessionTTL)
+}
+
+// activeDevice looks up the terminal a device token belongs to
+func (u *posUseCase) activeDevice(ctx context.Context, deviceToken string) (*entity.PosDevice, error) {
+	if deviceToken == "" {
+		return nil, constants.ErrInvalidDevice
+	}
+
+	device, err := u.posRepo.GetDeviceByHash(ctx, utils.GenerateSHA256Hash(deviceToken))
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrInvalidDevice
+		}
+		return nil, err
+	}
+	if device.RevokedAt != nil {
+		return nil, constants.ErrInvalidDevice
+	}
+	return device, nil
+}
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockPosRepository struct {
+	mock.Mock
+}
+
+func (m *MockPosRepository) CreateDevice(ctx context.Context, device *entity.PosDevice) error {
+	args := m.Called(ctx, device)
+	return args.Error(0)
+}
+
+func (m *MockPosRepository) GetDevices(ctx context.Context) ([]entity.PosDevice, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.PosDevice), args.Error(1)
+}
+
+func (m *MockPosRepository) GetDeviceByID(ctx context.Context, id int64) (*entity.PosDevice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PosDevice), args.Error(1)
+}
+
+func (m *MockPosRepository) GetDeviceByHash(ctx context.Context, hash string) (*entity.PosDevice, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PosDevice), args.Error(1)
+}
+
+func (m *MockPosRepository) RevokeDevice(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPosRepository) TouchDevice(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockPosRepository) GetPin(ctx context.Context, customerID int64) (*entity.StaffPin, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.StaffPin), args.Error(1)
+}
+
+func (m *MockPosRepository) GetPins(ctx context.Context) ([]entity.StaffPin, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.StaffPin), args.Error(1)
+}
+
+func (m *MockPosRepository) SavePin(ctx context.Context, pin *entity.StaffPin) error {
+	args := m.Called(ctx, pin)
+	return args.Error(0)
+}
+
+func (m *MockPosRepository) RecordPinFailure(ctx context.Context, customerID int64, maxAttempts int, lockFor time.Duration) (*time.Time, error) {
+	args := m.Called(ctx, customerID, maxAttempts, lockFor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockPosRepository) ResetPinFailures(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+func hashPin(t *testing.T, pin string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func TestPosUseCase_PinLogin(t *testing.T) {
+	const deviceToken = "terminal-secret"
+	device := &entity.PosDevice{ID: 3, Name: "Front counter"}
+	deviceHash := utils.GenerateSHA256Hash(deviceToken)
+
+	t.Run("Success", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		customerRepo := new(MockCustomerRepository)
+		authUseCase := new(MockAuthUseCase)
+		useCase := NewPosUseCase(posRepo, customerRepo, authUseCase, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		employee := &entity.Customer{ID: 7, Name: "Amanda", Role: constants.RoleCashier}
+		expected := &model.TokenResponse{AccessToken: "access", TokenType: "Bearer"}
+
+		posRepo.On("GetDeviceByHash", mock.Anything, deviceHash).Return(device, nil)
+		posRepo.On("GetPin", mock.Anything, int64(7)).Return(&entity.StaffPin{CustomerID: 7, PinHash: hashPin(t, "1234"), FailedAttempts: 2}, nil)
+		customerRepo.On("GetEmployeeByID", mock.Anything, int64(7)).Return(employee, nil)
+		posRepo.On("ResetPinFailures", mock.Anything, int64(7)).Return(nil)
+		posRepo.On("TouchDevice", mock.Anything, int64(3), mock.AnythingOfType("time.Time")).Return(nil)
+		authUseCase.On("IssueDeviceToken", mock.Anything, employee, int64(3), 30*time.Minute).Return(expected, nil)
+
+		tokens, err := useCase.PinLogin(context.Background(), deviceToken, &model.PinLoginRequest{EmployeeID: 7, Pin: "1234"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, tokens)
+		posRepo.AssertExpectations(t)
+		authUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Wrong PIN", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		authUseCase := new(MockAuthUseCase)
+		useCase := NewPosUseCase(posRepo, nil, authUseCase, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		posRepo.On("GetDeviceByHash", mock.Anything, deviceHash).Return(device, nil)
+		posRepo.On("GetPin", mock.Anything, int64(7)).Return(&entity.StaffPin{CustomerID: 7, PinHash: hashPin(t, "1234")}, nil)
+		posRepo.On("RecordPinFailure", mock.Anything, int64(7), maxPinAttempts, pinLockout).Return(nil, nil)
+
+		_, err := useCase.PinLogin(context.Background(), deviceToken, &model.PinLoginRequest{EmployeeID: 7, Pin: "9999"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		authUseCase.AssertNotCalled(t, "IssueDeviceToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Last attempt locks the PIN", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		until := time.Now().Add(pinLockout)
+
+		posRepo.On("GetDeviceByHash", mock.Anything, deviceHash).Return(device, nil)
+		posRepo.On("GetPin", mock.Anything, int64(7)).Return(&entity.StaffPin{CustomerID: 7, PinHash: hashPin(t, "1234"), FailedAttempts: 4}, nil)
+		posRepo.On("RecordPinFailure", mock.Anything, int64(7), maxPinAttempts, pinLockout).Return(&until, nil)
+
+		_, err := useCase.PinLogin(context.Background(), deviceToken, &model.PinLoginRequest{EmployeeID: 7, Pin: "9999"})
+
+		assert.ErrorIs(t, err, constants.ErrPinLocked)
+	})
+
+	t.Run("Locked PIN is not checked", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		until := time.Now().Add(time.Minute)
+
+		posRepo.On("GetDeviceByHash", mock.Anything, deviceHash).Return(device, nil)
+		posRepo.On("GetPin", mock.Anything, int64(7)).Return(&entity.StaffPin{CustomerID: 7, PinHash: hashPin(t, "1234"), LockedUntil: &until}, nil)
+
+		_, err := useCase.PinLogin(context.Background(), deviceToken, &model.PinLoginRequest{EmployeeID: 7, Pin: "1234"})
+
+		assert.ErrorIs(t, err, constants.ErrPinLocked)
+		posRepo.AssertNotCalled(t, "RecordPinFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Revoked device", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		revokedAt := time.Now()
+
+		posRepo.On("GetDeviceByHash", mock.Anything, deviceHash).Return(&entity.PosDevice{ID: 3, RevokedAt: &revokedAt}, nil)
+
+		_, err := useCase.PinLogin(context.Background(), deviceToken, &model.PinLoginRequest{EmployeeID: 7, Pin: "1234"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidDevice)
+		posRepo.AssertNotCalled(t, "GetPin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing device token", func(t *testing.T) {
+		useCase := NewPosUseCase(nil, nil, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		_, err := useCase.PinLogin(context.Background(), "", &model.PinLoginRequest{EmployeeID: 7, Pin: "1234"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidDevice)
+	})
+}
+
+func TestPosUseCase_VerifyDevice(t *testing.T) {
+	const deviceToken = "terminal-secret"
+
+	t.Run("Active device", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), cache, 30*time.Minute, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, "pos_device:3", mock.Anything).Return(errors.New("cache miss"))
+		posRepo.On("GetDeviceByID", mock.Anything, int64(3)).Return(&entity.PosDevice{ID: 3, TokenHash: utils.GenerateSHA256Hash(deviceToken)}, nil)
+		cache.On("Set", mock.Anything, "pos_device:3", mock.Anything, mock.Anything).Return(nil)
+
+		ok, err := useCase.VerifyDevice(context.Background(), 3, deviceToken)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = useCase.VerifyDevice(context.Background(), 3, "another-terminal")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Revoked device", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), cache, 30*time.Minute, newMockAuditRecorder())
+
+		revokedAt := time.Now()
+
+		cache.On("Get", mock.Anything, "pos_device:3", mock.Anything).Return(errors.New("cache miss"))
+		posRepo.On("GetDeviceByID", mock.Anything, int64(3)).Return(&entity.PosDevice{ID: 3, TokenHash: utils.GenerateSHA256Hash(deviceToken), RevokedAt: &revokedAt}, nil)
+		cache.On("Set", mock.Anything, "pos_device:3", mock.Anything, mock.Anything).Return(nil)
+
+		ok, err := useCase.VerifyDevice(context.Background(), 3, deviceToken)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestPosUseCase_RevokeDevice(t *testing.T) {
+	posRepo := new(MockPosRepository)
+	cache := new(database.MockRedisCacheService)
+	recorder := newMockAuditRecorder()
+	useCase := NewPosUseCase(posRepo, nil, nil, logrus.New(), cache, 30*time.Minute, recorder)
+
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+
+	posRepo.On("GetDeviceByID", mock.Anything, int64(3)).Return(&entity.PosDevice{ID: 3, Name: "Front counter"}, nil)
+	posRepo.On("RevokeDevice", mock.Anything, int64(3)).Return(nil)
+	cache.On("Delete", mock.Anything, "pos_device:3").Return(nil)
+
+	err := useCase.RevokeDevice(context.Background(), actor, 3)
+
+	assert.NoError(t, err)
+	posRepo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+	recorder.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionRevoke, constants.AuditEntityPosDevice, int64(3),
+		mock.MatchedBy(func(before model.DeviceResponse) bool { return before.RevokedAt == nil }),
+		mock.MatchedBy(func(after model.DeviceResponse) bool { return after.RevokedAt != nil }))
+}
+
+func TestPosUseCase_SetPin(t *testing.T) {
+	password, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewPosUseCase(posRepo, customerRepo, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(7)).Return(&entity.Customer{ID: 7, Role: constants.RoleKitchen, Password: string(password)}, nil)
+		posRepo.On("SavePin", mock.Anything, mock.MatchedBy(func(pin *entity.StaffPin) bool {
+			return pin.CustomerID == 7 && bcrypt.CompareHashAndPassword([]byte(pin.PinHash), []byte("4321")) == nil
+		})).Return(nil)
+
+		assert.NoError(t, useCase.SetPin(context.Background(), 7, &model.SetPinRequest{Pin: "4321", Password: "secret123"}))
+		posRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		posRepo := new(MockPosRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewPosUseCase(posRepo, customerRepo, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(7)).Return(&entity.Customer{ID: 7, Role: constants.RoleKitchen, Password: string(password)}, nil)
+
+		err := useCase.SetPin(context.Background(), 7, &model.SetPinRequest{Pin: "4321", Password: "guess"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		posRepo.AssertNotCalled(t, "SavePin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Customers can't set a PIN", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewPosUseCase(nil, customerRepo, nil, logrus.New(), nil, 30*time.Minute, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(8)).Return(&entity.Customer{ID: 8, Role: constants.RoleCustomer, Password: string(password)}, nil)
+
+		err := useCase.SetPin(context.Background(), 8, &model.SetPinRequest{Pin: "4321", Password: "secret123"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+	})
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/ratelimit"
+	"cakestore/internal/repository"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RateLimitUseCase interface {
+	// GetStatus reports the counters of a client in every rate limit group
+	GetStatus(ctx context.Context, query *model.RateLimitClientQuery) (*model.RateLimitStatusResponse, error)
+	// Reset forgets the requests of a client, so a customer locked out by a
+	// misbehaving app or a busy terminal can continue right away
+	Reset(ctx context.Context, actor model.Actor, query *model.RateLimitClientQuery) (*model.RateLimitStatusResponse, error)
+}
+
+type rateLimitUseCase struct {
+	limiter      *ratelimit.Limiter
+	customerRepo repository.CustomerRepository
+	log          *logrus.Logger
+	audit        AuditRecorder
+}
+
+func NewRateLimitUseCase(limiter *ratelimit.Limiter, customerRepo repository.CustomerRepository, log *logrus.Logger, audit AuditRecorder) RateLimitUseCase {
+	return &rateLimitUseCase{
+		limiter:      limiter,
+		customerRepo: customerRepo,
+		log:          log,
+		audit:        audit,
+	}
+}
+
+func (u *rateLimitUseCase) GetStatus(ctx context.Context, query *model.RateLimitClientQuery) (*model.RateLimitStatusResponse, error) {
+	client, role, err := u.client(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return u.status(ctx, client, role)
+}
+
+func (u *rateLimitUseCase) Reset(ctx context.Context, actor model.Actor, query *model.RateLimitClientQuery) (*model.RateLimitStatusResponse, error) {
+	client, role, err := u.client(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if query.Group != "" && !u.limiter.HasGroup(query.Group) {
+		return nil, fmt.Errorf("%w: unknown rate limit group %s", constants.ErrInvalidRequest, query.Group)
+	}
+
+	before, err := u.status(ctx, client, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.limiter.Reset(ctx, client, role, query.Group); err != nil {
+		u.log.Errorf("Error resetting rate limits of %s: %v", client, err)
+		return nil, err
+	}
+	after, err := u.status(ctx, client, role)
+	if err != nil {
+		return nil, err
+	}
+
+	u.audit.Record(ctx, actor, constants.AuditActionReset, constants.AuditEntityRateLimit, client, before, after)
+	return after, nil
+}
+
+// client resolves the counter key of the client picked by query and the
+// role whose limits apply to it
+func (u *rateLimitUseCase) client(ctx context.Context, query *model.RateLimitClientQuery) (string, string, error) {
+	switch {
+	case query.CustomerID != 0 && query.IP != "":
+		return "", "", fmt.Errorf("%w: pass either customer_id or ip", constants.ErrInvalidRequest)
+	case query.CustomerID != 0:
+		customer, err := u.customerRepo.GetByID(ctx, query.CustomerID)
+		if err != nil {
+			return "", "", constants.ErrNotFound
+		}
+		return ratelimit.UserClient(customer.ID), customer.Role, nil
+	case query.IP != "":
+		if net.ParseIP(query.IP) == nil {
+			return "", "", fmt.Errorf("%w: invalid ip address", constants.ErrInvalidRequest)
+		}
+		return query.IP, "", nil
+	default:
+		return "", "", fmt.Errorf("%w: customer_id or ip is required", constants.ErrInvalidRequest)
+	}
+}
+
+func (u *rateLimitUseCase) status(ctx context.Context, client, role string) (*model.RateLimitStatusResponse, error) {
+	statuses, err := u.limiter.Status(ctx, client, role)
+	if err != nil {
+		u.log.Errorf("Error getting rate limits of %s: %v", client, err)
+		return nil, err
+	}
+
+	response := &model.RateLimitStatusResponse{Client: client, Role: role, Limits: []model.RateLimitGroupStatus{}}
+	for _, status := range statuses {
+		response.Limits = append(response.Limits, model.RateLimitGroupStatus{
+			Group:         status.Group,
+			Algorithm:     string(status.Rule.Algorithm),
+			Limit:         status.Limit,
+			WindowSeconds: wholeSeconds(status.Rule.Window),
+			Remaining:     status.Remaining,
+			ResetSeconds:  wholeSeconds(status.Reset),
+			Limited:       !status.Allowed,
+		})
+	}
+	return response, nil
+}
+
+// wholeSeconds rounds d up to whole seconds
+func wholeSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
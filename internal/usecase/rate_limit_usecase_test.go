@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/ratelimit"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestLimiter limits orders to 2 requests an hour, 20 for staff
+func newTestLimiter(t *testing.T) *ratelimit.Limiter {
+	store := ratelimit.NewMemoryStore(time.Minute)
+	t.Cleanup(func() { store.Close() })
+
+	policy := ratelimit.NewPolicy(ratelimit.FixedWindow)
+	policy.Set(constants.RateLimitOrders, "", ratelimit.Rule{Max: 2, Window: time.Hour})
+	policy.SetTiers(func(role string) string {
+		if role != constants.RoleCustomer {
+			return constants.RateLimitTierStaff
+		}
+		return role
+	})
+	policy.Scale(constants.RateLimitTierStaff, 10, constants.RateLimitOrders)
+	return ratelimit.NewLimiter(store, policy)
+}
+
+func TestRateLimitUseCase_GetStatus(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("applies the staff tier to staff", func(t *testing.T) {
+		limiter := newTestLimiter(t)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewRateLimitUseCase(limiter, customerRepo, logger, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(7)).Return(&entity.Customer{ID: 7, Role: constants.RoleCashier}, nil)
+		_, err := limiter.Allow(context.Background(), constants.RateLimitOrders, constants.RoleCashier, ratelimit.UserClient(7))
+		assert.NoError(t, err)
+
+		status, err := useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{CustomerID: 7})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user:7", status.Client)
+		assert.Equal(t, constants.RoleCashier, status.Role)
+		assert.Len(t, status.Limits, 1)
+		assert.Equal(t, 20, status.Limits[0].Limit)
+		assert.Equal(t, 19, status.Limits[0].Remaining)
+		assert.False(t, status.Limits[0].Limited)
+	})
+
+	t.Run("reports limited customers", func(t *testing.T) {
+		limiter := newTestLimiter(t)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewRateLimitUseCase(limiter, customerRepo, logger, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(3)).Return(&entity.Customer{ID: 3, Role: constants.RoleCustomer}, nil)
+		for i := 0; i < 3; i++ {
+			limiter.Allow(context.Background(), constants.RateLimitOrders, constants.RoleCustomer, ratelimit.UserClient(3))
+		}
+
+		status, err := useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{CustomerID: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, status.Limits[0].Limit)
+		assert.Equal(t, 0, status.Limits[0].Remaining)
+		assert.True(t, status.Limits[0].Limited)
+	})
+
+	t.Run("rejects invalid clients", func(t *testing.T) {
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewRateLimitUseCase(newTestLimiter(t), customerRepo, logger, newMockAuditRecorder())
+		customerRepo.On("GetByID", mock.Anything, int64(404)).Return(nil, errors.New("customer not found"))
+
+		_, err := useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{})
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+
+		_, err = useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{IP: "not-an-ip"})
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+
+		_, err = useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{CustomerID: 1, IP: "10.0.0.1"})
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+
+		_, err = useCase.GetStatus(context.Background(), &model.RateLimitClientQuery{CustomerID: 404})
+		assert.ErrorIs(t, err, constants.ErrNotFound)
+	})
+}
+
+func TestRateLimitUseCase_Reset(t *testing.T) {
+	logger := logrus.New()
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+
+	t.Run("clears the counters of an IP address and audits it", func(t *testing.T) {
+		limiter := newTestLimiter(t)
+		audit := newMockAuditRecorder()
+		useCase := NewRateLimitUseCase(limiter, new(MockCustomerRepository), logger, audit)
+		for i := 0; i < 3; i++ {
+			limiter.Allow(context.Background(), constants.RateLimitOrders, "", "10.0.0.9")
+		}
+
+		status, err := useCase.Reset(context.Background(), actor, &model.RateLimitClientQuery{IP: "10.0.0.9"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, status.Limits[0].Remaining)
+		assert.False(t, status.Limits[0].Limited)
+		audit.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionReset, constants.AuditEntityRateLimit, "10.0.0.9", mock.Anything, status)
+
+		result, err := limiter.Allow(context.Background(), constants.RateLimitOrders, "", "10.0.0.9")
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("rejects unknown groups", func(t *testing.T) {
+		audit := newMockAuditRecorder()
+		useCase := NewRateLimitUseCase(newTestLimiter(t), new(MockCustomerRepository), logger, audit)
+
+		_, err := useCase.Reset(context.Background(), actor, &model.RateLimitClientQuery{IP: "10.0.0.9", Group: "nope"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
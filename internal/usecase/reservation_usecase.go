@@ -1,32 +1,62 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"cakestore/internal/notification"
 	"cakestore/internal/repository"
 	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// DepositStatusApplier updates a deposit from the payment webhook, confirming
+// the reservation once paid and cancelling it when the payment expires
+type DepositStatusApplier interface {
+	ApplyDepositStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error
+}
+
 type ReservationUseCase interface {
-	Create(customerID uint, request *model.CreateReservationRequest) (*model.ReservationResponse, error)
-	GetByID(id uint) (*model.ReservationResponse, error)
-	GetAll(params *model.ReservationQueryParams) (*model.PaginationResponse[[]model.ReservationResponse], error)
-	AdminGetAllCustomerReservations(params *model.PaginationQuery) (*model.PaginationResponse[[]model.ReservationResponse], error)
-	Update(id uint, request *model.UpdateReservationRequest) (*model.ReservationResponse, error)
-	Delete(id uint) error
+	DepositStatusApplier
+	Create(ctx context.Context, customerID uint, request *model.CreateReservationRequest) (*model.ReservationResponse, error)
+	// GetByID and GetAll only return reservations the actor owns, unless the actor is staff
+	GetByID(ctx context.Context, actor model.Actor, id uint) (*model.ReservationResponse, error)
+	GetAll(ctx context.Context, actor model.Actor, params *model.ReservationQueryParams) (*model.PaginationResponse[[]model.ReservationResponse], error)
+	AdminGetAllCustomerReservations(ctx context.Context, params *model.PaginationQuery) (*model.PaginationResponse[[]model.ReservationResponse], error)
+	Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateReservationRequest) (*model.ReservationResponse, error)
+	Delete(ctx context.Context, actor model.Actor, id uint) error
+	// Cancel and Modify let customers manage their own bookings until the
+	// change cutoff, staff are not bound by the cutoff
+	Cancel(ctx context.Context, actor model.Actor, id uint) (*model.ReservationResponse, error)
+	Modify(ctx context.Context, actor model.Actor, id uint, request *model.ModifyReservationRequest) (*model.ReservationResponse, error)
+
+	GetDeposit(ctx context.Context, actor model.Actor, id uint) (*model.DepositResponse, error)
+	// ExpireDeposits cancels reservations whose deposit was not paid in time
+	ExpireDeposits(ctx context.Context) (int, error)
+	MarkNoShow(ctx context.Context, actor model.Actor, id uint, request *model.NoShowRequest) (*model.ReservationResponse, error)
+	// ApplyDeposit credits a paid deposit to the bill of the party's order
+	ApplyDeposit(ctx context.Context, actor model.Actor, id uint, request *model.ApplyDepositRequest) (*model.TenderResponse, error)
 }
 
 type reservationUseCase struct {
 	repo            repository.ReservationRepository
 	tableRepository repository.TableRepository
+	depositRepo     repository.DepositRepository
+	customerRepo    repository.CustomerRepository
+	paymentUseCase  PaymentUseCase
+	billUseCase     BillUseCase
+	depositExpiry   time.Duration
+	changeCutoff    time.Duration
+	notifier        NotificationUseCase
 	logger          *logrus.Logger
 	cache           database.RedisCache
+	audit           AuditRecorder
 }
 
 func NewReservationUseCase(
@@ -34,68 +64,48 @@ func NewReservationUseCase(
 	logger *logrus.Logger,
 	tableRepository repository.TableRepository,
 	cache database.RedisCache,
+	depositRepo repository.DepositRepository,
+	customerRepo repository.CustomerRepository,
+	paymentUseCase PaymentUseCase,
+	billUseCase BillUseCase,
+	depositExpiry time.Duration,
+	changeCutoff time.Duration,
+	notifier NotificationUseCase,
+	audit AuditRecorder,
 ) ReservationUseCase {
 	return &reservationUseCase{
 		repo:            repo,
 		logger:          logger,
 		tableRepository: tableRepository,
+		depositRepo:     depositRepo,
+		customerRepo:    customerRepo,
+		paymentUseCase:  paymentUseCase,
+		billUseCase:     billUseCase,
+		depositExpiry:   depositExpiry,
+		changeCutoff:    changeCutoff,
+		notifier:        notifier,
 		cache:           cache,
+		audit:           audit,
 	}
 }
 
-func (u *reservationUseCase) AdminGetAllCustomerReservations(params *model.PaginationQuery) (*model.PaginationResponse[[]model.ReservationResponse], error) {
+func (u *reservationUseCase) AdminGetAllCustomerReservations(ctx context.Context, params *model.PaginationQuery) (*model.PaginationResponse[[]model.ReservationResponse], error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("AdminGetAllCustomerReservations took %v", time.Since(start))
 	}()
 
-	// Try to get the reservations from the cache first
-	cacheKey := fmt.Sprintf("reservations:admin:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData model.PaginationResponse[[]model.ReservationResponse]
-	if err := u.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		u.logger.Info("Admin reservations fetched from cache")
-		return &cachedData, nil
-	}
-
-	// If not in cache, get from the database
-	result, err := u.repo.AdminGetAllCustomerReservations(params)
-	if err != nil {
-		return nil, err
-	}
-
-	responses := make([]model.ReservationResponse, len(result.Data))
-	for i, reservation := range result.Data {
-		responses[i] = model.ReservationResponse{
-			ID:           reservation.ID,
-			CustomerID:   reservation.CustomerID,
-			Customer:     *model.ToCustomerResponse(&reservation.Customer),
-			TableNumber:  reservation.TableNumber,
-			GuestCount:   reservation.GuestCount,
-			ReserveDate:  reservation.ReserveDate,
-			Status:       string(reservation.Status),
-			SpecialNotes: reservation.SpecialNotes,
-			CreatedAt:    reservation.CreatedAt,
-			UpdatedAt:    reservation.UpdatedAt,
+	cacheKey := fmt.Sprintf("%spage:%d:limit:%d", adminReservationListCachePrefix, params.Page, params.Limit)
+	return database.GetOrLoad(ctx, u.cache, cacheKey, cacheTTL, func(ctx context.Context) (*model.PaginationResponse[[]model.ReservationResponse], error) {
+		result, err := u.repo.AdminGetAllCustomerReservations(ctx, params)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	paginatedResponse := &model.PaginationResponse[[]model.ReservationResponse]{
-		Data:       responses,
-		Total:      result.Total,
-		Page:       result.Page,
-		PageSize:   result.PageSize,
-		TotalPages: result.TotalPages,
-	}
-
-	// Store the reservations in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, paginatedResponse, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for admin reservations: %v", err)
-	}
-
-	return paginatedResponse, nil
+		return toReservationPage(result), nil
+	})
 }
 
-func (u *reservationUseCase) Create(customerID uint, request *model.CreateReservationRequest) (*model.ReservationResponse, error) {
+func (u *reservationUseCase) Create(ctx context.Context, customerID uint, request *model.CreateReservationRequest) (*model.ReservationResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
@@ -106,19 +116,19 @@ func (u *reservationUseCase) Create(customerID uint, request *model.CreateReserv
 	// Only get table and set relation if TableID is provided (not zero)
 	if request.TableID != 0 {
 		var err error
-		table, err = u.tableRepository.GetByID(request.TableID)
+		table, err = u.tableRepository.GetByID(ctx, request.TableID)
 		if err != nil {
 			u.logger.Errorf("Error getting table: %v", err)
 			return nil, err
 		}
 
 		// Check table availability
-		isAvailable, err := u.repo.CheckTableAvailability(request.TableID, request.ReserveDate)
+		isAvailable, err := u.repo.CheckTableAvailability(ctx, request.TableID, request.ReserveDate, 0)
 		if err != nil {
 			return nil, err
 		}
 		if !isAvailable {
-			return nil, errors.New("table is not available for the selected date")
+			return nil, constants.ErrTableUnavailable
 		}
 		tableNumber = table.TableNumber
 	}
@@ -138,16 +148,30 @@ func (u *reservationUseCase) Create(customerID uint, request *model.CreateReserv
 		reservation.TableNumber = tableNumber
 	}
 
-	if err := u.repo.Create(reservation); err != nil {
+	if err := u.repo.Create(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	deposit, err := u.requestDeposit(ctx, reservation)
+	if err != nil {
+		// without a payable deposit the booking cannot be held
+		if deleteErr := u.repo.Delete(ctx, reservation.ID); deleteErr != nil {
+			u.logger.Errorf("Error removing reservation %d after deposit failure: %v", reservation.ID, deleteErr)
+		}
 		return nil, err
 	}
 
 	// Get the created reservation with customer details
-	createdReservation, err := u.repo.GetByID(reservation.ID)
+	createdReservation, err := u.repo.GetByID(ctx, reservation.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	var depositResponse *model.DepositResponse
+	if deposit != nil {
+		depositResponse = model.ToDepositResponse(deposit)
+	}
+
 	return &model.ReservationResponse{
 		ID:           createdReservation.ID,
 		CustomerID:   createdReservation.CustomerID,
@@ -157,108 +181,65 @@ func (u *reservationUseCase) Create(customerID uint, request *model.CreateReserv
 		ReserveDate:  createdReservation.ReserveDate,
 		Status:       string(createdReservation.Status),
 		SpecialNotes: createdReservation.SpecialNotes,
+		Deposit:      depositResponse,
 		CreatedAt:    createdReservation.CreatedAt,
 		UpdatedAt:    createdReservation.UpdatedAt,
 	}, nil
 }
 
-func (u *reservationUseCase) GetByID(id uint) (*model.ReservationResponse, error) {
+func (u *reservationUseCase) GetByID(ctx context.Context, actor model.Actor, id uint) (*model.ReservationResponse, error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("GetByID took %v", time.Since(start))
 	}()
 
-	// Try to get the reservation from the cache first
-	cacheKey := fmt.Sprintf("reservation:%d", id)
-	var reservation model.ReservationResponse
-	if err := u.cache.Get(context.Background(), cacheKey, &reservation); err == nil {
-		u.logger.Info("Reservation fetched from cache")
-		return &reservation, nil
-	}
-
-	// If not in cache, get from the database
-	reservationEntity, err := u.repo.GetByID(id)
+	reservation, err := database.GetOrLoad(ctx, u.cache, fmt.Sprintf("reservation:%d", id), cacheTTL, func(ctx context.Context) (*model.ReservationResponse, error) {
+		reservationEntity, err := u.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return model.ToReservationResponse(reservationEntity), nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Store the reservation in the cache for future requests
-	reservationModel := &model.ReservationResponse{
-		ID:           reservationEntity.ID,
-		CustomerID:   reservationEntity.CustomerID,
-		Customer:     *model.ToCustomerResponse(&reservationEntity.Customer),
-		TableNumber:  reservationEntity.TableNumber,
-		GuestCount:   reservationEntity.GuestCount,
-		ReserveDate:  reservationEntity.ReserveDate,
-		Status:       string(reservationEntity.Status),
-		SpecialNotes: reservationEntity.SpecialNotes,
-		CreatedAt:    reservationEntity.CreatedAt,
-		UpdatedAt:    reservationEntity.UpdatedAt,
-	}
-	if err := u.cache.Set(context.Background(), cacheKey, reservationModel, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for reservation ID %d: %v", id, err)
+	// other customers' bookings are reported as missing rather than forbidden
+	if !actor.Owns(int64(reservation.CustomerID)) {
+		return nil, constants.ErrNotFound
 	}
 
-	return reservationModel, nil
+	return reservation, nil
 }
 
-func (u *reservationUseCase) GetAll(params *model.ReservationQueryParams) (*model.PaginationResponse[[]model.ReservationResponse], error) {
+func (u *reservationUseCase) GetAll(ctx context.Context, actor model.Actor, params *model.ReservationQueryParams) (*model.PaginationResponse[[]model.ReservationResponse], error) {
 	start := time.Now()
 	defer func() {
 		u.logger.Infof("GetAll took %v", time.Since(start))
 	}()
 
-	// Try to get the reservations from the cache first
-	cacheKey := fmt.Sprintf("reservations:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData model.PaginationResponse[[]model.ReservationResponse]
-	if err := u.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		u.logger.Info("Reservations fetched from cache")
-		return &cachedData, nil
-	}
-
-	// If not in cache, get from the database
-	result, err := u.repo.GetAll(params)
-	if err != nil {
-		return nil, err
+	// Customers only ever see their own reservations
+	if !actor.IsStaff() {
+		params.CustomerID = uint(actor.CustomerID)
 	}
 
-	responses := make([]model.ReservationResponse, len(result.Data))
-	for i, reservation := range result.Data {
-		responses[i] = model.ReservationResponse{
-			ID:           reservation.ID,
-			CustomerID:   reservation.CustomerID,
-			Customer:     *model.ToCustomerResponse(&reservation.Customer),
-			TableNumber:  reservation.TableNumber,
-			GuestCount:   reservation.GuestCount,
-			ReserveDate:  reservation.ReserveDate,
-			Status:       string(reservation.Status),
-			SpecialNotes: reservation.SpecialNotes,
-			CreatedAt:    reservation.CreatedAt,
-			UpdatedAt:    reservation.UpdatedAt,
+	// Every filter is part of the key
+	cacheKey := fmt.Sprintf("%scustomer:%d:status:%s:date:%s:table:%d:page:%d:limit:%d", reservationListCachePrefix,
+		params.CustomerID, params.Status, params.ReserveDate.Format("2006-01-02"), params.TableNumber, params.Page, params.Limit)
+	return database.GetOrLoad(ctx, u.cache, cacheKey, cacheTTL, func(ctx context.Context) (*model.PaginationResponse[[]model.ReservationResponse], error) {
+		result, err := u.repo.GetAll(ctx, params)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	paginatedResponse := &model.PaginationResponse[[]model.ReservationResponse]{
-		Data:       responses,
-		Total:      result.Total,
-		Page:       result.Page,
-		PageSize:   result.PageSize,
-		TotalPages: result.TotalPages,
-	}
-
-	// Store the reservations in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, paginatedResponse, 5*time.Minute); err != nil {
-		u.logger.Errorf("Error setting cache for all reservations: %v", err)
-	}
-
-	return paginatedResponse, nil
+		return toReservationPage(result), nil
+	})
 }
 
-func (u *reservationUseCase) Update(id uint, request *model.UpdateReservationRequest) (*model.ReservationResponse, error) {
-	existing, err := u.repo.GetByID(id)
+func (u *reservationUseCase) Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateReservationRequest) (*model.ReservationResponse, error) {
+	existing, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := model.ToReservationResponse(existing)
 
 	// Check table availability if table number or date is being updated
 	if (request.TableNumber != 0 && request.TableNumber != existing.TableNumber) ||
@@ -272,12 +253,21 @@ func (u *reservationUseCase) Update(id uint, request *model.UpdateReservationReq
 			checkTable = existing.TableNumber
 		}
 
-		available, err := u.repo.CheckTableAvailability(uint(checkTable), checkDate)
+		available, err := u.repo.CheckTableAvailability(ctx, uint(checkTable), checkDate, id)
 		if err != nil {
 			return nil, err
 		}
 		if !available {
-			return nil, errors.New("table is not available for the selected date")
+			return nil, constants.ErrTableUnavailable
+		}
+	}
+
+	if request.Status != "" && !canTransitionReservation(existing.Status, entity.ReservationStatus(request.Status)) {
+		return nil, fmt.Errorf("%w: %s to %s", constants.ErrInvalidReservationStatus, existing.Status, request.Status)
+	}
+	if request.Status != "" && request.Status != string(existing.Status) {
+		if err := u.checkStatusUpdate(ctx, id, entity.ReservationStatus(request.Status)); err != nil {
+			return nil, err
 		}
 	}
 
@@ -290,30 +280,36 @@ func (u *reservationUseCase) Update(id uint, request *model.UpdateReservationReq
 	if !request.ReserveDate.IsZero() {
 		existing.ReserveDate = request.ReserveDate
 	}
+	confirmed := false
 	if request.Status != "" {
+		confirmed = existing.Status != entity.ReservationStatusConfirmed && request.Status == string(entity.ReservationStatusConfirmed)
 		existing.Status = entity.ReservationStatus(request.Status)
 	}
 	if request.SpecialNotes != "" {
 		existing.SpecialNotes = request.SpecialNotes
 	}
 
-	if err := u.repo.Update(existing); err != nil {
+	if err := u.repo.Update(ctx, existing); err != nil {
 		return nil, err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityReservation, id, before, model.ToReservationResponse(existing))
+	if confirmed {
+		u.notifyConfirmed(ctx, existing)
+	}
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("reservation:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.logger.Errorf("Error deleting cache for reservation ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "reservations:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, reservationListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for all reservations: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "reservations:admin:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, adminReservationListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for admin reservations: %v", err)
 	}
 
-	updated, err := u.repo.GetByID(id)
+	updated, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -332,22 +328,582 @@ func (u *reservationUseCase) Update(id uint, request *model.UpdateReservationReq
 	}, nil
 }
 
-func (u *reservationUseCase) Delete(id uint) error {
-	if err := u.repo.Delete(id); err != nil {
+// checkStatusUpdate rejects the statuses an update must not set directly.
+// Cancelling and no-shows settle the deposit, so they go through Cancel and
+// MarkNoShow, and a reservation is only confirmed once its deposit is paid.
+func (u *reservationUseCase) checkStatusUpdate(ctx context.Context, id uint, status entity.ReservationStatus) error {
+	switch status {
+	case entity.ReservationStatusCancelled, entity.ReservationStatusNoShow:
+		return fmt.Errorf("%w: %s is set through its own endpoint", constants.ErrInvalidReservationStatus, status)
+	case entity.ReservationStatusConfirmed:
+		deposit, err := u.depositRepo.GetDepositByReservationID(ctx, id)
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if deposit.Status == entity.DepositStatusPending {
+			return constants.ErrDepositNotPaid
+		}
+	}
+	return nil
+}
+
+func (u *reservationUseCase) Delete(ctx context.Context, actor model.Actor, id uint) error {
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, id); err != nil {
 		return err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityReservation, id, model.ToReservationResponse(existing), nil)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("reservation:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.logger.Errorf("Error deleting cache for reservation ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "reservations:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, reservationListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for all reservations: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "reservations:admin:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, adminReservationListCachePrefix); err != nil {
 		u.logger.Errorf("Error deleting cache for admin reservations: %v", err)
 	}
 
 	return nil
 }
+
+// requestDeposit opens a Midtrans payment when a deposit rule applies to the reservation
+func (u *reservationUseCase) requestDeposit(ctx context.Context, reservation *entity.Reservation) (*entity.ReservationDeposit, error) {
+	rules, err := u.depositRepo.GetActiveRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, amount := matchDepositRule(rules, reservation.GuestCount, reservation.ReserveDate)
+	if rule == nil {
+		return nil, nil
+	}
+
+	externalID := fmt.Sprintf("%s-%d-%s", constants.PaymentPrefixDeposit, reservation.ID, uuid.New().String())
+	transaction, err := u.paymentUseCase.CreateTransaction(ctx, externalID, amount, u.depositExpiry)
+	if err != nil {
+		u.logger.Errorf("Error creating deposit payment for reservation %d: %v", reservation.ID, err)
+		return nil, err
+	}
+
+	deposit := &entity.ReservationDeposit{
+		ReservationID: reservation.ID,
+		RuleID:        rule.ID,
+		Amount:        amount,
+		Status:        entity.DepositStatusPending,
+		ExternalID:    externalID,
+		PaymentToken:  transaction.Token,
+		PaymentURL:    transaction.RedirectURL,
+		ExpiresAt:     time.Now().Add(u.depositExpiry),
+	}
+	if err := u.depositRepo.CreateDeposit(ctx, deposit); err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+func (u *reservationUseCase) GetDeposit(ctx context.Context, actor model.Actor, id uint) (*model.DepositResponse, error) {
+	reservation, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Owns(int64(reservation.CustomerID)) {
+		return nil, constants.ErrNotFound
+	}
+
+	deposit, err := u.depositRepo.GetDepositByReservationID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDepositResponse(deposit), nil
+}
+
+func (u *reservationUseCase) ApplyDepositStatus(ctx context.Context, externalID string, status constants.PaymentStatus) error {
+	deposit, err := u.depositRepo.GetDepositByExternalID(ctx, externalID)
+	if err != nil {
+		return err
+	}
+	// money captured after the payment window closed is given back, the
+	// reservation was already cancelled when the deposit expired
+	if deposit.Status == entity.DepositStatusExpired && status == constants.PaymentStatusSuccess {
+		return u.refundLateDeposit(ctx, deposit)
+	}
+	// late notifications must not reopen a settled deposit
+	if deposit.Status != entity.DepositStatusPending {
+		u.logger.Infof("Ignoring %s notification for deposit %d in status %s", status, deposit.ID, deposit.Status)
+		return nil
+	}
+
+	switch status {
+	case constants.PaymentStatusSuccess:
+		now := time.Now()
+		deposit.Status = entity.DepositStatusPaid
+		deposit.PaidAt = &now
+		paid, err := u.depositRepo.TransitionDeposit(ctx, deposit, entity.DepositStatusPending)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			// the deposit expired or was released while the payment came in
+			current, err := u.depositRepo.GetDepositByExternalID(ctx, externalID)
+			if err != nil {
+				return err
+			}
+			if current.Status == entity.DepositStatusExpired {
+				return u.refundLateDeposit(ctx, current)
+			}
+			u.logger.Infof("Ignoring %s notification for deposit %d in status %s", status, current.ID, current.Status)
+			return nil
+		}
+		return u.setStatus(ctx, deposit.ReservationID, entity.ReservationStatusConfirmed)
+	case constants.PaymentStatusExpired, constants.PaymentStatusCancelled, constants.PaymentStatusFailed:
+		_, err := u.expireDeposit(ctx, deposit)
+		return err
+	}
+	return nil
+}
+
+func (u *reservationUseCase) refundLateDeposit(ctx context.Context, deposit *entity.ReservationDeposit) error {
+	reservation, err := u.repo.GetByID(ctx, deposit.ReservationID)
+	if err != nil {
+		return err
+	}
+
+	// the deposit is claimed first so a repeated notification can't refund it twice
+	previous := *deposit
+	before := model.ToDepositResponse(deposit)
+	now := time.Now()
+	deposit.Status = entity.DepositStatusRefunded
+	deposit.PaidAt = &now
+	refunded, err := u.depositRepo.TransitionDeposit(ctx, deposit, entity.DepositStatusExpired)
+	if err != nil {
+		return err
+	}
+	if !refunded {
+		u.logger.Infof("Deposit %d of reservation %d was already refunded", deposit.ID, deposit.ReservationID)
+		return nil
+	}
+
+	// an error is returned so the webhook or the reconciler tries again
+	reason := fmt.Sprintf("Late payment for the expired deposit of reservation %d", deposit.ReservationID)
+	if err := u.paymentUseCase.RefundTransaction(ctx, deposit.ExternalID, deposit.Amount, reason); err != nil {
+		u.logger.Errorf("Error refunding late payment of deposit %d: %v", deposit.ID, err)
+		u.restoreDeposit(ctx, previous, entity.DepositStatusRefunded)
+		return err
+	}
+	u.logger.Warnf("Refunded deposit %d of reservation %d, it was paid after it expired", deposit.ID, deposit.ReservationID)
+	// the payment gateway is the actor, so it has no customer id
+	u.audit.Record(ctx, model.Actor{}, constants.AuditActionRefund, constants.AuditEntityDeposit, deposit.ID, before, model.ToDepositResponse(deposit))
+	u.notifyRefund(ctx, reservation, deposit)
+	return nil
+}
+
+func (u *reservationUseCase) ExpireDeposits(ctx context.Context) (int, error) {
+	deposits, err := u.depositRepo.GetExpiredDeposits(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range deposits {
+		expired, err := u.expireDeposit(ctx, &deposits[i])
+		if err != nil {
+			u.logger.Errorf("Error expiring deposit %d: %v", deposits[i].ID, err)
+			continue
+		}
+		if expired {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// expireDeposit cancels the reservation of a pending deposit, it reports false
+// and leaves the reservation alone when the deposit was paid meanwhile
+func (u *reservationUseCase) expireDeposit(ctx context.Context, deposit *entity.ReservationDeposit) (bool, error) {
+	deposit.Status = entity.DepositStatusExpired
+	expired, err := u.depositRepo.TransitionDeposit(ctx, deposit, entity.DepositStatusPending)
+	if err != nil || !expired {
+		return false, err
+	}
+	return true, u.setStatus(ctx, deposit.ReservationID, entity.ReservationStatusCancelled)
+}
+
+// restoreDeposit puts a claimed deposit back as it was before the claim when
+// the payment gateway call that followed failed, so the call can be retried
+func (u *reservationUseCase) restoreDeposit(ctx context.Context, previous entity.ReservationDeposit, claimed entity.DepositStatus) {
+	if _, err := u.depositRepo.TransitionDeposit(ctx, &previous, claimed); err != nil {
+		u.logger.Errorf("Error restoring deposit %d to %s: %v", previous.ID, previous.Status, err)
+	}
+}
+
+func (u *reservationUseCase) MarkNoShow(ctx context.Context, actor model.Actor, id uint, request *model.NoShowRequest) (*model.ReservationResponse, error) {
+	reservation, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransitionReservation(reservation.Status, entity.ReservationStatusNoShow) {
+		return nil, fmt.Errorf("%w: %s to %s", constants.ErrInvalidReservationStatus, reservation.Status, entity.ReservationStatusNoShow)
+	}
+
+	deposit, err := u.releaseDeposit(ctx, actor, reservation, request.RefundDeposit, fmt.Sprintf("No-show refund for reservation %d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.customerRepo.IncrementNoShows(ctx, int64(reservation.CustomerID)); err != nil {
+		return nil, err
+	}
+	if err := u.setStatus(ctx, id, entity.ReservationStatusNoShow); err != nil {
+		return nil, err
+	}
+
+	before := model.ToReservationResponse(reservation)
+	reservation.Status = entity.ReservationStatusNoShow
+	response := model.ToReservationResponse(reservation)
+	u.audit.Record(ctx, actor, constants.AuditActionNoShow, constants.AuditEntityReservation, id, before, response)
+	if deposit != nil {
+		response.Deposit = model.ToDepositResponse(deposit)
+	}
+	return response, nil
+}
+
+func (u *reservationUseCase) ApplyDeposit(ctx context.Context, actor model.Actor, id uint, request *model.ApplyDepositRequest) (*model.TenderResponse, error) {
+	deposit, err := u.depositRepo.GetDepositByReservationID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != entity.DepositStatusPaid {
+		return nil, constants.ErrDepositNotPaid
+	}
+	reservation, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the deposit is taken before the bill is credited, so it is credited once
+	previous := *deposit
+	deposit.Status = entity.DepositStatusApplied
+	deposit.AppliedOrderID = &request.OrderID
+	applied, err := u.depositRepo.TransitionDeposit(ctx, deposit, entity.DepositStatusPaid)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, constants.ErrDepositNotPaid
+	}
+
+	reference := fmt.Sprintf("reservation #%d", id)
+	tender, err := u.billUseCase.ApplyCredit(ctx, actor, request.OrderID, int64(reservation.CustomerID), deposit.Amount, constants.PaymentMethodDeposit, reference)
+	if err != nil {
+		u.restoreDeposit(ctx, previous, entity.DepositStatusApplied)
+		return nil, err
+	}
+
+	return tender, nil
+}
+
+func (u *reservationUseCase) Cancel(ctx context.Context, actor model.Actor, id uint) (*model.ReservationResponse, error) {
+	reservation, err := u.getChangeable(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := model.ToReservationResponse(reservation)
+
+	// cancelling before the cutoff gets a paid deposit back
+	deposit, err := u.releaseDeposit(ctx, actor, reservation, true, fmt.Sprintf("Cancellation refund for reservation %d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	reservation.Status = entity.ReservationStatusCancelled
+	if err := u.repo.Update(ctx, reservation); err != nil {
+		return nil, err
+	}
+	u.invalidateReservation(ctx, id)
+
+	response := model.ToReservationResponse(reservation)
+	u.audit.Record(ctx, actor, constants.AuditActionCancel, constants.AuditEntityReservation, id, before, response)
+	if deposit != nil {
+		response.Deposit = model.ToDepositResponse(deposit)
+	}
+	return response, nil
+}
+
+func (u *reservationUseCase) Modify(ctx context.Context, actor model.Actor, id uint, request *model.ModifyReservationRequest) (*model.ReservationResponse, error) {
+	reservation, err := u.getChangeable(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToReservationResponse(reservation)
+	previous := *reservation
+
+	guestsChanged := request.GuestCount != 0 && request.GuestCount != reservation.GuestCount
+	dateChanged := !request.ReserveDate.IsZero() && !request.ReserveDate.Equal(reservation.ReserveDate)
+	if guestsChanged && reservation.TableID != nil {
+		table, err := u.tableRepository.GetByID(ctx, *reservation.TableID)
+		if err != nil {
+			return nil, err
+		}
+		if request.GuestCount > table.Capacity {
+			return nil, fmt.Errorf("%w: table %d seats %d guests", constants.ErrInvalidRequest, table.TableNumber, table.Capacity)
+		}
+	}
+
+	if dateChanged {
+		earliest := time.Now()
+		if !actor.IsStaff() {
+			earliest = earliest.Add(u.changeCutoff)
+		}
+		if request.ReserveDate.Before(earliest) {
+			return nil, fmt.Errorf("%w: reserve_date must be after %s", constants.ErrInvalidRequest, earliest.Format(time.RFC3339))
+		}
+
+		// the table is checked again for the new day, ignoring this booking
+		if reservation.TableID != nil {
+			available, err := u.repo.CheckTableAvailability(ctx, *reservation.TableID, request.ReserveDate, id)
+			if err != nil {
+				return nil, err
+			}
+			if !available {
+				return nil, constants.ErrTableUnavailable
+			}
+		}
+		reservation.ReserveDate = request.ReserveDate
+	}
+	if request.GuestCount != 0 {
+		reservation.GuestCount = request.GuestCount
+	}
+	if request.SpecialNotes != nil {
+		reservation.SpecialNotes = *request.SpecialNotes
+	}
+
+	// a bigger party or another slot may fall under a deposit rule
+	var needsDeposit bool
+	if guestsChanged || dateChanged {
+		if needsDeposit, err = u.checkDepositForChange(ctx, reservation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repo.Update(ctx, reservation); err != nil {
+		return nil, err
+	}
+	u.invalidateReservation(ctx, id)
+
+	var deposit *entity.ReservationDeposit
+	if needsDeposit {
+		if deposit, err = u.requestDeposit(ctx, reservation); err != nil {
+			// without a payable deposit the change cannot be kept
+			if revertErr := u.repo.Update(ctx, &previous); revertErr != nil {
+				u.logger.Errorf("Error reverting reservation %d after deposit failure: %v", id, revertErr)
+			}
+			u.invalidateReservation(ctx, id)
+			return nil, err
+		}
+	}
+
+	response := model.ToReservationResponse(reservation)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityReservation, id, before, response)
+	if deposit != nil {
+		response.Deposit = model.ToDepositResponse(deposit)
+	}
+	return response, nil
+}
+
+// checkDepositForChange reports whether the changed reservation now needs a
+// deposit it doesn't have yet. A deposit taken for a smaller amount can't be
+// topped up, so such changes are refused.
+func (u *reservationUseCase) checkDepositForChange(ctx context.Context, reservation *entity.Reservation) (bool, error) {
+	rules, err := u.depositRepo.GetActiveRules(ctx)
+	if err != nil {
+		return false, err
+	}
+	rule, amount := matchDepositRule(rules, reservation.GuestCount, reservation.ReserveDate)
+	if rule == nil {
+		return false, nil
+	}
+
+	deposit, err := u.depositRepo.GetDepositByReservationID(ctx, reservation.ID)
+	if errors.Is(err, constants.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if deposit.Amount < amount {
+		return false, fmt.Errorf("%w: the change needs a deposit of %.2f, please contact the restaurant", constants.ErrInvalidRequest, amount)
+	}
+	return false, nil
+}
+
+// getChangeable loads a reservation the actor may still cancel or modify
+func (u *reservationUseCase) getChangeable(ctx context.Context, actor model.Actor, id uint) (*entity.Reservation, error) {
+	reservation, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Owns(int64(reservation.CustomerID)) {
+		return nil, constants.ErrNotFound
+	}
+	// cancelled is its own target, so it is rejected before the transition check
+	if reservation.Status == entity.ReservationStatusCancelled ||
+		!canTransitionReservation(reservation.Status, entity.ReservationStatusCancelled) {
+		return nil, fmt.Errorf("%w: %s reservations cannot be changed", constants.ErrInvalidReservationStatus, reservation.Status)
+	}
+	if !actor.IsStaff() && time.Until(reservation.ReserveDate) < u.changeCutoff {
+		return nil, constants.ErrReservationLocked
+	}
+	return reservation, nil
+}
+
+// releaseDeposit settles the deposit of a reservation that will not be honoured:
+// a paid deposit is refunded or forfeited and a pending one expires
+func (u *reservationUseCase) releaseDeposit(ctx context.Context, actor model.Actor, reservation *entity.Reservation, refund bool, reason string) (*entity.ReservationDeposit, error) {
+	deposit, err := u.depositRepo.GetDepositByReservationID(ctx, reservation.ID)
+	if errors.Is(err, constants.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *deposit
+	before := model.ToDepositResponse(deposit)
+	switch deposit.Status {
+	case entity.DepositStatusPaid:
+		if refund {
+			deposit.Status = entity.DepositStatusRefunded
+		} else {
+			deposit.Status = entity.DepositStatusForfeited
+		}
+	case entity.DepositStatusPending:
+		deposit.Status = entity.DepositStatusExpired
+	default:
+		return deposit, nil
+	}
+
+	released, err := u.depositRepo.TransitionDeposit(ctx, deposit, previous.Status)
+	if err != nil {
+		return nil, err
+	}
+	if !released {
+		// the deposit was paid or released meanwhile, settle it as it is now
+		return u.releaseDeposit(ctx, actor, reservation, refund, reason)
+	}
+
+	if deposit.Status == entity.DepositStatusRefunded {
+		if err := u.paymentUseCase.RefundTransaction(ctx, deposit.ExternalID, deposit.Amount, reason); err != nil {
+			u.logger.Errorf("Error refunding deposit %d: %v", deposit.ID, err)
+			u.restoreDeposit(ctx, previous, entity.DepositStatusRefunded)
+			return nil, err
+		}
+		u.notifyRefund(ctx, reservation, deposit)
+	}
+
+	action := constants.AuditActionStatusChange
+	if deposit.Status == entity.DepositStatusRefunded {
+		action = constants.AuditActionRefund
+	}
+	u.audit.Record(ctx, actor, action, constants.AuditEntityDeposit, deposit.ID, before, model.ToDepositResponse(deposit))
+	return deposit, nil
+}
+
+// reservationTransitions lists the statuses a reservation can move to,
+// completed, cancelled and no-show are final
+var reservationTransitions = map[entity.ReservationStatus][]entity.ReservationStatus{
+	entity.ReservationStatusPending:   {entity.ReservationStatusConfirmed, entity.ReservationStatusCancelled, entity.ReservationStatusNoShow},
+	entity.ReservationStatusConfirmed: {entity.ReservationStatusSeated, entity.ReservationStatusCancelled, entity.ReservationStatusNoShow},
+	entity.ReservationStatusSeated:    {entity.ReservationStatusCompleted},
+}
+
+func canTransitionReservation(from, to entity.ReservationStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range reservationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *reservationUseCase) setStatus(ctx context.Context, id uint, status entity.ReservationStatus) error {
+	reservation, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !canTransitionReservation(reservation.Status, status) {
+		return fmt.Errorf("%w: %s to %s", constants.ErrInvalidReservationStatus, reservation.Status, status)
+	}
+	confirmed := reservation.Status != entity.ReservationStatusConfirmed && status == entity.ReservationStatusConfirmed
+	reservation.Status = status
+	if err := u.repo.Update(ctx, reservation); err != nil {
+		return err
+	}
+
+	u.invalidateReservation(ctx, id)
+	if confirmed {
+		u.notifyConfirmed(ctx, reservation)
+	}
+	return nil
+}
+
+func (u *reservationUseCase) notifyConfirmed(ctx context.Context, reservation *entity.Reservation) {
+	data := map[string]any{
+		"ReservationID": reservation.ID,
+		"GuestCount":    reservation.GuestCount,
+		"Date":          reservation.ReserveDate.Local().Format("Monday, 2 January 2006 at 15:04"),
+	}
+	if err := u.notifier.Notify(ctx, notification.EventReservationConfirmed, int64(reservation.CustomerID), fmt.Sprint(reservation.ID), data); err != nil {
+		u.logger.Errorf("Error queueing confirmation for reservation %d: %v", reservation.ID, err)
+	}
+}
+
+func (u *reservationUseCase) notifyRefund(ctx context.Context, reservation *entity.Reservation, deposit *entity.ReservationDeposit) {
+	data := map[string]any{
+		"Amount": notification.FormatAmount(deposit.Amount),
+		"Reason": fmt.Sprintf("the deposit of reservation #%d", reservation.ID),
+	}
+	if err := u.notifier.Notify(ctx, notification.EventRefundProcessed, int64(reservation.CustomerID), deposit.ExternalID, data); err != nil {
+		u.logger.Errorf("Error queueing refund notification for deposit %d: %v", deposit.ID, err)
+	}
+}
+
+func (u *reservationUseCase) invalidateReservation(ctx context.Context, id uint) {
+	cacheKey := fmt.Sprintf("reservation:%d", id)
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
+		u.logger.Errorf("Error deleting cache for reservation ID %d: %v", id, err)
+	}
+	if err := u.cache.DeletePrefix(ctx, reservationListCachePrefix); err != nil {
+		u.logger.Errorf("Error deleting cache for all reservations: %v", err)
+	}
+	if err := u.cache.DeletePrefix(ctx, adminReservationListCachePrefix); err != nil {
+		u.logger.Errorf("Error deleting cache for admin reservations: %v", err)
+	}
+}
+
+// toReservationPage converts a page of reservations into responses
+func toReservationPage(result *model.PaginationResponse[[]entity.Reservation]) *model.PaginationResponse[[]model.ReservationResponse] {
+	responses := make([]model.ReservationResponse, len(result.Data))
+	for i, reservation := range result.Data {
+		responses[i] = *model.ToReservationResponse(&reservation)
+	}
+
+	return &model.PaginationResponse[[]model.ReservationResponse]{
+		Data:       responses,
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		TotalPages: result.TotalPages,
+	}
+}
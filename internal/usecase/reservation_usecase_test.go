@@ -1,9 +1,11 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -17,65 +19,83 @@ type MockReservationRepository struct {
 	mock.Mock
 }
 
-func (m *MockReservationRepository) Create(reservation *entity.Reservation) error {
-	args := m.Called(reservation)
+func (m *MockReservationRepository) Create(ctx context.Context, reservation *entity.Reservation) error {
+	args := m.Called(ctx, reservation)
 	return args.Error(0)
 }
 
-func (m *MockReservationRepository) GetByID(id uint) (*entity.Reservation, error) {
-	args := m.Called(id)
+func (m *MockReservationRepository) GetByID(ctx context.Context, id uint) (*entity.Reservation, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Reservation), args.Error(1)
 }
 
-func (m *MockReservationRepository) GetAll(params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error) {
-	args := m.Called(params)
+func (m *MockReservationRepository) GetAll(ctx context.Context, params *model.ReservationQueryParams) (*model.PaginationResponse[[]entity.Reservation], error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.PaginationResponse[[]entity.Reservation]), args.Error(1)
 }
 
-func (m *MockReservationRepository) AdminGetAllCustomerReservations(params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error) {
-	args := m.Called(params)
+func (m *MockReservationRepository) AdminGetAllCustomerReservations(ctx context.Context, params *model.PaginationQuery) (*model.PaginationResponse[[]entity.Reservation], error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.PaginationResponse[[]entity.Reservation]), args.Error(1)
 }
 
-func (m *MockReservationRepository) Update(reservation *entity.Reservation) error {
-	args := m.Called(reservation)
+func (m *MockReservationRepository) Update(ctx context.Context, reservation *entity.Reservation) error {
+	args := m.Called(ctx, reservation)
 	return args.Error(0)
 }
 
-func (m *MockReservationRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockReservationRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockReservationRepository) CheckTableAvailability(tableID uint, reserveDate time.Time) (bool, error) {
-	args := m.Called(tableID, reserveDate)
+func (m *MockReservationRepository) CheckTableAvailability(ctx context.Context, tableID uint, reserveDate time.Time, excludeID uint) (bool, error) {
+	args := m.Called(ctx, tableID, reserveDate, excludeID)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockReservationRepository) GetUpcoming(ctx context.Context, from, to time.Time) ([]entity.Reservation, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Reservation), args.Error(1)
+}
+
+var staff = model.Actor{CustomerID: 99, Role: constants.RoleWaitress}
+
+func (m *MockReservationRepository) GetActiveBetween(ctx context.Context, from, to time.Time) ([]entity.Reservation, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Reservation), args.Error(1)
+}
+
 func TestReservationUseCase_GetByID(t *testing.T) {
 	logger := logrus.New()
 	mockReservationRepo := new(MockReservationRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache)
+	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache, nil, nil, nil, nil, 0, 0, nil, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedReservation := &entity.Reservation{
 			ID: 1,
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("GetByID", uint(1)).Return(expectedReservation, nil).Once()
+		mockReservationRepo.On("GetByID", mock.Anything, uint(1)).Return(expectedReservation, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		reservation, err := useCase.GetByID(1)
+		reservation, err := useCase.GetByID(context.Background(), staff, 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reservation)
@@ -85,9 +105,9 @@ func TestReservationUseCase_GetByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("GetByID", uint(1)).Return(nil, errors.New("not found")).Once()
+		mockReservationRepo.On("GetByID", mock.Anything, uint(1)).Return(nil, errors.New("not found")).Once()
 
-		reservation, err := useCase.GetByID(1)
+		reservation, err := useCase.GetByID(context.Background(), staff, 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, reservation)
@@ -99,7 +119,7 @@ func TestReservationUseCase_GetAll(t *testing.T) {
 	logger := logrus.New()
 	mockReservationRepo := new(MockReservationRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache)
+	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache, nil, nil, nil, nil, 0, 0, nil, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := &model.PaginationResponse[[]entity.Reservation]{
@@ -112,10 +132,10 @@ func TestReservationUseCase_GetAll(t *testing.T) {
 		}
 		params := &model.ReservationQueryParams{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("GetAll", params).Return(expectedResponse, nil).Once()
+		mockReservationRepo.On("GetAll", mock.Anything, params).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		reservations, err := useCase.GetAll(params)
+		reservations, err := useCase.GetAll(context.Background(), staff, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reservations)
@@ -126,9 +146,9 @@ func TestReservationUseCase_GetAll(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.ReservationQueryParams{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("GetAll", params).Return(nil, errors.New("error")).Once()
+		mockReservationRepo.On("GetAll", mock.Anything, params).Return(nil, errors.New("error")).Once()
 
-		reservations, err := useCase.GetAll(params)
+		reservations, err := useCase.GetAll(context.Background(), staff, params)
 
 		assert.Error(t, err)
 		assert.Nil(t, reservations)
@@ -140,7 +160,7 @@ func TestReservationUseCase_AdminGetAllCustomerReservations(t *testing.T) {
 	logger := logrus.New()
 	mockReservationRepo := new(MockReservationRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache)
+	useCase := NewReservationUseCase(mockReservationRepo, logger, nil, mockCache, nil, nil, nil, nil, 0, 0, nil, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := &model.PaginationResponse[[]entity.Reservation]{
@@ -153,10 +173,10 @@ func TestReservationUseCase_AdminGetAllCustomerReservations(t *testing.T) {
 		}
 		params := &model.PaginationQuery{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("AdminGetAllCustomerReservations", params).Return(expectedResponse, nil).Once()
+		mockReservationRepo.On("AdminGetAllCustomerReservations", mock.Anything, params).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		reservations, err := useCase.AdminGetAllCustomerReservations(params)
+		reservations, err := useCase.AdminGetAllCustomerReservations(context.Background(), params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reservations)
@@ -167,12 +187,333 @@ func TestReservationUseCase_AdminGetAllCustomerReservations(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.PaginationQuery{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockReservationRepo.On("AdminGetAllCustomerReservations", params).Return(nil, errors.New("error")).Once()
+		mockReservationRepo.On("AdminGetAllCustomerReservations", mock.Anything, params).Return(nil, errors.New("error")).Once()
 
-		reservations, err := useCase.AdminGetAllCustomerReservations(params)
+		reservations, err := useCase.AdminGetAllCustomerReservations(context.Background(), params)
 
 		assert.Error(t, err)
 		assert.Nil(t, reservations)
 		mockReservationRepo.AssertExpectations(t)
 	})
 }
+
+func TestReservationUseCase_GetByIDOwnership(t *testing.T) {
+	mockReservationRepo := new(MockReservationRepository)
+	mockCache := new(database.MockRedisCacheService)
+	useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+	mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
+	mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockReservationRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.Reservation{ID: 1, CustomerID: 5}, nil)
+
+	t.Run("owner", func(t *testing.T) {
+		reservation, err := useCase.GetByID(context.Background(), model.Actor{CustomerID: 5, Role: constants.RoleCustomer}, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), reservation.CustomerID)
+	})
+
+	t.Run("other customer", func(t *testing.T) {
+		reservation, err := useCase.GetByID(context.Background(), model.Actor{CustomerID: 6, Role: constants.RoleCustomer}, 1)
+
+		assert.ErrorIs(t, err, constants.ErrNotFound)
+		assert.Nil(t, reservation)
+	})
+}
+
+func TestReservationUseCase_GetAllScopesCustomers(t *testing.T) {
+	mockReservationRepo := new(MockReservationRepository)
+	mockCache := new(database.MockRedisCacheService)
+	useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+	mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
+	mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockReservationRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(p *model.ReservationQueryParams) bool {
+		return p.CustomerID == 5
+	})).Return(&model.PaginationResponse[[]entity.Reservation]{}, nil)
+
+	// a customer asking for someone else's reservations only gets their own
+	_, err := useCase.GetAll(context.Background(), model.Actor{CustomerID: 5, Role: constants.RoleCustomer}, &model.ReservationQueryParams{CustomerID: 6})
+
+	assert.NoError(t, err)
+	mockReservationRepo.AssertExpectations(t)
+}
+
+func TestReservationUseCase_Cancel(t *testing.T) {
+	customer := model.Actor{CustomerID: 5, Role: constants.RoleCustomer}
+
+	t.Run("before the cutoff refunds the deposit", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		mockNotifier := new(MockNotificationUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, mockNotifier, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		deposit := &entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 100000, ExternalID: "DEPOSIT-7-x", Status: entity.DepositStatusPaid}
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusConfirmed, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(deposit, nil)
+		mockPaymentUseCase.On("RefundTransaction", mock.Anything, "DEPOSIT-7-x", float64(100000), mock.Anything).Return(nil)
+		mockDepositRepo.On("TransitionDeposit", mock.Anything, deposit, entity.DepositStatusPaid).Return(true, nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *entity.Reservation) bool {
+			return r.Status == entity.ReservationStatusCancelled
+		})).Return(nil)
+
+		response, err := useCase.Cancel(context.Background(), customer, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, string(entity.ReservationStatusCancelled), response.Status)
+		assert.Equal(t, string(entity.DepositStatusRefunded), response.Deposit.Status)
+		mockReservationRepo.AssertExpectations(t)
+	})
+
+	t.Run("after the cutoff", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusConfirmed, ReserveDate: time.Now().Add(time.Hour)}, nil)
+
+		_, err := useCase.Cancel(context.Background(), customer, 7)
+
+		assert.ErrorIs(t, err, constants.ErrReservationLocked)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("staff ignore the cutoff", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(time.Hour)}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(nil, constants.ErrNotFound)
+		mockReservationRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Reservation")).Return(nil)
+
+		_, err := useCase.Cancel(context.Background(), staff, 7)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("someone else's reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 6, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+
+		_, err := useCase.Cancel(context.Background(), customer, 7)
+
+		assert.ErrorIs(t, err, constants.ErrNotFound)
+	})
+
+	t.Run("seated reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusSeated, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+
+		_, err := useCase.Cancel(context.Background(), customer, 7)
+
+		assert.ErrorIs(t, err, constants.ErrInvalidReservationStatus)
+	})
+}
+
+func TestReservationUseCase_Modify(t *testing.T) {
+	customer := model.Actor{CustomerID: 5, Role: constants.RoleCustomer}
+	tableID := uint(3)
+
+	t.Run("new date is checked for availability", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockTableRepo := new(MockTableRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), mockTableRepo, mockCache, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+
+		newDate := time.Now().Add(72 * time.Hour)
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, TableID: &tableID, GuestCount: 2, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockTableRepo.On("GetByID", mock.Anything, tableID).Return(&entity.Table{ID: 3, TableNumber: 3, Capacity: 4}, nil)
+		mockReservationRepo.On("CheckTableAvailability", mock.Anything, tableID, newDate, uint(7)).Return(true, nil)
+		mockDepositRepo.On("GetActiveRules", mock.Anything).Return([]entity.DepositRule{}, nil)
+		mockReservationRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *entity.Reservation) bool {
+			return r.ReserveDate.Equal(newDate) && r.GuestCount == 4
+		})).Return(nil)
+
+		response, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{ReserveDate: newDate, GuestCount: 4})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, response.GuestCount)
+		mockReservationRepo.AssertExpectations(t)
+	})
+
+	t.Run("table already booked", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		newDate := time.Now().Add(72 * time.Hour)
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, TableID: &tableID, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockReservationRepo.On("CheckTableAvailability", mock.Anything, tableID, newDate, uint(7)).Return(false, nil)
+
+		_, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{ReserveDate: newDate})
+
+		assert.ErrorIs(t, err, constants.ErrTableUnavailable)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("more guests than the table seats", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockTableRepo := new(MockTableRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), mockTableRepo, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, TableID: &tableID, GuestCount: 2, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockTableRepo.On("GetByID", mock.Anything, tableID).Return(&entity.Table{ID: 3, TableNumber: 3, Capacity: 4}, nil)
+
+		_, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{GuestCount: 6})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("bigger party now needs a deposit", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockCache := new(database.MockRedisCacheService)
+		mockDepositRepo := new(MockDepositRepository)
+		mockPaymentUseCase := new(MockPaymentUseCase)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, mockPaymentUseCase, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, GuestCount: 2, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockDepositRepo.On("GetActiveRules", mock.Anything).Return([]entity.DepositRule{{ID: 1, Active: true, MinGuests: 6, FlatAmount: 100000}}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(nil, constants.ErrNotFound)
+		mockReservationRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Reservation")).Return(nil)
+		mockPaymentUseCase.On("CreateTransaction", mock.Anything, mock.Anything, float64(100000), 30*time.Minute).Return(&model.PaymentResponse{Token: "tok", RedirectURL: "https://pay"}, nil)
+		mockDepositRepo.On("CreateDeposit", mock.Anything, mock.AnythingOfType("*entity.ReservationDeposit")).Return(nil)
+
+		response, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{GuestCount: 8})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8, response.GuestCount)
+		assert.Equal(t, float64(100000), response.Deposit.Amount)
+		mockDepositRepo.AssertExpectations(t)
+	})
+
+	t.Run("paid deposit too small for the bigger party", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		mockDepositRepo := new(MockDepositRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, GuestCount: 6, Status: entity.ReservationStatusConfirmed, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockDepositRepo.On("GetActiveRules", mock.Anything).Return([]entity.DepositRule{{ID: 1, Active: true, MinGuests: 6, AmountPerGuest: 20000}}, nil)
+		mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(&entity.ReservationDeposit{ID: 1, ReservationID: 7, Amount: 120000, Status: entity.DepositStatusPaid}, nil)
+
+		_, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{GuestCount: 10})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancelled reservation", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusCancelled, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+
+		_, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{GuestCount: 3})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidReservationStatus)
+		mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("new date inside the cutoff", func(t *testing.T) {
+		mockReservationRepo := new(MockReservationRepository)
+		useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, nil, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+		mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, CustomerID: 5, Status: entity.ReservationStatusPending, ReserveDate: time.Now().Add(24 * time.Hour)}, nil)
+
+		_, err := useCase.Modify(context.Background(), customer, 7, &model.ModifyReservationRequest{ReserveDate: time.Now().Add(30 * time.Minute)})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+	})
+}
+
+func TestReservationUseCase_UpdateStatusRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  entity.ReservationStatus
+		deposit *entity.ReservationDeposit
+		want    error
+	}{
+		{name: "skipping a status", status: entity.ReservationStatusSeated, want: constants.ErrInvalidReservationStatus},
+		{name: "cancelling goes through cancel", status: entity.ReservationStatusCancelled, want: constants.ErrInvalidReservationStatus},
+		{name: "no-shows go through mark no-show", status: entity.ReservationStatusNoShow, want: constants.ErrInvalidReservationStatus},
+		{name: "pending deposit blocks confirmation", status: entity.ReservationStatusConfirmed, deposit: &entity.ReservationDeposit{ID: 1, ReservationID: 7, Status: entity.DepositStatusPending}, want: constants.ErrDepositNotPaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReservationRepo := new(MockReservationRepository)
+			mockDepositRepo := new(MockDepositRepository)
+			useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, nil, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, nil, newMockAuditRecorder())
+
+			mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, Status: entity.ReservationStatusPending}, nil)
+			mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(tt.deposit, nil)
+
+			_, err := useCase.Update(context.Background(), model.Actor{}, 7, &model.UpdateReservationRequest{Status: string(tt.status)})
+
+			assert.ErrorIs(t, err, tt.want)
+			mockReservationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReservationUseCase_UpdateConfirmsWithoutDeposit(t *testing.T) {
+	mockReservationRepo := new(MockReservationRepository)
+	mockCache := new(database.MockRedisCacheService)
+	mockDepositRepo := new(MockDepositRepository)
+	mockNotifier := new(MockNotificationUseCase)
+	useCase := NewReservationUseCase(mockReservationRepo, logrus.New(), nil, mockCache, mockDepositRepo, nil, nil, nil, 30*time.Minute, 2*time.Hour, mockNotifier, newMockAuditRecorder())
+
+	mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("DeletePrefix", mock.Anything, mock.Anything).Return(nil)
+	mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockReservationRepo.On("GetByID", mock.Anything, uint(7)).Return(&entity.Reservation{ID: 7, Status: entity.ReservationStatusPending}, nil)
+	mockDepositRepo.On("GetDepositByReservationID", mock.Anything, uint(7)).Return(nil, constants.ErrNotFound)
+	mockReservationRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *entity.Reservation) bool {
+		return r.Status == entity.ReservationStatusConfirmed
+	})).Return(nil)
+
+	_, err := useCase.Update(context.Background(), model.Actor{}, 7, &model.UpdateReservationRequest{Status: string(entity.ReservationStatusConfirmed)})
+
+	assert.NoError(t, err)
+	mockReservationRepo.AssertExpectations(t)
+}
+
+func TestCanTransitionReservation(t *testing.T) {
+	tests := []struct {
+		from, to entity.ReservationStatus
+		want     bool
+	}{
+		{entity.ReservationStatusPending, entity.ReservationStatusConfirmed, true},
+		{entity.ReservationStatusConfirmed, entity.ReservationStatusSeated, true},
+		{entity.ReservationStatusSeated, entity.ReservationStatusCompleted, true},
+		{entity.ReservationStatusPending, entity.ReservationStatusNoShow, true},
+		{entity.ReservationStatusPending, entity.ReservationStatusSeated, false},
+		{entity.ReservationStatusSeated, entity.ReservationStatusCancelled, false},
+		{entity.ReservationStatusCompleted, entity.ReservationStatusPending, false},
+		{entity.ReservationStatusCancelled, entity.ReservationStatusConfirmed, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, canTransitionReservation(tt.from, tt.to), "%s to %s", tt.from, tt.to)
+	}
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RoleUseCase manages the permission policy, it maps every role to the
+// permissions checked by RequirePermission
+type RoleUseCase interface {
+	GetRoles(ctx context.Context) ([]model.RoleResponse, error)
+	CreateRole(ctx context.Context, actor model.Actor, request *model.CreateRoleRequest) (*model.RoleResponse, error)
+	UpdateRole(ctx context.Context, actor model.Actor, id int64, request *model.UpdateRoleRequest) (*model.RoleResponse, error)
+	DeleteRole(ctx context.Context, actor model.Actor, id int64) error
+	// GetPermissions lists every permission a role can be granted
+	GetPermissions(ctx context.Context) []string
+	// HasPermission reports whether the role is granted the permission, admins
+	// hold every permission
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+type roleUseCase struct {
+	roleRepo repository.RoleRepository
+	log      *logrus.Logger
+	cache    database.RedisCache
+	audit    AuditRecorder
+}
+
+func NewRoleUseCase(roleRepo repository.RoleRepository, log *logrus.Logger, cache database.RedisCache, audit AuditRecorder) RoleUseCase {
+	return &roleUseCase{
+		roleRepo: roleRepo,
+		log:      log,
+		cache:    cache,
+		audit:    audit,
+	}
+}
+
+func rolePermissionsCacheKey(role string) string {
+	return fmt.Sprintf("role_permissions:%s", role)
+}
+
+func (u *roleUseCase) GetRoles(ctx context.Context) ([]model.RoleResponse, error) {
+	roles, err := u.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.RoleResponse, 0, len(roles))
+	for i := range roles {
+		responses = append(responses, model.ToRoleResponse(&roles[i]))
+	}
+	return responses, nil
+}
+
+func (u *roleUseCase) CreateRole(ctx context.Context, actor model.Actor, request *model.CreateRoleRequest) (*model.RoleResponse, error) {
+	if !roleNamePattern.MatchString(request.Name) {
+		return nil, fmt.Errorf("%w: role name may only contain lowercase letters, digits and underscores", constants.ErrInvalidRequest)
+	}
+	permissions, err := toRolePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.roleRepo.GetByName(ctx, request.Name); err == nil {
+		return nil, constants.ErrRoleExists
+	} else if !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+
+	role := &entity.Role{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: permissions,
+	}
+	if err := u.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	u.invalidateRole(ctx, role.Name)
+	response := model.ToRoleResponse(role)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityRole, role.ID, nil, response)
+	return &response, nil
+}
+
+func (u *roleUseCase) UpdateRole(ctx context.Context, actor model.Actor, id int64, request *model.UpdateRoleRequest) (*model.RoleResponse, error) {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToRoleResponse(role)
+	permissions, err := toRolePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = request.Description
+	role.Permissions = permissions
+	if err := u.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	u.invalidateRole(ctx, role.Name)
+	response := model.ToRoleResponse(role)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityRole, id, before, response)
+	return &response, nil
+}
+
+func (u *roleUseCase) DeleteRole(ctx context.Context, actor model.Actor, id int64) error {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return constants.ErrRoleBuiltIn
+	}
+
+	assigned, err := u.roleRepo.CountAssigned(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if assigned > 0 {
+		return constants.ErrRoleInUse
+	}
+
+	if err := u.roleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	u.invalidateRole(ctx, role.Name)
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityRole, id, model.ToRoleResponse(role), nil)
+	return nil
+}
+
+func (u *roleUseCase) GetPermissions(ctx context.Context) []string {
+	return constants.Permissions
+}
+
+func (u *roleUseCase) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	if role == constants.RoleAdmin {
+		return true, nil
+	}
+
+	permissions, err := u.rolePermissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rolePermissions loads the permissions of a role through the cache, unknown
+// roles have none
+func (u *roleUseCase) rolePermissions(ctx context.Context, role string) ([]string, error) {
+	return database.GetOrLoad(ctx, u.cache, rolePermissionsCacheKey(role), cacheTTL, func(ctx context.Context) ([]string, error) {
+		found, err := u.roleRepo.GetByName(ctx, role)
+		switch {
+		case err == nil:
+			return found.PermissionNames(), nil
+		case errors.Is(err, constants.ErrNotFound):
+			return []string{}, nil
+		}
+		return nil, err
+	})
+}
+
+func (u *roleUseCase) invalidateRole(ctx context.Context, name string) {
+	if err := u.cache.Delete(ctx, rolePermissionsCacheKey(name)); err != nil {
+		u.log.Errorf("Error deleting cache for role permissions: %v", err)
+	}
+}
+
+func toRolePermissions(names []string) ([]entity.RolePermission, error) {
+	seen := make(map[string]bool, len(names))
+	permissions := make([]entity.RolePermission, 0, len(names))
+	for _, name := range names {
+		if !constants.IsPermission(name) {
+			return nil, fmt.Errorf("%w: unknown permission %q", constants.ErrInvalidRequest, name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		permissions = append(permissions, entity.RolePermission{Permission: name})
+	}
+	return permissions, nil
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetAll(ctx context.Context) ([]entity.Role, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByID(ctx context.Context, id int64) (*entity.Role, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) Create(ctx context.Context, role *entity.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Update(ctx context.Context, role *entity.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) CountAssigned(ctx context.Context, name string) (int64, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRoleUseCase_HasPermission(t *testing.T) {
+	logger := logrus.New()
+	cacheMiss := errors.New("cache miss")
+
+	t.Run("Admin holds every permission", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		allowed, err := useCase.HasPermission(context.Background(), constants.RoleAdmin, constants.PermRoleManage)
+
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		mockRepo.AssertNotCalled(t, "GetByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Loads the policy once and caches it", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, database.NewMemoryCache(10), newMockAuditRecorder())
+
+		mockRepo.On("GetByName", mock.Anything, constants.RoleWaitress).Return(&entity.Role{
+			Name:        constants.RoleWaitress,
+			Permissions: []entity.RolePermission{{Permission: constants.PermBillManage}},
+		}, nil).Once()
+
+		allowed, err := useCase.HasPermission(context.Background(), constants.RoleWaitress, constants.PermMenuWrite)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = useCase.HasPermission(context.Background(), constants.RoleWaitress, constants.PermBillManage)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown role has no permissions", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewRoleUseCase(mockRepo, logger, mockCache, newMockAuditRecorder())
+
+		mockCache.On("Get", mock.Anything, "role_permissions:customer", mock.Anything).Return(cacheMiss)
+		mockRepo.On("GetByName", mock.Anything, constants.RoleCustomer).Return(nil, constants.ErrNotFound)
+		mockCache.On("Set", mock.Anything, "role_permissions:customer", mock.Anything, mock.Anything).Return(nil)
+
+		allowed, err := useCase.HasPermission(context.Background(), constants.RoleCustomer, constants.PermEmployeeRead)
+
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewRoleUseCase(mockRepo, logger, mockCache, newMockAuditRecorder())
+
+		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(cacheMiss)
+		mockRepo.On("GetByName", mock.Anything, "cashier").Return(nil, errors.New("db down"))
+
+		allowed, err := useCase.HasPermission(context.Background(), constants.RoleCashier, constants.PermTableWrite)
+
+		assert.Error(t, err)
+		assert.False(t, allowed)
+	})
+}
+
+func TestRoleUseCase_CreateRole(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewRoleUseCase(mockRepo, logger, mockCache, newMockAuditRecorder())
+
+		mockRepo.On("GetByName", mock.Anything, "shift_lead").Return(nil, constants.ErrNotFound)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Role")).Return(nil)
+		mockCache.On("Delete", mock.Anything, "role_permissions:shift_lead").Return(nil)
+
+		role, err := useCase.CreateRole(context.Background(), model.Actor{}, &model.CreateRoleRequest{
+			Name:        "shift_lead",
+			Permissions: []string{constants.PermRefundCreate, constants.PermBillManage, constants.PermRefundCreate},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "shift_lead", role.Name)
+		assert.Equal(t, []string{constants.PermRefundCreate, constants.PermBillManage}, role.Permissions)
+		assert.False(t, role.BuiltIn)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Unknown permission", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		_, err := useCase.CreateRole(context.Background(), model.Actor{}, &model.CreateRoleRequest{Name: "shift_lead", Permissions: []string{"menu:eat"}})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid name", func(t *testing.T) {
+		useCase := NewRoleUseCase(new(MockRoleRepository), logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		_, err := useCase.CreateRole(context.Background(), model.Actor{}, &model.CreateRoleRequest{Name: "Shift Lead"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		mockRepo.On("GetByName", mock.Anything, constants.RoleCashier).Return(&entity.Role{Name: constants.RoleCashier}, nil)
+
+		_, err := useCase.CreateRole(context.Background(), model.Actor{}, &model.CreateRoleRequest{Name: constants.RoleCashier})
+
+		assert.ErrorIs(t, err, constants.ErrRoleExists)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRoleUseCase_UpdateRole(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	mockCache := new(database.MockRedisCacheService)
+	recorder := newMockAuditRecorder()
+	useCase := NewRoleUseCase(mockRepo, logrus.New(), mockCache, recorder)
+	actor := model.Actor{CustomerID: 1, Role: constants.RoleAdmin}
+
+	mockRepo.On("GetByID", mock.Anything, int64(4)).Return(&entity.Role{
+		ID:          4,
+		Name:        constants.RoleWaitress,
+		BuiltIn:     true,
+		Permissions: []entity.RolePermission{{Permission: constants.PermMenuWrite}},
+	}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(role *entity.Role) bool {
+		return len(role.Permissions) == 1 && role.Permissions[0].Permission == constants.PermTableStatus
+	})).Return(nil)
+	mockCache.On("Delete", mock.Anything, "role_permissions:waitress").Return(nil)
+
+	role, err := useCase.UpdateRole(context.Background(), actor, 4, &model.UpdateRoleRequest{Permissions: []string{constants.PermTableStatus}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{constants.PermTableStatus}, role.Permissions)
+	recorder.AssertCalled(t, "Record", mock.Anything, actor, constants.AuditActionUpdate, constants.AuditEntityRole, int64(4),
+		mock.MatchedBy(func(before model.RoleResponse) bool { return before.Permissions[0] == constants.PermMenuWrite }),
+		mock.MatchedBy(func(after model.RoleResponse) bool { return after.Permissions[0] == constants.PermTableStatus }))
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestRoleUseCase_DeleteRole(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		mockCache := new(database.MockRedisCacheService)
+		useCase := NewRoleUseCase(mockRepo, logger, mockCache, newMockAuditRecorder())
+
+		mockRepo.On("GetByID", mock.Anything, int64(9)).Return(&entity.Role{ID: 9, Name: "shift_lead"}, nil)
+		mockRepo.On("CountAssigned", mock.Anything, "shift_lead").Return(int64(0), nil)
+		mockRepo.On("Delete", mock.Anything, int64(9)).Return(nil)
+		mockCache.On("Delete", mock.Anything, "role_permissions:shift_lead").Return(nil)
+
+		assert.NoError(t, useCase.DeleteRole(context.Background(), model.Actor{}, 9))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Built-in role", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Role{ID: 1, Name: constants.RoleAdmin, BuiltIn: true}, nil)
+
+		assert.ErrorIs(t, useCase.DeleteRole(context.Background(), model.Actor{}, 1), constants.ErrRoleBuiltIn)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Still assigned", func(t *testing.T) {
+		mockRepo := new(MockRoleRepository)
+		useCase := NewRoleUseCase(mockRepo, logger, new(database.MockRedisCacheService), newMockAuditRecorder())
+
+		mockRepo.On("GetByID", mock.Anything, int64(9)).Return(&entity.Role{ID: 9, Name: "shift_lead"}, nil)
+		mockRepo.On("CountAssigned", mock.Anything, "shift_lead").Return(int64(2), nil)
+
+		assert.ErrorIs(t, useCase.DeleteRole(context.Background(), model.Actor{}, 9), constants.ErrRoleInUse)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OnShiftLister reports who is working at a given time, role filters the
+// result unless empty
+type OnShiftLister interface {
+	OnShift(ctx context.Context, at time.Time, role string) ([]model.OnShiftResponse, error)
+}
+
+type ShiftUseCase interface {
+	OnShiftLister
+	// GetShifts returns the weekly schedule ordered by weekday and start time
+	GetShifts(ctx context.Context, params *model.ShiftQueryParams) ([]model.ShiftResponse, error)
+	CreateShift(ctx context.Context, actor model.Actor, request *model.ShiftRequest) (*model.ShiftResponse, error)
+	UpdateShift(ctx context.Context, actor model.Actor, id int64, request *model.ShiftRequest) (*model.ShiftResponse, error)
+	DeleteShift(ctx context.Context, actor model.Actor, id int64) error
+}
+
+type shiftUseCase struct {
+	employeeRepo repository.EmployeeRepository
+	customerRepo repository.CustomerRepository
+	roleRepo     repository.RoleRepository
+	log          *logrus.Logger
+	audit        AuditRecorder
+}
+
+func NewShiftUseCase(
+	employeeRepo repository.EmployeeRepository,
+	customerRepo repository.CustomerRepository,
+	roleRepo repository.RoleRepository,
+	log *logrus.Logger,
+	audit AuditRecorder,
+) ShiftUseCase {
+	return &shiftUseCase{
+		employeeRepo: employeeRepo,
+		customerRepo: customerRepo,
+		roleRepo:     roleRepo,
+		log:          log,
+		audit:        audit,
+	}
+}
+
+func (u *shiftUseCase) GetShifts(ctx context.Context, params *model.ShiftQueryParams) ([]model.ShiftResponse, error) {
+	shifts, err := u.employeeRepo.GetShifts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.ShiftResponse, 0, len(shifts))
+	for i := range shifts {
+		responses = append(responses, model.ToShiftResponse(&shifts[i]))
+	}
+	return responses, nil
+}
+
+func (u *shiftUseCase) CreateShift(ctx context.Context, actor model.Actor, request *model.ShiftRequest) (*model.ShiftResponse, error) {
+	shift := &entity.Shift{}
+	if err := u.apply(ctx, shift, request); err != nil {
+		return nil, err
+	}
+	if err := u.employeeRepo.CreateShift(ctx, shift); err != nil {
+		return nil, err
+	}
+
+	response := model.ToShiftResponse(shift)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityShift, shift.ID, nil, response)
+	return &response, nil
+}
+
+func (u *shiftUseCase) UpdateShift(ctx context.Context, actor model.Actor, id int64, request *model.ShiftRequest) (*model.ShiftResponse, error) {
+	shift, err := u.employeeRepo.GetShiftByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToShiftResponse(shift)
+
+	if err := u.apply(ctx, shift, request); err != nil {
+		return nil, err
+	}
+	if err := u.employeeRepo.UpdateShift(ctx, shift); err != nil {
+		return nil, err
+	}
+
+	response := model.ToShiftResponse(shift)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityShift, id, before, response)
+	return &response, nil
+}
+
+func (u *shiftUseCase) DeleteShift(ctx context.Context, actor model.Actor, id int64) error {
+	shift, err := u.employeeRepo.GetShiftByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.employeeRepo.DeleteShift(ctx, id); err != nil {
+		return err
+	}
+
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityShift, id, model.ToShiftResponse(shift), nil)
+	return nil
+}
+
+func (u *shiftUseCase) OnShift(ctx context.Context, at time.Time, role string) ([]model.OnShiftResponse, error) {
+	shifts, err := u.employeeRepo.GetShifts(ctx, &model.ShiftQueryParams{Role: role})
+	if err != nil {
+		return nil, err
+	}
+
+	at = at.In(time.Local)
+	minute := time.Date(at.Year(), at.Month(), at.Day(), at.Hour(), at.Minute(), 0, 0, time.Local)
+	weekMinute := int(at.Weekday())*24*60 + at.Hour()*60 + at.Minute()
+
+	working := make([]model.OnShiftResponse, 0)
+	for i := range shifts {
+		shift := &shifts[i]
+		if !shift.Covers(weekMinute) {
+			continue
+		}
+		elapsed := ((weekMinute-shift.WeekStart())%entity.MinutesPerWeek + entity.MinutesPerWeek) % entity.MinutesPerWeek
+		startsAt := minute.Add(-time.Duration(elapsed) * time.Minute)
+		working = append(working, model.OnShiftResponse{
+			EmployeeID: shift.EmployeeID,
+			Name:       shift.Employee.Name,
+			Role:       shift.Role,
+			ShiftID:    shift.ID,
+			StartsAt:   startsAt,
+			EndsAt:     startsAt.Add(time.Duration(shift.Duration()) * time.Minute),
+		})
+	}
+
+	sort.Slice(working, func(i, j int) bool {
+		if working[i].Role != working[j].Role {
+			return working[i].Role < working[j].Role
+		}
+		return working[i].Name < working[j].Name
+	})
+	return working, nil
+}
+
+// apply validates the request and copies it onto the shift, shifts of the
+// same employee may not overlap
+func (u *shiftUseCase) apply(ctx context.Context, shift *entity.Shift, request *model.ShiftRequest) error {
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, request.EmployeeID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return fmt.Errorf("%w: employee %d not found", constants.ErrInvalidRequest, request.EmployeeID)
+		}
+		return err
+	}
+
+	role := request.Role
+	if role == "" {
+		role = employee.Role
+	}
+	if role == constants.RoleCustomer {
+		return fmt.Errorf("%w: shifts can't use the customer role", constants.ErrInvalidRequest)
+	}
+	if role != employee.Role {
+		if _, err := u.roleRepo.GetByName(ctx, role); err != nil {
+			if errors.Is(err, constants.ErrNotFound) {
+				return fmt.Errorf("%w: unknown role %s", constants.ErrInvalidRequest, role)
+			}
+			return err
+		}
+	}
+
+	start, err := model.ParseClock(request.StartTime)
+	if err != nil {
+		return fmt.Errorf("%w: start_time must be HH:MM", constants.ErrInvalidRequest)
+	}
+	end, err := model.ParseClock(request.EndTime)
+	if err != nil {
+		return fmt.Errorf("%w: end_time must be HH:MM", constants.ErrInvalidRequest)
+	}
+	if start == end {
+		return fmt.Errorf("%w: a shift can't start and end at the same time", constants.ErrInvalidRequest)
+	}
+
+	shift.EmployeeID = employee.ID
+	shift.Employee = *employee
+	shift.Role = role
+	shift.Weekday = time.Weekday(request.Weekday)
+	shift.StartMinute = start
+	shift.EndMinute = end
+
+	existing, err := u.employeeRepo.GetShifts(ctx, &model.ShiftQueryParams{EmployeeID: employee.ID})
+	if err != nil {
+		return err
+	}
+	for i := range existing {
+		if existing[i].ID != shift.ID && shift.Overlaps(&existing[i]) {
+			return fmt.Errorf("%w: %s %s-%s", constants.ErrShiftOverlap, existing[i].Weekday,
+				model.FormatClock(existing[i].StartMinute), model.FormatClock(existing[i].EndMinute))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmployeeRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeRepository) CreateWithProfile(ctx context.Context, employee *entity.Customer, profile *entity.EmployeeProfile) error {
+	args := m.Called(ctx, employee, profile)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRepository) GetProfile(ctx context.Context, customerID int64) (*entity.EmployeeProfile, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmployeeProfile), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) GetProfiles(ctx context.Context, customerIDs []int64) ([]entity.EmployeeProfile, error) {
+	args := m.Called(ctx, customerIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EmployeeProfile), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) SaveProfile(ctx context.Context, profile *entity.EmployeeProfile) error {
+	args := m.Called(ctx, profile)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRepository) GetShifts(ctx context.Context, params *model.ShiftQueryParams) ([]entity.Shift, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Shift), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) GetShiftByID(ctx context.Context, id int64) (*entity.Shift, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Shift), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) CreateShift(ctx context.Context, shift *entity.Shift) error {
+	args := m.Called(ctx, shift)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRepository) UpdateShift(ctx context.Context, shift *entity.Shift) error {
+	args := m.Called(ctx, shift)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRepository) DeleteShift(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockOnShiftLister struct {
+	mock.Mock
+}
+
+func (m *MockOnShiftLister) OnShift(ctx context.Context, at time.Time, role string) ([]model.OnShiftResponse, error) {
+	args := m.Called(ctx, at, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.OnShiftResponse), args.Error(1)
+}
+
+func TestShift_Covers(t *testing.T) {
+	// Saturday 22:00 until Sunday 06:00 wraps around the end of the week
+	shift := &entity.Shift{Weekday: time.Saturday, StartMinute: 22 * 60, EndMinute: 6 * 60}
+
+	assert.Equal(t, 8*60, shift.Duration())
+	assert.True(t, shift.Covers(int(time.Saturday)*24*60+23*60))
+	assert.True(t, shift.Covers(int(time.Sunday)*24*60+5*60))
+	assert.False(t, shift.Covers(int(time.Sunday)*24*60+6*60))
+	assert.False(t, shift.Covers(int(time.Saturday)*24*60+21*60))
+}
+
+func TestShiftUseCase_CreateShift(t *testing.T) {
+	newUseCase := func() (ShiftUseCase, *MockEmployeeRepository, *MockCustomerRepository) {
+		employeeRepo := new(MockEmployeeRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewShiftUseCase(employeeRepo, customerRepo, new(MockRoleRepository), logrus.New(), newMockAuditRecorder())
+		return useCase, employeeRepo, customerRepo
+	}
+
+	t.Run("defaults to the employee role", func(t *testing.T) {
+		useCase, employeeRepo, customerRepo := newUseCase()
+		customerRepo.On("GetEmployeeByID", mock.Anything, int64(9)).Return(&entity.Customer{ID: 9, Name: "Sari", Role: constants.RoleWaitress}, nil)
+		employeeRepo.On("GetShifts", mock.Anything, &model.ShiftQueryParams{EmployeeID: 9}).Return([]entity.Shift{
+			{ID: 1, EmployeeID: 9, Weekday: time.Monday, StartMinute: 8 * 60, EndMinute: 16 * 60},
+		}, nil)
+		employeeRepo.On("CreateShift", mock.Anything, mock.MatchedBy(func(shift *entity.Shift) bool {
+			return shift.Role == constants.RoleWaitress && shift.StartMinute == 16*60 && shift.EndMinute == 23*60
+		})).Return(nil)
+
+		shift, err := useCase.CreateShift(context.Background(), model.Actor{}, &model.ShiftRequest{EmployeeID: 9, Weekday: 1, StartTime: "16:00", EndTime: "23:00"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Monday", shift.WeekdayName)
+		employeeRepo.AssertExpectations(t)
+	})
+
+	t.Run("overnight shift overlaps the next morning", func(t *testing.T) {
+		useCase, employeeRepo, customerRepo := newUseCase()
+		customerRepo.On("GetEmployeeByID", mock.Anything, int64(9)).Return(&entity.Customer{ID: 9, Role: constants.RoleKitchen}, nil)
+		employeeRepo.On("GetShifts", mock.Anything, &model.ShiftQueryParams{EmployeeID: 9}).Return([]entity.Shift{
+			{ID: 1, EmployeeID: 9, Weekday: time.Tuesday, StartMinute: 5 * 60, EndMinute: 12 * 60},
+		}, nil)
+
+		_, err := useCase.CreateShift(context.Background(), model.Actor{}, &model.ShiftRequest{EmployeeID: 9, Weekday: 1, StartTime: "22:00", EndTime: "06:00"})
+
+		assert.ErrorIs(t, err, constants.ErrShiftOverlap)
+		employeeRepo.AssertNotCalled(t, "CreateShift", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid clock", func(t *testing.T) {
+		useCase, employeeRepo, customerRepo := newUseCase()
+		customerRepo.On("GetEmployeeByID", mock.Anything, int64(9)).Return(&entity.Customer{ID: 9, Role: constants.RoleKitchen}, nil)
+
+		_, err := useCase.CreateShift(context.Background(), model.Actor{}, &model.ShiftRequest{EmployeeID: 9, Weekday: 1, StartTime: "25:00", EndTime: "06:00"})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		employeeRepo.AssertNotCalled(t, "CreateShift", mock.Anything, mock.Anything)
+	})
+}
+
+func TestShiftUseCase_OnShift(t *testing.T) {
+	employeeRepo := new(MockEmployeeRepository)
+	useCase := NewShiftUseCase(employeeRepo, new(MockCustomerRepository), new(MockRoleRepository), logrus.New(), newMockAuditRecorder())
+
+	employeeRepo.On("GetShifts", mock.Anything, &model.ShiftQueryParams{Role: constants.RoleWaitress}).Return([]entity.Shift{
+		{ID: 1, EmployeeID: 4, Employee: entity.Customer{Name: "Ayu"}, Role: constants.RoleWaitress, Weekday: time.Monday, StartMinute: 22 * 60, EndMinute: 6 * 60},
+		{ID: 2, EmployeeID: 9, Employee: entity.Customer{Name: "Sari"}, Role: constants.RoleWaitress, Weekday: time.Tuesday, StartMinute: 8 * 60, EndMinute: 16 * 60},
+	}, nil)
+
+	// Tuesday 02:30, inside Monday's overnight shift
+	at := time.Date(2026, time.October, 20, 2, 30, 0, 0, time.Local)
+	working, err := useCase.OnShift(context.Background(), at, constants.RoleWaitress)
+
+	assert.NoError(t, err)
+	assert.Len(t, working, 1)
+	assert.Equal(t, "Ayu", working[0].Name)
+	assert.Equal(t, time.Date(2026, time.October, 19, 22, 0, 0, 0, time.Local), working[0].StartsAt)
+	assert.Equal(t, time.Date(2026, time.October, 20, 6, 0, 0, 0, time.Local), working[0].EndsAt)
+}
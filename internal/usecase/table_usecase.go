@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"cakestore/internal/constants"
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
@@ -14,222 +15,212 @@ import (
 )
 
 type TableUseCase interface {
-	Create(request *model.CreateTableRequest) (*model.TableResponse, error)
-	GetByID(id uint) (*model.TableResponse, error)
-	GetAll(params *model.TableQueryParams) (*model.PaginationResponse[[]model.TableResponse], error)
-	Update(id uint, request *model.UpdateTableRequest) (*model.TableResponse, error)
-	Delete(id uint) error
-	GetAvailableTables(reserveTime time.Time, duration time.Duration) ([]model.TableResponse, error)
-	UpdateAvailability(id uint, isAvailable bool) error
+	Create(ctx context.Context, actor model.Actor, request *model.CreateTableRequest) (*model.TableResponse, error)
+	GetByID(ctx context.Context, id uint) (*model.TableResponse, error)
+	GetAll(ctx context.Context, params *model.TableQueryParams) (*model.PaginationResponse[[]model.TableResponse], error)
+	Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateTableRequest) (*model.TableResponse, error)
+	Delete(ctx context.Context, actor model.Actor, id uint) error
+	GetAvailableTables(ctx context.Context, reserveTime time.Time, duration time.Duration) ([]model.TableResponse, error)
+	UpdateAvailability(ctx context.Context, actor model.Actor, id uint, isAvailable bool) error
 }
 
 type tableUseCase struct {
 	tableRepo repository.TableRepository
 	log       *logrus.Logger
 	cache     database.RedisCache
+	audit     AuditRecorder
 }
 
-func NewTableUseCase(tableRepo repository.TableRepository, log *logrus.Logger, cache database.RedisCache) TableUseCase {
+func NewTableUseCase(tableRepo repository.TableRepository, log *logrus.Logger, cache database.RedisCache, audit AuditRecorder) TableUseCase {
 	return &tableUseCase{
 		tableRepo: tableRepo,
 		log:       log,
 		cache:     cache,
+		audit:     audit,
 	}
 }
 
-func (u *tableUseCase) Create(request *model.CreateTableRequest) (*model.TableResponse, error) {
+func (u *tableUseCase) Create(ctx context.Context, actor model.Actor, request *model.CreateTableRequest) (*model.TableResponse, error) {
 	table := &entity.Table{
 		TableNumber: request.TableNumber,
 		Capacity:    request.Capacity,
 		IsAvailable: true,
+		Status:      entity.TableStatusFree,
+		Shape:       entity.TableShapeSquare,
+		Width:       1,
+		Height:      1,
 	}
 
-	if err := u.tableRepo.Create(table); err != nil {
+	if err := u.tableRepo.Create(ctx, table); err != nil {
 		return nil, err
 	}
 
-	return model.ToTableResponse(table), nil
+	response := model.ToTableResponse(table)
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityTable, table.ID, nil, response)
+	return response, nil
 }
 
-func (u *tableUseCase) GetByID(id uint) (*model.TableResponse, error) {
+func (u *tableUseCase) GetByID(ctx context.Context, id uint) (*model.TableResponse, error) {
 	start := time.Now()
 	defer func() {
 		u.log.Infof("GetByID took %v", time.Since(start))
 	}()
 
-	// Try to get the table from the cache first
-	cacheKey := fmt.Sprintf("table:%d", id)
-	var table model.TableResponse
-	if err := u.cache.Get(context.Background(), cacheKey, &table); err == nil {
-		u.log.Info("Table fetched from cache")
-		return &table, nil
-	}
-
-	// If not in cache, get from the database
-	tableEntity, err := u.tableRepo.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-	response := model.ToTableResponse(tableEntity)
-
-	// Store the table in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, response, 5*time.Minute); err != nil {
-		u.log.Errorf("Error setting cache for table ID %d: %v", id, err)
-	}
-
-	return response, nil
+	return database.GetOrLoad(ctx, u.cache, fmt.Sprintf("table:%d", id), cacheTTL, func(ctx context.Context) (*model.TableResponse, error) {
+		tableEntity, err := u.tableRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return model.ToTableResponse(tableEntity), nil
+	})
 }
 
-func (u *tableUseCase) GetAll(params *model.TableQueryParams) (*model.PaginationResponse[[]model.TableResponse], error) {
+func (u *tableUseCase) GetAll(ctx context.Context, params *model.TableQueryParams) (*model.PaginationResponse[[]model.TableResponse], error) {
 	start := time.Now()
 	defer func() {
 		u.log.Infof("GetAll took %v", time.Since(start))
 	}()
 
-	// Try to get the tables from the cache first
-	cacheKey := fmt.Sprintf("tables:all:page:%d:limit:%d", params.Page, params.Limit)
-	var cachedData model.PaginationResponse[[]model.TableResponse]
-	if err := u.cache.Get(context.Background(), cacheKey, &cachedData); err == nil {
-		u.log.Info("Tables fetched from cache")
-		return &cachedData, nil
-	}
-
-	// If not in cache, get from the database
-	tables, err := u.tableRepo.GetAll()
-	if err != nil {
-		return nil, err
-	}
-
-	var filteredTables []entity.Table
-	for _, table := range tables {
-		if params.Capacity > 0 && table.Capacity != params.Capacity {
-			continue
-		}
-		if params.IsAvailable != nil && table.IsAvailable != *params.IsAvailable {
-			continue
+	// Every filter is part of the key
+	availability := "any"
+	if params.IsAvailable != nil {
+		availability = fmt.Sprint(*params.IsAvailable)
+	}
+	cacheKey := fmt.Sprintf("%spage:%d:limit:%d:capacity:%d:available:%s", tableListCachePrefix,
+		params.Page, params.Limit, params.Capacity, availability)
+	return database.GetOrLoad(ctx, u.cache, cacheKey, cacheTTL, func(ctx context.Context) (*model.PaginationResponse[[]model.TableResponse], error) {
+		tables, err := u.tableRepo.GetAll(ctx)
+		if err != nil {
+			return nil, err
 		}
-		filteredTables = append(filteredTables, table)
-	}
 
-	paginatedTables := utils.CreatePaginationMeta(params.Page, params.Limit, int64(len(filteredTables)))
-
-	var tableResponses []model.TableResponse
-	for _, table := range filteredTables {
-		tableResponses = append(tableResponses, *model.ToTableResponse(&table))
-	}
+		var filteredTables []entity.Table
+		for _, table := range tables {
+			if params.Capacity > 0 && table.Capacity != params.Capacity {
+				continue
+			}
+			if params.IsAvailable != nil && table.IsAvailable != *params.IsAvailable {
+				continue
+			}
+			filteredTables = append(filteredTables, table)
+		}
 
-	paginatedResponse := &model.PaginationResponse[[]model.TableResponse]{
-		Data:       tableResponses,
-		Total:      int64(len(filteredTables)),
-		Page:       params.Page,
-		PageSize:   params.Limit,
-		TotalPages: paginatedTables.LastPage,
-	}
+		paginatedTables := utils.CreatePaginationMeta(params.Page, params.Limit, int64(len(filteredTables)))
 
-	// Store the tables in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, paginatedResponse, 5*time.Minute); err != nil {
-		u.log.Errorf("Error setting cache for all tables: %v", err)
-	}
+		var tableResponses []model.TableResponse
+		for _, table := range filteredTables {
+			tableResponses = append(tableResponses, *model.ToTableResponse(&table))
+		}
 
-	return paginatedResponse, nil
+		return &model.PaginationResponse[[]model.TableResponse]{
+			Data:       tableResponses,
+			Total:      int64(len(filteredTables)),
+			Page:       params.Page,
+			PageSize:   params.Limit,
+			TotalPages: paginatedTables.LastPage,
+		}, nil
+	})
 }
 
-func (u *tableUseCase) Update(id uint, request *model.UpdateTableRequest) (*model.TableResponse, error) {
-	table, err := u.tableRepo.GetByID(id)
+func (u *tableUseCase) Update(ctx context.Context, actor model.Actor, id uint, request *model.UpdateTableRequest) (*model.TableResponse, error) {
+	table, err := u.tableRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := model.ToTableResponse(table)
 
 	table.TableNumber = request.TableNumber
 	table.Capacity = request.Capacity
 	table.IsAvailable = request.IsAvailable
 
-	if err := u.tableRepo.Update(table); err != nil {
+	if err := u.tableRepo.Update(ctx, table); err != nil {
 		return nil, err
 	}
+	response := model.ToTableResponse(table)
+	u.audit.Record(ctx, actor, constants.AuditActionUpdate, constants.AuditEntityTable, id, before, response)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("table:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.log.Errorf("Error deleting cache for table ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "tables:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, tableListCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for all tables: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "available_tables:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, availableTablesCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for available tables: %v", err)
 	}
 
-	return model.ToTableResponse(table), nil
+	return response, nil
 }
 
-func (u *tableUseCase) Delete(id uint) error {
-	if err := u.tableRepo.Delete(id); err != nil {
+func (u *tableUseCase) Delete(ctx context.Context, actor model.Actor, id uint) error {
+	table, err := u.tableRepo.GetByID(ctx, id)
+	if err != nil {
 		return err
 	}
+	if err := u.tableRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, actor, constants.AuditActionDelete, constants.AuditEntityTable, id, model.ToTableResponse(table), nil)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("table:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.log.Errorf("Error deleting cache for table ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "tables:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, tableListCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for all tables: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "available_tables:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, availableTablesCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for available tables: %v", err)
 	}
 
 	return nil
 }
 
-func (u *tableUseCase) GetAvailableTables(reserveTime time.Time, duration time.Duration) ([]model.TableResponse, error) {
+func (u *tableUseCase) GetAvailableTables(ctx context.Context, reserveTime time.Time, duration time.Duration) ([]model.TableResponse, error) {
 	start := time.Now()
 	defer func() {
 		u.log.Infof("GetAvailableTables took %v", time.Since(start))
 	}()
 
-	// Try to get the available tables from the cache first
-	cacheKey := fmt.Sprintf("available_tables:%s:%s", reserveTime.Format(time.RFC3339), duration.String())
-	var tables []model.TableResponse
-	if err := u.cache.Get(context.Background(), cacheKey, &tables); err == nil {
-		u.log.Info("Available tables fetched from cache")
-		return tables, nil
-	}
-
-	// If not in cache, get from the database
-	tableEntities, err := u.tableRepo.GetAvailableTables(reserveTime, duration)
-	if err != nil {
-		return nil, err
-	}
-
-	var tableResponses []model.TableResponse
-	for _, table := range tableEntities {
-		tableResponses = append(tableResponses, *model.ToTableResponse(&table))
-	}
-
-	// Store the available tables in the cache for future requests
-	if err := u.cache.Set(context.Background(), cacheKey, tableResponses, 5*time.Minute); err != nil {
-		u.log.Errorf("Error setting cache for available tables: %v", err)
-	}
+	cacheKey := fmt.Sprintf("%s%s:%s", availableTablesCachePrefix, reserveTime.Format(time.RFC3339), duration.String())
+	return database.GetOrLoad(ctx, u.cache, cacheKey, cacheTTL, func(ctx context.Context) ([]model.TableResponse, error) {
+		tableEntities, err := u.tableRepo.GetAvailableTables(ctx, reserveTime, duration)
+		if err != nil {
+			return nil, err
+		}
 
-	return tableResponses, nil
+		var tableResponses []model.TableResponse
+		for _, table := range tableEntities {
+			tableResponses = append(tableResponses, *model.ToTableResponse(&table))
+		}
+		return tableResponses, nil
+	})
 }
 
-func (u *tableUseCase) UpdateAvailability(id uint, isAvailable bool) error {
-	if err := u.tableRepo.UpdateAvailability(id, isAvailable); err != nil {
+func (u *tableUseCase) UpdateAvailability(ctx context.Context, actor model.Actor, id uint, isAvailable bool) error {
+	table, err := u.tableRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.tableRepo.UpdateAvailability(ctx, id, isAvailable); err != nil {
 		return err
 	}
+	u.audit.Record(ctx, actor, constants.AuditActionStatusChange, constants.AuditEntityTable, id,
+		map[string]bool{"is_available": table.IsAvailable},
+		map[string]bool{"is_available": isAvailable})
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("table:%d", id)
-	if err := u.cache.Delete(context.Background(), cacheKey); err != nil {
+	if err := u.cache.Delete(ctx, cacheKey); err != nil {
 		u.log.Errorf("Error deleting cache for table ID %d: %v", id, err)
 	}
-	if err := u.cache.Delete(context.Background(), "tables:all:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, tableListCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for all tables: %v", err)
 	}
-	if err := u.cache.Delete(context.Background(), "available_tables:*"); err != nil {
+	if err := u.cache.DeletePrefix(ctx, availableTablesCachePrefix); err != nil {
 		u.log.Errorf("Error deleting cache for available tables: %v", err)
 	}
 
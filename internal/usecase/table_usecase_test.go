@@ -4,6 +4,7 @@ import (
 	"cakestore/internal/database"
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -17,52 +18,57 @@ type MockTableRepository struct {
 	mock.Mock
 }
 
-func (m *MockTableRepository) Create(table *entity.Table) error {
-	args := m.Called(table)
+func (m *MockTableRepository) Create(ctx context.Context, table *entity.Table) error {
+	args := m.Called(ctx, table)
 	return args.Error(0)
 }
 
-func (m *MockTableRepository) GetByID(id uint) (*entity.Table, error) {
-	args := m.Called(id)
+func (m *MockTableRepository) GetByID(ctx context.Context, id uint) (*entity.Table, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entity.Table), args.Error(1)
 }
 
-func (m *MockTableRepository) GetAll() ([]entity.Table, error) {
-	args := m.Called()
+func (m *MockTableRepository) GetAll(ctx context.Context) ([]entity.Table, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Table), args.Error(1)
 }
 
-func (m *MockTableRepository) Update(table *entity.Table) error {
-	args := m.Called(table)
+func (m *MockTableRepository) Update(ctx context.Context, table *entity.Table) error {
+	args := m.Called(ctx, table)
 	return args.Error(0)
 }
 
-func (m *MockTableRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockTableRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockTableRepository) GetAvailableTables(reserveTime time.Time, duration time.Duration) ([]entity.Table, error) {
-	args := m.Called(reserveTime, duration)
+func (m *MockTableRepository) GetAvailableTables(ctx context.Context, reserveTime time.Time, duration time.Duration) ([]entity.Table, error) {
+	args := m.Called(ctx, reserveTime, duration)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]entity.Table), args.Error(1)
 }
 
-func (m *MockTableRepository) UpdateAvailability(id uint, isAvailable bool) error {
-	args := m.Called(id, isAvailable)
+func (m *MockTableRepository) UpdateAvailability(ctx context.Context, id uint, isAvailable bool) error {
+	args := m.Called(ctx, id, isAvailable)
 	return args.Error(0)
 }
 
-func (m *MockTableRepository) Count() (int64, error) {
-	args := m.Called()
+func (m *MockTableRepository) UpdateStatus(ctx context.Context, id uint, status entity.TableStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockTableRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -70,17 +76,17 @@ func TestTableUseCase_GetByID(t *testing.T) {
 	logger := logrus.New()
 	mockTableRepo := new(MockTableRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewTableUseCase(mockTableRepo, logger, mockCache)
+	useCase := NewTableUseCase(mockTableRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedTable := &entity.Table{
 			ID: 1,
 		}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetByID", uint(1)).Return(expectedTable, nil).Once()
+		mockTableRepo.On("GetByID", mock.Anything, uint(1)).Return(expectedTable, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		table, err := useCase.GetByID(1)
+		table, err := useCase.GetByID(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, table)
@@ -90,9 +96,9 @@ func TestTableUseCase_GetByID(t *testing.T) {
 
 	t.Run("not found", func(t *testing.T) {
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetByID", uint(1)).Return(nil, errors.New("not found")).Once()
+		mockTableRepo.On("GetByID", mock.Anything, uint(1)).Return(nil, errors.New("not found")).Once()
 
-		table, err := useCase.GetByID(1)
+		table, err := useCase.GetByID(context.Background(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, table)
@@ -104,7 +110,7 @@ func TestTableUseCase_GetAll(t *testing.T) {
 	logger := logrus.New()
 	mockTableRepo := new(MockTableRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewTableUseCase(mockTableRepo, logger, mockCache)
+	useCase := NewTableUseCase(mockTableRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := []entity.Table{
@@ -114,10 +120,10 @@ func TestTableUseCase_GetAll(t *testing.T) {
 		}
 		params := &model.TableQueryParams{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetAll").Return(expectedResponse, nil).Once()
+		mockTableRepo.On("GetAll", mock.Anything).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		tables, err := useCase.GetAll(params)
+		tables, err := useCase.GetAll(context.Background(), params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, tables)
@@ -128,9 +134,9 @@ func TestTableUseCase_GetAll(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		params := &model.TableQueryParams{}
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetAll").Return(nil, errors.New("error")).Once()
+		mockTableRepo.On("GetAll", mock.Anything).Return(nil, errors.New("error")).Once()
 
-		tables, err := useCase.GetAll(params)
+		tables, err := useCase.GetAll(context.Background(), params)
 
 		assert.Error(t, err)
 		assert.Nil(t, tables)
@@ -142,7 +148,7 @@ func TestTableUseCase_GetAvailableTables(t *testing.T) {
 	logger := logrus.New()
 	mockTableRepo := new(MockTableRepository)
 	mockCache := new(database.MockRedisCacheService)
-	useCase := NewTableUseCase(mockTableRepo, logger, mockCache)
+	useCase := NewTableUseCase(mockTableRepo, logger, mockCache, newMockAuditRecorder())
 
 	t.Run("success", func(t *testing.T) {
 		expectedResponse := []entity.Table{
@@ -153,10 +159,10 @@ func TestTableUseCase_GetAvailableTables(t *testing.T) {
 		reserveTime := time.Now()
 		duration := time.Hour
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetAvailableTables", reserveTime, duration).Return(expectedResponse, nil).Once()
+		mockTableRepo.On("GetAvailableTables", mock.Anything, reserveTime, duration).Return(expectedResponse, nil).Once()
 		mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		tables, err := useCase.GetAvailableTables(reserveTime, duration)
+		tables, err := useCase.GetAvailableTables(context.Background(), reserveTime, duration)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, tables)
@@ -168,9 +174,9 @@ func TestTableUseCase_GetAvailableTables(t *testing.T) {
 		reserveTime := time.Now()
 		duration := time.Hour
 		mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
-		mockTableRepo.On("GetAvailableTables", reserveTime, duration).Return(nil, errors.New("error")).Once()
+		mockTableRepo.On("GetAvailableTables", mock.Anything, reserveTime, duration).Return(nil, errors.New("error")).Once()
 
-		tables, err := useCase.GetAvailableTables(reserveTime, duration)
+		tables, err := useCase.GetAvailableTables(context.Background(), reserveTime, duration)
 
 		assert.Error(t, err)
 		assert.Nil(t, tables)
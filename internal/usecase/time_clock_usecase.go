@@ -0,0 +1,634 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// punchGrace is how far punches may be off the schedule before the
+// timesheet flags them as late or leaving early
+const punchGrace = 5 * time.Minute
+
+// PayPolicy configures pay periods and overtime
+type PayPolicy struct {
+	// PeriodStart is the first day of a pay period, the next one starts
+	// every PeriodDays days before or after it
+	PeriodStart time.Time
+	PeriodDays  int
+	// WeeklyOvertime is the work per week paid at the regular rate, time
+	// beyond it is overtime paid at OvertimeMultiplier times the rate
+	WeeklyOvertime     time.Duration
+	OvertimeMultiplier float64
+}
+
+// Period returns the pay period containing at, the end is exclusive
+func (p PayPolicy) Period(at time.Time) (time.Time, time.Time) {
+	at = at.In(time.Local)
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.Local)
+	anchor := time.Date(p.PeriodStart.Year(), p.PeriodStart.Month(), p.PeriodStart.Day(), 0, 0, 0, 0, time.Local)
+
+	// rounding keeps whole days across daylight saving changes
+	days := int(math.Round(day.Sub(anchor).Hours() / 24))
+	offset := (days%p.PeriodDays + p.PeriodDays) % p.PeriodDays
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, p.PeriodDays)
+}
+
+type TimeClockUseCase interface {
+	// ClockIn starts a time entry for the actor, punches are only accepted
+	// from a PIN session on a registered POS terminal
+	ClockIn(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error)
+	// ClockOut ends the running entry, and a break still in progress
+	ClockOut(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error)
+	StartBreak(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error)
+	EndBreak(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error)
+	// GetCurrent returns the running entry of an employee, nil when clocked out
+	GetCurrent(ctx context.Context, employeeID int64) (*model.TimeEntryResponse, error)
+
+	GetEntries(ctx context.Context, params *model.TimeEntryQueryParams) ([]model.TimeEntryResponse, error)
+	// CreateEntry and CorrectEntry let a manager fix missed or wrong punches,
+	// the entry keeps who changed it and why
+	CreateEntry(ctx context.Context, actor model.Actor, request *model.CreateTimeEntryRequest) (*model.TimeEntryResponse, error)
+	CorrectEntry(ctx context.Context, actor model.Actor, id int64, request *model.CorrectTimeEntryRequest) (*model.TimeEntryResponse, error)
+
+	// GetTimesheet totals regular and overtime hours per employee for a pay
+	// period and reconciles the punches against the shift schedule
+	GetTimesheet(ctx context.Context, params *model.TimesheetQueryParams) (*model.TimesheetResponse, error)
+	// ExportTimesheet writes the timesheet as CSV, one row per employee
+	ExportTimesheet(ctx context.Context, params *model.TimesheetQueryParams, w io.Writer) error
+}
+
+type timeClockUseCase struct {
+	timeClockRepo repository.TimeClockRepository
+	employeeRepo  repository.EmployeeRepository
+	customerRepo  repository.CustomerRepository
+	log           *logrus.Logger
+	policy        PayPolicy
+	audit         AuditRecorder
+}
+
+func NewTimeClockUseCase(
+	timeClockRepo repository.TimeClockRepository,
+	employeeRepo repository.EmployeeRepository,
+	customerRepo repository.CustomerRepository,
+	log *logrus.Logger,
+	policy PayPolicy,
+	audit AuditRecorder,
+) TimeClockUseCase {
+	return &timeClockUseCase{
+		timeClockRepo: timeClockRepo,
+		employeeRepo:  employeeRepo,
+		customerRepo:  customerRepo,
+		log:           log,
+		policy:        policy,
+		audit:         audit,
+	}
+}
+
+func (u *timeClockUseCase) ClockIn(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error) {
+	if actor.DeviceID == 0 {
+		return nil, constants.ErrDeviceRequired
+	}
+	if _, err := u.timeClockRepo.GetOpenEntry(ctx, actor.CustomerID); err == nil {
+		return nil, constants.ErrAlreadyClockedIn
+	} else if !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, actor.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deviceID := actor.DeviceID
+	entry := &entity.TimeEntry{
+		EmployeeID:      employee.ID,
+		Employee:        *employee,
+		ClockInAt:       now,
+		ClockInDeviceID: &deviceID,
+	}
+	if err := u.timeClockRepo.CreateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, now)
+	return &response, nil
+}
+
+func (u *timeClockUseCase) ClockOut(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error) {
+	entry, err := u.openEntry(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if open := entry.OpenBreak(); open != nil {
+		open.EndAt = &now
+	}
+	deviceID := actor.DeviceID
+	entry.ClockOutAt = &now
+	entry.ClockOutDeviceID = &deviceID
+	if err := u.timeClockRepo.UpdateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, now)
+	return &response, nil
+}
+
+func (u *timeClockUseCase) StartBreak(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error) {
+	entry, err := u.openEntry(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if entry.OpenBreak() != nil {
+		return nil, constants.ErrAlreadyOnBreak
+	}
+
+	now := time.Now()
+	entry.Breaks = append(entry.Breaks, entity.TimeBreak{TimeEntryID: entry.ID, StartAt: now})
+	if err := u.timeClockRepo.UpdateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, now)
+	return &response, nil
+}
+
+func (u *timeClockUseCase) EndBreak(ctx context.Context, actor model.Actor) (*model.TimeEntryResponse, error) {
+	entry, err := u.openEntry(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	open := entry.OpenBreak()
+	if open == nil {
+		return nil, constants.ErrNotOnBreak
+	}
+
+	now := time.Now()
+	open.EndAt = &now
+	if err := u.timeClockRepo.UpdateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, now)
+	return &response, nil
+}
+
+func (u *timeClockUseCase) GetCurrent(ctx context.Context, employeeID int64) (*model.TimeEntryResponse, error) {
+	entry, err := u.timeClockRepo.GetOpenEntry(ctx, employeeID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, time.Now())
+	return &response, nil
+}
+
+func (u *timeClockUseCase) GetEntries(ctx context.Context, params *model.TimeEntryQueryParams) ([]model.TimeEntryResponse, error) {
+	entries, err := u.timeClockRepo.GetEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]model.TimeEntryResponse, 0, len(entries))
+	for i := range entries {
+		responses = append(responses, model.ToTimeEntryResponse(&entries[i], now))
+	}
+	return responses, nil
+}
+
+func (u *timeClockUseCase) CreateEntry(ctx context.Context, actor model.Actor, request *model.CreateTimeEntryRequest) (*model.TimeEntryResponse, error) {
+	employee, err := u.customerRepo.GetEmployeeByID(ctx, request.EmployeeID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, fmt.Errorf("%w: employee %d not found", constants.ErrInvalidRequest, request.EmployeeID)
+		}
+		return nil, err
+	}
+
+	entry := &entity.TimeEntry{EmployeeID: employee.ID, Employee: *employee}
+	if err := u.apply(ctx, actor, entry, &request.CorrectTimeEntryRequest); err != nil {
+		return nil, err
+	}
+	if err := u.timeClockRepo.CreateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, time.Now())
+	u.audit.Record(ctx, actor, constants.AuditActionCreate, constants.AuditEntityTimeEntry, entry.ID, nil, response)
+	return &response, nil
+}
+
+func (u *timeClockUseCase) CorrectEntry(ctx context.Context, actor model.Actor, id int64, request *model.CorrectTimeEntryRequest) (*model.TimeEntryResponse, error) {
+	entry, err := u.timeClockRepo.GetEntryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := model.ToTimeEntryResponse(entry, time.Now())
+
+	if err := u.apply(ctx, actor, entry, request); err != nil {
+		return nil, err
+	}
+	if err := u.timeClockRepo.UpdateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	response := model.ToTimeEntryResponse(entry, time.Now())
+	u.audit.Record(ctx, actor, constants.AuditActionCorrect, constants.AuditEntityTimeEntry, id, before, response)
+	return &response, nil
+}
+
+// openEntry returns the running entry of the actor punching on a terminal
+func (u *timeClockUseCase) openEntry(ctx context.Context, actor model.Actor) (*entity.TimeEntry, error) {
+	if actor.DeviceID == 0 {
+		return nil, constants.ErrDeviceRequired
+	}
+	entry, err := u.timeClockRepo.GetOpenEntry(ctx, actor.CustomerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrNotClockedIn
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// apply validates a manager's times and copies them onto the entry, breaks
+// must lie inside the entry and entries of one employee may not overlap
+func (u *timeClockUseCase) apply(ctx context.Context, actor model.Actor, entry *entity.TimeEntry, request *model.CorrectTimeEntryRequest) error {
+	now := time.Now()
+	if request.ClockInAt.After(now) || request.ClockOutAt != nil && request.ClockOutAt.After(now) {
+		return fmt.Errorf("%w: times can't be in the future", constants.ErrInvalidRequest)
+	}
+	if request.ClockOutAt != nil && !request.ClockOutAt.After(request.ClockInAt) {
+		return fmt.Errorf("%w: clock_out_at must be after clock_in_at", constants.ErrInvalidRequest)
+	}
+	end := now
+	if request.ClockOutAt != nil {
+		end = *request.ClockOutAt
+	}
+
+	breaks := make([]entity.TimeBreak, 0, len(request.Breaks))
+	for _, b := range request.Breaks {
+		if !b.EndAt.After(b.StartAt) {
+			return fmt.Errorf("%w: a break must end after it starts", constants.ErrInvalidRequest)
+		}
+		if b.StartAt.Before(request.ClockInAt) || b.EndAt.After(end) {
+			return fmt.Errorf("%w: breaks must be inside the entry", constants.ErrInvalidRequest)
+		}
+		endAt := *b.EndAt
+		breaks = append(breaks, entity.TimeBreak{TimeEntryID: entry.ID, StartAt: b.StartAt, EndAt: &endAt})
+	}
+	sort.Slice(breaks, func(i, j int) bool { return breaks[i].StartAt.Before(breaks[j].StartAt) })
+	for i := 1; i < len(breaks); i++ {
+		if breaks[i].StartAt.Before(*breaks[i-1].EndAt) {
+			return fmt.Errorf("%w: breaks can't overlap", constants.ErrInvalidRequest)
+		}
+	}
+
+	others, err := u.timeClockRepo.GetEntries(ctx, &model.TimeEntryQueryParams{
+		EmployeeID: entry.EmployeeID,
+		From:       request.ClockInAt.Add(-24 * time.Hour),
+		To:         end.Add(time.Nanosecond),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range others {
+		other := &others[i]
+		if other.ID == entry.ID {
+			continue
+		}
+		otherEnd := now
+		if other.ClockOutAt != nil {
+			otherEnd = *other.ClockOutAt
+		}
+		if other.ClockInAt.Before(end) && request.ClockInAt.Before(otherEnd) {
+			return fmt.Errorf("%w: entry %d from %s", constants.ErrTimeEntryOverlap, other.ID, other.ClockInAt.Format(time.RFC3339))
+		}
+	}
+
+	correctedBy := actor.CustomerID
+	entry.ClockInAt = request.ClockInAt
+	entry.ClockOutAt = request.ClockOutAt
+	if entry.ClockOutAt == nil {
+		entry.ClockOutDeviceID = nil
+	}
+	entry.Breaks = breaks
+	entry.CorrectedBy = &correctedBy
+	entry.CorrectedAt = &now
+	entry.CorrectionReason = request.Reason
+	return nil
+}
+
+func (u *timeClockUseCase) GetTimesheet(ctx context.Context, params *model.TimesheetQueryParams) (*model.TimesheetResponse, error) {
+	at := params.Date
+	if at.IsZero() {
+		at = time.Now()
+	}
+	start, end := u.policy.Period(at)
+
+	entries, err := u.timeClockRepo.GetEntries(ctx, &model.TimeEntryQueryParams{EmployeeID: params.EmployeeID, From: start, To: end})
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := u.employeeRepo.GetShifts(ctx, &model.ShiftQueryParams{EmployeeID: params.EmployeeID})
+	if err != nil {
+		return nil, err
+	}
+
+	// everyone who worked or was scheduled in the period gets a row
+	employees := map[int64]entity.Customer{}
+	entriesByEmployee := map[int64][]entity.TimeEntry{}
+	shiftsByEmployee := map[int64][]entity.Shift{}
+	for _, entry := range entries {
+		employees[entry.EmployeeID] = entry.Employee
+		entriesByEmployee[entry.EmployeeID] = append(entriesByEmployee[entry.EmployeeID], entry)
+	}
+	for _, shift := range shifts {
+		employees[shift.EmployeeID] = shift.Employee
+		shiftsByEmployee[shift.EmployeeID] = append(shiftsByEmployee[shift.EmployeeID], shift)
+	}
+
+	ids := make([]int64, 0, len(employees))
+	for id := range employees {
+		ids = append(ids, id)
+	}
+	profiles, err := u.employeeRepo.GetProfiles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[int64]float64, len(profiles))
+	for _, profile := range profiles {
+		rates[profile.CustomerID] = profile.HourlyRate
+	}
+
+	now := time.Now()
+	sheets := make([]model.EmployeeTimesheet, 0, len(employees))
+	for id, employee := range employees {
+		sheet := model.EmployeeTimesheet{
+			EmployeeID: id,
+			Name:       employee.Name,
+			Role:       employee.Role,
+			HourlyRate: rates[id],
+		}
+		u.totalHours(&sheet, entriesByEmployee[id], start, end, now)
+		reconcileShifts(&sheet, entriesByEmployee[id], shiftsByEmployee[id], start, end, now)
+		sheets = append(sheets, sheet)
+	}
+	sort.Slice(sheets, func(i, j int) bool {
+		if sheets[i].Name != sheets[j].Name {
+			return sheets[i].Name < sheets[j].Name
+		}
+		return sheets[i].EmployeeID < sheets[j].EmployeeID
+	})
+
+	return &model.TimesheetResponse{PeriodStart: start, PeriodEnd: end, Employees: sheets}, nil
+}
+
+func (u *timeClockUseCase) ExportTimesheet(ctx context.Context, params *model.TimesheetQueryParams, w io.Writer) error {
+	timesheet, err := u.GetTimesheet(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"employee_id", "name", "role", "period_start", "period_end", "scheduled_hours", "worked_hours",
+		"regular_hours", "overtime_hours", "break_hours", "hourly_rate", "regular_pay", "overtime_pay", "gross_pay", "exceptions"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	for _, sheet := range timesheet.Employees {
+		record := []string{
+			strconv.FormatInt(sheet.EmployeeID, 10),
+			sheet.Name,
+			sheet.Role,
+			timesheet.PeriodStart.Format(model.DateLayout),
+			timesheet.PeriodEnd.AddDate(0, 0, -1).Format(model.DateLayout),
+			formatFloat(sheet.ScheduledHours),
+			formatFloat(sheet.WorkedHours),
+			formatFloat(sheet.RegularHours),
+			formatFloat(sheet.OvertimeHours),
+			formatFloat(sheet.BreakHours),
+			formatFloat(sheet.HourlyRate),
+			formatFloat(sheet.RegularPay),
+			formatFloat(sheet.OvertimePay),
+			formatFloat(sheet.GrossPay),
+			exceptionSummary(sheet.Exceptions),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// totalHours adds up the closed entries of an employee, overtime is counted
+// per week of the pay period starting on the period's first day
+func (u *timeClockUseCase) totalHours(sheet *model.EmployeeTimesheet, entries []entity.TimeEntry, start, end, now time.Time) {
+	var weekStarts []time.Time
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 7) {
+		weekStarts = append(weekStarts, day)
+	}
+	worked := make([]time.Duration, len(weekStarts))
+
+	var breaks time.Duration
+	for i := range entries {
+		entry := &entries[i]
+		if entry.ClockOutAt == nil {
+			continue
+		}
+		week := sort.Search(len(weekStarts), func(w int) bool { return weekStarts[w].After(entry.ClockInAt) }) - 1
+		if week < 0 {
+			continue
+		}
+		worked[week] += entry.Worked(now)
+		breaks += entry.BreakTime(*entry.ClockOutAt)
+	}
+
+	var regular, overtime time.Duration
+	sheet.Weeks = make([]model.TimesheetWeek, 0, len(weekStarts))
+	for w, weekStart := range weekStarts {
+		weekRegular := worked[w]
+		if weekRegular > u.policy.WeeklyOvertime {
+			weekRegular = u.policy.WeeklyOvertime
+		}
+		weekOvertime := worked[w] - weekRegular
+		regular += weekRegular
+		overtime += weekOvertime
+		sheet.Weeks = append(sheet.Weeks, model.TimesheetWeek{
+			StartsOn:      weekStart.Format(model.DateLayout),
+			WorkedHours:   model.Hours(worked[w]),
+			RegularHours:  model.Hours(weekRegular),
+			OvertimeHours: model.Hours(weekOvertime),
+		})
+	}
+
+	sheet.WorkedHours = model.Hours(regular + overtime)
+	sheet.RegularHours = model.Hours(regular)
+	sheet.OvertimeHours = model.Hours(overtime)
+	sheet.BreakHours = model.Hours(breaks)
+	sheet.RegularPay = roundMoney(regular.Hours() * sheet.HourlyRate)
+	sheet.OvertimePay = roundMoney(overtime.Hours() * sheet.HourlyRate * u.policy.OvertimeMultiplier)
+	sheet.GrossPay = roundMoney(sheet.RegularPay + sheet.OvertimePay)
+}
+
+// scheduledShift is one occurrence of a weekly shift on a calendar day
+type scheduledShift struct {
+	shift   *entity.Shift
+	startAt time.Time
+	endAt   time.Time
+}
+
+// reconcileShifts matches each entry to the scheduled shift it overlaps most
+// and records late starts, early leaves, missed shifts and unscheduled work
+func reconcileShifts(sheet *model.EmployeeTimesheet, entries []entity.TimeEntry, shifts []entity.Shift, start, end, now time.Time) {
+	var scheduled []scheduledShift
+	var scheduledTime time.Duration
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for i := range shifts {
+			shift := &shifts[i]
+			if shift.Weekday != day.Weekday() {
+				continue
+			}
+			startAt := time.Date(day.Year(), day.Month(), day.Day(), 0, shift.StartMinute, 0, 0, time.Local)
+			duration := time.Duration(shift.Duration()) * time.Minute
+			scheduled = append(scheduled, scheduledShift{shift: shift, startAt: startAt, endAt: startAt.Add(duration)})
+			scheduledTime += duration
+		}
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].startAt.Before(scheduled[j].startAt) })
+	sheet.ScheduledHours = model.Hours(scheduledTime)
+
+	exceptions := make([]model.TimesheetException, 0)
+	matched := make([]bool, len(scheduled))
+	for i := range entries {
+		entry := &entries[i]
+		entryEnd := now
+		if entry.ClockOutAt == nil {
+			exceptions = append(exceptions, model.TimesheetException{Type: model.TimesheetOpenEntry, At: entry.ClockInAt, EntryID: entry.ID})
+		} else {
+			entryEnd = *entry.ClockOutAt
+		}
+
+		best, bestOverlap := -1, time.Duration(0)
+		for s := range scheduled {
+			if matched[s] {
+				continue
+			}
+			overlap := minTime(entryEnd, scheduled[s].endAt).Sub(maxTime(entry.ClockInAt, scheduled[s].startAt))
+			if overlap > bestOverlap {
+				best, bestOverlap = s, overlap
+			}
+		}
+		if best < 0 {
+			exceptions = append(exceptions, model.TimesheetException{
+				Type:    model.TimesheetUnscheduled,
+				At:      entry.ClockInAt,
+				EntryID: entry.ID,
+				Minutes: int(entry.Worked(now) / time.Minute),
+			})
+			continue
+		}
+
+		matched[best] = true
+		occurrence := scheduled[best]
+		if late := entry.ClockInAt.Sub(occurrence.startAt); late > punchGrace {
+			exceptions = append(exceptions, model.TimesheetException{
+				Type:    model.TimesheetLate,
+				At:      entry.ClockInAt,
+				ShiftID: occurrence.shift.ID,
+				EntryID: entry.ID,
+				Minutes: int(late / time.Minute),
+			})
+		}
+		if entry.ClockOutAt != nil {
+			if early := occurrence.endAt.Sub(*entry.ClockOutAt); early > punchGrace {
+				exceptions = append(exceptions, model.TimesheetException{
+					Type:    model.TimesheetLeftEarly,
+					At:      *entry.ClockOutAt,
+					ShiftID: occurrence.shift.ID,
+					EntryID: entry.ID,
+					Minutes: int(early / time.Minute),
+				})
+			}
+		}
+	}
+
+	for s, occurrence := range scheduled {
+		if matched[s] || occurrence.endAt.After(now) {
+			continue
+		}
+		exceptions = append(exceptions, model.TimesheetException{
+			Type:    model.TimesheetMissedShift,
+			At:      occurrence.startAt,
+			ShiftID: occurrence.shift.ID,
+			Minutes: occurrence.shift.Duration(),
+		})
+	}
+
+	sort.SliceStable(exceptions, func(i, j int) bool { return exceptions[i].At.Before(exceptions[j].At) })
+	sheet.Exceptions = exceptions
+}
+
+// exceptionSummary counts the exceptions by type, e.g. "late:2 missed_shift:1"
+func exceptionSummary(exceptions []model.TimesheetException) string {
+	counts := map[string]int{}
+	var types []string
+	for _, exception := range exceptions {
+		if counts[exception.Type] == 0 {
+			types = append(types, exception.Type)
+		}
+		counts[exception.Type]++
+	}
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s:%d", t, counts[t]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
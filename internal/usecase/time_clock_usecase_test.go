@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"bytes"
+	"cakestore/internal/constants"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTimeClockRepository struct {
+	mock.Mock
+}
+
+func (m *MockTimeClockRepository) GetOpenEntry(ctx context.Context, employeeID int64) (*entity.TimeEntry, error) {
+	args := m.Called(ctx, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeClockRepository) GetEntries(ctx context.Context, params *model.TimeEntryQueryParams) ([]entity.TimeEntry, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeClockRepository) GetEntryByID(ctx context.Context, id int64) (*entity.TimeEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TimeEntry), args.Error(1)
+}
+
+func (m *MockTimeClockRepository) CreateEntry(ctx context.Context, entry *entity.TimeEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockTimeClockRepository) UpdateEntry(ctx context.Context, entry *entity.TimeEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+var testPayPolicy = PayPolicy{
+	PeriodStart:        time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local),
+	PeriodDays:         14,
+	WeeklyOvertime:     40 * time.Hour,
+	OvertimeMultiplier: 1.5,
+}
+
+func localTime(day, hour, minute int) time.Time {
+	return time.Date(2026, time.March, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestPayPolicy_Period(t *testing.T) {
+	start, end := testPayPolicy.Period(localTime(4, 13, 0))
+	assert.Equal(t, localTime(2, 0, 0), start)
+	assert.Equal(t, localTime(16, 0, 0), end)
+
+	// periods also run backwards from the anchor
+	start, _ = testPayPolicy.Period(time.Date(2025, time.December, 31, 8, 0, 0, 0, time.Local))
+	assert.Equal(t, time.Date(2025, time.December, 22, 0, 0, 0, 0, time.Local), start)
+}
+
+func TestTimeClockUseCase_ClockIn(t *testing.T) {
+	t.Run("requires a POS terminal", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+		_, err := useCase.ClockIn(context.Background(), model.Actor{CustomerID: 9, Role: constants.RoleWaitress})
+
+		assert.ErrorIs(t, err, constants.ErrDeviceRequired)
+		timeClockRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything)
+	})
+
+	t.Run("records the terminal", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, customerRepo, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+		timeClockRepo.On("GetOpenEntry", mock.Anything, int64(9)).Return(nil, constants.ErrNotFound)
+		customerRepo.On("GetEmployeeByID", mock.Anything, int64(9)).Return(&entity.Customer{ID: 9, Name: "Sari"}, nil)
+		timeClockRepo.On("CreateEntry", mock.Anything, mock.MatchedBy(func(entry *entity.TimeEntry) bool {
+			return entry.EmployeeID == 9 && *entry.ClockInDeviceID == 3 && entry.ClockOutAt == nil
+		})).Return(nil)
+
+		entry, err := useCase.ClockIn(context.Background(), model.Actor{CustomerID: 9, DeviceID: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Sari", entry.EmployeeName)
+		timeClockRepo.AssertExpectations(t)
+	})
+
+	t.Run("already clocked in", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+		timeClockRepo.On("GetOpenEntry", mock.Anything, int64(9)).Return(&entity.TimeEntry{ID: 1, EmployeeID: 9}, nil)
+
+		_, err := useCase.ClockIn(context.Background(), model.Actor{CustomerID: 9, DeviceID: 3})
+
+		assert.ErrorIs(t, err, constants.ErrAlreadyClockedIn)
+	})
+}
+
+func TestTimeClockUseCase_ClockOut(t *testing.T) {
+	timeClockRepo := new(MockTimeClockRepository)
+	useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+	clockIn := time.Now().Add(-4 * time.Hour)
+	timeClockRepo.On("GetOpenEntry", mock.Anything, int64(9)).Return(&entity.TimeEntry{
+		ID:         1,
+		EmployeeID: 9,
+		ClockInAt:  clockIn,
+		Breaks:     []entity.TimeBreak{{ID: 2, StartAt: time.Now().Add(-30 * time.Minute)}},
+	}, nil)
+	timeClockRepo.On("UpdateEntry", mock.Anything, mock.MatchedBy(func(entry *entity.TimeEntry) bool {
+		return entry.ClockOutAt != nil && entry.OpenBreak() == nil && *entry.ClockOutDeviceID == 3
+	})).Return(nil)
+
+	entry, err := useCase.ClockOut(context.Background(), model.Actor{CustomerID: 9, DeviceID: 3})
+
+	assert.NoError(t, err)
+	assert.False(t, entry.OnBreak)
+	assert.Equal(t, 30, entry.BreakMinutes)
+	assert.Equal(t, 210, entry.WorkedMinutes)
+}
+
+func TestTimeClockUseCase_StartBreak(t *testing.T) {
+	timeClockRepo := new(MockTimeClockRepository)
+	useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+	timeClockRepo.On("GetOpenEntry", mock.Anything, int64(9)).Return(nil, constants.ErrNotFound)
+
+	_, err := useCase.StartBreak(context.Background(), model.Actor{CustomerID: 9, DeviceID: 3})
+
+	assert.ErrorIs(t, err, constants.ErrNotClockedIn)
+}
+
+func TestTimeClockUseCase_CorrectEntry(t *testing.T) {
+	entry := func() *entity.TimeEntry {
+		clockOut := localTime(2, 17, 0)
+		return &entity.TimeEntry{ID: 1, EmployeeID: 9, ClockInAt: localTime(2, 9, 20), ClockOutAt: &clockOut}
+	}
+
+	t.Run("keeps who corrected the entry and audits it", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		recorder := newMockAuditRecorder()
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, recorder)
+
+		timeClockRepo.On("GetEntryByID", mock.Anything, int64(1)).Return(entry(), nil)
+		timeClockRepo.On("GetEntries", mock.Anything, mock.Anything).Return([]entity.TimeEntry{*entry()}, nil)
+		timeClockRepo.On("UpdateEntry", mock.Anything, mock.MatchedBy(func(e *entity.TimeEntry) bool {
+			return e.ClockInAt.Equal(localTime(2, 9, 0)) && len(e.Breaks) == 1 && *e.CorrectedBy == 1 && e.CorrectionReason == "forgot to punch"
+		})).Return(nil)
+		breakEnd := localTime(2, 12, 30)
+
+		corrected, err := useCase.CorrectEntry(context.Background(), model.Actor{CustomerID: 1, Role: constants.RoleAdmin}, 1, &model.CorrectTimeEntryRequest{
+			ClockInAt:  localTime(2, 9, 0),
+			ClockOutAt: entry().ClockOutAt,
+			Breaks:     []model.TimeBreakRequest{{StartAt: localTime(2, 12, 0), EndAt: &breakEnd}},
+			Reason:     "forgot to punch",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 450, corrected.WorkedMinutes)
+		recorder.AssertCalled(t, "Record", mock.Anything, mock.Anything, constants.AuditActionCorrect, constants.AuditEntityTimeEntry, int64(1), mock.Anything, mock.Anything)
+	})
+
+	t.Run("break outside the entry", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+		timeClockRepo.On("GetEntryByID", mock.Anything, int64(1)).Return(entry(), nil)
+		breakEnd := localTime(2, 18, 30)
+
+		_, err := useCase.CorrectEntry(context.Background(), model.Actor{CustomerID: 1}, 1, &model.CorrectTimeEntryRequest{
+			ClockInAt:  localTime(2, 9, 0),
+			ClockOutAt: entry().ClockOutAt,
+			Breaks:     []model.TimeBreakRequest{{StartAt: localTime(2, 18, 0), EndAt: &breakEnd}},
+			Reason:     "fix",
+		})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRequest)
+		timeClockRepo.AssertNotCalled(t, "UpdateEntry", mock.Anything, mock.Anything)
+	})
+
+	t.Run("overlaps another entry", func(t *testing.T) {
+		timeClockRepo := new(MockTimeClockRepository)
+		useCase := NewTimeClockUseCase(timeClockRepo, nil, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+		otherOut := localTime(2, 8, 0)
+		timeClockRepo.On("GetEntryByID", mock.Anything, int64(1)).Return(entry(), nil)
+		timeClockRepo.On("GetEntries", mock.Anything, mock.Anything).Return([]entity.TimeEntry{
+			{ID: 2, EmployeeID: 9, ClockInAt: localTime(2, 6, 0), ClockOutAt: &otherOut},
+		}, nil)
+
+		_, err := useCase.CorrectEntry(context.Background(), model.Actor{CustomerID: 1}, 1, &model.CorrectTimeEntryRequest{
+			ClockInAt:  localTime(2, 7, 0),
+			ClockOutAt: entry().ClockOutAt,
+			Reason:     "fix",
+		})
+
+		assert.ErrorIs(t, err, constants.ErrTimeEntryOverlap)
+	})
+}
+
+func TestTimeClockUseCase_GetTimesheet(t *testing.T) {
+	timeClockRepo := new(MockTimeClockRepository)
+	employeeRepo := new(MockEmployeeRepository)
+	useCase := NewTimeClockUseCase(timeClockRepo, employeeRepo, nil, logrus.New(), testPayPolicy, newMockAuditRecorder())
+
+	employee := entity.Customer{ID: 9, Name: "Sari", Role: constants.RoleWaitress}
+	entry := func(id int64, day, inHour, inMinute, outHour int) entity.TimeEntry {
+		clockOut := localTime(day, outHour, 0)
+		return entity.TimeEntry{ID: id, EmployeeID: 9, Employee: employee, ClockInAt: localTime(day, inHour, inMinute), ClockOutAt: &clockOut}
+	}
+
+	// late on the scheduled Monday, four unscheduled ten hour days and no
+	// show on the second Monday
+	monday := entry(1, 2, 9, 20, 17)
+	breakEnd := localTime(2, 12, 30)
+	monday.Breaks = []entity.TimeBreak{{StartAt: localTime(2, 12, 0), EndAt: &breakEnd}}
+	entries := []entity.TimeEntry{monday}
+	for day := 3; day <= 6; day++ {
+		entries = append(entries, entry(int64(day), day, 8, 0, 18))
+	}
+
+	timeClockRepo.On("GetEntries", mock.Anything, &model.TimeEntryQueryParams{From: localTime(2, 0, 0), To: localTime(16, 0, 0)}).Return(entries, nil)
+	employeeRepo.On("GetShifts", mock.Anything, &model.ShiftQueryParams{}).Return([]entity.Shift{
+		{ID: 7, EmployeeID: 9, Employee: employee, Role: constants.RoleWaitress, Weekday: time.Monday, StartMinute: 9 * 60, EndMinute: 17 * 60},
+	}, nil)
+	employeeRepo.On("GetProfiles", mock.Anything, []int64{9}).Return([]entity.EmployeeProfile{{CustomerID: 9, HourlyRate: 20000}}, nil)
+
+	timesheet, err := useCase.GetTimesheet(context.Background(), &model.TimesheetQueryParams{Date: localTime(4, 0, 0)})
+
+	assert.NoError(t, err)
+	assert.Len(t, timesheet.Employees, 1)
+	sheet := timesheet.Employees[0]
+	assert.Equal(t, 16.0, sheet.ScheduledHours)
+	assert.Equal(t, 47.17, sheet.WorkedHours)
+	assert.Equal(t, 40.0, sheet.RegularHours)
+	assert.Equal(t, 7.17, sheet.OvertimeHours)
+	assert.Equal(t, 0.5, sheet.BreakHours)
+	assert.Equal(t, 800000.0, sheet.RegularPay)
+	assert.Equal(t, 215000.0, sheet.OvertimePay)
+	assert.Equal(t, 0.0, sheet.Weeks[1].WorkedHours)
+
+	var types []string
+	for _, exception := range sheet.Exceptions {
+		types = append(types, exception.Type)
+	}
+	assert.Equal(t, []string{
+		model.TimesheetLate,
+		model.TimesheetUnscheduled, model.TimesheetUnscheduled, model.TimesheetUnscheduled, model.TimesheetUnscheduled,
+		model.TimesheetMissedShift,
+	}, types)
+	assert.Equal(t, 20, sheet.Exceptions[0].Minutes)
+
+	var buf bytes.Buffer
+	assert.NoError(t, useCase.ExportTimesheet(context.Background(), &model.TimesheetQueryParams{Date: localTime(4, 0, 0)}, &buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"9", "Sari", constants.RoleWaitress, "2026-03-02", "2026-03-15", "16.00", "47.17", "40.00", "7.17", "0.50",
+		"20000.00", "800000.00", "215000.00", "1015000.00", "late:1 unscheduled:4 missed_shift:1"}, records[1])
+}
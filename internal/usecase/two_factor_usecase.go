@@ -0,0 +1,442 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/repository"
+	"cakestore/internal/totp"
+	"cakestore/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	twoFactorIssuer = "CakeStore"
+	// twoFactorChallengeTTL is how long a password login waits for its TOTP
+	// code, the challenge is dropped after maxChallengeAttempts wrong codes
+	twoFactorChallengeTTL = 5 * time.Minute
+	maxChallengeAttempts  = 5
+	challengeTokenLength  = 32
+	recoveryCodeCount     = 10
+)
+
+// TwoFactorChallenger is the part of two-factor authentication the password
+// login goes through
+type TwoFactorChallenger interface {
+	// Challenge returns the response that replaces the tokens when the
+	// customer has to complete the login with a TOTP code, or nil when the
+	// password alone is enough
+	Challenge(ctx context.Context, customer *entity.Customer) (*model.TokenResponse, error)
+	// ChallengeCustomer returns the customer a pending challenge was issued to
+	ChallengeCustomer(ctx context.Context, challengeToken string) (int64, error)
+	// CompleteChallenge checks the code against the challenge and returns the
+	// customer it was issued to. The customer is also returned with
+	// ErrInvalidTwoFactorCode so the failure counts against the account.
+	// Recovery codes are returned when the challenge confirmed an enrollment.
+	CompleteChallenge(ctx context.Context, challengeToken, code string) (int64, []string, error)
+}
+
+// TwoFactorUseCase manages TOTP two-factor authentication. It is optional for
+// customers and enforced for the roles listed in TWO_FACTOR_REQUIRED_ROLES.
+type TwoFactorUseCase interface {
+	TwoFactorChallenger
+	GetStatus(ctx context.Context, customerID int64) (*model.TwoFactorStatusResponse, error)
+	// Enroll creates a new secret, it only takes effect once Confirm accepts a code
+	Enroll(ctx context.Context, customerID int64) (*model.TwoFactorEnrollResponse, error)
+	Confirm(ctx context.Context, customerID int64, code string) (*model.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, customerID int64, code string) (*model.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, customerID int64, request *model.DisableTwoFactorRequest) error
+	// Reset removes two-factor authentication from an account that lost both
+	// its phone and its recovery codes, the next login enrolls again if required
+	Reset(ctx context.Context, actor model.Actor, customerID int64) error
+	// EnrollChallenge enrolls an account whose role requires two-factor
+	// authentication during login, the challenge is completed with the first code
+	EnrollChallenge(ctx context.Context, challengeToken string) (*model.TwoFactorEnrollResponse, error)
+}
+
+type twoFactorUseCase struct {
+	twoFactorRepo repository.TwoFactorRepository
+	customerRepo  repository.CustomerRepository
+	log           *logrus.Logger
+	cache         database.RedisCache
+	requiredRoles []string
+	audit         AuditRecorder
+}
+
+func NewTwoFactorUseCase(
+	twoFactorRepo repository.TwoFactorRepository,
+	customerRepo repository.CustomerRepository,
+	log *logrus.Logger,
+	cache database.RedisCache,
+	requiredRoles []string,
+	audit AuditRecorder,
+) TwoFactorUseCase {
+	return &twoFactorUseCase{
+		twoFactorRepo: twoFactorRepo,
+		customerRepo:  customerRepo,
+		log:           log,
+		cache:         cache,
+		requiredRoles: requiredRoles,
+		audit:         audit,
+	}
+}
+
+// twoFactorChallenge is kept in the cache under the hash of its token
+type twoFactorChallenge struct {
+	CustomerID int64     `json:"customer_id"`
+	Attempts   int       `json:"attempts"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func twoFactorChallengeCacheKey(challengeToken string) string {
+	return fmt.Sprintf("two_factor_challenge:%s", utils.GenerateSHA256Hash(challengeToken))
+}
+
+func (u *twoFactorUseCase) GetStatus(ctx context.Context, customerID int64) (*model.TwoFactorStatusResponse, error) {
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, constants.ErrNotFound
+	}
+
+	status := &model.TwoFactorStatusResponse{Required: u.required(customer.Role)}
+	twoFactor, err := u.twoFactorRepo.Get(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return status, nil
+		}
+		return nil, err
+	}
+	if !twoFactor.Enabled() {
+		return status, nil
+	}
+
+	status.Enabled = true
+	if status.RecoveryCodesRemaining, err = u.twoFactorRepo.CountRecoveryCodes(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (u *twoFactorUseCase) Enroll(ctx context.Context, customerID int64) (*model.TwoFactorEnrollResponse, error) {
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, constants.ErrNotFound
+	}
+
+	current, err := u.twoFactorRepo.Get(ctx, customerID)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+	if current != nil && current.Enabled() {
+		return nil, constants.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		u.log.Errorf("Error generating two-factor secret: %v", err)
+		return nil, err
+	}
+	if err := u.twoFactorRepo.SavePending(ctx, &entity.TwoFactor{CustomerID: customerID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, customer.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUseCase) Confirm(ctx context.Context, customerID int64, code string) (*model.RecoveryCodesResponse, error) {
+	twoFactor, err := u.twoFactorRepo.Get(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, fmt.Errorf("%w: start the enrollment first", constants.ErrInvalidRequest)
+		}
+		return nil, err
+	}
+	if twoFactor.Enabled() {
+		return nil, constants.ErrTwoFactorEnabled
+	}
+
+	ok, err := u.verifyCode(ctx, twoFactor, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, constants.ErrInvalidTwoFactorCode
+	}
+
+	codes, err := u.enable(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u *twoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, customerID int64, code string) (*model.RecoveryCodesResponse, error) {
+	twoFactor, err := u.enabledTwoFactor(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a TOTP code is accepted, a recovery code would let whoever holds
+	// the old list mint a new one
+	ok, err := u.verifyCode(ctx, twoFactor, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, constants.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashed, err := newRecoveryCodes()
+	if err != nil {
+		u.log.Errorf("Error generating recovery codes: %v", err)
+		return nil, err
+	}
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(ctx, customerID, hashed); err != nil {
+		return nil, err
+	}
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u *twoFactorUseCase) Disable(ctx context.Context, customerID int64, request *model.DisableTwoFactorRequest) error {
+	customer, err := u.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return constants.ErrNotFound
+	}
+	if u.required(customer.Role) {
+		return constants.ErrTwoFactorEnforced
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
+		return constants.ErrInvalidCredentials
+	}
+
+	twoFactor, err := u.enabledTwoFactor(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	ok, err := u.verifyCode(ctx, twoFactor, request.Code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return constants.ErrInvalidTwoFactorCode
+	}
+
+	return u.twoFactorRepo.Delete(ctx, customerID)
+}
+
+func (u *twoFactorUseCase) Reset(ctx context.Context, actor model.Actor, customerID int64) error {
+	before, err := u.GetStatus(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if err := u.twoFactorRepo.Delete(ctx, customerID); err != nil {
+		return err
+	}
+
+	after := &model.TwoFactorStatusResponse{Required: before.Required}
+	u.audit.Record(ctx, actor, constants.AuditActionReset, constants.AuditEntityTwoFactor, customerID, before, after)
+	return nil
+}
+
+func (u *twoFactorUseCase) Challenge(ctx context.Context, customer *entity.Customer) (*model.TokenResponse, error) {
+	twoFactor, err := u.twoFactorRepo.Get(ctx, customer.ID)
+	if err != nil && !errors.Is(err, constants.ErrNotFound) {
+		return nil, err
+	}
+	enabled := twoFactor != nil && twoFactor.Enabled()
+	if !enabled && !u.required(customer.Role) {
+		return nil, nil
+	}
+
+	raw, err := utils.GenerateRandomString(challengeTokenLength)
+	if err != nil {
+		u.log.Errorf("Error generating two-factor challenge: %v", err)
+		return nil, err
+	}
+	challenge := twoFactorChallenge{
+		CustomerID: customer.ID,
+		ExpiresAt:  time.Now().Add(twoFactorChallengeTTL),
+	}
+	if err := u.cache.Set(ctx, twoFactorChallengeCacheKey(raw), challenge, twoFactorChallengeTTL); err != nil {
+		u.log.Errorf("Error storing two-factor challenge for customer %d: %v", customer.ID, err)
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		TwoFactorRequired:  true,
+		EnrollmentRequired: !enabled,
+		ChallengeToken:     raw,
+		ExpiresIn:          int64(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+func (u *twoFactorUseCase) EnrollChallenge(ctx context.Context, challengeToken string) (*model.TwoFactorEnrollResponse, error) {
+	challenge, err := u.getChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return u.Enroll(ctx, challenge.CustomerID)
+}
+
+func (u *twoFactorUseCase) ChallengeCustomer(ctx context.Context, challengeToken string) (int64, error) {
+	challenge, err := u.getChallenge(ctx, challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	return challenge.CustomerID, nil
+}
+
+func (u *twoFactorUseCase) CompleteChallenge(ctx context.Context, challengeToken, code string) (int64, []string, error) {
+	challenge, err := u.getChallenge(ctx, challengeToken)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	twoFactor, err := u.twoFactorRepo.Get(ctx, challenge.CustomerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return 0, nil, constants.ErrTwoFactorNotEnabled
+		}
+		return 0, nil, err
+	}
+
+	// An account enrolling at login has no recovery codes yet, its first
+	// TOTP code confirms the enrollment
+	enrolling := !twoFactor.Enabled()
+	ok, err := u.verifyCode(ctx, twoFactor, code, !enrolling)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !ok {
+		u.failChallenge(ctx, challengeToken, challenge)
+		return challenge.CustomerID, nil, constants.ErrInvalidTwoFactorCode
+	}
+	u.deleteChallenge(ctx, challengeToken)
+
+	if !enrolling {
+		return challenge.CustomerID, nil, nil
+	}
+	codes, err := u.enable(ctx, challenge.CustomerID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return challenge.CustomerID, codes, nil
+}
+
+func (u *twoFactorUseCase) getChallenge(ctx context.Context, challengeToken string) (*twoFactorChallenge, error) {
+	var challenge twoFactorChallenge
+	if err := u.cache.Get(ctx, twoFactorChallengeCacheKey(challengeToken), &challenge); err != nil {
+		return nil, constants.ErrInvalidToken
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return nil, constants.ErrInvalidToken
+	}
+	return &challenge, nil
+}
+
+// failChallenge counts a wrong code, the challenge is dropped once it ran out
+// of attempts so the password has to be entered again
+func (u *twoFactorUseCase) failChallenge(ctx context.Context, challengeToken string, challenge *twoFactorChallenge) {
+	challenge.Attempts++
+	ttl := time.Until(challenge.ExpiresAt)
+	if challenge.Attempts >= maxChallengeAttempts || ttl <= 0 {
+		u.deleteChallenge(ctx, challengeToken)
+		return
+	}
+	if err := u.cache.Set(ctx, twoFactorChallengeCacheKey(challengeToken), challenge, ttl); err != nil {
+		u.log.Errorf("Error updating two-factor challenge for customer %d: %v", challenge.CustomerID, err)
+		u.deleteChallenge(ctx, challengeToken)
+	}
+}
+
+func (u *twoFactorUseCase) deleteChallenge(ctx context.Context, challengeToken string) {
+	if err := u.cache.Delete(ctx, twoFactorChallengeCacheKey(challengeToken)); err != nil {
+		u.log.Errorf("Error deleting two-factor challenge: %v", err)
+	}
+}
+
+func (u *twoFactorUseCase) enabledTwoFactor(ctx context.Context, customerID int64) (*entity.TwoFactor, error) {
+	twoFactor, err := u.twoFactorRepo.Get(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, constants.ErrNotFound) {
+			return nil, constants.ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if !twoFactor.Enabled() {
+		return nil, constants.ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+func (u *twoFactorUseCase) enable(ctx context.Context, customerID int64) ([]string, error) {
+	codes, hashed, err := newRecoveryCodes()
+	if err != nil {
+		u.log.Errorf("Error generating recovery codes: %v", err)
+		return nil, err
+	}
+	if err := u.twoFactorRepo.Enable(ctx, customerID, hashed); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifyCode accepts a TOTP code whose time step wasn't used yet and, when
+// allowRecovery is set, an unused recovery code
+func (u *twoFactorUseCase) verifyCode(ctx context.Context, twoFactor *entity.TwoFactor, code string, allowRecovery bool) (bool, error) {
+	code = normalizeTwoFactorCode(code)
+
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now()); ok {
+		return u.twoFactorRepo.UseStep(ctx, twoFactor.CustomerID, step)
+	}
+	if !allowRecovery || len(code) == totp.Digits {
+		return false, nil
+	}
+	return u.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.CustomerID, utils.GenerateSHA256Hash(code))
+}
+
+func (u *twoFactorUseCase) required(role string) bool {
+	for _, r := range u.requiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTwoFactorCode drops the spaces and dashes people type along with
+// codes, recovery codes are compared in lowercase
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+// newRecoveryCodes returns the codes to show once and their hashes to store
+func newRecoveryCodes() ([]string, []entity.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashed := make([]entity.RecoveryCode, 0, recoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw)[:10])
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashed = append(hashed, entity.RecoveryCode{CodeHash: utils.GenerateSHA256Hash(code)})
+	}
+	return codes, hashed, nil
+}
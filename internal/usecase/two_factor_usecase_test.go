@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"cakestore/internal/constants"
+	"cakestore/internal/database"
+	"cakestore/internal/domain/entity"
+	"cakestore/internal/domain/model"
+	"cakestore/internal/totp"
+	"cakestore/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) Get(ctx context.Context, customerID int64) (*entity.TwoFactor, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) SavePending(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	args := m.Called(ctx, twoFactor)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Enable(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error {
+	args := m.Called(ctx, customerID, codes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, customerID int64, codes []entity.RecoveryCode) error {
+	args := m.Called(ctx, customerID, codes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseStep(ctx context.Context, customerID int64, step int64) (bool, error) {
+	args := m.Called(ctx, customerID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, customerID int64, codeHash string) (bool, error) {
+	args := m.Called(ctx, customerID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) CountRecoveryCodes(ctx context.Context, customerID int64) (int64, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Delete(ctx context.Context, customerID int64) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+type MockTwoFactorChallenger struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorChallenger) Challenge(ctx context.Context, customer *entity.Customer) (*model.TokenResponse, error) {
+	args := m.Called(ctx, customer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorChallenger) ChallengeCustomer(ctx context.Context, challengeToken string) (int64, error) {
+	args := m.Called(ctx, challengeToken)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTwoFactorChallenger) CompleteChallenge(ctx context.Context, challengeToken, code string) (int64, []string, error) {
+	args := m.Called(ctx, challengeToken, code)
+	if args.Get(1) == nil {
+		return args.Get(0).(int64), nil, args.Error(2)
+	}
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
+// cachedChallenge makes the cache return a pending challenge for customer 1
+func cachedChallenge(cache *database.MockRedisCacheService, attempts int) {
+	cache.On("Get", mock.Anything, twoFactorChallengeCacheKey("challenge"), mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*twoFactorChallenge) = twoFactorChallenge{
+			CustomerID: 1,
+			Attempts:   attempts,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		}
+	}).Return(nil)
+}
+
+func currentCode(secret string) string {
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	return code
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 test vector for the SHA-1 secret "12345678901234567890" at T=59
+	code, err := totp.Code("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", totp.Step(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	step, ok := totp.Validate("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", "287082", time.Unix(75, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+
+	_, ok = totp.Validate("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", "287082", time.Unix(150, 0))
+	assert.False(t, ok)
+}
+
+func TestTwoFactorUseCase_Challenge(t *testing.T) {
+	enabledAt := time.Now()
+
+	t.Run("no challenge without two-factor for optional roles", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+
+		challenge, err := useCase.Challenge(context.Background(), &entity.Customer{ID: 1, Role: constants.RoleCustomer})
+
+		assert.NoError(t, err)
+		assert.Nil(t, challenge)
+		cache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("enabled two-factor returns a challenge", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: "SECRET", EnabledAt: &enabledAt}, nil)
+		cache.On("Set", mock.Anything, mock.Anything, mock.MatchedBy(func(c twoFactorChallenge) bool {
+			return c.CustomerID == 1 && c.Attempts == 0
+		}), twoFactorChallengeTTL).Return(nil)
+
+		challenge, err := useCase.Challenge(context.Background(), &entity.Customer{ID: 1, Role: constants.RoleCustomer})
+
+		assert.NoError(t, err)
+		assert.True(t, challenge.TwoFactorRequired)
+		assert.False(t, challenge.EnrollmentRequired)
+		assert.NotEmpty(t, challenge.ChallengeToken)
+		assert.Empty(t, challenge.AccessToken)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("enforced role without enrollment has to enroll", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		cache.On("Set", mock.Anything, mock.Anything, mock.Anything, twoFactorChallengeTTL).Return(nil)
+
+		challenge, err := useCase.Challenge(context.Background(), &entity.Customer{ID: 1, Role: constants.RoleCashier})
+
+		assert.NoError(t, err)
+		assert.True(t, challenge.TwoFactorRequired)
+		assert.True(t, challenge.EnrollmentRequired)
+	})
+}
+
+func TestTwoFactorUseCase_CompleteChallenge(t *testing.T) {
+	secret, _ := totp.GenerateSecret()
+	enabledAt := time.Now()
+
+	t.Run("valid code completes the challenge", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cachedChallenge(cache, 0)
+		cache.On("Delete", mock.Anything, twoFactorChallengeCacheKey("challenge")).Return(nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret, EnabledAt: &enabledAt}, nil)
+		twoFactorRepo.On("UseStep", mock.Anything, int64(1), mock.Anything).Return(true, nil)
+
+		customerID, codes, err := useCase.CompleteChallenge(context.Background(), "challenge", currentCode(secret))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), customerID)
+		assert.Nil(t, codes)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("replayed code is rejected", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cachedChallenge(cache, 0)
+		cache.On("Set", mock.Anything, twoFactorChallengeCacheKey("challenge"), mock.MatchedBy(func(c *twoFactorChallenge) bool {
+			return c.Attempts == 1
+		}), mock.Anything).Return(nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret, EnabledAt: &enabledAt}, nil)
+		twoFactorRepo.On("UseStep", mock.Anything, int64(1), mock.Anything).Return(false, nil)
+
+		customerID, _, err := useCase.CompleteChallenge(context.Background(), "challenge", currentCode(secret))
+
+		assert.ErrorIs(t, err, constants.ErrInvalidTwoFactorCode)
+		assert.Equal(t, int64(1), customerID)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("last wrong attempt drops the challenge", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cachedChallenge(cache, maxChallengeAttempts-1)
+		cache.On("Delete", mock.Anything, twoFactorChallengeCacheKey("challenge")).Return(nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret, EnabledAt: &enabledAt}, nil)
+		twoFactorRepo.On("UseRecoveryCode", mock.Anything, int64(1), mock.Anything).Return(false, nil)
+
+		_, _, err := useCase.CompleteChallenge(context.Background(), "challenge", "not-a-code")
+
+		assert.ErrorIs(t, err, constants.ErrInvalidTwoFactorCode)
+		cache.AssertExpectations(t)
+		cache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recovery code is accepted", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cachedChallenge(cache, 0)
+		cache.On("Delete", mock.Anything, twoFactorChallengeCacheKey("challenge")).Return(nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret, EnabledAt: &enabledAt}, nil)
+		twoFactorRepo.On("UseRecoveryCode", mock.Anything, int64(1), utils.GenerateSHA256Hash("abcdefghij")).Return(true, nil)
+
+		customerID, _, err := useCase.CompleteChallenge(context.Background(), "challenge", "ABCDE-FGHIJ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), customerID)
+	})
+
+	t.Run("first code confirms an enrollment at login", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cachedChallenge(cache, 0)
+		cache.On("Delete", mock.Anything, twoFactorChallengeCacheKey("challenge")).Return(nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret}, nil)
+		twoFactorRepo.On("UseStep", mock.Anything, int64(1), mock.Anything).Return(true, nil)
+		twoFactorRepo.On("Enable", mock.Anything, int64(1), mock.MatchedBy(func(codes []entity.RecoveryCode) bool {
+			return len(codes) == recoveryCodeCount
+		})).Return(nil)
+
+		customerID, codes, err := useCase.CompleteChallenge(context.Background(), "challenge", currentCode(secret))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), customerID)
+		assert.Len(t, codes, recoveryCodeCount)
+		twoFactorRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown challenge", func(t *testing.T) {
+		cache := new(database.MockRedisCacheService)
+		useCase := NewTwoFactorUseCase(nil, nil, logrus.New(), cache, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("cache miss"))
+
+		_, _, err := useCase.CompleteChallenge(context.Background(), "challenge", "123456")
+
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+}
+
+func TestTwoFactorUseCase_Enroll(t *testing.T) {
+	t.Run("returns a provisioning URI", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, customerRepo, logrus.New(), nil, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Email: "rina@example.com"}, nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		twoFactorRepo.On("SavePending", mock.Anything, mock.MatchedBy(func(tf *entity.TwoFactor) bool {
+			return tf.CustomerID == 1 && tf.Secret != ""
+		})).Return(nil)
+
+		enrollment, err := useCase.Enroll(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/CakeStore:rina@example.com?")
+		assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, customerRepo, logrus.New(), nil, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		enabledAt := time.Now()
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1}, nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, EnabledAt: &enabledAt}, nil)
+
+		_, err := useCase.Enroll(context.Background(), 1)
+
+		assert.ErrorIs(t, err, constants.ErrTwoFactorEnabled)
+		twoFactorRepo.AssertNotCalled(t, "SavePending", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTwoFactorUseCase_Disable(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	secret, _ := totp.GenerateSecret()
+	enabledAt := time.Now()
+
+	t.Run("enforced roles can't disable", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, customerRepo, logrus.New(), nil, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Role: constants.RoleAdmin, Password: string(hashed)}, nil)
+
+		err := useCase.Disable(context.Background(), 1, &model.DisableTwoFactorRequest{Password: "password123", Code: currentCode(secret)})
+
+		assert.ErrorIs(t, err, constants.ErrTwoFactorEnforced)
+		twoFactorRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, customerRepo, logrus.New(), nil, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Role: constants.RoleCustomer, Password: string(hashed)}, nil)
+
+		err := useCase.Disable(context.Background(), 1, &model.DisableTwoFactorRequest{Password: "wrong", Code: currentCode(secret)})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidCredentials)
+		twoFactorRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		customerRepo := new(MockCustomerRepository)
+		useCase := NewTwoFactorUseCase(twoFactorRepo, customerRepo, logrus.New(), nil, []string{constants.RoleAdmin, constants.RoleCashier}, newMockAuditRecorder())
+
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(&entity.Customer{ID: 1, Role: constants.RoleCustomer, Password: string(hashed)}, nil)
+		twoFactorRepo.On("Get", mock.Anything, int64(1)).Return(&entity.TwoFactor{CustomerID: 1, Secret: secret, EnabledAt: &enabledAt}, nil)
+		twoFactorRepo.On("UseStep", mock.Anything, int64(1), mock.Anything).Return(true, nil)
+		twoFactorRepo.On("Delete", mock.Anything, int64(1)).Return(nil)
+
+		err := useCase.Disable(context.Background(), 1, &model.DisableTwoFactorRequest{Password: "password123", Code: currentCode(secret)})
+
+		assert.NoError(t, err)
+		twoFactorRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUseCase_LoginTwoFactor(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	customer := &entity.Customer{ID: 1, Email: "rina@example.com", Password: string(hashed), Role: constants.RoleAdmin}
+
+	newUseCase := func() (AuthUseCase, *MockCustomerRepository, *MockRefreshTokenRepository, *MockLoginRepository, *MockTwoFactorChallenger) {
+		customerRepo := new(MockCustomerRepository)
+		refreshTokenRepo := new(MockRefreshTokenRepository)
+		loginRepo := new(MockLoginRepository)
+		loginRepo.On("HasLoggedInFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, false, nil).Maybe()
+		loginRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+		twoFactor := new(MockTwoFactorChallenger)
+		twoFactor.On("ChallengeCustomer", mock.Anything, "challenge").Return(int64(1), nil).Maybe()
+		useCase := NewAuthUseCase(customerRepo, refreshTokenRepo, loginRepo, twoFactor, logrus.New(), new(database.MockRedisCacheService), newTestTokenService(), 15*time.Minute, 24*time.Hour, newMockAuditRecorder())
+		return useCase, customerRepo, refreshTokenRepo, loginRepo, twoFactor
+	}
+
+	t.Run("password login returns the challenge", func(t *testing.T) {
+		useCase, customerRepo, refreshTokenRepo, loginRepo, twoFactor := newUseCase()
+		customerRepo.On("GetByEmail", mock.Anything, "rina@example.com").Return(customer, nil)
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(&entity.AccountLockout{CustomerID: 1, FailedAttempts: 2}, nil)
+		twoFactor.On("Challenge", mock.Anything, customer).Return(&model.TokenResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil)
+
+		response, err := useCase.Login(context.Background(), &model.LoginRequest{Email: "rina@example.com", Password: "password123"}, model.SessionMeta{})
+
+		assert.NoError(t, err)
+		assert.True(t, response.TwoFactorRequired)
+		assert.Empty(t, response.AccessToken)
+		refreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		// The password alone doesn't clear failed logins, the code is still due
+		loginRepo.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("valid code issues tokens and clears failed logins", func(t *testing.T) {
+		useCase, customerRepo, refreshTokenRepo, loginRepo, twoFactor := newUseCase()
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(&entity.AccountLockout{CustomerID: 1, FailedAttempts: 2}, nil)
+		loginRepo.On("Unlock", mock.Anything, int64(1)).Return(nil)
+		twoFactor.On("CompleteChallenge", mock.Anything, "challenge", "123456").Return(int64(1), []string{"abcde-fghij"}, nil)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil)
+		refreshTokenRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		tokens, err := useCase.LoginTwoFactor(context.Background(), &model.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"}, model.SessionMeta{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Equal(t, []string{"abcde-fghij"}, tokens.RecoveryCodes)
+		loginRepo.AssertExpectations(t)
+	})
+
+	t.Run("locked account can't try codes", func(t *testing.T) {
+		useCase, _, refreshTokenRepo, loginRepo, twoFactor := newUseCase()
+		until := time.Now().Add(time.Minute)
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(&entity.AccountLockout{CustomerID: 1, FailedAttempts: 5, LockedUntil: &until}, nil)
+
+		_, err := useCase.LoginTwoFactor(context.Background(), &model.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"}, model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrAccountLocked)
+		twoFactor.AssertNotCalled(t, "CompleteChallenge", mock.Anything, mock.Anything, mock.Anything)
+		refreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong code counts as a failed login", func(t *testing.T) {
+		useCase, _, refreshTokenRepo, loginRepo, twoFactor := newUseCase()
+		loginRepo.On("GetLockout", mock.Anything, int64(1)).Return(nil, constants.ErrNotFound)
+		twoFactor.On("CompleteChallenge", mock.Anything, "challenge", "000000").Return(int64(1), nil, constants.ErrInvalidTwoFactorCode)
+		loginRepo.On("RecordFailure", mock.Anything, int64(1), loginFailureWindow).Return(1, nil)
+
+		_, err := useCase.LoginTwoFactor(context.Background(), &model.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "000000"}, model.SessionMeta{})
+
+		assert.ErrorIs(t, err, constants.ErrInvalidTwoFactorCode)
+		loginRepo.AssertCalled(t, "RecordFailure", mock.Anything, int64(1), loginFailureWindow)
+		refreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a background task run on a fixed interval. ctx is only cancelled
+// when the runner gives up waiting for the job on shutdown.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs periodic jobs until it is stopped
+type Runner struct {
+	jobs   []Job
+	logger *logrus.Logger
+	// cancel stops scheduling jobs, abort cancels the ones running
+	cancel context.CancelFunc
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// beats holds when each job was last started or finished
+	beats map[string]time.Time
+}
+
+func NewRunner(logger *logrus.Logger) *Runner {
+	return &Runner{logger: logger, beats: make(map[string]time.Time)}
+}
+
+// Add registers a job, it must be called before Start
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	var jobCtx context.Context
+	jobCtx, r.abort = context.WithCancel(context.WithoutCancel(ctx))
+	ctx, r.cancel = context.WithCancel(ctx)
+	for _, job := range r.jobs {
+		r.beat(job.Name)
+		r.wg.Add(1)
+		go r.loop(ctx, jobCtx, job)
+	}
+}
+
+// Stop cancels all jobs and waits for running ones to finish. Once ctx is
+// done the running jobs are cancelled as well.
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.abort()
+		return fmt.Errorf("jobs still running: %w", ctx.Err())
+	}
+}
+
+func (r *Runner) loop(ctx, jobCtx context.Context, job Job) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	r.logger.Infof("Worker %s started, running every %v", job.Name, job.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Infof("Worker %s stopped", job.Name)
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := job.Run(jobCtx)
+			r.beat(job.Name)
+			if err != nil {
+				r.logger.Errorf("Worker %s failed: %v", job.Name, err)
+				continue
+			}
+			r.logger.Debugf("Worker %s took %v", job.Name, time.Since(start))
+		}
+	}
+}
+
+// Check reports jobs that haven't run for three of their intervals, which
+// means they are stuck on a run or their loop stopped
+func (r *Runner) Check(context.Context) error {
+	if r.cancel == nil {
+		return errors.New("workers not started")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for _, job := range r.jobs {
+		if since := time.Since(r.beats[job.Name]); since > 3*job.Interval {
+			errs = append(errs, fmt.Errorf("%s hasn't run for %v", job.Name, since.Round(time.Second)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Runner) beat(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.beats[name] = time.Now()
+}
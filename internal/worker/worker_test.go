@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRunner() *Runner {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewRunner(logger)
+}
+
+func TestRunner_Stop(t *testing.T) {
+	t.Run("waits for a running job", func(t *testing.T) {
+		runner := newTestRunner()
+		started := make(chan struct{}, 1)
+		var finished atomic.Bool
+		runner.Add(Job{Name: "dispatch-notifications", Interval: time.Millisecond, Run: func(context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		}})
+		runner.Start(context.Background())
+		<-started
+
+		err := runner.Stop(context.Background())
+
+		assert.NoError(t, err)
+		assert.True(t, finished.Load())
+	})
+
+	t.Run("cancels running jobs when the context is done", func(t *testing.T) {
+		runner := newTestRunner()
+		started := make(chan struct{}, 1)
+		aborted := make(chan error, 1)
+		runner.Add(Job{Name: "expire-reservation-deposits", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			aborted <- ctx.Err()
+			return ctx.Err()
+		}})
+		runner.Start(context.Background())
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := runner.Stop(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, <-aborted, context.Canceled)
+	})
+}
+
+func TestRunner_Check(t *testing.T) {
+	t.Run("fails before the runner is started", func(t *testing.T) {
+		runner := newTestRunner()
+
+		assert.Error(t, runner.Check(context.Background()))
+	})
+
+	t.Run("passes while jobs keep running", func(t *testing.T) {
+		runner := newTestRunner()
+		runner.Add(Job{Name: "dispatch-notifications", Interval: 10 * time.Millisecond, Run: func(context.Context) error { return nil }})
+		runner.Start(context.Background())
+		defer runner.Stop(context.Background())
+
+		time.Sleep(50 * time.Millisecond)
+
+		assert.NoError(t, runner.Check(context.Background()))
+	})
+
+	t.Run("reports jobs stuck on a run", func(t *testing.T) {
+		runner := newTestRunner()
+		release := make(chan struct{})
+		runner.Add(Job{Name: "queue-reservation-reminders", Interval: 5 * time.Millisecond, Run: func(context.Context) error {
+			<-release
+			return nil
+		}})
+		runner.Start(context.Background())
+		defer runner.Stop(context.Background())
+		defer close(release)
+
+		time.Sleep(50 * time.Millisecond)
+
+		assert.ErrorContains(t, runner.Check(context.Background()), "queue-reservation-reminders")
+	})
+}
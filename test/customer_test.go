@@ -8,7 +8,9 @@ import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
 	"cakestore/internal/middleware"
+	"cakestore/internal/notification"
 	"cakestore/internal/repository"
+	"cakestore/internal/token"
 	"cakestore/internal/usecase"
 	"cakestore/utils"
 	"context"
@@ -17,6 +19,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -40,7 +43,7 @@ func (suite *AuthTestSuite) SetupTest() {
 	cfg := configs.LoadConfig()
 	db := database.ConnectPostgres(cfg)
 	// Run migrations
-	err := db.AutoMigrate(&entity.Customer{})
+	err := db.AutoMigrate(&entity.Customer{}, &entity.RefreshToken{}, &entity.AccountToken{}, &entity.LoginEvent{}, &entity.AccountLockout{}, &entity.AuditLog{})
 	assert.NoError(suite.T(), err)
 	ctx := context.Background()
 	redis := database.NewRedisCacheService(ctx, "")
@@ -48,22 +51,26 @@ func (suite *AuthTestSuite) SetupTest() {
 	suite.db = db
 	suite.logger = utils.NewLogger()
 	suite.repo = repository.NewCustomerRepository(db, suite.logger)
-	suite.useCase = usecase.NewCustomerUseCase(suite.repo, suite.logger, cfg.JWT_SECRET, redis)
-	suite.handler = controller.NewCustomerController(suite.useCase, suite.logger)
+	suite.useCase = usecase.NewCustomerUseCase(suite.repo, repository.NewAccountTokenRepository(db, suite.logger), suite.logger, redis, redis, usecase.NewAuditUseCase(repository.NewAuditRepository(db, suite.logger), suite.logger))
+	tokens, err := token.NewService(token.Config{Secret: cfg.JWT_SECRET})
+	suite.Require().NoError(err)
+	authUseCase := usecase.NewAuthUseCase(suite.repo, repository.NewRefreshTokenRepository(db, suite.logger), repository.NewLoginRepository(db, suite.logger), nil, suite.logger, redis, tokens, 15*time.Minute, 24*time.Hour, usecase.NewAuditUseCase(repository.NewAuditRepository(db, suite.logger), suite.logger))
+	accountUseCase := usecase.NewAccountUseCase(suite.repo, repository.NewAccountTokenRepository(db, suite.logger), authUseCase, notification.NewFileChannel("", suite.logger), suite.logger, redis, "http://localhost:8080")
+	suite.handler = controller.NewCustomerController(suite.useCase, authUseCase, accountUseCase, suite.logger)
 
 	suite.app = fiber.New()
 
 	// Generate a test token
-	token, err := utils.GenerateToken(123, "test@example.com", "Test User", "customer")
+	accessToken, err := GenerateToken(tokens, 123, "test@example.com", "Test User", "customer")
 	suite.Require().NoError(err)
-	suite.token = token
+	suite.token = accessToken
 
 	// Setup routes
 	suite.app.Post("/register", suite.handler.Register)
 	suite.app.Post("/login", suite.handler.Login)
-	suite.app.Get("/authorize", middleware.AuthMiddleware(cfg.JWT_SECRET), suite.handler.Authorize)
-	suite.app.Get("/customers/me", middleware.AuthMiddleware(cfg.JWT_SECRET), suite.handler.GetCustomerByID)
-	suite.app.Put("/customers/:id", middleware.AuthMiddleware(cfg.JWT_SECRET), suite.handler.UpdateProfile)
+	suite.app.Get("/authorize", middleware.AuthMiddleware(tokens, nil, nil), suite.handler.Authorize)
+	suite.app.Get("/customers/me", middleware.AuthMiddleware(tokens, nil, nil), suite.handler.GetCustomerByID)
+	suite.app.Put("/customers/:id", middleware.AuthMiddleware(tokens, nil, nil), suite.handler.UpdateProfile)
 }
 
 func (suite *AuthTestSuite) TestRegister() {
@@ -124,12 +131,14 @@ func (suite *AuthTestSuite) TestGetCustomerByID() {
 	_ = json.Unmarshal(respBody, &response)
 
 	// parse token
-	token, ok := response.Data.(string)
+	tokens, ok := response.Data.(map[string]interface{})
+	suite.Require().True(ok)
+	accessToken, ok := tokens["access_token"].(string)
 	suite.Require().True(ok)
 
 	// Fetch the customer by assumed ID
 	req := httptest.NewRequest("GET", "/customers/me", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
 	resp, err := suite.app.Test(req)
 	suite.Require().NoError(err)
 	suite.Equal(200, resp.StatusCode)
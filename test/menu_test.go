@@ -8,6 +8,7 @@ import (
 	"cakestore/internal/domain/entity"
 	"cakestore/internal/domain/model"
 	"cakestore/internal/repository"
+	"cakestore/internal/token"
 	"cakestore/internal/usecase"
 	"cakestore/utils"
 	"context"
@@ -41,7 +42,7 @@ func (suite *MenuHandlerTestSuite) SetupTest() {
 	db := database.ConnectPostgres(cfg)
 
 	// Run migrations
-	err := db.AutoMigrate(&entity.Menu{})
+	err := db.AutoMigrate(&entity.Menu{}, &entity.AuditLog{})
 	assert.NoError(suite.T(), err)
 
 	ctx := context.Background()
@@ -50,16 +51,18 @@ func (suite *MenuHandlerTestSuite) SetupTest() {
 	suite.db = db
 	suite.logger = utils.NewLogger()
 	suite.repo = repository.NewMenuRepository(db, suite.logger)
-	suite.useCase = usecase.NewMenuUseCase(suite.repo, suite.logger, redis)
+	suite.useCase = usecase.NewMenuUseCase(suite.repo, suite.logger, redis, usecase.NewAuditUseCase(repository.NewAuditRepository(db, suite.logger), suite.logger))
 	suite.handler = controller.NewMenuController(suite.useCase, suite.logger)
 
 	// Initialize Fiber app
 	suite.app = fiber.New()
 
 	// Generate a test token
-	token, err := utils.GenerateToken(123, "test@example.com", "Test User", "customer")
+	tokens, err := token.NewService(token.Config{Secret: cfg.JWT_SECRET})
 	suite.Require().NoError(err)
-	suite.token = token
+	accessToken, err := GenerateToken(tokens, 123, "test@example.com", "Test User", "customer")
+	suite.Require().NoError(err)
+	suite.token = accessToken
 
 	// Setup routes
 	suite.app.Post("/menus", suite.handler.CreateMenu)
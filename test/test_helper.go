@@ -2,13 +2,25 @@ package test
 
 import (
 	"bytes"
+	"cakestore/internal/token"
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// GenerateToken issues an access token for tests
+func GenerateToken(tokens *token.Service, customerID int64, email, name, role string) (string, error) {
+	return tokens.Issue(&token.Claims{
+		Email:      email,
+		Name:       name,
+		CustomerID: customerID,
+		Role:       role,
+	}, 24*time.Hour)
+}
+
 // MakeRequest is a helper function to make HTTP requests in tests
 func MakeRequest(app *fiber.App, method, path string, body interface{}) (*http.Response, error) {
 	var reqBody io.Reader